@@ -0,0 +1,69 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const (
+	// We ask the peer to send (roughly) this many ACKs per congestion window.
+	acksPerCongestionWindow = 4
+	// The maximum ack-eliciting threshold we request.
+	// Waiting for too many packets makes the sender bursty and delays loss detection.
+	maxAckElicitingThreshold = 64
+)
+
+// The ackFrequencyController decides which values to request from the peer using ACK_FREQUENCY frames
+// (draft-ietf-quic-ack-frequency).
+// The ack-eliciting threshold scales with the congestion window, so that fewer ACKs are sent on fast connections.
+// The reordering threshold is always kept at 1, such that the peer reports gaps immediately and loss detection isn't delayed.
+type ackFrequencyController struct {
+	peerMinAckDelay time.Duration
+	peerMaxAckDelay time.Duration
+	rttStats        *utils.RTTStats
+
+	nextSequenceNumber uint64
+	lastSent           time.Time
+	threshold          uint64 // the ack-eliciting threshold requested by the last ACK_FREQUENCY frame
+}
+
+func newAckFrequencyController(peerMinAckDelay, peerMaxAckDelay time.Duration, rttStats *utils.RTTStats) *ackFrequencyController {
+	return &ackFrequencyController{
+		peerMinAckDelay: peerMinAckDelay,
+		peerMaxAckDelay: max(peerMinAckDelay, peerMaxAckDelay),
+		rttStats:        rttStats,
+		threshold:       1, // the default ack-eliciting threshold, as defined in RFC 9000
+	}
+}
+
+// GetFrame returns a new ACK_FREQUENCY frame, if the ack-eliciting threshold derived
+// from the congestion window changed.
+// To avoid sending a frame for every small change of the congestion window, frames are sent at most once per RTT.
+func (c *ackFrequencyController) GetFrame(cwnd, maxDatagramSize protocol.ByteCount, now time.Time) *wire.AckFrequencyFrame {
+	if !c.lastSent.IsZero() && now.Sub(c.lastSent) < c.rttStats.SmoothedRTT() {
+		return nil
+	}
+	threshold := uint64(cwnd / (acksPerCongestionWindow * maxDatagramSize))
+	threshold = min(max(threshold, 2)-1, maxAckElicitingThreshold)
+	if threshold == c.threshold {
+		return nil
+	}
+	// Ask for ACKs at least 4 times per RTT, but never for a delay larger than the peer's max_ack_delay.
+	ackDelay := c.peerMaxAckDelay
+	if srtt := c.rttStats.SmoothedRTT(); srtt > 0 {
+		ackDelay = min(ackDelay, max(c.peerMinAckDelay, srtt/4))
+	}
+	f := &wire.AckFrequencyFrame{
+		SequenceNumber:        c.nextSequenceNumber,
+		AckElicitingThreshold: threshold,
+		RequestMaxAckDelay:    ackDelay,
+		ReorderingThreshold:   1,
+	}
+	c.nextSequenceNumber++
+	c.lastSent = now
+	c.threshold = threshold
+	return f
+}
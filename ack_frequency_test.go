@@ -0,0 +1,81 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK Frequency Controller", func() {
+	const maxDatagramSize = 1000
+
+	var (
+		c        *ackFrequencyController
+		rttStats *utils.RTTStats
+	)
+
+	BeforeEach(func() {
+		rttStats = &utils.RTTStats{}
+		rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+		c = newAckFrequencyController(2*time.Millisecond, 25*time.Millisecond, rttStats)
+	})
+
+	It("doesn't send a frame for small congestion windows", func() {
+		Expect(c.GetFrame(4*maxDatagramSize, maxDatagramSize, time.Now())).To(BeNil())
+		Expect(c.GetFrame(8*maxDatagramSize-1, maxDatagramSize, time.Now())).To(BeNil())
+	})
+
+	It("scales the ack-eliciting threshold with the congestion window", func() {
+		now := time.Now()
+		f := c.GetFrame(40*maxDatagramSize, maxDatagramSize, now)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeZero())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(9))
+		Expect(f.ReorderingThreshold).To(Equal(protocol.PacketNumber(1)))
+		Expect(f.RequestMaxAckDelay).To(Equal(25 * time.Millisecond))
+
+		// the threshold is capped
+		now = now.Add(time.Second)
+		f = c.GetFrame(1e9, maxDatagramSize, now)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeEquivalentTo(1))
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(maxAckElicitingThreshold))
+		// no new frame if the threshold didn't change
+		now = now.Add(time.Second)
+		Expect(c.GetFrame(2e9, maxDatagramSize, now)).To(BeNil())
+		// reset to the default threshold
+		f = c.GetFrame(4*maxDatagramSize, maxDatagramSize, now)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeEquivalentTo(2))
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(1))
+	})
+
+	It("sends at most one frame per RTT", func() {
+		now := time.Now()
+		Expect(c.GetFrame(40*maxDatagramSize, maxDatagramSize, now)).ToNot(BeNil())
+		Expect(c.GetFrame(80*maxDatagramSize, maxDatagramSize, now.Add(99*time.Millisecond))).To(BeNil())
+		Expect(c.GetFrame(80*maxDatagramSize, maxDatagramSize, now.Add(100*time.Millisecond))).ToNot(BeNil())
+	})
+
+	It("requests a smaller max_ack_delay on low-RTT connections", func() {
+		rttStats = &utils.RTTStats{}
+		rttStats.UpdateRTT(20*time.Millisecond, 0, time.Now())
+		c = newAckFrequencyController(2*time.Millisecond, 25*time.Millisecond, rttStats)
+		f := c.GetFrame(40*maxDatagramSize, maxDatagramSize, time.Now())
+		Expect(f).ToNot(BeNil())
+		Expect(f.RequestMaxAckDelay).To(Equal(5 * time.Millisecond))
+	})
+
+	It("never requests a delay smaller than the peer's min_ack_delay", func() {
+		rttStats = &utils.RTTStats{}
+		rttStats.UpdateRTT(time.Millisecond, 0, time.Now())
+		c = newAckFrequencyController(2*time.Millisecond, 25*time.Millisecond, rttStats)
+		f := c.GetFrame(40*maxDatagramSize, maxDatagramSize, time.Now())
+		Expect(f).ToNot(BeNil())
+		Expect(f.RequestMaxAckDelay).To(Equal(2 * time.Millisecond))
+	})
+})
@@ -2,6 +2,7 @@ package quic
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 )
@@ -13,6 +14,10 @@ type packetBuffer struct {
 	// It doesn't support concurrent use.
 	// It is > 1 when used for coalesced packet.
 	refCount int
+
+	// parent is set if Data is a slice of a buffer that is shared with other packetBuffers,
+	// e.g. when a datagram coalesced by GRO is split into its segments.
+	parent *sharedBuffer
 }
 
 // Split increases the refCount.
@@ -55,6 +60,13 @@ func (b *packetBuffer) Len() protocol.ByteCount { return protocol.ByteCount(len(
 func (b *packetBuffer) Cap() protocol.ByteCount { return protocol.ByteCount(cap(b.Data)) }
 
 func (b *packetBuffer) putBack() {
+	if b.parent != nil {
+		b.parent.Release()
+		b.parent = nil
+		b.Data = nil
+		segmentBufferPool.Put(b)
+		return
+	}
 	if cap(b.Data) == protocol.MaxPacketBufferSize {
 		bufferPool.Put(b)
 		return
@@ -66,7 +78,7 @@ func (b *packetBuffer) putBack() {
 	panic("putPacketBuffer called with packet of wrong size!")
 }
 
-var bufferPool, largeBufferPool sync.Pool
+var bufferPool, largeBufferPool, segmentBufferPool, sharedBufferPool sync.Pool
 
 func getPacketBuffer() *packetBuffer {
 	buf := bufferPool.Get().(*packetBuffer)
@@ -82,6 +94,43 @@ func getLargePacketBuffer() *packetBuffer {
 	return buf
 }
 
+// A sharedBuffer is a large buffer that is shared by multiple packetBuffers.
+// It is put back into the pool once all of them have been released.
+// Contrary to the packetBuffer, it can be released concurrently.
+type sharedBuffer struct {
+	Data []byte
+
+	refCount atomic.Int32
+}
+
+func getSharedBuffer() *sharedBuffer {
+	buf := sharedBufferPool.Get().(*sharedBuffer)
+	buf.refCount.Store(1)
+	buf.Data = buf.Data[:0]
+	return buf
+}
+
+// Slice returns a packetBuffer using Data[start:end].
+// The sharedBuffer is not put back into the pool before that packetBuffer has been released.
+func (b *sharedBuffer) Slice(start, end int) *packetBuffer {
+	b.refCount.Add(1)
+	buf := segmentBufferPool.Get().(*packetBuffer)
+	buf.refCount = 1
+	buf.Data = b.Data[start:end:end]
+	buf.parent = b
+	return buf
+}
+
+// Release decrements the reference counter,
+// and puts the buffer back into the pool once it reaches 0.
+func (b *sharedBuffer) Release() {
+	if refCount := b.refCount.Add(-1); refCount == 0 {
+		sharedBufferPool.Put(b)
+	} else if refCount < 0 {
+		panic("negative sharedBuffer refCount")
+	}
+}
+
 func init() {
 	bufferPool.New = func() any {
 		return &packetBuffer{Data: make([]byte, 0, protocol.MaxPacketBufferSize)}
@@ -89,4 +138,8 @@ func init() {
 	largeBufferPool.New = func() any {
 		return &packetBuffer{Data: make([]byte, 0, protocol.MaxLargePacketBufferSize)}
 	}
+	segmentBufferPool.New = func() any { return &packetBuffer{} }
+	sharedBufferPool.New = func() any {
+		return &sharedBuffer{Data: make([]byte, 0, protocol.MaxGROBufferSize)}
+	}
 }
@@ -56,4 +56,24 @@ var _ = Describe("Buffer Pool", func() {
 		buf.Decrement()
 		Expect(func() { buf.Decrement() }).To(Panic())
 	})
+
+	It("releases shared buffers once all slices have been released", func() {
+		shared := getSharedBuffer()
+		Expect(shared.Data).To(HaveCap(protocol.MaxGROBufferSize))
+		shared.Data = append(shared.Data, []byte("foobar")...)
+		buf1 := shared.Slice(0, 3)
+		buf2 := shared.Slice(3, 6)
+		Expect(buf1.Data).To(Equal([]byte("foo")))
+		Expect(buf1.Data).To(HaveCap(3))
+		Expect(buf2.Data).To(Equal([]byte("bar")))
+		shared.Release()
+		Expect(shared.refCount.Load()).To(BeEquivalentTo(2))
+		buf1.Split()
+		buf1.Decrement()
+		buf1.Release()
+		Expect(shared.refCount.Load()).To(BeEquivalentTo(1))
+		buf2.Release()
+		Expect(shared.refCount.Load()).To(BeZero())
+		Expect(func() { shared.Release() }).To(Panic())
+	})
 })
@@ -106,23 +106,36 @@ func populateConfig(config *Config) *Config {
 	}
 
 	return &Config{
-		GetConfigForClient:             config.GetConfigForClient,
-		Versions:                       versions,
-		HandshakeIdleTimeout:           handshakeIdleTimeout,
-		MaxIdleTimeout:                 idleTimeout,
-		KeepAlivePeriod:                config.KeepAlivePeriod,
-		InitialStreamReceiveWindow:     initialStreamReceiveWindow,
-		MaxStreamReceiveWindow:         maxStreamReceiveWindow,
-		InitialConnectionReceiveWindow: initialConnectionReceiveWindow,
-		MaxConnectionReceiveWindow:     maxConnectionReceiveWindow,
-		AllowConnectionWindowIncrease:  config.AllowConnectionWindowIncrease,
-		MaxIncomingStreams:             maxIncomingStreams,
-		MaxIncomingUniStreams:          maxIncomingUniStreams,
-		TokenStore:                     config.TokenStore,
-		EnableDatagrams:                config.EnableDatagrams,
-		InitialPacketSize:              initialPacketSize,
-		DisablePathMTUDiscovery:        config.DisablePathMTUDiscovery,
-		Allow0RTT:                      config.Allow0RTT,
-		Tracer:                         config.Tracer,
+		GetConfigForClient:               config.GetConfigForClient,
+		Versions:                         versions,
+		HandshakeIdleTimeout:             handshakeIdleTimeout,
+		MaxIdleTimeout:                   idleTimeout,
+		KeepAlivePeriod:                  config.KeepAlivePeriod,
+		InitialStreamReceiveWindow:       initialStreamReceiveWindow,
+		MaxStreamReceiveWindow:           maxStreamReceiveWindow,
+		InitialConnectionReceiveWindow:   initialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:       maxConnectionReceiveWindow,
+		AllowConnectionWindowIncrease:    config.AllowConnectionWindowIncrease,
+		MaxIncomingStreams:               maxIncomingStreams,
+		MaxIncomingUniStreams:            maxIncomingUniStreams,
+		TokenStore:                       config.TokenStore,
+		EnableDatagrams:                  config.EnableDatagrams,
+		EnableMultipath:                  config.EnableMultipath,
+		EnableStreamResetPartialDelivery: config.EnableStreamResetPartialDelivery,
+		EnableAckFrequency:               config.EnableAckFrequency,
+		EnableQUICBitGreasing:            config.EnableQUICBitGreasing,
+		PathScheduler:                    config.PathScheduler,
+		StreamScheduler:                  config.StreamScheduler,
+		CongestionControl:                config.CongestionControl,
+		InitialPacketSize:                initialPacketSize,
+		DisablePathMTUDiscovery:          config.DisablePathMTUDiscovery,
+		EnableActiveMigration:            config.EnableActiveMigration,
+		AllowMigration:                   config.AllowMigration,
+		Allow0RTT:                        config.Allow0RTT,
+		Accept0RTT:                       config.Accept0RTT,
+		GetSessionTicketData:             config.GetSessionTicketData,
+		ValidateSessionTicketData:        config.ValidateSessionTicketData,
+		ZeroRTTAntiReplay:                config.ZeroRTTAntiReplay,
+		Tracer:                           config.Tracer,
 	}
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/logging"
 	"github.com/quic-go/quic-go/quicvarint"
@@ -83,7 +85,7 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer":
+			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "AllowMigration", "Accept0RTT", "GetSessionTicketData", "ValidateSessionTicketData", "PathScheduler", "StreamScheduler", "CongestionControl", "Tracer":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]Version{1, 2, 3}))
@@ -115,14 +117,26 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(time.Second))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "EnableMultipath":
+				f.Set(reflect.ValueOf(true))
+			case "EnableStreamResetPartialDelivery":
+				f.Set(reflect.ValueOf(true))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "EnableQUICBitGreasing":
+				f.Set(reflect.ValueOf(true))
 			case "DisableVersionNegotiationPackets":
 				f.Set(reflect.ValueOf(true))
 			case "InitialPacketSize":
 				f.Set(reflect.ValueOf(uint16(1350)))
 			case "DisablePathMTUDiscovery":
 				f.Set(reflect.ValueOf(true))
+			case "EnableActiveMigration":
+				f.Set(reflect.ValueOf(true))
 			case "Allow0RTT":
 				f.Set(reflect.ValueOf(true))
+			case "ZeroRTTAntiReplay":
+				f.Set(reflect.ValueOf(NewAntiReplayStore()))
 			default:
 				Fail(fmt.Sprintf("all fields must be accounted for, but saw unknown field %q", fn))
 			}
@@ -137,10 +151,18 @@ var _ = Describe("Config", func() {
 
 	Context("cloning", func() {
 		It("clones function fields", func() {
-			var calledAllowConnectionWindowIncrease, calledTracer bool
+			var calledAllowConnectionWindowIncrease, calledAllowMigration, calledAccept0RTT, calledPathScheduler, calledStreamScheduler, calledCongestionControl, calledTracer bool
 			c1 := &Config{
 				GetConfigForClient:            func(info *ClientHelloInfo) (*Config, error) { return nil, errors.New("nope") },
 				AllowConnectionWindowIncrease: func(Connection, uint64) bool { calledAllowConnectionWindowIncrease = true; return true },
+				AllowMigration:                func(Connection, net.Addr) bool { calledAllowMigration = true; return true },
+				Accept0RTT:                    func(*ZeroRTTInfo) bool { calledAccept0RTT = true; return true },
+				PathScheduler:                 func() PathScheduler { calledPathScheduler = true; return nil },
+				StreamScheduler:               func() StreamScheduler { calledStreamScheduler = true; return nil },
+				CongestionControl: func(*congestion.RTTStats, congestion.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+					calledCongestionControl = true
+					return nil
+				},
 				Tracer: func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer {
 					calledTracer = true
 					return nil
@@ -149,6 +171,16 @@ var _ = Describe("Config", func() {
 			c2 := c1.Clone()
 			c2.AllowConnectionWindowIncrease(nil, 1234)
 			Expect(calledAllowConnectionWindowIncrease).To(BeTrue())
+			c2.AllowMigration(nil, nil)
+			Expect(calledAllowMigration).To(BeTrue())
+			c2.Accept0RTT(&ZeroRTTInfo{})
+			Expect(calledAccept0RTT).To(BeTrue())
+			c2.PathScheduler()
+			Expect(calledPathScheduler).To(BeTrue())
+			c2.StreamScheduler()
+			Expect(calledStreamScheduler).To(BeTrue())
+			c2.CongestionControl(nil, 0)
+			Expect(calledCongestionControl).To(BeTrue())
 			_, err := c2.GetConfigForClient(&ClientHelloInfo{})
 			Expect(err).To(MatchError("nope"))
 			c2.Tracer(context.Background(), logging.PerspectiveClient, protocol.ConnectionID{})
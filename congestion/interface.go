@@ -0,0 +1,52 @@
+// Package congestion defines the interface between quic-go and congestion controllers.
+// This package should not be considered stable
+package congestion
+
+import (
+	"github.com/quic-go/quic-go/internal/congestion"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+)
+
+type (
+	// A ByteCount is used to count bytes.
+	ByteCount = protocol.ByteCount
+	// A PacketNumber is a QUIC packet number.
+	PacketNumber = protocol.PacketNumber
+	// RTTStats contains the RTT estimate of a path.
+	// It is updated by quic-go when an ACK is received, before the congestion controller is notified.
+	RTTStats = utils.RTTStats
+
+	// A SendAlgorithm is a congestion controller.
+	// Its methods are called from the connection's run loop, and must not block.
+	SendAlgorithm = congestion.SendAlgorithm
+	// A SendAlgorithmWithDebugInfos is a SendAlgorithm that exposes its state.
+	// The congestion window is used for logging and tracing.
+	SendAlgorithmWithDebugInfos = congestion.SendAlgorithmWithDebugInfos
+	// A DiscardingSendAlgorithm is a congestion controller that keeps state for the packets in flight.
+	// If the congestion controller implements this interface, quic-go calls OnPacketDiscarded for packets
+	// that won't be acknowledged or declared lost, e.g. when the Initial and Handshake keys are dropped.
+	DiscardingSendAlgorithm = congestion.DiscardingSendAlgorithm
+	// A TracingSendAlgorithm is a congestion controller that reports its state to the connection tracer.
+	// If the congestion controller implements this interface, quic-go calls SetTracer after creating it.
+	TracingSendAlgorithm = congestion.TracingSendAlgorithm
+)
+
+// NewCubic creates a congestion controller using the CUBIC algorithm (RFC 9438).
+// It paces packets sent at a rate derived from the congestion window and the smoothed RTT.
+func NewCubic(rttStats *RTTStats, initialMaxDatagramSize ByteCount) SendAlgorithmWithDebugInfos {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, rttStats, initialMaxDatagramSize, false, nil)
+}
+
+// NewReno creates a congestion controller using the NewReno algorithm (RFC 9002).
+// This is the congestion controller that quic-go uses by default.
+func NewReno(rttStats *RTTStats, initialMaxDatagramSize ByteCount) SendAlgorithmWithDebugInfos {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, rttStats, initialMaxDatagramSize, true, nil)
+}
+
+// NewBBR creates a congestion controller using the BBR algorithm (draft-ietf-ccwg-bbr).
+// Instead of reacting to every packet loss, BBR builds a model of the bottleneck bandwidth and the minimum RTT of the path,
+// which makes it a good choice for paths with random (non-congestive) loss.
+func NewBBR(rttStats *RTTStats, initialMaxDatagramSize ByteCount) SendAlgorithmWithDebugInfos {
+	return congestion.NewBBRSender(congestion.DefaultClock{}, rttStats, initialMaxDatagramSize, nil)
+}
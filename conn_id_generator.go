@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -8,6 +9,15 @@ import (
 	"github.com/quic-go/quic-go/internal/wire"
 )
 
+// connRunnerCallbacks are used to register connection IDs with a connRunner,
+// in addition to the one the connection was created on.
+type connRunnerCallbacks struct {
+	AddConnectionID    func(protocol.ConnectionID)
+	RemoveConnectionID func(protocol.ConnectionID)
+	RetireConnectionID func(protocol.ConnectionID)
+	ReplaceWithClosed  func([]protocol.ConnectionID, []byte)
+}
+
 type connIDGenerator struct {
 	generator  ConnectionIDGenerator
 	highestSeq uint64
@@ -21,6 +31,33 @@ type connIDGenerator struct {
 	retireConnectionID     func(protocol.ConnectionID)
 	replaceWithClosed      func([]protocol.ConnectionID, []byte)
 	queueControlFrame      func(wire.Frame)
+
+	// connRunners used by additional paths
+	pathRunners map[pathRunnerKey]connRunnerCallbacks
+
+	// connection IDs issued for paths other than path 0 of a multipath connection
+	multipathConnIDs map[protocol.PathID]*pathConnIDs // initialized lazily
+}
+
+// The kind of a path that a connRunner is registered for.
+// Paths of different kinds are numbered independently.
+type pathRunnerKind uint8
+
+const (
+	pathRunnerPreferredAddress pathRunnerKind = iota
+	pathRunnerMigration
+	pathRunnerMultipath
+)
+
+// A pathRunnerKey identifies the path that a connRunner is registered for.
+type pathRunnerKey struct {
+	kind pathRunnerKind
+	id   uint64
+}
+
+type pathConnIDs struct {
+	nextSeq uint64
+	active  map[uint64]protocol.ConnectionID // nil once the path was abandoned
 }
 
 func newConnIDGenerator(
@@ -57,8 +94,7 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
 	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
+	// Both of them are contained in activeSrcConnIDs.
 	for i := uint64(len(m.activeSrcConnIDs)); i < min(limit, protocol.MaxIssuedConnectionIDs); i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
@@ -67,6 +103,22 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	return nil
 }
 
+// IssuePreferredAddressConnID issues the connection ID sent in the preferred_address transport parameter.
+// This connection ID has sequence number 1, so this needs to be called before any other connection ID is issued.
+func (m *connIDGenerator) IssuePreferredAddressConnID() (protocol.ConnectionID, protocol.StatelessResetToken, error) {
+	if m.highestSeq != 0 {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, errors.New("preferred address connection ID must have sequence number 1")
+	}
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, err
+	}
+	m.highestSeq = 1
+	m.activeSrcConnIDs[1] = connID
+	m.add(connID)
+	return connID, m.getStatelessResetToken(connID), nil
+}
+
 func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.ConnectionID) error {
 	if seq > m.highestSeq {
 		return &qerr.TransportError{
@@ -85,7 +137,7 @@ func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.Connect
 			ErrorMessage: fmt.Sprintf("retired connection ID %d (%s), which was used as the Destination Connection ID on this packet", seq, connID),
 		}
 	}
-	m.retireConnectionID(connID)
+	m.retire(connID)
 	delete(m.activeSrcConnIDs, seq)
 	// Don't issue a replacement for the initial connection ID.
 	if seq == 0 {
@@ -100,7 +152,7 @@ func (m *connIDGenerator) issueNewConnID() error {
 		return err
 	}
 	m.activeSrcConnIDs[m.highestSeq+1] = connID
-	m.addConnectionID(connID)
+	m.add(connID)
 	m.queueControlFrame(&wire.NewConnectionIDFrame{
 		SequenceNumber:      m.highestSeq + 1,
 		ConnectionID:        connID,
@@ -112,21 +164,28 @@ func (m *connIDGenerator) issueNewConnID() error {
 
 func (m *connIDGenerator) SetHandshakeComplete() {
 	if m.initialClientDestConnID != nil {
-		m.retireConnectionID(*m.initialClientDestConnID)
+		m.retire(*m.initialClientDestConnID)
 		m.initialClientDestConnID = nil
 	}
 }
 
-func (m *connIDGenerator) RemoveAll() {
-	if m.initialClientDestConnID != nil {
-		m.removeConnectionID(*m.initialClientDestConnID)
+func (m *connIDGenerator) add(connID protocol.ConnectionID) {
+	m.addConnectionID(connID)
+	for _, r := range m.pathRunners {
+		r.AddConnectionID(connID)
 	}
-	for _, connID := range m.activeSrcConnIDs {
-		m.removeConnectionID(connID)
+}
+
+func (m *connIDGenerator) retire(connID protocol.ConnectionID) {
+	m.retireConnectionID(connID)
+	for _, r := range m.pathRunners {
+		r.RetireConnectionID(connID)
 	}
 }
 
-func (m *connIDGenerator) ReplaceWithClosed(connClose []byte) {
+// allConnIDs returns all connection IDs that packets might be sent to,
+// including the connection IDs issued for multipath paths.
+func (m *connIDGenerator) allConnIDs() []protocol.ConnectionID {
 	connIDs := make([]protocol.ConnectionID, 0, len(m.activeSrcConnIDs)+1)
 	if m.initialClientDestConnID != nil {
 		connIDs = append(connIDs, *m.initialClientDestConnID)
@@ -134,5 +193,148 @@ func (m *connIDGenerator) ReplaceWithClosed(connClose []byte) {
 	for _, connID := range m.activeSrcConnIDs {
 		connIDs = append(connIDs, connID)
 	}
+	for _, p := range m.multipathConnIDs {
+		for _, connID := range p.active {
+			connIDs = append(connIDs, connID)
+		}
+	}
+	return connIDs
+}
+
+func (m *connIDGenerator) RemoveAll() {
+	connIDs := m.allConnIDs()
+	for _, connID := range connIDs {
+		m.removeConnectionID(connID)
+	}
+	for _, r := range m.pathRunners {
+		for _, connID := range connIDs {
+			r.RemoveConnectionID(connID)
+		}
+	}
+}
+
+func (m *connIDGenerator) ReplaceWithClosed(connClose []byte) {
+	connIDs := m.allConnIDs()
 	m.replaceWithClosed(connIDs, connClose)
+	for _, r := range m.pathRunners {
+		r.ReplaceWithClosed(connIDs, connClose)
+	}
+}
+
+// AddConnRunner registers all active connection IDs with a connRunner used by an additional path.
+// Connection IDs issued or retired later on are registered and retired as well.
+func (m *connIDGenerator) AddConnRunner(id pathRunnerKey, r connRunnerCallbacks) {
+	if m.pathRunners == nil {
+		m.pathRunners = make(map[pathRunnerKey]connRunnerCallbacks)
+	}
+	m.pathRunners[id] = r
+	for _, connID := range m.allConnIDs() {
+		r.AddConnectionID(connID)
+	}
+}
+
+// RemoveConnRunner retires all active connection IDs from the connRunner used by a path that was abandoned.
+func (m *connIDGenerator) RemoveConnRunner(id pathRunnerKey) {
+	r, ok := m.pathRunners[id]
+	if !ok {
+		return
+	}
+	delete(m.pathRunners, id)
+	for _, connID := range m.allConnIDs() {
+		r.RetireConnectionID(connID)
+	}
+}
+
+// IssuePathConnIDs issues connection IDs for a path of a multipath connection,
+// such that the peer has numConnIDs connection IDs available for this path.
+func (m *connIDGenerator) IssuePathConnIDs(id protocol.PathID, numConnIDs int) error {
+	if m.multipathConnIDs == nil {
+		m.multipathConnIDs = make(map[protocol.PathID]*pathConnIDs)
+	}
+	p, ok := m.multipathConnIDs[id]
+	if !ok {
+		p = &pathConnIDs{active: make(map[uint64]protocol.ConnectionID, numConnIDs)}
+		m.multipathConnIDs[id] = p
+	}
+	if p.active == nil {
+		return nil
+	}
+	for len(p.active) < numConnIDs {
+		if err := m.issueNewPathConnID(id, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *connIDGenerator) issueNewPathConnID(id protocol.PathID, p *pathConnIDs) error {
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return err
+	}
+	seq := p.nextSeq
+	p.nextSeq++
+	p.active[seq] = connID
+	m.add(connID)
+	m.queueControlFrame(&wire.MPNewConnectionIDFrame{
+		PathID: id,
+		NewConnectionIDFrame: wire.NewConnectionIDFrame{
+			SequenceNumber:      seq,
+			ConnectionID:        connID,
+			StatelessResetToken: m.getStatelessResetToken(connID),
+		},
+	})
+	return nil
+}
+
+// RetirePathConnID retires a connection ID issued for a path of a multipath connection,
+// and issues a new connection ID for that path.
+func (m *connIDGenerator) RetirePathConnID(id protocol.PathID, seq uint64, sentWithDestConnID protocol.ConnectionID) error {
+	p, ok := m.multipathConnIDs[id]
+	if !ok || seq >= p.nextSeq {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("retired connection ID %d for path %d, which was never issued", seq, id),
+		}
+	}
+	connID, ok := p.active[seq]
+	// We might already have deleted this connection ID, if this is a duplicate frame.
+	if !ok {
+		return nil
+	}
+	if connID == sentWithDestConnID {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("retired connection ID %d (%s), which was used as the Destination Connection ID on this packet", seq, connID),
+		}
+	}
+	m.retire(connID)
+	delete(p.active, seq)
+	return m.issueNewPathConnID(id, p)
+}
+
+// PathForConnID returns the multipath path that a connection ID was issued for.
+// It returns false for connection IDs belonging to path 0.
+func (m *connIDGenerator) PathForConnID(connID protocol.ConnectionID) (protocol.PathID, bool) {
+	for id, p := range m.multipathConnIDs {
+		for _, c := range p.active {
+			if c == connID {
+				return id, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// RemovePath retires all connection IDs issued for a path that was abandoned.
+// No new connection IDs will be issued for this path.
+func (m *connIDGenerator) RemovePath(id protocol.PathID) {
+	p, ok := m.multipathConnIDs[id]
+	if !ok {
+		return
+	}
+	for _, connID := range p.active {
+		m.retire(connID)
+	}
+	p.active = nil
 }
@@ -72,6 +72,29 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(queuedFrames).To(HaveLen(protocol.MaxIssuedConnectionIDs - 1))
 	})
 
+	It("issues the connection ID for the preferred address", func() {
+		connID, token, err := g.IssuePreferredAddressConnID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(7))
+		Expect(token).To(Equal(connIDToToken(connID)))
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+		Expect(queuedFrames).To(BeEmpty())
+		// the preferred address connection ID counts towards the limit
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		Expect(addedConnIDs).To(HaveLen(3))
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(2))
+		// the preferred address connection ID can be retired
+		Expect(g.Retire(1, protocol.ParseConnectionID([]byte{1, 2, 3, 4}))).To(Succeed())
+		Expect(retiredConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+	})
+
+	It("refuses to issue the connection ID for the preferred address after issuing other connection IDs", func() {
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		_, _, err := g.IssuePreferredAddressConnID()
+		Expect(err).To(HaveOccurred())
+	})
+
 	// SetMaxActiveConnIDs is called twice when dialing a 0-RTT connection:
 	// once for the restored from the old connections, once when we receive the transport parameters
 	Context("dealing with 0-RTT", func() {
@@ -184,4 +207,149 @@ var _ = Describe("Connection ID Generator", func() {
 			Expect(replacedWithClosed).To(ContainElement(nf.ConnectionID))
 		}
 	})
+
+	Context("additional paths", func() {
+		var (
+			pathAddedConnIDs       []protocol.ConnectionID
+			pathRetiredConnIDs     []protocol.ConnectionID
+			pathRemovedConnIDs     []protocol.ConnectionID
+			pathReplacedWithClosed []protocol.ConnectionID
+		)
+
+		BeforeEach(func() {
+			pathAddedConnIDs = nil
+			pathRetiredConnIDs = nil
+			pathRemovedConnIDs = nil
+			pathReplacedWithClosed = nil
+		})
+
+		addConnRunner := func() {
+			g.AddConnRunner(pathRunnerKey{kind: pathRunnerMigration, id: 1}, connRunnerCallbacks{
+				AddConnectionID:    func(c protocol.ConnectionID) { pathAddedConnIDs = append(pathAddedConnIDs, c) },
+				RemoveConnectionID: func(c protocol.ConnectionID) { pathRemovedConnIDs = append(pathRemovedConnIDs, c) },
+				RetireConnectionID: func(c protocol.ConnectionID) { pathRetiredConnIDs = append(pathRetiredConnIDs, c) },
+				ReplaceWithClosed: func(cs []protocol.ConnectionID, _ []byte) {
+					pathReplacedWithClosed = append(pathReplacedWithClosed, cs...)
+				},
+			})
+		}
+
+		It("registers all active connection IDs", func() {
+			Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+			addConnRunner()
+			Expect(pathAddedConnIDs).To(HaveLen(4)) // initial conn ID, initial client dest conn id, and newly issued ones
+			Expect(pathAddedConnIDs).To(ContainElement(initialConnID))
+			Expect(pathAddedConnIDs).To(ContainElement(initialClientDestConnID))
+			for _, f := range queuedFrames {
+				Expect(pathAddedConnIDs).To(ContainElement(f.(*wire.NewConnectionIDFrame).ConnectionID))
+			}
+		})
+
+		It("registers and retires connection IDs issued later", func() {
+			addConnRunner()
+			Expect(g.SetMaxActiveConnIDs(2)).To(Succeed())
+			Expect(queuedFrames).To(HaveLen(1))
+			newConnID := queuedFrames[0].(*wire.NewConnectionIDFrame).ConnectionID
+			Expect(pathAddedConnIDs).To(ContainElement(newConnID))
+			Expect(g.Retire(1, protocol.ConnectionID{})).To(Succeed())
+			Expect(pathRetiredConnIDs).To(Equal([]protocol.ConnectionID{newConnID}))
+			Expect(pathAddedConnIDs).To(ContainElement(queuedFrames[1].(*wire.NewConnectionIDFrame).ConnectionID))
+		})
+
+		It("removes and replaces connection IDs", func() {
+			addConnRunner()
+			g.RemoveAll()
+			Expect(pathRemovedConnIDs).To(ConsistOf(initialConnID, initialClientDestConnID))
+			g.ReplaceWithClosed([]byte("foobar"))
+			Expect(pathReplacedWithClosed).To(ConsistOf(initialConnID, initialClientDestConnID))
+		})
+
+		It("retires all connection IDs when the connRunner is removed", func() {
+			addConnRunner()
+			g.RemoveConnRunner(pathRunnerKey{kind: pathRunnerMigration, id: 1})
+			Expect(pathRetiredConnIDs).To(ConsistOf(initialConnID, initialClientDestConnID))
+			// connection IDs issued later on are not registered any more
+			pathAddedConnIDs = nil
+			Expect(g.SetMaxActiveConnIDs(2)).To(Succeed())
+			Expect(pathAddedConnIDs).To(BeEmpty())
+		})
+	})
+
+	Context("multipath", func() {
+		It("issues connection IDs for a path", func() {
+			Expect(g.IssuePathConnIDs(2, 2)).To(Succeed())
+			Expect(queuedFrames).To(HaveLen(2))
+			Expect(addedConnIDs).To(HaveLen(2))
+			for i, f := range queuedFrames {
+				nf := f.(*wire.MPNewConnectionIDFrame)
+				Expect(nf.PathID).To(Equal(protocol.PathID(2)))
+				Expect(nf.SequenceNumber).To(BeEquivalentTo(i))
+				Expect(nf.ConnectionID).To(Equal(addedConnIDs[i]))
+				Expect(nf.StatelessResetToken).To(Equal(connIDToToken(nf.ConnectionID)))
+				id, ok := g.PathForConnID(nf.ConnectionID)
+				Expect(ok).To(BeTrue())
+				Expect(id).To(Equal(protocol.PathID(2)))
+			}
+			_, ok := g.PathForConnID(initialConnID)
+			Expect(ok).To(BeFalse())
+			// calling it again doesn't issue more connection IDs
+			Expect(g.IssuePathConnIDs(2, 2)).To(Succeed())
+			Expect(queuedFrames).To(HaveLen(2))
+		})
+
+		It("issues new connection IDs for a path, when old ones are retired", func() {
+			Expect(g.IssuePathConnIDs(1, 2)).To(Succeed())
+			connID := queuedFrames[0].(*wire.MPNewConnectionIDFrame).ConnectionID
+			queuedFrames = nil
+			Expect(g.RetirePathConnID(1, 0, protocol.ConnectionID{})).To(Succeed())
+			Expect(retiredConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+			Expect(queuedFrames).To(HaveLen(1))
+			nf := queuedFrames[0].(*wire.MPNewConnectionIDFrame)
+			Expect(nf.PathID).To(Equal(protocol.PathID(1)))
+			Expect(nf.SequenceNumber).To(BeEquivalentTo(2))
+			_, ok := g.PathForConnID(connID)
+			Expect(ok).To(BeFalse())
+			// duplicate retirements are ignored
+			Expect(g.RetirePathConnID(1, 0, protocol.ConnectionID{})).To(Succeed())
+			Expect(queuedFrames).To(HaveLen(1))
+		})
+
+		It("errors when the peer retires a connection ID for a path that wasn't issued", func() {
+			Expect(g.IssuePathConnIDs(1, 2)).To(Succeed())
+			Expect(g.RetirePathConnID(1, 2, protocol.ConnectionID{})).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: "retired connection ID 2 for path 1, which was never issued",
+			}))
+			Expect(g.RetirePathConnID(3, 0, protocol.ConnectionID{})).To(HaveOccurred())
+		})
+
+		It("errors when the peer retires a connection ID in a packet sent to that connection ID", func() {
+			Expect(g.IssuePathConnIDs(1, 2)).To(Succeed())
+			connID := queuedFrames[1].(*wire.MPNewConnectionIDFrame).ConnectionID
+			Expect(g.RetirePathConnID(1, 1, connID)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: fmt.Sprintf("retired connection ID 1 (%s), which was used as the Destination Connection ID on this packet", connID),
+			}))
+		})
+
+		It("retires all connection IDs when a path is removed", func() {
+			Expect(g.IssuePathConnIDs(1, 2)).To(Succeed())
+			g.RemovePath(1)
+			Expect(retiredConnIDs).To(ConsistOf(addedConnIDs))
+			// no new connection IDs are issued for this path
+			queuedFrames = nil
+			Expect(g.IssuePathConnIDs(1, 2)).To(Succeed())
+			Expect(queuedFrames).To(BeEmpty())
+		})
+
+		It("removes and replaces the connection IDs issued for paths", func() {
+			Expect(g.IssuePathConnIDs(1, 2)).To(Succeed())
+			g.RemoveAll()
+			Expect(removedConnIDs).To(HaveLen(4)) // initial conn ID, initial client dest conn id, and 2 path connection IDs
+			Expect(removedConnIDs).To(ContainElements(addedConnIDs))
+			g.ReplaceWithClosed([]byte("foobar"))
+			Expect(replacedWithClosed).To(HaveLen(4))
+			Expect(replacedWithClosed).To(ContainElements(addedConnIDs))
+		})
+	})
 })
@@ -25,6 +25,12 @@ type connIDManager struct {
 	activeConnectionID        protocol.ConnectionID
 	activeStatelessResetToken *protocol.StatelessResetToken
 
+	// Connection IDs used for probing new paths.
+	// They are taken from the queue, and not used for any other path.
+	pathProbing map[pathID]newConnID // initialized lazily
+	// The highest sequence number of a connection ID that was used for probing.
+	highestProbingSequenceNumber uint64
+
 	// We change the connection ID after sending on average
 	// protocol.PacketsPerConnectionID packets. The actual value is randomized
 	// hide the packet loss rate from on-path observers.
@@ -51,6 +57,26 @@ func newConnIDManager(
 	}
 }
 
+// newPathConnIDManager creates the connIDManager for a path of a multipath connection.
+// The first connection ID the peer issued for the path becomes the active connection ID.
+func newPathConnIDManager(
+	f *wire.NewConnectionIDFrame,
+	addStatelessResetToken func(protocol.StatelessResetToken),
+	removeStatelessResetToken func(protocol.StatelessResetToken),
+	queueControlFrame func(wire.Frame),
+) *connIDManager {
+	h := newConnIDManager(f.ConnectionID, addStatelessResetToken, removeStatelessResetToken, queueControlFrame)
+	h.setActiveConnectionID(newConnID{
+		SequenceNumber:      f.SequenceNumber,
+		ConnectionID:        f.ConnectionID,
+		StatelessResetToken: f.StatelessResetToken,
+	})
+	h.addStatelessResetToken(f.StatelessResetToken)
+	// Paths are only used after completion of the handshake.
+	h.handshakeComplete = true
+	return h
+}
+
 func (h *connIDManager) AddFromPreferredAddress(connID protocol.ConnectionID, resetToken protocol.StatelessResetToken) error {
 	return h.addConnectionID(1, connID, resetToken)
 }
@@ -59,7 +85,7 @@ func (h *connIDManager) Add(f *wire.NewConnectionIDFrame) error {
 	if err := h.add(f); err != nil {
 		return err
 	}
-	if h.queue.Len() >= protocol.MaxActiveConnectionIDs {
+	if h.queue.Len()+len(h.pathProbing) >= protocol.MaxActiveConnectionIDs {
 		return &qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}
 	}
 	return nil
@@ -89,12 +115,35 @@ func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 			})
 			h.queue.Remove(el)
 		}
+		for id, entry := range h.pathProbing {
+			if entry.SequenceNumber >= f.RetirePriorTo {
+				continue
+			}
+			h.queueControlFrame(&wire.RetireConnectionIDFrame{
+				SequenceNumber: entry.SequenceNumber,
+			})
+			h.removeStatelessResetToken(entry.StatelessResetToken)
+			delete(h.pathProbing, id)
+		}
 		h.highestRetired = f.RetirePriorTo
 	}
 
 	if f.SequenceNumber == h.activeSequenceNumber {
 		return nil
 	}
+	for _, entry := range h.pathProbing {
+		if entry.SequenceNumber == f.SequenceNumber {
+			return nil
+		}
+	}
+	// Connection IDs are taken from the front of the queue when probing a path.
+	// If this connection ID is not in the queue, it was used for a path that was abandoned since.
+	if f.SequenceNumber <= h.highestProbingSequenceNumber && !h.isQueued(f.SequenceNumber) {
+		h.queueControlFrame(&wire.RetireConnectionIDFrame{
+			SequenceNumber: f.SequenceNumber,
+		})
+		return nil
+	}
 
 	if err := h.addConnectionID(f.SequenceNumber, f.ConnectionID, f.StatelessResetToken); err != nil {
 		return err
@@ -108,6 +157,15 @@ func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 	return nil
 }
 
+func (h *connIDManager) isQueued(seq uint64) bool {
+	for el := h.queue.Front(); el != nil; el = el.Next() {
+		if el.Value.SequenceNumber == seq {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID, resetToken protocol.StatelessResetToken) error {
 	// insert a new element at the end
 	if h.queue.Len() == 0 || h.queue.Back().Value.SequenceNumber < seq {
@@ -142,6 +200,13 @@ func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID
 }
 
 func (h *connIDManager) updateConnectionID() {
+	h.retireActiveConnectionID()
+	front := h.queue.Remove(h.queue.Front())
+	h.setActiveConnectionID(front)
+	h.addStatelessResetToken(*h.activeStatelessResetToken)
+}
+
+func (h *connIDManager) retireActiveConnectionID() {
 	h.queueControlFrame(&wire.RetireConnectionIDFrame{
 		SequenceNumber: h.activeSequenceNumber,
 	})
@@ -149,20 +214,23 @@ func (h *connIDManager) updateConnectionID() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+}
 
-	front := h.queue.Remove(h.queue.Front())
-	h.activeSequenceNumber = front.SequenceNumber
-	h.activeConnectionID = front.ConnectionID
-	h.activeStatelessResetToken = &front.StatelessResetToken
+func (h *connIDManager) setActiveConnectionID(c newConnID) {
+	h.activeSequenceNumber = c.SequenceNumber
+	h.activeConnectionID = c.ConnectionID
+	h.activeStatelessResetToken = &c.StatelessResetToken
 	h.packetsSinceLastChange = 0
 	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
-	h.addStatelessResetToken(*h.activeStatelessResetToken)
 }
 
 func (h *connIDManager) Close() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+	for _, entry := range h.pathProbing {
+		h.removeStatelessResetToken(entry.StatelessResetToken)
+	}
 }
 
 // is called when the server performs a Retry
@@ -212,3 +280,53 @@ func (h *connIDManager) Get() protocol.ConnectionID {
 func (h *connIDManager) SetHandshakeComplete() {
 	h.handshakeComplete = true
 }
+
+// GetConnIDForPath returns the connection ID used for probing the path with the given ID.
+// A new connection ID is taken from the queue the first time it is called for a path.
+// It returns false if no unused connection ID is available.
+func (h *connIDManager) GetConnIDForPath(id pathID) (protocol.ConnectionID, bool) {
+	// If we're using zero-length connection IDs, there's nothing to rotate.
+	if h.activeConnectionID.Len() == 0 {
+		return protocol.ConnectionID{}, true
+	}
+	if entry, ok := h.pathProbing[id]; ok {
+		return entry.ConnectionID, true
+	}
+	if h.queue.Len() == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	if h.pathProbing == nil {
+		h.pathProbing = make(map[pathID]newConnID)
+	}
+	front := h.queue.Remove(h.queue.Front())
+	h.pathProbing[id] = front
+	h.highestProbingSequenceNumber = max(h.highestProbingSequenceNumber, front.SequenceNumber)
+	h.addStatelessResetToken(front.StatelessResetToken)
+	return front.ConnectionID, true
+}
+
+// RetireConnIDForPath retires the connection ID used for probing a path that was abandoned.
+func (h *connIDManager) RetireConnIDForPath(id pathID) {
+	entry, ok := h.pathProbing[id]
+	if !ok {
+		return
+	}
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: entry.SequenceNumber,
+	})
+	h.removeStatelessResetToken(entry.StatelessResetToken)
+	delete(h.pathProbing, id)
+}
+
+// SwitchToPath is called when the connection migrates to the path with the given ID.
+// The connection ID used for probing this path becomes the active connection ID,
+// and the connection ID used on the old path is retired.
+func (h *connIDManager) SwitchToPath(id pathID) {
+	entry, ok := h.pathProbing[id]
+	if !ok {
+		return
+	}
+	delete(h.pathProbing, id)
+	h.retireActiveConnectionID()
+	h.setActiveConnectionID(entry)
+}
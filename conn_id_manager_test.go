@@ -361,4 +361,126 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens).To(HaveLen(1))
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}))
 	})
+
+	Context("connection IDs for path probing", func() {
+		BeforeEach(func() {
+			m.SetStatelessResetToken(protocol.StatelessResetToken{0xff})
+			for i := uint64(1); i <= 3; i++ {
+				Expect(m.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      i,
+					ConnectionID:        protocol.ParseConnectionID([]byte{byte(i), byte(i), byte(i), byte(i)}),
+					StatelessResetToken: protocol.StatelessResetToken{byte(i)},
+				})).To(Succeed())
+			}
+			frameQueue = nil
+		})
+
+		It("uses a separate connection ID for every path", func() {
+			connID1, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID1).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1}))
+			connID2, ok := m.GetConnIDForPath(2)
+			Expect(ok).To(BeTrue())
+			Expect(connID2).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+			// the same path always uses the same connection ID
+			connID, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(connID1))
+			// the active connection ID is not affected
+			Expect(m.Get()).To(Equal(initialConnID))
+			Expect(frameQueue).To(BeEmpty())
+		})
+
+		It("doesn't return a connection ID if none is available", func() {
+			for i := pathID(1); i <= 3; i++ {
+				_, ok := m.GetConnIDForPath(i)
+				Expect(ok).To(BeTrue())
+			}
+			_, ok := m.GetConnIDForPath(4)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("retires the connection ID of an abandoned path", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			m.RetireConnIDForPath(1)
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1}}))
+			// retransmissions of the NEW_CONNECTION_ID frame are ignored
+			frameQueue = nil
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 1, 1, 1}),
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			})).To(Succeed())
+			connID, ok := m.GetConnIDForPath(2)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+		})
+
+		It("ignores retransmissions of NEW_CONNECTION_ID frames for connection IDs used for probing", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 1, 1, 1}),
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			})).To(Succeed())
+			connID, ok := m.GetConnIDForPath(2)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+		})
+
+		It("switches to the connection ID of a path", func() {
+			_, ok := m.GetConnIDForPath(2)
+			Expect(ok).To(BeTrue())
+			m.SwitchToPath(2)
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{0xff}}))
+		})
+
+		It("retires connection IDs used for probing when the peer asks for it", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      4,
+				RetirePriorTo:       2,
+				ConnectionID:        protocol.ParseConnectionID([]byte{4, 4, 4, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{4},
+			})).To(Succeed())
+			Expect(frameQueue).To(ContainElement(&wire.RetireConnectionIDFrame{SequenceNumber: 1}))
+			Expect(removedTokens).To(ContainElement(protocol.StatelessResetToken{1}))
+			// the path will use a new connection ID
+			connID, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID).ToNot(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+		})
+	})
+
+	It("uses the first connection ID issued for a multipath path", func() {
+		m = newPathConnIDManager(
+			&wire.NewConnectionIDFrame{
+				SequenceNumber:      0,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			},
+			func(token protocol.StatelessResetToken) { tokenAdded = &token },
+			func(token protocol.StatelessResetToken) { removedTokens = append(removedTokens, token) },
+			func(f wire.Frame) { frameQueue = append(frameQueue, f) },
+		)
+		Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+		Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1}))
+		Expect(m.Add(&wire.NewConnectionIDFrame{
+			SequenceNumber:      1,
+			ConnectionID:        protocol.ParseConnectionID([]byte{2, 3, 4, 5}),
+			StatelessResetToken: protocol.StatelessResetToken{2},
+		})).To(Succeed())
+		// the connection ID is changed right away, as for path 0 after completion of the handshake
+		Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 3, 4, 5})))
+		Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+		m.Close()
+		Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1}, {2}}))
+	})
 })
@@ -26,6 +26,7 @@ import (
 type unpacker interface {
 	UnpackLongHeader(hdr *wire.Header, data []byte) (*unpackedPacket, error)
 	UnpackShortHeader(rcvTime time.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
+	UnpackPathShortHeader(rcvTime time.Time, data []byte, pathID protocol.PathID, largestRcvd protocol.PacketNumber) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
 }
 
 type streamManager interface {
@@ -48,6 +49,7 @@ type streamManager interface {
 type cryptoStreamHandler interface {
 	StartHandshake(context.Context) error
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.Version)
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
 	GetSessionTicket() ([]byte, error)
@@ -68,6 +70,10 @@ type receivedPacket struct {
 	ecn protocol.ECN
 
 	info packetInfo // only valid if the contained IP address is valid
+
+	// The conn the packet was received on.
+	// Only set for packets received on the server's preferred address.
+	rcvConn rawConn
 }
 
 func (p *receivedPacket) Size() protocol.ByteCount { return protocol.ByteCount(len(p.data)) }
@@ -80,6 +86,7 @@ func (p *receivedPacket) Clone() *receivedPacket {
 		buffer:     p.buffer,
 		ecn:        p.ecn,
 		info:       p.info,
+		rcvConn:    p.rcvConn,
 	}
 }
 
@@ -128,11 +135,25 @@ type connection struct {
 
 	conn      sendConn
 	sendQueue sender
+	// Only used by the server:
+	// handshakeConn is the conn the connection was created on,
+	// and rcvConn is the conn packets on the active path are received on (nil if that's handshakeConn).
+	handshakeConn sendConn
+	rcvConn       rawConn
+	// activePathConnID is the connection ID the client last used on the active path.
+	// A client that deliberately migrates uses a new connection ID, whereas the connection ID
+	// stays the same after a NAT rebinding.
+	activePathConnID protocol.ConnectionID
+	// largestRcvd1RTTPacket is the largest packet number received in a 1-RTT packet.
+	largestRcvd1RTTPacket protocol.PacketNumber
 
 	streamsMap      streamManager
 	connIDManager   *connIDManager
 	connIDGenerator *connIDGenerator
 
+	pathManagerOutgoing atomic.Pointer[pathManagerOutgoing] // only set for the client, once AddPath is called
+	pathManager         *pathManager                        // only set for the server, once a packet is received on a new path
+
 	rttStats *utils.RTTStats
 
 	cryptoStreamManager   *cryptoStreamManager
@@ -141,15 +162,22 @@ type connection struct {
 	retransmissionQueue   *retransmissionQueue
 	framer                *framer
 	connFlowController    flowcontrol.ConnectionFlowController
-	tokenStoreKey         string                    // only set for the client
-	tokenGenerator        *handshake.TokenGenerator // only set for the server
+	tokenStoreKey         string        // only set for the client
+	tokenProvider         TokenProvider // only set for the server
 
-	unpacker      unpacker
-	frameParser   wire.FrameParser
-	packer        packer
-	mtuDiscoverer mtuDiscoverer // initialized when the transport parameters are received
+	unpacker       unpacker
+	frameParser    wire.FrameParser
+	packer         packer
+	sealingManager sealingManager          // used by the packers of multipath paths
+	mtuDiscoverer  mtuDiscoverer           // initialized when the transport parameters are received
+	ackFrequency   *ackFrequencyController // set if both endpoints support the ACK frequency extension
+
+	multipath *multipathManager // set once the multipath extension was negotiated
 
 	maxPayloadSizeEstimate atomic.Uint32
+	// The PTO is updated by the run loop whenever an ACK is received.
+	// It is used as the initial retransmission timeout for path probes (see AddPath).
+	pto atomic.Int64
 
 	initialStream       *cryptoStream
 	handshakeStream     *cryptoStream
@@ -179,6 +207,9 @@ type connection struct {
 	receivedRetry       bool
 	versionNegotiated   bool
 	receivedFirstPacket bool
+	// Only set for the server, after switching to a compatible version (RFC 9368),
+	// until the first packet using the negotiated version is received.
+	originalVersion protocol.Version
 
 	// the minimum of the max_idle_timeout values advertised by both endpoints
 	idleTimeout  time.Duration
@@ -203,6 +234,11 @@ type connection struct {
 	connStateMutex sync.Mutex
 	connState      ConnectionState
 
+	// used to request the statistics from the run loop
+	connStatsRequests chan chan<- ConnectionStats
+	// closed when the run loop exits
+	runLoopDone chan struct{}
+
 	logID  string
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
@@ -228,7 +264,8 @@ var newConnection = func(
 	statelessResetToken protocol.StatelessResetToken,
 	conf *Config,
 	tlsConf *tls.Config,
-	tokenGenerator *handshake.TokenGenerator,
+	tokenProvider TokenProvider,
+	preferredAddress *PreferredAddress,
 	clientAddressValidated bool,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
@@ -238,10 +275,12 @@ var newConnection = func(
 		ctx:                 ctx,
 		ctxCancel:           ctxCancel,
 		conn:                conn,
+		handshakeConn:       conn,
+		activePathConnID:    srcConnID,
 		config:              conf,
 		handshakeDestConnID: destConnID,
 		srcConnIDLen:        srcConnID.Len(),
-		tokenGenerator:      tokenGenerator,
+		tokenProvider:       tokenProvider,
 		oneRTTStream:        newCryptoStream(),
 		perspective:         protocol.PerspectiveServer,
 		tracer:              tracer,
@@ -277,6 +316,7 @@ var newConnection = func(
 		s.rttStats,
 		clientAddressValidated,
 		s.conn.capabilities().ECN,
+		s.config.CongestionControl,
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -293,7 +333,7 @@ var newConnection = func(
 		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
 		MaxUDPPayloadSize:               protocol.MaxPacketBufferSize,
-		DisableActiveMigration:          true,
+		DisableActiveMigration:          !s.config.EnableActiveMigration,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		// For interoperability with quic-go versions before May 2023, this value must be set to a value
@@ -304,12 +344,33 @@ var newConnection = func(
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
 		RetrySourceConnectionID:   retrySrcConnID,
+		EnableResetStreamAt:       s.config.EnableStreamResetPartialDelivery,
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     s.version,
+			AvailableVersions: s.config.Versions,
+		},
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.EnableMultipath && srcConnID.Len() > 0 {
+		maxPathID := protocol.PathID(protocol.MaxMultipathPaths - 1)
+		params.InitialMaxPathID = &maxPathID
+	}
+	if s.config.EnableAckFrequency {
+		params.MinAckDelay = protocol.MinAckDelay
+	}
+	params.GreaseQUICBit = s.config.EnableQUICBitGreasing
+	if preferredAddress != nil {
+		pa, err := s.setupPreferredAddress(preferredAddress)
+		if err != nil {
+			s.logger.Debugf("Not sending a preferred address: %s", err)
+		} else {
+			params.PreferredAddress = pa
+		}
+	}
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -320,18 +381,66 @@ var newConnection = func(
 		params,
 		tlsConf,
 		conf.Allow0RTT,
+		newZeroRTTPolicy(conf, conn.RemoteAddr()),
+		s.newSessionTicketDataHandler(),
 		s.rttStats,
 		tracer,
 		logger,
 		s.version,
 	)
 	s.cryptoStreamHandler = cs
+	s.sealingManager = cs
 	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective)
-	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
+	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, s.config.EnableQUICBitGreasing)
 	s.cryptoStreamManager = newCryptoStreamManager(s.initialStream, s.handshakeStream, s.oneRTTStream)
 	return s
 }
 
+// setupPreferredAddress issues the connection ID for the preferred address,
+// and registers the connection with the Transports the preferred address is bound to.
+func (s *connection) setupPreferredAddress(pa *PreferredAddress) (*wire.PreferredAddress, error) {
+	connID, token, err := s.connIDGenerator.IssuePreferredAddressConnID()
+	if err != nil {
+		return nil, err
+	}
+	transports := make([]*Transport, 0, 2)
+	for _, tr := range []*Transport{pa.IPv4Transport, pa.IPv6Transport} {
+		if tr != nil && (len(transports) == 0 || transports[0] != tr) {
+			transports = append(transports, tr)
+		}
+	}
+	for i, tr := range transports {
+		runner := tr.handlerMap
+		handler := &preferredAddressHandler{connection: s, rcvConn: tr.conn}
+		s.connIDGenerator.AddConnRunner(pathRunnerKey{kind: pathRunnerPreferredAddress, id: uint64(i)}, connRunnerCallbacks{
+			AddConnectionID:    func(connID protocol.ConnectionID) { runner.Add(connID, handler) },
+			RemoveConnectionID: runner.Remove,
+			RetireConnectionID: runner.Retire,
+			ReplaceWithClosed:  runner.ReplaceWithClosed,
+		})
+	}
+	return &wire.PreferredAddress{
+		IPv4:                pa.IPv4,
+		IPv6:                pa.IPv6,
+		ConnectionID:        connID,
+		StatelessResetToken: token,
+	}, nil
+}
+
+// The preferredAddressHandler passes packets received on the server's preferred address to the connection.
+type preferredAddressHandler struct {
+	*connection
+
+	rcvConn rawConn
+}
+
+var _ packetHandler = &preferredAddressHandler{}
+
+func (h *preferredAddressHandler) handlePacket(p receivedPacket) {
+	p.rcvConn = h.rcvConn
+	h.connection.handlePacket(p)
+}
+
 // declare this as a variable, such that we can it mock it in the tests
 var newClientConnection = func(
 	ctx context.Context,
@@ -387,6 +496,7 @@ var newClientConnection = func(
 		s.rttStats,
 		false, // has no effect
 		s.conn.capabilities().ECN,
+		s.config.CongestionControl,
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -412,12 +522,25 @@ var newClientConnection = func(
 		// See https://github.com/quic-go/quic-go/pull/3806.
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
+		EnableResetStreamAt:       s.config.EnableStreamResetPartialDelivery,
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     s.version,
+			AvailableVersions: s.config.Versions,
+		},
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.EnableMultipath && srcConnID.Len() > 0 {
+		maxPathID := protocol.PathID(protocol.MaxMultipathPaths - 1)
+		params.InitialMaxPathID = &maxPathID
+	}
+	if s.config.EnableAckFrequency {
+		params.MinAckDelay = protocol.MinAckDelay
+	}
+	params.GreaseQUICBit = s.config.EnableQUICBitGreasing
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -426,14 +549,16 @@ var newClientConnection = func(
 		params,
 		tlsConf,
 		enable0RTT,
+		s.newSessionTicketDataHandler(),
 		s.rttStats,
 		tracer,
 		logger,
 		s.version,
 	)
 	s.cryptoStreamHandler = cs
+	s.sealingManager = cs
 	s.cryptoStreamManager = newCryptoStreamManager(s.initialStream, s.handshakeStream, oneRTTStream)
-	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
+	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, s.config.EnableQUICBitGreasing)
 	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective)
 	if len(tlsConf.ServerName) > 0 {
 		s.tokenStoreKey = tlsConf.ServerName
@@ -453,8 +578,9 @@ func (s *connection) preSetup() {
 	s.handshakeStream = newCryptoStream()
 	s.sendQueue = newSendQueue(s.conn)
 	s.retransmissionQueue = newRetransmissionQueue()
-	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams)
+	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams, s.config.EnableStreamResetPartialDelivery, s.config.EnableAckFrequency)
 	s.rttStats = &utils.RTTStats{}
+	s.pto.Store(int64(s.rttStats.PTO(false)))
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
 		protocol.ByteCount(s.config.MaxConnectionReceiveWindow),
@@ -477,11 +603,19 @@ func (s *connection) preSetup() {
 		uint64(s.config.MaxIncomingUniStreams),
 		s.perspective,
 	)
-	s.framer = newFramer()
+	var streamScheduler StreamScheduler
+	if s.config.StreamScheduler != nil {
+		streamScheduler = s.config.StreamScheduler()
+	} else {
+		streamScheduler = NewRoundRobinStreamScheduler()
+	}
+	s.framer = newFramer(streamScheduler)
 	s.receivedPackets = make(chan receivedPacket, protocol.MaxConnUnprocessedPackets)
 	s.closeChan = make(chan closeError, 1)
 	s.sendingScheduled = make(chan struct{}, 1)
 	s.handshakeCompleteChan = make(chan struct{})
+	s.connStatsRequests = make(chan chan<- ConnectionStats)
+	s.runLoopDone = make(chan struct{})
 
 	now := time.Now()
 	s.lastPacketReceivedTime = now
@@ -494,7 +628,10 @@ func (s *connection) preSetup() {
 // run the connection main loop
 func (s *connection) run() error {
 	var closeErr closeError
-	defer func() { s.ctxCancel(closeErr.err) }()
+	defer func() {
+		close(s.runLoopDone)
+		s.ctxCancel(closeErr.err)
+	}()
 
 	s.timer = *newTimer()
 
@@ -559,6 +696,10 @@ runLoop:
 				// We do all the interesting stuff after the switch statement, so
 				// nothing to see here.
 			case <-sendQueueAvailable:
+			case c := <-s.connStatsRequests:
+				c <- s.connectionStats()
+				// Serving the request doesn't change the connection's state.
+				continue
 			case firstPacket := <-s.receivedPackets:
 				wasProcessed := s.handlePacketImpl(firstPacket)
 				// Don't set timers and send packets if the packet made us close the connection.
@@ -624,6 +765,15 @@ runLoop:
 			}
 		}
 
+		if s.perspective == protocol.PerspectiveClient && s.handshakeConfirmed {
+			if pm := s.pathManagerOutgoing.Load(); pm != nil {
+				s.handleOutgoingPaths(pm, now)
+			}
+		}
+		if s.multipath != nil && s.handshakeConfirmed {
+			s.handleMultipathPaths(now)
+		}
+
 		if s.sendQueue.WouldBlock() {
 			// The send queue is still busy sending out packets.
 			// Wait until there's space to enqueue new packets.
@@ -641,6 +791,9 @@ runLoop:
 	}
 
 	s.cryptoStreamHandler.Close()
+	if s.multipath != nil {
+		s.multipath.Close()
+	}
 	s.sendQueue.Close() // close the send queue before sending the CONNECTION_CLOSE
 	s.handleCloseError(&closeErr)
 	if s.tracer != nil && s.tracer.Close != nil {
@@ -670,6 +823,40 @@ func (s *connection) supportsDatagrams() bool {
 	return s.peerParams.MaxDatagramFrameSize > 0
 }
 
+func (s *connection) ConnectionStats() ConnectionStats {
+	c := make(chan ConnectionStats, 1)
+	select {
+	case s.connStatsRequests <- c:
+		return <-c
+	case <-s.runLoopDone:
+		// Once the run loop has exited, the connection's state isn't modified anymore.
+		return s.connectionStats()
+	}
+}
+
+// connectionStats collects the connection's statistics.
+// It must only be called from the run loop, or after the run loop has exited.
+func (s *connection) connectionStats() ConnectionStats {
+	sentStats := s.sentPacketHandler.Stats()
+	return ConnectionStats{
+		MinRTT:               s.rttStats.MinRTT(),
+		LatestRTT:            s.rttStats.LatestRTT(),
+		SmoothedRTT:          s.rttStats.SmoothedRTT(),
+		MeanDeviation:        s.rttStats.MeanDeviation(),
+		CongestionWindow:     uint64(sentStats.CongestionWindow),
+		BytesInFlight:        uint64(sentStats.BytesInFlight),
+		BytesSent:            uint64(sentStats.BytesSent),
+		BytesReceived:        uint64(sentStats.BytesReceived),
+		PacketsSent:          sentStats.PacketsSent,
+		PacketsLost:          sentStats.PacketsLost,
+		PacketsRetransmitted: sentStats.PacketsRetransmitted,
+		MTU:                  uint64(s.maxPacketSize()),
+		ECNState:             sentStats.ECNState,
+		SendWindow:           uint64(s.connFlowController.SendWindowSize()),
+		ReceiveWindow:        uint64(s.connFlowController.ReceiveWindowSize()),
+	}
+}
+
 func (s *connection) ConnectionState() ConnectionState {
 	s.connStateMutex.Lock()
 	defer s.connStateMutex.Unlock()
@@ -677,9 +864,21 @@ func (s *connection) ConnectionState() ConnectionState {
 	s.connState.TLS = cs.ConnectionState
 	s.connState.Used0RTT = cs.Used0RTT
 	s.connState.GSO = s.conn.capabilities().GSO
+	s.connState.GRO = s.conn.capabilities().GRO
 	return s.connState
 }
 
+func (s *connection) newSessionTicketDataHandler() *handshake.SessionTicketDataHandler {
+	if s.config.GetSessionTicketData == nil && s.config.ValidateSessionTicketData == nil {
+		return nil
+	}
+	h := &handshake.SessionTicketDataHandler{Validate: s.config.ValidateSessionTicketData}
+	if s.config.GetSessionTicketData != nil {
+		h.Get = func() []byte { return s.config.GetSessionTicketData(s) }
+	}
+	return h
+}
+
 // Time when the connection should time out
 func (s *connection) nextIdleTimeoutTime() time.Time {
 	idleTimeout := max(s.idleTimeout, s.rttStats.PTO(true)*3)
@@ -711,12 +910,19 @@ func (s *connection) maybeResetTimer() {
 		}
 	}
 
-	s.timer.SetTimer(
-		deadline,
-		s.receivedPacketHandler.GetAlarmTimeout(),
-		s.sentPacketHandler.GetLossDetectionTimeout(),
-		s.pacingDeadline,
-	)
+	ackAlarm := s.receivedPacketHandler.GetAlarmTimeout()
+	lossTime := s.sentPacketHandler.GetLossDetectionTimeout()
+	if s.multipath != nil {
+		pathAckAlarm, pathLossTime := s.multipathTimers()
+		if !pathAckAlarm.IsZero() && (ackAlarm.IsZero() || pathAckAlarm.Before(ackAlarm)) {
+			ackAlarm = pathAckAlarm
+		}
+		if !pathLossTime.IsZero() && (lossTime.IsZero() || pathLossTime.Before(lossTime)) {
+			lossTime = pathLossTime
+		}
+	}
+
+	s.timer.SetTimer(deadline, ackAlarm, lossTime, s.pacingDeadline)
 }
 
 func (s *connection) idleTimeoutStartTime() time.Time {
@@ -762,7 +968,7 @@ func (s *connection) handleHandshakeComplete() error {
 			s.queueControlFrame(s.oneRTTStream.PopCryptoFrame(protocol.MaxPostHandshakeCryptoFrameSize))
 		}
 	}
-	token, err := s.tokenGenerator.NewToken(s.conn.RemoteAddr())
+	token, err := s.tokenProvider.NewToken(s.ctx, s.conn.RemoteAddr())
 	if err != nil {
 		return err
 	}
@@ -783,6 +989,9 @@ func (s *connection) handleHandshakeConfirmed() error {
 	if !s.config.DisablePathMTUDiscovery && s.conn.capabilities().DF {
 		s.mtuDiscoverer.Start()
 	}
+	if s.perspective == protocol.PerspectiveClient && s.peerParams.PreferredAddress != nil {
+		s.migrateToPreferredAddress(s.peerParams.PreferredAddress)
+	}
 	return nil
 }
 
@@ -822,7 +1031,7 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 		}
 
 		if wire.IsLongHeaderPacket(p.data[0]) {
-			hdr, packetData, rest, err := wire.ParsePacket(p.data)
+			hdr, packetData, rest, err := wire.ParsePacket(p.data, s.config.EnableQUICBitGreasing)
 			if err != nil {
 				if s.tracer != nil && s.tracer.DroppedPacket != nil {
 					dropReason := logging.PacketDropHeaderParseError
@@ -836,12 +1045,17 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 			}
 			lastConnID = hdr.DestConnectionID
 
-			if hdr.Version != s.version {
-				if s.tracer != nil && s.tracer.DroppedPacket != nil {
-					s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), protocol.InvalidPacketNumber, protocol.ByteCount(len(data)), logging.PacketDropUnexpectedVersion)
+			if hdr.Version != s.version && !s.isOriginalVersionPacket(hdr) {
+				if !s.isCompatibleVersionUpgrade(hdr) {
+					if s.tracer != nil && s.tracer.DroppedPacket != nil {
+						s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), protocol.InvalidPacketNumber, protocol.ByteCount(len(data)), logging.PacketDropUnexpectedVersion)
+					}
+					s.logger.Debugf("Dropping packet with version %x. Expected %x.", hdr.Version, s.version)
+					break
 				}
-				s.logger.Debugf("Dropping packet with version %x. Expected %x.", hdr.Version, s.version)
-				break
+				s.logger.Infof("Server switched to compatible QUIC version %s.", hdr.Version)
+				s.cryptoStreamHandler.ChangeVersion(hdr.Version)
+				s.setVersion(hdr.Version)
 			}
 
 			if counter > 0 {
@@ -858,6 +1072,11 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 
 			if wasProcessed := s.handleLongHeaderPacket(p, hdr); wasProcessed {
 				processed = true
+				// The client only switches to the negotiated version after receiving our first packet.
+				// From now on, it won't send any packets using the original version.
+				if s.originalVersion != 0 && hdr.Version == s.version {
+					s.originalVersion = 0
+				}
 			}
 			data = rest
 		} else {
@@ -875,6 +1094,32 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 	return processed
 }
 
+// isCompatibleVersionUpgrade says if the server switched the connection to a compatible version (RFC 9368).
+// This can only happen with the server's first Initial packet.
+func (s *connection) isCompatibleVersionUpgrade(hdr *wire.Header) bool {
+	return s.perspective == protocol.PerspectiveClient &&
+		!s.receivedFirstPacket &&
+		hdr.Type == protocol.PacketTypeInitial &&
+		protocol.IsSupportedVersion(s.config.Versions, hdr.Version) &&
+		protocol.AreCompatibleVersions(s.version, hdr.Version)
+}
+
+// isOriginalVersionPacket says if the client sent this packet using the original version,
+// after the server switched to a compatible version.
+// Before switching, the client only sends Initial and 0-RTT packets.
+func (s *connection) isOriginalVersionPacket(hdr *wire.Header) bool {
+	return s.originalVersion != 0 &&
+		hdr.Version == s.originalVersion &&
+		(hdr.Type == protocol.PacketTypeInitial || hdr.Type == protocol.PacketType0RTT)
+}
+
+func (s *connection) setVersion(v protocol.Version) {
+	s.version = v
+	s.connStateMutex.Lock()
+	s.connState.Version = v
+	s.connStateMutex.Unlock()
+}
+
 func (s *connection) handleShortHeaderPacket(p receivedPacket) bool {
 	var wasQueued bool
 
@@ -890,6 +1135,13 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket) bool {
 		s.tracer.DroppedPacket(logging.PacketType1RTT, protocol.InvalidPacketNumber, protocol.ByteCount(len(p.data)), logging.PacketDropHeaderParseError)
 		return false
 	}
+	if s.multipath != nil {
+		if id, ok := s.connIDGenerator.PathForConnID(destConnID); ok {
+			var processed bool
+			processed, wasQueued = s.handleMultipathPacket(p, id, destConnID)
+			return processed
+		}
+	}
 	pn, pnLen, keyPhase, data, err := s.unpacker.UnpackShortHeader(p.rcvTime, p.data)
 	if err != nil {
 		wasQueued = s.handleUnpackError(err, p, logging.PacketType1RTT)
@@ -909,6 +1161,17 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket) bool {
 		return false
 	}
 
+	// Only the client can migrate the connection, see section 9 of RFC 9000.
+	onNewPath := s.perspective == protocol.PerspectiveServer &&
+		(p.rcvConn != s.rcvConn || !addrsEqual(p.remoteAddr, s.conn.RemoteAddr()))
+	if onNewPath && !s.allowsPath(p.rcvConn, p.remoteAddr, destConnID) {
+		s.logger.Debugf("Dropping packet %d received from new address %s", pn, p.remoteAddr)
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(logging.PacketType1RTT, pn, p.Size(), logging.PacketDropUnexpectedPacket)
+		}
+		return false
+	}
+
 	var log func([]logging.Frame)
 	if s.tracer != nil && s.tracer.ReceivedShortHeaderPacket != nil {
 		log = func(frames []logging.Frame) {
@@ -925,13 +1188,87 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket) bool {
 			)
 		}
 	}
-	if err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log); err != nil {
+	isNonProbing, pathChallenge, err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log)
+	if err != nil {
 		s.closeLocal(err)
 		return false
 	}
+	s.largestRcvd1RTTPacket = max(s.largestRcvd1RTTPacket, pn)
+	if !onNewPath {
+		if s.perspective == protocol.PerspectiveServer {
+			s.activePathConnID = destConnID
+		}
+		if pathChallenge != nil {
+			s.handlePathChallengeFrame(pathChallenge)
+		}
+	} else {
+		s.handlePacketOnNewPath(p, pn, isNonProbing, pathChallenge)
+	}
+	if s.pathManager != nil {
+		if rcvConn, addr, info, ok := s.pathManager.ShouldSwitchPath(s.largestRcvd1RTTPacket); ok {
+			s.migrate(s.pathConn(rcvConn, addr, info), p.rcvTime)
+			s.rcvConn = rcvConn
+			s.activePathConnID = destConnID
+		}
+	}
 	return true
 }
 
+// allowsPath says if packets received on a new path are processed.
+func (s *connection) allowsPath(rcvConn rawConn, addr net.Addr, connID protocol.ConnectionID) bool {
+	// Clients are not allowed to migrate before the handshake is confirmed, see section 9 of RFC 9000.
+	if !s.handshakeConfirmed {
+		return false
+	}
+	// After a NAT rebinding, the client continues using the same connection ID.
+	// NAT rebindings are handled independently of the disable_active_migration transport parameter,
+	// see section 9 of RFC 9000.
+	isMigration := rcvConn != s.rcvConn || connID != s.activePathConnID
+	// The disable_active_migration transport parameter doesn't apply to the preferred address,
+	// see section 18.2 of RFC 9000.
+	if isMigration && !s.config.EnableActiveMigration && rcvConn == nil {
+		return false
+	}
+	if s.pathManager == nil {
+		var allowMigration func(net.Addr) bool
+		if s.config.AllowMigration != nil {
+			allowMigration = func(addr net.Addr) bool { return s.config.AllowMigration(s, addr) }
+		}
+		s.pathManager = newPathManager(allowMigration)
+	}
+	return s.pathManager.AllowsPath(rcvConn, addr, isMigration)
+}
+
+// pathConn returns the sendConn used to send packets on a path.
+// A nil rcvConn identifies the conn the connection was created on.
+func (s *connection) pathConn(rcvConn rawConn, addr net.Addr, info packetInfo) sendConn {
+	if rcvConn == nil {
+		return s.handshakeConn.withRemoteAddr(addr, info)
+	}
+	return newSendConn(rcvConn, addr, info, s.logger)
+}
+
+func (s *connection) handlePacketOnNewPath(p receivedPacket, pn protocol.PacketNumber, isNonProbing bool, pathChallenge *wire.PathChallengeFrame) {
+	frames, size := s.pathManager.HandlePacket(p, pn, pathChallenge, isNonProbing, s.rttStats.PTO(false))
+	if len(frames) == 0 {
+		return
+	}
+	// The client might have NAT rebound, in which case it continues using the same connection ID.
+	probe, buf, err := s.packer.PackPathProbePacket(s.connIDManager.Get(), frames, size, s.version)
+	if err != nil {
+		s.logger.Debugf("Failed to pack path probe packet for %s: %s", p.remoteAddr, err)
+		return
+	}
+	s.pathManager.SentPathProbe(p.rcvConn, p.remoteAddr, frames, buf.Len(), p.rcvTime)
+	ecn := s.sentPacketHandler.ECNMode(false)
+	s.logShortHeaderPacket(probe.DestConnID, probe.Ack, probe.Frames, probe.StreamFrames, probe.PacketNumber, probe.PacketNumberLen, probe.KeyPhase, ecn, buf.Len(), false)
+	s.sentPacketHandler.SentPacket(p.rcvTime, probe.PacketNumber, protocol.InvalidPacketNumber, nil, nil, protocol.Encryption1RTT, ecn, buf.Len(), false)
+	if err := s.pathConn(p.rcvConn, p.remoteAddr, p.info).Write(buf.Data, 0, ecn, time.Time{}); err != nil {
+		s.logger.Debugf("Failed to send path probe packet to %s: %s", p.remoteAddr, err)
+	}
+	buf.Release()
+}
+
 func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header) bool /* was the packet successfully processed */ {
 	var wasQueued bool
 
@@ -1153,15 +1490,10 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 ) error {
 	if !s.receivedFirstPacket {
 		s.receivedFirstPacket = true
-		if !s.versionNegotiated && s.tracer != nil && s.tracer.NegotiatedVersion != nil {
-			var clientVersions, serverVersions []protocol.Version
-			switch s.perspective {
-			case protocol.PerspectiveClient:
-				clientVersions = s.config.Versions
-			case protocol.PerspectiveServer:
-				serverVersions = s.config.Versions
-			}
-			s.tracer.NegotiatedVersion(s.version, clientVersions, serverVersions)
+		// The server might still switch to a compatible version when processing the client's transport parameters.
+		// It logs the negotiated version once it has received them.
+		if s.perspective == protocol.PerspectiveClient && !s.versionNegotiated && s.tracer != nil && s.tracer.NegotiatedVersion != nil {
+			s.tracer.NegotiatedVersion(s.version, s.config.Versions, nil)
 		}
 		// The server can change the source connection ID with the first Handshake packet.
 		if s.perspective == protocol.PerspectiveClient && packet.hdr.SrcConnectionID != s.handshakeDestConnID {
@@ -1210,10 +1542,13 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 			s.tracer.ReceivedLongHeaderPacket(packet.hdr, packetSize, ecn, frames)
 		}
 	}
-	isAckEliciting, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log, rcvTime)
+	isAckEliciting, _, pathChallenge, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log, rcvTime)
 	if err != nil {
 		return err
 	}
+	if pathChallenge != nil {
+		s.handlePathChallengeFrame(pathChallenge)
+	}
 	return s.receivedPacketHandler.ReceivedPacket(packet.hdr.PacketNumber, ecn, packet.encryptionLevel, rcvTime, isAckEliciting)
 }
 
@@ -1224,16 +1559,19 @@ func (s *connection) handleUnpackedShortHeaderPacket(
 	ecn protocol.ECN,
 	rcvTime time.Time,
 	log func([]logging.Frame),
-) error {
+) (isNonProbing bool, pathChallenge *wire.PathChallengeFrame, _ error) {
 	s.lastPacketReceivedTime = rcvTime
 	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
 	s.keepAlivePingSent = false
 
-	isAckEliciting, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log, rcvTime)
+	isAckEliciting, isNonProbing, pathChallenge, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log, rcvTime)
 	if err != nil {
-		return err
+		return false, nil, err
+	}
+	if err := s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting); err != nil {
+		return false, nil, err
 	}
-	return s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
+	return isNonProbing, pathChallenge, nil
 }
 
 func (s *connection) handleFrames(
@@ -1242,7 +1580,7 @@ func (s *connection) handleFrames(
 	encLevel protocol.EncryptionLevel,
 	log func([]logging.Frame),
 	rcvTime time.Time,
-) (isAckEliciting bool, _ error) {
+) (isAckEliciting, isNonProbing bool, pathChallenge *wire.PathChallengeFrame, _ error) {
 	// Only used for tracing.
 	// If we're not tracing, this slice will always remain empty.
 	var frames []logging.Frame
@@ -1254,7 +1592,7 @@ func (s *connection) handleFrames(
 	for len(data) > 0 {
 		l, frame, err := s.frameParser.ParseNext(data, encLevel, s.version)
 		if err != nil {
-			return false, err
+			return false, false, nil, err
 		}
 		data = data[l:]
 		if frame == nil {
@@ -1263,6 +1601,9 @@ func (s *connection) handleFrames(
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
 		}
+		if !isProbingFrame(frame) {
+			isNonProbing = true
+		}
 		if log != nil {
 			frames = append(frames, toLoggingFrame(frame))
 		}
@@ -1271,9 +1612,15 @@ func (s *connection) handleFrames(
 		if handleErr != nil {
 			continue
 		}
+		// The PATH_RESPONSE needs to be sent on the path that the PATH_CHALLENGE was received on.
+		// This is handled by the caller.
+		if f, ok := frame.(*wire.PathChallengeFrame); ok {
+			pathChallenge = f
+			continue
+		}
 		if err := s.handleFrame(frame, encLevel, destConnID, rcvTime); err != nil {
 			if log == nil {
-				return false, err
+				return false, false, nil, err
 			}
 			// If we're logging, we need to keep parsing (but not handling) all frames.
 			handleErr = err
@@ -1283,7 +1630,7 @@ func (s *connection) handleFrames(
 	if log != nil {
 		log(frames)
 		if handleErr != nil {
-			return false, handleErr
+			return false, false, nil, handleErr
 		}
 	}
 
@@ -1293,7 +1640,7 @@ func (s *connection) handleFrames(
 	// and an ACK serialized after that CRYPTO frame. In this case, we still want to process the ACK frame.
 	if !handshakeWasComplete && s.handshakeComplete {
 		if err := s.handleHandshakeComplete(); err != nil {
-			return false, err
+			return false, false, nil, err
 		}
 	}
 
@@ -1331,11 +1678,8 @@ func (s *connection) handleFrame(
 	case *wire.StopSendingFrame:
 		err = s.handleStopSendingFrame(frame)
 	case *wire.PingFrame:
-	case *wire.PathChallengeFrame:
-		s.handlePathChallengeFrame(frame)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		err = s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1346,6 +1690,24 @@ func (s *connection) handleFrame(
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.receivedPacketHandler.ReceivedImmediateAckFrame()
+	case *wire.PathAckFrame:
+		err = s.handlePathAckFrame(frame)
+	case *wire.PathAbandonFrame:
+		s.handlePathAbandonFrame(frame, rcvTime)
+	case *wire.PathStandbyFrame:
+		s.multipath.HandlePathStatus(frame.PathID, true, frame.SequenceNumber)
+	case *wire.PathAvailableFrame:
+		s.multipath.HandlePathStatus(frame.PathID, false, frame.SequenceNumber)
+	case *wire.MPNewConnectionIDFrame:
+		err = s.handleMPNewConnectionIDFrame(frame)
+	case *wire.MPRetireConnectionIDFrame:
+		err = s.handleMPRetireConnectionIDFrame(frame, destConnID)
+	case *wire.MaxPathIDFrame:
+		s.handleMaxPathIDFrame(frame)
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1409,8 +1771,16 @@ func (s *connection) handleHandshakeEvents() error {
 			// Don't call handleHandshakeComplete yet.
 			// It's advantageous to process ACK frames that might be serialized after the CRYPTO frame first.
 			s.handshakeComplete = true
+		case handshake.EventNegotiatedVersion:
+			s.logger.Infof("Switching to compatible QUIC version %s.", ev.Version)
+			s.originalVersion = s.version
+			s.setVersion(ev.Version)
 		case handshake.EventReceivedTransportParameters:
 			err = s.handleTransportParameters(ev.TransportParameters)
+		case handshake.EventSessionTicketData:
+			s.connStateMutex.Lock()
+			s.connState.SessionTicketData = ev.Data
+			s.connStateMutex.Unlock()
 		case handshake.EventRestoredTransportParameters:
 			s.restoreTransportParameters(ev.TransportParameters)
 			close(s.earlyConnReadyChan)
@@ -1494,6 +1864,48 @@ func (s *connection) handlePathChallengeFrame(frame *wire.PathChallengeFrame) {
 	s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
 }
 
+func (s *connection) handlePathResponseFrame(frame *wire.PathResponseFrame) error {
+	if s.multipath != nil {
+		if p, ok := s.multipath.HandlePathResponseFrame(frame); ok {
+			s.logger.Debugf("Validated path %d: %s -> %s", p.id, p.conn.LocalAddr(), p.conn.RemoteAddr())
+			if s.tracer != nil && s.tracer.ValidatedPath != nil {
+				s.tracer.ValidatedPath(p.conn.LocalAddr(), p.conn.RemoteAddr())
+			}
+			return nil
+		}
+	}
+	if s.perspective == protocol.PerspectiveServer {
+		if s.pathManager == nil {
+			// since we didn't send any PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
+			return errors.New("unexpected PATH_RESPONSE frame")
+		}
+		if rcvConn, addr, ok := s.pathManager.HandlePathResponseFrame(frame); ok {
+			localAddr := s.handshakeConn.LocalAddr()
+			if rcvConn != nil {
+				localAddr = rcvConn.LocalAddr()
+			}
+			s.logger.Debugf("Validated path %s -> %s", localAddr, addr)
+			if s.tracer != nil && s.tracer.ValidatedPath != nil {
+				s.tracer.ValidatedPath(localAddr, addr)
+			}
+		}
+		return nil
+	}
+	pm := s.pathManagerOutgoing.Load()
+	if pm == nil {
+		// since we didn't send any PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
+		return errors.New("unexpected PATH_RESPONSE frame")
+	}
+	// PATH_RESPONSE frames might be duplicated, or arrive after the path was closed.
+	if conn, ok := pm.HandlePathResponseFrame(frame); ok {
+		s.logger.Debugf("Validated path %s -> %s", conn.LocalAddr(), conn.RemoteAddr())
+		if s.tracer != nil && s.tracer.ValidatedPath != nil {
+			s.tracer.ValidatedPath(conn.LocalAddr(), conn.RemoteAddr())
+		}
+	}
+	return nil
+}
+
 func (s *connection) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
 	if s.perspective == protocol.PerspectiveServer {
 		return &qerr.TransportError{
@@ -1533,6 +1945,7 @@ func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encr
 	if err != nil {
 		return err
 	}
+	s.pto.Store(int64(s.rttStats.PTO(false)))
 	if !acked1RTTPacket {
 		return nil
 	}
@@ -1558,6 +1971,19 @@ func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
 	return nil
 }
 
+// ACK_FREQUENCY frames only apply to the path that the handshake was performed on.
+// The multipath draft doesn't define how the ACK frequency extension interacts with multiple paths.
+func (s *connection) handleAckFrequencyFrame(f *wire.AckFrequencyFrame) error {
+	if f.RequestMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "requested max_ack_delay smaller than min_ack_delay",
+		}
+	}
+	s.receivedPacketHandler.ReceivedAckFrequencyFrame(f)
+	return nil
+}
+
 // closeLocal closes the connection and send a CONNECTION_CLOSE containing the error
 func (s *connection) closeLocal(e error) {
 	s.closeOnce.Do(func() {
@@ -1716,6 +2142,14 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 			ErrorMessage: err.Error(),
 		}
 	}
+	if s.perspective == protocol.PerspectiveClient {
+		if err := s.checkVersionInformation(params.VersionInformation); err != nil {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorCode,
+				ErrorMessage: err.Error(),
+			}
+		}
+	}
 
 	if s.perspective == protocol.PerspectiveClient && s.peerParams != nil && s.ConnectionState().Used0RTT && !params.ValidForUpdate(s.peerParams) {
 		return &qerr.TransportError{
@@ -1725,6 +2159,9 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 	}
 
 	s.peerParams = params
+	if s.perspective == protocol.PerspectiveServer && s.tracer != nil && s.tracer.NegotiatedVersion != nil {
+		s.tracer.NegotiatedVersion(s.version, nil, s.config.Versions)
+	}
 	// On the client side we have to wait for handshake completion.
 	// During a 0-RTT connection, we are only allowed to use the new transport parameters for 1-RTT packets.
 	if s.perspective == protocol.PerspectiveServer {
@@ -1736,6 +2173,7 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 
 	s.connStateMutex.Lock()
 	s.connState.SupportsDatagrams = s.supportsDatagrams()
+	s.connState.SupportsStreamResetPartialDelivery = s.config.EnableStreamResetPartialDelivery && params.EnableResetStreamAt
 	s.connStateMutex.Unlock()
 	return nil
 }
@@ -1770,6 +2208,30 @@ func (s *connection) checkTransportParameters(params *wire.TransportParameters)
 	return nil
 }
 
+// checkVersionInformation validates the server's version_information transport parameter,
+// as described in section 4 of RFC 9368.
+func (s *connection) checkVersionInformation(vi *wire.VersionInformation) error {
+	if vi == nil {
+		// Servers that don't support compatible version negotiation don't send version_information.
+		// However, if we acted on a Version Negotiation packet, we need it to detect downgrade attacks.
+		if s.versionNegotiated {
+			return errors.New("missing version_information after Version Negotiation")
+		}
+		return nil
+	}
+	if vi.ChosenVersion != s.version {
+		return fmt.Errorf("server's chosen version (%s) doesn't match the negotiated version (%s)", vi.ChosenVersion, s.version)
+	}
+	if s.versionNegotiated {
+		// Check that we would have chosen the same version, had the Version Negotiation packet
+		// contained the server's list of available versions.
+		if v, ok := protocol.ChooseSupportedVersion(s.config.Versions, vi.AvailableVersions); !ok || v != s.version {
+			return fmt.Errorf("version downgrade detected (negotiated %s, server supports %s)", s.version, vi.AvailableVersions)
+		}
+	}
+	return nil
+}
+
 func (s *connection) applyTransportParameters() {
 	params := s.peerParams
 	// Our local idle timeout will always be > 0.
@@ -1787,14 +2249,24 @@ func (s *connection) applyTransportParameters() {
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
+	// The client migrates to the preferred address once the handshake is confirmed.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
 	}
+	if s.config.EnableAckFrequency && params.MinAckDelay > 0 {
+		s.ackFrequency = newAckFrequencyController(params.MinAckDelay, params.MaxAckDelay, s.rttStats)
+	}
+	if s.config.EnableQUICBitGreasing && params.GreaseQUICBit {
+		s.packer.EnableQUICBitGreasing()
+	}
+	s.maybeEnableMultipath(params)
+	s.initMTUDiscoverer()
+}
+
+func (s *connection) initMTUDiscoverer() {
 	maxPacketSize := protocol.ByteCount(protocol.MaxPacketBufferSize)
-	if params.MaxUDPPayloadSize > 0 && params.MaxUDPPayloadSize < maxPacketSize {
-		maxPacketSize = params.MaxUDPPayloadSize
+	if s.peerParams.MaxUDPPayloadSize > 0 && s.peerParams.MaxUDPPayloadSize < maxPacketSize {
+		maxPacketSize = s.peerParams.MaxUDPPayloadSize
 	}
 	s.mtuDiscoverer = newMTUDiscoverer(
 		s.rttStats,
@@ -1807,6 +2279,9 @@ func (s *connection) applyTransportParameters() {
 
 func (s *connection) triggerSending(now time.Time) error {
 	s.pacingDeadline = time.Time{}
+	if s.handshakeConfirmed && s.multipath != nil && s.multipath.HasValidatedPaths() {
+		return s.triggerSendingMultipath(now)
+	}
 
 	sendMode := s.sentPacketHandler.SendMode(now)
 	//nolint:exhaustive // No need to handle pacing limited here.
@@ -1816,11 +2291,11 @@ func (s *connection) triggerSending(now time.Time) error {
 	case ackhandler.SendNone:
 		return nil
 	case ackhandler.SendPacingLimited:
-		deadline := s.sentPacketHandler.TimeUntilSend()
-		if deadline.IsZero() {
-			deadline = deadlineSendImmediately
+		// When the kernel paces packets, packets that are due within the pacing horizon can be sent right away.
+		if _, ok := s.kernelPacedSendTime(now); ok {
+			return s.sendPackets(now)
 		}
-		s.pacingDeadline = deadline
+		s.resetPacingDeadline()
 		// Allow sending of an ACK if we're pacing limit.
 		// This makes sure that a peer that is mostly receiving data (and thus has an inaccurate cwnd estimate)
 		// sends enough ACKs to allow its peer to utilize the bandwidth.
@@ -1876,7 +2351,7 @@ func (s *connection) sendPackets(now time.Time) error {
 		ecn := s.sentPacketHandler.ECNMode(true)
 		s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
 		s.registerPackedShortHeaderPacket(p, ecn, now)
-		s.sendQueue.Send(buf, 0, ecn)
+		s.sendQueue.Send(buf, 0, ecn, time.Time{})
 		// This is kind of a hack. We need to trigger sending again somehow.
 		s.pacingDeadline = deadlineSendImmediately
 		return nil
@@ -1891,6 +2366,11 @@ func (s *connection) sendPackets(now time.Time) error {
 	if cf := s.cryptoStreamManager.GetPostHandshakeData(protocol.MaxPostHandshakeCryptoFrameSize); cf != nil {
 		s.queueControlFrame(cf)
 	}
+	if s.handshakeConfirmed && s.ackFrequency != nil {
+		if f := s.ackFrequency.GetFrame(s.sentPacketHandler.Stats().CongestionWindow, s.maxPacketSize(), now); f != nil {
+			s.framer.QueueControlFrame(f)
+		}
+	}
 
 	if !s.handshakeConfirmed {
 		packet, err := s.packer.PackCoalescedPacket(false, s.maxPacketSize(), now, s.version)
@@ -1910,6 +2390,10 @@ func (s *connection) sendPackets(now time.Time) error {
 		return nil
 	}
 
+	// GSO is not used when sending on multiple paths.
+	if s.multipath != nil && s.multipath.HasValidatedPaths() {
+		return s.sendPacketsMultipath(now)
+	}
 	if s.conn.capabilities().GSO {
 		return s.sendPacketsWithGSO(now)
 	}
@@ -1917,10 +2401,15 @@ func (s *connection) sendPackets(now time.Time) error {
 }
 
 func (s *connection) sendPacketsWithoutGSO(now time.Time) error {
+	sendTime, ok := s.firstSendTime(now)
+	if !ok {
+		s.resetPacingDeadline()
+		return nil
+	}
 	for {
 		buf := getPacketBuffer()
 		ecn := s.sentPacketHandler.ECNMode(true)
-		if _, err := s.appendOneShortHeaderPacket(buf, s.maxPacketSize(), ecn, now); err != nil {
+		if _, err := s.appendOneShortHeaderPacket(buf, s.maxPacketSize(), ecn, sendTime); err != nil {
 			if err == errNothingToPack {
 				buf.Release()
 				return nil
@@ -1928,15 +2417,21 @@ func (s *connection) sendPacketsWithoutGSO(now time.Time) error {
 			return err
 		}
 
-		s.sendQueue.Send(buf, 0, ecn)
+		s.sendQueue.Send(buf, 0, ecn, s.txTime(now, sendTime))
 
 		if s.sendQueue.WouldBlock() {
 			return nil
 		}
-		sendMode := s.sentPacketHandler.SendMode(now)
+		sendMode := s.sentPacketHandler.SendMode(sendTime)
 		if sendMode == ackhandler.SendPacingLimited {
-			s.resetPacingDeadline()
-			return nil
+			if t, ok := s.kernelPacedSendTime(now); ok {
+				sendTime = t
+				sendMode = s.sentPacketHandler.SendMode(sendTime)
+			}
+			if sendMode == ackhandler.SendPacingLimited {
+				s.resetPacingDeadline()
+				return nil
+			}
 		}
 		if sendMode != ackhandler.SendAny {
 			return nil
@@ -1950,13 +2445,19 @@ func (s *connection) sendPacketsWithoutGSO(now time.Time) error {
 }
 
 func (s *connection) sendPacketsWithGSO(now time.Time) error {
+	sendTime, ok := s.firstSendTime(now)
+	if !ok {
+		s.resetPacingDeadline()
+		return nil
+	}
 	buf := getLargePacketBuffer()
 	maxSize := s.maxPacketSize()
 
 	ecn := s.sentPacketHandler.ECNMode(true)
 	for {
 		var dontSendMore bool
-		size, err := s.appendOneShortHeaderPacket(buf, maxSize, ecn, now)
+		var nextSendTime time.Time
+		size, err := s.appendOneShortHeaderPacket(buf, maxSize, ecn, sendTime)
 		if err != nil {
 			if err != errNothingToPack {
 				return err
@@ -1969,9 +2470,16 @@ func (s *connection) sendPacketsWithGSO(now time.Time) error {
 		}
 
 		if !dontSendMore {
-			sendMode := s.sentPacketHandler.SendMode(now)
+			sendMode := s.sentPacketHandler.SendMode(sendTime)
 			if sendMode == ackhandler.SendPacingLimited {
-				s.resetPacingDeadline()
+				// All packets in a GSO batch are sent at the same time.
+				// If the kernel paces packets, the next packet is sent in a new batch.
+				if t, ok := s.kernelPacedSendTime(now); ok {
+					nextSendTime = t
+					sendMode = ackhandler.SendAny
+				} else {
+					s.resetPacingDeadline()
+				}
 			}
 			if sendMode != ackhandler.SendAny {
 				dontSendMore = true
@@ -1986,11 +2494,11 @@ func (s *connection) sendPacketsWithGSO(now time.Time) error {
 		// 2. The last packet appended was a full-size packet
 		// 3. The next packet will have the same ECN marking
 		// 4. We still have enough space for another full-size packet in the buffer
-		if !dontSendMore && size == maxSize && nextECN == ecn && buf.Len()+maxSize <= buf.Cap() {
+		if !dontSendMore && nextSendTime.IsZero() && size == maxSize && nextECN == ecn && buf.Len()+maxSize <= buf.Cap() {
 			continue
 		}
 
-		s.sendQueue.Send(buf, uint16(maxSize), ecn)
+		s.sendQueue.Send(buf, uint16(maxSize), ecn, s.txTime(now, sendTime))
 
 		if dontSendMore {
 			return nil
@@ -2005,6 +2513,9 @@ func (s *connection) sendPacketsWithGSO(now time.Time) error {
 			return nil
 		}
 
+		if !nextSendTime.IsZero() {
+			sendTime = nextSendTime
+		}
 		buf = getLargePacketBuffer()
 	}
 }
@@ -2013,10 +2524,58 @@ func (s *connection) resetPacingDeadline() {
 	deadline := s.sentPacketHandler.TimeUntilSend()
 	if deadline.IsZero() {
 		deadline = deadlineSendImmediately
+	} else if s.conn.capabilities().TXTime && s.handshakeConfirmed {
+		// The kernel paces packets. Packets can be handed to the kernel ahead of time.
+		// The pacer still determines when packets are sent: they are handed to the kernel
+		// at most KernelPacingHorizon before their departure time.
+		// This serves as a fallback if the qdisc doesn't support SO_TXTIME (and sends packets right away).
+		deadline = deadline.Add(-protocol.KernelPacingHorizon)
 	}
 	s.pacingDeadline = deadline
 }
 
+// firstSendTime returns the send time of the first packet sent by sendPacketsWithGSO and sendPacketsWithoutGSO.
+// If the kernel paces packets, this might be in the future.
+func (s *connection) firstSendTime(now time.Time) (time.Time, bool) {
+	if !s.conn.capabilities().TXTime {
+		return now, true
+	}
+	if s.sentPacketHandler.SendMode(now) != ackhandler.SendPacingLimited {
+		return now, true
+	}
+	return s.kernelPacedSendTime(now)
+}
+
+// kernelPacedSendTime is used when the pacer doesn't allow sending a packet right now.
+// If the kernel paces packets (using SO_TXTIME), packets can be scheduled ahead of time.
+// It returns the departure time of the next packet, if it is within the KernelPacingHorizon.
+func (s *connection) kernelPacedSendTime(now time.Time) (time.Time, bool) {
+	if !s.conn.capabilities().TXTime || !s.handshakeConfirmed {
+		return time.Time{}, false
+	}
+	// GSO and kernel pacing is not used when sending on multiple paths.
+	if s.multipath != nil && s.multipath.HasValidatedPaths() {
+		return time.Time{}, false
+	}
+	t := s.sentPacketHandler.TimeUntilSend()
+	if t.Before(now) {
+		return now, true
+	}
+	if t.Sub(now) > protocol.KernelPacingHorizon {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// txTime returns the time when the kernel should send the packet,
+// or the zero value if it should be sent immediately.
+func (s *connection) txTime(now, sendTime time.Time) time.Time {
+	if !sendTime.After(now) {
+		return time.Time{}
+	}
+	return sendTime
+}
+
 func (s *connection) maybeSendAckOnlyPacket(now time.Time) error {
 	if !s.handshakeConfirmed {
 		ecn := s.sentPacketHandler.ECNMode(false)
@@ -2040,11 +2599,16 @@ func (s *connection) maybeSendAckOnlyPacket(now time.Time) error {
 	}
 	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
 	s.registerPackedShortHeaderPacket(p, ecn, now)
-	s.sendQueue.Send(buf, 0, ecn)
+	s.sendQueue.Send(buf, 0, ecn, time.Time{})
 	return nil
 }
 
 func (s *connection) sendProbePacket(encLevel protocol.EncryptionLevel, now time.Time) error {
+	// The peer might be delaying its ACKs, as requested by our ACK_FREQUENCY frames.
+	// Ask it to acknowledge the probe packet right away.
+	if encLevel == protocol.Encryption1RTT && s.ackFrequency != nil {
+		s.framer.QueueControlFrame(&wire.ImmediateAckFrame{})
+	}
 	// Queue probe packets until we actually send out a packet,
 	// or until there are no more packets to queue.
 	var packet *coalescedPacket
@@ -2133,7 +2697,7 @@ func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn prot
 		s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket)
 	}
 	s.connIDManager.SentPacket()
-	s.sendQueue.Send(packet.buffer, 0, ecn)
+	s.sendQueue.Send(packet.buffer, 0, ecn, time.Time{})
 	return nil
 }
 
@@ -2157,7 +2721,7 @@ func (s *connection) sendConnectionClose(e error) ([]byte, error) {
 	}
 	ecn := s.sentPacketHandler.ECNMode(packet.IsOnlyShortHeaderPacket())
 	s.logCoalescedPacket(packet, ecn)
-	return packet.buffer.Data, s.conn.Write(packet.buffer.Data, 0, ecn)
+	return packet.buffer.Data, s.conn.Write(packet.buffer.Data, 0, ecn, time.Time{})
 }
 
 func (s *connection) maxPacketSize() protocol.ByteCount {
@@ -2267,6 +2831,15 @@ func (s *connection) onHasStreamControlFrame(id protocol.StreamID, str streamCon
 	s.scheduleSending()
 }
 
+func (s *connection) onStreamPriorityChanged(id protocol.StreamID, prio StreamPriority, str sendStreamI) {
+	s.framer.SetStreamPriority(id, prio, str)
+	s.scheduleSending()
+}
+
+func (s *connection) supportsResetStreamAt() bool {
+	return s.ConnectionState().SupportsStreamResetPartialDelivery
+}
+
 func (s *connection) onStreamCompleted(id protocol.StreamID) {
 	if err := s.streamsMap.DeleteStream(id); err != nil {
 		s.closeLocal(err)
@@ -2307,13 +2880,206 @@ func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
 }
 
 func (s *connection) LocalAddr() net.Addr {
+	// The connection might be switched to a different path by the run loop.
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
 	return s.conn.LocalAddr()
 }
 
 func (s *connection) RemoteAddr() net.Addr {
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
 	return s.conn.RemoteAddr()
 }
 
+// AddPath adds a new path to the connection.
+func (s *connection) AddPath(t *Transport) (*Path, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return nil, errors.New("server cannot initiate connection migration")
+	}
+	select {
+	case <-s.HandshakeComplete():
+	default:
+		return nil, errors.New("can only add paths after the handshake completed")
+	}
+	if s.peerParams.DisableActiveMigration {
+		return nil, errors.New("server disabled connection migration")
+	}
+	if err := t.init(false); err != nil {
+		return nil, err
+	}
+	if t.connIDLen != s.srcConnIDLen {
+		return nil, fmt.Errorf("transport uses connection IDs of length %d, connection uses %d", t.connIDLen, s.srcConnIDLen)
+	}
+	if t.conn.LocalAddr().String() == s.LocalAddr().String() {
+		return nil, errors.New("path already in use")
+	}
+	return s.getPathManagerOutgoing().NewPath(
+		t,
+		newSendConn(t.conn, s.RemoteAddr(), packetInfo{}, s.logger),
+		time.Duration(s.pto.Load()),
+		func(id pathID) {
+			runner := t.handlerMap
+			s.connIDGenerator.AddConnRunner(pathRunnerKey{kind: pathRunnerMigration, id: uint64(id)}, connRunnerCallbacks{
+				AddConnectionID:    func(connID protocol.ConnectionID) { runner.Add(connID, s) },
+				RemoveConnectionID: runner.Remove,
+				RetireConnectionID: runner.Retire,
+				ReplaceWithClosed:  runner.ReplaceWithClosed,
+			})
+		},
+	)
+}
+
+// OriginalPath returns the path that the handshake was performed on.
+func (s *connection) OriginalPath() (*Path, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return nil, errors.New("server cannot initiate connection migration")
+	}
+	select {
+	case <-s.HandshakeComplete():
+	default:
+		return nil, errors.New("the original path is only available after the handshake completed")
+	}
+	return s.getPathManagerOutgoing().OriginalPath(), nil
+}
+
+func (s *connection) getPathManagerOutgoing() *pathManagerOutgoing {
+	pm := s.pathManagerOutgoing.Load()
+	if pm == nil {
+		// The connection only switches paths once the path manager was created,
+		// so at this point, s.conn is guaranteed to be the path that the handshake was performed on.
+		s.connStateMutex.Lock()
+		handshakeConn := s.conn
+		s.connStateMutex.Unlock()
+		pm = newPathManagerOutgoing(
+			s.ctx,
+			handshakeConn,
+			s.connIDManager.GetConnIDForPath,
+			func(id pathID) {
+				s.connIDManager.RetireConnIDForPath(id)
+				s.connIDGenerator.RemoveConnRunner(pathRunnerKey{kind: pathRunnerMigration, id: uint64(id)})
+			},
+			s.scheduleSending,
+		)
+		if !s.pathManagerOutgoing.CompareAndSwap(nil, pm) {
+			pm = s.pathManagerOutgoing.Load()
+		}
+	}
+	return pm
+}
+
+// migrateToPreferredAddress validates the path to the server's preferred address,
+// and switches to that path once it is validated, see section 9.6 of RFC 9000.
+// If path validation fails, the connection continues using the current path.
+func (s *connection) migrateToPreferredAddress(pa *wire.PreferredAddress) {
+	remoteAddr, ok := s.conn.RemoteAddr().(*net.UDPAddr)
+	if !ok {
+		return
+	}
+	// Only use the preferred address of the address family that we're already using,
+	// since we might not be able to send packets to the other address family.
+	addr := pa.IPv6
+	if remoteAddr.IP.To4() != nil {
+		addr = pa.IPv4
+	}
+	if !addr.IsValid() {
+		return
+	}
+	conn := s.conn.withRemoteAddr(net.UDPAddrFromAddrPort(addr), packetInfo{})
+	// The path uses the same Transport, so we don't need to register our connection IDs anywhere.
+	path, err := s.getPathManagerOutgoing().NewPath(nil, conn, s.rttStats.PTO(false), nil)
+	if err != nil {
+		s.logger.Debugf("Not migrating to the preferred address: %s", err)
+		return
+	}
+	// Use the recommended timeout for path validation, see section 8.2.4 of RFC 9000.
+	timeout := 3 * s.rttStats.PTO(false)
+	go func() {
+		ctx, cancel := context.WithTimeout(s.ctx, timeout)
+		defer cancel()
+		if err := path.Probe(ctx); err != nil {
+			s.logger.Debugf("Validating the preferred address %s failed: %s", addr, err)
+			path.Close()
+			return
+		}
+		if err := path.Switch(); err != nil {
+			s.logger.Debugf("Migrating to the preferred address %s failed: %s", addr, err)
+		}
+	}()
+}
+
+func (s *connection) handleOutgoingPaths(pm *pathManagerOutgoing, now time.Time) {
+	for _, conn := range pm.RetireAbandonedPaths() {
+		s.logger.Debugf("Abandoned path %s -> %s", conn.LocalAddr(), conn.RemoteAddr())
+		if s.tracer != nil && s.tracer.AbandonedPath != nil {
+			s.tracer.AbandonedPath(conn.LocalAddr(), conn.RemoteAddr())
+		}
+	}
+	if id, conn, ok := pm.ShouldSwitchPath(); ok {
+		s.switchToNewPath(id, conn, now)
+	}
+	for {
+		connID, frame, conn, ok := pm.NextPathToProbe()
+		if !ok {
+			return
+		}
+		s.sendPathProbePacket(connID, frame, conn, now)
+	}
+}
+
+func (s *connection) sendPathProbePacket(connID protocol.ConnectionID, frame ackhandler.Frame, conn sendConn, now time.Time) {
+	p, buf, err := s.packer.PackPathProbePacket(connID, []ackhandler.Frame{frame}, protocol.MinInitialPacketSize, s.version)
+	if err != nil {
+		s.logger.Debugf("Failed to pack path probe packet: %s", err)
+		return
+	}
+	ecn := s.sentPacketHandler.ECNMode(false)
+	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
+	// Path probe packets are not retransmitted, and don't count towards the congestion window of the active path.
+	// Register it as a non-ack-eliciting packet, such that the sent packet handler knows about the packet number.
+	s.sentPacketHandler.SentPacket(now, p.PacketNumber, protocol.InvalidPacketNumber, nil, nil, protocol.Encryption1RTT, ecn, buf.Len(), false)
+	// Failing to send on a new path doesn't affect the active path.
+	if err := conn.Write(buf.Data, 0, ecn, time.Time{}); err != nil {
+		s.logger.Debugf("Failed to send path probe packet from %s: %s", conn.LocalAddr(), err)
+	}
+	buf.Release()
+}
+
+func (s *connection) switchToNewPath(id pathID, conn sendConn, now time.Time) {
+	// Make sure that we don't use the same connection ID on the old and the new path.
+	s.connIDManager.GetConnIDForPath(id)
+	s.connIDManager.SwitchToPath(id)
+	s.migrate(conn, now)
+}
+
+// migrate switches the connection to a new path.
+// The path must have been validated before.
+func (s *connection) migrate(conn sendConn, now time.Time) {
+	s.logger.Debugf("Migrating to path %s -> %s", conn.LocalAddr(), conn.RemoteAddr())
+	initialPacketSize := protocol.ByteCount(s.config.InitialPacketSize)
+	s.sentPacketHandler.MigratedPath(now, initialPacketSize)
+	s.maxPayloadSizeEstimate.Store(uint32(estimateMaxPayloadSize(initialPacketSize)))
+	s.initMTUDiscoverer()
+	if !s.config.DisablePathMTUDiscovery && conn.capabilities().DF {
+		s.mtuDiscoverer.Start()
+	}
+
+	// make sure that all packets queued on the old path are sent out
+	s.sendQueue.Close()
+	s.connStateMutex.Lock()
+	s.conn = conn
+	s.connStateMutex.Unlock()
+	s.sendQueue = newSendQueue(conn)
+	go func() {
+		if err := s.sendQueue.Run(); err != nil {
+			s.destroyImpl(err)
+		}
+	}()
+	if s.tracer != nil && s.tracer.MigratedPath != nil {
+		s.tracer.MigratedPath(conn.LocalAddr(), conn.RemoteAddr())
+	}
+}
+
 func (s *connection) GetVersion() protocol.Version {
 	return s.version
 }
@@ -0,0 +1,615 @@
+package quic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// maybeEnableMultipath enables the multipath extension, if both endpoints support it.
+// Multipath can't be used with zero-length connection IDs.
+func (s *connection) maybeEnableMultipath(params *wire.TransportParameters) {
+	if !s.config.EnableMultipath || params.InitialMaxPathID == nil {
+		return
+	}
+	if s.srcConnIDLen == 0 || params.InitialSourceConnectionID.Len() == 0 {
+		return
+	}
+	var scheduler PathScheduler
+	if s.config.PathScheduler != nil {
+		scheduler = s.config.PathScheduler()
+	}
+	if scheduler == nil {
+		scheduler = NewMinRTTPathScheduler()
+	}
+	s.multipath = newMultipathManager(
+		s.perspective,
+		scheduler,
+		protocol.MaxMultipathPaths-1,
+		*params.InitialMaxPathID,
+		s.conn.LocalAddr(),
+		s.conn.RemoteAddr(),
+		s.queueControlFrame,
+		s.scheduleSending,
+	)
+	s.multipath.SetPTO(s.rttStats.PTO(false))
+	s.frameParser.SetSupportsMultipath()
+	s.issuePathConnIDs()
+}
+
+// issuePathConnIDs issues connection IDs for all paths allowed by the current path limit.
+func (s *connection) issuePathConnIDs() {
+	for _, id := range s.multipath.PathsToIssueConnIDs() {
+		if err := s.connIDGenerator.IssuePathConnIDs(id, protocol.MaxIssuedConnectionIDsPerPath); err != nil {
+			s.closeLocal(err)
+			return
+		}
+	}
+}
+
+// handleMultipathPacket handles a short header packet that was sent to a connection ID
+// that we issued for a path other than path 0.
+func (s *connection) handleMultipathPacket(p receivedPacket, id PathID, destConnID protocol.ConnectionID) (processed, wasQueued bool) {
+	path, ok := s.multipath.Path(id)
+	// Only the client opens new paths. The server sets up a path when receiving the first packet on it.
+	// Migration of individual paths is not supported.
+	drop := !s.handshakeConfirmed || s.multipath.IsAbandoned(id)
+	if s.perspective == protocol.PerspectiveClient {
+		drop = drop || !ok
+	} else if ok {
+		drop = drop || !addrsEqual(p.remoteAddr, path.conn.RemoteAddr())
+	}
+	if drop {
+		s.logger.Debugf("Dropping packet for path %d received from %s", id, p.remoteAddr)
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(logging.PacketType1RTT, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropUnexpectedPacket)
+		}
+		return false, false
+	}
+
+	largestRcvd := protocol.InvalidPacketNumber
+	if path != nil {
+		largestRcvd = path.largestRcvd
+	}
+	pn, pnLen, keyPhase, data, err := s.unpacker.UnpackPathShortHeader(p.rcvTime, p.data, id, largestRcvd)
+	if err != nil {
+		return false, s.handleUnpackError(err, p, logging.PacketType1RTT)
+	}
+	if path == nil {
+		// This is the first packet received on this path.
+		path, ok = s.multipath.AddIncomingPath(id, s.pathConn(p.rcvConn, p.remoteAddr, p.info))
+		if !ok {
+			s.logger.Debugf("Dropping packet for path %d: path can't be used", id)
+			if s.tracer != nil && s.tracer.DroppedPacket != nil {
+				s.tracer.DroppedPacket(logging.PacketType1RTT, pn, p.Size(), logging.PacketDropUnexpectedPacket)
+			}
+			return false, false
+		}
+		s.setUpPath(path)
+	}
+
+	if s.logger.Debug() {
+		s.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s, path %d, 1-RTT", pn, p.Size(), destConnID, id)
+		wire.LogShortHeader(s.logger, destConnID, pn, pnLen, keyPhase)
+	}
+
+	if path.rph.IsPotentiallyDuplicate(pn, protocol.Encryption1RTT) {
+		s.logger.Debugf("Dropping (potentially) duplicate packet.")
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(logging.PacketType1RTT, pn, p.Size(), logging.PacketDropDuplicate)
+		}
+		return false, false
+	}
+
+	var log func([]logging.Frame)
+	if s.tracer != nil && s.tracer.ReceivedShortHeaderPacket != nil {
+		log = func(frames []logging.Frame) {
+			s.tracer.ReceivedShortHeaderPacket(
+				&logging.ShortHeader{
+					DestConnectionID: destConnID,
+					PacketNumber:     pn,
+					PacketNumberLen:  pnLen,
+					KeyPhase:         keyPhase,
+				},
+				p.Size(),
+				p.ecn,
+				frames,
+			)
+		}
+	}
+
+	s.lastPacketReceivedTime = p.rcvTime
+	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
+	s.keepAlivePingSent = false
+
+	isAckEliciting, _, pathChallenge, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log, p.rcvTime)
+	if err != nil {
+		s.closeLocal(err)
+		return false, false
+	}
+	// The path might have been abandoned while handling the frames.
+	if s.multipath.IsAbandoned(id) {
+		return true, false
+	}
+	if err := path.rph.ReceivedPacket(pn, p.ecn, protocol.Encryption1RTT, p.rcvTime, isAckEliciting); err != nil {
+		s.closeLocal(err)
+		return false, false
+	}
+	if pn > path.largestRcvd {
+		path.largestRcvd = pn
+	}
+	if pathChallenge != nil {
+		s.sendPathResponse(path, pathChallenge, p.rcvTime)
+	}
+	return true, false
+}
+
+// setUpPath creates the state needed to send and receive packets on a path.
+func (s *connection) setUpPath(p *multipathPath) {
+	initialPacketSize := protocol.ByteCount(s.config.InitialPacketSize)
+	p.rttStats = &utils.RTTStats{}
+	p.rttStats.SetMaxAckDelay(s.peerParams.MaxAckDelay)
+	p.sph, p.rph = ackhandler.NewPathAckHandler(initialPacketSize, p.rttStats, p.conn.capabilities().ECN, s.config.CongestionControl, s.perspective, s.logger)
+	p.connIDManager = s.multipath.SetUp(p)
+	p.packer = newPathPacketPacker(
+		p.id,
+		p.connIDManager.Get,
+		p.sph,
+		s.retransmissionQueue,
+		s.sealingManager,
+		s.framer,
+		p.rph,
+		s.datagramQueue,
+		s.perspective,
+	)
+	if s.config.EnableQUICBitGreasing && s.peerParams.GreaseQUICBit {
+		p.packer.EnableQUICBitGreasing()
+	}
+	p.sendQueue = newSendQueue(p.conn)
+	go func() {
+		if err := p.sendQueue.Run(); err != nil {
+			s.destroyImpl(err)
+		}
+	}()
+	if p.tr != nil {
+		runner := p.tr.handlerMap
+		s.connIDGenerator.AddConnRunner(pathRunnerKey{kind: pathRunnerMultipath, id: uint64(p.id)}, connRunnerCallbacks{
+			AddConnectionID:    func(connID protocol.ConnectionID) { runner.Add(connID, s) },
+			RemoveConnectionID: runner.Remove,
+			RetireConnectionID: runner.Retire,
+			ReplaceWithClosed:  runner.ReplaceWithClosed,
+		})
+	}
+	s.logger.Debugf("Set up path %d: %s -> %s", p.id, p.conn.LocalAddr(), p.conn.RemoteAddr())
+}
+
+// sendPathResponse sends a PATH_RESPONSE frame on the path that the PATH_CHALLENGE was received on.
+// Until the path is validated, the server also sends a PATH_CHALLENGE to validate the client's address.
+func (s *connection) sendPathResponse(p *multipathPath, f *wire.PathChallengeFrame, now time.Time) {
+	frames := []ackhandler.Frame{{Frame: &wire.PathResponseFrame{Data: f.Data}}}
+	if challenge := s.multipath.PathChallengeForResponse(p); challenge != nil {
+		frames = append(frames, ackhandler.Frame{Frame: challenge})
+	}
+	s.sendMultipathProbePacket(p, frames, now)
+}
+
+func (s *connection) sendMultipathProbePacket(p *multipathPath, frames []ackhandler.Frame, now time.Time) {
+	packet, buf, err := p.packer.PackPathProbePacket(p.connIDManager.Get(), frames, protocol.MinInitialPacketSize, s.version)
+	if err != nil {
+		s.logger.Debugf("Failed to pack probe packet for path %d: %s", p.id, err)
+		return
+	}
+	ecn := p.sph.ECNMode(false)
+	s.logPathPacket(p, packet, ecn, buf.Len())
+	// Probe packets are not retransmitted. Register it as a non-ack-eliciting packet,
+	// such that the sent packet handler knows about the packet number.
+	p.sph.SentPacket(now, packet.PacketNumber, protocol.InvalidPacketNumber, nil, nil, protocol.Encryption1RTT, ecn, buf.Len(), false)
+	p.sendQueue.Send(buf, 0, ecn, time.Time{})
+}
+
+// handleMultipathPaths sets up paths opened by the application, sends PATH_CHALLENGE frames
+// on paths that are being validated and abandons paths closed by the application.
+func (s *connection) handleMultipathPaths(now time.Time) {
+	s.multipath.SetPTO(s.rttStats.PTO(false))
+	for _, p := range s.multipath.PathsToSetUp() {
+		// Incoming paths are set up when the first packet is received.
+		if p.tr != nil {
+			s.setUpPath(p)
+		}
+	}
+	for {
+		p, f, ok := s.multipath.NextPathChallenge()
+		if !ok {
+			break
+		}
+		s.sendMultipathProbePacket(p, []ackhandler.Frame{{Frame: f}}, now)
+	}
+	for _, p := range s.multipath.PathsToClose() {
+		s.abandonPath(p.id, now)
+	}
+	for _, p := range s.multipath.ActivePaths() {
+		if timeout := p.sph.GetLossDetectionTimeout(); !timeout.IsZero() && timeout.Before(now) {
+			if err := p.sph.OnLossDetectionTimeout(); err != nil {
+				s.closeLocal(err)
+			}
+		}
+	}
+}
+
+// multipathTimers returns the earliest ACK alarm and loss detection timeout of all paths other than path 0.
+func (s *connection) multipathTimers() (ackAlarm, lossTime time.Time) {
+	for _, p := range s.multipath.ActivePaths() {
+		if t := p.rph.GetAlarmTimeout(); !t.IsZero() && (ackAlarm.IsZero() || t.Before(ackAlarm)) {
+			ackAlarm = t
+		}
+		if t := p.sph.GetLossDetectionTimeout(); !t.IsZero() && (lossTime.IsZero() || t.Before(lossTime)) {
+			lossTime = t
+		}
+	}
+	return ackAlarm, lossTime
+}
+
+// abandonPath abandons a path, and sends a PATH_ABANDON frame to the peer.
+// If the path was abandoned by the peer, this echoes the peer's PATH_ABANDON frame.
+func (s *connection) abandonPath(id PathID, now time.Time) {
+	p, connIDManager, newMaxPathID := s.multipath.Abandon(id)
+	if newMaxPathID == 0 {
+		return // already abandoned
+	}
+	if p != nil {
+		// Retransmit all frames in flight on this path on the remaining paths.
+		p.sph.MigratedPath(now, protocol.ByteCount(s.config.InitialPacketSize))
+		p.sendQueue.Close()
+		if p.tr != nil {
+			s.connIDGenerator.RemoveConnRunner(pathRunnerKey{kind: pathRunnerMultipath, id: uint64(id)})
+		}
+		s.logger.Debugf("Abandoned path %d: %s -> %s", id, p.conn.LocalAddr(), p.conn.RemoteAddr())
+		if s.tracer != nil && s.tracer.AbandonedPath != nil {
+			s.tracer.AbandonedPath(p.conn.LocalAddr(), p.conn.RemoteAddr())
+		}
+	}
+	if connIDManager != nil {
+		connIDManager.Close()
+	}
+	s.connIDGenerator.RemovePath(id)
+	s.queueControlFrame(&wire.PathAbandonFrame{PathID: id})
+	s.queueControlFrame(&wire.MaxPathIDFrame{MaxPathID: newMaxPathID})
+	s.issuePathConnIDs()
+}
+
+func (s *connection) handlePathAckFrame(f *wire.PathAckFrame) error {
+	if f.PathID == 0 {
+		return s.handleAckFrame(&f.AckFrame, protocol.Encryption1RTT)
+	}
+	p, ok := s.multipath.Path(f.PathID)
+	if !ok {
+		// The path might already have been abandoned.
+		return nil
+	}
+	_, err := p.sph.ReceivedAck(&f.AckFrame, protocol.Encryption1RTT, s.lastPacketReceivedTime)
+	return err
+}
+
+func (s *connection) handlePathAbandonFrame(f *wire.PathAbandonFrame, rcvTime time.Time) {
+	if f.PathID == 0 {
+		s.logger.Debugf("Ignoring PATH_ABANDON frame for path 0")
+		return
+	}
+	s.abandonPath(f.PathID, rcvTime)
+}
+
+func (s *connection) handleMPNewConnectionIDFrame(f *wire.MPNewConnectionIDFrame) error {
+	if f.PathID == 0 {
+		return s.connIDManager.Add(&f.NewConnectionIDFrame)
+	}
+	err := s.multipath.AddConnectionID(f, func() *connIDManager {
+		return newPathConnIDManager(
+			&f.NewConnectionIDFrame,
+			s.connIDManager.addStatelessResetToken,
+			s.connIDManager.removeStatelessResetToken,
+			func(frame wire.Frame) {
+				if rf, ok := frame.(*wire.RetireConnectionIDFrame); ok {
+					frame = &wire.MPRetireConnectionIDFrame{PathID: f.PathID, SequenceNumber: rf.SequenceNumber}
+				}
+				s.queueControlFrame(frame)
+			},
+		)
+	})
+	if err != nil {
+		var transportErr *qerr.TransportError
+		if errors.As(err, &transportErr) {
+			return err
+		}
+		return &qerr.TransportError{ErrorCode: qerr.ProtocolViolation, ErrorMessage: err.Error()}
+	}
+	return nil
+}
+
+func (s *connection) handleMPRetireConnectionIDFrame(f *wire.MPRetireConnectionIDFrame, destConnID protocol.ConnectionID) error {
+	if f.PathID == 0 {
+		return s.connIDGenerator.Retire(f.SequenceNumber, destConnID)
+	}
+	return s.connIDGenerator.RetirePathConnID(f.PathID, f.SequenceNumber, destConnID)
+}
+
+func (s *connection) handleMaxPathIDFrame(f *wire.MaxPathIDFrame) {
+	if s.multipath.HandleMaxPathIDFrame(f) {
+		s.issuePathConnIDs()
+	}
+}
+
+// triggerSendingMultipath sends packets once paths other than path 0 were validated.
+func (s *connection) triggerSendingMultipath(now time.Time) error {
+	// Send PTO probe packets first.
+	if s.sentPacketHandler.SendMode(now) == ackhandler.SendPTOAppData && !s.sendQueue.WouldBlock() {
+		if err := s.sendProbePacket(protocol.Encryption1RTT, now); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.multipath.ActivePaths() {
+		if p.sph.SendMode(now) == ackhandler.SendPTOAppData && !p.sendQueue.WouldBlock() {
+			if err := s.sendPathPTOProbePacket(p, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.sendPackets(now); err != nil {
+		return err
+	}
+
+	// Send ACK-only packets on all paths that have an ACK to send.
+	if s.sentPacketHandler.SendMode(now) != ackhandler.SendNone {
+		if err := s.maybeSendAckOnlyPacket(now); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.multipath.ActivePaths() {
+		if p.sph.SendMode(now) == ackhandler.SendNone || p.sendQueue.WouldBlock() {
+			continue
+		}
+		ecn := p.sph.ECNMode(true)
+		packet, buf, err := p.packer.PackAckOnlyPacket(protocol.ByteCount(s.config.InitialPacketSize), now, s.version)
+		if err != nil {
+			if err == errNothingToPack {
+				continue
+			}
+			return err
+		}
+		s.logPathPacket(p, packet, ecn, buf.Len())
+		s.registerPathPacket(p, packet, ecn, now)
+		p.sendQueue.Send(buf, 0, ecn, time.Time{})
+	}
+	return nil
+}
+
+func (s *connection) sendPathPTOProbePacket(p *multipathPath, now time.Time) error {
+	maxSize := protocol.ByteCount(s.config.InitialPacketSize)
+	var packet *coalescedPacket
+	for {
+		if wasQueued := p.sph.QueueProbePacket(protocol.Encryption1RTT); !wasQueued {
+			break
+		}
+		var err error
+		packet, err = p.packer.MaybePackProbePacket(protocol.Encryption1RTT, maxSize, now, s.version)
+		if err != nil {
+			return err
+		}
+		if packet != nil {
+			break
+		}
+	}
+	if packet == nil {
+		s.retransmissionQueue.AddPing(protocol.Encryption1RTT)
+		var err error
+		packet, err = p.packer.MaybePackProbePacket(protocol.Encryption1RTT, maxSize, now, s.version)
+		if err != nil {
+			return err
+		}
+	}
+	if packet == nil || packet.shortHdrPacket == nil {
+		return fmt.Errorf("connection BUG: couldn't pack probe packet for path %d", p.id)
+	}
+	ecn := p.sph.ECNMode(true)
+	s.logPathPacket(p, *packet.shortHdrPacket, ecn, packet.buffer.Len())
+	s.registerPathPacket(p, *packet.shortHdrPacket, ecn, now)
+	p.sendQueue.Send(packet.buffer, 0, ecn, time.Time{})
+	return nil
+}
+
+// sendPacketsMultipath sends packets on all validated paths.
+// For every packet, the PathScheduler selects the path among the paths that the congestion controller allows sending on.
+func (s *connection) sendPacketsMultipath(now time.Time) error {
+	paths := make(map[PathID]*multipathPath)
+	states := make([]PathState, 0, protocol.MaxMultipathPaths)
+	for {
+		clear(paths)
+		states = states[:0]
+		var pacingDeadline time.Time
+		updatePacingDeadline := func(t time.Time) {
+			if t.IsZero() {
+				t = deadlineSendImmediately
+			}
+			if pacingDeadline.IsZero() || t.Before(pacingDeadline) {
+				pacingDeadline = t
+			}
+		}
+
+		var hasActive bool
+		switch s.sentPacketHandler.SendMode(now) {
+		case ackhandler.SendAny:
+			if !s.sendQueue.WouldBlock() {
+				standby := s.multipath.Path0Standby()
+				hasActive = !standby
+				states = append(states, PathState{
+					ID:          0,
+					LocalAddr:   s.conn.LocalAddr(),
+					RemoteAddr:  s.conn.RemoteAddr(),
+					SmoothedRTT: s.rttStats.SmoothedRTT(),
+					Standby:     standby,
+				})
+			}
+		case ackhandler.SendPacingLimited:
+			updatePacingDeadline(s.sentPacketHandler.TimeUntilSend())
+		}
+		for _, p := range s.multipath.ValidatedPaths() {
+			switch p.sph.SendMode(now) {
+			case ackhandler.SendAny:
+				if p.sendQueue.WouldBlock() {
+					continue
+				}
+				standby := s.multipath.PeerStandby(p)
+				if !standby {
+					hasActive = true
+				}
+				paths[p.id] = p
+				states = append(states, PathState{
+					ID:          p.id,
+					LocalAddr:   p.conn.LocalAddr(),
+					RemoteAddr:  p.conn.RemoteAddr(),
+					SmoothedRTT: p.rttStats.SmoothedRTT(),
+					Standby:     standby,
+				})
+			case ackhandler.SendPacingLimited:
+				updatePacingDeadline(p.sph.TimeUntilSend())
+			}
+		}
+		if len(states) == 0 {
+			s.pacingDeadline = pacingDeadline
+			return nil
+		}
+		// Only use standby paths if no other path is available.
+		if hasActive {
+			n := 0
+			for _, st := range states {
+				if !st.Standby {
+					states[n] = st
+					n++
+				}
+			}
+			states = states[:n]
+		}
+
+		id := s.multipath.scheduler.SelectPath(states)
+		buf := getPacketBuffer()
+		if id == 0 {
+			ecn := s.sentPacketHandler.ECNMode(true)
+			if _, err := s.appendOneShortHeaderPacket(buf, s.maxPacketSize(), ecn, now); err != nil {
+				buf.Release()
+				if err == errNothingToPack {
+					return nil
+				}
+				return err
+			}
+			s.sendQueue.Send(buf, 0, ecn, time.Time{})
+		} else {
+			p, ok := paths[id]
+			if !ok {
+				buf.Release()
+				return fmt.Errorf("path scheduler selected an invalid path: %d", id)
+			}
+			ecn := p.sph.ECNMode(true)
+			packet, err := p.packer.AppendPacket(buf, protocol.ByteCount(s.config.InitialPacketSize), now, s.version)
+			if err != nil {
+				buf.Release()
+				if err == errNothingToPack {
+					return nil
+				}
+				return err
+			}
+			s.logPathPacket(p, packet, ecn, buf.Len())
+			s.registerPathPacket(p, packet, ecn, now)
+			p.sendQueue.Send(buf, 0, ecn, time.Time{})
+		}
+
+		// Prioritize receiving of packets over sending out more packets.
+		if len(s.receivedPackets) > 0 {
+			s.pacingDeadline = deadlineSendImmediately
+			return nil
+		}
+	}
+}
+
+func (s *connection) registerPathPacket(p *multipathPath, packet shortHeaderPacket, ecn protocol.ECN, now time.Time) {
+	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && (len(packet.StreamFrames) > 0 || ackhandler.HasAckElicitingFrames(packet.Frames)) {
+		s.firstAckElicitingPacketAfterIdleSentTime = now
+	}
+	largestAcked := protocol.InvalidPacketNumber
+	if packet.Ack != nil {
+		largestAcked = packet.Ack.LargestAcked()
+	}
+	p.sph.SentPacket(now, packet.PacketNumber, largestAcked, packet.StreamFrames, packet.Frames, protocol.Encryption1RTT, ecn, packet.Length, false)
+	p.connIDManager.SentPacket()
+}
+
+// logPathPacket logs a packet sent on a path other than path 0.
+// The ACK frame of such a packet is sent as a PATH_ACK frame.
+func (s *connection) logPathPacket(p *multipathPath, packet shortHeaderPacket, ecn protocol.ECN, size protocol.ByteCount) {
+	frames := packet.Frames
+	if packet.Ack != nil {
+		frames = make([]ackhandler.Frame, 0, len(packet.Frames)+1)
+		frames = append(frames, ackhandler.Frame{Frame: &wire.PathAckFrame{PathID: p.id, AckFrame: *packet.Ack}})
+		frames = append(frames, packet.Frames...)
+	}
+	if s.logger.Debug() {
+		s.logger.Debugf("Sending packet on path %d", p.id)
+	}
+	s.logShortHeaderPacket(packet.DestConnID, nil, frames, packet.StreamFrames, packet.PacketNumber, packet.PacketNumberLen, packet.KeyPhase, ecn, size, false)
+}
+
+// OpenPath opens a new path using the multipath extension.
+func (s *connection) OpenPath(ctx context.Context, t *Transport) (*MultipathPath, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return nil, errors.New("server cannot open paths")
+	}
+	select {
+	case <-s.HandshakeComplete():
+	default:
+		return nil, errors.New("can only open paths after the handshake completed")
+	}
+	if s.multipath == nil {
+		return nil, errors.New("multipath extension not negotiated")
+	}
+	if err := t.init(false); err != nil {
+		return nil, err
+	}
+	if t.connIDLen != s.srcConnIDLen {
+		return nil, fmt.Errorf("transport uses connection IDs of length %d, connection uses %d", t.connIDLen, s.srcConnIDLen)
+	}
+	for _, p := range s.multipath.Paths() {
+		if t.conn.LocalAddr().String() == p.LocalAddr().String() {
+			return nil, errors.New("path already in use")
+		}
+	}
+	m := s.multipath
+	handle, p, err := m.NewPath(t, newSendConn(t.conn, s.RemoteAddr(), packetInfo{}, s.logger))
+	if err != nil {
+		return nil, err
+	}
+	if err := m.probe(ctx, s.ctx, p); err != nil {
+		handle.Close()
+		return nil, err
+	}
+	return handle, nil
+}
+
+// Paths returns all paths of a multipath connection.
+func (s *connection) Paths() []*MultipathPath {
+	select {
+	case <-s.HandshakeComplete():
+	default:
+		return nil
+	}
+	if s.multipath == nil {
+		return nil
+	}
+	return s.multipath.Paths()
+}
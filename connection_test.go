@@ -108,7 +108,8 @@ var _ = Describe("Connection", func() {
 		mconn.EXPECT().capabilities().DoAndReturn(func() connCapabilities { return capabilities }).AnyTimes()
 		mconn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
-		tokenGenerator := handshake.NewTokenGenerator([32]byte{0xa, 0xb, 0xc})
+		tokenProvider, err := NewDefaultTokenProvider(TokenKey{Key: [32]byte{0xa, 0xb, 0xc}})
+		Expect(err).ToNot(HaveOccurred())
 		var tr *logging.ConnectionTracer
 		tr, tracer = mocklogging.NewMockConnectionTracer(mockCtrl)
 		tracer.EXPECT().NegotiatedVersion(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(1)
@@ -130,7 +131,8 @@ var _ = Describe("Connection", func() {
 			protocol.StatelessResetToken{},
 			populateConfig(&Config{DisablePathMTUDiscovery: true}),
 			&tls.Config{},
-			tokenGenerator,
+			tokenProvider,
+			nil,
 			false,
 			tr,
 			utils.DefaultLogger,
@@ -196,6 +198,21 @@ var _ = Describe("Connection", func() {
 				err := conn.handleAckFrame(f, protocol.EncryptionHandshake)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("updates the PTO used for path probes", func() {
+				f := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedAck(f, protocol.EncryptionHandshake, gomock.Any()).DoAndReturn(
+					func(*wire.AckFrame, protocol.EncryptionLevel, time.Time) (bool, error) {
+						conn.rttStats.UpdateRTT(time.Second, 0, time.Now())
+						return false, nil
+					},
+				)
+				conn.sentPacketHandler = sph
+				Expect(conn.handleAckFrame(f, protocol.EncryptionHandshake)).To(Succeed())
+				Expect(time.Duration(conn.pto.Load())).To(Equal(conn.rttStats.PTO(false)))
+				Expect(time.Duration(conn.pto.Load())).To(BeNumerically(">", time.Second))
+			})
 		})
 
 		Context("handling RESET_STREAM frames", func() {
@@ -322,8 +339,7 @@ var _ = Describe("Connection", func() {
 
 		It("handles PATH_CHALLENGE frames", func() {
 			data := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
-			err := conn.handleFrame(&wire.PathChallengeFrame{Data: data}, protocol.Encryption1RTT, protocol.ConnectionID{}, time.Now())
-			Expect(err).ToNot(HaveOccurred())
+			conn.handlePathChallengeFrame(&wire.PathChallengeFrame{Data: data})
 			frames, _ := conn.framer.AppendControlFrames(nil, 1000, time.Now(), protocol.Version1)
 			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.PathResponseFrame{Data: data}}}))
 		})
@@ -350,6 +366,28 @@ var _ = Describe("Connection", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("handles ACK_FREQUENCY frames", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			conn.receivedPacketHandler = rph
+			f := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond}
+			rph.EXPECT().ReceivedAckFrequencyFrame(f)
+			Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{}, time.Now())).To(Succeed())
+		})
+
+		It("rejects ACK_FREQUENCY frames requesting a delay smaller than min_ack_delay", func() {
+			err := conn.handleFrame(&wire.AckFrequencyFrame{RequestMaxAckDelay: protocol.MinAckDelay / 2}, protocol.Encryption1RTT, protocol.ConnectionID{}, time.Now())
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
+			Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.ProtocolViolation))
+		})
+
+		It("handles IMMEDIATE_ACK frames", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			conn.receivedPacketHandler = rph
+			rph.EXPECT().ReceivedImmediateAckFrame()
+			Expect(conn.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{}, time.Now())).To(Succeed())
+		})
+
 		It("handles CONNECTION_CLOSE frames, with a transport error code", func() {
 			expectedErr := &qerr.TransportError{
 				Remote:       true,
@@ -466,7 +504,7 @@ var _ = Describe("Connection", func() {
 				Expect(e.ErrorMessage).To(BeEmpty())
 				return &coalescedPacket{buffer: buffer}, nil
 			})
-			mconn.EXPECT().Write([]byte("connection close"), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write([]byte("connection close"), gomock.Any(), gomock.Any(), time.Time{})
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(gomock.Any()).Do(func(e error) {
 					var appErr *ApplicationError
@@ -487,7 +525,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.CloseWithError(0, "")
@@ -506,7 +544,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackApplicationClose(expectedErr, gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(expectedErr),
 				tracer.EXPECT().Close(),
@@ -528,7 +566,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
 			packer.EXPECT().PackConnectionClose(expectedErr, gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			gomock.InOrder(
 				tracer.EXPECT().ClosedConnection(expectedErr),
 				tracer.EXPECT().Close(),
@@ -570,7 +608,7 @@ var _ = Describe("Connection", func() {
 			cryptoSetup.EXPECT().Close()
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			connRunner.EXPECT().ReplaceWithClosed(gomock.Any(), gomock.Any()).AnyTimes()
-			b, err := wire.AppendShortHeader(nil, srcConnID, 42, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
+			b, err := wire.AppendShortHeader(nil, srcConnID, 42, protocol.PacketNumberLen2, protocol.KeyPhaseOne, false)
 			Expect(err).ToNot(HaveOccurred())
 
 			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).DoAndReturn(func(time.Time, []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
@@ -586,7 +624,7 @@ var _ = Describe("Connection", func() {
 			// don't EXPECT any calls to packer.PackPacket()
 			conn.handlePacket(receivedPacket{
 				rcvTime:    time.Now(),
-				remoteAddr: &net.UDPAddr{},
+				remoteAddr: remoteAddr,
 				buffer:     getPacketBuffer(),
 				data:       b,
 			})
@@ -598,7 +636,7 @@ var _ = Describe("Connection", func() {
 			conn.handshakeConfirmed = true
 			sconn := NewMockSendConn(mockCtrl)
 			sconn.EXPECT().capabilities().AnyTimes()
-			sconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Return(io.ErrClosedPipe).AnyTimes()
+			sconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(io.ErrClosedPipe).AnyTimes()
 			conn.sendQueue = newSendQueue(sconn)
 			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
 			sph.EXPECT().GetLossDetectionTimeout().Return(time.Now().Add(time.Hour)).AnyTimes()
@@ -645,12 +683,13 @@ var _ = Describe("Connection", func() {
 		})
 
 		getShortHeaderPacket := func(connID protocol.ConnectionID, pn protocol.PacketNumber, data []byte) receivedPacket {
-			b, err := wire.AppendShortHeader(nil, connID, pn, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
+			b, err := wire.AppendShortHeader(nil, connID, pn, protocol.PacketNumberLen2, protocol.KeyPhaseOne, false)
 			Expect(err).ToNot(HaveOccurred())
 			return receivedPacket{
-				data:    append(b, data...),
-				buffer:  getPacketBuffer(),
-				rcvTime: time.Now(),
+				remoteAddr: remoteAddr,
+				data:       append(b, data...),
+				buffer:     getPacketBuffer(),
+				rcvTime:    time.Now(),
 			}
 		}
 
@@ -735,6 +774,46 @@ var _ = Describe("Connection", func() {
 			Expect(conn.handlePacketImpl(p)).To(BeFalse())
 		})
 
+		It("accepts packets using the original version after switching to a compatible version", func() {
+			conn.version = protocol.Version2
+			conn.originalVersion = protocol.Version1
+			getPacket := func(v protocol.Version) receivedPacket {
+				b, err := (&wire.ExtendedHeader{
+					Header: wire.Header{
+						Type:             protocol.PacketTypeInitial,
+						DestConnectionID: srcConnID,
+						Version:          v,
+						Length:           1,
+					},
+					PacketNumber:    0x37,
+					PacketNumberLen: protocol.PacketNumberLen1,
+				}).Append(nil, v)
+				Expect(err).ToNot(HaveOccurred())
+				return receivedPacket{data: b, buffer: getPacketBuffer(), rcvTime: time.Now()}
+			}
+			unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any()).DoAndReturn(func(hdr *wire.Header, _ []byte) (*unpackedPacket, error) {
+				return &unpackedPacket{
+					encryptionLevel: protocol.EncryptionInitial,
+					hdr:             &wire.ExtendedHeader{Header: *hdr, PacketNumber: 0x1337, PacketNumberLen: protocol.PacketNumberLen1},
+					data:            []byte{0}, // one PADDING frame
+				}, nil
+			}).Times(2)
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			rph.EXPECT().IsPotentiallyDuplicate(gomock.Any(), gomock.Any()).AnyTimes()
+			rph.EXPECT().ReceivedPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			conn.receivedPacketHandler = rph
+			tracer.EXPECT().StartedConnection(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			Expect(conn.handlePacketImpl(getPacket(protocol.Version1))).To(BeTrue())
+			Expect(conn.originalVersion).To(Equal(protocol.Version1))
+			// the client switched to the negotiated version
+			Expect(conn.handlePacketImpl(getPacket(protocol.Version2))).To(BeTrue())
+			Expect(conn.originalVersion).To(BeZero())
+			p := getPacket(protocol.Version1)
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropUnexpectedVersion)
+			Expect(conn.handlePacketImpl(p)).To(BeFalse())
+		})
+
 		It("informs the ReceivedPacketHandler about non-ack-eliciting packets", func() {
 			hdr := &wire.ExtendedHeader{
 				Header: wire.Header{
@@ -829,7 +908,7 @@ var _ = Describe("Connection", func() {
 			// make the go routine return
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			conn.closeLocal(errors.New("close"))
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -863,7 +942,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			conn.closeLocal(errors.New("close"))
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -898,7 +977,7 @@ var _ = Describe("Connection", func() {
 			expectReplaceWithClosed()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			conn.closeLocal(errors.New("close"))
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -920,7 +999,7 @@ var _ = Describe("Connection", func() {
 				close(done)
 			}()
 			expectReplaceWithClosed()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			packet := getShortHeaderPacket(srcConnID, 0x42, nil)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
@@ -948,7 +1027,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			conn.CloseWithError(0, "")
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -970,7 +1049,7 @@ var _ = Describe("Connection", func() {
 				close(done)
 			}()
 			expectReplaceWithClosed()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.handlePacket(getShortHeaderPacket(srcConnID, 0x42, nil))
@@ -1038,12 +1117,238 @@ var _ = Describe("Connection", func() {
 			Expect(conn.undecryptablePackets).To(Equal([]receivedPacket{packet}))
 		})
 
-		Context("updating the remote address", func() {
-			It("doesn't support connection migration", func() {
-				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* one PADDING frame */, nil)
-				packet := getShortHeaderPacket(srcConnID, 0x42, nil)
-				packet.remoteAddr = &net.IPAddr{IP: net.IPv4(192, 168, 0, 100)}
-				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any(), gomock.Any())
+		Context("connection migration", func() {
+			newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 4242}
+
+			BeforeEach(func() {
+				conn.config.EnableActiveMigration = true
+			})
+
+			// getPacketWithConnIDFromNewAddr returns a packet that the client sent from a new address.
+			// Using the connection ID of the active path, this simulates a NAT rebinding.
+			getPacketWithConnIDFromNewAddr := func(connID protocol.ConnectionID, pn protocol.PacketNumber, frames ...wire.Frame) receivedPacket {
+				var b []byte
+				for _, f := range frames {
+					var err error
+					b, err = f.Append(b, conn.version)
+					Expect(err).ToNot(HaveOccurred())
+				}
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+				packet := getShortHeaderPacket(connID, pn, nil)
+				packet.remoteAddr = newAddr
+				return packet
+			}
+			getPacketFromNewAddr := func(pn protocol.PacketNumber, frames ...wire.Frame) receivedPacket {
+				return getPacketWithConnIDFromNewAddr(srcConnID, pn, frames...)
+			}
+			newConnID := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad, 0xde, 0xca, 0xfb, 0xad})
+
+			It("drops packets from a new address before the handshake is confirmed", func() {
+				packet := getPacketFromNewAddr(10, &wire.PingFrame{})
+				tracer.EXPECT().DroppedPacket(logging.PacketType1RTT, protocol.PacketNumber(10), packet.Size(), logging.PacketDropUnexpectedPacket)
+				Expect(conn.handlePacketImpl(packet)).To(BeFalse())
+			})
+
+			It("drops packets from a new address using a new connection ID if active migration is disabled", func() {
+				conn.handshakeConfirmed = true
+				conn.config.EnableActiveMigration = false
+				packet := getPacketWithConnIDFromNewAddr(newConnID, 10, &wire.PingFrame{})
+				tracer.EXPECT().DroppedPacket(logging.PacketType1RTT, protocol.PacketNumber(10), packet.Size(), logging.PacketDropUnexpectedPacket)
+				Expect(conn.handlePacketImpl(packet)).To(BeFalse())
+				Expect(conn.pathManager).To(BeNil())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			It("validates NAT rebindings, even if active migration is disabled", func() {
+				conn.handshakeConfirmed = true
+				conn.config.EnableActiveMigration = false
+				conn.config.AllowMigration = func(Connection, net.Addr) bool {
+					Fail("AllowMigration shouldn't be called for NAT rebindings")
+					return false
+				}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				conn.sentPacketHandler = sph
+				sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+				sph.EXPECT().ECNMode(false).Return(protocol.ECNUnsupported).AnyTimes()
+				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				pathConn := NewMockSendConn(mockCtrl)
+				pathConn.EXPECT().Write([]byte("probe"), uint16(0), protocol.ECNUnsupported, time.Time{})
+				mconn.EXPECT().withRemoteAddr(newAddr, gomock.Any()).Return(pathConn)
+
+				packet := getPacketFromNewAddr(10, &wire.PingFrame{})
+				packet.data = append(packet.data, make([]byte, 50)...)
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), 3*packet.Size(), conn.version).DoAndReturn(
+					func(_ protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+						Expect(frames).To(HaveLen(1))
+						Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+						buf := getPacketBuffer()
+						buf.Data = append(buf.Data, []byte("probe")...)
+						return shortHeaderPacket{PacketNumber: 1, Frames: frames}, buf, nil
+					},
+				)
+				tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+			})
+
+			It("drops packets from a new address if the application rejects the migration", func() {
+				conn.handshakeConfirmed = true
+				var calls int
+				conn.config.AllowMigration = func(c Connection, addr net.Addr) bool {
+					Expect(c).To(Equal(conn))
+					Expect(addr).To(Equal(newAddr))
+					calls++
+					return false
+				}
+				for pn := protocol.PacketNumber(10); pn < 13; pn++ {
+					packet := getPacketWithConnIDFromNewAddr(newConnID, pn, &wire.PingFrame{})
+					tracer.EXPECT().DroppedPacket(logging.PacketType1RTT, pn, packet.Size(), logging.PacketDropUnexpectedPacket)
+					Expect(conn.handlePacketImpl(packet)).To(BeFalse())
+				}
+				// the decision is cached
+				Expect(calls).To(Equal(1))
+			})
+
+			It("validates the new path, and switches to it", func() {
+				conn.handshakeConfirmed = true
+				conn.peerParams = &wire.TransportParameters{}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				conn.sentPacketHandler = sph
+				sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+				sph.EXPECT().ECNMode(false).Return(protocol.ECNUnsupported).AnyTimes()
+				sender := NewMockSender(mockCtrl)
+				conn.sendQueue = sender
+				pathConn := NewMockSendConn(mockCtrl)
+				pathConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				pathConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+				pathConn.EXPECT().capabilities().AnyTimes()
+				mconn.EXPECT().withRemoteAddr(newAddr, gomock.Any()).Return(pathConn).AnyTimes()
+
+				// The first packet received on the new path is answered with a PATH_CHALLENGE.
+				packet := getPacketFromNewAddr(10, &wire.PingFrame{})
+				// make sure that the PATH_CHALLENGE fits into the anti-amplification limit
+				packet.data = append(packet.data, make([]byte, 50)...)
+				var pathChallenge *wire.PathChallengeFrame
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), 3*packet.Size(), conn.version).DoAndReturn(
+					func(_ protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+						Expect(frames).To(HaveLen(1))
+						Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+						pathChallenge = frames[0].Frame.(*wire.PathChallengeFrame)
+						buf := getPacketBuffer()
+						buf.Data = append(buf.Data, []byte("probe")...)
+						return shortHeaderPacket{PacketNumber: 1, DestConnID: destConnID, Frames: frames}, buf, nil
+					},
+				)
+				tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(1), protocol.InvalidPacketNumber, nil, nil, protocol.Encryption1RTT, protocol.ECNUnsupported, protocol.ByteCount(5), false)
+				pathConn.EXPECT().Write([]byte("probe"), uint16(0), protocol.ECNUnsupported, time.Time{})
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+
+				// The PATH_RESPONSE validates the path, and the connection switches to the new path.
+				packet = getPacketFromNewAddr(11, &wire.PathResponseFrame{Data: pathChallenge.Data}, &wire.PingFrame{})
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				tracer.EXPECT().ValidatedPath(localAddr, newAddr)
+				sph.EXPECT().MigratedPath(gomock.Any(), protocol.ByteCount(protocol.InitialPacketSize))
+				sender.EXPECT().Close()
+				tracer.EXPECT().MigratedPath(localAddr, newAddr)
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				Expect(conn.RemoteAddr()).To(Equal(newAddr))
+				conn.sendQueue.Close()
+			})
+
+			It("responds to PATH_CHALLENGE frames on the new path", func() {
+				conn.handshakeConfirmed = true
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				conn.sentPacketHandler = sph
+				sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+				sph.EXPECT().ECNMode(false).Return(protocol.ECNUnsupported).AnyTimes()
+				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				pathConn := NewMockSendConn(mockCtrl)
+				pathConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				mconn.EXPECT().withRemoteAddr(newAddr, gomock.Any()).Return(pathConn)
+
+				packet := getPacketFromNewAddr(10, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+				packet.data = append(packet.data, make([]byte, 1200)...)
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), protocol.ByteCount(protocol.MinInitialPacketSize), conn.version).DoAndReturn(
+					func(_ protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+						Expect(frames).To(HaveLen(2))
+						Expect(frames[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))
+						Expect(frames[1].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+						return shortHeaderPacket{PacketNumber: 1, Frames: frames}, getPacketBuffer(), nil
+					},
+				)
+				tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				// the PATH_RESPONSE is not sent on the active path
+				Expect(conn.framer.HasData()).To(BeFalse())
+			})
+
+			It("registers with the preferred address Transports", func() {
+				phm := NewMockPacketHandlerManager(mockCtrl)
+				rawConn := NewMockRawConn(mockCtrl)
+				tr := &Transport{handlerMap: phm, conn: rawConn}
+				var handlers []packetHandler
+				var connIDs []protocol.ConnectionID
+				phm.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(c protocol.ConnectionID, h packetHandler) bool {
+					connIDs = append(connIDs, c)
+					handlers = append(handlers, h)
+					return true
+				}).Times(3)
+				conn.connIDGenerator.generator = &protocol.DefaultConnectionIDGenerator{ConnLen: 8}
+				connRunner.EXPECT().Add(gomock.Any(), conn)
+				connRunner.EXPECT().GetStatelessResetToken(gomock.Any()).Return(protocol.StatelessResetToken{42})
+				// the IPv4 and the IPv6 address are bound to the same Transport
+				pa, err := conn.setupPreferredAddress(&PreferredAddress{
+					IPv4:          netip.MustParseAddrPort("192.0.2.1:443"),
+					IPv6:          netip.MustParseAddrPort("[2001:db8::1]:443"),
+					IPv4Transport: tr,
+					IPv6Transport: tr,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(pa.IPv4).To(Equal(netip.MustParseAddrPort("192.0.2.1:443")))
+				Expect(pa.IPv6).To(Equal(netip.MustParseAddrPort("[2001:db8::1]:443")))
+				Expect(pa.ConnectionID.Len()).To(Equal(8))
+				Expect(pa.StatelessResetToken).To(Equal(protocol.StatelessResetToken{42}))
+				Expect(connIDs).To(ContainElements(srcConnID, clientDestConnID, pa.ConnectionID))
+
+				// packets received on the preferred address are passed to the connection
+				handlers[0].handlePacket(receivedPacket{remoteAddr: remoteAddr})
+				var p receivedPacket
+				Expect(conn.receivedPackets).To(Receive(&p))
+				Expect(p.rcvConn).To(Equal(rawConn))
+			})
+
+			It("validates paths to the preferred address, even if active migration is disabled", func() {
+				conn.handshakeConfirmed = true
+				conn.config.EnableActiveMigration = false
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				conn.sentPacketHandler = sph
+				sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+				sph.EXPECT().ECNMode(false).Return(protocol.ECNUnsupported).AnyTimes()
+				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				rawConn := NewMockRawConn(mockCtrl)
+				rawConn.EXPECT().LocalAddr().Return(&net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 443}).AnyTimes()
+				// the client migrates from the same address
+				rawConn.EXPECT().WritePacket([]byte("probe"), remoteAddr, gomock.Any(), gomock.Any(), uint16(0), protocol.ECNUnsupported, time.Time{})
+
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0x1} /* PING */, nil)
+				packet := getShortHeaderPacket(srcConnID, 10, nil)
+				packet.data = append(packet.data, make([]byte, 50)...)
+				packet.rcvConn = rawConn
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), 3*packet.Size(), conn.version).DoAndReturn(
+					func(_ protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+						Expect(frames).To(HaveLen(1))
+						Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+						buf := getPacketBuffer()
+						buf.Data = append(buf.Data, []byte("probe")...)
+						return shortHeaderPacket{PacketNumber: 1, Frames: frames}, buf, nil
+					},
+				)
+				tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
 			})
 		})
@@ -1200,7 +1505,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sender.EXPECT().Close()
@@ -1237,7 +1542,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack).AnyTimes()
 			sent := make(chan struct{})
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(sent) })
 			tracer.EXPECT().SentShortHeaderPacket(&logging.ShortHeader{
 				DestConnectionID: p.DestConnID,
 				PacketNumber:     p.PacketNumber,
@@ -1292,7 +1597,7 @@ var _ = Describe("Connection", func() {
 			conn.connFlowController = fc
 			runConn()
 			sent := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(sent) })
 			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), nil, []logging.Frame{})
 			conn.scheduleSending()
 			Eventually(sent).Should(BeClosed())
@@ -1343,7 +1648,7 @@ var _ = Describe("Connection", func() {
 					conn.sentPacketHandler = sph
 					runConn()
 					sent := make(chan struct{})
-					sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
+					sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(sent) })
 					if encLevel == protocol.Encryption1RTT {
 						tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), p.shortHdrPacket.Length, gomock.Any(), gomock.Any(), gomock.Any())
 					} else {
@@ -1365,7 +1670,7 @@ var _ = Describe("Connection", func() {
 					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 					runConn()
 					sent := make(chan struct{})
-					sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
+					sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(sent) })
 					if encLevel == protocol.Encryption1RTT {
 						tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), p.shortHdrPacket.Length, logging.ECT0, gomock.Any(), gomock.Any())
 					} else {
@@ -1406,7 +1711,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			sender.EXPECT().Close()
@@ -1423,10 +1728,10 @@ var _ = Describe("Connection", func() {
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 10}, []byte("packet10"))
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, []byte("packet11"))
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
 				Expect(b.Data).To(Equal([]byte("packet10")))
 			})
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
 				Expect(b.Data).To(Equal([]byte("packet11")))
 			})
 			go func() {
@@ -1452,7 +1757,7 @@ var _ = Describe("Connection", func() {
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, payload2)
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(shortHeaderPacket{}, errNothingToPack)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any(), time.Time{}).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
 				Expect(b.Data).To(Equal(append(payload1, payload2...)))
 			})
 			go func() {
@@ -1481,10 +1786,10 @@ var _ = Describe("Connection", func() {
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, payload2)
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 12}, payload3)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any(), time.Time{}).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
 				Expect(b.Data).To(Equal(append(payload1, payload2...)))
 			})
-			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any(), time.Time{}).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
 				Expect(b.Data).To(Equal(payload3))
 			})
 			go func() {
@@ -1514,10 +1819,10 @@ var _ = Describe("Connection", func() {
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, payload2)
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, payload3)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any(), time.Time{}).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
 				Expect(b.Data).To(Equal(append(payload1, payload2...)))
 			})
-			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+			sender.EXPECT().Send(gomock.Any(), uint16(conn.maxPacketSize()), gomock.Any(), time.Time{}).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
 				Expect(b.Data).To(Equal(payload3))
 			})
 			go func() {
@@ -1530,6 +1835,85 @@ var _ = Describe("Connection", func() {
 			time.Sleep(50 * time.Millisecond) // make sure that only 2 packets are sent
 		})
 
+		It("schedules packets ahead of time, when the kernel paces packets", func() {
+			capabilities = connCapabilities{TXTime: true}
+			sendAt := time.Now().Add(protocol.KernelPacingHorizon / 2)
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(sendAt, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(2)
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited)
+			sph.EXPECT().SendMode(sendAt).Return(ackhandler.SendAny)
+			sph.EXPECT().SendMode(sendAt).Return(ackhandler.SendPacingLimited)
+			sph.EXPECT().TimeUntilSend().Return(sendAt)
+			sph.EXPECT().TimeUntilSend().Return(time.Now().Add(time.Hour)).Times(2)
+			sph.EXPECT().ECNMode(gomock.Any()).Times(2)
+			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 10}, []byte("packet10"))
+			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, []byte("packet11"))
+			sender.EXPECT().WouldBlock().AnyTimes()
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), time.Time{}).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
+				Expect(b.Data).To(Equal([]byte("packet10")))
+			})
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), sendAt).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ time.Time) {
+				Expect(b.Data).To(Equal([]byte("packet11")))
+			})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				conn.run()
+			}()
+			conn.scheduleSending()
+			time.Sleep(50 * time.Millisecond) // make sure that only 2 packets are sent
+		})
+
+		It("hands packets to the kernel at most the pacing horizon ahead of time", func() {
+			capabilities = connCapabilities{TXTime: true}
+			start := time.Now()
+			sendAt := start.Add(3 * protocol.KernelPacingHorizon)
+			// the pacer allows sending the first packet right away, and the second packet at sendAt
+			var numSent int
+			sph.EXPECT().SendMode(gomock.Any()).DoAndReturn(func(t time.Time) ackhandler.SendMode {
+				if numSent == 0 || (numSent == 1 && !t.Before(sendAt)) {
+					return ackhandler.SendAny
+				}
+				return ackhandler.SendPacingLimited
+			}).AnyTimes()
+			sph.EXPECT().TimeUntilSend().DoAndReturn(func() time.Time {
+				if numSent < 2 {
+					return sendAt
+				}
+				return time.Now().Add(time.Hour)
+			}).AnyTimes()
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
+			packer.EXPECT().PackAckOnlyPacket(gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, nil, errNothingToPack).AnyTimes()
+			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 10}, []byte("packet10"))
+			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 11}, []byte("packet11"))
+			sender.EXPECT().WouldBlock().AnyTimes()
+			sent := make(chan time.Time, 2)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), time.Time{}).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) {
+				numSent++
+				sent <- time.Now()
+			})
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), sendAt).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) {
+				numSent++
+				sent <- time.Now()
+			})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				conn.run()
+			}()
+			conn.scheduleSending()
+			Eventually(sent).Should(Receive())
+			// The second packet is not handed to the kernel before the pacing horizon,
+			// such that packets are still paced if the qdisc doesn't support SO_TXTIME.
+			var t time.Time
+			Eventually(sent).Should(Receive(&t))
+			Expect(t).To(BeTemporally(">=", sendAt.Add(-protocol.KernelPacingHorizon)))
+		})
+
 		It("sends multiple packets, when the pacer allows immediate sending", func() {
 			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(2)
@@ -1537,7 +1921,7 @@ var _ = Describe("Connection", func() {
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 10}, []byte("packet10"))
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
@@ -1556,7 +1940,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackAckOnlyPacket(gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{PacketNumber: 123}, getPacketBuffer(), nil)
 
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
@@ -1576,7 +1960,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().ECNMode(gomock.Any()).Times(2)
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 100}, []byte("packet100"))
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
@@ -1605,7 +1989,7 @@ var _ = Describe("Connection", func() {
 			)
 			written := make(chan struct{}, 2)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { written <- struct{}{} }).Times(2)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN, time.Time) { written <- struct{}{} }).Times(2)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
@@ -1629,7 +2013,7 @@ var _ = Describe("Connection", func() {
 			}
 			written := make(chan struct{}, 3)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { written <- struct{}{} }).Times(3)
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN, time.Time) { written <- struct{}{} }).Times(3)
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
@@ -1664,7 +2048,7 @@ var _ = Describe("Connection", func() {
 				sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 				expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 1000}, []byte("packet1000"))
 				packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack)
-				sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
+				sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(written) })
 				available <- struct{}{}
 				Eventually(written).Should(BeClosed())
 			})
@@ -1689,7 +2073,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 10}, []byte("packet10"))
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack)
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(written) })
 
 			conn.scheduleSending()
 			time.Sleep(scaleDuration(50 * time.Millisecond))
@@ -1705,7 +2089,7 @@ var _ = Describe("Connection", func() {
 			written := make(chan struct{}, 1)
 			sender.EXPECT().WouldBlock()
 			sender.EXPECT().WouldBlock().Return(true).Times(2)
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN, time.Time) { written <- struct{}{} })
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().StartHandshake(gomock.Any()).MaxTimes(1)
@@ -1725,7 +2109,7 @@ var _ = Describe("Connection", func() {
 			sender.EXPECT().WouldBlock().AnyTimes()
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 1001}, []byte("packet1001"))
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack)
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN, time.Time) { written <- struct{}{} })
 			available <- struct{}{}
 			Eventually(written).Should(Receive())
 
@@ -1760,7 +2144,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
 			written := make(chan struct{}, 1)
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN) { written <- struct{}{} })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(*packetBuffer, uint16, protocol.ECN, time.Time) { written <- struct{}{} })
 			mtuDiscoverer.EXPECT().ShouldSendProbe(gomock.Any()).Return(true)
 			ping := ackhandler.Frame{Frame: &wire.PingFrame{}}
 			mtuDiscoverer.EXPECT().GetPing().Return(ping, protocol.ByteCount(1234))
@@ -1794,7 +2178,7 @@ var _ = Describe("Connection", func() {
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sender.EXPECT().Close()
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
@@ -1824,7 +2208,7 @@ var _ = Describe("Connection", func() {
 			time.Sleep(50 * time.Millisecond)
 			// only EXPECT calls after scheduleSending is called
 			written := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(written) })
 			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			conn.scheduleSending()
 			Eventually(written).Should(BeClosed())
@@ -1846,7 +2230,7 @@ var _ = Describe("Connection", func() {
 			conn.receivedPacketHandler = rph
 
 			written := make(chan struct{})
-			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(written) })
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN, time.Time) { close(written) })
 			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			go func() {
 				defer GinkgoRecover()
@@ -1904,7 +2288,7 @@ var _ = Describe("Connection", func() {
 		)
 
 		sent := make(chan struct{})
-		mconn.EXPECT().Write([]byte("foobar"), uint16(0), protocol.ECT1).Do(func([]byte, uint16, protocol.ECN) error { close(sent); return nil })
+		mconn.EXPECT().Write([]byte("foobar"), uint16(0), protocol.ECT1, time.Time{}).Do(func([]byte, uint16, protocol.ECN, time.Time) error { close(sent); return nil })
 
 		go func() {
 			defer GinkgoRecover()
@@ -1921,7 +2305,7 @@ var _ = Describe("Connection", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		conn.CloseWithError(0, "")
@@ -1996,7 +2380,7 @@ var _ = Describe("Connection", func() {
 		}()
 		handshakeCtx := conn.HandshakeComplete()
 		Consistently(handshakeCtx).ShouldNot(BeClosed())
-		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		conn.closeLocal(errors.New("handshake error"))
 		Consistently(handshakeCtx).ShouldNot(BeClosed())
 		Eventually(conn.Context().Done()).Should(BeClosed())
@@ -2010,7 +2394,7 @@ var _ = Describe("Connection", func() {
 		sph.EXPECT().TimeUntilSend().AnyTimes()
 		sph.EXPECT().SetHandshakeConfirmed()
 		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
-		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ChoseALPN(gomock.Any())
 		conn.sentPacketHandler = sph
@@ -2034,7 +2418,7 @@ var _ = Describe("Connection", func() {
 			cryptoSetup.EXPECT().SetHandshakeConfirmed()
 			cryptoSetup.EXPECT().GetSessionTicket()
 			cryptoSetup.EXPECT().ConnectionState()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			Expect(conn.handleHandshakeComplete()).To(Succeed())
 			conn.run()
 		}()
@@ -2088,7 +2472,7 @@ var _ = Describe("Connection", func() {
 		expectReplaceWithClosed()
 		packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
-		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		Expect(conn.CloseWithError(0x1337, testErr.Error())).To(Succeed())
@@ -2115,6 +2499,32 @@ var _ = Describe("Connection", func() {
 			conn.handleTransportParameters(params)
 			Expect(conn.earlyConnReady()).To(BeClosed())
 		})
+
+		It("enables greasing of the QUIC bit, if the client supports it", func() {
+			conn.config.EnableQUICBitGreasing = true
+			params := &wire.TransportParameters{
+				ActiveConnectionIDLimit:   3,
+				InitialSourceConnectionID: destConnID,
+				GreaseQUICBit:             true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().EnableQUICBitGreasing()
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+		})
+
+		It("doesn't grease the QUIC bit, if it's not enabled in the config", func() {
+			params := &wire.TransportParameters{
+				ActiveConnectionIDLimit:   3,
+				InitialSourceConnectionID: destConnID,
+				GreaseQUICBit:             true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+		})
 	})
 
 	Context("keep-alives", func() {
@@ -2148,7 +2558,7 @@ var _ = Describe("Connection", func() {
 			streamManager.EXPECT().CloseWithError(gomock.Any())
 			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.CloseWithError(0, "")
@@ -2301,7 +2711,7 @@ var _ = Describe("Connection", func() {
 			// make the go routine return
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			conn.CloseWithError(0, "")
 			Eventually(conn.Context().Done()).Should(BeClosed())
 		})
@@ -2386,7 +2796,7 @@ var _ = Describe("Connection", func() {
 			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 			expectReplaceWithClosed()
 			cryptoSetup.EXPECT().Close()
-			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
 			conn.CloseWithError(0, "")
@@ -2528,6 +2938,40 @@ var _ = Describe("Connection", func() {
 	It("returns the remote address", func() {
 		Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
 	})
+
+	Context("session ticket data", func() {
+		It("doesn't set a handler if no callbacks are configured", func() {
+			Expect(conn.newSessionTicketDataHandler()).To(BeNil())
+		})
+
+		It("passes the connection to GetSessionTicketData", func() {
+			var c Connection
+			conn.config.GetSessionTicketData = func(connection Connection) []byte {
+				c = connection
+				return []byte("foobar")
+			}
+			h := conn.newSessionTicketDataHandler()
+			Expect(h.Validate).To(BeNil())
+			Expect(h.Get()).To(Equal([]byte("foobar")))
+			Expect(c).To(Equal(conn))
+		})
+
+		It("uses ValidateSessionTicketData", func() {
+			conn.config.ValidateSessionTicketData = func(data []byte) bool { return string(data) == "foobar" }
+			h := conn.newSessionTicketDataHandler()
+			Expect(h.Get).To(BeNil())
+			Expect(h.Validate([]byte("foobar"))).To(BeTrue())
+			Expect(h.Validate([]byte("foo"))).To(BeFalse())
+		})
+
+		It("makes the session ticket data available in the connection state", func() {
+			cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventSessionTicketData, Data: []byte("foobar")})
+			cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+			Expect(conn.handleHandshakeEvents()).To(Succeed())
+			cryptoSetup.EXPECT().ConnectionState()
+			Expect(conn.ConnectionState().SessionTicketData).To(Equal([]byte("foobar")))
+		})
+	})
 })
 
 var _ = Describe("Client Connection", func() {
@@ -2639,7 +3083,7 @@ var _ = Describe("Client Connection", func() {
 		packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
 		cryptoSetup.EXPECT().Close()
 		connRunner.EXPECT().ReplaceWithClosed([]protocol.ConnectionID{srcConnID}, gomock.Any())
-		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(1)
+		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(1)
 		tracer.EXPECT().ClosedConnection(gomock.Any())
 		tracer.EXPECT().Close()
 		conn.CloseWithError(0, "")
@@ -2720,6 +3164,100 @@ var _ = Describe("Client Connection", func() {
 		Eventually(areConnsRunning).Should(BeFalse())
 	})
 
+	Context("connection migration", func() {
+		It("doesn't add paths before the handshake completed", func() {
+			_, err := conn.AddPath(&Transport{})
+			Expect(err).To(MatchError("can only add paths after the handshake completed"))
+		})
+
+		It("doesn't add paths if the server disabled active migration", func() {
+			close(conn.handshakeCompleteChan)
+			conn.peerParams = &wire.TransportParameters{DisableActiveMigration: true}
+			_, err := conn.AddPath(&Transport{})
+			Expect(err).To(MatchError("server disabled connection migration"))
+		})
+
+		It("returns the original path", func() {
+			_, err := conn.OriginalPath()
+			Expect(err).To(MatchError("the original path is only available after the handshake completed"))
+			close(conn.handshakeCompleteChan)
+			p, err := conn.OriginalPath()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.id).To(BeZero())
+			Expect(p.validated.Load()).To(BeTrue())
+			p2, err := conn.OriginalPath()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p2).To(BeIdenticalTo(p))
+		})
+
+		It("sends path probe packets", func() {
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			pm := newPathManagerOutgoing(
+				context.Background(),
+				conn.conn,
+				func(pathID) (protocol.ConnectionID, bool) {
+					return protocol.ParseConnectionID([]byte{1, 3, 3, 7}), true
+				},
+				func(pathID) {},
+				func() {},
+			)
+			conn.pathManagerOutgoing.Store(pm)
+			pathConn := NewMockSendConn(mockCtrl)
+			p, err := pm.NewPath(&Transport{}, pathConn, time.Hour, func(pathID) {})
+			Expect(err).ToNot(HaveOccurred())
+			pm.enqueueProbe(p.id)
+
+			buf := getPacketBuffer()
+			buf.Data = append(buf.Data, []byte("probe")...)
+			packer.EXPECT().PackPathProbePacket(protocol.ParseConnectionID([]byte{1, 3, 3, 7}), gomock.Any(), protocol.ByteCount(protocol.MinInitialPacketSize), protocol.Version1).Return(
+				shortHeaderPacket{PacketNumber: 10, DestConnID: protocol.ParseConnectionID([]byte{1, 3, 3, 7})},
+				buf,
+				nil,
+			)
+			sph.EXPECT().ECNMode(false).Return(protocol.ECNUnsupported)
+			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(10), protocol.InvalidPacketNumber, nil, nil, protocol.Encryption1RTT, protocol.ECNUnsupported, protocol.ByteCount(5), false)
+			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			pathConn.EXPECT().Write([]byte("probe"), uint16(0), protocol.ECNUnsupported, time.Time{})
+			conn.handleOutgoingPaths(pm, time.Now())
+		})
+
+		It("rejects PATH_RESPONSE frames if no path was probed", func() {
+			Expect(conn.handlePathResponseFrame(&wire.PathResponseFrame{})).To(MatchError("unexpected PATH_RESPONSE frame"))
+		})
+
+		It("probes the server's preferred address", func() {
+			preferredConnID := protocol.ParseConnectionID([]byte{1, 3, 3, 7})
+			Expect(conn.connIDManager.AddFromPreferredAddress(preferredConnID, protocol.StatelessResetToken{42})).To(Succeed())
+			addr := netip.MustParseAddrPort("[2001:db8::1]:443")
+			pathConn := NewMockSendConn(mockCtrl)
+			// the connection uses an IPv6 address, so the IPv4 address is ignored
+			mconn.EXPECT().withRemoteAddr(net.UDPAddrFromAddrPort(addr), packetInfo{}).Return(pathConn)
+			conn.migrateToPreferredAddress(&wire.PreferredAddress{
+				IPv4: netip.MustParseAddrPort("192.0.2.1:443"),
+				IPv6: addr,
+			})
+			pm := conn.pathManagerOutgoing.Load()
+			Expect(pm).ToNot(BeNil())
+
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{42}, gomock.Any())
+			var connID protocol.ConnectionID
+			var c sendConn
+			Eventually(func() bool {
+				var ok bool
+				connID, _, c, ok = pm.NextPathToProbe()
+				return ok
+			}).Should(BeTrue())
+			Expect(connID).To(Equal(preferredConnID))
+			Expect(c).To(Equal(pathConn))
+		})
+
+		It("doesn't probe the preferred address if it uses a different address family", func() {
+			conn.migrateToPreferredAddress(&wire.PreferredAddress{IPv4: netip.MustParseAddrPort("192.0.2.1:443")})
+			Expect(conn.pathManagerOutgoing.Load()).To(BeNil())
+		})
+	})
+
 	Context("handling tokens", func() {
 		var tokenStore TokenStore
 
@@ -2937,7 +3475,7 @@ var _ = Describe("Client Connection", func() {
 					packer.EXPECT().PackConnectionClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil).MaxTimes(1)
 				}
 				cryptoSetup.EXPECT().Close()
-				mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+				mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 				gomock.InOrder(
 					tracer.EXPECT().ClosedConnection(gomock.Any()),
 					tracer.EXPECT().Close(),
@@ -3091,6 +3629,69 @@ var _ = Describe("Client Connection", func() {
 			})))
 		})
 
+		It("errors if the server's chosen version doesn't match the negotiated version", func() {
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version2,
+					AvailableVersions: []protocol.Version{protocol.Version2},
+				},
+			}
+			Expect(conn.version).To(Equal(protocol.Version1))
+			expectClose(false, true)
+			processed := make(chan struct{})
+			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
+			paramsChan <- params
+			Eventually(processed).Should(BeClosed())
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorCode,
+				ErrorMessage: "server's chosen version (v2) doesn't match the negotiated version (v1)",
+			})))
+		})
+
+		It("detects version downgrades after a Version Negotiation", func() {
+			conn.versionNegotiated = true
+			conn.config.Versions = []protocol.Version{protocol.Version2, protocol.Version1}
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version1,
+					AvailableVersions: []protocol.Version{protocol.Version1, protocol.Version2},
+				},
+			}
+			expectClose(false, true)
+			processed := make(chan struct{})
+			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
+			paramsChan <- params
+			Eventually(processed).Should(BeClosed())
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorCode,
+				ErrorMessage: "version downgrade detected (negotiated v1, server supports [v1 v2])",
+			})))
+		})
+
+		It("requires the version_information after a Version Negotiation", func() {
+			conn.versionNegotiated = true
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			}
+			expectClose(false, true)
+			processed := make(chan struct{})
+			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
+			paramsChan <- params
+			Eventually(processed).Should(BeClosed())
+			Eventually(errChan).Should(Receive(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorCode,
+				ErrorMessage: "missing version_information after Version Negotiation",
+			})))
+		})
+
 		It("errors if the transport parameters contain reduced limits after knowing 0-RTT data is accepted by the server", func() {
 			conn.perspective = protocol.PerspectiveClient
 			conn.peerParams = &wire.TransportParameters{
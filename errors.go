@@ -41,6 +41,9 @@ const (
 	NoViablePathError         = qerr.NoViablePathError
 )
 
+// VersionNegotiationErrorCode is used when compatible version negotiation (RFC 9368) fails.
+const VersionNegotiationErrorCode = qerr.VersionNegotiationErrorCode
+
 // A StreamError is used for Stream.CancelRead and Stream.CancelWrite.
 // It is also returned from Stream.Read and Stream.Write if the peer canceled reading or writing.
 type StreamError struct {
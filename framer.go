@@ -7,7 +7,6 @@ import (
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/protocol"
-	"github.com/quic-go/quic-go/internal/utils/ringbuffer"
 	"github.com/quic-go/quic-go/internal/wire"
 	"github.com/quic-go/quic-go/quicvarint"
 )
@@ -29,7 +28,8 @@ type framer struct {
 	mutex sync.Mutex
 
 	activeStreams            map[protocol.StreamID]sendStreamI
-	streamQueue              ringbuffer.RingBuffer[protocol.StreamID]
+	scheduler                StreamScheduler
+	streamPriorities         map[protocol.StreamID]StreamPriority // only contains streams that SetPriority was called on
 	streamsWithControlFrames map[protocol.StreamID]streamControlFrameGetter
 
 	controlFrameMutex          sync.Mutex
@@ -38,16 +38,18 @@ type framer struct {
 	queuedTooManyControlFrames bool
 }
 
-func newFramer() *framer {
+func newFramer(scheduler StreamScheduler) *framer {
 	return &framer{
 		activeStreams:            make(map[protocol.StreamID]sendStreamI),
+		scheduler:                scheduler,
+		streamPriorities:         make(map[protocol.StreamID]StreamPriority),
 		streamsWithControlFrames: make(map[protocol.StreamID]streamControlFrameGetter),
 	}
 }
 
 func (f *framer) HasData() bool {
 	f.mutex.Lock()
-	hasData := !f.streamQueue.Empty()
+	hasData := len(f.activeStreams) > 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -149,12 +151,35 @@ func (f *framer) QueuedTooManyControlFrames() bool {
 func (f *framer) AddActiveStream(id protocol.StreamID, str sendStreamI) {
 	f.mutex.Lock()
 	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue.PushBack(id)
 		f.activeStreams[id] = str
+		f.scheduler.AddStream(id, f.streamPriority(id))
 	}
 	f.mutex.Unlock()
 }
 
+// SetStreamPriority is called when the application changes the priority of a stream.
+func (f *framer) SetStreamPriority(id protocol.StreamID, prio StreamPriority, str sendStreamI) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	// The stream might have completed concurrently.
+	// This check needs to happen while holding the mutex:
+	// RemoveActiveStream is called after the stream completed, and deletes the priority.
+	if str.isCompleted() {
+		return
+	}
+	f.streamPriorities[id] = prio
+	if _, ok := f.activeStreams[id]; ok {
+		f.scheduler.SetPriority(id, prio)
+	}
+}
+
+func (f *framer) streamPriority(id protocol.StreamID) StreamPriority {
+	if prio, ok := f.streamPriorities[id]; ok {
+		return prio
+	}
+	return defaultStreamPriority
+}
+
 func (f *framer) AddStreamWithControlFrames(id protocol.StreamID, str streamControlFrameGetter) {
 	f.controlFrameMutex.Lock()
 	if _, ok := f.streamsWithControlFrames[id]; !ok {
@@ -166,10 +191,11 @@ func (f *framer) AddStreamWithControlFrames(id protocol.StreamID, str streamCont
 // RemoveActiveStream is called when a stream completes.
 func (f *framer) RemoveActiveStream(id protocol.StreamID) {
 	f.mutex.Lock()
-	delete(f.activeStreams, id)
-	// We don't delete the stream from the streamQueue,
-	// since we'd have to iterate over the ringbuffer.
-	// Instead, we check if the stream is still in activeStreams in AppendStreamFrames.
+	if _, ok := f.activeStreams[id]; ok {
+		delete(f.activeStreams, id)
+		f.scheduler.RemoveStream(id)
+	}
+	delete(f.streamPriorities, id)
 	f.mutex.Unlock()
 }
 
@@ -177,30 +203,27 @@ func (f *framer) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen prot
 	startLen := len(frames)
 	var length protocol.ByteCount
 	f.mutex.Lock()
-	// pop STREAM frames, until less than 128 bytes are left in the packet
-	numActiveStreams := f.streamQueue.Len()
+	// pop STREAM frames, until less than 128 bytes are left in the packet,
+	// asking the scheduler at most once per active stream
+	numActiveStreams := len(f.activeStreams)
 	for i := 0; i < numActiveStreams; i++ {
 		if protocol.MinStreamFrameSize+length > maxLen {
 			break
 		}
-		id := f.streamQueue.PopFront()
-		// This should never return an error. Better check it anyway.
-		// The stream will only be in the streamQueue, if it enqueued itself there.
-		str, ok := f.activeStreams[id]
-		// The stream might have been removed after being enqueued.
+		id, ok := f.scheduler.NextStream()
 		if !ok {
-			continue
+			break
 		}
+		str := f.activeStreams[id]
 		remainingLen := maxLen - length
 		// For the last STREAM frame, we'll remove the DataLen field later.
 		// Therefore, we can pretend to have more bytes available when popping
 		// the STREAM frame (which will always have the DataLen set).
 		remainingLen += protocol.ByteCount(quicvarint.Len(uint64(remainingLen)))
 		frame, ok, hasMoreData := str.popStreamFrame(remainingLen, v)
-		if hasMoreData { // put the stream back in the queue (at the end)
-			f.streamQueue.PushBack(id)
-		} else { // no more data to send. Stream is not active
+		if !hasMoreData { // no more data to send. Stream is not active
 			delete(f.activeStreams, id)
+			f.scheduler.RemoveStream(id)
 		}
 		// The frame can be "nil"
 		// * if the stream was canceled after it said it had data
@@ -210,6 +233,7 @@ func (f *framer) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen prot
 		}
 		frames = append(frames, frame)
 		length += frame.Frame.Length(v)
+		f.scheduler.StreamDataSent(id, len(frame.Frame.Data))
 	}
 	f.mutex.Unlock()
 	if len(frames) > startLen {
@@ -227,10 +251,11 @@ func (f *framer) Handle0RTTRejection() {
 	f.controlFrameMutex.Lock()
 	defer f.controlFrameMutex.Unlock()
 
-	f.streamQueue.Clear()
 	for id := range f.activeStreams {
 		delete(f.activeStreams, id)
+		f.scheduler.RemoveStream(id)
 	}
+	clear(f.streamPriorities)
 	var j int
 	for i, frame := range f.controlFrames {
 		switch frame.(type) {
@@ -32,7 +32,7 @@ var _ = Describe("Framer", func() {
 		stream1.EXPECT().StreamID().Return(protocol.StreamID(5)).AnyTimes()
 		stream2 = NewMockSendStreamI(mockCtrl)
 		stream2.EXPECT().StreamID().Return(protocol.StreamID(6)).AnyTimes()
-		framer = newFramer()
+		framer = newFramer(NewRoundRobinStreamScheduler())
 	})
 
 	Context("handling control frames", func() {
@@ -464,4 +464,60 @@ var _ = Describe("Framer", func() {
 			Expect(length).To(BeZero())
 		})
 	})
+
+	Context("prioritizing streams", func() {
+		BeforeEach(func() {
+			framer = newFramer(NewStrictPriorityStreamScheduler())
+		})
+
+		It("uses the stream priority", func() {
+			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream2.EXPECT().isCompleted()
+			framer.SetStreamPriority(id2, StreamPriority{Urgency: 1}, stream2)
+			framer.AddActiveStream(id1, stream1)
+			framer.AddActiveStream(id2, stream2)
+			stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f2}, true, false)
+			stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f1}, true, false)
+			frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame).To(Equal(f2))
+			Expect(frames[1].Frame).To(Equal(f1))
+		})
+
+		It("updates the priority of active streams", func() {
+			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			framer.AddActiveStream(id1, stream1)
+			framer.AddActiveStream(id2, stream2)
+			stream2.EXPECT().isCompleted()
+			framer.SetStreamPriority(id2, StreamPriority{Urgency: 1}, stream2)
+			stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f2}, true, true)
+			frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f2))
+			stream1.EXPECT().isCompleted()
+			framer.SetStreamPriority(id1, StreamPriority{Urgency: 0}, stream1)
+			stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f1}, true, false)
+			frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f1))
+		})
+
+		It("forgets the priority when the stream completes", func() {
+			stream1.EXPECT().isCompleted()
+			framer.SetStreamPriority(id1, StreamPriority{Urgency: 0}, stream1)
+			Expect(framer.streamPriority(id1)).To(Equal(StreamPriority{Urgency: 0}))
+			framer.RemoveActiveStream(id1)
+			Expect(framer.streamPriority(id1)).To(Equal(defaultStreamPriority))
+		})
+
+		It("doesn't set the priority of completed streams", func() {
+			// the stream completed concurrently, and RemoveActiveStream was already called
+			framer.RemoveActiveStream(id1)
+			stream1.EXPECT().isCompleted().Return(true)
+			framer.SetStreamPriority(id1, StreamPriority{Urgency: 0}, stream1)
+			Expect(framer.streamPriorities).To(BeEmpty())
+		})
+	})
 })
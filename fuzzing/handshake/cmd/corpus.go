@@ -29,6 +29,7 @@ func main() {
 			ClientSessionCache: tls.NewLRUClientSessionCache(1),
 		},
 		false,
+		nil,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -44,6 +45,8 @@ func main() {
 		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
 		config,
 		false,
+		nil,
+		nil,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
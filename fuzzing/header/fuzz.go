@@ -32,12 +32,12 @@ func Fuzz(data []byte) int {
 	}
 
 	if !wire.IsLongHeaderPacket(data[0]) {
-		wire.ParseShortHeader(data, connIDLen)
+		wire.ParseShortHeader(data, connIDLen, false)
 		return 1
 	}
 
 	is0RTTPacket := wire.Is0RTTPacket(data)
-	hdr, _, _, err := wire.ParsePacket(data)
+	hdr, _, _, err := wire.ParsePacket(data, false)
 	if err != nil {
 		return 0
 	}
@@ -17,7 +17,7 @@ func Fuzz(data []byte) int {
 	var key quic.TokenGeneratorKey
 	copy(key[:], data[:32])
 	data = data[32:]
-	tg := handshake.NewTokenGenerator(key)
+	tg := handshake.NewTokenGenerator(handshake.TokenKey{Key: key})
 	if len(data) < 1 {
 		return -1
 	}
@@ -57,7 +57,7 @@ func newToken(tg *handshake.TokenGenerator, data []byte) int {
 		}
 	}
 	start := time.Now()
-	encrypted, err := tg.NewToken(addr)
+	encrypted, err := tg.NewToken(addr, nil)
 	if err != nil {
 		panic(err)
 	}
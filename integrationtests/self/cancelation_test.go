@@ -329,6 +329,54 @@ var _ = Describe("Stream Cancellations", func() {
 		})
 	})
 
+	It("delivers data up to the reliable size when the server resets streams using RESET_STREAM_AT", func() {
+		const reliableSize = 3000
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableStreamResetPartialDelivery: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conn.ConnectionState().SupportsStreamResetPartialDelivery).To(BeTrue())
+			for i := 0; i < numStreams; i++ {
+				str, err := conn.OpenUniStreamSync(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write(PRData[:2*reliableSize])
+				Expect(err).ToNot(HaveOccurred())
+				str.CancelWriteAt(quic.StreamErrorCode(str.StreamID()), reliableSize)
+			}
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableStreamResetPartialDelivery: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().SupportsStreamResetPartialDelivery).To(BeTrue())
+		for i := 0; i < numStreams; i++ {
+			str, err := conn.AcceptUniStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).To(MatchError(&quic.StreamError{
+				StreamID:  str.StreamID(),
+				ErrorCode: quic.StreamErrorCode(str.StreamID()),
+				Remote:    true,
+			}))
+			// data that was received before the RESET_STREAM_AT frame might also have been read
+			Expect(len(data)).To(BeNumerically(">=", reliableSize))
+			Expect(data).To(Equal(PRData[:len(data)]))
+		}
+	})
+
 	Context("canceling both read and write side", func() {
 		It("downloads data when both sides cancel streams immediately", func() {
 			server, err := quic.ListenAddr("localhost:0", getTLSConfig(), nil)
@@ -0,0 +1,142 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/congestion"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type countingCongestionController struct {
+	congestion.SendAlgorithmWithDebugInfos
+
+	sent, acked *atomic.Int64
+}
+
+func (c *countingCongestionController) OnPacketSent(t time.Time, bytesInFlight congestion.ByteCount, pn congestion.PacketNumber, bytes congestion.ByteCount, isRetransmittable bool) {
+	c.sent.Add(1)
+	c.SendAlgorithmWithDebugInfos.OnPacketSent(t, bytesInFlight, pn, bytes, isRetransmittable)
+}
+
+func (c *countingCongestionController) OnPacketAcked(pn congestion.PacketNumber, ackedBytes, priorInFlight congestion.ByteCount, eventTime time.Time) {
+	c.acked.Add(1)
+	c.SendAlgorithmWithDebugInfos.OnPacketAcked(pn, ackedBytes, priorInFlight, eventTime)
+}
+
+var _ = Describe("Congestion Control", func() {
+	It("uses the congestion controller provided by the application", func() {
+		var numCreated, sent, acked atomic.Int64
+		serverConf := getQuicConfig(&quic.Config{
+			CongestionControl: func(rttStats *congestion.RTTStats, initialMaxDatagramSize congestion.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+				numCreated.Add(1)
+				return &countingCongestionController{
+					SendAlgorithmWithDebugInfos: congestion.NewCubic(rttStats, initialMaxDatagramSize),
+					sent:                        &sent,
+					acked:                       &acked,
+				}
+			},
+		})
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConf)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		data := GeneratePRData(500 << 10)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		received, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received).To(Equal(data))
+
+		Expect(numCreated.Load()).To(BeEquivalentTo(1))
+		Expect(sent.Load()).To(BeNumerically(">", 500000/1500))
+		Eventually(acked.Load).Should(BeNumerically(">", 500000/1500))
+	})
+
+	It("reports the state of the BBR congestion controller", func() {
+		var mx sync.Mutex
+		var states []logging.CongestionState
+		serverConf := getQuicConfig(&quic.Config{
+			CongestionControl: congestion.NewBBR,
+			Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+				return &logging.ConnectionTracer{
+					UpdatedCongestionState: func(s logging.CongestionState) {
+						mx.Lock()
+						defer mx.Unlock()
+						states = append(states, s)
+					},
+				}
+			},
+		})
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConf)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		data := GeneratePRData(2 << 20)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		received, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received).To(Equal(data))
+
+		mx.Lock()
+		defer mx.Unlock()
+		Expect(states).ToNot(BeEmpty())
+		Expect(states[0]).To(Equal(logging.CongestionStateStartup))
+		for _, s := range states {
+			Expect(s).To(BeElementOf(
+				logging.CongestionStateStartup,
+				logging.CongestionStateDrain,
+				logging.CongestionStateProbeBW,
+				logging.CongestionStateProbeRTT,
+			))
+		}
+	})
+})
@@ -0,0 +1,71 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Statistics", func() {
+	It("reports statistics", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		data := GeneratePRData(200 << 10)
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		// wait for the final ACK to arrive
+		Eventually(func() uint64 { return serverConn.ConnectionStats().BytesInFlight }).Should(BeZero())
+		serverStats := serverConn.ConnectionStats()
+		Expect(serverStats.BytesSent).To(BeNumerically(">", len(data)))
+		Expect(serverStats.PacketsSent).To(BeNumerically(">", len(data)/1500))
+		Expect(serverStats.SmoothedRTT).ToNot(BeZero())
+		Expect(serverStats.MinRTT).To(BeNumerically("<=", serverStats.SmoothedRTT))
+		Expect(serverStats.CongestionWindow).ToNot(BeZero())
+		Expect(serverStats.MTU).To(BeNumerically(">=", 1200))
+		Expect(serverStats.PacketsRetransmitted).To(BeNumerically(">=", serverStats.PacketsLost))
+
+		clientStats := conn.ConnectionStats()
+		Expect(clientStats.BytesReceived).To(BeNumerically(">", len(data)))
+		Expect(clientStats.BytesReceived).To(BeNumerically("<=", serverStats.BytesSent))
+		Expect(clientStats.SendWindow).ToNot(BeZero())
+		Expect(clientStats.ReceiveWindow).ToNot(BeZero())
+
+		// statistics are still available after the connection was closed
+		conn.CloseWithError(0, "")
+		Expect(conn.ConnectionStats().BytesReceived).To(BeNumerically(">=", clientStats.BytesReceived))
+	})
+})
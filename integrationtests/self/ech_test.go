@@ -0,0 +1,144 @@
+//go:build go1.24
+
+package self_test
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/internal/qerr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// generateECHKey generates an X25519 ECH key, and returns it along with the ECHConfigList
+// that clients use to encrypt the ClientHello, see section 4 of draft-ietf-tls-esni.
+func generateECHKey(configID uint8, publicName string) (tls.EncryptedClientHelloKey, []byte) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint16(0xfe0d) // version
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint8(configID)
+		b.AddUint16(0x0020) // KEM: DHKEM(X25519, HKDF-SHA256)
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(key.PublicKey().Bytes()) })
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint16(0x0001) // KDF: HKDF-SHA256
+			b.AddUint16(0x0001) // AEAD: AES-128-GCM
+		})
+		b.AddUint8(0) // maximum name length
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes([]byte(publicName)) })
+		b.AddUint16(0) // extensions
+	})
+	config := b.BytesOrPanic()
+
+	list := cryptobyte.NewBuilder(nil)
+	list.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(config) })
+	return tls.EncryptedClientHelloKey{Config: config, PrivateKey: key.Bytes(), SendAsRetry: true}, list.BytesOrPanic()
+}
+
+var _ = Describe("Encrypted Client Hello", func() {
+	// The public name is sent in the outer ClientHello.
+	// It needs to be a valid DNS name with at least two labels.
+	const publicName = "public.example"
+
+	// runServer runs a server that accepts ECH using the given keys.
+	// It returns the server names that the TLS stack sees, i.e. the names sent in the inner ClientHello
+	// if ECH was accepted, and the public names otherwise.
+	runServer := func(keys ...tls.EncryptedClientHelloKey) (*quic.Listener, <-chan string) {
+		serverNames := make(chan string, 10)
+		tlsConf := getTLSConfig()
+		tlsConf.EncryptedClientHelloKeys = keys
+		tlsConf.GetConfigForClient = func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- info.ServerName
+			return nil, nil
+		}
+		ln, err := quic.ListenAddr("localhost:0", tlsConf, getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			for {
+				conn, err := ln.Accept(context.Background())
+				if err != nil {
+					return
+				}
+				Expect(conn.ConnectionState().TLS.ECHAccepted).To(BeTrue())
+				conn.CloseWithError(0, "")
+			}
+		}()
+		return ln, serverNames
+	}
+
+	dial := func(port int, echConfigList []byte) (quic.Connection, error) {
+		tlsConf := getTLSClientConfig()
+		tlsConf.EncryptedClientHelloConfigList = echConfigList
+		// When ECH is rejected, the server authenticates using a certificate for the public name,
+		// which allows the client to trust the retry configs.
+		// The test certificate is only valid for localhost.
+		tlsConf.EncryptedClientHelloRejectionVerify = func(tls.ConnectionState) error { return nil }
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(time.Second))
+		defer cancel()
+		return quic.DialAddr(ctx, fmt.Sprintf("localhost:%d", port), tlsConf, getQuicConfig(nil))
+	}
+
+	It("encrypts the ClientHello", func() {
+		key, configList := generateECHKey(1, publicName)
+		ln, serverNames := runServer(key)
+		defer ln.Close()
+
+		conn, err := dial(ln.Addr().(*net.UDPAddr).Port, configList)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().TLS.ECHAccepted).To(BeTrue())
+		Expect(serverNames).To(Receive(Equal("localhost")))
+	})
+
+	It("returns the retry configs when ECH is rejected", func() {
+		key, configList := generateECHKey(1, publicName)
+		ln, serverNames := runServer(key)
+		defer ln.Close()
+
+		_, outdatedConfigList := generateECHKey(2, publicName)
+		_, err := dial(ln.Addr().(*net.UDPAddr).Port, outdatedConfigList)
+		Expect(err).To(HaveOccurred())
+		var transportErr *qerr.TransportError
+		Expect(errors.As(err, &transportErr)).To(BeTrue())
+		Expect(transportErr.ErrorCode).To(BeEquivalentTo(0x100 + 121)) // ech_required alert
+		var echErr *tls.ECHRejectionError
+		Expect(errors.As(err, &echErr)).To(BeTrue())
+		Expect(echErr.RetryConfigList).To(Equal(configList))
+		// the server couldn't decrypt the inner ClientHello
+		Expect(serverNames).To(Receive(Equal(publicName)))
+
+		// retry using the configs sent by the server
+		conn, err := dial(ln.Addr().(*net.UDPAddr).Port, echErr.RetryConfigList)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.ConnectionState().TLS.ECHAccepted).To(BeTrue())
+		Expect(serverNames).To(Receive(Equal("localhost")))
+	})
+
+	It("fails the handshake if the server doesn't support ECH", func() {
+		ln, serverNames := runServer()
+		defer ln.Close()
+
+		_, configList := generateECHKey(1, publicName)
+		_, err := dial(ln.Addr().(*net.UDPAddr).Port, configList)
+		Expect(err).To(HaveOccurred())
+		var echErr *tls.ECHRejectionError
+		Expect(errors.As(err, &echErr)).To(BeTrue())
+		Expect(echErr.RetryConfigList).To(BeEmpty())
+		Expect(serverNames).To(Receive(Equal(publicName)))
+	})
+})
@@ -0,0 +1,148 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Migration", func() {
+	It("migrates the connection to a new path", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableActiveMigration: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			str.Close()
+		}()
+
+		udpConn1, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr1 := &quic.Transport{Conn: udpConn1}
+		defer tr1.Close()
+		udpConn2, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr2 := &quic.Transport{Conn: udpConn2}
+		defer tr2.Close()
+
+		conn, err := tr1.Dial(
+			context.Background(),
+			&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: ln.Addr().(*net.UDPAddr).Port},
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		// paths can only be added once the handshake is confirmed
+		var path *quic.Path
+		Eventually(func() error {
+			var err error
+			path, err = conn.AddPath(tr2)
+			return err
+		}).Should(Succeed())
+
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write([]byte("foo"))
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(time.Second))
+		defer cancel()
+		Expect(path.Probe(ctx)).To(Succeed())
+		Expect(path.Switch()).To(Succeed())
+		Eventually(conn.LocalAddr).Should(Equal(udpConn2.LocalAddr()))
+
+		_, err = str.Write([]byte("bar"))
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(serverConn.RemoteAddr).Should(Equal(udpConn2.LocalAddr()))
+
+		// switch back to the path that the handshake was performed on
+		originalPath, err := conn.OriginalPath()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(originalPath.Switch()).To(Succeed())
+		Eventually(conn.LocalAddr).Should(Equal(udpConn1.LocalAddr()))
+
+		_, err = str.Write([]byte("baz"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal([]byte("foobarbaz")))
+		Eventually(serverConn.RemoteAddr).Should(Equal(udpConn1.LocalAddr()))
+	})
+})
+
+var _ = Describe("Preferred Address", func() {
+	It("migrates the connection to the server's preferred address", func() {
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		preferredConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		preferredTr := &quic.Transport{Conn: preferredConn}
+		defer preferredTr.Close()
+		tr := &quic.Transport{
+			Conn: udpConn,
+			PreferredAddress: &quic.PreferredAddress{
+				IPv4:          preferredConn.LocalAddr().(*net.UDPAddr).AddrPort(),
+				IPv4Transport: preferredTr,
+			},
+		}
+		defer tr.Close()
+		ln, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			str.Close()
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		Eventually(conn.RemoteAddr).Should(Equal(preferredConn.LocalAddr()))
+		Eventually(serverConn.LocalAddr).Should(Equal(preferredConn.LocalAddr()))
+
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+	})
+})
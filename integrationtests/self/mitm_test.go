@@ -109,7 +109,7 @@ var _ = Describe("MITM test", func() {
 				defer ticker.Stop()
 
 				if wire.IsLongHeaderPacket(raw[0]) {
-					hdr, _, _, err := wire.ParsePacket(raw)
+					hdr, _, _, err := wire.ParsePacket(raw, false)
 					Expect(err).ToNot(HaveOccurred())
 					replyHdr := &wire.ExtendedHeader{
 						Header: wire.Header{
@@ -138,12 +138,12 @@ var _ = Describe("MITM test", func() {
 				} else {
 					connID, err := wire.ParseConnectionID(raw, connIDLen)
 					Expect(err).ToNot(HaveOccurred())
-					_, pn, pnLen, _, err := wire.ParseShortHeader(raw, connIDLen)
+					_, pn, pnLen, _, err := wire.ParseShortHeader(raw, connIDLen, false)
 					if err != nil { // normally, ParseShortHeader is called after decrypting the header
 						Expect(err).To(MatchError(wire.ErrInvalidReservedBits))
 					}
 					for i := 0; i < numPackets; i++ {
-						b, err := wire.AppendShortHeader(nil, connID, pn, pnLen, protocol.KeyPhaseBit(rand.Intn(2)))
+						b, err := wire.AppendShortHeader(nil, connID, pn, pnLen, protocol.KeyPhaseBit(rand.Intn(2)), false)
 						Expect(err).ToNot(HaveOccurred())
 						payloadLen := rand.Int31n(100)
 						r := make([]byte, payloadLen)
@@ -334,7 +334,7 @@ var _ = Describe("MITM test", func() {
 				if dir == quicproxy.DirectionIncoming {
 					defer GinkgoRecover()
 
-					hdr, _, _, err := wire.ParsePacket(raw)
+					hdr, _, _, err := wire.ParsePacket(raw, false)
 					Expect(err).ToNot(HaveOccurred())
 
 					if hdr.Type != protocol.PacketTypeInitial {
@@ -377,7 +377,7 @@ var _ = Describe("MITM test", func() {
 					defer GinkgoRecover()
 					defer close(done)
 
-					hdr, _, _, err := wire.ParsePacket(raw)
+					hdr, _, _, err := wire.ParsePacket(raw, false)
 					Expect(err).ToNot(HaveOccurred())
 
 					if hdr.Type != protocol.PacketTypeInitial {
@@ -412,7 +412,7 @@ var _ = Describe("MITM test", func() {
 				if dir == quicproxy.DirectionIncoming {
 					defer GinkgoRecover()
 
-					hdr, _, _, err := wire.ParsePacket(raw)
+					hdr, _, _, err := wire.ParsePacket(raw, false)
 					Expect(err).ToNot(HaveOccurred())
 					if hdr.Type != protocol.PacketTypeInitial || injected {
 						return 0
@@ -442,7 +442,7 @@ var _ = Describe("MITM test", func() {
 				if dir == quicproxy.DirectionIncoming {
 					defer GinkgoRecover()
 
-					hdr, _, _, err := wire.ParsePacket(raw)
+					hdr, _, _, err := wire.ParsePacket(raw, false)
 					Expect(err).ToNot(HaveOccurred())
 					if hdr.Type != protocol.PacketTypeInitial || injected {
 						return 0
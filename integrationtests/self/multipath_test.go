@@ -0,0 +1,121 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multipath", func() {
+	It("transfers data using multiple paths", func() {
+		ln, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableMultipath: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			str.Close()
+		}()
+
+		udpConn1, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr1 := &quic.Transport{Conn: udpConn1, ConnectionIDLength: 4}
+		defer tr1.Close()
+		udpConn2, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr2 := &quic.Transport{Conn: udpConn2, ConnectionIDLength: 4}
+		defer tr2.Close()
+
+		conn, err := tr1.Dial(
+			context.Background(),
+			&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: ln.Addr().(*net.UDPAddr).Port},
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				EnableMultipath: true,
+				PathScheduler:   quic.NewRoundRobinPathScheduler,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+
+		// The peer's connection IDs for the new path might not have arrived yet.
+		var path *quic.MultipathPath
+		Eventually(func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(time.Second))
+			defer cancel()
+			var err error
+			path, err = conn.OpenPath(ctx, tr2)
+			return err
+		}).Should(Succeed())
+		Expect(path.ID()).To(Equal(quic.PathID(1)))
+		Expect(path.LocalAddr()).To(Equal(udpConn2.LocalAddr()))
+		Expect(conn.Paths()).To(HaveLen(2))
+
+		data := GeneratePRData(2 << 20)
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			_, err := str.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+		received, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bytes.Equal(received, data)).To(BeTrue())
+		Expect(serverConn.Paths()).To(HaveLen(2))
+
+		// After closing the path, the connection continues on path 0.
+		Expect(path.Close()).To(Succeed())
+		Eventually(conn.Paths).Should(HaveLen(1))
+		Eventually(serverConn.Paths).Should(HaveLen(1))
+		str, err = conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+	})
+
+	It("doesn't use multipath unless both endpoints enable it", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr := &quic.Transport{Conn: udpConn, ConnectionIDLength: 4}
+		defer tr.Close()
+		conn, err := tr.Dial(
+			context.Background(),
+			&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: ln.Addr().(*net.UDPAddr).Port},
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableMultipath: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		Expect(conn.Paths()).To(BeEmpty())
+		_, err = conn.OpenPath(context.Background(), tr)
+		Expect(err).To(MatchError("multipath extension not negotiated"))
+	})
+})
@@ -3,7 +3,9 @@ package self_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go"
@@ -117,4 +119,134 @@ var _ = Describe("Packetization", func() {
 			BeNumerically(">", numMsg*9/10),
 		))
 	})
+
+	It("reduces the number of ACKs when using the ACK frequency extension", func() {
+		serverCounter, serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: true,
+				Tracer:             newTracer(serverTracer),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRDataLong)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		clientCounter, clientTracer := newPacketTracer()
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: true,
+				Tracer:             newTracer(clientTracer),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRDataLong))
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+
+		var numAckFrequencyFrames int
+		for _, p := range serverCounter.getSentShortHeaderPackets() {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrequencyFrame); ok {
+					numAckFrequencyFrames++
+				}
+			}
+		}
+		var numAcks int
+		for _, p := range clientCounter.getSentShortHeaderPackets() {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrame); ok {
+					numAcks++
+				}
+			}
+		}
+		numRcvd := len(clientCounter.getRcvdShortHeaderPackets())
+		fmt.Fprintf(GinkgoWriter, "ACK_FREQUENCY frames sent: %d, ACKs sent for %d packets: %d\n", numAckFrequencyFrames, numRcvd, numAcks)
+		Expect(numAckFrequencyFrames).ToNot(BeZero())
+		// Without the extension, the client would acknowledge every other packet.
+		Expect(numAcks).To(BeNumerically("<", numRcvd/4))
+	})
+
+	It("greases the QUIC bit", func() {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableQUICBitGreasing: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		var numPackets, numGreased [2]atomic.Int64 // indexed by: 0 = incoming, 1 = outgoing
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(dir quicproxy.Direction, b []byte) time.Duration {
+				var i int
+				if dir == quicproxy.DirectionOutgoing {
+					i = 1
+				}
+				numPackets[i].Add(1)
+				if b[0]&0x40 == 0 {
+					numGreased[i].Add(1)
+				}
+				return 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableQUICBitGreasing: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			_, err := str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+
+		for i := range numPackets {
+			fmt.Fprintf(GinkgoWriter, "greased the QUIC bit on %d of %d packets\n", numGreased[i].Load(), numPackets[i].Load())
+			Expect(numGreased[i].Load()).To(BeNumerically(">", numPackets[i].Load()/4))
+			Expect(numGreased[i].Load()).To(BeNumerically("<", numPackets[i].Load()*3/4))
+		}
+	})
 })
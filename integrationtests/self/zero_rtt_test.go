@@ -57,7 +57,7 @@ func contains0RTTPacket(data []byte) bool {
 		if !wire.IsLongHeaderPacket(data[0]) {
 			return false
 		}
-		hdr, _, rest, err := wire.ParsePacket(data)
+		hdr, _, rest, err := wire.ParsePacket(data, false)
 		if err != nil {
 			return false
 		}
@@ -414,7 +414,7 @@ var _ = Describe("0-RTT", func() {
 				if !wire.IsLongHeaderPacket(data[0]) {
 					return false
 				}
-				hdr, _, _, err := wire.ParsePacket(data)
+				hdr, _, _, err := wire.ParsePacket(data, false)
 				Expect(err).ToNot(HaveOccurred())
 				if hdr.Type == protocol.PacketType0RTT {
 					count := num0RTTPackets.Add(1)
@@ -451,7 +451,7 @@ var _ = Describe("0-RTT", func() {
 
 		countZeroRTTBytes := func(data []byte) (n protocol.ByteCount) {
 			for len(data) > 0 {
-				hdr, _, rest, err := wire.ParsePacket(data)
+				hdr, _, rest, err := wire.ParsePacket(data, false)
 				if err != nil {
 					return
 				}
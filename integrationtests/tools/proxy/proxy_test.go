@@ -41,7 +41,7 @@ func makePacket(t *testing.T, p protocol.PacketNumber, payload []byte) []byte {
 
 func readPacketNumber(t *testing.T, b []byte) protocol.PacketNumber {
 	t.Helper()
-	hdr, data, _, err := wire.ParsePacket(b)
+	hdr, data, _, err := wire.ParsePacket(b, false)
 	require.NoError(t, err)
 	require.Equal(t, protocol.PacketTypeInitial, hdr.Type)
 	extHdr, err := hdr.ParseExtended(data)
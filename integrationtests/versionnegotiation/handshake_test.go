@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"slices"
 	"testing"
 	"time"
 
@@ -188,3 +190,88 @@ func TestServerDisablesVersionNegotiation(t *testing.T) {
 	require.True(t, nerr.Timeout())
 	require.False(t, clientResult.receivedVersionNegotiation)
 }
+
+func TestCompatibleVersionNegotiation(t *testing.T) {
+	serverResult, serverTracer := newVersionNegotiationTracer(t)
+	serverConfig := &quic.Config{
+		Versions: []protocol.Version{quic.Version2, quic.Version1},
+		Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+			return serverTracer
+		},
+	}
+	server, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
+	require.NoError(t, err)
+	defer server.Close()
+
+	clientResult, clientTracer := newVersionNegotiationTracer(t)
+	var initialVersions []logging.Version
+	clientTracer.SentLongHeaderPacket = func(hdr *logging.ExtendedHeader, _ logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, _ []logging.Frame) {
+		if hdr.Type == protocol.PacketTypeInitial && !slices.Contains(initialVersions, hdr.Version) {
+			initialVersions = append(initialVersions, hdr.Version)
+		}
+	}
+	conn, err := quic.DialAddr(
+		context.Background(),
+		fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+		getTLSClientConfig(),
+		maybeAddQLOGTracer(&quic.Config{
+			Versions: []protocol.Version{quic.Version1, quic.Version2},
+			Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+				return clientTracer
+			},
+		}),
+	)
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+	require.Equal(t, quic.Version2, conn.ConnectionState().Version)
+
+	sconn, err := server.Accept(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, quic.Version2, sconn.ConnectionState().Version)
+
+	str, err := conn.OpenStream()
+	require.NoError(t, err)
+	_, err = str.Write([]byte("foobar"))
+	require.NoError(t, err)
+	require.NoError(t, str.Close())
+	sstr, err := sconn.AcceptStream(context.Background())
+	require.NoError(t, err)
+	data, err := io.ReadAll(sstr)
+	require.NoError(t, err)
+	require.Equal(t, []byte("foobar"), data)
+	require.NoError(t, conn.CloseWithError(0, ""))
+
+	select {
+	case <-sconn.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for connection to close")
+	}
+
+	// the client started with QUIC v1, and switched to QUIC v2 without a Version Negotiation packet
+	require.Equal(t, []logging.Version{quic.Version1, quic.Version2}, initialVersions)
+	require.False(t, clientResult.receivedVersionNegotiation)
+	require.Equal(t, quic.Version2, clientResult.chosen)
+	require.Equal(t, quic.Version2, serverResult.chosen)
+}
+
+func TestCompatibleVersionNegotiationNotOfferedByClient(t *testing.T) {
+	server, err := quic.ListenAddr("localhost:0", getTLSConfig(), &quic.Config{
+		Versions: []protocol.Version{quic.Version2, quic.Version1},
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	conn, err := quic.DialAddr(
+		context.Background(),
+		fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+		getTLSClientConfig(),
+		maybeAddQLOGTracer(&quic.Config{Versions: []protocol.Version{quic.Version1}}),
+	)
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+	require.Equal(t, quic.Version1, conn.ConnectionState().Version)
+
+	sconn, err := server.Accept(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, quic.Version1, sconn.ConnectionState().Version)
+}
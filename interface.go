@@ -8,6 +8,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/logging"
@@ -138,6 +139,19 @@ type SendStream interface {
 	// some data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// CancelWriteAt aborts sending on this stream, like CancelWrite.
+	// However, the first reliableSize bytes of the stream are still delivered to the peer,
+	// using the RESET_STREAM_AT frame (draft-ietf-quic-reliable-stream-reset).
+	// This can be used to guarantee the delivery of a stream header.
+	// If reliableSize exceeds the number of bytes written to the stream, it is reduced accordingly.
+	// The reliable size can be reduced by calling CancelWriteAt (or CancelWrite) again.
+	// If the peer doesn't support RESET_STREAM_AT, this is equivalent to calling CancelWrite,
+	// see ConnectionState.SupportsStreamResetPartialDelivery.
+	CancelWriteAt(errorCode StreamErrorCode, reliableSize int64)
+	// SetPriority sets the priority of the stream.
+	// How the priority is used depends on the StreamScheduler configured in Config.StreamScheduler.
+	// By default, streams are scheduled round-robin, and the priority is ignored.
+	SetPriority(StreamPriority)
 }
 
 // A Connection is a QUIC connection between two peers.
@@ -197,6 +211,32 @@ type Connection interface {
 	// ConnectionState returns basic details about the QUIC connection.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// ConnectionStats returns a snapshot of the connection's statistics.
+	// The statistics are collected by the connection's run loop when this method is called.
+	// After the connection was closed, it returns the final statistics.
+	ConnectionStats() ConnectionStats
+
+	// AddPath adds a new path to the connection, using the Transport to send and receive packets.
+	// It can only be called by the client, after the handshake has completed.
+	// The path needs to be probed using Path.Probe before the connection can switch to it.
+	// Warning: This API should not be considered stable and might change soon.
+	AddPath(*Transport) (*Path, error)
+	// OriginalPath returns the path that the handshake was performed on.
+	// After switching to a path added using AddPath, the connection can switch back to this path.
+	// It can only be called by the client, after the handshake has completed.
+	// Warning: This API should not be considered stable and might change soon.
+	OriginalPath() (*Path, error)
+
+	// OpenPath opens a new path using the multipath extension, using the Transport to send and receive packets.
+	// Different from a path added using AddPath, packets are sent on all paths of the connection at the same time.
+	// It can only be called by the client, once the handshake has completed, and if the multipath extension
+	// was negotiated. It blocks until the path was validated, or the context is canceled.
+	// Warning: This API should not be considered stable and might change soon.
+	OpenPath(context.Context, *Transport) (*MultipathPath, error)
+	// Paths returns the paths used by a connection that negotiated the multipath extension,
+	// including the path that the handshake was performed on.
+	// Warning: This API should not be considered stable and might change soon.
+	Paths() []*MultipathPath
 
 	// SendDatagram sends a message using a QUIC datagram, as specified in RFC 9221.
 	// There is no delivery guarantee for DATAGRAM frames, they are not retransmitted if lost.
@@ -334,12 +374,89 @@ type Config struct {
 	// This allows the sending of QUIC packets that fully utilize the available MTU of the path.
 	// Path MTU discovery is only available on systems that allow setting of the Don't Fragment (DF) bit.
 	DisablePathMTUDiscovery bool
+	// EnableActiveMigration enables active connection migration.
+	// Only valid for the server: Unless set, it sends the disable_active_migration transport parameter,
+	// and drops packets that the client sends from a new address using a new connection ID.
+	// If set, the server validates the new address, and switches to it.
+	// This only applies to deliberate migrations: The server always validates and switches to
+	// a new client address after a NAT rebinding, and to the server's preferred address.
+	EnableActiveMigration bool
+	// AllowMigration is called on the server when the client deliberately migrates the connection
+	// to a new address, if EnableActiveMigration is set, or to the server's preferred address.
+	// It is not called for NAT rebindings.
+	// If it returns false, packets from this address are dropped.
+	// If not set, the server validates the new address and switches to it.
+	// To avoid deadlocks, it is not valid to call other functions on the connection in this callback.
+	AllowMigration func(conn Connection, addr net.Addr) bool
 	// Allow0RTT allows the application to decide if a 0-RTT connection attempt should be accepted.
 	// Only valid for the server.
 	Allow0RTT bool
+	// Accept0RTT is called on the server when a client attempts to use 0-RTT,
+	// and decides if 0-RTT is accepted for this connection attempt.
+	// It is only called if Allow0RTT is set, and if the transport parameters stored in the session ticket
+	// are compatible with the current configuration.
+	// If it returns false, 0-RTT is rejected, and the handshake proceeds without 0-RTT.
+	Accept0RTT func(info *ZeroRTTInfo) bool
+	// GetSessionTicketData returns application data that is stored with a session ticket.
+	// On the server, it is called when a session ticket is issued, and the data is stored in the
+	// encrypted session ticket. The client can't read it.
+	// On the client, it is called when a session ticket is received, and the data is stored in the
+	// tls.ClientSessionCache, together with the session ticket.
+	// When the session ticket is used, the data is available in ConnectionState.SessionTicketData,
+	// and on the server also in ZeroRTTInfo.SessionTicketData.
+	// To avoid deadlocks, it is not valid to call other functions on the connection in this callback.
+	GetSessionTicketData func(conn Connection) []byte
+	// ValidateSessionTicketData is called with the data returned by GetSessionTicketData when
+	// a session ticket is used for 0-RTT.
+	// If it returns false, 0-RTT is rejected on the server, and not attempted on the client.
+	// This allows rejecting 0-RTT if the application's settings changed, as required by HTTP/3
+	// (see section 7.2.4.2 of RFC 9114).
+	ValidateSessionTicketData func(data []byte) bool
+	// ZeroRTTAntiReplay protects against replays of 0-RTT data, by accepting 0-RTT at most once
+	// for every session ticket. It is only used if Allow0RTT is set.
+	// This is recommended for applications that don't only process idempotent requests in 0-RTT.
+	// NewAntiReplayStore returns an in-memory store.
+	// Only valid for the server.
+	ZeroRTTAntiReplay AntiReplayStore
 	// Enable QUIC datagram support (RFC 9221).
 	EnableDatagrams bool
-	Tracer          func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
+	// EnableStreamResetPartialDelivery enables support for the RESET_STREAM_AT frame
+	// (draft-ietf-quic-reliable-stream-reset).
+	// If both endpoints enable it, SendStream.CancelWriteAt resets a stream while
+	// still reliably delivering the beginning of the stream.
+	EnableStreamResetPartialDelivery bool
+	// EnableAckFrequency enables the ACK frequency extension (draft-ietf-quic-ack-frequency).
+	// The peer can then use the ACK_FREQUENCY and IMMEDIATE_ACK frames to control how often acknowledgments are sent.
+	// If the peer enables the extension as well, the number of ack-eliciting packets the peer may receive
+	// before acknowledging them is scaled with the congestion window, reducing the number of ACKs
+	// on high-throughput connections.
+	EnableAckFrequency bool
+	// EnableQUICBitGreasing advertises support for greasing the QUIC bit (RFC 9287).
+	// We then accept packets that have the QUIC bit set to 0, and if the peer enables it as well,
+	// the QUIC bit is randomized on the packets we send, making it harder for middleboxes to ossify on it.
+	// Packets with the QUIC bit set to 0 can't be distinguished from other protocols,
+	// so this should not be used together with Transport.ReadNonQUICPacket.
+	EnableQUICBitGreasing bool
+	// EnableMultipath enables the multipath extension for QUIC (draft-ietf-quic-multipath).
+	// It is only negotiated if both endpoints enable it, and if both use non-zero-length connection IDs.
+	// The client can then open additional paths using Connection.OpenPath.
+	EnableMultipath bool
+	// PathScheduler is called once for every connection that negotiated the multipath extension.
+	// The PathScheduler decides which path a packet is sent on.
+	// If not set, packets are sent on the path with the lowest RTT, see NewMinRTTPathScheduler.
+	PathScheduler func() PathScheduler
+	// StreamScheduler is called once for every connection.
+	// The StreamScheduler decides which stream the next STREAM frame is sent on.
+	// If not set, data is sent on all streams in turn, see NewRoundRobinStreamScheduler.
+	// NewStrictPriorityStreamScheduler and NewWeightedFairStreamScheduler take the stream priority into account.
+	StreamScheduler func() StreamScheduler
+	// CongestionControl creates the congestion controller.
+	// It is called when a connection is created, every time the congestion controller is reset
+	// (e.g. when the connection is migrated to a new path), and for every path of a multipath connection.
+	// If not set, NewReno is used, see congestion.NewReno.
+	// To use BBR, set this to congestion.NewBBR.
+	CongestionControl func(rttStats *congestion.RTTStats, initialMaxDatagramSize congestion.ByteCount) congestion.SendAlgorithmWithDebugInfos
+	Tracer            func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
 }
 
 // ClientHelloInfo contains information about an incoming connection attempt.
@@ -347,25 +464,88 @@ type ClientHelloInfo struct {
 	// RemoteAddr is the remote address on the Initial packet.
 	// Unless AddrVerified is set, the address is not yet verified, and could be a spoofed IP address.
 	RemoteAddr net.Addr
+	// LocalAddr is the local address the Initial packet was received on.
+	// When listening on a wildcard address, this is the destination address of the packet (if the platform
+	// reports it), allowing to apply different configurations for different addresses.
+	// All packets sent on this connection use this address as their source address.
+	LocalAddr net.Addr
 	// AddrVerified says if the remote address was verified using QUIC's Retry mechanism.
 	// Note that the Retry mechanism costs one network roundtrip,
 	// and is not performed unless Transport.MaxUnvalidatedHandshakes is surpassed.
 	AddrVerified bool
+	// Token is the address validation token sent by the client, if it was successfully validated.
+	// Tokens issued using a NEW_TOKEN frame carry the data returned by DefaultTokenProvider.GetData.
+	Token *AddressToken
+}
+
+// ConnectionStats is a snapshot of the statistics of a QUIC connection.
+// All values are taken at the same point in time, and apply to the connection's primary path.
+type ConnectionStats struct {
+	// The RTT estimates, see RFC 9002 section 5.
+	MinRTT        time.Duration
+	LatestRTT     time.Duration
+	SmoothedRTT   time.Duration
+	MeanDeviation time.Duration
+
+	// CongestionWindow is the congestion window of the congestion controller, in bytes.
+	CongestionWindow uint64
+	// BytesInFlight is the number of bytes sent in ack-eliciting packets that were not yet acknowledged or declared lost.
+	BytesInFlight uint64
+
+	// BytesSent and BytesReceived count the UDP payload bytes sent and received.
+	BytesSent     uint64
+	BytesReceived uint64
+	PacketsSent   uint64
+	PacketsLost   uint64
+	// PacketsRetransmitted is the number of packets whose frames were queued for retransmission,
+	// either because the packet was declared lost, or because the probe timeout (PTO) fired.
+	// Every lost packet is counted, so this is always at least PacketsLost.
+	PacketsRetransmitted uint64
+
+	// MTU is the maximum size of the UDP payload of packets sent,
+	// as determined by DPLPMTUD (RFC 8899).
+	MTU uint64
+	// ECNState is the state of the ECN validation (RFC 9000 section 13.4.2).
+	// It is 0 if ECN is disabled, or if the validation hasn't started yet.
+	ECNState logging.ECNState
+	// SendWindow is the connection-level flow control credit, i.e. the number of bytes
+	// of stream data that the peer currently allows us to send.
+	SendWindow uint64
+	// ReceiveWindow is the connection-level flow control credit granted to the peer,
+	// i.e. the number of bytes of stream data that the peer is currently allowed to send.
+	ReceiveWindow uint64
 }
 
 // ConnectionState records basic details about a QUIC connection
 type ConnectionState struct {
 	// TLS contains information about the TLS connection state, incl. the tls.ConnectionState.
+	// If the client offered Encrypted Client Hello (ECH), TLS.ECHAccepted says if the server accepted it.
 	TLS tls.ConnectionState
 	// SupportsDatagrams says if support for QUIC datagrams (RFC 9221) was negotiated.
 	// This requires both nodes to support and enable the datagram extensions (via Config.EnableDatagrams).
 	// If datagram support was negotiated, datagrams can be sent and received using the
 	// SendDatagram and ReceiveDatagram methods on the Connection.
 	SupportsDatagrams bool
+	// SupportsStreamResetPartialDelivery says if support for the RESET_STREAM_AT frame
+	// (draft-ietf-quic-reliable-stream-reset) was negotiated.
+	// This requires both nodes to enable it via Config.EnableStreamResetPartialDelivery.
+	SupportsStreamResetPartialDelivery bool
 	// Used0RTT says if 0-RTT resumption was used.
 	Used0RTT bool
+	// SessionTicketData is the application data stored with the session ticket used for this connection,
+	// see Config.GetSessionTicketData.
+	// On the client, it is available as soon as the session ticket is offered to the server,
+	// i.e. before the handshake completes. This allows 0-RTT requests to use remembered settings.
+	// Whether the session was actually resumed is only known once the handshake completes (see TLS.DidResume).
+	// On the server, it is set once the ClientHello was processed, if the session was resumed.
+	// This happens before the handshake completes, and before any 0-RTT data is processed.
+	// While deciding if 0-RTT is accepted, the data is passed to Config.ValidateSessionTicketData
+	// and is available in ZeroRTTInfo.SessionTicketData.
+	SessionTicketData []byte
 	// Version is the QUIC version of the QUIC connection.
 	Version Version
 	// GSO says if generic segmentation offload is used
 	GSO bool
+	// GRO says if generic receive offload is used
+	GRO bool
 }
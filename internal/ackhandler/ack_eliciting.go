@@ -4,9 +4,12 @@ import "github.com/quic-go/quic-go/internal/wire"
 
 // IsFrameAckEliciting returns true if the frame is ack-eliciting.
 func IsFrameAckEliciting(f wire.Frame) bool {
-	_, isAck := f.(*wire.AckFrame)
-	_, isConnectionClose := f.(*wire.ConnectionCloseFrame)
-	return !isAck && !isConnectionClose
+	switch f.(type) {
+	case *wire.AckFrame, *wire.PathAckFrame, *wire.ConnectionCloseFrame:
+		return false
+	default:
+		return true
+	}
 }
 
 // HasAckElicitingFrames returns true if at least one frame is ack-eliciting.
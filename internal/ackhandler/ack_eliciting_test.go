@@ -12,6 +12,7 @@ import (
 var _ = Describe("ack-eliciting frames", func() {
 	for fl, el := range map[wire.Frame]bool{
 		&wire.AckFrame{}:             false,
+		&wire.PathAckFrame{}:         false,
 		&wire.ConnectionCloseFrame{}: false,
 		&wire.DataBlockedFrame{}:     true,
 		&wire.PingFrame{}:            true,
@@ -19,6 +20,7 @@ var _ = Describe("ack-eliciting frames", func() {
 		&wire.StreamFrame{}:          true,
 		&wire.MaxDataFrame{}:         true,
 		&wire.MaxStreamDataFrame{}:   true,
+		&wire.PathAbandonFrame{}:     true,
 	} {
 		f := fl
 		e := el
@@ -1,24 +1,55 @@
 package ackhandler
 
 import (
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 )
 
+// NewCongestionControllerFunc creates a congestion controller.
+// It is called when the connection is created, and every time the congestion controller is reset,
+// e.g. after connection migration.
+type NewCongestionControllerFunc func(*utils.RTTStats, protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos
+
 // NewAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler.
 // clientAddressValidated indicates whether the address was validated beforehand by an address validation token.
 // clientAddressValidated has no effect for a client.
+// If newCongestionController is nil, the NewReno congestion controller is used.
 func NewAckHandler(
 	initialPacketNumber protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
 	rttStats *utils.RTTStats,
 	clientAddressValidated bool,
 	enableECN bool,
+	newCongestionController NewCongestionControllerFunc,
 	pers protocol.Perspective,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 ) (SentPacketHandler, ReceivedPacketHandler) {
-	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, enableECN, pers, tracer, logger)
+	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, enableECN, newCongestionController, pers, tracer, logger)
 	return sph, newReceivedPacketHandler(sph, logger)
 }
+
+// NewPathAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler
+// for a path of a multipath connection (other than the path that the handshake was performed on).
+// Every path uses its own packet number space, congestion controller and RTT estimate.
+// Paths are only created after the handshake has been confirmed,
+// so the Initial and the Handshake packet number space are dropped right away.
+func NewPathAckHandler(
+	initialMaxDatagramSize protocol.ByteCount,
+	rttStats *utils.RTTStats,
+	enableECN bool,
+	newCongestionController NewCongestionControllerFunc,
+	pers protocol.Perspective,
+	logger utils.Logger,
+) (SentPacketHandler, ReceivedPacketHandler) {
+	sph := newSentPacketHandler(0, initialMaxDatagramSize, rttStats, true, enableECN, newCongestionController, pers, nil, logger)
+	rph := newReceivedPacketHandler(sph, logger)
+	for _, encLevel := range []protocol.EncryptionLevel{protocol.EncryptionInitial, protocol.EncryptionHandshake} {
+		sph.DropPackets(encLevel)
+		rph.DropPackets(encLevel)
+	}
+	sph.SetHandshakeConfirmed()
+	return sph, rph
+}
@@ -0,0 +1,30 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Ack Handler", func() {
+	It("creates ack handlers for a new path", func() {
+		var rttStats utils.RTTStats
+		sph, rph := NewPathAckHandler(protocol.InitialPacketSize, &rttStats, false, nil, protocol.PerspectiveClient, utils.DefaultLogger)
+		// packet numbers start at 0
+		pn, _ := sph.PeekPacketNumber(protocol.Encryption1RTT)
+		Expect(pn).To(BeZero())
+		// the handshake is confirmed, so we're allowed to send right away
+		Expect(sph.SendMode(time.Now())).To(Equal(SendAny))
+
+		Expect(rph.ReceivedPacket(0, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+		Expect(rph.ReceivedPacket(1, protocol.ECNNon, protocol.Encryption1RTT, time.Now(), true)).To(Succeed())
+		ack := rph.GetAckFrame(protocol.Encryption1RTT, true)
+		Expect(ack).ToNot(BeNil())
+		Expect(ack.AckRanges).To(Equal([]wire.AckRange{{Smallest: 0, Largest: 1}}))
+	})
+})
@@ -26,6 +26,7 @@ type ecnHandler interface {
 	Mode() protocol.ECN
 	HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64) (congested bool)
 	LostPacket(protocol.PacketNumber)
+	State() logging.ECNState
 }
 
 // The ecnTracker performs ECN validation of a path.
@@ -117,6 +118,23 @@ func (e *ecnTracker) Mode() protocol.ECN {
 	}
 }
 
+// State returns the state of the ECN validation.
+// It returns 0 if the validation hasn't started yet.
+func (e *ecnTracker) State() logging.ECNState {
+	switch e.state {
+	case ecnStateTesting:
+		return logging.ECNStateTesting
+	case ecnStateUnknown:
+		return logging.ECNStateUnknown
+	case ecnStateCapable:
+		return logging.ECNStateCapable
+	case ecnStateFailed:
+		return logging.ECNStateFailed
+	default:
+		return 0
+	}
+}
+
 func (e *ecnTracker) LostPacket(pn protocol.PacketNumber) {
 	if e.state != ecnStateTesting && e.state != ecnStateUnknown {
 		return
@@ -43,6 +43,21 @@ var _ = Describe("ECN tracker", func() {
 		Expect(ecnTracker.Mode()).To(Equal(protocol.ECNNon))
 	})
 
+	It("reports its state", func() {
+		Expect(ecnTracker.State()).To(BeZero())
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateTesting, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.Mode()).To(Equal(protocol.ECT0))
+		Expect(ecnTracker.State()).To(Equal(logging.ECNStateTesting))
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateUnknown, logging.ECNTriggerNoTrigger)
+		for i := 0; i < 10; i++ {
+			ecnTracker.SentPacket(protocol.PacketNumber(i), protocol.ECT0)
+		}
+		Expect(ecnTracker.State()).To(Equal(logging.ECNStateUnknown))
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.HandleNewlyAcked(getAckedPackets(0, 1), 2, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.State()).To(Equal(logging.ECNStateCapable))
+	})
+
 	sendAllTestingPackets := func() {
 		tracer.EXPECT().ECNStateUpdated(logging.ECNStateTesting, logging.ECNTriggerNoTrigger)
 		tracer.EXPECT().ECNStateUpdated(logging.ECNStateUnknown, logging.ECNTriggerNoTrigger)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 )
 
 // SentPacketHandler handles ACKs received for outgoing packets
@@ -18,6 +19,9 @@ type SentPacketHandler interface {
 	DropPackets(protocol.EncryptionLevel)
 	ResetForRetry(rcvTime time.Time) error
 	SetHandshakeConfirmed()
+	// MigratedPath is called when the connection switches to a new path.
+	// It declares all outstanding packets lost, and resets the congestion controller and the RTT estimate.
+	MigratedPath(now time.Time, initialMaxDatagramSize protocol.ByteCount)
 
 	// The SendMode determines if and what kind of packets can be sent.
 	SendMode(now time.Time) SendMode
@@ -35,6 +39,21 @@ type SentPacketHandler interface {
 
 	GetLossDetectionTimeout() time.Time
 	OnLossDetectionTimeout() error
+
+	// Stats returns statistics about the packets sent and received, and the state of the congestion controller.
+	Stats() SentPacketStats
+}
+
+// SentPacketStats are the statistics collected by the SentPacketHandler.
+type SentPacketStats struct {
+	BytesSent            protocol.ByteCount
+	BytesReceived        protocol.ByteCount
+	PacketsSent          uint64
+	PacketsLost          uint64
+	PacketsRetransmitted uint64 // the number of packets whose frames were queued for retransmission after a loss or a PTO
+	BytesInFlight        protocol.ByteCount
+	CongestionWindow     protocol.ByteCount
+	ECNState             logging.ECNState // 0 if ECN is disabled, or ECN validation hasn't started yet
 }
 
 type sentPacketTracker interface {
@@ -47,6 +66,9 @@ type ReceivedPacketHandler interface {
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime time.Time, ackEliciting bool) error
 	DropPackets(protocol.EncryptionLevel)
+	// ACK_FREQUENCY and IMMEDIATE_ACK frames apply to the Application Data packet number space.
+	ReceivedAckFrequencyFrame(*wire.AckFrequencyFrame)
+	ReceivedImmediateAckFrame()
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
@@ -123,3 +123,11 @@ func (h *receivedPacketHandler) IsPotentiallyDuplicate(pn protocol.PacketNumber,
 	}
 	panic("unexpected encryption level")
 }
+
+func (h *receivedPacketHandler) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	h.appDataPackets.ReceivedAckFrequencyFrame(f)
+}
+
+func (h *receivedPacketHandler) ReceivedImmediateAckFrame() {
+	h.appDataPackets.ReceivedImmediateAckFrame()
+}
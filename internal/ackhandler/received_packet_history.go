@@ -124,6 +124,17 @@ func (h *receivedPacketHistory) GetHighestAckRange() wire.AckRange {
 	return ackRange
 }
 
+// SmallestMissingAbove returns the smallest packet number larger than p that hasn't been received,
+// but is smaller than the largest received packet number.
+func (h *receivedPacketHistory) SmallestMissingAbove(p protocol.PacketNumber) (protocol.PacketNumber, bool) {
+	for i := 0; i < len(h.ranges)-1; i++ {
+		if h.ranges[i+1].Start-1 > p {
+			return max(h.ranges[i].End+1, p+1), true
+		}
+	}
+	return 0, false
+}
+
 func (h *receivedPacketHistory) IsPotentiallyDuplicate(p protocol.PacketNumber) bool {
 	if p < h.deletedBelow {
 		return true
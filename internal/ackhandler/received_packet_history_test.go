@@ -256,6 +256,32 @@ var _ = Describe("receivedPacketHistory", func() {
 		})
 	})
 
+	Context("finding missing packets", func() {
+		It("doesn't find missing packets if there are no gaps", func() {
+			Expect(hist.ReceivedPacket(4)).To(BeTrue())
+			Expect(hist.ReceivedPacket(5)).To(BeTrue())
+			_, ok := hist.SmallestMissingAbove(3)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("finds the smallest missing packet", func() {
+			Expect(hist.ReceivedPacket(1)).To(BeTrue())
+			Expect(hist.ReceivedPacket(4)).To(BeTrue())
+			Expect(hist.ReceivedPacket(8)).To(BeTrue())
+			pn, ok := hist.SmallestMissingAbove(0)
+			Expect(ok).To(BeTrue())
+			Expect(pn).To(Equal(protocol.PacketNumber(2)))
+			pn, ok = hist.SmallestMissingAbove(2)
+			Expect(ok).To(BeTrue())
+			Expect(pn).To(Equal(protocol.PacketNumber(3)))
+			pn, ok = hist.SmallestMissingAbove(4)
+			Expect(ok).To(BeTrue())
+			Expect(pn).To(Equal(protocol.PacketNumber(5)))
+			_, ok = hist.SmallestMissingAbove(7)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
 	Context("duplicate detection", func() {
 		It("doesn't declare the first packet a duplicate", func() {
 			Expect(hist.IsPotentiallyDuplicate(5)).To(BeFalse())
@@ -75,6 +75,7 @@ const packetsBeforeAck = 2
 
 // The appDataReceivedPacketTracker tracks packets received in the Application Data packet number space.
 // It waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// The peer can change these values by sending an ACK_FREQUENCY frame.
 type appDataReceivedPacketTracker struct {
 	receivedPacketTracker
 
@@ -86,6 +87,11 @@ type appDataReceivedPacketTracker struct {
 	maxAckDelay time.Duration
 	ackQueued   bool // true if we need send a new ACK
 
+	// values set by the peer using ACK_FREQUENCY frames
+	ackElicitingThreshold  uint64
+	reorderingThreshold    protocol.PacketNumber
+	nextAckFrequencySeqNum uint64 // ACK_FREQUENCY frames with a smaller sequence number are ignored
+
 	ackElicitingPacketsReceivedSinceLastAck int
 	ackAlarm                                time.Time
 
@@ -96,6 +102,8 @@ func newAppDataReceivedPacketTracker(logger utils.Logger) *appDataReceivedPacket
 	h := &appDataReceivedPacketTracker{
 		receivedPacketTracker: *newReceivedPacketTracker(),
 		maxAckDelay:           protocol.MaxAckDelay,
+		ackElicitingThreshold: packetsBeforeAck - 1,
+		reorderingThreshold:   1,
 		logger:                logger,
 	}
 	return h
@@ -141,6 +149,34 @@ func (h *appDataReceivedPacketTracker) IgnoreBelow(pn protocol.PacketNumber) {
 	}
 }
 
+// ReceivedAckFrequencyFrame applies the values requested by the peer in an ACK_FREQUENCY frame.
+// Frames that are reordered (i.e. have a smaller sequence number than a previously received frame) are ignored.
+func (h *appDataReceivedPacketTracker) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	if f.SequenceNumber < h.nextAckFrequencySeqNum {
+		return
+	}
+	h.nextAckFrequencySeqNum = f.SequenceNumber + 1
+	h.ackElicitingThreshold = f.AckElicitingThreshold
+	h.reorderingThreshold = f.ReorderingThreshold
+	h.maxAckDelay = f.RequestMaxAckDelay
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdating ACK frequency: ack-eliciting threshold %d, max_ack_delay %s, reordering threshold %d", h.ackElicitingThreshold, h.maxAckDelay, h.reorderingThreshold)
+	}
+	// The ACK alarm might now fire later than the new max_ack_delay.
+	if alarm := h.largestObservedRcvdTime.Add(h.maxAckDelay); !h.ackAlarm.IsZero() && alarm.Before(h.ackAlarm) {
+		h.ackAlarm = alarm
+	}
+}
+
+// ReceivedImmediateAckFrame queues an ACK, as requested by an IMMEDIATE_ACK frame.
+func (h *appDataReceivedPacketTracker) ReceivedImmediateAckFrame() {
+	if h.logger.Debug() && !h.ackQueued {
+		h.logger.Debugf("\tQueueing ACK because an IMMEDIATE_ACK frame was received.")
+	}
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
+}
+
 // isMissing says if a packet was reported missing in the last ACK.
 func (h *appDataReceivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 	if h.lastAck == nil || p < h.ignoreBelow {
@@ -150,11 +186,18 @@ func (h *appDataReceivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 }
 
 func (h *appDataReceivedPacketTracker) hasNewMissingPackets() bool {
-	if h.lastAck == nil {
+	if h.lastAck == nil || h.reorderingThreshold == 0 {
 		return false
 	}
-	highestRange := h.packetHistory.GetHighestAckRange()
-	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	if h.reorderingThreshold == 1 {
+		highestRange := h.packetHistory.GetHighestAckRange()
+		return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	}
+	// With a larger reordering threshold, only report a missing packet once
+	// it has been overtaken by at least reorderingThreshold packets.
+	// See section 6.2 of draft-ietf-quic-ack-frequency.
+	missing, ok := h.packetHistory.SmallestMissingAbove(h.lastAck.LargestAcked())
+	return ok && h.largestObserved-missing >= h.reorderingThreshold
 }
 
 func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber, ecn protocol.ECN, wasMissing bool) bool {
@@ -167,17 +210,17 @@ func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber,
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ACK, send an ACK immediately.
-	if wasMissing {
+	if wasMissing && h.reorderingThreshold == 1 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		return true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK once the ack-eliciting threshold is exceeded (by default, every 2 ack-eliciting packets)
+	if uint64(h.ackElicitingPacketsReceivedSinceLastAck) > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		return true
 	}
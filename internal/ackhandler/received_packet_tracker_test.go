@@ -236,6 +236,75 @@ var _ = Describe("Application Data Received Packet Tracker", func() {
 				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
 				Expect(tracker.GetAckFrame(true)).To(BeNil())
 			})
+
+			Context("ACK frequency", func() {
+				It("uses the ack-eliciting threshold and max_ack_delay requested by the peer", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+						AckElicitingThreshold: 4,
+						RequestMaxAckDelay:    50 * time.Millisecond,
+						ReorderingThreshold:   1,
+					})
+					rcvTime := time.Now()
+					for i := 11; i <= 14; i++ {
+						Expect(tracker.ReceivedPacket(protocol.PacketNumber(i), protocol.ECNNon, rcvTime, true)).To(Succeed())
+						Expect(tracker.ackQueued).To(BeFalse())
+						Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(50 * time.Millisecond)))
+					}
+					Expect(tracker.ReceivedPacket(15, protocol.ECNNon, rcvTime, true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+				})
+
+				It("ignores reordered ACK_FREQUENCY frames", func() {
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: time.Millisecond})
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 0, AckElicitingThreshold: 5, RequestMaxAckDelay: time.Second})
+					Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(10))
+					Expect(tracker.maxAckDelay).To(Equal(time.Millisecond))
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 2, AckElicitingThreshold: 5, RequestMaxAckDelay: time.Second})
+					Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(5))
+					Expect(tracker.maxAckDelay).To(Equal(time.Second))
+				})
+
+				It("moves the ACK alarm forward when the max_ack_delay is decreased", func() {
+					receiveAndAck10Packets()
+					rcvTime := time.Now()
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, rcvTime, true)).To(Succeed())
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(protocol.MaxAckDelay)))
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 1, RequestMaxAckDelay: 5 * time.Millisecond, ReorderingThreshold: 1})
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(5 * time.Millisecond)))
+				})
+
+				It("doesn't queue ACKs for reordered packets if the reordering threshold is 0", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: protocol.MaxAckDelay})
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+				})
+
+				It("queues an ACK once a missing packet exceeds the reordering threshold", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: protocol.MaxAckDelay, ReorderingThreshold: 3})
+					// 11 is missing
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+				})
+
+				It("queues an ACK when receiving an IMMEDIATE_ACK frame", func() {
+					receiveAndAck10Packets()
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					tracker.ReceivedImmediateAckFrame()
+					Expect(tracker.GetAlarmTimeout()).To(BeZero())
+					ack := tracker.GetAckFrame(true)
+					Expect(ack).ToNot(BeNil())
+					Expect(ack.LargestAcked()).To(Equal(protocol.PacketNumber(11)))
+				})
+			})
 		})
 
 		Context("ACK generation", func() {
@@ -79,8 +79,15 @@ type sentPacketHandler struct {
 
 	bytesInFlight protocol.ByteCount
 
+	// statistics, see Stats
+	packetsSent          uint64
+	packetsLost          uint64
+	packetsRetransmitted uint64
+
 	congestion congestion.SendAlgorithmWithDebugInfos
-	rttStats   *utils.RTTStats
+	// creates the congestion controller, if set by the application
+	newCongestionController NewCongestionControllerFunc
+	rttStats                *utils.RTTStats
 
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
@@ -114,18 +121,11 @@ func newSentPacketHandler(
 	rttStats *utils.RTTStats,
 	clientAddressValidated bool,
 	enableECN bool,
+	newCongestionController NewCongestionControllerFunc,
 	pers protocol.Perspective,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 ) *sentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		tracer,
-	)
-
 	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
 		peerAddressValidated:           pers == protocol.PerspectiveClient || clientAddressValidated,
@@ -133,11 +133,12 @@ func newSentPacketHandler(
 		handshakePackets:               newPacketNumberSpace(0, false),
 		appDataPackets:                 newPacketNumberSpace(0, true),
 		rttStats:                       rttStats,
-		congestion:                     congestion,
+		newCongestionController:        newCongestionController,
 		perspective:                    pers,
 		tracer:                         tracer,
 		logger:                         logger,
 	}
+	h.congestion = h.createCongestionController(initialMaxDatagramSize)
 	if enableECN {
 		h.enableECN = true
 		h.ecnTracker = newECNTracker(logger, tracer)
@@ -145,6 +146,23 @@ func newSentPacketHandler(
 	return h
 }
 
+func (h *sentPacketHandler) createCongestionController(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+	if h.newCongestionController != nil {
+		cc := h.newCongestionController(h.rttStats, initialMaxDatagramSize)
+		if t, ok := cc.(congestion.TracingSendAlgorithm); ok && h.tracer != nil {
+			t.SetTracer(h.tracer)
+		}
+		return cc
+	}
+	return congestion.NewCubicSender(
+		congestion.DefaultClock{},
+		h.rttStats,
+		initialMaxDatagramSize,
+		true, // use Reno
+		h.tracer,
+	)
+}
+
 func (h *sentPacketHandler) removeFromBytesInFlight(p *packet) {
 	if p.includedInBytesInFlight {
 		if p.Length > h.bytesInFlight {
@@ -155,6 +173,16 @@ func (h *sentPacketHandler) removeFromBytesInFlight(p *packet) {
 	}
 }
 
+// discardPacket is called for packets that won't be acknowledged or declared lost.
+func (h *sentPacketHandler) discardPacket(p *packet) {
+	if p.declaredLost || p.skippedPacket {
+		return
+	}
+	if c, ok := h.congestion.(congestion.DiscardingSendAlgorithm); ok {
+		c.OnPacketDiscarded(p.PacketNumber)
+	}
+}
+
 func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	// The server won't await address validation after the handshake is confirmed.
 	// This applies even if we didn't receive an ACK for a Handshake packet.
@@ -170,6 +198,7 @@ func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 		}
 		pnSpace.history.Iterate(func(p *packet) (bool, error) {
 			h.removeFromBytesInFlight(p)
+			h.discardPacket(p)
 			return true, nil
 		})
 	}
@@ -190,6 +219,7 @@ func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 				return false, nil
 			}
 			h.removeFromBytesInFlight(p)
+			h.discardPacket(p)
 			h.appDataPackets.history.Remove(p.PacketNumber)
 			return true, nil
 		})
@@ -242,6 +272,7 @@ func (h *sentPacketHandler) SentPacket(
 	isPathMTUProbePacket bool,
 ) {
 	h.bytesSent += size
+	h.packetsSent++
 
 	pnSpace := h.getPacketNumberSpace(encLevel)
 	if h.logger.Debug() && pnSpace.history.HasOutstandingPackets() {
@@ -654,6 +685,8 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 		if packetLost {
 			pnSpace.history.DeclareLost(p.PacketNumber)
 			if !p.skippedPacket {
+				h.packetsLost++
+				h.packetsRetransmitted++
 				// the bytes in flight need to be reduced no matter if the frames in this packet will be retransmitted
 				h.removeFromBytesInFlight(p)
 				h.queueFramesForRetransmission(p)
@@ -838,10 +871,12 @@ func (h *sentPacketHandler) QueueProbePacket(encLevel protocol.EncryptionLevel)
 	if p == nil {
 		return false
 	}
+	h.packetsRetransmitted++
 	h.queueFramesForRetransmission(p)
 	// TODO: don't declare the packet lost here.
 	// Keep track of acknowledged frames instead.
 	h.removeFromBytesInFlight(p)
+	h.discardPacket(p)
 	pnSpace.history.DeclareLost(p.PacketNumber)
 	return true
 }
@@ -875,6 +910,7 @@ func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 			return true, nil
 		}
 		h.queueFramesForRetransmission(p)
+		h.discardPacket(p)
 		return true, nil
 	})
 	// All application data packets sent at this point are 0-RTT packets.
@@ -882,6 +918,7 @@ func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 	h.appDataPackets.history.Iterate(func(p *packet) (bool, error) {
 		if !p.declaredLost && !p.skippedPacket {
 			h.queueFramesForRetransmission(p)
+			h.discardPacket(p)
 		}
 		return true, nil
 	})
@@ -914,6 +951,33 @@ func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 	return nil
 }
 
+func (h *sentPacketHandler) MigratedPath(now time.Time, initialMaxDatagramSize protocol.ByteCount) {
+	h.rttStats.ResetForPathMigration()
+	// Packets sent on the old path are unlikely to be acknowledged.
+	// Retransmit their contents on the new path right away.
+	h.appDataPackets.history.Iterate(func(p *packet) (bool, error) {
+		if p.declaredLost || p.skippedPacket {
+			return true, nil
+		}
+		h.appDataPackets.history.DeclareLost(p.PacketNumber)
+		h.removeFromBytesInFlight(p)
+		h.queueFramesForRetransmission(p)
+		return true, nil
+	})
+	h.appDataPackets.lossTime = time.Time{}
+	h.congestion = h.createCongestionController(initialMaxDatagramSize)
+	if h.tracer != nil && h.tracer.UpdatedPTOCount != nil && h.ptoCount != 0 {
+		h.tracer.UpdatedPTOCount(0)
+	}
+	h.ptoCount = 0
+	h.numProbesToSend = 0
+	h.ptoMode = SendNone
+	if h.tracer != nil && h.tracer.UpdatedMetrics != nil {
+		h.tracer.UpdatedMetrics(h.rttStats, h.congestion.GetCongestionWindow(), h.bytesInFlight, h.packetsInFlight())
+	}
+	h.setLossDetectionTimer()
+}
+
 func (h *sentPacketHandler) SetHandshakeConfirmed() {
 	if h.initialPackets != nil {
 		panic("didn't drop initial correctly")
@@ -926,3 +990,19 @@ func (h *sentPacketHandler) SetHandshakeConfirmed() {
 	// Make sure the timer is armed now, if necessary.
 	h.setLossDetectionTimer()
 }
+
+func (h *sentPacketHandler) Stats() SentPacketStats {
+	stats := SentPacketStats{
+		BytesSent:            h.bytesSent,
+		BytesReceived:        h.bytesReceived,
+		PacketsSent:          h.packetsSent,
+		PacketsLost:          h.packetsLost,
+		PacketsRetransmitted: h.packetsRetransmitted,
+		BytesInFlight:        h.bytesInFlight,
+		CongestionWindow:     h.congestion.GetCongestionWindow(),
+	}
+	if h.ecnTracker != nil {
+		stats.ECNState = h.ecnTracker.State()
+	}
+	return stats
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/mocks"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -31,6 +33,15 @@ func (h *customFrameHandler) OnAcked(f wire.Frame) {
 	}
 }
 
+type discardingSendAlgorithm struct {
+	congestion.SendAlgorithmWithDebugInfos
+	discarded []protocol.PacketNumber
+}
+
+func (a *discardingSendAlgorithm) OnPacketDiscarded(pn protocol.PacketNumber) {
+	a.discarded = append(a.discarded, pn)
+}
+
 var _ = Describe("SentPacketHandler", func() {
 	var (
 		handler     *sentPacketHandler
@@ -44,7 +55,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		var rttStats utils.RTTStats
-		handler = newSentPacketHandler(42, protocol.InitialPacketSize, &rttStats, false, false, perspective, nil, utils.DefaultLogger)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSize, &rttStats, false, false, nil, perspective, nil, utils.DefaultLogger)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -702,6 +713,9 @@ var _ = Describe("SentPacketHandler", func() {
 			queued := handler.QueueProbePacket(protocol.Encryption1RTT)
 			Expect(queued).To(BeTrue())
 			Expect(lostPackets).To(Equal([]protocol.PacketNumber{10}))
+			// the packet wasn't declared lost, but its frames were retransmitted
+			Expect(handler.Stats().PacketsLost).To(BeZero())
+			Expect(handler.Stats().PacketsRetransmitted).To(BeEquivalentTo(1))
 		})
 
 		It("says when it can't queue a probe packet", func() {
@@ -984,7 +998,7 @@ var _ = Describe("SentPacketHandler", func() {
 	Context("amplification limit, for the server, with validated address", func() {
 		JustBeforeEach(func() {
 			var rttStats utils.RTTStats
-			handler = newSentPacketHandler(42, protocol.InitialPacketSize, &rttStats, true, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSize, &rttStats, true, false, nil, perspective, nil, utils.DefaultLogger)
 		})
 
 		It("do not limits the window", func() {
@@ -1118,6 +1132,27 @@ var _ = Describe("SentPacketHandler", func() {
 			expectInPacketHistory([]protocol.PacketNumber{4, 5}, protocol.Encryption1RTT)
 			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
 		})
+
+		It("counts sent, lost and retransmitted packets", func() {
+			now := time.Now()
+			handler.ReceivedBytes(1000)
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 100}))
+			}
+			sentPacket(nonAckElicitingPacket(&packet{PacketNumber: 7, Length: 50}))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			stats := handler.Stats()
+			Expect(stats.BytesSent).To(Equal(protocol.ByteCount(650)))
+			Expect(stats.BytesReceived).To(Equal(protocol.ByteCount(1000)))
+			Expect(stats.PacketsSent).To(BeEquivalentTo(7))
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(3))
+			Expect(stats.BytesInFlight).To(Equal(protocol.ByteCount(200)))
+			Expect(stats.CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+			Expect(stats.ECNState).To(BeZero())
+		})
 	})
 
 	Context("Delay-based loss detection", func() {
@@ -1283,6 +1318,26 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(6)))
 		})
 
+		It("tells the congestion controller about discarded packets", func() {
+			cong := &discardingSendAlgorithm{SendAlgorithmWithDebugInfos: handler.congestion}
+			handler.congestion = cong
+			for i := protocol.PacketNumber(0); i < 3; i++ {
+				sentPacket(initialPacket(&packet{PacketNumber: i}))
+			}
+			handler.DropPackets(protocol.EncryptionInitial)
+			Expect(cong.discarded).To(Equal([]protocol.PacketNumber{0, 1, 2}))
+			cong.discarded = nil
+			for i := protocol.PacketNumber(0); i < 5; i++ {
+				if i == 2 {
+					handler.appDataPackets.history.SkippedPacket(2)
+					continue
+				}
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, EncryptionLevel: protocol.Encryption0RTT}))
+			}
+			handler.DropPackets(protocol.Encryption0RTT)
+			Expect(cong.discarded).To(Equal([]protocol.PacketNumber{0, 1, 3, 4}))
+		})
+
 		It("cancels the PTO when dropping a packet number space", func() {
 			handler.ReceivedPacket(protocol.EncryptionHandshake)
 			now := time.Now()
@@ -1430,6 +1485,66 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("path migration", func() {
+		It("declares all outstanding packets lost", func() {
+			setHandshakeConfirmed()
+			for i := protocol.PacketNumber(0); i < 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 100}))
+			}
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(500)))
+			handler.MigratedPath(time.Now(), protocol.InitialPacketSize)
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{0, 1, 2, 3, 4}))
+			Expect(handler.bytesInFlight).To(BeZero())
+			expectInPacketHistory([]protocol.PacketNumber{}, protocol.Encryption1RTT)
+			// acknowledging a packet sent on the old path doesn't cause any problems
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 4}}}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("resets the RTT estimate and the congestion controller", func() {
+			setHandshakeConfirmed()
+			updateRTT(time.Second)
+			cong := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			handler.congestion = cong
+			handler.MigratedPath(time.Now(), protocol.InitialPacketSize)
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+			Expect(handler.rttStats.MinRTT()).To(BeZero())
+			Expect(handler.congestion).ToNot(Equal(cong))
+			Expect(handler.congestion.GetCongestionWindow()).To(Equal(32 * protocol.ByteCount(protocol.InitialPacketSize)))
+		})
+
+		It("uses the congestion controller provided by the application", func() {
+			var rttStats utils.RTTStats
+			var created []*mocks.MockSendAlgorithmWithDebugInfos
+			newCongestionController := func(r *utils.RTTStats, size protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+				Expect(r).To(Equal(&rttStats))
+				Expect(size).To(Equal(protocol.ByteCount(1234)))
+				cong := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+				created = append(created, cong)
+				return cong
+			}
+			handler = newSentPacketHandler(0, 1234, &rttStats, false, false, newCongestionController, perspective, nil, utils.DefaultLogger)
+			Expect(created).To(HaveLen(1))
+			Expect(handler.congestion).To(Equal(created[0]))
+			handler.MigratedPath(time.Now(), 1234)
+			Expect(created).To(HaveLen(2))
+			Expect(handler.congestion).To(Equal(created[1]))
+		})
+
+		It("sets the tracer on the congestion controller provided by the application", func() {
+			var rttStats utils.RTTStats
+			var states []logging.CongestionState
+			tracer := &logging.ConnectionTracer{
+				UpdatedCongestionState: func(s logging.CongestionState) { states = append(states, s) },
+			}
+			newCongestionController := func(r *utils.RTTStats, size protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+				return congestion.NewCubicSender(congestion.DefaultClock{}, r, size, true, nil)
+			}
+			newSentPacketHandler(0, 1234, &rttStats, false, false, newCongestionController, perspective, tracer, utils.DefaultLogger)
+			Expect(states).To(Equal([]logging.CongestionState{logging.CongestionStateSlowStart}))
+		})
+	})
+
 	Context("ECN handling", func() {
 		var ecnHandler *MockECNHandler
 		var cong *mocks.MockSendAlgorithmWithDebugInfos
@@ -1443,7 +1558,7 @@ var _ = Describe("SentPacketHandler", func() {
 			lostPackets = nil
 			var rttStats utils.RTTStats
 			rttStats.UpdateRTT(time.Hour, 0, time.Now())
-			handler = newSentPacketHandler(42, protocol.InitialPacketSize, &rttStats, false, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSize, &rttStats, false, false, nil, perspective, nil, utils.DefaultLogger)
 			handler.ecnTracker = ecnHandler
 			handler.congestion = cong
 		})
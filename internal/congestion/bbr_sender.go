@@ -0,0 +1,929 @@
+package congestion
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// This file implements BBR, as described in draft-ietf-ccwg-bbr (BBRv3).
+// The names of fields and functions follow the pseudocode of the draft where possible.
+
+const (
+	// The pacing gain used in Startup, 4*ln(2).
+	bbrStartupPacingGain = 2.77
+	// The pacing gain used in Drain.
+	bbrDrainPacingGain = 0.35
+	// The cwnd gain used in all states, except for ProbeRTT.
+	bbrDefaultCwndGain = 2.0
+	// The cwnd gain used in ProbeBW_UP.
+	bbrProbeUpCwndGain = 2.25
+	// The pacing gains used in the ProbeBW phases.
+	bbrProbeDownPacingGain = 0.9
+	bbrProbeUpPacingGain   = 1.25
+	// Pace at a rate slightly below the estimated bandwidth, to reduce queueing.
+	bbrPacingMarginPercent = 1
+
+	// The maximum tolerated loss rate per round.
+	bbrLossThresh = 0.02
+	// The multiplicative decrease applied to the lower bounds upon loss.
+	bbrBeta = 0.7
+	// The share of inflight_hi left unused to leave room for other flows.
+	bbrHeadroom = 0.15
+	// The number of loss events in a round that end Startup.
+	bbrStartupFullLossCount = 6
+	// Startup ends if the bandwidth didn't grow by this factor ...
+	bbrFullBwThreshold = 1.25
+	// ... for this many rounds.
+	bbrFullBwCount = 3
+
+	bbrMinPipeCwndPackets = 4
+	bbrMinRTTFilterLen    = 10 * time.Second
+	bbrProbeRTTCwndGain   = 0.5
+	bbrProbeRTTDuration   = 200 * time.Millisecond
+	bbrProbeRTTInterval   = 5 * time.Second
+	// The maximum number of rounds to wait before probing for bandwidth,
+	// to be fair to Reno / Cubic flows sharing the bottleneck.
+	bbrMaxRenoRounds = 63
+)
+
+type bbrState uint8
+
+const (
+	bbrStateStartup bbrState = iota
+	bbrStateDrain
+	bbrStateProbeBWDown
+	bbrStateProbeBWCruise
+	bbrStateProbeBWRefill
+	bbrStateProbeBWUp
+	bbrStateProbeRTT
+)
+
+func (s bbrState) isProbeBW() bool {
+	return s == bbrStateProbeBWDown || s == bbrStateProbeBWCruise || s == bbrStateProbeBWRefill || s == bbrStateProbeBWUp
+}
+
+func (s bbrState) congestionState() logging.CongestionState {
+	switch s {
+	case bbrStateStartup:
+		return logging.CongestionStateStartup
+	case bbrStateDrain:
+		return logging.CongestionStateDrain
+	case bbrStateProbeRTT:
+		return logging.CongestionStateProbeRTT
+	default:
+		return logging.CongestionStateProbeBW
+	}
+}
+
+type bbrAckPhase uint8
+
+const (
+	bbrAcksInit bbrAckPhase = iota
+	bbrAcksRefilling
+	bbrAcksProbeStarting
+	bbrAcksProbeFeedback
+	bbrAcksProbeStopping
+)
+
+// bbrPacketState is the state of the connection at the time a packet was sent,
+// used to compute delivery rate samples (see draft-ietf-ccwg-bbr, section 4.5.2).
+type bbrPacketState struct {
+	size          protocol.ByteCount
+	sendTime      time.Time
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	lost          protocol.ByteCount
+	txInFlight    protocol.ByteCount
+	isAppLimited  bool
+}
+
+type bbrRateSample struct {
+	deliveryRate   Bandwidth
+	isAppLimited   bool
+	delivered      protocol.ByteCount
+	priorDelivered protocol.ByteCount
+	txInFlight     protocol.ByteCount
+	lost           protocol.ByteCount
+	newlyAcked     protocol.ByteCount
+	rtt            time.Duration
+}
+
+type bbrSender struct {
+	rttStats *utils.RTTStats
+	pacer    *pacer
+	clock    Clock
+	rand     utils.Rand
+
+	maxDatagramSize protocol.ByteCount
+	cwnd            protocol.ByteCount
+	priorCwnd       protocol.ByteCount
+	pacingRate      Bandwidth
+	bytesInFlight   protocol.ByteCount
+
+	state      bbrState
+	pacingGain float64
+	cwndGain   float64
+
+	// delivery rate estimation
+	packets         map[protocol.PacketNumber]bbrPacketState
+	delivered       protocol.ByteCount
+	deliveredTime   time.Time
+	firstSentTime   time.Time
+	lost            protocol.ByteCount
+	appLimited      protocol.ByteCount // the value of delivered when the application-limited phase ends, 0 if not app-limited
+	cwndLimited     bool               // if the congestion window prevented sending since the last packet was sent
+	largestSentTime time.Time
+
+	// round counting
+	nextRoundDelivered protocol.ByteCount
+	roundStart         bool
+
+	// the bandwidth model
+	maxBwFilter    [2]Bandwidth
+	maxBw          Bandwidth
+	bwLo           Bandwidth
+	bw             Bandwidth
+	bwLatest       Bandwidth
+	inflightHi     protocol.ByteCount
+	inflightLo     protocol.ByteCount
+	inflightLatest protocol.ByteCount
+
+	// congestion signals
+	lossRoundStart     bool
+	lossRoundDelivered protocol.ByteCount
+	lossInRound        bool
+	lossEventsInRound  int
+	bwProbeSamples     bool
+
+	// Startup
+	filledPipe  bool
+	fullBw      Bandwidth
+	fullBwCount int
+
+	// ProbeBW
+	cycleStamp         time.Time
+	ackPhase           bbrAckPhase
+	bwProbeWait        time.Duration
+	roundsSinceBwProbe uint64
+	bwProbeUpCnt       protocol.ByteCount
+	bwProbeUpAcks      protocol.ByteCount
+	bwProbeUpRounds    int
+
+	// min RTT and ProbeRTT
+	minRTT            time.Duration
+	minRTTStamp       time.Time
+	probeRTTMinDelay  time.Duration
+	probeRTTMinStamp  time.Time
+	probeRTTExpired   bool
+	probeRTTDoneStamp time.Time
+	probeRTTRoundDone bool
+
+	lastState logging.CongestionState
+	tracer    *logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &bbrSender{}
+	_ SendAlgorithmWithDebugInfos = &bbrSender{}
+	_ TracingSendAlgorithm        = &bbrSender{}
+	_ DiscardingSendAlgorithm     = &bbrSender{}
+)
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer *logging.ConnectionTracer,
+) *bbrSender {
+	b := &bbrSender{
+		rttStats:         rttStats,
+		clock:            clock,
+		maxDatagramSize:  initialMaxDatagramSize,
+		cwnd:             initialCongestionWindow * initialMaxDatagramSize,
+		packets:          make(map[protocol.PacketNumber]bbrPacketState),
+		bwLo:             infBandwidth,
+		inflightHi:       protocol.MaxByteCount,
+		inflightLo:       protocol.MaxByteCount,
+		minRTT:           math.MaxInt64,
+		probeRTTMinDelay: math.MaxInt64,
+		ackPhase:         bbrAcksInit,
+	}
+	now := clock.Now()
+	b.minRTTStamp = now
+	b.probeRTTMinStamp = now
+	b.cycleStamp = now
+	b.pacer = newPacer(func() Bandwidth {
+		// The pacer sends slightly faster than the bandwidth it is given.
+		// BBR already chooses its pacing rate carefully, so compensate for that.
+		return b.pacingRate * 4 / 5
+	})
+	b.enterStartup()
+	b.initPacingRate()
+	b.SetTracer(tracer)
+	return b
+}
+
+// SetTracer sets the tracer, and traces the current state.
+func (b *bbrSender) SetTracer(tracer *logging.ConnectionTracer) {
+	b.tracer = tracer
+	if b.tracer != nil && b.tracer.UpdatedCongestionState != nil {
+		b.lastState = b.state.congestionState()
+		b.tracer.UpdatedCongestionState(b.lastState)
+	}
+}
+
+func (b *bbrSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return b.pacer.TimeUntilSend()
+}
+
+func (b *bbrSender) HasPacingBudget(now time.Time) bool {
+	return b.pacer.Budget(now) >= b.maxDatagramSize
+}
+
+func (b *bbrSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	if bytesInFlight >= b.cwnd {
+		b.cwndLimited = true
+		return false
+	}
+	return true
+}
+
+func (b *bbrSender) InSlowStart() bool { return b.state == bbrStateStartup }
+
+// InRecovery returns false.
+// BBR doesn't have a recovery state, losses are reflected in the lower bounds of the model.
+func (b *bbrSender) InRecovery() bool { return false }
+
+func (b *bbrSender) GetCongestionWindow() protocol.ByteCount { return b.cwnd }
+
+// MaybeExitSlowStart is a no-op.
+// BBR leaves Startup based on the delivery rate samples, see checkStartupFullBandwidth.
+func (b *bbrSender) MaybeExitSlowStart() {}
+
+func (b *bbrSender) OnPacketSent(
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	// If the pacer accumulated its full budget, and the congestion window didn't block us either,
+	// the application didn't have enough data to send.
+	if b.pacer.Budget(sentTime) >= b.pacer.maxBurstSize() && !b.cwndLimited {
+		b.appLimited = max(b.delivered+bytesInFlight, 1)
+	}
+	b.cwndLimited = false
+	b.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	b.bytesInFlight = bytesInFlight
+	if bytesInFlight <= bytes {
+		b.firstSentTime = sentTime
+		b.deliveredTime = sentTime
+	}
+	b.packets[packetNumber] = bbrPacketState{
+		size:          bytes,
+		sendTime:      sentTime,
+		delivered:     b.delivered,
+		deliveredTime: b.deliveredTime,
+		firstSentTime: b.firstSentTime,
+		lost:          b.lost,
+		txInFlight:    bytesInFlight,
+		isAppLimited:  b.appLimited != 0,
+	}
+}
+
+func (b *bbrSender) OnPacketAcked(
+	number protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	b.bytesInFlight = subtractBytes(b.bytesInFlight, ackedBytes)
+	b.delivered += ackedBytes
+	b.deliveredTime = eventTime
+	if b.appLimited != 0 && b.delivered > b.appLimited {
+		b.appLimited = 0
+	}
+	p, ok := b.packets[number]
+	if !ok {
+		return
+	}
+	delete(b.packets, number)
+
+	rs := bbrRateSample{
+		isAppLimited:   p.isAppLimited,
+		delivered:      b.delivered - p.delivered,
+		priorDelivered: p.delivered,
+		txInFlight:     p.txInFlight,
+		lost:           b.lost - p.lost,
+		newlyAcked:     ackedBytes,
+		rtt:            eventTime.Sub(p.sendTime),
+	}
+	if p.sendTime.After(b.largestSentTime) {
+		b.largestSentTime = p.sendTime
+		b.firstSentTime = p.sendTime
+	}
+	// Use the longer of the send and the ACK interval, to avoid overestimating the bandwidth
+	// when ACKs are compressed.
+	interval := max(p.sendTime.Sub(p.firstSentTime), eventTime.Sub(p.deliveredTime))
+	if interval > 0 && (b.minRTT == math.MaxInt64 || interval >= b.minRTT) {
+		rs.deliveryRate = BandwidthFromDelta(rs.delivered, interval)
+	}
+
+	b.updateModelAndState(&rs, priorInFlight, eventTime)
+	b.updateControlParameters(&rs)
+}
+
+func (b *bbrSender) OnCongestionEvent(number protocol.PacketNumber, lostBytes, _ protocol.ByteCount) {
+	now := b.clock.Now()
+	b.lossInRound = true
+	b.lossEventsInRound++
+	if lostBytes == 0 {
+		// An ECN-CE mark was reported. Like a packet loss, this means that the network is congested.
+		rs := bbrRateSample{txInFlight: b.bytesInFlight}
+		if b.bwProbeSamples {
+			b.handleInflightTooHigh(&rs, now)
+		}
+		return
+	}
+	b.bytesInFlight = subtractBytes(b.bytesInFlight, lostBytes)
+	b.lost += lostBytes
+	p, ok := b.packets[number]
+	if !ok {
+		return
+	}
+	delete(b.packets, number)
+	b.handleLostPacket(p, now)
+}
+
+// OnPacketDiscarded removes the state kept for a packet that won't be acknowledged or declared lost.
+func (b *bbrSender) OnPacketDiscarded(number protocol.PacketNumber) {
+	p, ok := b.packets[number]
+	if !ok {
+		return
+	}
+	delete(b.packets, number)
+	b.bytesInFlight = subtractBytes(b.bytesInFlight, p.size)
+}
+
+// OnRetransmissionTimeout collapses the congestion window, if packets were retransmitted.
+func (b *bbrSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	if !packetsRetransmitted {
+		return
+	}
+	b.saveCwnd()
+	b.cwnd = b.bytesInFlight + b.maxDatagramSize
+}
+
+func (b *bbrSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < b.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", b.maxDatagramSize, s))
+	}
+	b.maxDatagramSize = s
+	b.cwnd = max(b.cwnd, b.minPipeCwnd())
+	b.pacer.SetMaxDatagramSize(s)
+}
+
+func (b *bbrSender) updateModelAndState(rs *bbrRateSample, priorInFlight protocol.ByteCount, now time.Time) {
+	b.updateLatestDeliverySignals(rs)
+	b.updateCongestionSignals(rs)
+	b.checkStartupDone(rs)
+	b.checkDrain(now)
+	b.updateProbeBWCyclePhase(rs, priorInFlight, now)
+	b.updateMinRTT(rs, now)
+	b.checkProbeRTT(now)
+	b.advanceLatestDeliverySignals(rs)
+	b.boundBWForModel()
+}
+
+func (b *bbrSender) updateControlParameters(rs *bbrRateSample) {
+	b.setPacingRate()
+	b.setCwnd(rs)
+	b.maybeTraceStateChange(b.state.congestionState())
+}
+
+func (b *bbrSender) updateRound(rs *bbrRateSample) {
+	if rs.priorDelivered >= b.nextRoundDelivered {
+		b.startRound()
+		b.roundsSinceBwProbe++
+		b.roundStart = true
+	} else {
+		b.roundStart = false
+	}
+}
+
+func (b *bbrSender) startRound() {
+	b.nextRoundDelivered = b.delivered
+}
+
+func (b *bbrSender) updateLatestDeliverySignals(rs *bbrRateSample) {
+	b.lossRoundStart = false
+	b.bwLatest = max(b.bwLatest, rs.deliveryRate)
+	b.inflightLatest = max(b.inflightLatest, rs.delivered)
+	if rs.priorDelivered >= b.lossRoundDelivered {
+		b.lossRoundDelivered = b.delivered
+		b.lossRoundStart = true
+	}
+}
+
+func (b *bbrSender) advanceLatestDeliverySignals(rs *bbrRateSample) {
+	if b.lossRoundStart {
+		b.bwLatest = rs.deliveryRate
+		b.inflightLatest = rs.delivered
+		b.lossEventsInRound = 0
+	}
+}
+
+func (b *bbrSender) updateCongestionSignals(rs *bbrRateSample) {
+	b.updateMaxBw(rs)
+	if !b.lossRoundStart {
+		return
+	}
+	b.adaptLowerBoundsFromCongestion()
+	b.lossInRound = false
+}
+
+func (b *bbrSender) updateMaxBw(rs *bbrRateSample) {
+	b.updateRound(rs)
+	if rs.deliveryRate > 0 && (rs.deliveryRate >= b.maxBw || !rs.isAppLimited) {
+		b.maxBwFilter[1] = max(b.maxBwFilter[1], rs.deliveryRate)
+		b.maxBw = max(b.maxBwFilter[0], b.maxBwFilter[1])
+	}
+}
+
+// advanceMaxBwFilter is called once per bandwidth probing cycle.
+// The max bandwidth is the maximum delivery rate observed in the last two cycles.
+func (b *bbrSender) advanceMaxBwFilter() {
+	b.maxBwFilter[0] = b.maxBwFilter[1]
+	b.maxBwFilter[1] = 0
+}
+
+func (b *bbrSender) isProbingBW() bool {
+	return b.state == bbrStateStartup || b.state == bbrStateProbeBWRefill || b.state == bbrStateProbeBWUp
+}
+
+func (b *bbrSender) adaptLowerBoundsFromCongestion() {
+	if b.isProbingBW() || !b.lossInRound {
+		return
+	}
+	if b.bwLo == infBandwidth {
+		b.bwLo = b.maxBw
+	}
+	if b.inflightLo == protocol.MaxByteCount {
+		b.inflightLo = b.cwnd
+	}
+	b.bwLo = max(b.bwLatest, Bandwidth(bbrBeta*float64(b.bwLo)))
+	b.inflightLo = max(b.inflightLatest, protocol.ByteCount(bbrBeta*float64(b.inflightLo)))
+}
+
+func (b *bbrSender) resetLowerBounds() {
+	b.bwLo = infBandwidth
+	b.inflightLo = protocol.MaxByteCount
+}
+
+func (b *bbrSender) boundBWForModel() {
+	b.bw = min(b.maxBw, b.bwLo)
+}
+
+func (b *bbrSender) enterStartup() {
+	b.state = bbrStateStartup
+	b.pacingGain = bbrStartupPacingGain
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) checkStartupDone(rs *bbrRateSample) {
+	b.checkStartupFullBandwidth(rs)
+	b.checkStartupHighLoss(rs)
+	if b.state == bbrStateStartup && b.filledPipe {
+		b.enterDrain()
+	}
+}
+
+func (b *bbrSender) checkStartupFullBandwidth(rs *bbrRateSample) {
+	if b.filledPipe || !b.roundStart || rs.isAppLimited {
+		return
+	}
+	if float64(b.maxBw) >= float64(b.fullBw)*bbrFullBwThreshold {
+		b.fullBw = b.maxBw
+		b.fullBwCount = 0
+		return
+	}
+	b.fullBwCount++
+	if b.fullBwCount >= bbrFullBwCount {
+		b.filledPipe = true
+	}
+}
+
+func (b *bbrSender) checkStartupHighLoss(rs *bbrRateSample) {
+	if b.filledPipe || b.state != bbrStateStartup || !b.lossRoundStart {
+		return
+	}
+	if b.lossEventsInRound >= bbrStartupFullLossCount && b.isInflightTooHigh(rs) {
+		b.filledPipe = true
+		b.inflightHi = max(b.bdp(b.maxBw, 1), b.inflightLatest)
+	}
+}
+
+func (b *bbrSender) enterDrain() {
+	b.state = bbrStateDrain
+	b.pacingGain = bbrDrainPacingGain
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) checkDrain(now time.Time) {
+	if b.state == bbrStateDrain && b.bytesInFlight <= b.inflight(b.maxBw, 1) {
+		b.enterProbeBW(now)
+	}
+}
+
+func (b *bbrSender) enterProbeBW(now time.Time) {
+	b.cwndGain = bbrDefaultCwndGain
+	b.startProbeBWDown(now)
+}
+
+func (b *bbrSender) startProbeBWDown(now time.Time) {
+	b.resetCongestionSignals()
+	b.bwProbeUpCnt = protocol.MaxByteCount
+	b.pickProbeWait()
+	b.cycleStamp = now
+	b.ackPhase = bbrAcksProbeStopping
+	b.startRound()
+	b.state = bbrStateProbeBWDown
+	b.pacingGain = bbrProbeDownPacingGain
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) startProbeBWCruise() {
+	b.state = bbrStateProbeBWCruise
+	b.pacingGain = 1
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) startProbeBWRefill() {
+	b.resetLowerBounds()
+	b.bwProbeUpRounds = 0
+	b.bwProbeUpAcks = 0
+	b.ackPhase = bbrAcksRefilling
+	b.startRound()
+	b.state = bbrStateProbeBWRefill
+	b.pacingGain = 1
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) startProbeBWUp(now time.Time) {
+	b.ackPhase = bbrAcksProbeStarting
+	b.startRound()
+	b.cycleStamp = now
+	b.state = bbrStateProbeBWUp
+	b.pacingGain = bbrProbeUpPacingGain
+	b.cwndGain = bbrProbeUpCwndGain
+	b.raiseInflightHiSlope()
+}
+
+func (b *bbrSender) resetCongestionSignals() {
+	b.lossInRound = false
+	b.lossEventsInRound = 0
+	b.bwLatest = 0
+	b.inflightLatest = 0
+}
+
+// pickProbeWait randomizes the time until the next bandwidth probe,
+// to desynchronize flows sharing a bottleneck.
+func (b *bbrSender) pickProbeWait() {
+	b.roundsSinceBwProbe = uint64(b.rand.Int31n(2))
+	b.bwProbeWait = 2*time.Second + time.Duration(b.rand.Int31n(1000))*time.Millisecond
+}
+
+func (b *bbrSender) updateProbeBWCyclePhase(rs *bbrRateSample, priorInFlight protocol.ByteCount, now time.Time) {
+	if !b.filledPipe {
+		return
+	}
+	b.adaptUpperBounds(rs, priorInFlight, now)
+	if !b.state.isProbeBW() {
+		return
+	}
+	switch b.state {
+	case bbrStateProbeBWDown:
+		if b.checkTimeToProbeBW(now) {
+			return
+		}
+		if b.checkTimeToCruise() {
+			b.startProbeBWCruise()
+		}
+	case bbrStateProbeBWCruise:
+		b.checkTimeToProbeBW(now)
+	case bbrStateProbeBWRefill:
+		// After one round of REFILL, start UP.
+		if b.roundStart {
+			b.bwProbeSamples = true
+			b.startProbeBWUp(now)
+		}
+	case bbrStateProbeBWUp:
+		if now.Sub(b.cycleStamp) > b.minRTT && b.bytesInFlight > b.inflight(b.maxBw, bbrProbeUpPacingGain) {
+			b.startProbeBWDown(now)
+		}
+	}
+}
+
+func (b *bbrSender) adaptUpperBounds(rs *bbrRateSample, priorInFlight protocol.ByteCount, now time.Time) {
+	if b.ackPhase == bbrAcksProbeStarting && b.roundStart {
+		// starting to get bandwidth probing samples
+		b.ackPhase = bbrAcksProbeFeedback
+	}
+	if b.ackPhase == bbrAcksProbeStopping && b.roundStart {
+		// end of samples from bandwidth probing phase
+		b.bwProbeSamples = false
+		b.ackPhase = bbrAcksInit
+		if b.state.isProbeBW() && !rs.isAppLimited {
+			b.advanceMaxBwFilter()
+		}
+	}
+	if b.isInflightTooHigh(rs) {
+		if b.bwProbeSamples {
+			b.handleInflightTooHigh(rs, now)
+		}
+		return
+	}
+	if b.inflightHi == protocol.MaxByteCount {
+		return
+	}
+	if rs.txInFlight > b.inflightHi {
+		b.inflightHi = rs.txInFlight
+	}
+	if b.state == bbrStateProbeBWUp {
+		b.probeInflightHiUpward(rs, priorInFlight)
+	}
+}
+
+func (b *bbrSender) checkTimeToProbeBW(now time.Time) bool {
+	if now.Sub(b.cycleStamp) > b.bwProbeWait || b.isRenoCoexistenceProbeTime() {
+		b.startProbeBWRefill()
+		return true
+	}
+	return false
+}
+
+// isRenoCoexistenceProbeTime makes sure that BBR probes for bandwidth at least as often
+// as a Reno flow would grow its congestion window to fill the pipe.
+func (b *bbrSender) isRenoCoexistenceProbeTime() bool {
+	renoRounds := min(uint64(b.targetInflight()/b.maxDatagramSize), bbrMaxRenoRounds)
+	return b.roundsSinceBwProbe >= renoRounds
+}
+
+func (b *bbrSender) checkTimeToCruise() bool {
+	if b.bytesInFlight > b.inflightWithHeadroom() {
+		return false // not enough headroom
+	}
+	return b.bytesInFlight <= b.inflight(b.maxBw, 1)
+}
+
+func (b *bbrSender) raiseInflightHiSlope() {
+	growthThisRound := b.maxDatagramSize << b.bwProbeUpRounds
+	b.bwProbeUpRounds = min(b.bwProbeUpRounds+1, 30)
+	b.bwProbeUpCnt = max(b.cwnd/growthThisRound, 1)
+}
+
+// probeInflightHiUpward increases inflight_hi, if the congestion window is fully utilized.
+func (b *bbrSender) probeInflightHiUpward(rs *bbrRateSample, priorInFlight protocol.ByteCount) {
+	if priorInFlight+maxBurstPackets*b.maxDatagramSize < b.cwnd || b.cwnd < b.inflightHi {
+		return // not fully using inflight_hi, so don't grow it
+	}
+	b.bwProbeUpAcks += rs.newlyAcked
+	if b.bwProbeUpAcks >= b.bwProbeUpCnt {
+		delta := b.bwProbeUpAcks / b.bwProbeUpCnt
+		b.bwProbeUpAcks -= delta * b.bwProbeUpCnt
+		b.inflightHi += delta * b.maxDatagramSize
+	}
+	if b.roundStart {
+		b.raiseInflightHiSlope()
+	}
+}
+
+func (b *bbrSender) isInflightTooHigh(rs *bbrRateSample) bool {
+	return float64(rs.lost) > float64(rs.txInFlight)*bbrLossThresh
+}
+
+func (b *bbrSender) handleLostPacket(p bbrPacketState, now time.Time) {
+	if !b.bwProbeSamples {
+		return
+	}
+	rs := bbrRateSample{
+		txInFlight:   p.txInFlight,
+		lost:         b.lost - p.lost,
+		isAppLimited: p.isAppLimited,
+	}
+	if b.isInflightTooHigh(&rs) {
+		rs.txInFlight = b.inflightAtLoss(&rs, p)
+		b.handleInflightTooHigh(&rs, now)
+	}
+}
+
+// inflightAtLoss estimates the bytes in flight at the point where the loss rate crossed bbrLossThresh.
+func (b *bbrSender) inflightAtLoss(rs *bbrRateSample, p bbrPacketState) protocol.ByteCount {
+	inflightPrev := subtractBytes(rs.txInFlight, p.size)
+	lostPrev := float64(rs.lost) - float64(p.size)
+	lostPrefix := (bbrLossThresh*float64(inflightPrev) - lostPrev) / (1 - bbrLossThresh)
+	if lostPrefix < 0 {
+		return inflightPrev
+	}
+	return inflightPrev + protocol.ByteCount(lostPrefix)
+}
+
+func (b *bbrSender) handleInflightTooHigh(rs *bbrRateSample, now time.Time) {
+	b.bwProbeSamples = false
+	if !rs.isAppLimited {
+		b.inflightHi = max(rs.txInFlight, protocol.ByteCount(float64(b.targetInflight())*bbrBeta))
+	}
+	if b.state == bbrStateProbeBWUp {
+		b.startProbeBWDown(now)
+	}
+	b.maybeTraceStateChange(b.state.congestionState())
+}
+
+func (b *bbrSender) updateMinRTT(rs *bbrRateSample, now time.Time) {
+	b.probeRTTExpired = now.Sub(b.probeRTTMinStamp) > bbrProbeRTTInterval
+	if rs.rtt > 0 && (rs.rtt < b.probeRTTMinDelay || b.probeRTTExpired) {
+		b.probeRTTMinDelay = rs.rtt
+		b.probeRTTMinStamp = now
+	}
+	minRTTExpired := now.Sub(b.minRTTStamp) > bbrMinRTTFilterLen
+	if b.probeRTTMinDelay < b.minRTT || minRTTExpired {
+		b.minRTT = b.probeRTTMinDelay
+		b.minRTTStamp = b.probeRTTMinStamp
+	}
+}
+
+func (b *bbrSender) checkProbeRTT(now time.Time) {
+	if b.state != bbrStateProbeRTT && b.probeRTTExpired {
+		b.enterProbeRTT()
+		b.saveCwnd()
+		b.probeRTTDoneStamp = time.Time{}
+		b.ackPhase = bbrAcksProbeStopping
+		b.startRound()
+	}
+	if b.state == bbrStateProbeRTT {
+		b.handleProbeRTT(now)
+	}
+}
+
+func (b *bbrSender) enterProbeRTT() {
+	b.state = bbrStateProbeRTT
+	b.pacingGain = 1
+	b.cwndGain = bbrProbeRTTCwndGain
+}
+
+func (b *bbrSender) handleProbeRTT(now time.Time) {
+	if b.probeRTTDoneStamp.IsZero() {
+		if b.bytesInFlight <= b.probeRTTCwnd() {
+			// Wait for at least bbrProbeRTTDuration and one round trip.
+			b.probeRTTDoneStamp = now.Add(bbrProbeRTTDuration)
+			b.probeRTTRoundDone = false
+			b.startRound()
+		}
+		return
+	}
+	if b.roundStart {
+		b.probeRTTRoundDone = true
+	}
+	if b.probeRTTRoundDone && now.After(b.probeRTTDoneStamp) {
+		b.probeRTTMinStamp = now
+		b.restoreCwnd()
+		b.exitProbeRTT(now)
+	}
+}
+
+func (b *bbrSender) exitProbeRTT(now time.Time) {
+	b.resetLowerBounds()
+	if b.filledPipe {
+		b.startProbeBWDown(now)
+		b.startProbeBWCruise()
+	} else {
+		b.enterStartup()
+	}
+}
+
+func (b *bbrSender) saveCwnd() {
+	if b.state != bbrStateProbeRTT {
+		b.priorCwnd = b.cwnd
+	} else {
+		b.priorCwnd = max(b.priorCwnd, b.cwnd)
+	}
+}
+
+func (b *bbrSender) restoreCwnd() {
+	b.cwnd = max(b.cwnd, b.priorCwnd)
+}
+
+func (b *bbrSender) initPacingRate() {
+	srtt := b.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		srtt = time.Millisecond
+	}
+	b.pacingRate = Bandwidth(bbrStartupPacingGain * float64(BandwidthFromDelta(b.cwnd, srtt)))
+}
+
+func (b *bbrSender) setPacingRate() {
+	if b.bw == 0 {
+		return
+	}
+	rate := Bandwidth(b.pacingGain * float64(b.bw) * (100 - bbrPacingMarginPercent) / 100)
+	if rate > 0 && (b.filledPipe || rate > b.pacingRate) {
+		b.pacingRate = rate
+	}
+}
+
+func (b *bbrSender) setCwnd(rs *bbrRateSample) {
+	maxInflight := b.inflight(b.bw, b.cwndGain)
+	if b.filledPipe {
+		b.cwnd = min(b.cwnd+rs.newlyAcked, maxInflight)
+	} else if b.cwnd < maxInflight || b.delivered < initialCongestionWindow*b.maxDatagramSize {
+		b.cwnd += rs.newlyAcked
+	}
+	b.cwnd = max(b.cwnd, b.minPipeCwnd())
+	if b.state == bbrStateProbeRTT {
+		b.cwnd = min(b.cwnd, b.probeRTTCwnd())
+	}
+	b.boundCwndForModel()
+}
+
+func (b *bbrSender) boundCwndForModel() {
+	limit := protocol.MaxByteCount
+	if b.state.isProbeBW() && b.state != bbrStateProbeBWCruise {
+		limit = b.inflightHi
+	} else if b.state == bbrStateProbeRTT || b.state == bbrStateProbeBWCruise {
+		limit = b.inflightWithHeadroom()
+	}
+	limit = min(limit, b.inflightLo)
+	limit = max(limit, b.minPipeCwnd())
+	b.cwnd = min(b.cwnd, limit)
+}
+
+func (b *bbrSender) minPipeCwnd() protocol.ByteCount {
+	return bbrMinPipeCwndPackets * b.maxDatagramSize
+}
+
+func (b *bbrSender) probeRTTCwnd() protocol.ByteCount {
+	return max(b.bdp(b.bw, bbrProbeRTTCwndGain), b.minPipeCwnd())
+}
+
+// bdp calculates the bandwidth-delay product, multiplied by gain.
+func (b *bbrSender) bdp(bw Bandwidth, gain float64) protocol.ByteCount {
+	if b.minRTT == math.MaxInt64 {
+		// no valid RTT samples yet
+		return initialCongestionWindow * b.maxDatagramSize
+	}
+	return protocol.ByteCount(gain * float64(bw/BytesPerSecond) * b.minRTT.Seconds())
+}
+
+// inflight calculates the amount of data that should be in flight to achieve bw, including an allowance
+// for the data queued in the network stack and for offloading.
+func (b *bbrSender) inflight(bw Bandwidth, gain float64) protocol.ByteCount {
+	inflight := b.bdp(bw, gain) + 3*b.sendQuantum()
+	if b.state == bbrStateProbeBWUp {
+		inflight += 2 * b.maxDatagramSize
+	}
+	return max(inflight, b.minPipeCwnd())
+}
+
+func (b *bbrSender) targetInflight() protocol.ByteCount {
+	return min(b.bdp(b.bw, 1), b.cwnd)
+}
+
+func (b *bbrSender) inflightWithHeadroom() protocol.ByteCount {
+	if b.inflightHi == protocol.MaxByteCount {
+		return protocol.MaxByteCount
+	}
+	headroom := max(b.maxDatagramSize, protocol.ByteCount(bbrHeadroom*float64(b.inflightHi)))
+	return max(subtractBytes(b.inflightHi, headroom), b.minPipeCwnd())
+}
+
+// sendQuantum is the amount of data sent in a single burst:
+// 1ms at the pacing rate, capped at 64 KB, but at least 2 packets.
+func (b *bbrSender) sendQuantum() protocol.ByteCount {
+	quantum := min(protocol.ByteCount(b.pacingRate/BytesPerSecond/1000), 64*1024)
+	return max(quantum, 2*b.maxDatagramSize)
+}
+
+func (b *bbrSender) maybeTraceStateChange(new logging.CongestionState) {
+	if b.tracer == nil || b.tracer.UpdatedCongestionState == nil || new == b.lastState {
+		return
+	}
+	b.tracer.UpdatedCongestionState(new)
+	b.lastState = new
+}
+
+func subtractBytes(a, b protocol.ByteCount) protocol.ByteCount {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
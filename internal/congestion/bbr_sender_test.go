@@ -0,0 +1,200 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type simulatedPacket struct {
+	pn      protocol.PacketNumber
+	size    protocol.ByteCount
+	arrival time.Time // the time when the ACK arrives, or when the loss is detected
+	lost    bool
+}
+
+// simulatedLink is a bottleneck link with a fixed bandwidth, a fixed RTT, and an unlimited buffer.
+type simulatedLink struct {
+	bandwidth Bandwidth
+	rtt       time.Duration
+	// drop every dropEvery-th packet, 0 to not drop any packets
+	dropEvery int
+
+	linkFree      time.Time
+	inFlight      []simulatedPacket
+	bytesInFlight protocol.ByteCount
+	numSent       int
+}
+
+func (l *simulatedLink) send(now time.Time, pn protocol.PacketNumber, size protocol.ByteCount) {
+	start := now
+	if l.linkFree.After(start) {
+		start = l.linkFree
+	}
+	l.linkFree = start.Add(time.Duration(uint64(size) * uint64(BytesPerSecond) * uint64(time.Second) / uint64(l.bandwidth)))
+	l.numSent++
+	l.inFlight = append(l.inFlight, simulatedPacket{
+		pn:      pn,
+		size:    size,
+		arrival: l.linkFree.Add(l.rtt),
+		lost:    l.dropEvery > 0 && l.numSent%l.dropEvery == 0,
+	})
+	l.bytesInFlight += size
+}
+
+// run sends as much as the congestion controller allows, for the given duration.
+func (l *simulatedLink) run(sender *bbrSender, clock *mockClock, pn *protocol.PacketNumber, duration time.Duration) {
+	end := time.Time(*clock).Add(duration)
+	for time.Time(*clock).Before(end) {
+		now := time.Time(*clock)
+		priorInFlight := l.bytesInFlight
+		for len(l.inFlight) > 0 && !l.inFlight[0].arrival.After(now) {
+			p := l.inFlight[0]
+			l.inFlight = l.inFlight[1:]
+			l.bytesInFlight -= p.size
+			if p.lost {
+				sender.OnCongestionEvent(p.pn, p.size, priorInFlight)
+			} else {
+				sender.OnPacketAcked(p.pn, p.size, priorInFlight, now)
+			}
+		}
+		for sender.CanSend(l.bytesInFlight) && sender.HasPacingBudget(now) {
+			l.send(now, *pn, maxDatagramSize)
+			sender.OnPacketSent(now, l.bytesInFlight, *pn, maxDatagramSize, true)
+			*pn++
+		}
+		next := end
+		if len(l.inFlight) > 0 && l.inFlight[0].arrival.Before(next) {
+			next = l.inFlight[0].arrival
+		}
+		if sender.CanSend(l.bytesInFlight) {
+			if t := sender.TimeUntilSend(l.bytesInFlight); t.Before(next) {
+				next = t
+			}
+		}
+		if !next.After(now) {
+			next = now.Add(time.Microsecond)
+		}
+		*clock = mockClock(next)
+	}
+}
+
+var _ = Describe("BBR Sender", func() {
+	const (
+		bandwidth = 10 * 1000 * 1000 * BitsPerSecond // 10 Mbit/s
+		rtt       = 50 * time.Millisecond
+		// the bandwidth-delay product of the simulated link
+		bdp = protocol.ByteCount(bandwidth / BytesPerSecond * Bandwidth(rtt) / Bandwidth(time.Second))
+	)
+
+	var (
+		sender   *bbrSender
+		clock    mockClock
+		rttStats utils.RTTStats
+		pn       protocol.PacketNumber
+		states   []logging.CongestionState
+	)
+
+	BeforeEach(func() {
+		clock = mockClock(time.Now())
+		rttStats = utils.RTTStats{}
+		pn = 1
+		states = nil
+		sender = NewBBRSender(&clock, &rttStats, maxDatagramSize, &logging.ConnectionTracer{
+			UpdatedCongestionState: func(s logging.CongestionState) { states = append(states, s) },
+		})
+	})
+
+	It("starts in Startup", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * maxDatagramSize))
+		Expect(states).To(Equal([]logging.CongestionState{logging.CongestionStateStartup}))
+	})
+
+	It("estimates the bandwidth, and leaves Startup", func() {
+		link := &simulatedLink{bandwidth: bandwidth, rtt: rtt}
+		link.run(sender, &clock, &pn, 2*time.Second)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(states[:3]).To(Equal([]logging.CongestionState{
+			logging.CongestionStateStartup,
+			logging.CongestionStateDrain,
+			logging.CongestionStateProbeBW,
+		}))
+		Expect(sender.maxBw).To(BeNumerically("~", bandwidth, bandwidth/10))
+		Expect(sender.minRTT).To(BeNumerically("~", rtt, rtt/10))
+		// The congestion window is twice the BDP, plus some allowance for bursts.
+		Expect(sender.GetCongestionWindow()).To(And(
+			BeNumerically(">=", bdp),
+			BeNumerically("<", 3*bdp),
+		))
+	})
+
+	It("keeps the bandwidth estimate when packets are lost randomly", func() {
+		const duration = 5 * time.Second
+		// BBR tolerates a loss rate of up to 2% per round trip.
+		// Use a link with a large BDP, such that a single loss doesn't exceed that rate.
+		link := &simulatedLink{bandwidth: 10 * bandwidth, rtt: rtt, dropEvery: 100} // 1% loss
+		link.run(sender, &clock, &pn, duration)
+		Expect(sender.maxBw).To(BeNumerically("~", 10*bandwidth, bandwidth))
+		// the link is still well utilized
+		throughput := BandwidthFromDelta(sender.delivered, duration)
+		Expect(throughput).To(BeNumerically(">", 10*bandwidth*8/10))
+	})
+
+	It("leaves Startup when the loss rate is too high", func() {
+		// Use a link with a large BDP, such that Startup doesn't end before losses are detected.
+		link := &simulatedLink{bandwidth: 10 * bandwidth, rtt: rtt, dropEvery: 10} // 10% loss
+		link.run(sender, &clock, &pn, 4*rtt)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.fullBwCount).To(BeZero())
+		Expect(sender.inflightHi).To(BeNumerically("<", 10*bdp))
+	})
+
+	It("probes the RTT", func() {
+		link := &simulatedLink{bandwidth: bandwidth, rtt: rtt}
+		link.run(sender, &clock, &pn, bbrProbeRTTInterval+time.Second)
+		Expect(states).To(ContainElement(logging.CongestionStateProbeRTT))
+		// After ProbeRTT, BBR returns to ProbeBW.
+		Expect(states[len(states)-1]).To(Equal(logging.CongestionStateProbeBW))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", bdp))
+	})
+
+	It("reduces the congestion window when ECN-CE marks are reported during bandwidth probing", func() {
+		link := &simulatedLink{bandwidth: bandwidth, rtt: rtt}
+		for !sender.bwProbeSamples {
+			link.run(sender, &clock, &pn, rtt)
+		}
+		Expect(sender.state).To(Equal(bbrStateProbeBWUp))
+		sender.OnCongestionEvent(pn-1, 0, link.bytesInFlight)
+		Expect(sender.state).To(Equal(bbrStateProbeBWDown))
+		Expect(sender.inflightHi).To(BeNumerically("<=", link.bytesInFlight))
+	})
+
+	It("removes the state of discarded packets", func() {
+		now := clock.Now()
+		sender.OnPacketSent(now, maxDatagramSize, 1, maxDatagramSize, true)
+		sender.OnPacketSent(now, 2*maxDatagramSize, 2, maxDatagramSize, true)
+		Expect(sender.packets).To(HaveLen(2))
+		sender.OnPacketDiscarded(1)
+		Expect(sender.packets).To(HaveLen(1))
+		Expect(sender.packets).To(HaveKey(protocol.PacketNumber(2)))
+		Expect(sender.bytesInFlight).To(Equal(maxDatagramSize))
+		// discarding a packet that was already acknowledged is a no-op
+		sender.OnPacketAcked(2, maxDatagramSize, maxDatagramSize, now.Add(rtt))
+		sender.OnPacketDiscarded(2)
+		Expect(sender.packets).To(BeEmpty())
+		Expect(sender.bytesInFlight).To(BeZero())
+	})
+
+	It("panics if the max datagram size is decreased", func() {
+		Expect(func() { sender.SetMaxDatagramSize(maxDatagramSize - 1) }).To(Panic())
+		sender.SetMaxDatagramSize(maxDatagramSize + 1)
+		Expect(sender.maxDatagramSize).To(Equal(maxDatagramSize + 1))
+	})
+})
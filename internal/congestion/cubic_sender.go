@@ -62,6 +62,7 @@ type cubicSender struct {
 var (
 	_ SendAlgorithm               = &cubicSender{}
 	_ SendAlgorithmWithDebugInfos = &cubicSender{}
+	_ TracingSendAlgorithm        = &cubicSender{}
 )
 
 // NewCubicSender makes a new cubic sender
@@ -104,15 +105,31 @@ func newCubicSender(
 		cubic:                      NewCubic(clock),
 		clock:                      clock,
 		reno:                       reno,
-		tracer:                     tracer,
 		maxDatagramSize:            initialMaxDatagramSize,
 	}
 	c.pacer = newPacer(c.BandwidthEstimate)
+	c.SetTracer(tracer)
+	return c
+}
+
+// SetTracer sets the tracer, and traces the current state.
+func (c *cubicSender) SetTracer(tracer *logging.ConnectionTracer) {
+	c.tracer = tracer
 	if c.tracer != nil && c.tracer.UpdatedCongestionState != nil {
-		c.lastState = logging.CongestionStateSlowStart
-		c.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
+		c.lastState = c.congestionState()
+		c.tracer.UpdatedCongestionState(c.lastState)
+	}
+}
+
+func (c *cubicSender) congestionState() logging.CongestionState {
+	switch {
+	case c.InRecovery():
+		return logging.CongestionStateRecovery
+	case c.InSlowStart():
+		return logging.CongestionStateSlowStart
+	default:
+		return logging.CongestionStateCongestionAvoidance
 	}
-	return c
 }
 
 // TimeUntilSend returns when the next packet should be sent.
@@ -5,6 +5,7 @@ import (
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -197,6 +198,25 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(sender.hybridSlowStart.Started()).To(BeFalse())
 	})
 
+	It("traces the current state when the tracer is set", func() {
+		for i := 0; i < 10; i++ {
+			SendAvailableSendWindow()
+			AckNPackets(2)
+		}
+		LoseNPackets(1)
+		Expect(sender.InRecovery()).To(BeTrue())
+		var states []logging.CongestionState
+		sender.SetTracer(&logging.ConnectionTracer{
+			UpdatedCongestionState: func(s logging.CongestionState) { states = append(states, s) },
+		})
+		Expect(states).To(Equal([]logging.CongestionState{logging.CongestionStateRecovery}))
+		// the next state change is traced
+		AckNPackets(int(sender.GetCongestionWindow()/maxDatagramSize) + 20)
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		Expect(states).To(ContainElement(logging.CongestionStateCongestionAvoidance))
+	})
+
 	It("slow start packet loss PRR", func() {
 		// Test based on the first example in RFC6937.
 		// Ack 10 packets in 5 acks to raise the CWND to 20, as in the example.
@@ -4,18 +4,30 @@ import (
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
 )
 
 // A SendAlgorithm performs congestion control
 type SendAlgorithm interface {
+	// TimeUntilSend returns when the next packet should be sent.
+	// It is used for pacing, and is only called if HasPacingBudget returned false.
 	TimeUntilSend(bytesInFlight protocol.ByteCount) time.Time
+	// HasPacingBudget says if the pacer allows sending a packet at this moment.
 	HasPacingBudget(now time.Time) bool
+	// OnPacketSent is called for every packet sent, after the bytes in flight were updated.
 	OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool)
+	// CanSend says if the congestion window allows sending more data.
 	CanSend(bytesInFlight protocol.ByteCount) bool
+	// MaybeExitSlowStart is called when an ACK is received, before OnPacketAcked is called for the packets it acknowledges.
 	MaybeExitSlowStart()
+	// OnPacketAcked is called for every newly acknowledged packet.
 	OnPacketAcked(number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time)
+	// OnCongestionEvent is called when a packet is declared lost, and when an ECN-CE mark is reported by the peer.
+	// For ECN-CE marks, lostBytes is 0.
 	OnCongestionEvent(number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount)
+	// OnRetransmissionTimeout is called when the PTO timer fires.
 	OnRetransmissionTimeout(packetsRetransmitted bool)
+	// SetMaxDatagramSize is called when the maximum packet size increases, e.g. due to Path MTU Discovery.
 	SetMaxDatagramSize(protocol.ByteCount)
 }
 
@@ -26,3 +38,16 @@ type SendAlgorithmWithDebugInfos interface {
 	InRecovery() bool
 	GetCongestionWindow() protocol.ByteCount
 }
+
+// A DiscardingSendAlgorithm is a SendAlgorithm that keeps state for the packets in flight.
+// OnPacketDiscarded is called for packets that are neither acknowledged nor declared lost,
+// e.g. when the keys for their packet number space are dropped, or when 0-RTT is rejected.
+type DiscardingSendAlgorithm interface {
+	OnPacketDiscarded(number protocol.PacketNumber)
+}
+
+// A TracingSendAlgorithm is a SendAlgorithm that reports its state to a connection tracer.
+// SetTracer is called right after the congestion controller was created.
+type TracingSendAlgorithm interface {
+	SetTracer(*logging.ConnectionTracer)
+}
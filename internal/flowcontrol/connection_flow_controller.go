@@ -57,6 +57,18 @@ func (c *connectionFlowController) IncrementHighestReceived(increment protocol.B
 	return nil
 }
 
+// ReceiveWindowSize returns the flow control credit granted to the peer,
+// i.e. the number of bytes the peer is currently allowed to send.
+func (c *connectionFlowController) ReceiveWindowSize() protocol.ByteCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.highestReceived > c.receiveWindow {
+		return 0
+	}
+	return c.receiveWindow - c.highestReceived
+}
+
 func (c *connectionFlowController) AddBytesRead(n protocol.ByteCount) {
 	c.mutex.Lock()
 	c.baseFlowController.addBytesRead(n)
@@ -59,6 +59,18 @@ func TestConnectionFlowControlViolation(t *testing.T) {
 	require.Equal(t, qerr.FlowControlError, terr.ErrorCode)
 }
 
+func TestConnectionFlowControlReceiveWindowSize(t *testing.T) {
+	fc := NewConnectionFlowController(100, 100, nil, &utils.RTTStats{}, utils.DefaultLogger)
+	require.Equal(t, protocol.ByteCount(100), fc.ReceiveWindowSize())
+	require.NoError(t, fc.IncrementHighestReceived(40, time.Now()))
+	require.Equal(t, protocol.ByteCount(60), fc.ReceiveWindowSize())
+	// reading data doesn't increase the credit until the window update is sent
+	fc.AddBytesRead(40)
+	require.Equal(t, protocol.ByteCount(60), fc.ReceiveWindowSize())
+	require.Equal(t, protocol.ByteCount(140), fc.GetWindowUpdate(time.Now()))
+	require.Equal(t, protocol.ByteCount(100), fc.ReceiveWindowSize())
+}
+
 // TODO (#4732): add a test for successfully resetting the flow controller
 func TestConnectionFlowControllerReset(t *testing.T) {
 	fc := NewConnectionFlowController(0, 0, nil, &utils.RTTStats{}, utils.DefaultLogger)
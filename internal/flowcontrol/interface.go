@@ -33,6 +33,8 @@ type StreamFlowController interface {
 type ConnectionFlowController interface {
 	flowController
 	AddBytesRead(protocol.ByteCount)
+	// ReceiveWindowSize returns the number of bytes the peer is currently allowed to send.
+	ReceiveWindowSize() protocol.ByteCount
 	Reset() error
 	IsNewlyBlocked() (bool, protocol.ByteCount)
 }
@@ -1,7 +1,9 @@
 package handshake
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -23,7 +25,7 @@ type quicVersionContextKey struct{}
 
 var QUICVersionContextKey = &quicVersionContextKey{}
 
-const clientSessionStateRevision = 4
+const clientSessionStateRevision = 5
 
 type cryptoSetup struct {
 	tlsConf *tls.Config
@@ -31,13 +33,20 @@ type cryptoSetup struct {
 
 	events []Event
 
-	version protocol.Version
+	version         protocol.Version
+	originalVersion protocol.Version // the version used for the first Initial packet
+	initialConnID   protocol.ConnectionID
 
 	ourParams  *wire.TransportParameters
 	peerParams *wire.TransportParameters
 
 	zeroRTTParameters *wire.TransportParameters
 	allow0RTT         bool
+	zeroRTTPolicy     *ZeroRTTPolicy // only set for the server
+
+	sessionTicketData *SessionTicketDataHandler
+	// the application data stored with the session ticket offered by the client, only used for the server
+	resumedSessionTicketData []byte
 
 	rttStats *utils.RTTStats
 
@@ -53,6 +62,10 @@ type cryptoSetup struct {
 
 	initialOpener LongHeaderOpener
 	initialSealer LongHeaderSealer
+	// Only set for the server, after switching to a compatible version.
+	// The client keeps sending Initial packets using the original version
+	// until it receives the first packet of the negotiated version.
+	originalInitialOpener LongHeaderOpener
 
 	handshakeOpener LongHeaderOpener
 	handshakeSealer LongHeaderSealer
@@ -72,6 +85,7 @@ func NewCryptoSetupClient(
 	tp *wire.TransportParameters,
 	tlsConf *tls.Config,
 	enable0RTT bool,
+	sessionTicketData *SessionTicketDataHandler,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
@@ -93,6 +107,7 @@ func NewCryptoSetupClient(
 	qtls.SetupConfigForClient(quicConf, cs.marshalDataForSessionState, cs.handleDataFromSessionState)
 	cs.tlsConf = tlsConf
 	cs.allow0RTT = enable0RTT
+	cs.sessionTicketData = sessionTicketData
 
 	cs.conn = tls.QUICClient(quicConf)
 	cs.conn.SetTransportParameters(cs.ourParams.Marshal(protocol.PerspectiveClient))
@@ -107,6 +122,8 @@ func NewCryptoSetupServer(
 	tp *wire.TransportParameters,
 	tlsConf *tls.Config,
 	allow0RTT bool,
+	zeroRTTPolicy *ZeroRTTPolicy,
+	sessionTicketData *SessionTicketDataHandler,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
@@ -122,6 +139,8 @@ func NewCryptoSetupServer(
 		version,
 	)
 	cs.allow0RTT = allow0RTT
+	cs.zeroRTTPolicy = zeroRTTPolicy
+	cs.sessionTicketData = sessionTicketData
 
 	tlsConf = qtls.SetupConfigForServer(tlsConf, localAddr, remoteAddr, cs.getDataForSessionTicket, cs.handleSessionTicket)
 	cs.tlsConf = tlsConf
@@ -144,21 +163,36 @@ func newCryptoSetup(
 		tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveServer)
 	}
 	return &cryptoSetup{
-		initialSealer: initialSealer,
-		initialOpener: initialOpener,
-		aead:          newUpdatableAEAD(rttStats, tracer, logger, version),
-		events:        make([]Event, 0, 16),
-		ourParams:     tp,
-		rttStats:      rttStats,
-		tracer:        tracer,
-		logger:        logger,
-		perspective:   perspective,
-		version:       version,
+		initialSealer:   initialSealer,
+		initialOpener:   initialOpener,
+		aead:            newUpdatableAEAD(rttStats, tracer, logger, version),
+		events:          make([]Event, 0, 16),
+		ourParams:       tp,
+		rttStats:        rttStats,
+		tracer:          tracer,
+		logger:          logger,
+		perspective:     perspective,
+		version:         version,
+		originalVersion: version,
+		initialConnID:   connID,
 	}
 }
 
 func (h *cryptoSetup) ChangeConnectionID(id protocol.ConnectionID) {
-	initialSealer, initialOpener := NewInitialAEAD(id, h.perspective, h.version)
+	h.initialConnID = id
+	h.updateInitialKeys()
+}
+
+// ChangeVersion switches to a compatible version (RFC 9368).
+// It must be called before the Handshake keys are derived.
+func (h *cryptoSetup) ChangeVersion(v protocol.Version) {
+	h.version = v
+	h.aead.version = v
+	h.updateInitialKeys()
+}
+
+func (h *cryptoSetup) updateInitialKeys() {
+	initialSealer, initialOpener := NewInitialAEAD(h.initialConnID, h.perspective, h.version)
 	h.initialSealer = initialSealer
 	h.initialOpener = initialOpener
 	if h.tracer != nil && h.tracer.UpdatedKeyFromTLS != nil {
@@ -278,15 +312,53 @@ func (h *cryptoSetup) handleTransportParameters(data []byte) error {
 		return err
 	}
 	h.peerParams = &tp
+	if h.perspective == protocol.PerspectiveServer {
+		if err := h.negotiateVersion(tp.VersionInformation); err != nil {
+			return err
+		}
+	}
 	h.events = append(h.events, Event{Kind: EventReceivedTransportParameters, TransportParameters: h.peerParams})
 	return nil
 }
 
+// negotiateVersion performs compatible version negotiation (RFC 9368) on the server side.
+// The server picks the first version from its list of available versions that is
+// compatible with the original version and that the client offered.
+func (h *cryptoSetup) negotiateVersion(vi *wire.VersionInformation) error {
+	if vi == nil || h.ourParams.VersionInformation == nil {
+		return nil
+	}
+	if vi.ChosenVersion != h.originalVersion {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.VersionNegotiationErrorCode,
+			ErrorMessage: fmt.Sprintf("client's chosen version (%s) doesn't match the original version (%s)", vi.ChosenVersion, h.originalVersion),
+		}
+	}
+	for _, v := range h.ourParams.VersionInformation.AvailableVersions {
+		if !protocol.AreCompatibleVersions(h.originalVersion, v) || !protocol.IsSupportedVersion(vi.AvailableVersions, v) {
+			continue
+		}
+		if v != h.version {
+			h.logger.Debugf("Switching to compatible version %s.", v)
+			originalInitialOpener := h.initialOpener
+			h.ChangeVersion(v)
+			h.originalInitialOpener = originalInitialOpener
+			h.ourParams.VersionInformation.ChosenVersion = v
+			h.events = append(h.events, Event{Kind: EventNegotiatedVersion, Version: v})
+		}
+		break
+	}
+	return nil
+}
+
 // must be called after receiving the transport parameters
 func (h *cryptoSetup) marshalDataForSessionState(earlyData bool) []byte {
 	b := make([]byte, 0, 256)
 	b = quicvarint.Append(b, clientSessionStateRevision)
 	b = quicvarint.Append(b, uint64(h.rttStats.SmoothedRTT().Microseconds()))
+	appData := h.getSessionTicketData()
+	b = quicvarint.Append(b, uint64(len(appData)))
+	b = append(b, appData...)
 	if earlyData {
 		// only save the transport parameters for 0-RTT enabled session tickets
 		return h.peerParams.MarshalForSessionTicket(b)
@@ -295,51 +367,82 @@ func (h *cryptoSetup) marshalDataForSessionState(earlyData bool) []byte {
 }
 
 func (h *cryptoSetup) handleDataFromSessionState(data []byte, earlyData bool) (allowEarlyData bool) {
-	rtt, tp, err := decodeDataFromSessionState(data, earlyData)
+	rtt, appData, tp, err := decodeDataFromSessionState(data, earlyData)
 	if err != nil {
 		h.logger.Debugf("Restoring of transport parameters from session ticket failed: %s", err.Error())
 		return
 	}
 	h.rttStats.SetInitialRTT(rtt)
+	if appData != nil {
+		h.events = append(h.events, Event{Kind: EventSessionTicketData, Data: appData})
+	}
 	// The session ticket might have been saved from a connection that allowed 0-RTT,
 	// and therefore contain transport parameters.
 	// Only use them if 0-RTT is actually used on the new connection.
 	if tp != nil && h.allow0RTT {
+		if h.sessionTicketData != nil && h.sessionTicketData.Validate != nil && !h.sessionTicketData.Validate(appData) {
+			h.logger.Debugf("Application data in session ticket not valid. Not using 0-RTT.")
+			return false
+		}
 		h.zeroRTTParameters = tp
 		return true
 	}
 	return false
 }
 
-func decodeDataFromSessionState(b []byte, earlyData bool) (time.Duration, *wire.TransportParameters, error) {
+func decodeDataFromSessionState(b []byte, earlyData bool) (time.Duration, []byte, *wire.TransportParameters, error) {
 	ver, l, err := quicvarint.Parse(b)
 	if err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
 	}
 	b = b[l:]
 	if ver != clientSessionStateRevision {
-		return 0, nil, fmt.Errorf("mismatching version. Got %d, expected %d", ver, clientSessionStateRevision)
+		return 0, nil, nil, fmt.Errorf("mismatching version. Got %d, expected %d", ver, clientSessionStateRevision)
 	}
 	rttEncoded, l, err := quicvarint.Parse(b)
 	if err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
 	}
 	b = b[l:]
 	rtt := time.Duration(rttEncoded) * time.Microsecond
+	appDataLen, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	b = b[l:]
+	if uint64(len(b)) < appDataLen {
+		return 0, nil, nil, errors.New("application data too short")
+	}
+	var appData []byte
+	if appDataLen > 0 {
+		// the session state might be shared between multiple connections
+		appData = bytes.Clone(b[:appDataLen])
+	}
+	b = b[appDataLen:]
 	if !earlyData {
-		return rtt, nil, nil
+		return rtt, appData, nil, nil
 	}
 	var tp wire.TransportParameters
 	if err := tp.UnmarshalFromSessionTicket(b); err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
+	}
+	return rtt, appData, &tp, nil
+}
+
+func (h *cryptoSetup) getSessionTicketData() []byte {
+	if h.sessionTicketData == nil || h.sessionTicketData.Get == nil {
+		return nil
 	}
-	return rtt, &tp, nil
+	return h.sessionTicketData.Get()
 }
 
 func (h *cryptoSetup) getDataForSessionTicket() []byte {
 	ticket := &sessionTicket{
-		RTT: h.rttStats.SmoothedRTT(),
+		RTT:      h.rttStats.SmoothedRTT(),
+		IssuedAt: time.Now(),
+		AppData:  h.getSessionTicketData(),
 	}
+	rand.Read(ticket.ID[:])
 	if h.allow0RTT {
 		ticket.Parameters = h.ourParams
 	}
@@ -376,29 +479,68 @@ func (h *cryptoSetup) GetSessionTicket() ([]byte, error) {
 // It reads parameters from the session ticket and checks whether to accept 0-RTT if the session ticket enabled 0-RTT.
 // Note that the fact that the session ticket allows 0-RTT doesn't mean that the actual TLS handshake enables 0-RTT:
 // A client may use a 0-RTT enabled session to resume a TLS session without using 0-RTT.
-func (h *cryptoSetup) handleSessionTicket(sessionTicketData []byte, using0RTT bool) bool {
+func (h *cryptoSetup) handleSessionTicket(sessionTicketData []byte, using0RTT bool, alpn string) bool {
 	var t sessionTicket
 	if err := t.Unmarshal(sessionTicketData, using0RTT); err != nil {
 		h.logger.Debugf("Unmarshalling session ticket failed: %s", err.Error())
 		return false
 	}
 	h.rttStats.SetInitialRTT(t.RTT)
+	h.resumedSessionTicketData = t.AppData
 	if !using0RTT {
 		return false
 	}
 	valid := h.ourParams.ValidFor0RTT(t.Parameters)
 	if !valid {
 		h.logger.Debugf("Transport parameters changed. Rejecting 0-RTT.")
+		h.rejected0RTTAttempt(logging.ZeroRTTRejectedTransportParameters)
 		return false
 	}
 	if !h.allow0RTT {
 		h.logger.Debugf("0-RTT not allowed. Rejecting 0-RTT.")
+		h.rejected0RTTAttempt(logging.ZeroRTTRejectedDisabled)
 		return false
 	}
+	if h.sessionTicketData != nil && h.sessionTicketData.Validate != nil && !h.sessionTicketData.Validate(t.AppData) {
+		h.logger.Debugf("Application data in session ticket not valid. Rejecting 0-RTT.")
+		h.rejected0RTTAttempt(logging.ZeroRTTRejectedByApplication)
+		return false
+	}
+	if h.zeroRTTPolicy != nil {
+		if h.zeroRTTPolicy.Accept != nil && !h.zeroRTTPolicy.Accept(time.Since(t.IssuedAt), alpn, t.AppData) {
+			h.logger.Debugf("0-RTT rejected by the application.")
+			h.rejected0RTTAttempt(logging.ZeroRTTRejectedByApplication)
+			return false
+		}
+		// Only use up the session ticket once we know that 0-RTT will be accepted.
+		if h.zeroRTTPolicy.UseTicket != nil && !h.zeroRTTPolicy.UseTicket(t.ID[:], t.IssuedAt.Add(sessionTicketLifetime)) {
+			h.logger.Debugf("Session ticket already used for 0-RTT. Rejecting 0-RTT.")
+			h.rejected0RTTAttempt(logging.ZeroRTTRejectedReplay)
+			return false
+		}
+	}
 	h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
 	return true
 }
 
+// maybeSendSessionTicketData is called for the server when the ServerHello was sent.
+// The client might offer a session ticket that the server decides not to use.
+// At this point, crypto/tls has decided if the session is resumed,
+// so the application data is only made available if it was.
+// This happens before any 0-RTT data is processed.
+func (h *cryptoSetup) maybeSendSessionTicketData() {
+	if h.perspective == protocol.PerspectiveServer && h.resumedSessionTicketData != nil && h.conn.ConnectionState().DidResume {
+		h.events = append(h.events, Event{Kind: EventSessionTicketData, Data: h.resumedSessionTicketData})
+	}
+}
+
+// rejected0RTTAttempt is called for the server when it rejects 0-RTT.
+func (h *cryptoSetup) rejected0RTTAttempt(reason logging.ZeroRTTRejectReason) {
+	if h.tracer != nil && h.tracer.Rejected0RTT != nil {
+		h.tracer.Rejected0RTT(reason)
+	}
+}
+
 // rejected0RTT is called for the client when the server rejects 0-RTT.
 func (h *cryptoSetup) rejected0RTT() {
 	h.logger.Debugf("0-RTT was rejected. Dropping 0-RTT keys.")
@@ -419,9 +561,10 @@ func (h *cryptoSetup) setReadKey(el tls.QUICEncryptionLevel, suiteID uint16, tra
 		if h.perspective == protocol.PerspectiveClient {
 			panic("Received 0-RTT read key for the client")
 		}
+		// 0-RTT packets are always sent using the original version (RFC 9369, section 5).
 		h.zeroRTTOpener = newLongHeaderOpener(
-			createAEAD(suite, trafficSecret, h.version),
-			newHeaderProtector(suite, trafficSecret, true, h.version),
+			createAEAD(suite, trafficSecret, h.originalVersion),
+			newHeaderProtector(suite, trafficSecret, true, h.originalVersion),
 		)
 		h.used0RTT.Store(true)
 		if h.logger.Debug() {
@@ -459,8 +602,8 @@ func (h *cryptoSetup) setWriteKey(el tls.QUICEncryptionLevel, suiteID uint16, tr
 			panic("Received 0-RTT write key for the server")
 		}
 		h.zeroRTTSealer = newLongHeaderSealer(
-			createAEAD(suite, trafficSecret, h.version),
-			newHeaderProtector(suite, trafficSecret, true, h.version),
+			createAEAD(suite, trafficSecret, h.originalVersion),
+			newHeaderProtector(suite, trafficSecret, true, h.originalVersion),
 		)
 		if h.logger.Debug() {
 			h.logger.Debugf("Installed 0-RTT Write keys (using %s)", tls.CipherSuiteName(suite.ID))
@@ -471,6 +614,7 @@ func (h *cryptoSetup) setWriteKey(el tls.QUICEncryptionLevel, suiteID uint16, tr
 		// don't set used0RTT here. 0-RTT might still get rejected.
 		return
 	case tls.QUICEncryptionLevelHandshake:
+		h.maybeSendSessionTicketData()
 		h.handshakeSealer = newLongHeaderSealer(
 			createAEAD(suite, trafficSecret, h.version),
 			newHeaderProtector(suite, trafficSecret, true, h.version),
@@ -520,6 +664,7 @@ func (h *cryptoSetup) DiscardInitialKeys() {
 	dropped := h.initialOpener != nil
 	h.initialOpener = nil
 	h.initialSealer = nil
+	h.originalInitialOpener = nil
 	if dropped {
 		h.logger.Debugf("Dropping Initial keys.")
 	}
@@ -575,10 +720,14 @@ func (h *cryptoSetup) Get1RTTSealer() (ShortHeaderSealer, error) {
 	return h.aead, nil
 }
 
-func (h *cryptoSetup) GetInitialOpener() (LongHeaderOpener, error) {
+// GetInitialOpener returns the opener for Initial packets sent using the given version.
+func (h *cryptoSetup) GetInitialOpener(v protocol.Version) (LongHeaderOpener, error) {
 	if h.initialOpener == nil {
 		return nil, ErrKeysDropped
 	}
+	if v != h.version && v == h.originalVersion && h.originalInitialOpener != nil {
+		return h.originalInitialOpener, nil
+	}
 	return h.initialOpener, nil
 }
 
@@ -627,6 +776,9 @@ func (h *cryptoSetup) ConnectionState() ConnectionState {
 }
 
 func wrapError(err error) error {
+	if transportErr := (&qerr.TransportError{}); errors.As(err, &transportErr) {
+		return transportErr
+	}
 	if alertErr := tls.AlertError(0); errors.As(err, &alertErr) {
 		return qerr.NewLocalCryptoError(uint8(alertErr), err)
 	}
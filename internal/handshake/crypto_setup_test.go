@@ -18,6 +18,7 @@ import (
 	"github.com/quic-go/quic-go/internal/testdata"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 
 	"github.com/stretchr/testify/require"
 )
@@ -70,6 +71,7 @@ func TestErrorBeforeClientHelloGeneration(t *testing.T) {
 		&wire.TransportParameters{},
 		tlsConf,
 		false,
+		nil,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -92,6 +94,8 @@ func TestMessageReceivedAtWrongEncryptionLevel(t *testing.T) {
 		&wire.TransportParameters{StatelessResetToken: &token},
 		testdata.GetTLSConfig(),
 		false,
+		nil,
+		nil,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -191,6 +195,27 @@ func handshakeWithTLSConf(
 	enable0RTT bool,
 ) (CryptoSetup /* client */, []Event /* more client events */, error, /* client error */
 	CryptoSetup /* server */, []Event /* more server events */, error, /* server error */
+) {
+	t.Helper()
+	return handshakeWithCallbacks(t, clientConf, serverConf, clientRTTStats, serverRTTStats, clientTransportParameters, serverTransportParameters, enable0RTT, handshakeCallbacks{})
+}
+
+type handshakeCallbacks struct {
+	zeroRTTPolicy           *ZeroRTTPolicy
+	clientSessionTicketData *SessionTicketDataHandler
+	serverSessionTicketData *SessionTicketDataHandler
+	serverTracer            *logging.ConnectionTracer
+}
+
+func handshakeWithCallbacks(
+	t *testing.T,
+	clientConf, serverConf *tls.Config,
+	clientRTTStats, serverRTTStats *utils.RTTStats,
+	clientTransportParameters, serverTransportParameters *wire.TransportParameters,
+	enable0RTT bool,
+	callbacks handshakeCallbacks,
+) (CryptoSetup /* client */, []Event /* more client events */, error, /* client error */
+	CryptoSetup /* server */, []Event /* more server events */, error, /* server error */
 ) {
 	t.Helper()
 	client := NewCryptoSetupClient(
@@ -198,6 +223,7 @@ func handshakeWithTLSConf(
 		clientTransportParameters,
 		clientConf,
 		enable0RTT,
+		callbacks.clientSessionTicketData,
 		clientRTTStats,
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -215,8 +241,10 @@ func handshakeWithTLSConf(
 		serverTransportParameters,
 		serverConf,
 		enable0RTT,
+		callbacks.zeroRTTPolicy,
+		callbacks.serverSessionTicketData,
 		serverRTTStats,
-		nil,
+		callbacks.serverTracer,
 		utils.DefaultLogger.WithPrefix("server"),
 		protocol.Version1,
 	)
@@ -291,6 +319,7 @@ func TestTransportParameters(t *testing.T) {
 		cTransportParameters,
 		clientConf,
 		false,
+		nil,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -310,6 +339,8 @@ func TestTransportParameters(t *testing.T) {
 		sTransportParameters,
 		serverConf,
 		false,
+		nil,
+		nil,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -591,3 +622,332 @@ func Test0RTTRejectionOnTransportParametersChanged(t *testing.T) {
 	require.False(t, server.ConnectionState().Used0RTT)
 	require.False(t, client.ConnectionState().Used0RTT)
 }
+
+func establish0RTTSession(t *testing.T) (clientConf, serverConf *tls.Config) {
+	t.Helper()
+	clientConf, serverConf = getTLSConfigs()
+	csc := newMockClientSessionCache()
+	clientConf.ClientSessionCache = csc
+	_, _, clientErr, _, _, serverErr := handshakeWithTLSConf(
+		t,
+		clientConf, serverConf,
+		&utils.RTTStats{}, &utils.RTTStats{},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		true,
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	select {
+	case <-csc.puts:
+	case <-time.After(time.Second):
+		t.Fatal("didn't receive a session ticket")
+	}
+	return clientConf, serverConf
+}
+
+func Test0RTTPolicyAccept(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		test0RTTPolicyAccept(t, true)
+	})
+	t.Run("rejected", func(t *testing.T) {
+		test0RTTPolicyAccept(t, false)
+	})
+}
+
+func test0RTTPolicyAccept(t *testing.T, accept bool) {
+	clientConf, serverConf := establish0RTTSession(t)
+
+	var ticketAge time.Duration
+	var alpn string
+	var appData []byte
+	var rejectReasons []logging.ZeroRTTRejectReason
+	client, _, clientErr, server, _, serverErr := handshakeWithCallbacks(
+		t,
+		clientConf, serverConf,
+		&utils.RTTStats{}, &utils.RTTStats{},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		true,
+		handshakeCallbacks{
+			zeroRTTPolicy: &ZeroRTTPolicy{
+				Accept: func(age time.Duration, p string, data []byte) bool {
+					ticketAge = age
+					alpn = p
+					appData = data
+					return accept
+				},
+			},
+			serverTracer: &logging.ConnectionTracer{
+				Rejected0RTT: func(reason logging.ZeroRTTRejectReason) { rejectReasons = append(rejectReasons, reason) },
+			},
+		},
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	require.Equal(t, "crypto-setup", alpn)
+	require.Nil(t, appData)
+	require.Positive(t, ticketAge)
+	require.Less(t, ticketAge, time.Second)
+	require.True(t, server.ConnectionState().DidResume)
+	require.Equal(t, accept, server.ConnectionState().Used0RTT)
+	require.Equal(t, accept, client.ConnectionState().Used0RTT)
+	if accept {
+		require.Empty(t, rejectReasons)
+	} else {
+		require.Equal(t, []logging.ZeroRTTRejectReason{logging.ZeroRTTRejectedByApplication}, rejectReasons)
+	}
+}
+
+// replayingClientSessionCache always returns the first session ticket it received
+type replayingClientSessionCache struct {
+	state *tls.ClientSessionState
+}
+
+func (c *replayingClientSessionCache) Get(string) (*tls.ClientSessionState, bool) {
+	return c.state, c.state != nil
+}
+
+func (c *replayingClientSessionCache) Put(_ string, cs *tls.ClientSessionState) {
+	if c.state == nil {
+		c.state = cs
+	}
+}
+
+func Test0RTTPolicyAntiReplay(t *testing.T) {
+	clientConf, serverConf := getTLSConfigs()
+	clientConf.ClientSessionCache = &replayingClientSessionCache{}
+
+	used := make(map[string]time.Time)
+	var rejectReasons []logging.ZeroRTTRejectReason
+	policy := &ZeroRTTPolicy{
+		UseTicket: func(ticketID []byte, expiry time.Time) bool {
+			if _, ok := used[string(ticketID)]; ok {
+				return false
+			}
+			used[string(ticketID)] = expiry
+			return true
+		},
+	}
+	tracer := &logging.ConnectionTracer{
+		Rejected0RTT: func(reason logging.ZeroRTTRejectReason) { rejectReasons = append(rejectReasons, reason) },
+	}
+
+	// The first handshake obtains a session ticket.
+	// The following handshakes all use this session ticket, and therefore replay the ClientHello.
+	for i := range 3 {
+		client, _, clientErr, server, _, serverErr := handshakeWithCallbacks(
+			t,
+			clientConf, serverConf,
+			&utils.RTTStats{}, &utils.RTTStats{},
+			&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+			&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+			true,
+			handshakeCallbacks{zeroRTTPolicy: policy, serverTracer: tracer},
+		)
+		require.NoError(t, clientErr)
+		require.NoError(t, serverErr)
+		require.Equal(t, i > 0, server.ConnectionState().DidResume)
+		require.Equal(t, i == 1, server.ConnectionState().Used0RTT)
+		require.Equal(t, i == 1, client.ConnectionState().Used0RTT)
+	}
+	require.Len(t, used, 1)
+	for _, expiry := range used {
+		require.WithinDuration(t, time.Now().Add(sessionTicketLifetime), expiry, time.Second)
+	}
+	require.Equal(t, []logging.ZeroRTTRejectReason{logging.ZeroRTTRejectedReplay}, rejectReasons)
+}
+
+func TestSessionTicketData(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		testSessionTicketData(t, true, true)
+	})
+	t.Run("rejected by the server", func(t *testing.T) {
+		testSessionTicketData(t, false, true)
+	})
+	t.Run("rejected by the client", func(t *testing.T) {
+		testSessionTicketData(t, true, false)
+	})
+}
+
+func testSessionTicketData(t *testing.T, serverValid, clientValid bool) {
+	clientConf, serverConf := getTLSConfigs()
+	csc := newMockClientSessionCache()
+	clientConf.ClientSessionCache = csc
+
+	var clientValidated, serverValidated []byte
+	callbacks := handshakeCallbacks{
+		clientSessionTicketData: &SessionTicketDataHandler{
+			Get:      func() []byte { return []byte("client data") },
+			Validate: func(b []byte) bool { clientValidated = b; return clientValid },
+		},
+		serverSessionTicketData: &SessionTicketDataHandler{
+			Get:      func() []byte { return []byte("server data") },
+			Validate: func(b []byte) bool { serverValidated = b; return serverValid },
+		},
+	}
+	// sessionTicketData returns the data of the EventSessionTicketData event, if any
+	sessionTicketData := func(events []Event) []byte {
+		var data []byte
+		for _, ev := range events {
+			if ev.Kind == EventSessionTicketData {
+				require.Nil(t, data, "more than one EventSessionTicketData")
+				data = ev.Data
+			}
+		}
+		return data
+	}
+
+	_, clientEvents, clientErr, _, serverEvents, serverErr := handshakeWithCallbacks(
+		t,
+		clientConf, serverConf,
+		&utils.RTTStats{}, &utils.RTTStats{},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		true,
+		callbacks,
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	select {
+	case <-csc.puts:
+	case <-time.After(time.Second):
+		t.Fatal("didn't receive a session ticket")
+	}
+	require.Nil(t, sessionTicketData(clientEvents))
+	require.Nil(t, sessionTicketData(serverEvents))
+	require.Nil(t, clientValidated)
+	require.Nil(t, serverValidated)
+
+	client, clientEvents, clientErr, server, serverEvents, serverErr := handshakeWithCallbacks(
+		t,
+		clientConf, serverConf,
+		&utils.RTTStats{}, &utils.RTTStats{},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		true,
+		callbacks,
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	require.True(t, server.ConnectionState().DidResume)
+	require.Equal(t, []byte("client data"), sessionTicketData(clientEvents))
+	require.Equal(t, []byte("server data"), sessionTicketData(serverEvents))
+	require.Equal(t, []byte("client data"), clientValidated)
+	require.Equal(t, []byte("server data"), serverValidated)
+	require.Equal(t, serverValid && clientValid, server.ConnectionState().Used0RTT)
+	require.Equal(t, serverValid && clientValid, client.ConnectionState().Used0RTT)
+}
+
+func TestSessionTicketDataBeforeHandshakeCompletion(t *testing.T) {
+	clientConf, serverConf := getTLSConfigs()
+	csc := newMockClientSessionCache()
+	clientConf.ClientSessionCache = csc
+	serverSessionTicketData := &SessionTicketDataHandler{Get: func() []byte { return []byte("server data") }}
+
+	_, _, clientErr, _, _, serverErr := handshakeWithCallbacks(
+		t,
+		clientConf, serverConf,
+		&utils.RTTStats{}, &utils.RTTStats{},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		true,
+		handshakeCallbacks{serverSessionTicketData: serverSessionTicketData},
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	select {
+	case <-csc.puts:
+	case <-time.After(time.Second):
+		t.Fatal("didn't receive a session ticket")
+	}
+
+	client := NewCryptoSetupClient(
+		protocol.ConnectionID{},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		clientConf,
+		true,
+		nil,
+		&utils.RTTStats{},
+		nil,
+		utils.DefaultLogger.WithPrefix("client"),
+		protocol.Version1,
+	)
+	var token protocol.StatelessResetToken
+	server := NewCryptoSetupServer(
+		protocol.ConnectionID{},
+		&net.UDPAddr{IP: net.IPv6loopback, Port: 1234},
+		&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2, StatelessResetToken: &token},
+		serverConf,
+		true,
+		nil,
+		serverSessionTicketData,
+		&utils.RTTStats{},
+		nil,
+		utils.DefaultLogger.WithPrefix("server"),
+		protocol.Version1,
+	)
+	require.NoError(t, client.StartHandshake(context.Background()))
+	require.NoError(t, server.StartHandshake(context.Background()))
+
+	var clientHello []byte
+	for ev := client.NextEvent(); ev.Kind != EventNoEvent; ev = client.NextEvent() {
+		if ev.Kind == EventWriteInitialData {
+			clientHello = ev.Data
+		}
+	}
+	require.NotNil(t, clientHello)
+	// the data is available as soon as the server processed the ClientHello,
+	// before the 0-RTT keys are used and before the handshake completes
+	require.NoError(t, server.HandleMessage(clientHello, protocol.EncryptionInitial))
+	var kinds []EventKind
+	var data []byte
+	for ev := server.NextEvent(); ev.Kind != EventNoEvent; ev = server.NextEvent() {
+		kinds = append(kinds, ev.Kind)
+		if ev.Kind == EventSessionTicketData {
+			data = ev.Data
+		}
+	}
+	require.Equal(t, []byte("server data"), data)
+	require.Contains(t, kinds, EventReceivedReadKeys)
+	require.NotContains(t, kinds, EventHandshakeComplete)
+}
+
+func TestCompatibleVersionNegotiationInitialKeys(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	server := newCryptoSetup(
+		connID,
+		&wire.TransportParameters{
+			VersionInformation: &wire.VersionInformation{
+				ChosenVersion:     protocol.Version1,
+				AvailableVersions: []protocol.Version{protocol.Version2, protocol.Version1},
+			},
+		},
+		&utils.RTTStats{},
+		nil,
+		utils.DefaultLogger.WithPrefix("server"),
+		protocol.PerspectiveServer,
+		protocol.Version1,
+	)
+	require.NoError(t, server.negotiateVersion(&wire.VersionInformation{
+		ChosenVersion:     protocol.Version1,
+		AvailableVersions: []protocol.Version{protocol.Version1, protocol.Version2},
+	}))
+	require.Equal(t, protocol.Version2, server.version)
+
+	// the server can open Initial packets sent using the original and the negotiated version
+	for _, v := range []protocol.Version{protocol.Version1, protocol.Version2} {
+		clientSealer, _ := NewInitialAEAD(connID, protocol.PerspectiveClient, v)
+		opener, err := server.GetInitialOpener(v)
+		require.NoError(t, err)
+		msg := clientSealer.Seal(nil, []byte("foobar"), 42, []byte("aad"))
+		decrypted, err := opener.Open(nil, msg, 42, []byte("aad"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("foobar"), decrypted)
+	}
+
+	server.DiscardInitialKeys()
+	_, err := server.GetInitialOpener(protocol.Version1)
+	require.ErrorIs(t, err, ErrKeysDropped)
+}
@@ -39,6 +39,8 @@ type ShortHeaderOpener interface {
 	headerDecryptor
 	DecodePacketNumber(wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber
 	Open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
+	// OpenPath opens a packet received on a path, when using the multipath extension.
+	OpenPath(dst, src []byte, rcvTime time.Time, pathID protocol.PathID, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
 }
 
 // LongHeaderSealer seals a long header packet
@@ -52,6 +54,8 @@ type LongHeaderSealer interface {
 type ShortHeaderSealer interface {
 	LongHeaderSealer
 	KeyPhase() protocol.KeyPhaseBit
+	// SealPath seals a packet sent on a path, when using the multipath extension.
+	SealPath(dst, src []byte, pathID protocol.PathID, packetNumber protocol.PacketNumber, associatedData []byte) []byte
 }
 
 type ConnectionState struct {
@@ -59,6 +63,29 @@ type ConnectionState struct {
 	Used0RTT bool
 }
 
+// ZeroRTTPolicy is used by the server to decide if a 0-RTT connection attempt is accepted.
+// It is only consulted if 0-RTT is allowed, and if the transport parameters stored in the
+// session ticket are compatible with the current transport parameters.
+type ZeroRTTPolicy struct {
+	// Accept decides if 0-RTT is accepted.
+	// appData is the application data stored with the session ticket. It may be nil.
+	Accept func(ticketAge time.Duration, alpn string, appData []byte) bool
+	// UseTicket marks a session ticket as used for 0-RTT.
+	// It returns false if the session ticket was used before.
+	// The ticket ID needs to be remembered until the expiry time. It may be nil.
+	UseTicket func(ticketID []byte, expiry time.Time) bool
+}
+
+// SessionTicketDataHandler allows the application to store data with session tickets.
+type SessionTicketDataHandler struct {
+	// Get returns the data to store, when a session ticket is issued (on the server),
+	// or when a session ticket is received (on the client). It may be nil.
+	Get func() []byte
+	// Validate is called with the stored data when a session ticket is used for 0-RTT.
+	// If it returns false, 0-RTT is rejected (on the server), or not used (on the client). It may be nil.
+	Validate func([]byte) bool
+}
+
 // EventKind is the kind of handshake event.
 type EventKind uint8
 
@@ -81,6 +108,12 @@ const (
 	EventRestoredTransportParameters
 	// EventHandshakeComplete signals that the TLS handshake was completed.
 	EventHandshakeComplete
+	// EventNegotiatedVersion signals that the server switched the connection to a compatible version (RFC 9368).
+	// It is only emitted on the server side.
+	EventNegotiatedVersion
+	// EventSessionTicketData contains the application data stored with the session ticket.
+	// The client emits it when offering the session ticket, the server once the session was resumed.
+	EventSessionTicketData
 )
 
 func (k EventKind) String() string {
@@ -101,6 +134,10 @@ func (k EventKind) String() string {
 		return "EventRestoredTransportParameters"
 	case EventHandshakeComplete:
 		return "EventHandshakeComplete"
+	case EventNegotiatedVersion:
+		return "EventNegotiatedVersion"
+	case EventSessionTicketData:
+		return "EventSessionTicketData"
 	default:
 		return "Unknown EventKind"
 	}
@@ -111,6 +148,7 @@ type Event struct {
 	Kind                EventKind
 	Data                []byte
 	TransportParameters *wire.TransportParameters
+	Version             protocol.Version
 }
 
 // CryptoSetup handles the handshake and protecting / unprotecting packets
@@ -118,6 +156,7 @@ type CryptoSetup interface {
 	StartHandshake(context.Context) error
 	io.Closer
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.Version)
 	GetSessionTicket() ([]byte, error)
 
 	HandleMessage([]byte, protocol.EncryptionLevel) error
@@ -128,7 +167,7 @@ type CryptoSetup interface {
 	SetHandshakeConfirmed()
 	ConnectionState() ConnectionState
 
-	GetInitialOpener() (LongHeaderOpener, error)
+	GetInitialOpener(protocol.Version) (LongHeaderOpener, error)
 	GetHandshakeOpener() (LongHeaderOpener, error)
 	Get0RTTOpener() (LongHeaderOpener, error)
 	Get1RTTOpener() (ShortHeaderOpener, error)
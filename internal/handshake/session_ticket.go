@@ -1,6 +1,7 @@
 package handshake
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"time"
@@ -9,17 +10,34 @@ import (
 	"github.com/quic-go/quic-go/quicvarint"
 )
 
-const sessionTicketRevision = 4
+const sessionTicketRevision = 6
+
+// sessionTicketLifetime is the maximum lifetime of a session ticket enforced by crypto/tls.
+const sessionTicketLifetime = 7 * 24 * time.Hour
 
 type sessionTicket struct {
 	Parameters *wire.TransportParameters
 	RTT        time.Duration // to be encoded in mus
+	IssuedAt   time.Time     // to be encoded in mus
+	// ID uniquely identifies the session ticket.
+	// It is used to prevent replays of 0-RTT data.
+	ID [16]byte
+	// AppData is application-defined data
+	AppData []byte
 }
 
 func (t *sessionTicket) Marshal() []byte {
 	b := make([]byte, 0, 256)
 	b = quicvarint.Append(b, sessionTicketRevision)
 	b = quicvarint.Append(b, uint64(t.RTT.Microseconds()))
+	var issuedAt uint64
+	if !t.IssuedAt.IsZero() {
+		issuedAt = uint64(t.IssuedAt.UnixMicro())
+	}
+	b = quicvarint.Append(b, issuedAt)
+	b = append(b, t.ID[:]...)
+	b = quicvarint.Append(b, uint64(len(t.AppData)))
+	b = append(b, t.AppData...)
 	if t.Parameters == nil {
 		return b
 	}
@@ -40,6 +58,28 @@ func (t *sessionTicket) Unmarshal(b []byte, using0RTT bool) error {
 		return errors.New("failed to read RTT")
 	}
 	b = b[l:]
+	issuedAt, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return errors.New("failed to read issue time")
+	}
+	b = b[l:]
+	if len(b) < len(t.ID) {
+		return errors.New("failed to read session ticket ID")
+	}
+	copy(t.ID[:], b)
+	b = b[len(t.ID):]
+	appDataLen, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return errors.New("failed to read application data length")
+	}
+	b = b[l:]
+	if uint64(len(b)) < appDataLen {
+		return errors.New("failed to read application data")
+	}
+	if appDataLen > 0 {
+		t.AppData = bytes.Clone(b[:appDataLen])
+	}
+	b = b[appDataLen:]
 	if using0RTT {
 		var tp wire.TransportParameters
 		if err := tp.UnmarshalFromSessionTicket(b); err != nil {
@@ -50,5 +90,8 @@ func (t *sessionTicket) Unmarshal(b []byte, using0RTT bool) error {
 		return fmt.Errorf("the session ticket has more bytes than expected")
 	}
 	t.RTT = time.Duration(rtt) * time.Microsecond
+	if issuedAt > 0 {
+		t.IssuedAt = time.UnixMicro(int64(issuedAt))
+	}
 	return nil
 }
@@ -18,10 +18,16 @@ func TestMarshalUnmarshal0RTTSessionTicket(t *testing.T) {
 			ActiveConnectionIDLimit:        10,
 			MaxDatagramFrameSize:           20,
 		},
-		RTT: 1337 * time.Microsecond,
+		RTT:      1337 * time.Microsecond,
+		IssuedAt: time.UnixMicro(1234567890),
+		ID:       [16]byte{1, 2, 3, 4},
+		AppData:  []byte("foobar"),
 	}
 	var t2 sessionTicket
 	require.NoError(t, t2.Unmarshal(ticket.Marshal(), true))
+	require.Equal(t, []byte("foobar"), t2.AppData)
+	require.Equal(t, time.UnixMicro(1234567890), t2.IssuedAt)
+	require.Equal(t, [16]byte{1, 2, 3, 4}, t2.ID)
 	require.EqualValues(t, 1, t2.Parameters.InitialMaxStreamDataBidiLocal)
 	require.EqualValues(t, 2, t2.Parameters.InitialMaxStreamDataBidiRemote)
 	require.EqualValues(t, 10, t2.Parameters.ActiveConnectionIDLimit)
@@ -69,8 +75,39 @@ func TestUnmarshalRefusesInvalidRTT(t *testing.T) {
 	require.EqualError(t, err, "failed to read RTT")
 }
 
+func TestUnmarshalRefusesInvalidIssueTime(t *testing.T) {
+	b := quicvarint.Append(nil, sessionTicketRevision)
+	b = quicvarint.Append(b, 1337)
+	err := (&sessionTicket{}).Unmarshal(b, true)
+	require.EqualError(t, err, "failed to read issue time")
+}
+
+func TestUnmarshalRefusesTooShortID(t *testing.T) {
+	b := quicvarint.Append(nil, sessionTicketRevision)
+	b = quicvarint.Append(b, 1337)
+	b = quicvarint.Append(b, 42)
+	b = append(b, make([]byte, 15)...)
+	err := (&sessionTicket{}).Unmarshal(b, false)
+	require.EqualError(t, err, "failed to read session ticket ID")
+}
+
+func TestUnmarshalRefusesTooShortAppData(t *testing.T) {
+	b := quicvarint.Append(nil, sessionTicketRevision)
+	b = quicvarint.Append(b, 1337)
+	b = quicvarint.Append(b, 42)
+	b = append(b, make([]byte, 16)...)
+	b = quicvarint.Append(b, 10)
+	b = append(b, []byte("foobar")...)
+	err := (&sessionTicket{}).Unmarshal(b, false)
+	require.EqualError(t, err, "failed to read application data")
+}
+
 func TestUnmarshal0RTTRefusesInvalidTransportParameters(t *testing.T) {
 	b := quicvarint.Append(nil, sessionTicketRevision)
+	b = quicvarint.Append(b, 1337)
+	b = quicvarint.Append(b, 42)
+	b = append(b, make([]byte, 16)...)
+	b = quicvarint.Append(b, 0)
 	b = append(b, []byte("foobar")...)
 	err := (&sessionTicket{}).Unmarshal(b, true)
 	require.Error(t, err)
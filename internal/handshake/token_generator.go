@@ -5,6 +5,7 @@ import (
 	"encoding/asn1"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -17,17 +18,24 @@ const (
 
 // A Token is derived from the client address and can be used to verify the ownership of this address.
 type Token struct {
-	IsRetryToken      bool
-	SentTime          time.Time
-	encodedRemoteAddr []byte
+	IsRetryToken bool
+	SentTime     time.Time
+	// RemoteAddr is the address the token was issued to.
+	// For UDP addresses, only the IP address is used, since the port might change due to NAT rebinding.
+	RemoteAddr net.Addr
 	// only set for retry tokens
 	OriginalDestConnectionID protocol.ConnectionID
 	RetrySrcConnectionID     protocol.ConnectionID
+	// Data is application-defined data, only set for tokens sent in NEW_TOKEN frames.
+	Data []byte
 }
 
 // ValidateRemoteAddr validates the address, but does not check expiration
 func (t *Token) ValidateRemoteAddr(addr net.Addr) bool {
-	return bytes.Equal(encodeRemoteAddr(addr), t.encodedRemoteAddr)
+	if t.RemoteAddr == nil {
+		return false
+	}
+	return bytes.Equal(encodeRemoteAddr(addr), encodeRemoteAddr(t.RemoteAddr))
 }
 
 // token is the struct that is used for ASN1 serialization and deserialization
@@ -37,16 +45,44 @@ type token struct {
 	Timestamp                int64
 	OriginalDestConnectionID []byte
 	RetrySrcConnectionID     []byte
+	Data                     []byte `asn1:"optional"`
+}
+
+// A TokenKey is a key used to encrypt tokens.
+type TokenKey struct {
+	// ID identifies the key. It is sent in the clear, as the first byte of the token.
+	ID  uint8
+	Key TokenProtectorKey
 }
 
 // A TokenGenerator generates tokens
 type TokenGenerator struct {
-	tokenProtector tokenProtector
+	mutex      sync.RWMutex
+	currentID  uint8
+	protectors map[uint8]*tokenProtector
+}
+
+// NewTokenGenerator initializes a new TokenGenerator.
+// New tokens are encrypted using the current key, tokens encrypted using any of the keys are accepted.
+func NewTokenGenerator(current TokenKey, keys ...TokenKey) *TokenGenerator {
+	g := &TokenGenerator{}
+	g.SetKeys(current, keys...)
+	return g
 }
 
-// NewTokenGenerator initializes a new TokenGenerator
-func NewTokenGenerator(key TokenProtectorKey) *TokenGenerator {
-	return &TokenGenerator{tokenProtector: *newTokenProtector(key)}
+// SetKeys replaces the keys.
+// This allows rotating keys without rejecting tokens encrypted using the previous key.
+func (g *TokenGenerator) SetKeys(current TokenKey, keys ...TokenKey) {
+	protectors := make(map[uint8]*tokenProtector, len(keys)+1)
+	for _, k := range keys {
+		protectors[k.ID] = newTokenProtector(k.Key)
+	}
+	protectors[current.ID] = newTokenProtector(current.Key)
+
+	g.mutex.Lock()
+	g.currentID = current.ID
+	g.protectors = protectors
+	g.mutex.Unlock()
 }
 
 // NewRetryToken generates a new token for a Retry for a given source address
@@ -65,19 +101,35 @@ func (g *TokenGenerator) NewRetryToken(
 	if err != nil {
 		return nil, err
 	}
-	return g.tokenProtector.NewToken(data)
+	return g.protect(data)
 }
 
-// NewToken generates a new token to be sent in a NEW_TOKEN frame
-func (g *TokenGenerator) NewToken(raddr net.Addr) ([]byte, error) {
+// NewToken generates a new token to be sent in a NEW_TOKEN frame.
+// The application-defined data is included in the token.
+func (g *TokenGenerator) NewToken(raddr net.Addr, appData []byte) ([]byte, error) {
 	data, err := asn1.Marshal(token{
 		RemoteAddr: encodeRemoteAddr(raddr),
 		Timestamp:  time.Now().UnixNano(),
+		Data:       appData,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return g.tokenProtector.NewToken(data)
+	return g.protect(data)
+}
+
+// protect encrypts the token using the current key, and prepends the key ID
+func (g *TokenGenerator) protect(data []byte) ([]byte, error) {
+	g.mutex.RLock()
+	id := g.currentID
+	protector := g.protectors[id]
+	g.mutex.RUnlock()
+
+	b, err := protector.NewToken(data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{id}, b...), nil
 }
 
 // DecodeToken decodes a token
@@ -87,7 +139,13 @@ func (g *TokenGenerator) DecodeToken(encrypted []byte) (*Token, error) {
 		return nil, nil
 	}
 
-	data, err := g.tokenProtector.DecodeToken(encrypted)
+	g.mutex.RLock()
+	protector, ok := g.protectors[encrypted[0]]
+	g.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown token key ID: %d", encrypted[0])
+	}
+	data, err := protector.DecodeToken(encrypted[1:])
 	if err != nil {
 		return nil, err
 	}
@@ -100,9 +158,10 @@ func (g *TokenGenerator) DecodeToken(encrypted []byte) (*Token, error) {
 		return nil, fmt.Errorf("rest when unpacking token: %d", len(rest))
 	}
 	token := &Token{
-		IsRetryToken:      t.IsRetryToken,
-		SentTime:          time.Unix(0, t.Timestamp),
-		encodedRemoteAddr: t.RemoteAddr,
+		IsRetryToken: t.IsRetryToken,
+		SentTime:     time.Unix(0, t.Timestamp),
+		RemoteAddr:   decodeRemoteAddr(t.RemoteAddr),
+		Data:         t.Data,
 	}
 	if t.IsRetryToken {
 		token.OriginalDestConnectionID = protocol.ParseConnectionID(t.OriginalDestConnectionID)
@@ -118,3 +177,24 @@ func encodeRemoteAddr(remoteAddr net.Addr) []byte {
 	}
 	return append([]byte{tokenPrefixString}, []byte(remoteAddr.String())...)
 }
+
+// decodeRemoteAddr decodes a remote address encoded by encodeRemoteAddr
+func decodeRemoteAddr(b []byte) net.Addr {
+	if len(b) == 0 {
+		return nil
+	}
+	switch b[0] {
+	case tokenPrefixIP:
+		return &net.UDPAddr{IP: net.IP(b[1:])}
+	case tokenPrefixString:
+		return stringAddr(b[1:])
+	default:
+		return nil
+	}
+}
+
+// stringAddr is a net.Addr that is only known by its string representation
+type stringAddr string
+
+func (a stringAddr) Network() string { return "" }
+func (a stringAddr) String() string  { return string(a) }
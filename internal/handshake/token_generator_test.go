@@ -16,7 +16,7 @@ func newTokenGenerator(t *testing.T) *TokenGenerator {
 	var key TokenProtectorKey
 	_, err := rand.Read(key[:])
 	require.NoError(t, err)
-	return NewTokenGenerator(key)
+	return NewTokenGenerator(TokenKey{Key: key})
 }
 
 func TestTokenGeneratorNilTokens(t *testing.T) {
@@ -46,15 +46,25 @@ func TestTokenGeneratorValidToken(t *testing.T) {
 func TestTokenGeneratorRejectsInvalidTokens(t *testing.T) {
 	tokenGen := newTokenGenerator(t)
 
-	_, err := tokenGen.DecodeToken([]byte("invalid token"))
+	_, err := tokenGen.DecodeToken(append([]byte{0}, []byte("invalid token")...))
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "too short")
 }
 
+func TestTokenGeneratorUnknownKeyID(t *testing.T) {
+	tokenGen := newTokenGenerator(t)
+
+	tokenEnc, err := tokenGen.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}, nil)
+	require.NoError(t, err)
+	tokenEnc[0] = 42
+	_, err = tokenGen.DecodeToken(tokenEnc)
+	require.EqualError(t, err, "unknown token key ID: 42")
+}
+
 func TestTokenGeneratorDecodingFailed(t *testing.T) {
 	tokenGen := newTokenGenerator(t)
 
-	invalidToken, err := tokenGen.tokenProtector.NewToken([]byte("foobar"))
+	invalidToken, err := tokenGen.protect([]byte("foobar"))
 	require.NoError(t, err)
 	_, err = tokenGen.DecodeToken(invalidToken)
 	require.Error(t, err)
@@ -67,7 +77,7 @@ func TestTokenGeneratorAdditionalPayload(t *testing.T) {
 	tok, err := asn1.Marshal(token{RemoteAddr: []byte("foobar")})
 	require.NoError(t, err)
 	tok = append(tok, []byte("rest")...)
-	enc, err := tokenGen.tokenProtector.NewToken(tok)
+	enc, err := tokenGen.protect(tok)
 	require.NoError(t, err)
 	_, err = tokenGen.DecodeToken(enc)
 	require.EqualError(t, err, "rest when unpacking token: 4")
@@ -78,10 +88,67 @@ func TestTokenGeneratorEmptyTokens(t *testing.T) {
 
 	emptyTok, err := asn1.Marshal(token{RemoteAddr: []byte("")})
 	require.NoError(t, err)
-	emptyEnc, err := tokenGen.tokenProtector.NewToken(emptyTok)
+	emptyEnc, err := tokenGen.protect(emptyTok)
+	require.NoError(t, err)
+	tok, err := tokenGen.DecodeToken(emptyEnc)
+	require.NoError(t, err)
+	require.Nil(t, tok.RemoteAddr)
+	require.False(t, tok.ValidateRemoteAddr(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}))
+}
+
+func TestTokenGeneratorNewToken(t *testing.T) {
+	tokenGen := newTokenGenerator(t)
+
+	addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+	tokenEnc, err := tokenGen.NewToken(addr, []byte("foobar"))
+	require.NoError(t, err)
+	token, err := tokenGen.DecodeToken(tokenEnc)
+	require.NoError(t, err)
+	require.False(t, token.IsRetryToken)
+	require.Equal(t, []byte("foobar"), token.Data)
+	require.Equal(t, &net.UDPAddr{IP: addr.IP}, token.RemoteAddr)
+	// the port is not encoded
+	require.True(t, token.ValidateRemoteAddr(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 4242}))
+	require.Zero(t, token.OriginalDestConnectionID.Len())
+	require.Zero(t, token.RetrySrcConnectionID.Len())
+}
+
+func TestTokenGeneratorKeyRotation(t *testing.T) {
+	key1 := TokenKey{ID: 1, Key: TokenProtectorKey{1}}
+	key2 := TokenKey{ID: 2, Key: TokenProtectorKey{2}}
+	tokenGen := NewTokenGenerator(key1)
+
+	addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+	token1, err := tokenGen.NewToken(addr, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint8(1), token1[0])
+
+	// rotate the key, but keep accepting tokens encrypted using the old key
+	tokenGen.SetKeys(key2, key1)
+	token2, err := tokenGen.NewToken(addr, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint8(2), token2[0])
+	for _, tok := range [][]byte{token1, token2} {
+		decoded, err := tokenGen.DecodeToken(tok)
+		require.NoError(t, err)
+		require.True(t, decoded.ValidateRemoteAddr(addr))
+	}
+
+	// retire the old key
+	tokenGen.SetKeys(key2)
+	_, err = tokenGen.DecodeToken(token1)
+	require.EqualError(t, err, "unknown token key ID: 1")
+	_, err = tokenGen.DecodeToken(token2)
 	require.NoError(t, err)
-	_, err = tokenGen.DecodeToken(emptyEnc)
+}
+
+func TestTokenGeneratorKeyIDMismatch(t *testing.T) {
+	tokenGen := NewTokenGenerator(TokenKey{ID: 1, Key: TokenProtectorKey{1}}, TokenKey{ID: 2, Key: TokenProtectorKey{2}})
+	tokenEnc, err := tokenGen.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}, nil)
 	require.NoError(t, err)
+	tokenEnc[0] = 2
+	_, err = tokenGen.DecodeToken(tokenEnc)
+	require.Error(t, err)
 }
 
 func TestTokenGeneratorIPv6(t *testing.T) {
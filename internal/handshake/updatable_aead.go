@@ -63,6 +63,8 @@ type updatableAEAD struct {
 
 	// use a single slice to avoid allocations
 	nonceBuf []byte
+	// used for packets sent on paths other than path 0, when using the multipath extension
+	pathNonceBuf [aeadNonceLength]byte
 }
 
 var (
@@ -166,9 +168,8 @@ func (a *updatableAEAD) DecodePacketNumber(wirePN protocol.PacketNumber, wirePNL
 func (a *updatableAEAD) Open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
 	dec, err := a.open(dst, src, rcvTime, pn, kp, ad)
 	if err == ErrDecryptionFailed {
-		a.invalidPacketCount++
-		if a.invalidPacketCount >= a.invalidPacketLimit {
-			return nil, &qerr.TransportError{ErrorCode: qerr.AEADLimitReached}
+		if err := a.countInvalidPacket(); err != nil {
+			return nil, err
 		}
 	}
 	if err == nil {
@@ -177,7 +178,31 @@ func (a *updatableAEAD) Open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 	return dec, err
 }
 
-func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+// OpenPath opens a packet received on a path other than the handshake path, when using the multipath extension.
+// Every path uses its own packet number space, and the path ID is used in the nonce.
+// For path 0, it is equivalent to Open.
+func (a *updatableAEAD) OpenPath(dst, src []byte, rcvTime time.Time, pathID protocol.PathID, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	if pathID == 0 {
+		return a.Open(dst, src, rcvTime, pn, kp, ad)
+	}
+	dec, err := a.openPath(dst, src, rcvTime, pathID, pn, kp, ad)
+	if err == ErrDecryptionFailed {
+		if err := a.countInvalidPacket(); err != nil {
+			return nil, err
+		}
+	}
+	return dec, err
+}
+
+func (a *updatableAEAD) countInvalidPacket() error {
+	a.invalidPacketCount++
+	if a.invalidPacketCount >= a.invalidPacketLimit {
+		return &qerr.TransportError{ErrorCode: qerr.AEADLimitReached}
+	}
+	return nil
+}
+
+func (a *updatableAEAD) maybeDropPrevKey(rcvTime time.Time) {
 	if a.prevRcvAEAD != nil && !a.prevRcvAEADExpiry.IsZero() && rcvTime.After(a.prevRcvAEADExpiry) {
 		a.prevRcvAEAD = nil
 		a.logger.Debugf("Dropping key phase %d", a.keyPhase-1)
@@ -186,6 +211,10 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 			a.tracer.DroppedKey(a.keyPhase - 1)
 		}
 	}
+}
+
+func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	a.maybeDropPrevKey(rcvTime)
 	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
 	if kp != a.keyPhase.Bit() {
 		if a.keyPhase > 0 && a.firstRcvdWithCurrentKey == protocol.InvalidPacketNumber || pn < a.firstRcvdWithCurrentKey {
@@ -211,14 +240,7 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 				ErrorMessage: "keys updated too quickly",
 			}
 		}
-		a.rollKeys()
-		a.logger.Debugf("Peer updated keys to %d", a.keyPhase)
-		// The peer initiated this key update. It's safe to drop the keys for the previous generation now.
-		// Start a timer to drop the previous key generation.
-		a.startKeyDropTimer(rcvTime)
-		if a.tracer != nil && a.tracer.UpdatedKey != nil {
-			a.tracer.UpdatedKey(a.keyPhase, true)
-		}
+		a.handlePeerKeyUpdate(rcvTime)
 		a.firstRcvdWithCurrentKey = pn
 		return dec, err
 	}
@@ -241,6 +263,51 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 	return dec, err
 }
 
+// openPath opens a packet received on a path other than path 0.
+// Packet numbers on these paths can't be compared to the packet numbers used on path 0,
+// so the key phase of a packet is determined by trial decryption.
+func (a *updatableAEAD) openPath(dst, src []byte, rcvTime time.Time, pathID protocol.PathID, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	a.maybeDropPrevKey(rcvTime)
+	nonce := a.pathNonce(pathID, pn)
+	if kp == a.keyPhase.Bit() {
+		dec, err := a.rcvAEAD.Open(dst, nonce, src, ad)
+		if err != nil {
+			return nil, ErrDecryptionFailed
+		}
+		a.numRcvdWithCurrentKey++
+		return dec, nil
+	}
+	if a.prevRcvAEAD != nil {
+		if dec, err := a.prevRcvAEAD.Open(dst, nonce, src, ad); err == nil {
+			return dec, nil
+		}
+	}
+	dec, err := a.nextRcvAEAD.Open(dst, nonce, src, ad)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	if a.keyPhase > 0 && a.numSentWithCurrentKey == 0 {
+		return nil, &qerr.TransportError{
+			ErrorCode:    qerr.KeyUpdateError,
+			ErrorMessage: "keys updated too quickly",
+		}
+	}
+	a.handlePeerKeyUpdate(rcvTime)
+	a.numRcvdWithCurrentKey++
+	return dec, nil
+}
+
+func (a *updatableAEAD) handlePeerKeyUpdate(rcvTime time.Time) {
+	a.rollKeys()
+	a.logger.Debugf("Peer updated keys to %d", a.keyPhase)
+	// The peer initiated this key update. It's safe to drop the keys for the previous generation now.
+	// Start a timer to drop the previous key generation.
+	a.startKeyDropTimer(rcvTime)
+	if a.tracer != nil && a.tracer.UpdatedKey != nil {
+		a.tracer.UpdatedKey(a.keyPhase, true)
+	}
+}
+
 func (a *updatableAEAD) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byte) []byte {
 	if a.firstSentWithCurrentKey == protocol.InvalidPacketNumber {
 		a.firstSentWithCurrentKey = pn
@@ -255,6 +322,24 @@ func (a *updatableAEAD) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byt
 	return a.sendAEAD.Seal(dst, a.nonceBuf, src, ad)
 }
 
+// SealPath seals a packet sent on the path with the given path ID, when using the multipath extension.
+// For path 0, it is equivalent to Seal.
+func (a *updatableAEAD) SealPath(dst, src []byte, pathID protocol.PathID, pn protocol.PacketNumber, ad []byte) []byte {
+	if pathID == 0 {
+		return a.Seal(dst, src, pn, ad)
+	}
+	a.numSentWithCurrentKey++
+	return a.sendAEAD.Seal(dst, a.pathNonce(pathID, pn), src, ad)
+}
+
+// pathNonce returns the nonce used on paths other than path 0:
+// the 32-bit path ID, followed by the 64-bit packet number.
+func (a *updatableAEAD) pathNonce(pathID protocol.PathID, pn protocol.PacketNumber) []byte {
+	binary.BigEndian.PutUint32(a.pathNonceBuf[:4], uint32(pathID))
+	binary.BigEndian.PutUint64(a.pathNonceBuf[4:], uint64(pn))
+	return a.pathNonceBuf[:]
+}
+
 func (a *updatableAEAD) SetLargestAcked(pn protocol.PacketNumber) error {
 	if a.firstSentWithCurrentKey != protocol.InvalidPacketNumber &&
 		pn >= a.firstSentWithCurrentKey && a.numRcvdWithCurrentKey == 0 {
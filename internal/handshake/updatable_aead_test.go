@@ -175,6 +175,55 @@ func TestUpdatableAEADPacketNumbers(t *testing.T) {
 	require.Equal(t, protocol.PacketNumber(0x1338), client.DecodePacketNumber(0x38, protocol.PacketNumberLen1))
 }
 
+func TestUpdatableAEADPathNonce(t *testing.T) {
+	client, server, _ := setupEndpoints(t, &utils.RTTStats{})
+
+	// path 0 uses the same nonce as a single-path connection
+	encrypted := server.SealPath(nil, []byte(msg), 0, 0x1337, []byte(ad))
+	require.Equal(t, server.Seal(nil, []byte(msg), 0x1337, []byte(ad)), encrypted)
+
+	encrypted1 := server.SealPath(nil, []byte(msg), 1, 0x1337, []byte(ad))
+	encrypted2 := server.SealPath(nil, []byte(msg), 2, 0x1337, []byte(ad))
+	require.NotEqual(t, encrypted, encrypted1)
+	require.NotEqual(t, encrypted1, encrypted2)
+
+	decrypted, err := client.OpenPath(nil, encrypted1, time.Now(), 1, 0x1337, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, msg, string(decrypted))
+	_, err = client.OpenPath(nil, encrypted1, time.Now(), 2, 0x1337, protocol.KeyPhaseZero, []byte(ad))
+	require.Equal(t, ErrDecryptionFailed, err)
+	decrypted, err = client.OpenPath(nil, encrypted2, time.Now(), 2, 0x1337, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, msg, string(decrypted))
+	// packet numbers on other paths don't affect packet number decoding on path 0
+	require.Equal(t, protocol.PacketNumber(0x38), client.DecodePacketNumber(0x38, protocol.PacketNumberLen1))
+}
+
+func TestUpdatableAEADPathKeyUpdate(t *testing.T) {
+	client, server, serverTracer := setupEndpoints(t, &utils.RTTStats{})
+	server.SetHandshakeConfirmed()
+
+	now := time.Now()
+	encrypted := client.SealPath(nil, []byte(msg), 1, 0x10, []byte(ad))
+	_, err := server.OpenPath(nil, encrypted, now, 1, 0x10, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+
+	// the client updates its keys, and sends a packet on path 1
+	client.rollKeys()
+	encrypted = client.SealPath(nil, []byte(msg), 1, 0x11, []byte(ad))
+	serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), true)
+	decrypted, err := server.OpenPath(nil, encrypted, now, 1, 0x11, protocol.KeyPhaseOne, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, msg, string(decrypted))
+	require.Equal(t, protocol.KeyPhaseOne, server.KeyPhase())
+
+	// reordered packets using the old key phase can still be decrypted
+	encrypted = server.prevRcvAEAD.Seal(nil, server.pathNonce(1, 0xf), []byte(msg), []byte(ad))
+	decrypted, err = server.OpenPath(nil, encrypted, now, 1, 0xf, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, msg, string(decrypted))
+}
+
 func TestAEADLimitReached(t *testing.T) {
 	client, _, _ := setupEndpoints(t, &utils.RTTStats{})
 	client.invalidPacketLimit = 10
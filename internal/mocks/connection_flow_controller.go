@@ -189,6 +189,44 @@ func (c *MockConnectionFlowControllerIsNewlyBlockedCall) DoAndReturn(f func() (b
 	return c
 }
 
+// ReceiveWindowSize mocks base method.
+func (m *MockConnectionFlowController) ReceiveWindowSize() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveWindowSize")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// ReceiveWindowSize indicates an expected call of ReceiveWindowSize.
+func (mr *MockConnectionFlowControllerMockRecorder) ReceiveWindowSize() *MockConnectionFlowControllerReceiveWindowSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveWindowSize", reflect.TypeOf((*MockConnectionFlowController)(nil).ReceiveWindowSize))
+	return &MockConnectionFlowControllerReceiveWindowSizeCall{Call: call}
+}
+
+// MockConnectionFlowControllerReceiveWindowSizeCall wrap *gomock.Call
+type MockConnectionFlowControllerReceiveWindowSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionFlowControllerReceiveWindowSizeCall) Return(arg0 protocol.ByteCount) *MockConnectionFlowControllerReceiveWindowSizeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionFlowControllerReceiveWindowSizeCall) Do(f func() protocol.ByteCount) *MockConnectionFlowControllerReceiveWindowSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionFlowControllerReceiveWindowSizeCall) DoAndReturn(f func() protocol.ByteCount) *MockConnectionFlowControllerReceiveWindowSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Reset mocks base method.
 func (m *MockConnectionFlowController) Reset() error {
 	m.ctrl.T.Helper()
@@ -104,6 +104,15 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		ChoseALPN: func(protocol string) {
 			t.ChoseALPN(protocol)
 		},
+		ValidatedPath: func(local, remote net.Addr) {
+			t.ValidatedPath(local, remote)
+		},
+		AbandonedPath: func(local, remote net.Addr) {
+			t.AbandonedPath(local, remote)
+		},
+		MigratedPath: func(local, remote net.Addr) {
+			t.MigratedPath(local, remote)
+		},
 		Close: func() {
 			t.Close()
 		},
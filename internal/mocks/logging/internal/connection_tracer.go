@@ -44,6 +44,42 @@ func (m *MockConnectionTracer) EXPECT() *MockConnectionTracerMockRecorder {
 	return m.recorder
 }
 
+// AbandonedPath mocks base method.
+func (m *MockConnectionTracer) AbandonedPath(arg0, arg1 net.Addr) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AbandonedPath", arg0, arg1)
+}
+
+// AbandonedPath indicates an expected call of AbandonedPath.
+func (mr *MockConnectionTracerMockRecorder) AbandonedPath(arg0, arg1 any) *MockConnectionTracerAbandonedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbandonedPath", reflect.TypeOf((*MockConnectionTracer)(nil).AbandonedPath), arg0, arg1)
+	return &MockConnectionTracerAbandonedPathCall{Call: call}
+}
+
+// MockConnectionTracerAbandonedPathCall wrap *gomock.Call
+type MockConnectionTracerAbandonedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerAbandonedPathCall) Return() *MockConnectionTracerAbandonedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerAbandonedPathCall) Do(f func(net.Addr, net.Addr)) *MockConnectionTracerAbandonedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerAbandonedPathCall) DoAndReturn(f func(net.Addr, net.Addr)) *MockConnectionTracerAbandonedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // AcknowledgedPacket mocks base method.
 func (m *MockConnectionTracer) AcknowledgedPacket(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber) {
 	m.ctrl.T.Helper()
@@ -512,6 +548,42 @@ func (c *MockConnectionTracerLostPacketCall) DoAndReturn(f func(protocol.Encrypt
 	return c
 }
 
+// MigratedPath mocks base method.
+func (m *MockConnectionTracer) MigratedPath(arg0, arg1 net.Addr) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedPath", arg0, arg1)
+}
+
+// MigratedPath indicates an expected call of MigratedPath.
+func (mr *MockConnectionTracerMockRecorder) MigratedPath(arg0, arg1 any) *MockConnectionTracerMigratedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedPath", reflect.TypeOf((*MockConnectionTracer)(nil).MigratedPath), arg0, arg1)
+	return &MockConnectionTracerMigratedPathCall{Call: call}
+}
+
+// MockConnectionTracerMigratedPathCall wrap *gomock.Call
+type MockConnectionTracerMigratedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerMigratedPathCall) Return() *MockConnectionTracerMigratedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerMigratedPathCall) Do(f func(net.Addr, net.Addr)) *MockConnectionTracerMigratedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerMigratedPathCall) DoAndReturn(f func(net.Addr, net.Addr)) *MockConnectionTracerMigratedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NegotiatedVersion mocks base method.
 func (m *MockConnectionTracer) NegotiatedVersion(arg0 protocol.Version, arg1, arg2 []protocol.Version) {
 	m.ctrl.T.Helper()
@@ -1159,3 +1231,39 @@ func (c *MockConnectionTracerUpdatedPTOCountCall) DoAndReturn(f func(uint32)) *M
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// ValidatedPath mocks base method.
+func (m *MockConnectionTracer) ValidatedPath(arg0, arg1 net.Addr) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ValidatedPath", arg0, arg1)
+}
+
+// ValidatedPath indicates an expected call of ValidatedPath.
+func (mr *MockConnectionTracerMockRecorder) ValidatedPath(arg0, arg1 any) *MockConnectionTracerValidatedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatedPath", reflect.TypeOf((*MockConnectionTracer)(nil).ValidatedPath), arg0, arg1)
+	return &MockConnectionTracerValidatedPathCall{Call: call}
+}
+
+// MockConnectionTracerValidatedPathCall wrap *gomock.Call
+type MockConnectionTracerValidatedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerValidatedPathCall) Return() *MockConnectionTracerValidatedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerValidatedPathCall) Do(f func(net.Addr, net.Addr)) *MockConnectionTracerValidatedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerValidatedPathCall) DoAndReturn(f func(net.Addr, net.Addr)) *MockConnectionTracerValidatedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -49,6 +49,9 @@ type ConnectionTracer interface {
 	LossTimerCanceled()
 	ECNStateUpdated(state logging.ECNState, trigger logging.ECNStateTrigger)
 	ChoseALPN(protocol string)
+	ValidatedPath(local, remote net.Addr)
+	AbandonedPath(local, remote net.Addr)
+	MigratedPath(local, remote net.Addr)
 	// Close is called when the connection is closed.
 	Close()
 	Debug(name, msg string)
@@ -120,6 +120,45 @@ func (c *MockEarlyConnectionAcceptUniStreamCall) DoAndReturn(f func(context.Cont
 	return c
 }
 
+// AddPath mocks base method.
+func (m *MockEarlyConnection) AddPath(arg0 *quic.Transport) (*quic.Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(*quic.Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockEarlyConnectionMockRecorder) AddPath(arg0 any) *MockEarlyConnectionAddPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockEarlyConnection)(nil).AddPath), arg0)
+	return &MockEarlyConnectionAddPathCall{Call: call}
+}
+
+// MockEarlyConnectionAddPathCall wrap *gomock.Call
+type MockEarlyConnectionAddPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionAddPathCall) Return(arg0 *quic.Path, arg1 error) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionAddPathCall) Do(f func(*quic.Transport) (*quic.Path, error)) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionAddPathCall) DoAndReturn(f func(*quic.Transport) (*quic.Path, error)) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CloseWithError mocks base method.
 func (m *MockEarlyConnection) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -196,6 +235,44 @@ func (c *MockEarlyConnectionConnectionStateCall) DoAndReturn(f func() quic.Conne
 	return c
 }
 
+// ConnectionStats mocks base method.
+func (m *MockEarlyConnection) ConnectionStats() quic.ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectionStats")
+	ret0, _ := ret[0].(quic.ConnectionStats)
+	return ret0
+}
+
+// ConnectionStats indicates an expected call of ConnectionStats.
+func (mr *MockEarlyConnectionMockRecorder) ConnectionStats() *MockEarlyConnectionConnectionStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionStats", reflect.TypeOf((*MockEarlyConnection)(nil).ConnectionStats))
+	return &MockEarlyConnectionConnectionStatsCall{Call: call}
+}
+
+// MockEarlyConnectionConnectionStatsCall wrap *gomock.Call
+type MockEarlyConnectionConnectionStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionConnectionStatsCall) Return(arg0 quic.ConnectionStats) *MockEarlyConnectionConnectionStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionConnectionStatsCall) Do(f func() quic.ConnectionStats) *MockEarlyConnectionConnectionStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionConnectionStatsCall) DoAndReturn(f func() quic.ConnectionStats) *MockEarlyConnectionConnectionStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Context mocks base method.
 func (m *MockEarlyConnection) Context() context.Context {
 	m.ctrl.T.Helper()
@@ -349,6 +426,45 @@ func (c *MockEarlyConnectionNextConnectionCall) DoAndReturn(f func(context.Conte
 	return c
 }
 
+// OpenPath mocks base method.
+func (m *MockEarlyConnection) OpenPath(arg0 context.Context, arg1 *quic.Transport) (*quic.MultipathPath, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenPath", arg0, arg1)
+	ret0, _ := ret[0].(*quic.MultipathPath)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenPath indicates an expected call of OpenPath.
+func (mr *MockEarlyConnectionMockRecorder) OpenPath(arg0, arg1 any) *MockEarlyConnectionOpenPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenPath", reflect.TypeOf((*MockEarlyConnection)(nil).OpenPath), arg0, arg1)
+	return &MockEarlyConnectionOpenPathCall{Call: call}
+}
+
+// MockEarlyConnectionOpenPathCall wrap *gomock.Call
+type MockEarlyConnectionOpenPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionOpenPathCall) Return(arg0 *quic.MultipathPath, arg1 error) *MockEarlyConnectionOpenPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionOpenPathCall) Do(f func(context.Context, *quic.Transport) (*quic.MultipathPath, error)) *MockEarlyConnectionOpenPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionOpenPathCall) DoAndReturn(f func(context.Context, *quic.Transport) (*quic.MultipathPath, error)) *MockEarlyConnectionOpenPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // OpenStream mocks base method.
 func (m *MockEarlyConnection) OpenStream() (quic.Stream, error) {
 	m.ctrl.T.Helper()
@@ -505,6 +621,83 @@ func (c *MockEarlyConnectionOpenUniStreamSyncCall) DoAndReturn(f func(context.Co
 	return c
 }
 
+// OriginalPath mocks base method.
+func (m *MockEarlyConnection) OriginalPath() (*quic.Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OriginalPath")
+	ret0, _ := ret[0].(*quic.Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OriginalPath indicates an expected call of OriginalPath.
+func (mr *MockEarlyConnectionMockRecorder) OriginalPath() *MockEarlyConnectionOriginalPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OriginalPath", reflect.TypeOf((*MockEarlyConnection)(nil).OriginalPath))
+	return &MockEarlyConnectionOriginalPathCall{Call: call}
+}
+
+// MockEarlyConnectionOriginalPathCall wrap *gomock.Call
+type MockEarlyConnectionOriginalPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionOriginalPathCall) Return(arg0 *quic.Path, arg1 error) *MockEarlyConnectionOriginalPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionOriginalPathCall) Do(f func() (*quic.Path, error)) *MockEarlyConnectionOriginalPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionOriginalPathCall) DoAndReturn(f func() (*quic.Path, error)) *MockEarlyConnectionOriginalPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Paths mocks base method.
+func (m *MockEarlyConnection) Paths() []*quic.MultipathPath {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Paths")
+	ret0, _ := ret[0].([]*quic.MultipathPath)
+	return ret0
+}
+
+// Paths indicates an expected call of Paths.
+func (mr *MockEarlyConnectionMockRecorder) Paths() *MockEarlyConnectionPathsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Paths", reflect.TypeOf((*MockEarlyConnection)(nil).Paths))
+	return &MockEarlyConnectionPathsCall{Call: call}
+}
+
+// MockEarlyConnectionPathsCall wrap *gomock.Call
+type MockEarlyConnectionPathsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionPathsCall) Return(arg0 []*quic.MultipathPath) *MockEarlyConnectionPathsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionPathsCall) Do(f func() []*quic.MultipathPath) *MockEarlyConnectionPathsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionPathsCall) DoAndReturn(f func() []*quic.MultipathPath) *MockEarlyConnectionPathsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ReceiveDatagram mocks base method.
 func (m *MockEarlyConnection) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -152,3 +152,42 @@ func (c *MockShortHeaderOpenerOpenCall) DoAndReturn(f func([]byte, []byte, time.
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// OpenPath mocks base method.
+func (m *MockShortHeaderOpener) OpenPath(arg0, arg1 []byte, arg2 time.Time, arg3 protocol.PathID, arg4 protocol.PacketNumber, arg5 protocol.KeyPhaseBit, arg6 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenPath", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenPath indicates an expected call of OpenPath.
+func (mr *MockShortHeaderOpenerMockRecorder) OpenPath(arg0, arg1, arg2, arg3, arg4, arg5, arg6 any) *MockShortHeaderOpenerOpenPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenPath", reflect.TypeOf((*MockShortHeaderOpener)(nil).OpenPath), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	return &MockShortHeaderOpenerOpenPathCall{Call: call}
+}
+
+// MockShortHeaderOpenerOpenPathCall wrap *gomock.Call
+type MockShortHeaderOpenerOpenPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockShortHeaderOpenerOpenPathCall) Return(arg0 []byte, arg1 error) *MockShortHeaderOpenerOpenPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockShortHeaderOpenerOpenPathCall) Do(f func([]byte, []byte, time.Time, protocol.PathID, protocol.PacketNumber, protocol.KeyPhaseBit, []byte) ([]byte, error)) *MockShortHeaderOpenerOpenPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockShortHeaderOpenerOpenPathCall) DoAndReturn(f func([]byte, []byte, time.Time, protocol.PathID, protocol.PacketNumber, protocol.KeyPhaseBit, []byte) ([]byte, error)) *MockShortHeaderOpenerOpenPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -188,3 +188,41 @@ func (c *MockShortHeaderSealerSealCall) DoAndReturn(f func([]byte, []byte, proto
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SealPath mocks base method.
+func (m *MockShortHeaderSealer) SealPath(arg0, arg1 []byte, arg2 protocol.PathID, arg3 protocol.PacketNumber, arg4 []byte) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealPath", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// SealPath indicates an expected call of SealPath.
+func (mr *MockShortHeaderSealerMockRecorder) SealPath(arg0, arg1, arg2, arg3, arg4 any) *MockShortHeaderSealerSealPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealPath", reflect.TypeOf((*MockShortHeaderSealer)(nil).SealPath), arg0, arg1, arg2, arg3, arg4)
+	return &MockShortHeaderSealerSealPathCall{Call: call}
+}
+
+// MockShortHeaderSealerSealPathCall wrap *gomock.Call
+type MockShortHeaderSealerSealPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockShortHeaderSealerSealPathCall) Return(arg0 []byte) *MockShortHeaderSealerSealPathCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockShortHeaderSealerSealPathCall) Do(f func([]byte, []byte, protocol.PathID, protocol.PacketNumber, []byte) []byte) *MockShortHeaderSealerSealPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockShortHeaderSealerSealPathCall) DoAndReturn(f func([]byte, []byte, protocol.PathID, protocol.PacketNumber, []byte) []byte) *MockShortHeaderSealerSealPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
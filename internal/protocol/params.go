@@ -136,6 +136,12 @@ const MaxNumAckRanges = 32
 // Example: For a packet pacing delay of 200μs, we would send 5 packets at once, wait for 1ms, and so forth.
 const MinPacingDelay = time.Millisecond
 
+// KernelPacingHorizon is the maximum duration that packets are scheduled ahead of time
+// when pacing is offloaded to the kernel (using SO_TXTIME on Linux).
+// If the kernel doesn't pace the packets (e.g. because the qdisc doesn't support SO_TXTIME),
+// packets are sent out at most this much too early.
+const KernelPacingHorizon = 2 * time.Millisecond
+
 // DefaultConnectionIDLength is the connection ID length that is used for multiplexed connections
 // if no other value is configured.
 const DefaultConnectionIDLength = 4
@@ -146,6 +152,14 @@ const MaxActiveConnectionIDs = 4
 // MaxIssuedConnectionIDs is the maximum number of connection IDs that we're issuing at the same time.
 const MaxIssuedConnectionIDs = 6
 
+// MaxMultipathPaths is the maximum number of paths that the peer can open when using the multipath extension.
+// This includes the path that the handshake is performed on.
+const MaxMultipathPaths = 4
+
+// MaxIssuedConnectionIDsPerPath is the number of connection IDs that we're issuing for every path
+// opened using the multipath extension.
+const MaxIssuedConnectionIDsPerPath = 2
+
 // PacketsPerConnectionID is the number of packets we send using one connection ID.
 // If the peer provices us with enough new connection IDs, we switch to a new connection ID.
 const PacketsPerConnectionID = 10000
@@ -164,6 +178,10 @@ const MaxAckDelay = 25 * time.Millisecond
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
 
+// MinAckDelay is the min_ack_delay advertised when the ACK frequency extension is enabled.
+// The peer can't ask us to delay ACKs by less than this value.
+const MinAckDelay = TimerGranularity
+
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
 
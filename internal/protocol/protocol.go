@@ -104,6 +104,14 @@ const InvalidByteCount ByteCount = -1
 // A StatelessResetToken is a stateless reset token.
 type StatelessResetToken [16]byte
 
+// A PathID identifies a path when using the multipath extension.
+// The path the handshake is performed on has the path ID 0.
+type PathID uint32
+
+// MaxPathID is the largest path ID that can be used.
+// Path IDs are used in the AEAD nonce, and are therefore limited to 32 bits.
+const MaxPathID = PathID(1<<32 - 1)
+
 // MaxPacketBufferSize maximum packet size of any QUIC packet, based on
 // ethernet's max size, minus the IP and UDP headers. IPv6 has a 40 byte header,
 // UDP adds an additional 8 bytes.  This is a total overhead of 48 bytes.
@@ -113,6 +121,12 @@ const MaxPacketBufferSize = 1452
 // MaxLargePacketBufferSize is used when using GSO
 const MaxLargePacketBufferSize = 20 * 1024
 
+// MaxGROBufferSize is the size of the buffer used to receive datagrams coalesced by GRO.
+// The kernel never coalesces more than 64 KB.
+// It can't be smaller than that: the kernel truncates coalesced datagrams that don't fit into the buffer.
+// Since small datagrams are copied out of this buffer, a large buffer is only kept alive by coalesced segments.
+const MaxGROBufferSize = 1 << 16
+
 // MinInitialPacketSize is the minimum size an Initial packet is required to have.
 const MinInitialPacketSize = 1200
 
@@ -88,6 +88,16 @@ func ChooseSupportedVersion(ours, theirs []Version) (Version, bool) {
 	return 0, false
 }
 
+// AreCompatibleVersions says if a connection can be switched from one version to the other
+// using compatible version negotiation (RFC 9368).
+// QUIC v1 and QUIC v2 are compatible with each other, see section 4 of RFC 9369.
+func AreCompatibleVersions(a, b Version) bool {
+	if a == b {
+		return true
+	}
+	return (a == Version1 || a == Version2) && (b == Version1 || b == Version2)
+}
+
 var (
 	versionNegotiationMx   sync.Mutex
 	versionNegotiationRand = rand.New(rand.NewSource(uint64(time.Now().UnixNano())))
@@ -95,6 +95,14 @@ func TestVersionSelection(t *testing.T) {
 
 func isReservedVersion(v Version) bool { return v&0x0f0f0f0f == 0x0a0a0a0a }
 
+func TestCompatibleVersions(t *testing.T) {
+	require.True(t, AreCompatibleVersions(Version1, Version1))
+	require.True(t, AreCompatibleVersions(Version1, Version2))
+	require.True(t, AreCompatibleVersions(Version2, Version1))
+	require.False(t, AreCompatibleVersions(Version1, 0x1337))
+	require.False(t, AreCompatibleVersions(0x1337, Version2))
+}
+
 func TestAddGreasedVersionToEmptySlice(t *testing.T) {
 	greased := GetGreasedVersions([]Version{})
 	require.Len(t, greased, 1)
@@ -29,6 +29,10 @@ const (
 	NoViablePathError         TransportErrorCode = 0x10
 )
 
+// VersionNegotiationErrorCode is the error code defined by RFC 9368.
+// It is used when compatible version negotiation fails.
+const VersionNegotiationErrorCode TransportErrorCode = 0x11
+
 func (e TransportErrorCode) IsCryptoError() bool {
 	return e >= 0x100 && e < 0x200
 }
@@ -78,6 +82,8 @@ func (e TransportErrorCode) String() string {
 		return "AEAD_LIMIT_REACHED"
 	case NoViablePathError:
 		return "NO_VIABLE_PATH"
+	case VersionNegotiationErrorCode:
+		return "VERSION_NEGOTIATION_ERROR"
 	default:
 		if e.IsCryptoError() {
 			return fmt.Sprintf("CRYPTO_ERROR %#x", uint16(e))
@@ -0,0 +1,21 @@
+//go:build go1.25
+
+package qtls
+
+import (
+	"crypto/tls"
+	"net"
+)
+
+// setupECHKeysCallback sets the Conn field on the tls.ClientHelloInfo passed to
+// tls.Config.GetEncryptedClientHelloKeys, see SetupConfigForServer.
+func setupECHKeysCallback(conf *tls.Config, localAddr, remoteAddr net.Addr) {
+	if conf.GetEncryptedClientHelloKeys == nil {
+		return
+	}
+	gk := conf.GetEncryptedClientHelloKeys
+	conf.GetEncryptedClientHelloKeys = func(info *tls.ClientHelloInfo) ([]tls.EncryptedClientHelloKey, error) {
+		info.Conn = &conn{localAddr: localAddr, remoteAddr: remoteAddr}
+		return gk(info)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build go1.25
+
+package qtls
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerConfigGetEncryptedClientHelloKeys(t *testing.T) {
+	local := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42}
+	remote := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+
+	var localAddr, remoteAddr net.Addr
+	tlsConf := &tls.Config{
+		GetEncryptedClientHelloKeys: func(info *tls.ClientHelloInfo) ([]tls.EncryptedClientHelloKey, error) {
+			localAddr = info.Conn.LocalAddr()
+			remoteAddr = info.Conn.RemoteAddr()
+			return []tls.EncryptedClientHelloKey{}, nil
+		},
+	}
+	conf := SetupConfigForServer(tlsConf, local, remote, nil, nil)
+	_, err := conf.GetEncryptedClientHelloKeys(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Equal(t, local, localAddr)
+	require.Equal(t, remote, remoteAddr)
+}
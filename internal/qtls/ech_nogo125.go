@@ -0,0 +1,11 @@
+//go:build !go1.25
+
+package qtls
+
+import (
+	"crypto/tls"
+	"net"
+)
+
+// tls.Config.GetEncryptedClientHelloKeys was added in Go 1.25.
+func setupECHKeysCallback(*tls.Config, net.Addr, net.Addr) {}
@@ -13,7 +13,7 @@ func SetupConfigForServer(
 	conf *tls.Config,
 	localAddr, remoteAddr net.Addr,
 	getData func() []byte,
-	handleSessionTicket func([]byte, bool) bool,
+	handleSessionTicket func(data []byte, earlyData bool, alpn string) bool,
 ) *tls.Config {
 	// Workaround for https://github.com/golang/go/issues/60506.
 	// This initializes the session tickets _before_ cloning the config.
@@ -54,14 +54,14 @@ func SetupConfigForServer(
 
 		extra := findExtraData(state.Extra)
 		if extra != nil {
-			state.EarlyData = handleSessionTicket(extra, state.EarlyData && unwrapCount == 1)
+			state.EarlyData = handleSessionTicket(extra, state.EarlyData && unwrapCount == 1, connState.NegotiatedProtocol)
 		} else {
 			state.EarlyData = false
 		}
 
 		return state, nil
 	}
-	// The tls.Config contains two callbacks that pass in a tls.ClientHelloInfo.
+	// The tls.Config contains multiple callbacks that pass in a tls.ClientHelloInfo.
 	// Since crypto/tls doesn't do it, we need to make sure to set the Conn field with a fake net.Conn
 	// that allows the caller to get the local and the remote address.
 	if conf.GetConfigForClient != nil {
@@ -83,6 +83,7 @@ func SetupConfigForServer(
 			return gc(info)
 		}
 	}
+	setupECHKeysCallback(conf, localAddr, remoteAddr)
 	return conf
 }
 
@@ -116,3 +116,24 @@ func TestRTTMeasurementAfterRestore(t *testing.T) {
 	require.Equal(t, rtt, rttStats.LatestRTT())
 	require.Equal(t, rtt, rttStats.SmoothedRTT())
 }
+
+func TestRTTStatsResetForPathMigration(t *testing.T) {
+	var rttStats RTTStats
+	rttStats.SetMaxAckDelay(10 * time.Millisecond)
+	rttStats.UpdateRTT(time.Second, 0, time.Now())
+	rttStats.UpdateRTT(2*time.Second, 0, time.Now())
+	require.NotZero(t, rttStats.MinRTT())
+	require.NotZero(t, rttStats.SmoothedRTT())
+
+	rttStats.ResetForPathMigration()
+	require.Zero(t, rttStats.MinRTT())
+	require.Zero(t, rttStats.LatestRTT())
+	require.Zero(t, rttStats.SmoothedRTT())
+	require.Zero(t, rttStats.MeanDeviation())
+	// make sure that the max_ack_delay was not reset
+	require.Equal(t, 10*time.Millisecond, rttStats.MaxAckDelay())
+
+	rttStats.UpdateRTT(10*time.Millisecond, 0, time.Now())
+	require.Equal(t, 10*time.Millisecond, rttStats.LatestRTT())
+	require.Equal(t, 10*time.Millisecond, rttStats.SmoothedRTT())
+}
@@ -0,0 +1,59 @@
+package wire
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAckFrequency(t *testing.T) {
+	data := encodeVarInt(0x1337)                // sequence number
+	data = append(data, encodeVarInt(10)...)    // ack-eliciting threshold
+	data = append(data, encodeVarInt(12345)...) // request max ack delay
+	data = append(data, encodeVarInt(3)...)     // reordering threshold
+	frame, l, err := parseAckFrequencyFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, &AckFrequencyFrame{
+		SequenceNumber:        0x1337,
+		AckElicitingThreshold: 10,
+		RequestMaxAckDelay:    12345 * time.Microsecond,
+		ReorderingThreshold:   3,
+	}, frame)
+	require.Equal(t, len(data), l)
+}
+
+func TestParseAckFrequencyErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(0x1337)
+	data = append(data, encodeVarInt(10)...)
+	data = append(data, encodeVarInt(12345)...)
+	data = append(data, encodeVarInt(3)...)
+	_, l, err := parseAckFrequencyFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parseAckFrequencyFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWriteAckFrequency(t *testing.T) {
+	frame := &AckFrequencyFrame{
+		SequenceNumber:        0xdeadbeef,
+		AckElicitingThreshold: 0x42,
+		RequestMaxAckDelay:    25 * time.Millisecond,
+		ReorderingThreshold:   1,
+	}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := encodeVarInt(ackFrequencyFrameType)
+	expected = append(expected, encodeVarInt(0xdeadbeef)...)
+	expected = append(expected, encodeVarInt(0x42)...)
+	expected = append(expected, encodeVarInt(25000)...)
+	expected = append(expected, encodeVarInt(1)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
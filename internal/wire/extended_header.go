@@ -28,6 +28,10 @@ type ExtendedHeader struct {
 	PacketNumberLen protocol.PacketNumberLen
 	PacketNumber    protocol.PacketNumber
 
+	// ClearQUICBit makes Append set the QUIC bit to 0.
+	// This is only allowed if the peer advertised support for greasing the QUIC bit (RFC 9287).
+	ClearQUICBit bool
+
 	parsedLen protocol.ByteCount
 }
 
@@ -86,6 +90,9 @@ func (h *ExtendedHeader) Append(b []byte, v protocol.Version) ([]byte, error) {
 		}
 	}
 	firstByte := 0xc0 | packetType<<4
+	if h.ClearQUICBit {
+		firstByte &^= 0x40
+	}
 	if h.Type != protocol.PacketTypeRetry {
 		// Retry packets don't have a packet number
 		firstByte |= uint8(h.PacketNumberLen - 1)
@@ -53,6 +53,34 @@ func TestWritesHandshakePacketVersion2(t *testing.T) {
 	require.Equal(t, protocol.ByteCount(len(b)), header.GetLength(protocol.Version2))
 }
 
+func TestWritesHeaderClearingQUICBit(t *testing.T) {
+	header := &ExtendedHeader{
+		Header: Header{
+			Version:          protocol.Version1,
+			Type:             protocol.PacketTypeHandshake,
+			DestConnectionID: protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
+			SrcConnectionID:  protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
+			Length:           4,
+		},
+		PacketNumber:    0xdecafbad,
+		PacketNumberLen: protocol.PacketNumberLen4,
+		ClearQUICBit:    true,
+	}
+	b, err := header.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x80|0b10<<4|0x3), b[0])
+
+	_, _, _, err = ParsePacket(b, false)
+	require.EqualError(t, err, "not a QUIC packet")
+	hdr, _, _, err := ParsePacket(b, true)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PacketTypeHandshake, hdr.Type)
+	require.Equal(t, protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}), hdr.DestConnectionID)
+	extHdr, err := hdr.ParseExtended(b)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PacketNumber(0xdecafbad), extHdr.PacketNumber)
+}
+
 func TestWritesHeaderWith20ByteConnectionID(t *testing.T) {
 	srcConnID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
 	header := &ExtendedHeader{
@@ -258,7 +286,7 @@ func BenchmarkParseExtendedHeader(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		hdr, _, _, err := ParsePacket(data)
+		hdr, _, _, err := ParsePacket(data, false)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -36,10 +36,34 @@ const (
 	handshakeDoneFrameType      = 0x1e
 )
 
+// frame types defined by the multipath extension, see draft-ietf-quic-multipath-07
+const (
+	pathAckFrameType              = 0x15228c00
+	pathAckECNFrameType           = 0x15228c01
+	pathAbandonFrameType          = 0x15228c05
+	pathStandbyFrameType          = 0x15228c07
+	pathAvailableFrameType        = 0x15228c08
+	mpNewConnectionIDFrameType    = 0x15228c09
+	mpRetireConnectionIDFrameType = 0x15228c0a
+	maxPathIDFrameType            = 0x15228c0c
+)
+
+// frame type defined by draft-ietf-quic-reliable-stream-reset-06
+const resetStreamAtFrameType = 0x24
+
+// frame types defined by draft-ietf-quic-ack-frequency-10
+const (
+	ackFrequencyFrameType = 0xaf
+	immediateAckFrameType = 0x1f
+)
+
 // The FrameParser parses QUIC frames, one by one.
 type FrameParser struct {
-	ackDelayExponent  uint8
-	supportsDatagrams bool
+	ackDelayExponent      uint8
+	supportsDatagrams     bool
+	supportsResetStreamAt bool
+	supportsAckFrequency  bool
+	supportsMultipath     bool
 
 	// To avoid allocating when parsing, keep a single ACK frame struct.
 	// It is used over and over again.
@@ -47,10 +71,12 @@ type FrameParser struct {
 }
 
 // NewFrameParser creates a new frame parser.
-func NewFrameParser(supportsDatagrams bool) *FrameParser {
+func NewFrameParser(supportsDatagrams, supportsResetStreamAt, supportsAckFrequency bool) *FrameParser {
 	return &FrameParser{
-		supportsDatagrams: supportsDatagrams,
-		ackFrame:          &AckFrame{},
+		supportsDatagrams:     supportsDatagrams,
+		supportsResetStreamAt: supportsResetStreamAt,
+		supportsAckFrequency:  supportsAckFrequency,
+		ackFrame:              &AckFrame{},
 	}
 }
 
@@ -95,7 +121,7 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 	var frame Frame
 	var err error
 	var l int
-	if typ&0xf8 == 0x8 {
+	if typ&^0x7 == 0x8 { // STREAM frames use the frame types 0x08 to 0x0f
 		frame, l, err = parseStreamFrame(b, typ, v)
 	} else {
 		switch typ {
@@ -110,7 +136,7 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 			l, err = parseAckFrame(p.ackFrame, b, typ, ackDelayExponent, v)
 			frame = p.ackFrame
 		case resetStreamFrameType:
-			frame, l, err = parseResetStreamFrame(b, v)
+			frame, l, err = parseResetStreamFrame(b, false, v)
 		case stopSendingFrameType:
 			frame, l, err = parseStopSendingFrame(b, v)
 		case cryptoFrameType:
@@ -141,6 +167,24 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 			frame, l, err = parseConnectionCloseFrame(b, typ, v)
 		case handshakeDoneFrameType:
 			frame = &HandshakeDoneFrame{}
+		case resetStreamAtFrameType:
+			if !p.supportsResetStreamAt {
+				err = errors.New("unknown frame type")
+				break
+			}
+			frame, l, err = parseResetStreamFrame(b, true, v)
+		case ackFrequencyFrameType:
+			if !p.supportsAckFrequency {
+				err = errors.New("unknown frame type")
+				break
+			}
+			frame, l, err = parseAckFrequencyFrame(b, v)
+		case immediateAckFrameType:
+			if !p.supportsAckFrequency {
+				err = errors.New("unknown frame type")
+				break
+			}
+			frame = &ImmediateAckFrame{}
 		case 0x30, 0x31:
 			if p.supportsDatagrams {
 				frame, l, err = parseDatagramFrame(b, typ, v)
@@ -148,6 +192,10 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 			}
 			fallthrough
 		default:
+			if p.supportsMultipath {
+				frame, l, err = p.parseMultipathFrame(b, typ, v)
+				break
+			}
 			err = errors.New("unknown frame type")
 		}
 	}
@@ -160,6 +208,27 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 	return frame, l, nil
 }
 
+func (p *FrameParser) parseMultipathFrame(b []byte, typ uint64, v protocol.Version) (Frame, int, error) {
+	switch typ {
+	case pathAckFrameType, pathAckECNFrameType:
+		return parsePathAckFrame(b, typ, p.ackDelayExponent, v)
+	case pathAbandonFrameType:
+		return parsePathAbandonFrame(b, v)
+	case pathStandbyFrameType:
+		return parsePathStandbyFrame(b, v)
+	case pathAvailableFrameType:
+		return parsePathAvailableFrame(b, v)
+	case mpNewConnectionIDFrameType:
+		return parseMPNewConnectionIDFrame(b, v)
+	case mpRetireConnectionIDFrameType:
+		return parseMPRetireConnectionIDFrame(b, v)
+	case maxPathIDFrameType:
+		return parseMaxPathIDFrame(b, v)
+	default:
+		return nil, 0, errors.New("unknown frame type")
+	}
+}
+
 func (p *FrameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionLevel) bool {
 	switch encLevel {
 	case protocol.EncryptionInitial, protocol.EncryptionHandshake:
@@ -171,7 +240,8 @@ func (p *FrameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionL
 		}
 	case protocol.Encryption0RTT:
 		switch f.(type) {
-		case *CryptoFrame, *AckFrame, *ConnectionCloseFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
+		case *CryptoFrame, *AckFrame, *ConnectionCloseFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame,
+			*PathAckFrame, *PathAbandonFrame, *PathStandbyFrame, *PathAvailableFrame, *MPNewConnectionIDFrame, *MPRetireConnectionIDFrame, *MaxPathIDFrame:
 			return false
 		default:
 			return true
@@ -189,6 +259,12 @@ func (p *FrameParser) SetAckDelayExponent(exp uint8) {
 	p.ackDelayExponent = exp
 }
 
+// SetSupportsMultipath enables parsing of the frames defined by the multipath extension.
+// It is called once the use of the extension has been negotiated.
+func (p *FrameParser) SetSupportsMultipath() {
+	p.supportsMultipath = true
+}
+
 func replaceUnexpectedEOF(e error) error {
 	if e == io.ErrUnexpectedEOF {
 		return io.EOF
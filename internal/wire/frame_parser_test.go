@@ -14,7 +14,7 @@ import (
 )
 
 func TestFrameParsingReturnsNilWhenNothingToRead(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	l, f, err := parser.ParseNext(nil, protocol.Encryption1RTT, protocol.Version1)
 	require.NoError(t, err)
 	require.Zero(t, l)
@@ -22,7 +22,7 @@ func TestFrameParsingReturnsNilWhenNothingToRead(t *testing.T) {
 }
 
 func TestFrameParsingSkipsPaddingFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	b := []byte{0, 0} // 2 PADDING frames
 	b, err := (&PingFrame{}).Append(b, protocol.Version1)
 	require.NoError(t, err)
@@ -33,7 +33,7 @@ func TestFrameParsingSkipsPaddingFrames(t *testing.T) {
 }
 
 func TestFrameParsingHandlesPaddingAtEnd(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	l, f, err := parser.ParseNext([]byte{0, 0, 0}, protocol.Encryption1RTT, protocol.Version1)
 	require.NoError(t, err)
 	require.Nil(t, f)
@@ -41,7 +41,7 @@ func TestFrameParsingHandlesPaddingAtEnd(t *testing.T) {
 }
 
 func TestFrameParsingParsesSingleFrame(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	var b []byte
 	for i := 0; i < 10; i++ {
 		var err error
@@ -55,7 +55,7 @@ func TestFrameParsingParsesSingleFrame(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksAckFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 0x13}}}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -68,7 +68,7 @@ func TestFrameParsingUnpacksAckFrames(t *testing.T) {
 }
 
 func TestFrameParsingUsesCustomAckDelayExponentFor1RTTPackets(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	parser.SetAckDelayExponent(protocol.AckDelayExponent + 2)
 	f := &AckFrame{
 		AckRanges: []AckRange{{Smallest: 1, Largest: 1}},
@@ -82,7 +82,7 @@ func TestFrameParsingUsesCustomAckDelayExponentFor1RTTPackets(t *testing.T) {
 }
 
 func TestFrameParsingUsesDefaultAckDelayExponentForNon1RTTPackets(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	parser.SetAckDelayExponent(protocol.AckDelayExponent + 2)
 	f := &AckFrame{
 		AckRanges: []AckRange{{Smallest: 1, Largest: 1}},
@@ -96,7 +96,7 @@ func TestFrameParsingUsesDefaultAckDelayExponentForNon1RTTPackets(t *testing.T)
 }
 
 func TestFrameParsingUnpacksResetStreamFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &ResetStreamFrame{
 		StreamID:  0xdeadbeef,
 		FinalSize: 0xdecafbad1234,
@@ -110,8 +110,72 @@ func TestFrameParsingUnpacksResetStreamFrames(t *testing.T) {
 	require.Equal(t, len(b), l)
 }
 
+func TestFrameParsingUnpacksResetStreamAtFrames(t *testing.T) {
+	parser := NewFrameParser(true, true, true)
+	f := &ResetStreamFrame{
+		StreamID:     0xdeadbeef,
+		FinalSize:    0xdecafbad1234,
+		ErrorCode:    0x1337,
+		ReliableSize: 0x42,
+	}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, f, frame)
+	require.Equal(t, len(b), l)
+}
+
+func TestFrameParsingErrorsWhenResetStreamAtIsNotSupported(t *testing.T) {
+	parser := NewFrameParser(true, false, false)
+	f := &ResetStreamFrame{StreamID: 0xdeadbeef, FinalSize: 0x100, ReliableSize: 0x42}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+	var transportErr *qerr.TransportError
+	require.ErrorAs(t, err, &transportErr)
+	require.Equal(t, qerr.FrameEncodingError, transportErr.ErrorCode)
+	require.Equal(t, uint64(resetStreamAtFrameType), transportErr.FrameType)
+	require.Equal(t, "unknown frame type", transportErr.ErrorMessage)
+}
+
+func TestFrameParsingUnpacksAckFrequencyFrames(t *testing.T) {
+	parser := NewFrameParser(true, true, true)
+	f := &AckFrequencyFrame{
+		SequenceNumber:        0x1337,
+		AckElicitingThreshold: 10,
+		RequestMaxAckDelay:    5 * time.Millisecond,
+		ReorderingThreshold:   2,
+	}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	b, err = (&ImmediateAckFrame{}).Append(b, protocol.Version1)
+	require.NoError(t, err)
+	l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, f, frame)
+	require.Equal(t, int(f.Length(protocol.Version1)), l)
+	l, frame, err = parser.ParseNext(b[l:], protocol.Encryption1RTT, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, &ImmediateAckFrame{}, frame)
+	require.Equal(t, 1, l)
+}
+
+func TestFrameParsingErrorsWhenAckFrequencyIsNotSupported(t *testing.T) {
+	parser := NewFrameParser(true, true, false)
+	for _, f := range []Frame{&AckFrequencyFrame{AckElicitingThreshold: 1}, &ImmediateAckFrame{}} {
+		b, err := f.Append(nil, protocol.Version1)
+		require.NoError(t, err)
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		var transportErr *qerr.TransportError
+		require.ErrorAs(t, err, &transportErr)
+		require.Equal(t, qerr.FrameEncodingError, transportErr.ErrorCode)
+		require.Equal(t, "unknown frame type", transportErr.ErrorMessage)
+	}
+}
+
 func TestFrameParsingUnpacksStopSendingFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &StopSendingFrame{StreamID: 0x42}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -122,7 +186,7 @@ func TestFrameParsingUnpacksStopSendingFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksCryptoFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &CryptoFrame{
 		Offset: 0x1337,
 		Data:   []byte("lorem ipsum"),
@@ -137,7 +201,7 @@ func TestFrameParsingUnpacksCryptoFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksNewTokenFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &NewTokenFrame{Token: []byte("foobar")}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -149,7 +213,7 @@ func TestFrameParsingUnpacksNewTokenFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksStreamFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &StreamFrame{
 		StreamID: 0x42,
 		Offset:   0x1337,
@@ -166,7 +230,7 @@ func TestFrameParsingUnpacksStreamFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksMaxDataFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &MaxDataFrame{MaximumData: 0xcafe}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -177,7 +241,7 @@ func TestFrameParsingUnpacksMaxDataFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksMaxStreamDataFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &MaxStreamDataFrame{
 		StreamID:          0xdeadbeef,
 		MaximumStreamData: 0xdecafbad,
@@ -191,7 +255,7 @@ func TestFrameParsingUnpacksMaxStreamDataFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksMaxStreamsFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &MaxStreamsFrame{
 		Type:         protocol.StreamTypeBidi,
 		MaxStreamNum: 0x1337,
@@ -205,7 +269,7 @@ func TestFrameParsingUnpacksMaxStreamsFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksDataBlockedFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &DataBlockedFrame{MaximumData: 0x1234}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -216,7 +280,7 @@ func TestFrameParsingUnpacksDataBlockedFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksStreamDataBlockedFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &StreamDataBlockedFrame{
 		StreamID:          0xdeadbeef,
 		MaximumStreamData: 0xdead,
@@ -230,7 +294,7 @@ func TestFrameParsingUnpacksStreamDataBlockedFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksStreamsBlockedFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &StreamsBlockedFrame{
 		Type:        protocol.StreamTypeBidi,
 		StreamLimit: 0x1234567,
@@ -244,7 +308,7 @@ func TestFrameParsingUnpacksStreamsBlockedFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksNewConnectionIDFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &NewConnectionIDFrame{
 		SequenceNumber:      0x1337,
 		ConnectionID:        protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
@@ -259,7 +323,7 @@ func TestFrameParsingUnpacksNewConnectionIDFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksRetireConnectionIDFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &RetireConnectionIDFrame{SequenceNumber: 0x1337}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -270,7 +334,7 @@ func TestFrameParsingUnpacksRetireConnectionIDFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksPathChallengeFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -283,7 +347,7 @@ func TestFrameParsingUnpacksPathChallengeFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksPathResponseFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -296,7 +360,7 @@ func TestFrameParsingUnpacksPathResponseFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksConnectionCloseFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &ConnectionCloseFrame{
 		IsApplicationError: true,
 		ReasonPhrase:       "foobar",
@@ -310,7 +374,7 @@ func TestFrameParsingUnpacksConnectionCloseFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksHandshakeDoneFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &HandshakeDoneFrame{}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -321,7 +385,7 @@ func TestFrameParsingUnpacksHandshakeDoneFrames(t *testing.T) {
 }
 
 func TestFrameParsingUnpacksDatagramFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &DatagramFrame{Data: []byte("foobar")}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -332,7 +396,7 @@ func TestFrameParsingUnpacksDatagramFrames(t *testing.T) {
 }
 
 func TestFrameParsingErrorsWhenDatagramFramesAreNotSupported(t *testing.T) {
-	parser := NewFrameParser(false)
+	parser := NewFrameParser(false, false, false)
 	f := &DatagramFrame{Data: []byte("foobar")}
 	b, err := f.Append(nil, protocol.Version1)
 	require.NoError(t, err)
@@ -345,8 +409,52 @@ func TestFrameParsingErrorsWhenDatagramFramesAreNotSupported(t *testing.T) {
 	require.Equal(t, "unknown frame type", transportErr.ErrorMessage)
 }
 
+func TestFrameParsingUnpacksMultipathFrames(t *testing.T) {
+	parser := NewFrameParser(false, false, false)
+	parser.SetSupportsMultipath()
+	for _, f := range []Frame{
+		&PathAckFrame{PathID: 1, AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 10}}}},
+		&PathAbandonFrame{PathID: 2, ErrorCode: 42, ReasonPhrase: "foo"},
+		&PathStandbyFrame{PathID: 3, SequenceNumber: 1},
+		&PathAvailableFrame{PathID: 3, SequenceNumber: 2},
+		&MPNewConnectionIDFrame{
+			PathID: 1,
+			NewConnectionIDFrame: NewConnectionIDFrame{
+				SequenceNumber: 2,
+				ConnectionID:   protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+			},
+		},
+		&MPRetireConnectionIDFrame{PathID: 2, SequenceNumber: 3},
+		&MaxPathIDFrame{MaxPathID: 10},
+	} {
+		b, err := f.Append(nil, protocol.Version1)
+		require.NoError(t, err)
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		require.NoError(t, err)
+		require.Equal(t, f, frame)
+		require.Equal(t, len(b), l)
+		// multipath frames are not allowed in 0-RTT packets
+		_, _, err = parser.ParseNext(b, protocol.Encryption0RTT, protocol.Version1)
+		require.Error(t, err)
+	}
+}
+
+func TestFrameParsingErrorsWhenMultipathIsNotSupported(t *testing.T) {
+	parser := NewFrameParser(true, true, true)
+	f := &MaxPathIDFrame{MaxPathID: 10}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+	require.Error(t, err)
+	var transportErr *qerr.TransportError
+	require.ErrorAs(t, err, &transportErr)
+	require.Equal(t, qerr.FrameEncodingError, transportErr.ErrorCode)
+	require.Equal(t, uint64(maxPathIDFrameType), transportErr.FrameType)
+	require.Equal(t, "unknown frame type", transportErr.ErrorMessage)
+}
+
 func TestFrameParsingErrorsOnInvalidType(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	_, _, err := parser.ParseNext(encodeVarInt(0x42), protocol.Encryption1RTT, protocol.Version1)
 	require.Error(t, err)
 	var transportErr *qerr.TransportError
@@ -357,7 +465,7 @@ func TestFrameParsingErrorsOnInvalidType(t *testing.T) {
 }
 
 func TestFrameParsingErrorsOnInvalidFrames(t *testing.T) {
-	parser := NewFrameParser(true)
+	parser := NewFrameParser(true, true, true)
 	f := &MaxStreamDataFrame{
 		StreamID:          0x1337,
 		MaximumStreamData: 0xdeadbeef,
@@ -400,7 +508,7 @@ func BenchmarkParseStreamAndACK(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	parser := NewFrameParser(false)
+	parser := NewFrameParser(false, false, false)
 	parser.SetAckDelayExponent(3)
 
 	b.ResetTimer()
@@ -458,7 +566,7 @@ func BenchmarkParseOtherFrames(b *testing.B) {
 		}
 	}
 
-	parser := NewFrameParser(false)
+	parser := NewFrameParser(false, false, false)
 
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -109,6 +109,27 @@ func Is0RTTPacket(b []byte) bool {
 	}
 }
 
+// IsHandshakePacket says if this is a Handshake packet.
+// A packet sent with a version we don't understand can never be a Handshake packet.
+func IsHandshakePacket(b []byte) bool {
+	if len(b) < 5 {
+		return false
+	}
+	if !IsLongHeaderPacket(b[0]) {
+		return false
+	}
+	version := protocol.Version(binary.BigEndian.Uint32(b[1:5]))
+	//nolint:exhaustive // We only need to test QUIC versions that we support.
+	switch version {
+	case protocol.Version1:
+		return b[0]>>4&0b11 == 0b10
+	case protocol.Version2:
+		return b[0]>>4&0b11 == 0b11
+	default:
+		return false
+	}
+}
+
 var ErrUnsupportedVersion = errors.New("unsupported version")
 
 // The Header is the version independent part of the header
@@ -131,11 +152,13 @@ type Header struct {
 // The packet is cut according to the length field.
 // If we understand the version, the packet is parsed up unto the packet number.
 // Otherwise, only the invariant part of the header is parsed.
-func ParsePacket(data []byte) (*Header, []byte, []byte, error) {
+// Packets that have the QUIC bit set to 0 are only accepted if acceptZeroQUICBit is set,
+// which is the case once support for greasing the QUIC bit was advertised (RFC 9287).
+func ParsePacket(data []byte, acceptZeroQUICBit bool) (*Header, []byte, []byte, error) {
 	if len(data) == 0 || !IsLongHeaderPacket(data[0]) {
 		return nil, nil, nil, errors.New("not a long header packet")
 	}
-	hdr, err := parseHeader(data)
+	hdr, err := parseHeader(data, acceptZeroQUICBit)
 	if err != nil {
 		if errors.Is(err, ErrUnsupportedVersion) {
 			return hdr, nil, nil, err
@@ -152,25 +175,25 @@ func ParsePacket(data []byte) (*Header, []byte, []byte, error) {
 // ParseHeader parses the header:
 // * if we understand the version: up to the packet number
 // * if not, only the invariant part of the header
-func parseHeader(b []byte) (*Header, error) {
+func parseHeader(b []byte, acceptZeroQUICBit bool) (*Header, error) {
 	if len(b) == 0 {
 		return nil, io.EOF
 	}
 	typeByte := b[0]
 
 	h := &Header{typeByte: typeByte}
-	l, err := h.parseLongHeader(b[1:])
+	l, err := h.parseLongHeader(b[1:], acceptZeroQUICBit)
 	h.parsedLen = protocol.ByteCount(l) + 1
 	return h, err
 }
 
-func (h *Header) parseLongHeader(b []byte) (int, error) {
+func (h *Header) parseLongHeader(b []byte, acceptZeroQUICBit bool) (int, error) {
 	startLen := len(b)
 	if len(b) < 5 {
 		return 0, io.EOF
 	}
 	h.Version = protocol.Version(binary.BigEndian.Uint32(b[:4]))
-	if h.Version != 0 && h.typeByte&0x40 == 0 {
+	if h.Version != 0 && h.typeByte&0x40 == 0 && !acceptZeroQUICBit {
 		return startLen - len(b), errors.New("not a QUIC packet")
 	}
 	destConnIDLen := int(b[4])
@@ -89,6 +89,28 @@ func TestIs0RTT(t *testing.T) {
 	})
 }
 
+func TestIsHandshakePacket(t *testing.T) {
+	for _, tc := range []struct {
+		version  protocol.Version
+		typeBits byte
+	}{
+		{version: protocol.Version1, typeBits: 0b10},
+		{version: protocol.Version2, typeBits: 0b11},
+	} {
+		t.Run(tc.version.String(), func(t *testing.T) {
+			hdr := make([]byte, 5)
+			hdr[0] = 0x80 | tc.typeBits<<4
+			binary.BigEndian.PutUint32(hdr[1:], uint32(tc.version))
+
+			require.True(t, IsHandshakePacket(hdr))
+			require.False(t, IsHandshakePacket(hdr[:4]))                            // too short
+			require.False(t, IsHandshakePacket([]byte{hdr[0], 1, 2, 3, 4}))         // unknown version
+			require.False(t, IsHandshakePacket([]byte{hdr[0] &^ 0x80, 1, 2, 3, 4})) // short header
+			require.False(t, IsHandshakePacket([]byte{hdr[0] ^ 0b01<<4, hdr[1], hdr[2], hdr[3], hdr[4]}))
+		})
+	}
+}
+
 func TestParseVersion(t *testing.T) {
 	b := []byte{0x80, 0xde, 0xad, 0xbe, 0xef}
 	v, err := ParseVersion(b)
@@ -163,7 +185,7 @@ func TestParseLongHeader(t *testing.T) {
 	data = append(data, []byte("foobar")...)
 	require.False(t, IsVersionNegotiationPacket(data))
 
-	hdr, pdata, rest, err := ParsePacket(data)
+	hdr, pdata, rest, err := ParsePacket(data, false)
 	require.NoError(t, err)
 	require.Equal(t, data, pdata)
 	require.Equal(t, destConnID, hdr.DestConnectionID)
@@ -188,7 +210,7 @@ func TestErrorIfReservedBitNotSet(t *testing.T) {
 		0xde, 0xca, 0xfb, 0xad, // dest conn ID
 		0xde, 0xad, 0xbe, 0xef, // src conn ID
 	}
-	_, _, _, err := ParsePacket(data)
+	_, _, _, err := ParsePacket(data, false)
 	require.EqualError(t, err, "not a QUIC packet")
 }
 
@@ -202,7 +224,7 @@ func TestStopParsingWhenEncounteringUnsupportedVersion(t *testing.T) {
 		0x8, 0x7, 0x6, 0x5, 0x4, 0x3, 0x2, 0x1, // src conn ID
 		'f', 'o', 'o', 'b', 'a', 'r', // unspecified bytes
 	}
-	hdr, _, rest, err := ParsePacket(data)
+	hdr, _, rest, err := ParsePacket(data, false)
 	require.EqualError(t, err, ErrUnsupportedVersion.Error())
 	require.Equal(t, protocol.Version(0xdeadbeef), hdr.Version)
 	require.Equal(t, protocol.ParseConnectionID([]byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8}), hdr.DestConnectionID)
@@ -218,7 +240,7 @@ func TestParseLongHeaderWithoutDestinationConnectionID(t *testing.T) {
 	data = append(data, []byte{0xde, 0xad, 0xbe, 0xef}...) // source connection ID
 	data = append(data, encodeVarInt(0)...)                // length
 	data = append(data, []byte{0xde, 0xca, 0xfb, 0xad}...)
-	hdr, _, _, err := ParsePacket(data)
+	hdr, _, _, err := ParsePacket(data, false)
 	require.NoError(t, err)
 	require.Equal(t, protocol.PacketType0RTT, hdr.Type)
 	require.Equal(t, protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}), hdr.SrcConnectionID)
@@ -233,7 +255,7 @@ func TestParseLongHeaderWithoutSourceConnectionID(t *testing.T) {
 	data = append(data, 0)                                        // src conn ID len
 	data = append(data, encodeVarInt(0)...)                       // length
 	data = append(data, []byte{0xde, 0xca, 0xfb, 0xad}...)
-	hdr, _, _, err := ParsePacket(data)
+	hdr, _, _, err := ParsePacket(data, false)
 	require.NoError(t, err)
 	require.Zero(t, hdr.SrcConnectionID)
 	require.Equal(t, protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}), hdr.DestConnectionID)
@@ -247,7 +269,7 @@ func TestErrorOnTooLongDestinationConnectionID(t *testing.T) {
 	data = append(data, 0x0)                                                                                  // src conn ID len
 	data = append(data, encodeVarInt(0)...)                                                                   // length
 	data = append(data, []byte{0xde, 0xca, 0xfb, 0xad}...)
-	_, _, _, err := ParsePacket(data)
+	_, _, _, err := ParsePacket(data, false)
 	require.EqualError(t, err, protocol.ErrInvalidConnectionIDLen.Error())
 }
 
@@ -259,7 +281,7 @@ func TestParseLongHeaderWith2BytePacketNumber(t *testing.T) {
 	data = append(data, encodeVarInt(0)...)       // length
 	data = append(data, []byte{0x1, 0x23}...)
 
-	hdr, _, _, err := ParsePacket(data)
+	hdr, _, _, err := ParsePacket(data, false)
 	require.NoError(t, err)
 	extHdr, err := hdr.ParseExtended(data)
 	require.NoError(t, err)
@@ -285,7 +307,7 @@ func TestParseRetryPacket(t *testing.T) {
 			data = append(data, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}...) // source connection ID
 			data = append(data, []byte{'f', 'o', 'o', 'b', 'a', 'r'}...)  // token
 			data = append(data, []byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}...)
-			hdr, pdata, rest, err := ParsePacket(data)
+			hdr, pdata, rest, err := ParsePacket(data, false)
 			require.NoError(t, err)
 			require.Equal(t, protocol.PacketTypeRetry, hdr.Type)
 			require.Equal(t, version, hdr.Version)
@@ -305,7 +327,7 @@ func TestRetryPacketTooShortForIntegrityTag(t *testing.T) {
 	data = append(data, []byte{'f', 'o', 'o', 'b', 'a', 'r'}...) // token
 	data = append(data, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}...)
 	// this results in a token length of 0
-	_, _, _, err := ParsePacket(data)
+	_, _, _, err := ParsePacket(data, false)
 	require.Equal(t, io.EOF, err)
 }
 
@@ -317,7 +339,7 @@ func TestTokenLengthTooLarge(t *testing.T) {
 	data = append(data, encodeVarInt(0x42)...) // length, 1 byte
 	data = append(data, []byte{0x12, 0x34}...) // packet number
 
-	_, _, _, err := ParsePacket(data)
+	_, _, _, err := ParsePacket(data, false)
 	require.Equal(t, io.EOF, err)
 }
 
@@ -327,7 +349,7 @@ func TestErrorOn5thOr6thBitSet(t *testing.T) {
 	data = append(data, []byte{0x0, 0x0}...)   // connection ID lengths
 	data = append(data, encodeVarInt(2)...)    // length
 	data = append(data, []byte{0x12, 0x34}...) // packet number
-	hdr, _, _, err := ParsePacket(data)
+	hdr, _, _, err := ParsePacket(data, false)
 	require.NoError(t, err)
 	require.Equal(t, protocol.PacketTypeHandshake, hdr.Type)
 	extHdr, err := hdr.ParseExtended(data)
@@ -344,7 +366,7 @@ func TestHeaderEOF(t *testing.T) {
 	data = append(data, 0x8)                                                       // src conn ID len
 	data = append(data, []byte{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe, 0x13, 0x37}...) // src conn ID
 	for i := 1; i < len(data); i++ {
-		_, _, _, err := ParsePacket(data[:i])
+		_, _, _, err := ParsePacket(data[:i], false)
 		require.Equal(t, io.EOF, err)
 	}
 }
@@ -358,7 +380,7 @@ func TestParseExtendedHeaderEOF(t *testing.T) {
 	data = append(data, []byte{0xde, 0xad, 0xbe, 0xef}...) // packet number
 	for i := hdrLen; i < len(data); i++ {
 		b := data[:i]
-		hdr, _, _, err := ParsePacket(b)
+		hdr, _, _, err := ParsePacket(b, false)
 		require.NoError(t, err)
 		_, err = hdr.ParseExtended(b)
 		require.Equal(t, io.EOF, err)
@@ -374,7 +396,7 @@ func TestParseRetryEOF(t *testing.T) {
 	hdrLen := len(data)
 	for i := hdrLen; i < len(data); i++ {
 		data = data[:i]
-		hdr, _, _, err := ParsePacket(data)
+		hdr, _, _, err := ParsePacket(data, false)
 		require.NoError(t, err)
 		_, err = hdr.ParseExtended(data)
 		require.Equal(t, io.EOF, err)
@@ -397,7 +419,7 @@ func TestCoalescedPacketParsing(t *testing.T) {
 	hdrRaw := append([]byte{}, b...)
 	b = append(b, []byte("foobar")...) // payload of the first packet
 	b = append(b, []byte("raboof")...) // second packet
-	parsedHdr, data, rest, err := ParsePacket(b)
+	parsedHdr, data, rest, err := ParsePacket(b, false)
 	require.NoError(t, err)
 	require.Equal(t, hdr.Type, parsedHdr.Type)
 	require.Equal(t, hdr.DestConnectionID, parsedHdr.DestConnectionID)
@@ -417,7 +439,7 @@ func TestCoalescedPacketErrorOnTooSmallPacketNumber(t *testing.T) {
 		PacketNumberLen: 2,
 	}).Append(nil, protocol.Version1)
 	require.NoError(t, err)
-	_, _, _, err = ParsePacket(b)
+	_, _, _, err = ParsePacket(b, false)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "packet length (2 bytes) is smaller than the expected length (3 bytes)")
 }
@@ -435,7 +457,7 @@ func TestCoalescedPacketErrorOnTooSmallPayload(t *testing.T) {
 	}).Append(nil, protocol.Version1)
 	require.NoError(t, err)
 	b = append(b, make([]byte, 500-2 /* for packet number length */)...)
-	_, _, _, err = ParsePacket(b)
+	_, _, _, err = ParsePacket(b, false)
 	require.EqualError(t, err, "packet length (500 bytes) is smaller than the expected length (1000 bytes)")
 }
 
@@ -496,7 +518,7 @@ func benchmarkInitialPacketParsing(b *testing.B, token []byte) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		h, _, _, err := ParsePacket(data)
+		h, _, _, err := ParsePacket(data, false)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -527,7 +549,7 @@ func BenchmarkParseRetry(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		h, _, _, err := ParsePacket(data)
+		h, _, _, err := ParsePacket(data, false)
 		if err != nil {
 			b.Fatal(err)
 		}
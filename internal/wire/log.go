@@ -24,6 +24,10 @@ func LogFrame(logger utils.Logger, frame Frame, sent bool) {
 	case *StreamFrame:
 		logger.Debugf("\t%s &wire.StreamFrame{StreamID: %d, Fin: %t, Offset: %d, Data length: %d, Offset + Data length: %d}", dir, f.StreamID, f.Fin, f.Offset, f.DataLen(), f.Offset+f.DataLen())
 	case *ResetStreamFrame:
+		if f.ReliableSize > 0 {
+			logger.Debugf("\t%s &wire.ResetStreamFrame{StreamID: %d, ErrorCode: %#x, FinalSize: %d, ReliableSize: %d}", dir, f.StreamID, f.ErrorCode, f.FinalSize, f.ReliableSize)
+			break
+		}
 		logger.Debugf("\t%s &wire.ResetStreamFrame{StreamID: %d, ErrorCode: %#x, FinalSize: %d}", dir, f.StreamID, f.ErrorCode, f.FinalSize)
 	case *AckFrame:
 		hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
@@ -40,6 +44,8 @@ func LogFrame(logger utils.Logger, frame Frame, sent bool) {
 		} else {
 			logger.Debugf("\t%s &wire.AckFrame{LargestAcked: %d, LowestAcked: %d, DelayTime: %s%s}", dir, f.LargestAcked(), f.LowestAcked(), f.DelayTime.String(), ecn)
 		}
+	case *PathAckFrame:
+		logger.Debugf("\t%s &wire.PathAckFrame{PathID: %d, LargestAcked: %d, LowestAcked: %d, DelayTime: %s}", dir, f.PathID, f.LargestAcked(), f.LowestAcked(), f.DelayTime.String())
 	case *MaxDataFrame:
 		logger.Debugf("\t%s &wire.MaxDataFrame{MaximumData: %d}", dir, f.MaximumData)
 	case *MaxStreamDataFrame:
@@ -64,10 +70,14 @@ func LogFrame(logger utils.Logger, frame Frame, sent bool) {
 		}
 	case *NewConnectionIDFrame:
 		logger.Debugf("\t%s &wire.NewConnectionIDFrame{SequenceNumber: %d, RetirePriorTo: %d, ConnectionID: %s, StatelessResetToken: %#x}", dir, f.SequenceNumber, f.RetirePriorTo, f.ConnectionID, f.StatelessResetToken)
+	case *MPNewConnectionIDFrame:
+		logger.Debugf("\t%s &wire.MPNewConnectionIDFrame{PathID: %d, SequenceNumber: %d, RetirePriorTo: %d, ConnectionID: %s, StatelessResetToken: %#x}", dir, f.PathID, f.SequenceNumber, f.RetirePriorTo, f.ConnectionID, f.StatelessResetToken)
 	case *RetireConnectionIDFrame:
 		logger.Debugf("\t%s &wire.RetireConnectionIDFrame{SequenceNumber: %d}", dir, f.SequenceNumber)
 	case *NewTokenFrame:
 		logger.Debugf("\t%s &wire.NewTokenFrame{Token: %#x}", dir, f.Token)
+	case *AckFrequencyFrame:
+		logger.Debugf("\t%s &wire.AckFrequencyFrame{SequenceNumber: %d, AckElicitingThreshold: %d, RequestMaxAckDelay: %s, ReorderingThreshold: %d}", dir, f.SequenceNumber, f.AckElicitingThreshold, f.RequestMaxAckDelay, f.ReorderingThreshold)
 	default:
 		logger.Debugf("\t%s %#v", dir, frame)
 	}
@@ -33,6 +33,13 @@ func TestLogReceivedFrame(t *testing.T) {
 	require.Contains(t, buf.String(), "\t<- &wire.ResetStreamFrame{StreamID: 0, ErrorCode: 0x0, FinalSize: 0}\n")
 }
 
+func TestLogResetStreamAtFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := setupLogTest(t, buf)
+	LogFrame(logger, &ResetStreamFrame{StreamID: 4, FinalSize: 1000, ReliableSize: 100}, false)
+	require.Contains(t, buf.String(), "\t<- &wire.ResetStreamFrame{StreamID: 4, ErrorCode: 0x0, FinalSize: 1000, ReliableSize: 100}\n")
+}
+
 func TestLogCryptoFrame(t *testing.T) {
 	buf := &bytes.Buffer{}
 	logger := setupLogTest(t, buf)
@@ -95,6 +102,20 @@ func TestLogAckFrameWithMissingPackets(t *testing.T) {
 	require.Contains(t, buf.String(), "\t<- &wire.AckFrame{LargestAcked: 8, LowestAcked: 2, AckRanges: {{Largest: 8, Smallest: 5}, {Largest: 3, Smallest: 2}}, DelayTime: 12ms}\n")
 }
 
+func TestLogPathAckFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := setupLogTest(t, buf)
+	frame := &PathAckFrame{
+		PathID: 3,
+		AckFrame: AckFrame{
+			AckRanges: []AckRange{{Smallest: 42, Largest: 1337}},
+			DelayTime: 1 * time.Millisecond,
+		},
+	}
+	LogFrame(logger, frame, true)
+	require.Contains(t, buf.String(), "\t-> &wire.PathAckFrame{PathID: 3, LargestAcked: 1337, LowestAcked: 42, DelayTime: 1ms}\n")
+}
+
 func TestLogMaxStreamsFrame(t *testing.T) {
 	buf := &bytes.Buffer{}
 	logger := setupLogTest(t, buf)
@@ -171,6 +192,21 @@ func TestLogNewConnectionIDFrame(t *testing.T) {
 	require.Contains(t, buf.String(), "\t<- &wire.NewConnectionIDFrame{SequenceNumber: 42, RetirePriorTo: 24, ConnectionID: deadbeef, StatelessResetToken: 0x0102030405060708090a0b0c0d0e0f10}")
 }
 
+func TestLogMPNewConnectionIDFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := setupLogTest(t, buf)
+	LogFrame(logger, &MPNewConnectionIDFrame{
+		PathID: 2,
+		NewConnectionIDFrame: NewConnectionIDFrame{
+			SequenceNumber:      42,
+			RetirePriorTo:       24,
+			ConnectionID:        protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
+			StatelessResetToken: protocol.StatelessResetToken{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8, 0x9, 0xa, 0xb, 0xc, 0xd, 0xe, 0xf, 0x10},
+		},
+	}, false)
+	require.Contains(t, buf.String(), "\t<- &wire.MPNewConnectionIDFrame{PathID: 2, SequenceNumber: 42, RetirePriorTo: 24, ConnectionID: deadbeef, StatelessResetToken: 0x0102030405060708090a0b0c0d0e0f10}")
+}
+
 func TestLogRetireConnectionIDFrame(t *testing.T) {
 	buf := &bytes.Buffer{}
 	logger := setupLogTest(t, buf)
@@ -186,3 +222,15 @@ func TestLogNewTokenFrame(t *testing.T) {
 	}, true)
 	require.Contains(t, buf.String(), "\t-> &wire.NewTokenFrame{Token: 0xdeadbeef")
 }
+
+func TestLogAckFrequencyFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := setupLogTest(t, buf)
+	LogFrame(logger, &AckFrequencyFrame{
+		SequenceNumber:        3,
+		AckElicitingThreshold: 10,
+		RequestMaxAckDelay:    5 * time.Millisecond,
+		ReorderingThreshold:   1,
+	}, true)
+	require.Contains(t, buf.String(), "\t-> &wire.AckFrequencyFrame{SequenceNumber: 3, AckElicitingThreshold: 10, RequestMaxAckDelay: 5ms, ReorderingThreshold: 1}\n")
+}
@@ -0,0 +1,44 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaxPathID(t *testing.T) {
+	data := encodeVarInt(10)
+	frame, l, err := parseMaxPathIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(10), frame.MaxPathID)
+	require.Equal(t, len(data), l)
+}
+
+func TestParseMaxPathIDErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(0x1337)
+	_, l, err := parseMaxPathIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parseMaxPathIDFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestParseMaxPathIDInvalidPathID(t *testing.T) {
+	_, _, err := parseMaxPathIDFrame(encodeVarInt(uint64(protocol.MaxPathID)+1), protocol.Version1)
+	require.ErrorIs(t, err, errInvalidPathID)
+}
+
+func TestWriteMaxPathID(t *testing.T) {
+	frame := &MaxPathIDFrame{MaxPathID: 0xdeadbeef}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := encodeVarInt(maxPathIDFrameType)
+	expected = append(expected, encodeVarInt(0xdeadbeef)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
@@ -0,0 +1,47 @@
+package wire
+
+import (
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// An MPNewConnectionIDFrame is an MP_NEW_CONNECTION_ID frame.
+// It issues a connection ID for the path with the given path ID.
+type MPNewConnectionIDFrame struct {
+	PathID protocol.PathID
+	NewConnectionIDFrame
+}
+
+func parseMPNewConnectionIDFrame(b []byte, v protocol.Version) (*MPNewConnectionIDFrame, int, error) {
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, n, err := parseNewConnectionIDFrame(b[l:], v)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &MPNewConnectionIDFrame{PathID: pathID, NewConnectionIDFrame: *f}, l + n, nil
+}
+
+func (f *MPNewConnectionIDFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, mpNewConnectionIDFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.SequenceNumber)
+	b = quicvarint.Append(b, f.RetirePriorTo)
+	connIDLen := f.ConnectionID.Len()
+	if connIDLen > protocol.MaxConnIDLen {
+		return nil, fmt.Errorf("invalid connection ID length: %d", connIDLen)
+	}
+	b = append(b, uint8(connIDLen))
+	b = append(b, f.ConnectionID.Bytes()...)
+	b = append(b, f.StatelessResetToken[:]...)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *MPNewConnectionIDFrame) Length(v protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(mpNewConnectionIDFrameType)+quicvarint.Len(uint64(f.PathID))) + f.NewConnectionIDFrame.Length(v) - 1
+}
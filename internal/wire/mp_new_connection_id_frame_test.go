@@ -0,0 +1,78 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMPNewConnectionID(t *testing.T) {
+	data := encodeVarInt(3)                   // path ID
+	data = append(data, encodeVarInt(0xd)...) // sequence number
+	data = append(data, encodeVarInt(0xc)...) // retire prior to
+	data = append(data, 4)                    // connection ID length
+	data = append(data, []byte{1, 2, 3, 4}...)
+	data = append(data, []byte("0123456789abcdef")...) // stateless reset token
+	frame, l, err := parseMPNewConnectionIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(3), frame.PathID)
+	require.Equal(t, uint64(0xd), frame.SequenceNumber)
+	require.Equal(t, uint64(0xc), frame.RetirePriorTo)
+	require.Equal(t, protocol.ParseConnectionID([]byte{1, 2, 3, 4}), frame.ConnectionID)
+	require.Equal(t, protocol.StatelessResetToken([]byte("0123456789abcdef")), frame.StatelessResetToken)
+	require.Equal(t, len(data), l)
+}
+
+func TestParseMPNewConnectionIDRetirePriorToLargerThanSequenceNumber(t *testing.T) {
+	data := encodeVarInt(3)                    // path ID
+	data = append(data, encodeVarInt(1000)...) // sequence number
+	data = append(data, encodeVarInt(1001)...) // retire prior to
+	data = append(data, 3)
+	data = append(data, []byte{1, 2, 3}...)
+	data = append(data, []byte("0123456789abcdef")...)
+	_, _, err := parseMPNewConnectionIDFrame(data, protocol.Version1)
+	require.EqualError(t, err, "Retire Prior To value (1001) larger than Sequence Number (1000)")
+}
+
+func TestParseMPNewConnectionIDErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(3)                            // path ID
+	data = append(data, encodeVarInt(0x1337)...)       // sequence number
+	data = append(data, encodeVarInt(0x1234)...)       // retire prior to
+	data = append(data, 4)                             // connection ID length
+	data = append(data, []byte{1, 2, 3, 4}...)         // connection ID
+	data = append(data, []byte("0123456789abcdef")...) // stateless reset token
+	_, l, err := parseMPNewConnectionIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parseMPNewConnectionIDFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWriteMPNewConnectionID(t *testing.T) {
+	token := protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	frame := &MPNewConnectionIDFrame{
+		PathID: 2,
+		NewConnectionIDFrame: NewConnectionIDFrame{
+			SequenceNumber:      0x1337,
+			RetirePriorTo:       0x42,
+			ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6}),
+			StatelessResetToken: token,
+		},
+	}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := encodeVarInt(mpNewConnectionIDFrameType)
+	expected = append(expected, encodeVarInt(2)...)
+	expected = append(expected, encodeVarInt(0x1337)...)
+	expected = append(expected, encodeVarInt(0x42)...)
+	expected = append(expected, 6)
+	expected = append(expected, []byte{1, 2, 3, 4, 5, 6}...)
+	expected = append(expected, token[:]...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
@@ -0,0 +1,33 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// An MPRetireConnectionIDFrame is an MP_RETIRE_CONNECTION_ID frame.
+// It retires a connection ID that was issued for the path with the given path ID.
+type MPRetireConnectionIDFrame struct {
+	PathID         protocol.PathID
+	SequenceNumber uint64
+}
+
+func parseMPRetireConnectionIDFrame(b []byte, _ protocol.Version) (*MPRetireConnectionIDFrame, int, error) {
+	pathID, seq, l, err := parsePathStatus(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &MPRetireConnectionIDFrame{PathID: pathID, SequenceNumber: seq}, l, nil
+}
+
+func (f *MPRetireConnectionIDFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, mpRetireConnectionIDFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.SequenceNumber)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *MPRetireConnectionIDFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(mpRetireConnectionIDFrameType) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.SequenceNumber))
+}
@@ -0,0 +1,43 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMPRetireConnectionID(t *testing.T) {
+	data := encodeVarInt(6)                          // path ID
+	data = append(data, encodeVarInt(0xdeadbeef)...) // sequence number
+	frame, l, err := parseMPRetireConnectionIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(6), frame.PathID)
+	require.Equal(t, uint64(0xdeadbeef), frame.SequenceNumber)
+	require.Equal(t, len(data), l)
+}
+
+func TestParseMPRetireConnectionIDErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(6)                          // path ID
+	data = append(data, encodeVarInt(0xdeadbeef)...) // sequence number
+	_, l, err := parseMPRetireConnectionIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parseMPRetireConnectionIDFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWriteMPRetireConnectionID(t *testing.T) {
+	frame := &MPRetireConnectionIDFrame{PathID: 1, SequenceNumber: 0x1337}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := encodeVarInt(mpRetireConnectionIDFrameType)
+	expected = append(expected, encodeVarInt(1)...)
+	expected = append(expected, encodeVarInt(0x1337)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
@@ -0,0 +1,58 @@
+package wire
+
+import (
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathAbandonFrame is a PATH_ABANDON frame.
+type PathAbandonFrame struct {
+	PathID       protocol.PathID
+	ErrorCode    uint64
+	ReasonPhrase string
+}
+
+func parsePathAbandonFrame(b []byte, _ protocol.Version) (*PathAbandonFrame, int, error) {
+	startLen := len(b)
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	b = b[l:]
+	ec, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	reasonPhraseLen, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	if int(reasonPhraseLen) > len(b) {
+		return nil, 0, io.EOF
+	}
+	reasonPhrase := make([]byte, reasonPhraseLen)
+	copy(reasonPhrase, b)
+	return &PathAbandonFrame{
+		PathID:       pathID,
+		ErrorCode:    ec,
+		ReasonPhrase: string(reasonPhrase),
+	}, startLen - len(b) + int(reasonPhraseLen), nil
+}
+
+func (f *PathAbandonFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathAbandonFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.ErrorCode)
+	b = quicvarint.Append(b, uint64(len(f.ReasonPhrase)))
+	b = append(b, []byte(f.ReasonPhrase)...)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathAbandonFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(pathAbandonFrameType)+quicvarint.Len(uint64(f.PathID))+quicvarint.Len(f.ErrorCode)+quicvarint.Len(uint64(len(f.ReasonPhrase)))) + protocol.ByteCount(len(f.ReasonPhrase))
+}
@@ -0,0 +1,56 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathAbandon(t *testing.T) {
+	reason := "no reason"
+	data := encodeVarInt(5)                                   // path ID
+	data = append(data, encodeVarInt(0x1337)...)              // error code
+	data = append(data, encodeVarInt(uint64(len(reason)))...) // reason phrase length
+	data = append(data, []byte(reason)...)
+	frame, l, err := parsePathAbandonFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(5), frame.PathID)
+	require.Equal(t, uint64(0x1337), frame.ErrorCode)
+	require.Equal(t, reason, frame.ReasonPhrase)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathAbandonErrorsOnEOFs(t *testing.T) {
+	reason := "no reason"
+	data := encodeVarInt(5)                                   // path ID
+	data = append(data, encodeVarInt(0x1337)...)              // error code
+	data = append(data, encodeVarInt(uint64(len(reason)))...) // reason phrase length
+	data = append(data, []byte(reason)...)
+	_, l, err := parsePathAbandonFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathAbandonFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathAbandon(t *testing.T) {
+	frame := &PathAbandonFrame{
+		PathID:       3,
+		ErrorCode:    0xdead,
+		ReasonPhrase: "foobar",
+	}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := encodeVarInt(pathAbandonFrameType)
+	expected = append(expected, encodeVarInt(3)...)
+	expected = append(expected, encodeVarInt(0xdead)...)
+	expected = append(expected, encodeVarInt(6)...)
+	expected = append(expected, []byte("foobar")...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
@@ -0,0 +1,62 @@
+package wire
+
+import (
+	"errors"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathAckFrame is a PATH_ACK frame.
+// It acknowledges packets sent on the path with the given path ID.
+type PathAckFrame struct {
+	PathID protocol.PathID
+	AckFrame
+}
+
+func parsePathAckFrame(b []byte, typ uint64, ackDelayExponent uint8, v protocol.Version) (*PathAckFrame, int, error) {
+	startLen := len(b)
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	b = b[l:]
+	f := &PathAckFrame{PathID: pathID}
+	ackTyp := uint64(ackFrameType)
+	if typ == pathAckECNFrameType {
+		ackTyp = ackECNFrameType
+	}
+	l, err = parseAckFrame(&f.AckFrame, b, ackTyp, ackDelayExponent, v)
+	if err != nil {
+		return nil, 0, err
+	}
+	return f, startLen - len(b) + l, nil
+}
+
+func (f *PathAckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	if f.hasECN() {
+		b = quicvarint.Append(b, pathAckECNFrameType)
+	} else {
+		b = quicvarint.Append(b, pathAckFrameType)
+	}
+	b = quicvarint.Append(b, uint64(f.PathID))
+	return f.appendBody(b), nil
+}
+
+// Length of a written frame
+func (f *PathAckFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(pathAckFrameType)+quicvarint.Len(uint64(f.PathID))) + f.bodyLength()
+}
+
+var errInvalidPathID = errors.New("invalid path ID")
+
+func parsePathID(b []byte) (protocol.PathID, int, error) {
+	id, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, 0, replaceUnexpectedEOF(err)
+	}
+	if id > uint64(protocol.MaxPathID) {
+		return 0, 0, errInvalidPathID
+	}
+	return protocol.PathID(id), l, nil
+}
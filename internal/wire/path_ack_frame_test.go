@@ -0,0 +1,109 @@
+package wire
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathAck(t *testing.T) {
+	data := encodeVarInt(7)                   // path ID
+	data = append(data, encodeVarInt(100)...) // largest acked
+	data = append(data, encodeVarInt(0)...)   // delay
+	data = append(data, encodeVarInt(0)...)   // num blocks
+	data = append(data, encodeVarInt(10)...)  // first ack block
+	frame, l, err := parsePathAckFrame(data, pathAckFrameType, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	require.Equal(t, protocol.PathID(7), frame.PathID)
+	require.Equal(t, protocol.PacketNumber(100), frame.LargestAcked())
+	require.Equal(t, protocol.PacketNumber(90), frame.LowestAcked())
+}
+
+func TestParsePathAckECN(t *testing.T) {
+	data := encodeVarInt(3)                   // path ID
+	data = append(data, encodeVarInt(100)...) // largest acked
+	data = append(data, encodeVarInt(0)...)   // delay
+	data = append(data, encodeVarInt(0)...)   // num blocks
+	data = append(data, encodeVarInt(10)...)  // first ack block
+	data = append(data, encodeVarInt(1)...)   // ECT(0)
+	data = append(data, encodeVarInt(2)...)   // ECT(1)
+	data = append(data, encodeVarInt(3)...)   // ECN-CE
+	frame, l, err := parsePathAckFrame(data, pathAckECNFrameType, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	require.Equal(t, protocol.PathID(3), frame.PathID)
+	require.Equal(t, uint64(1), frame.ECT0)
+	require.Equal(t, uint64(2), frame.ECT1)
+	require.Equal(t, uint64(3), frame.ECNCE)
+}
+
+func TestParsePathAckInvalidPathID(t *testing.T) {
+	data := encodeVarInt(uint64(protocol.MaxPathID) + 1) // path ID
+	data = append(data, encodeVarInt(100)...)            // largest acked
+	data = append(data, encodeVarInt(0)...)              // delay
+	data = append(data, encodeVarInt(0)...)              // num blocks
+	data = append(data, encodeVarInt(10)...)             // first ack block
+	_, _, err := parsePathAckFrame(data, pathAckFrameType, protocol.AckDelayExponent, protocol.Version1)
+	require.ErrorIs(t, err, errInvalidPathID)
+}
+
+func TestParsePathAckErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(7)                   // path ID
+	data = append(data, encodeVarInt(100)...) // largest acked
+	data = append(data, encodeVarInt(0)...)   // delay
+	data = append(data, encodeVarInt(0)...)   // num blocks
+	data = append(data, encodeVarInt(10)...)  // first ack block
+	_, l, err := parsePathAckFrame(data, pathAckFrameType, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathAckFrame(data[:i], pathAckFrameType, protocol.AckDelayExponent, protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathAck(t *testing.T) {
+	f := &PathAckFrame{
+		PathID: 42,
+		AckFrame: AckFrame{
+			AckRanges: []AckRange{{Smallest: 10, Largest: 20}, {Smallest: 1, Largest: 5}},
+			DelayTime: 8 * time.Millisecond,
+		},
+	}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+	typ, l, err := quicvarint.Parse(b)
+	require.NoError(t, err)
+	require.Equal(t, uint64(pathAckFrameType), typ)
+	frame, n, err := parsePathAckFrame(b[l:], typ, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(b)-l, n)
+	require.Equal(t, f, frame)
+}
+
+func TestWritePathAckECN(t *testing.T) {
+	f := &PathAckFrame{
+		PathID: 1,
+		AckFrame: AckFrame{
+			AckRanges: []AckRange{{Smallest: 1, Largest: 5}},
+			ECT0:      10,
+			ECNCE:     1,
+		},
+	}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+	typ, l, err := quicvarint.Parse(b)
+	require.NoError(t, err)
+	require.Equal(t, uint64(pathAckECNFrameType), typ)
+	frame, _, err := parsePathAckFrame(b[l:], typ, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, f, frame)
+}
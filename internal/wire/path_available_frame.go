@@ -0,0 +1,33 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathAvailableFrame is a PATH_AVAILABLE frame.
+// It tells the peer that the path can be used for sending.
+type PathAvailableFrame struct {
+	PathID         protocol.PathID
+	SequenceNumber uint64
+}
+
+func parsePathAvailableFrame(b []byte, _ protocol.Version) (*PathAvailableFrame, int, error) {
+	pathID, seq, l, err := parsePathStatus(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &PathAvailableFrame{PathID: pathID, SequenceNumber: seq}, l, nil
+}
+
+func (f *PathAvailableFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathAvailableFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.SequenceNumber)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathAvailableFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(pathAvailableFrameType) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.SequenceNumber))
+}
@@ -0,0 +1,43 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathAvailable(t *testing.T) {
+	data := encodeVarInt(9)                  // path ID
+	data = append(data, encodeVarInt(13)...) // sequence number
+	frame, l, err := parsePathAvailableFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(9), frame.PathID)
+	require.Equal(t, uint64(13), frame.SequenceNumber)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathAvailableErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(9)                      // path ID
+	data = append(data, encodeVarInt(0x1337)...) // sequence number
+	_, l, err := parsePathAvailableFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathAvailableFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathAvailable(t *testing.T) {
+	frame := &PathAvailableFrame{PathID: 4, SequenceNumber: 7}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := encodeVarInt(pathAvailableFrameType)
+	expected = append(expected, encodeVarInt(4)...)
+	expected = append(expected, encodeVarInt(7)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
@@ -0,0 +1,50 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathStandbyFrame is a PATH_STANDBY frame.
+// It asks the peer to not use the path for sending, as long as another path is available.
+type PathStandbyFrame struct {
+	PathID         protocol.PathID
+	SequenceNumber uint64
+}
+
+func parsePathStandbyFrame(b []byte, _ protocol.Version) (*PathStandbyFrame, int, error) {
+	pathID, seq, l, err := parsePathStatus(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &PathStandbyFrame{PathID: pathID, SequenceNumber: seq}, l, nil
+}
+
+func (f *PathStandbyFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathStandbyFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.SequenceNumber)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathStandbyFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(pathStandbyFrameType) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.SequenceNumber))
+}
+
+// parsePathStatus parses a path ID followed by a sequence number.
+// This layout is shared by the PATH_STANDBY, PATH_AVAILABLE and MP_RETIRE_CONNECTION_ID frames.
+func parsePathStatus(b []byte) (protocol.PathID, uint64, int, error) {
+	startLen := len(b)
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	b = b[l:]
+	seq, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, 0, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	return pathID, seq, startLen - len(b), nil
+}
@@ -0,0 +1,43 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathStandby(t *testing.T) {
+	data := encodeVarInt(2)                  // path ID
+	data = append(data, encodeVarInt(42)...) // sequence number
+	frame, l, err := parsePathStandbyFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(2), frame.PathID)
+	require.Equal(t, uint64(42), frame.SequenceNumber)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathStandbyErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(2)                      // path ID
+	data = append(data, encodeVarInt(0x1337)...) // sequence number
+	_, l, err := parsePathStandbyFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathStandbyFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathStandby(t *testing.T) {
+	frame := &PathStandbyFrame{PathID: 1, SequenceNumber: 0x1337}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := encodeVarInt(pathStandbyFrameType)
+	expected = append(expected, encodeVarInt(1)...)
+	expected = append(expected, encodeVarInt(0x1337)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
@@ -1,19 +1,23 @@
 package wire
 
 import (
+	"errors"
+
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/quicvarint"
 )
 
-// A ResetStreamFrame is a RESET_STREAM frame in QUIC
+// A ResetStreamFrame is a RESET_STREAM frame in QUIC.
+// If the ReliableSize is non-zero, it is a RESET_STREAM_AT frame (draft-ietf-quic-reliable-stream-reset).
 type ResetStreamFrame struct {
-	StreamID  protocol.StreamID
-	ErrorCode qerr.StreamErrorCode
-	FinalSize protocol.ByteCount
+	StreamID     protocol.StreamID
+	ErrorCode    qerr.StreamErrorCode
+	FinalSize    protocol.ByteCount
+	ReliableSize protocol.ByteCount
 }
 
-func parseResetStreamFrame(b []byte, _ protocol.Version) (*ResetStreamFrame, int, error) {
+func parseResetStreamFrame(b []byte, isResetStreamAt bool, _ protocol.Version) (*ResetStreamFrame, int, error) {
 	startLen := len(b)
 	var streamID protocol.StreamID
 	var byteOffset protocol.ByteCount
@@ -33,23 +37,48 @@ func parseResetStreamFrame(b []byte, _ protocol.Version) (*ResetStreamFrame, int
 		return nil, 0, replaceUnexpectedEOF(err)
 	}
 	byteOffset = protocol.ByteCount(bo)
+	b = b[l:]
+	var reliableSize protocol.ByteCount
+	if isResetStreamAt {
+		rs, l, err := quicvarint.Parse(b)
+		if err != nil {
+			return nil, 0, replaceUnexpectedEOF(err)
+		}
+		b = b[l:]
+		reliableSize = protocol.ByteCount(rs)
+		if reliableSize > byteOffset {
+			return nil, 0, errors.New("RESET_STREAM_AT frame: reliable size can't be larger than the final size")
+		}
+	}
 
 	return &ResetStreamFrame{
-		StreamID:  streamID,
-		ErrorCode: qerr.StreamErrorCode(errorCode),
-		FinalSize: byteOffset,
-	}, startLen - len(b) + l, nil
+		StreamID:     streamID,
+		ErrorCode:    qerr.StreamErrorCode(errorCode),
+		FinalSize:    byteOffset,
+		ReliableSize: reliableSize,
+	}, startLen - len(b), nil
 }
 
 func (f *ResetStreamFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
-	b = append(b, resetStreamFrameType)
+	if f.ReliableSize > 0 {
+		b = append(b, resetStreamAtFrameType)
+	} else {
+		b = append(b, resetStreamFrameType)
+	}
 	b = quicvarint.Append(b, uint64(f.StreamID))
 	b = quicvarint.Append(b, uint64(f.ErrorCode))
 	b = quicvarint.Append(b, uint64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		b = quicvarint.Append(b, uint64(f.ReliableSize))
+	}
 	return b, nil
 }
 
 // Length of a written frame
 func (f *ResetStreamFrame) Length(protocol.Version) protocol.ByteCount {
-	return 1 + protocol.ByteCount(quicvarint.Len(uint64(f.StreamID))+quicvarint.Len(uint64(f.ErrorCode))+quicvarint.Len(uint64(f.FinalSize)))
+	l := 1 + quicvarint.Len(uint64(f.StreamID)) + quicvarint.Len(uint64(f.ErrorCode)) + quicvarint.Len(uint64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		l += quicvarint.Len(uint64(f.ReliableSize))
+	}
+	return protocol.ByteCount(l)
 }
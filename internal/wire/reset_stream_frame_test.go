@@ -13,7 +13,7 @@ func TestParseResetStream(t *testing.T) {
 	data := encodeVarInt(0xdeadbeef)                  // stream ID
 	data = append(data, encodeVarInt(0x1337)...)      // error code
 	data = append(data, encodeVarInt(0x987654321)...) // byte offset
-	frame, l, err := parseResetStreamFrame(data, protocol.Version1)
+	frame, l, err := parseResetStreamFrame(data, false, protocol.Version1)
 	require.NoError(t, err)
 	require.Equal(t, protocol.StreamID(0xdeadbeef), frame.StreamID)
 	require.Equal(t, protocol.ByteCount(0x987654321), frame.FinalSize)
@@ -25,11 +25,11 @@ func TestParseResetStreamErrorsOnEOFs(t *testing.T) {
 	data := encodeVarInt(0xdeadbeef)                  // stream ID
 	data = append(data, encodeVarInt(0x1337)...)      // error code
 	data = append(data, encodeVarInt(0x987654321)...) // byte offset
-	_, l, err := parseResetStreamFrame(data, protocol.Version1)
+	_, l, err := parseResetStreamFrame(data, false, protocol.Version1)
 	require.NoError(t, err)
 	require.Equal(t, len(data), l)
 	for i := range data {
-		_, _, err := parseResetStreamFrame(data[:i], protocol.Version1)
+		_, _, err := parseResetStreamFrame(data[:i], false, protocol.Version1)
 		require.Error(t, err)
 	}
 }
@@ -49,3 +49,48 @@ func TestWriteResetStream(t *testing.T) {
 	require.Equal(t, expected, b)
 	require.Len(t, b, int(frame.Length(protocol.Version1)))
 }
+
+func TestParseResetStreamAt(t *testing.T) {
+	data := encodeVarInt(0xdeadbeef)                  // stream ID
+	data = append(data, encodeVarInt(0x1337)...)      // error code
+	data = append(data, encodeVarInt(0x987654321)...) // byte offset
+	data = append(data, encodeVarInt(0x123456)...)    // reliable size
+	frame, l, err := parseResetStreamFrame(data, true, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.StreamID(0xdeadbeef), frame.StreamID)
+	require.Equal(t, protocol.ByteCount(0x987654321), frame.FinalSize)
+	require.Equal(t, protocol.ByteCount(0x123456), frame.ReliableSize)
+	require.Equal(t, qerr.StreamErrorCode(0x1337), frame.ErrorCode)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parseResetStreamFrame(data[:i], true, protocol.Version1)
+		require.Error(t, err)
+	}
+}
+
+func TestParseResetStreamAtReliableSizeLargerThanFinalSize(t *testing.T) {
+	data := encodeVarInt(0xdeadbeef)             // stream ID
+	data = append(data, encodeVarInt(0x1337)...) // error code
+	data = append(data, encodeVarInt(1000)...)   // byte offset
+	data = append(data, encodeVarInt(1001)...)   // reliable size
+	_, _, err := parseResetStreamFrame(data, true, protocol.Version1)
+	require.EqualError(t, err, "RESET_STREAM_AT frame: reliable size can't be larger than the final size")
+}
+
+func TestWriteResetStreamAt(t *testing.T) {
+	frame := ResetStreamFrame{
+		StreamID:     0x1337,
+		FinalSize:    0x11223344decafbad,
+		ErrorCode:    0xcafe,
+		ReliableSize: 0xdead,
+	}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := []byte{resetStreamAtFrameType}
+	expected = append(expected, encodeVarInt(0x1337)...)
+	expected = append(expected, encodeVarInt(0xcafe)...)
+	expected = append(expected, encodeVarInt(0x11223344decafbad)...)
+	expected = append(expected, encodeVarInt(0xdead)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
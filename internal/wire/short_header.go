@@ -11,14 +11,15 @@ import (
 // ParseShortHeader parses a short header packet.
 // It must be called after header protection was removed.
 // Otherwise, the check for the reserved bits will (most likely) fail.
-func ParseShortHeader(data []byte, connIDLen int) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
+// Packets that have the QUIC bit set to 0 are only accepted if acceptZeroQUICBit is set (RFC 9287).
+func ParseShortHeader(data []byte, connIDLen int, acceptZeroQUICBit bool) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
 	if len(data) == 0 {
 		return 0, 0, 0, 0, io.EOF
 	}
 	if data[0]&0x80 > 0 {
 		return 0, 0, 0, 0, errors.New("not a short header packet")
 	}
-	if data[0]&0x40 == 0 {
+	if data[0]&0x40 == 0 && !acceptZeroQUICBit {
 		return 0, 0, 0, 0, errors.New("not a QUIC packet")
 	}
 	pnLen := protocol.PacketNumberLen(data[0]&0b11) + 1
@@ -43,8 +44,13 @@ func ParseShortHeader(data []byte, connIDLen int) (length int, _ protocol.Packet
 }
 
 // AppendShortHeader writes a short header.
-func AppendShortHeader(b []byte, connID protocol.ConnectionID, pn protocol.PacketNumber, pnLen protocol.PacketNumberLen, kp protocol.KeyPhaseBit) ([]byte, error) {
+// If clearQUICBit is set, the QUIC bit is set to 0.
+// This is only allowed if the peer advertised support for greasing the QUIC bit (RFC 9287).
+func AppendShortHeader(b []byte, connID protocol.ConnectionID, pn protocol.PacketNumber, pnLen protocol.PacketNumberLen, kp protocol.KeyPhaseBit, clearQUICBit bool) ([]byte, error) {
 	typeByte := 0x40 | uint8(pnLen-1)
+	if clearQUICBit {
+		typeByte &^= 0x40
+	}
 	if kp == protocol.KeyPhaseOne {
 		typeByte |= byte(1 << 2)
 	}
@@ -16,7 +16,7 @@ func TestParseShortHeader(t *testing.T) {
 		0xde, 0xad, 0xbe, 0xef,
 		0x13, 0x37, 0x99,
 	}
-	l, pn, pnLen, kp, err := ParseShortHeader(data, 4)
+	l, pn, pnLen, kp, err := ParseShortHeader(data, 4, false)
 	require.NoError(t, err)
 	require.Equal(t, len(data), l)
 	require.Equal(t, protocol.KeyPhaseOne, kp)
@@ -30,23 +30,37 @@ func TestParseShortHeaderNoQUICBit(t *testing.T) {
 		0xde, 0xad, 0xbe, 0xef,
 		0x13, 0x37,
 	}
-	_, _, _, _, err := ParseShortHeader(data, 4)
+	_, _, _, _, err := ParseShortHeader(data, 4, false)
 	require.EqualError(t, err, "not a QUIC packet")
 }
 
+func TestParseShortHeaderGreasedQUICBit(t *testing.T) {
+	data := []byte{
+		0b00000101,
+		0xde, 0xad, 0xbe, 0xef,
+		0x13, 0x37,
+	}
+	l, pn, pnLen, kp, err := ParseShortHeader(data, 4, true)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	require.Equal(t, protocol.KeyPhaseOne, kp)
+	require.Equal(t, protocol.PacketNumber(0x1337), pn)
+	require.Equal(t, protocol.PacketNumberLen2, pnLen)
+}
+
 func TestParseShortHeaderReservedBitsSet(t *testing.T) {
 	data := []byte{
 		0b01010101,
 		0xde, 0xad, 0xbe, 0xef,
 		0x13, 0x37,
 	}
-	_, pn, _, _, err := ParseShortHeader(data, 4)
+	_, pn, _, _, err := ParseShortHeader(data, 4, false)
 	require.EqualError(t, err, ErrInvalidReservedBits.Error())
 	require.Equal(t, protocol.PacketNumber(0x1337), pn)
 }
 
 func TestParseShortHeaderErrorsWhenPassedLongHeaderPacket(t *testing.T) {
-	_, _, _, _, err := ParseShortHeader([]byte{0x80}, 4)
+	_, _, _, _, err := ParseShortHeader([]byte{0x80}, 4, false)
 	require.EqualError(t, err, "not a short header packet")
 }
 
@@ -56,10 +70,10 @@ func TestParseShortHeaderErrorsOnEOF(t *testing.T) {
 		0xde, 0xad, 0xbe, 0xef,
 		0x13, 0x37, 0x99,
 	}
-	_, _, _, _, err := ParseShortHeader(data, 4)
+	_, _, _, _, err := ParseShortHeader(data, 4, false)
 	require.NoError(t, err)
 	for i := range data {
-		_, _, _, _, err := ParseShortHeader(data[:i], 4)
+		_, _, _, _, err := ParseShortHeader(data[:i], 4, false)
 		require.EqualError(t, err, io.EOF.Error())
 	}
 }
@@ -71,9 +85,24 @@ func TestShortHeaderLen(t *testing.T) {
 
 func TestWriteShortHeaderPacket(t *testing.T) {
 	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
-	b, err := AppendShortHeader(nil, connID, 1337, 4, protocol.KeyPhaseOne)
+	b, err := AppendShortHeader(nil, connID, 1337, 4, protocol.KeyPhaseOne, false)
 	require.NoError(t, err)
-	l, pn, pnLen, kp, err := ParseShortHeader(b, 4)
+	l, pn, pnLen, kp, err := ParseShortHeader(b, 4, false)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PacketNumber(1337), pn)
+	require.Equal(t, protocol.PacketNumberLen4, pnLen)
+	require.Equal(t, protocol.KeyPhaseOne, kp)
+	require.Equal(t, len(b), l)
+}
+
+func TestWriteShortHeaderPacketClearingQUICBit(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	b, err := AppendShortHeader(nil, connID, 1337, 4, protocol.KeyPhaseOne, true)
+	require.NoError(t, err)
+	require.Zero(t, b[0]&0x40)
+	_, _, _, _, err = ParseShortHeader(b, 4, false)
+	require.EqualError(t, err, "not a QUIC packet")
+	l, pn, pnLen, kp, err := ParseShortHeader(b, 4, true)
 	require.NoError(t, err)
 	require.Equal(t, protocol.PacketNumber(1337), pn)
 	require.Equal(t, protocol.PacketNumberLen4, pnLen)
@@ -96,7 +125,7 @@ func BenchmarkWriteShortHeader(b *testing.B) {
 	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6})
 	for i := 0; i < b.N; i++ {
 		var err error
-		buf, err = AppendShortHeader(buf, connID, 1337, protocol.PacketNumberLen4, protocol.KeyPhaseOne)
+		buf, err = AppendShortHeader(buf, connID, 1337, protocol.PacketNumberLen4, protocol.KeyPhaseOne, false)
 		if err != nil {
 			b.Fatalf("failed to write short header: %s", err)
 		}
@@ -36,6 +36,7 @@ func appendInitialSourceConnectionID(b []byte) []byte {
 
 func TestTransportParametersStringRepresentation(t *testing.T) {
 	rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+	maxPathID := protocol.PathID(3)
 	p := &TransportParameters{
 		InitialMaxStreamDataBidiLocal:   1234,
 		InitialMaxStreamDataBidiRemote:  2345,
@@ -52,8 +53,16 @@ func TestTransportParametersStringRepresentation(t *testing.T) {
 		StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 		ActiveConnectionIDLimit:         123,
 		MaxDatagramFrameSize:            876,
+		InitialMaxPathID:                &maxPathID,
+		EnableResetStreamAt:             true,
+		MinAckDelay:                     time.Millisecond,
+		GreaseQUICBit:                   true,
+		VersionInformation: &VersionInformation{
+			ChosenVersion:     protocol.Version2,
+			AvailableVersions: []protocol.Version{protocol.Version2, protocol.Version1},
+		},
 	}
-	expected := "&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"
+	expected := "&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, InitialMaxPathID: 3, EnableResetStreamAt: true, MinAckDelay: 1ms, GreaseQUICBit: true, VersionInformation: {ChosenVersion: v2, AvailableVersions: [v2 v1]}}"
 	require.Equal(t, expected, p.String())
 }
 
@@ -81,6 +90,7 @@ func TestMarshalAndUnmarshalTransportParameters(t *testing.T) {
 	var token protocol.StatelessResetToken
 	rand.Read(token[:])
 	rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+	maxPathID := protocol.PathID(getRandomValueUpTo(int64(protocol.MaxPathID)))
 	params := &TransportParameters{
 		InitialMaxStreamDataBidiLocal:   protocol.ByteCount(getRandomValue()),
 		InitialMaxStreamDataBidiRemote:  protocol.ByteCount(getRandomValue()),
@@ -99,6 +109,14 @@ func TestMarshalAndUnmarshalTransportParameters(t *testing.T) {
 		ActiveConnectionIDLimit:         2 + getRandomValueUpTo(quicvarint.Max-2),
 		MaxUDPPayloadSize:               1200 + protocol.ByteCount(getRandomValueUpTo(quicvarint.Max-1200)),
 		MaxDatagramFrameSize:            protocol.ByteCount(getRandomValue()),
+		InitialMaxPathID:                &maxPathID,
+		EnableResetStreamAt:             true,
+		MinAckDelay:                     1234 * time.Microsecond,
+		GreaseQUICBit:                   true,
+		VersionInformation: &VersionInformation{
+			ChosenVersion:     protocol.Version1,
+			AvailableVersions: []protocol.Version{protocol.Version1, protocol.Version2, 0x1a2a3a4a},
+		},
 	}
 	data := params.Marshal(protocol.PerspectiveServer)
 
@@ -121,6 +139,23 @@ func TestMarshalAndUnmarshalTransportParameters(t *testing.T) {
 	require.Equal(t, params.ActiveConnectionIDLimit, p.ActiveConnectionIDLimit)
 	require.Equal(t, params.MaxUDPPayloadSize, p.MaxUDPPayloadSize)
 	require.Equal(t, params.MaxDatagramFrameSize, p.MaxDatagramFrameSize)
+	require.Equal(t, params.InitialMaxPathID, p.InitialMaxPathID)
+	require.True(t, p.EnableResetStreamAt)
+	require.Equal(t, 1234*time.Microsecond, p.MinAckDelay)
+	require.True(t, p.GreaseQUICBit)
+	require.Equal(t, params.VersionInformation, p.VersionInformation)
+}
+
+func TestMarshalVersionInformationWithoutAvailableVersions(t *testing.T) {
+	params := &TransportParameters{
+		StatelessResetToken:     &protocol.StatelessResetToken{},
+		ActiveConnectionIDLimit: 2,
+		VersionInformation:      &VersionInformation{ChosenVersion: protocol.Version2},
+	}
+	p := &TransportParameters{}
+	require.NoError(t, p.Unmarshal(params.Marshal(protocol.PerspectiveServer), protocol.PerspectiveServer))
+	require.Equal(t, protocol.Version2, p.VersionInformation.ChosenVersion)
+	require.Empty(t, p.VersionInformation.AvailableVersions)
 }
 
 func TestMarshalAdditionalTransportParameters(t *testing.T) {
@@ -213,6 +248,21 @@ func TestTransportParameterSetsDefaultValuesWhenNotSent(t *testing.T) {
 	require.NoError(t, p.Unmarshal(data, protocol.PerspectiveServer))
 	require.EqualValues(t, protocol.DefaultAckDelayExponent, p.AckDelayExponent)
 	require.EqualValues(t, protocol.DefaultActiveConnectionIDLimit, p.ActiveConnectionIDLimit)
+	require.Nil(t, p.InitialMaxPathID)
+}
+
+func TestTransportParameterZeroInitialMaxPathID(t *testing.T) {
+	// a value of 0 only allows using the handshake path, but it still enables the multipath extension
+	var maxPathID protocol.PathID
+	data := (&TransportParameters{
+		StatelessResetToken:     &protocol.StatelessResetToken{},
+		ActiveConnectionIDLimit: 2,
+		InitialMaxPathID:        &maxPathID,
+	}).Marshal(protocol.PerspectiveServer)
+	p := &TransportParameters{}
+	require.NoError(t, p.Unmarshal(data, protocol.PerspectiveServer))
+	require.NotNil(t, p.InitialMaxPathID)
+	require.Zero(t, *p.InitialMaxPathID)
 }
 
 func TestTransportParameterErrors(t *testing.T) {
@@ -373,6 +423,91 @@ func TestTransportParameterErrors(t *testing.T) {
 			perspective:    protocol.PerspectiveClient,
 			expectedErrMsg: "invalid value for max_ack_delay: 3689348814741910323ms (maximum 16383ms)",
 		},
+		{
+			name: "initial_max_path_id too large",
+			data: func() []byte {
+				val := uint64(protocol.MaxPathID) + 1
+				b := quicvarint.Append(nil, uint64(initialMaxPathIDParameterID))
+				b = quicvarint.Append(b, uint64(quicvarint.Len(val)))
+				b = quicvarint.Append(b, val)
+				return appendInitialSourceConnectionID(b)
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "initial_max_path_id too large: 4294967296 (maximum 4294967295)",
+		},
+		{
+			name: "reset_stream_at with a value",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(resetStreamAtParameterID))
+				b = quicvarint.Append(b, 1)
+				b = append(b, 0)
+				return appendInitialSourceConnectionID(b)
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "wrong length for reset_stream_at: 1 (expected empty)",
+		},
+		{
+			name: "grease_quic_bit with a value",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(greaseQUICBitParameterID))
+				b = quicvarint.Append(b, 1)
+				b = append(b, 0)
+				return appendInitialSourceConnectionID(b)
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "wrong length for grease_quic_bit: 1 (expected empty)",
+		},
+		{
+			name: "min_ack_delay too large",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(minAckDelayParameterID))
+				b = quicvarint.Append(b, uint64(quicvarint.Len(1<<24)))
+				b = quicvarint.Append(b, 1<<24)
+				return appendInitialSourceConnectionID(b)
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "invalid value for min_ack_delay: 16777216us",
+		},
+		{
+			name: "min_ack_delay larger than max_ack_delay",
+			params: &TransportParameters{
+				MaxAckDelay:             10 * time.Millisecond,
+				MinAckDelay:             11 * time.Millisecond,
+				ActiveConnectionIDLimit: 2,
+				StatelessResetToken:     &protocol.StatelessResetToken{},
+			},
+			perspective:    protocol.PerspectiveServer,
+			expectedErrMsg: "min_ack_delay (11ms) larger than max_ack_delay (10ms)",
+		},
+		{
+			name: "version_information with invalid length",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(versionInformationParameterID))
+				b = quicvarint.Append(b, 6)
+				b = append(b, 0, 0, 0, 1, 0, 0)
+				return appendInitialSourceConnectionID(b)
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "invalid length for version_information: 6",
+		},
+		{
+			name: "version_information with chosen version 0",
+			params: &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				VersionInformation:      &VersionInformation{AvailableVersions: []protocol.Version{protocol.Version1}},
+			},
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "invalid chosen version in version_information: 0",
+		},
+		{
+			name: "version_information with available version 0",
+			params: &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				VersionInformation:      &VersionInformation{ChosenVersion: protocol.Version1, AvailableVersions: []protocol.Version{protocol.Version1, 0}},
+			},
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "invalid available version in version_information: 0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -43,8 +43,18 @@ const (
 	activeConnectionIDLimitParameterID         transportParameterID = 0xe
 	initialSourceConnectionIDParameterID       transportParameterID = 0xf
 	retrySourceConnectionIDParameterID         transportParameterID = 0x10
+	// RFC 9368
+	versionInformationParameterID transportParameterID = 0x11
 	// RFC 9221
 	maxDatagramFrameSizeParameterID transportParameterID = 0x20
+	// RFC 9287
+	greaseQUICBitParameterID transportParameterID = 0x2ab2
+	// draft-ietf-quic-multipath-07
+	initialMaxPathIDParameterID transportParameterID = 0x0f739bbc1b666d07
+	// draft-ietf-quic-reliable-stream-reset-06
+	resetStreamAtParameterID transportParameterID = 0x17f7586d2cb571
+	// draft-ietf-quic-ack-frequency-10
+	minAckDelayParameterID transportParameterID = 0xff04de1b
 )
 
 // PreferredAddress is the value encoding in the preferred_address transport parameter
@@ -54,6 +64,12 @@ type PreferredAddress struct {
 	StatelessResetToken protocol.StatelessResetToken
 }
 
+// VersionInformation is the value encoded in the version_information transport parameter (RFC 9368).
+type VersionInformation struct {
+	ChosenVersion     protocol.Version
+	AvailableVersions []protocol.Version
+}
+
 // TransportParameters are parameters sent to the peer during the handshake
 type TransportParameters struct {
 	InitialMaxStreamDataBidiLocal  protocol.ByteCount
@@ -83,6 +99,24 @@ type TransportParameters struct {
 	ActiveConnectionIDLimit uint64
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	// InitialMaxPathID is the maximum path ID the peer is allowed to use.
+	// If nil, the multipath extension is not supported.
+	InitialMaxPathID *protocol.PathID // use a pointer here to distinguish a value of 0 from a missing transport parameter
+
+	// EnableResetStreamAt says if the RESET_STREAM_AT frame is supported.
+	EnableResetStreamAt bool
+
+	// MinAckDelay is the minimum amount of time the endpoint is able to delay sending an acknowledgment.
+	// A value of 0 means that the ACK_FREQUENCY and IMMEDIATE_ACK frames are not supported.
+	MinAckDelay time.Duration
+
+	// VersionInformation is used for compatible version negotiation (RFC 9368).
+	// It is nil if the peer didn't send the version_information transport parameter.
+	VersionInformation *VersionInformation
+
+	// GreaseQUICBit says if the endpoint accepts packets with the QUIC bit set to 0 (RFC 9287).
+	GreaseQUICBit bool
 }
 
 // Unmarshal the transport parameters
@@ -140,6 +174,8 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 			initialMaxStreamsUniParameterID,
 			maxAckDelayParameterID,
 			maxDatagramFrameSizeParameterID,
+			initialMaxPathIDParameterID,
+			minAckDelayParameterID,
 			ackDelayExponentParameterID:
 			if err := p.readNumericTransportParameter(b, paramID, int(paramLen)); err != nil {
 				return err
@@ -153,11 +189,26 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 				return err
 			}
 			b = b[paramLen:]
+		case versionInformationParameterID:
+			if err := p.readVersionInformation(b[:paramLen]); err != nil {
+				return err
+			}
+			b = b[paramLen:]
 		case disableActiveMigrationParameterID:
 			if paramLen != 0 {
 				return fmt.Errorf("wrong length for disable_active_migration: %d (expected empty)", paramLen)
 			}
 			p.DisableActiveMigration = true
+		case resetStreamAtParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for reset_stream_at: %d (expected empty)", paramLen)
+			}
+			p.EnableResetStreamAt = true
+		case greaseQUICBitParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for grease_quic_bit: %d (expected empty)", paramLen)
+			}
+			p.GreaseQUICBit = true
 		case statelessResetTokenParameterID:
 			if sentBy == protocol.PerspectiveClient {
 				return errors.New("client sent a stateless_reset_token")
@@ -219,6 +270,10 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 		}
 	}
 
+	if p.MinAckDelay > p.MaxAckDelay {
+		return fmt.Errorf("min_ack_delay (%s) larger than max_ack_delay (%s)", p.MinAckDelay, p.MaxAckDelay)
+	}
+
 	// check that every transport parameter was sent at most once
 	slices.SortFunc(parameterIDs, func(a, b transportParameterID) int {
 		if a < b {
@@ -270,6 +325,28 @@ func (p *TransportParameters) readPreferredAddress(b []byte, expectedLen int) er
 	return nil
 }
 
+func (p *TransportParameters) readVersionInformation(b []byte) error {
+	if len(b) < 4 || len(b)%4 != 0 {
+		return fmt.Errorf("invalid length for version_information: %d", len(b))
+	}
+	vi := &VersionInformation{ChosenVersion: protocol.Version(binary.BigEndian.Uint32(b))}
+	if vi.ChosenVersion == 0 {
+		return errors.New("invalid chosen version in version_information: 0")
+	}
+	b = b[4:]
+	vi.AvailableVersions = make([]protocol.Version, 0, len(b)/4)
+	for len(b) > 0 {
+		v := protocol.Version(binary.BigEndian.Uint32(b))
+		if v == 0 {
+			return errors.New("invalid available version in version_information: 0")
+		}
+		vi.AvailableVersions = append(vi.AvailableVersions, v)
+		b = b[4:]
+	}
+	p.VersionInformation = vi
+	return nil
+}
+
 func (p *TransportParameters) readNumericTransportParameter(b []byte, paramID transportParameterID, expectedLen int) error {
 	val, l, err := quicvarint.Parse(b)
 	if err != nil {
@@ -322,6 +399,17 @@ func (p *TransportParameters) readNumericTransportParameter(b []byte, paramID tr
 		p.ActiveConnectionIDLimit = val
 	case maxDatagramFrameSizeParameterID:
 		p.MaxDatagramFrameSize = protocol.ByteCount(val)
+	case initialMaxPathIDParameterID:
+		if val > uint64(protocol.MaxPathID) {
+			return fmt.Errorf("initial_max_path_id too large: %d (maximum %d)", val, protocol.MaxPathID)
+		}
+		id := protocol.PathID(val)
+		p.InitialMaxPathID = &id
+	case minAckDelayParameterID:
+		if val >= 1<<24 {
+			return fmt.Errorf("invalid value for min_ack_delay: %dus", val)
+		}
+		p.MinAckDelay = time.Duration(val) * time.Microsecond
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
 	}
@@ -419,6 +507,33 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	// initial_max_path_id
+	if p.InitialMaxPathID != nil {
+		b = p.marshalVarintParam(b, initialMaxPathIDParameterID, uint64(*p.InitialMaxPathID))
+	}
+	// reset_stream_at
+	if p.EnableResetStreamAt {
+		b = quicvarint.Append(b, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 0)
+	}
+	// min_ack_delay
+	if p.MinAckDelay > 0 {
+		b = p.marshalVarintParam(b, minAckDelayParameterID, uint64(p.MinAckDelay/time.Microsecond))
+	}
+	// grease_quic_bit
+	if p.GreaseQUICBit {
+		b = quicvarint.Append(b, uint64(greaseQUICBitParameterID))
+		b = quicvarint.Append(b, 0)
+	}
+	// version_information
+	if p.VersionInformation != nil {
+		b = quicvarint.Append(b, uint64(versionInformationParameterID))
+		b = quicvarint.Append(b, uint64(4*(1+len(p.VersionInformation.AvailableVersions))))
+		b = binary.BigEndian.AppendUint32(b, uint32(p.VersionInformation.ChosenVersion))
+		for _, v := range p.VersionInformation.AvailableVersions {
+			b = binary.BigEndian.AppendUint32(b, uint32(v))
+		}
+	}
 
 	if pers == protocol.PerspectiveClient && len(AdditionalTransportParametersClient) > 0 {
 		for k, v := range AdditionalTransportParametersClient {
@@ -527,6 +642,24 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
+	if p.InitialMaxPathID != nil {
+		logString += ", InitialMaxPathID: %d"
+		logParams = append(logParams, *p.InitialMaxPathID)
+	}
+	if p.EnableResetStreamAt {
+		logString += ", EnableResetStreamAt: true"
+	}
+	if p.MinAckDelay > 0 {
+		logString += ", MinAckDelay: %s"
+		logParams = append(logParams, p.MinAckDelay)
+	}
+	if p.GreaseQUICBit {
+		logString += ", GreaseQUICBit: true"
+	}
+	if p.VersionInformation != nil {
+		logString += ", VersionInformation: {ChosenVersion: %s, AvailableVersions: %s}"
+		logParams = append(logParams, p.VersionInformation.ChosenVersion, p.VersionInformation.AvailableVersions)
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
@@ -15,6 +15,7 @@ type ConnectionTracer struct {
 	SentTransportParameters          func(parameters *TransportParameters)
 	ReceivedTransportParameters      func(parameters *TransportParameters)
 	RestoredTransportParameters      func(parameters *TransportParameters) // for 0-RTT
+	Rejected0RTT                     func(reason ZeroRTTRejectReason)      // for the server
 	SentLongHeaderPacket             func(hdr *ExtendedHeader, size ByteCount, ecn ECN, ack *AckFrame, frames []Frame)
 	SentShortHeaderPacket            func(hdr *ShortHeader, size ByteCount, ecn ECN, ack *AckFrame, frames []Frame)
 	ReceivedVersionNegotiationPacket func(dest, src ArbitraryLenConnectionID, versions []Version)
@@ -38,6 +39,9 @@ type ConnectionTracer struct {
 	LossTimerCanceled                func()
 	ECNStateUpdated                  func(state ECNState, trigger ECNStateTrigger)
 	ChoseALPN                        func(protocol string)
+	ValidatedPath                    func(local, remote net.Addr)
+	AbandonedPath                    func(local, remote net.Addr)
+	MigratedPath                     func(local, remote net.Addr)
 	// Close is called when the connection is closed.
 	Close func()
 	Debug func(name, msg string)
@@ -57,6 +57,13 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		Rejected0RTT: func(reason ZeroRTTRejectReason) {
+			for _, t := range tracers {
+				if t.Rejected0RTT != nil {
+					t.Rejected0RTT(reason)
+				}
+			}
+		},
 		SentLongHeaderPacket: func(hdr *ExtendedHeader, size ByteCount, ecn ECN, ack *AckFrame, frames []Frame) {
 			for _, t := range tracers {
 				if t.SentLongHeaderPacket != nil {
@@ -218,6 +225,27 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		ValidatedPath: func(local net.Addr, remote net.Addr) {
+			for _, t := range tracers {
+				if t.ValidatedPath != nil {
+					t.ValidatedPath(local, remote)
+				}
+			}
+		},
+		AbandonedPath: func(local net.Addr, remote net.Addr) {
+			for _, t := range tracers {
+				if t.AbandonedPath != nil {
+					t.AbandonedPath(local, remote)
+				}
+			}
+		},
+		MigratedPath: func(local net.Addr, remote net.Addr) {
+			for _, t := range tracers {
+				if t.MigratedPath != nil {
+					t.MigratedPath(local, remote)
+				}
+			}
+		},
 		Close: func() {
 			for _, t := range tracers {
 				if t.Close != nil {
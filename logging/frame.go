@@ -46,6 +46,32 @@ type (
 	StreamDataBlockedFrame = wire.StreamDataBlockedFrame
 )
 
+// Frames defined by the multipath extension.
+type (
+	// A PathAckFrame is a PATH_ACK frame.
+	PathAckFrame = wire.PathAckFrame
+	// A PathAbandonFrame is a PATH_ABANDON frame.
+	PathAbandonFrame = wire.PathAbandonFrame
+	// A PathStandbyFrame is a PATH_STANDBY frame.
+	PathStandbyFrame = wire.PathStandbyFrame
+	// A PathAvailableFrame is a PATH_AVAILABLE frame.
+	PathAvailableFrame = wire.PathAvailableFrame
+	// An MPNewConnectionIDFrame is an MP_NEW_CONNECTION_ID frame.
+	MPNewConnectionIDFrame = wire.MPNewConnectionIDFrame
+	// An MPRetireConnectionIDFrame is an MP_RETIRE_CONNECTION_ID frame.
+	MPRetireConnectionIDFrame = wire.MPRetireConnectionIDFrame
+	// A MaxPathIDFrame is a MAX_PATH_ID frame.
+	MaxPathIDFrame = wire.MaxPathIDFrame
+)
+
+// Frames defined by the ACK frequency extension.
+type (
+	// An AckFrequencyFrame is an ACK_FREQUENCY frame.
+	AckFrequencyFrame = wire.AckFrequencyFrame
+	// An ImmediateAckFrame is an IMMEDIATE_ACK frame.
+	ImmediateAckFrame = wire.ImmediateAckFrame
+)
+
 // A CryptoFrame is a CRYPTO frame.
 type CryptoFrame struct {
 	Offset ByteCount
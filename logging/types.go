@@ -91,6 +91,14 @@ const (
 	CongestionStateRecovery
 	// CongestionStateApplicationLimited means that the congestion controller is application limited
 	CongestionStateApplicationLimited
+	// CongestionStateStartup is the startup phase of BBR
+	CongestionStateStartup
+	// CongestionStateDrain is the drain phase of BBR
+	CongestionStateDrain
+	// CongestionStateProbeBW is the bandwidth probing phase of BBR
+	CongestionStateProbeBW
+	// CongestionStateProbeRTT is the RTT probing phase of BBR
+	CongestionStateProbeRTT
 )
 
 // ECNState is the state of the ECN state machine (see Appendix A.4 of RFC 9000)
@@ -126,3 +134,18 @@ const (
 	// ECNFailedManglingDetected is emitted when the path marks all ECN-marked packets as CE
 	ECNFailedManglingDetected
 )
+
+// ZeroRTTRejectReason is the reason why the server rejected 0-RTT.
+type ZeroRTTRejectReason uint8
+
+const (
+	// ZeroRTTRejectedDisabled is used when 0-RTT is disabled on the server
+	ZeroRTTRejectedDisabled ZeroRTTRejectReason = iota
+	// ZeroRTTRejectedTransportParameters is used when the transport parameters changed
+	// in a way that doesn't allow the use of 0-RTT
+	ZeroRTTRejectedTransportParameters
+	// ZeroRTTRejectedByApplication is used when the application rejected 0-RTT
+	ZeroRTTRejectedByApplication
+	// ZeroRTTRejectedReplay is used when the session ticket was already used for 0-RTT
+	ZeroRTTRejectedReplay
+)
@@ -187,6 +187,44 @@ func (c *MockPacketHandlerManagerCloseCall) DoAndReturn(f func(error)) *MockPack
 	return c
 }
 
+// Empty mocks base method.
+func (m *MockPacketHandlerManager) Empty() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Empty")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Empty indicates an expected call of Empty.
+func (mr *MockPacketHandlerManagerMockRecorder) Empty() *MockPacketHandlerManagerEmptyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Empty", reflect.TypeOf((*MockPacketHandlerManager)(nil).Empty))
+	return &MockPacketHandlerManagerEmptyCall{Call: call}
+}
+
+// MockPacketHandlerManagerEmptyCall wrap *gomock.Call
+type MockPacketHandlerManagerEmptyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPacketHandlerManagerEmptyCall) Return(arg0 <-chan struct{}) *MockPacketHandlerManagerEmptyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPacketHandlerManagerEmptyCall) Do(f func() <-chan struct{}) *MockPacketHandlerManagerEmptyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPacketHandlerManagerEmptyCall) DoAndReturn(f func() <-chan struct{}) *MockPacketHandlerManagerEmptyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Get mocks base method.
 func (m *MockPacketHandlerManager) Get(arg0 protocol.ConnectionID) (packetHandler, bool) {
 	m.ctrl.T.Helper()
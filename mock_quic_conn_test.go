@@ -119,6 +119,45 @@ func (c *MockQUICConnAcceptUniStreamCall) DoAndReturn(f func(context.Context) (R
 	return c
 }
 
+// AddPath mocks base method.
+func (m *MockQUICConn) AddPath(arg0 *Transport) (*Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(*Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockQUICConnMockRecorder) AddPath(arg0 any) *MockQUICConnAddPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockQUICConn)(nil).AddPath), arg0)
+	return &MockQUICConnAddPathCall{Call: call}
+}
+
+// MockQUICConnAddPathCall wrap *gomock.Call
+type MockQUICConnAddPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnAddPathCall) Return(arg0 *Path, arg1 error) *MockQUICConnAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnAddPathCall) Do(f func(*Transport) (*Path, error)) *MockQUICConnAddPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnAddPathCall) DoAndReturn(f func(*Transport) (*Path, error)) *MockQUICConnAddPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CloseWithError mocks base method.
 func (m *MockQUICConn) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -195,6 +234,44 @@ func (c *MockQUICConnConnectionStateCall) DoAndReturn(f func() ConnectionState)
 	return c
 }
 
+// ConnectionStats mocks base method.
+func (m *MockQUICConn) ConnectionStats() ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectionStats")
+	ret0, _ := ret[0].(ConnectionStats)
+	return ret0
+}
+
+// ConnectionStats indicates an expected call of ConnectionStats.
+func (mr *MockQUICConnMockRecorder) ConnectionStats() *MockQUICConnConnectionStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionStats", reflect.TypeOf((*MockQUICConn)(nil).ConnectionStats))
+	return &MockQUICConnConnectionStatsCall{Call: call}
+}
+
+// MockQUICConnConnectionStatsCall wrap *gomock.Call
+type MockQUICConnConnectionStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnConnectionStatsCall) Return(arg0 ConnectionStats) *MockQUICConnConnectionStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnConnectionStatsCall) Do(f func() ConnectionStats) *MockQUICConnConnectionStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnConnectionStatsCall) DoAndReturn(f func() ConnectionStats) *MockQUICConnConnectionStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Context mocks base method.
 func (m *MockQUICConn) Context() context.Context {
 	m.ctrl.T.Helper()
@@ -348,6 +425,45 @@ func (c *MockQUICConnNextConnectionCall) DoAndReturn(f func(context.Context) (Co
 	return c
 }
 
+// OpenPath mocks base method.
+func (m *MockQUICConn) OpenPath(arg0 context.Context, arg1 *Transport) (*MultipathPath, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenPath", arg0, arg1)
+	ret0, _ := ret[0].(*MultipathPath)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenPath indicates an expected call of OpenPath.
+func (mr *MockQUICConnMockRecorder) OpenPath(arg0, arg1 any) *MockQUICConnOpenPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenPath", reflect.TypeOf((*MockQUICConn)(nil).OpenPath), arg0, arg1)
+	return &MockQUICConnOpenPathCall{Call: call}
+}
+
+// MockQUICConnOpenPathCall wrap *gomock.Call
+type MockQUICConnOpenPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnOpenPathCall) Return(arg0 *MultipathPath, arg1 error) *MockQUICConnOpenPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnOpenPathCall) Do(f func(context.Context, *Transport) (*MultipathPath, error)) *MockQUICConnOpenPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnOpenPathCall) DoAndReturn(f func(context.Context, *Transport) (*MultipathPath, error)) *MockQUICConnOpenPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // OpenStream mocks base method.
 func (m *MockQUICConn) OpenStream() (Stream, error) {
 	m.ctrl.T.Helper()
@@ -504,6 +620,83 @@ func (c *MockQUICConnOpenUniStreamSyncCall) DoAndReturn(f func(context.Context)
 	return c
 }
 
+// OriginalPath mocks base method.
+func (m *MockQUICConn) OriginalPath() (*Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OriginalPath")
+	ret0, _ := ret[0].(*Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OriginalPath indicates an expected call of OriginalPath.
+func (mr *MockQUICConnMockRecorder) OriginalPath() *MockQUICConnOriginalPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OriginalPath", reflect.TypeOf((*MockQUICConn)(nil).OriginalPath))
+	return &MockQUICConnOriginalPathCall{Call: call}
+}
+
+// MockQUICConnOriginalPathCall wrap *gomock.Call
+type MockQUICConnOriginalPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnOriginalPathCall) Return(arg0 *Path, arg1 error) *MockQUICConnOriginalPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnOriginalPathCall) Do(f func() (*Path, error)) *MockQUICConnOriginalPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnOriginalPathCall) DoAndReturn(f func() (*Path, error)) *MockQUICConnOriginalPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Paths mocks base method.
+func (m *MockQUICConn) Paths() []*MultipathPath {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Paths")
+	ret0, _ := ret[0].([]*MultipathPath)
+	return ret0
+}
+
+// Paths indicates an expected call of Paths.
+func (mr *MockQUICConnMockRecorder) Paths() *MockQUICConnPathsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Paths", reflect.TypeOf((*MockQUICConn)(nil).Paths))
+	return &MockQUICConnPathsCall{Call: call}
+}
+
+// MockQUICConnPathsCall wrap *gomock.Call
+type MockQUICConnPathsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnPathsCall) Return(arg0 []*MultipathPath) *MockQUICConnPathsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnPathsCall) Do(f func() []*MultipathPath) *MockQUICConnPathsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnPathsCall) DoAndReturn(f func() []*MultipathPath) *MockQUICConnPathsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ReceiveDatagram mocks base method.
 func (m *MockQUICConn) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -11,6 +11,7 @@ package quic
 
 import (
 	net "net"
+	netip "net/netip"
 	reflect "reflect"
 	time "time"
 
@@ -195,18 +196,18 @@ func (c *MockRawConnSetReadDeadlineCall) DoAndReturn(f func(time.Time) error) *M
 }
 
 // WritePacket mocks base method.
-func (m *MockRawConn) WritePacket(arg0 []byte, arg1 net.Addr, arg2 []byte, arg3 uint16, arg4 protocol.ECN) (int, error) {
+func (m *MockRawConn) WritePacket(arg0 []byte, arg1 net.Addr, arg2 netip.Addr, arg3 []byte, arg4 uint16, arg5 protocol.ECN, arg6 time.Time) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WritePacket", arg0, arg1, arg2, arg3, arg4)
+	ret := m.ctrl.Call(m, "WritePacket", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // WritePacket indicates an expected call of WritePacket.
-func (mr *MockRawConnMockRecorder) WritePacket(arg0, arg1, arg2, arg3, arg4 any) *MockRawConnWritePacketCall {
+func (mr *MockRawConnMockRecorder) WritePacket(arg0, arg1, arg2, arg3, arg4, arg5, arg6 any) *MockRawConnWritePacketCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WritePacket", reflect.TypeOf((*MockRawConn)(nil).WritePacket), arg0, arg1, arg2, arg3, arg4)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WritePacket", reflect.TypeOf((*MockRawConn)(nil).WritePacket), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 	return &MockRawConnWritePacketCall{Call: call}
 }
 
@@ -222,13 +223,13 @@ func (c *MockRawConnWritePacketCall) Return(arg0 int, arg1 error) *MockRawConnWr
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockRawConnWritePacketCall) Do(f func([]byte, net.Addr, []byte, uint16, protocol.ECN) (int, error)) *MockRawConnWritePacketCall {
+func (c *MockRawConnWritePacketCall) Do(f func([]byte, net.Addr, netip.Addr, []byte, uint16, protocol.ECN, time.Time) (int, error)) *MockRawConnWritePacketCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockRawConnWritePacketCall) DoAndReturn(f func([]byte, net.Addr, []byte, uint16, protocol.ECN) (int, error)) *MockRawConnWritePacketCall {
+func (c *MockRawConnWritePacketCall) DoAndReturn(f func([]byte, net.Addr, netip.Addr, []byte, uint16, protocol.ECN, time.Time) (int, error)) *MockRawConnWritePacketCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -12,6 +12,7 @@ package quic
 import (
 	net "net"
 	reflect "reflect"
+	time "time"
 
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	gomock "go.uber.org/mock/gomock"
@@ -155,17 +156,17 @@ func (c *MockSendConnRemoteAddrCall) DoAndReturn(f func() net.Addr) *MockSendCon
 }
 
 // Write mocks base method.
-func (m *MockSendConn) Write(arg0 []byte, arg1 uint16, arg2 protocol.ECN) error {
+func (m *MockSendConn) Write(arg0 []byte, arg1 uint16, arg2 protocol.ECN, arg3 time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Write", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Write", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Write indicates an expected call of Write.
-func (mr *MockSendConnMockRecorder) Write(arg0, arg1, arg2 any) *MockSendConnWriteCall {
+func (mr *MockSendConnMockRecorder) Write(arg0, arg1, arg2, arg3 any) *MockSendConnWriteCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSendConn)(nil).Write), arg0, arg1, arg2)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSendConn)(nil).Write), arg0, arg1, arg2, arg3)
 	return &MockSendConnWriteCall{Call: call}
 }
 
@@ -181,13 +182,13 @@ func (c *MockSendConnWriteCall) Return(arg0 error) *MockSendConnWriteCall {
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockSendConnWriteCall) Do(f func([]byte, uint16, protocol.ECN) error) *MockSendConnWriteCall {
+func (c *MockSendConnWriteCall) Do(f func([]byte, uint16, protocol.ECN, time.Time) error) *MockSendConnWriteCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockSendConnWriteCall) DoAndReturn(f func([]byte, uint16, protocol.ECN) error) *MockSendConnWriteCall {
+func (c *MockSendConnWriteCall) DoAndReturn(f func([]byte, uint16, protocol.ECN, time.Time) error) *MockSendConnWriteCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -229,3 +230,41 @@ func (c *MockSendConncapabilitiesCall) DoAndReturn(f func() connCapabilities) *M
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// withRemoteAddr mocks base method.
+func (m *MockSendConn) withRemoteAddr(arg0 net.Addr, arg1 packetInfo) sendConn {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "withRemoteAddr", arg0, arg1)
+	ret0, _ := ret[0].(sendConn)
+	return ret0
+}
+
+// withRemoteAddr indicates an expected call of withRemoteAddr.
+func (mr *MockSendConnMockRecorder) withRemoteAddr(arg0, arg1 any) *MockSendConnwithRemoteAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "withRemoteAddr", reflect.TypeOf((*MockSendConn)(nil).withRemoteAddr), arg0, arg1)
+	return &MockSendConnwithRemoteAddrCall{Call: call}
+}
+
+// MockSendConnwithRemoteAddrCall wrap *gomock.Call
+type MockSendConnwithRemoteAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendConnwithRemoteAddrCall) Return(arg0 sendConn) *MockSendConnwithRemoteAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendConnwithRemoteAddrCall) Do(f func(net.Addr, packetInfo) sendConn) *MockSendConnwithRemoteAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendConnwithRemoteAddrCall) DoAndReturn(f func(net.Addr, packetInfo) sendConn) *MockSendConnwithRemoteAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,595 @@
+package quic
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// PathID identifies a path of a connection that uses the multipath extension.
+// Path 0 is the path that the handshake was performed on.
+type PathID = protocol.PathID
+
+// ErrPathLimitReached is returned by Connection.OpenPath when the path limit set by
+// the peer or by us is reached.
+var ErrPathLimitReached = errors.New("path limit reached")
+
+// A MultipathPath is a path of a connection that uses the multipath extension.
+// Paths are opened by the client using Connection.OpenPath.
+type MultipathPath struct {
+	id         PathID
+	m          *multipathManager
+	localAddr  net.Addr
+	remoteAddr net.Addr
+
+	abandoned chan struct{} // closed when the path is abandoned
+}
+
+// ID returns the path ID.
+func (p *MultipathPath) ID() PathID { return p.id }
+
+// LocalAddr returns the local address of the path.
+func (p *MultipathPath) LocalAddr() net.Addr { return p.localAddr }
+
+// RemoteAddr returns the address of the peer on this path.
+func (p *MultipathPath) RemoteAddr() net.Addr { return p.remoteAddr }
+
+// SetStandby asks the peer to not send packets on this path, unless no other path is available.
+// Calling it with standby set to false makes the path available again.
+func (p *MultipathPath) SetStandby(standby bool) error {
+	return p.m.setStandby(p.id, standby)
+}
+
+// Close abandons the path.
+// Packets in flight on this path are retransmitted on the remaining paths.
+// It is not possible to close path 0.
+func (p *MultipathPath) Close() error {
+	if p.id == 0 {
+		return errors.New("can't close path 0")
+	}
+	return p.m.closePath(p.id)
+}
+
+// pathStatus is the status of a path, as signaled by PATH_STANDBY and PATH_AVAILABLE frames.
+type pathStatus struct {
+	standby bool
+	// The sequence number of the PATH_STANDBY / PATH_AVAILABLE frame that set the status.
+	// A status can only be updated by a frame with a larger sequence number.
+	seq uint64
+}
+
+// update updates the path status, if the sequence number is larger than the current one.
+func (s *pathStatus) update(standby bool, seq uint64) {
+	if s.seq != 0 && seq <= s.seq {
+		return
+	}
+	s.seq = seq
+	s.standby = standby
+}
+
+type multipathPath struct {
+	id     PathID
+	handle *MultipathPath
+	tr     *Transport // only set for the client
+
+	// These fields are only accessed from the connection's run loop,
+	// once the path was set up.
+	conn          sendConn
+	sendQueue     sender
+	rttStats      *utils.RTTStats
+	sph           ackhandler.SentPacketHandler
+	rph           ackhandler.ReceivedPacketHandler
+	packer        *packetPacker
+	connIDManager *connIDManager
+	largestRcvd   protocol.PacketNumber
+
+	// the following fields are protected by the mutex of the multipathManager
+	isSetUp        bool
+	validated      bool
+	validatedChan  chan struct{}
+	pathChallenges [][8]byte // length is implicitly limited by exponential backoff
+	needsProbe     bool
+	closeRequested bool
+	peerStatus     pathStatus
+	localStatusSeq uint64
+}
+
+// The multipathManager manages the paths of a connection that negotiated the multipath extension.
+// Apart from the methods used by MultipathPath and by Connection.OpenPath, which are called from
+// arbitrary go routines, its methods are called from the connection's run loop.
+type multipathManager struct {
+	perspective     protocol.Perspective
+	scheduler       PathScheduler
+	queueFrame      func(wire.Frame)
+	scheduleSending func()
+
+	mx sync.Mutex
+	// path 0 is not contained in this map
+	paths          map[PathID]*multipathPath
+	path0          *MultipathPath
+	path0Status    pathStatus
+	path0LocalSeq  uint64
+	abandonedPaths map[PathID]struct{}
+	// connection IDs the peer issued for paths other than path 0
+	connIDManagers map[PathID]*connIDManager
+	nextPathID     PathID // only used by the client
+	localMaxPathID PathID
+	peerMaxPathID  PathID
+	// the largest path ID that connection IDs were issued for
+	issuedMaxPathID PathID
+	// the PTO of path 0, used as the initial timeout when probing new paths
+	pto time.Duration
+}
+
+func newMultipathManager(
+	perspective protocol.Perspective,
+	scheduler PathScheduler,
+	localMaxPathID, peerMaxPathID PathID,
+	localAddr, remoteAddr net.Addr,
+	queueFrame func(wire.Frame),
+	scheduleSending func(),
+) *multipathManager {
+	m := &multipathManager{
+		perspective:     perspective,
+		scheduler:       scheduler,
+		queueFrame:      queueFrame,
+		scheduleSending: scheduleSending,
+		paths:           make(map[PathID]*multipathPath),
+		abandonedPaths:  make(map[PathID]struct{}),
+		connIDManagers:  make(map[PathID]*connIDManager),
+		nextPathID:      1,
+		localMaxPathID:  localMaxPathID,
+		peerMaxPathID:   peerMaxPathID,
+	}
+	m.path0 = &MultipathPath{
+		id:         0,
+		m:          m,
+		localAddr:  localAddr,
+		remoteAddr: remoteAddr,
+		abandoned:  make(chan struct{}),
+	}
+	return m
+}
+
+// maxPathID is the largest path ID that can be used.
+func (m *multipathManager) maxPathID() PathID {
+	return min(m.localMaxPathID, m.peerMaxPathID)
+}
+
+// PathsToIssueConnIDs returns the path IDs that connection IDs need to be issued for.
+// It is called after the multipath extension was negotiated, and every time the path limit is increased.
+func (m *multipathManager) PathsToIssueConnIDs() []PathID {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	var ids []PathID
+	for m.issuedMaxPathID < m.maxPathID() {
+		m.issuedMaxPathID++
+		ids = append(ids, m.issuedMaxPathID)
+	}
+	return ids
+}
+
+// NewPath creates a new path.
+// It is called by the client, when opening a new path.
+func (m *multipathManager) NewPath(tr *Transport, conn sendConn) (*MultipathPath, *multipathPath, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if m.nextPathID > m.maxPathID() {
+		return nil, nil, ErrPathLimitReached
+	}
+	if _, ok := m.connIDManagers[m.nextPathID]; !ok {
+		return nil, nil, errors.New("no connection ID available for the new path")
+	}
+	p := m.newPath(m.nextPathID, conn)
+	p.tr = tr
+	p.needsProbe = true
+	m.nextPathID++
+	m.scheduleSending()
+	return p.handle, p, nil
+}
+
+// AddIncomingPath creates a path when receiving the first packet on it.
+// It is called by the server.
+// It returns false if the path can't be used.
+func (m *multipathManager) AddIncomingPath(id PathID, conn sendConn) (*multipathPath, bool) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if id > m.maxPathID() {
+		return nil, false
+	}
+	if _, ok := m.abandonedPaths[id]; ok {
+		return nil, false
+	}
+	if _, ok := m.connIDManagers[id]; !ok {
+		return nil, false
+	}
+	return m.newPath(id, conn), true
+}
+
+func (m *multipathManager) newPath(id PathID, conn sendConn) *multipathPath {
+	p := &multipathPath{
+		id:            id,
+		conn:          conn,
+		largestRcvd:   protocol.InvalidPacketNumber,
+		validatedChan: make(chan struct{}),
+	}
+	p.handle = &MultipathPath{
+		id:         id,
+		m:          m,
+		localAddr:  conn.LocalAddr(),
+		remoteAddr: conn.RemoteAddr(),
+		abandoned:  make(chan struct{}),
+	}
+	m.paths[id] = p
+	return p
+}
+
+// Path returns a path that was set up.
+func (m *multipathManager) Path(id PathID) (*multipathPath, bool) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	p, ok := m.paths[id]
+	if !ok || !p.isSetUp {
+		return nil, false
+	}
+	return p, true
+}
+
+// PathsToSetUp returns the paths that were created using NewPath, and need to be set up by the run loop.
+func (m *multipathManager) PathsToSetUp() []*multipathPath {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	var paths []*multipathPath
+	for _, p := range m.paths {
+		if !p.isSetUp {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// SetUp is called once the run loop has set up the path.
+// It returns the connIDManager for that path.
+func (m *multipathManager) SetUp(p *multipathPath) *connIDManager {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	p.isSetUp = true
+	return m.connIDManagers[p.id]
+}
+
+// ActivePaths returns all paths that were set up and are not being closed.
+func (m *multipathManager) ActivePaths() []*multipathPath {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	paths := make([]*multipathPath, 0, len(m.paths))
+	for _, p := range m.paths {
+		if p.isSetUp && !p.closeRequested {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// ValidatedPaths returns all paths that were validated and are not being closed.
+func (m *multipathManager) ValidatedPaths() []*multipathPath {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	paths := make([]*multipathPath, 0, len(m.paths))
+	for _, p := range m.paths {
+		if p.isSetUp && p.validated && !p.closeRequested {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// HasValidatedPaths says if there are paths other than path 0 that packets can be sent on.
+func (m *multipathManager) HasValidatedPaths() bool {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	for _, p := range m.paths {
+		if p.isSetUp && p.validated && !p.closeRequested {
+			return true
+		}
+	}
+	return false
+}
+
+// PathsToClose returns the paths that the application closed.
+func (m *multipathManager) PathsToClose() []*multipathPath {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	var paths []*multipathPath
+	for _, p := range m.paths {
+		if p.isSetUp && p.closeRequested {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// NextPathChallenge returns a PATH_CHALLENGE frame to be sent on a path.
+// It returns false if no path needs to be probed.
+func (m *multipathManager) NextPathChallenge() (*multipathPath, *wire.PathChallengeFrame, bool) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	for _, p := range m.paths {
+		if !p.isSetUp || !p.needsProbe || p.closeRequested {
+			continue
+		}
+		p.needsProbe = false
+		return p, m.newPathChallenge(p), true
+	}
+	return nil, nil, false
+}
+
+// PathChallengeForResponse returns the PATH_CHALLENGE frame that the server sends together with a PATH_RESPONSE,
+// in order to validate the client's address.
+// It returns nil if the path was already validated.
+func (m *multipathManager) PathChallengeForResponse(p *multipathPath) *wire.PathChallengeFrame {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if m.perspective == protocol.PerspectiveClient || p.validated {
+		return nil
+	}
+	return m.newPathChallenge(p)
+}
+
+func (m *multipathManager) newPathChallenge(p *multipathPath) *wire.PathChallengeFrame {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	p.pathChallenges = append(p.pathChallenges, b)
+	return &wire.PathChallengeFrame{Data: b}
+}
+
+// HandlePathResponseFrame handles a PATH_RESPONSE frame.
+// It returns the path that was validated, or false if the frame didn't validate any path.
+func (m *multipathManager) HandlePathResponseFrame(f *wire.PathResponseFrame) (*multipathPath, bool) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	for _, p := range m.paths {
+		if p.validated {
+			continue
+		}
+		for _, c := range p.pathChallenges {
+			if c == f.Data {
+				p.validated = true
+				p.pathChallenges = nil
+				close(p.validatedChan)
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// SetPTO sets the PTO of path 0.
+// It is called from the connection's run loop.
+func (m *multipathManager) SetPTO(pto time.Duration) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.pto = pto
+}
+
+// probe is called by the client to validate a new path.
+// PATH_CHALLENGE frames are retransmitted with an exponential backoff,
+// until the path is validated, or the context is canceled.
+func (m *multipathManager) probe(ctx, connCtx context.Context, p *multipathPath) error {
+	m.mx.Lock()
+	nextProbeDur := m.pto
+	m.mx.Unlock()
+	timer := time.NewTimer(nextProbeDur)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-connCtx.Done():
+			return context.Cause(connCtx)
+		case <-p.validatedChan:
+			return nil
+		case <-p.handle.abandoned:
+			return ErrPathClosed
+		case <-timer.C:
+			nextProbeDur *= 2 // exponential backoff
+			m.mx.Lock()
+			p.needsProbe = true
+			m.mx.Unlock()
+			m.scheduleSending()
+			timer.Reset(nextProbeDur)
+		}
+	}
+}
+
+// AddConnectionID handles a MP_NEW_CONNECTION_ID frame for a path other than path 0.
+// newConnIDManager is called when the first connection ID for a path is received.
+func (m *multipathManager) AddConnectionID(f *wire.MPNewConnectionIDFrame, newConnIDManager func() *connIDManager) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if f.PathID > m.localMaxPathID {
+		return errors.New("MP_NEW_CONNECTION_ID frame for a path ID exceeding the maximum path ID")
+	}
+	if _, ok := m.abandonedPaths[f.PathID]; ok {
+		return nil
+	}
+	h, ok := m.connIDManagers[f.PathID]
+	if !ok {
+		m.connIDManagers[f.PathID] = newConnIDManager()
+		return nil
+	}
+	return h.Add(&f.NewConnectionIDFrame)
+}
+
+// HandleMaxPathIDFrame handles a MAX_PATH_ID frame.
+// It returns true if the peer increased the maximum path ID.
+func (m *multipathManager) HandleMaxPathIDFrame(f *wire.MaxPathIDFrame) bool {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if f.MaxPathID <= m.peerMaxPathID {
+		return false
+	}
+	m.peerMaxPathID = f.MaxPathID
+	return true
+}
+
+// HandlePathStatus handles PATH_STANDBY and PATH_AVAILABLE frames.
+func (m *multipathManager) HandlePathStatus(id PathID, standby bool, seq uint64) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if id == 0 {
+		m.path0Status.update(standby, seq)
+		return
+	}
+	if p, ok := m.paths[id]; ok {
+		p.peerStatus.update(standby, seq)
+	}
+}
+
+// Path0Standby says if the peer asked us to not use path 0.
+func (m *multipathManager) Path0Standby() bool {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	return m.path0Status.standby
+}
+
+// PeerStandby says if the peer asked us to not use a path.
+func (m *multipathManager) PeerStandby(p *multipathPath) bool {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	return p.peerStatus.standby
+}
+
+func (m *multipathManager) setStandby(id PathID, standby bool) error {
+	m.mx.Lock()
+	var seq uint64
+	if id == 0 {
+		m.path0LocalSeq++
+		seq = m.path0LocalSeq
+	} else {
+		p, ok := m.paths[id]
+		if !ok || p.closeRequested {
+			m.mx.Unlock()
+			return ErrPathClosed
+		}
+		p.localStatusSeq++
+		seq = p.localStatusSeq
+	}
+	m.mx.Unlock()
+
+	if standby {
+		m.queueFrame(&wire.PathStandbyFrame{PathID: id, SequenceNumber: seq})
+	} else {
+		m.queueFrame(&wire.PathAvailableFrame{PathID: id, SequenceNumber: seq})
+	}
+	return nil
+}
+
+func (m *multipathManager) closePath(id PathID) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	p, ok := m.paths[id]
+	if !ok || p.closeRequested {
+		return ErrPathClosed
+	}
+	p.closeRequested = true
+	m.scheduleSending()
+	return nil
+}
+
+// Abandon marks a path as abandoned, and removes it.
+// The maximum path ID is increased by one, such that a new path can be opened.
+// It returns the path, if it was set up, and the new maximum path ID.
+// If the path was already abandoned, the maximum path ID is 0.
+func (m *multipathManager) Abandon(id PathID) (*multipathPath, *connIDManager, PathID) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if _, ok := m.abandonedPaths[id]; ok {
+		return nil, nil, 0
+	}
+	m.abandonedPaths[id] = struct{}{}
+	connIDManager := m.connIDManagers[id]
+	delete(m.connIDManagers, id)
+	if m.localMaxPathID < protocol.MaxPathID {
+		m.localMaxPathID++
+	}
+	p, ok := m.paths[id]
+	if !ok {
+		return nil, connIDManager, m.localMaxPathID
+	}
+	delete(m.paths, id)
+	p.closeRequested = true
+	close(p.handle.abandoned)
+	if !p.isSetUp {
+		return nil, connIDManager, m.localMaxPathID
+	}
+	return p, connIDManager, m.localMaxPathID
+}
+
+// IsAbandoned says if a path was abandoned.
+func (m *multipathManager) IsAbandoned(id PathID) bool {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	_, ok := m.abandonedPaths[id]
+	return ok
+}
+
+// Paths returns all paths, including path 0.
+func (m *multipathManager) Paths() []*MultipathPath {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	paths := make([]*MultipathPath, 0, len(m.paths)+1)
+	paths = append(paths, m.path0)
+	for _, p := range m.paths {
+		if p.isSetUp && !p.closeRequested {
+			paths = append(paths, p.handle)
+		}
+	}
+	return paths
+}
+
+// Close closes all paths.
+// It is called when the connection is closed.
+func (m *multipathManager) Close() {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	for _, p := range m.paths {
+		if p.sendQueue != nil {
+			p.sendQueue.Close()
+		}
+	}
+	for _, h := range m.connIDManagers {
+		h.Close()
+	}
+}
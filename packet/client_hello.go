@@ -0,0 +1,159 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/cryptobyte"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// ErrIncompleteClientHello is returned when the data passed to ParseClientHello
+// only contains the beginning of the ClientHello.
+var ErrIncompleteClientHello = errors.New("incomplete ClientHello")
+
+const (
+	typeClientHello = 1
+
+	extensionServerName              = 0
+	extensionALPN                    = 16
+	extensionSupportedVersions       = 43
+	extensionQUICTransportParameters = 57
+	extensionEncryptedClientHello    = 0xfe0d
+
+	serverNameTypeHostName = 0
+
+	clientHelloRandomLength           = 32
+	clientHelloMaxLegacySessionIDSize = 32
+)
+
+// A ClientHello is a TLS ClientHello, as sent by a QUIC client in its Initial packets.
+type ClientHello struct {
+	// Raw is the raw ClientHello handshake message, including the message header.
+	Raw []byte
+
+	Random       []byte
+	CipherSuites []uint16
+	// ServerName is the host name sent in the server_name extension (SNI).
+	// It is empty if the client didn't send the extension.
+	ServerName string
+	// ALPNProtocols is the list of application protocols offered by the client.
+	ALPNProtocols     []string
+	SupportedVersions []uint16
+	// UsesEncryptedClientHello is set if the client sent the encrypted_client_hello extension.
+	// In that case, ServerName is the public name, not the name of the backend server.
+	UsesEncryptedClientHello bool
+	// TransportParameters are the QUIC transport parameters sent by the client.
+	// It is nil if the client didn't send the extension.
+	TransportParameters *TransportParameters
+}
+
+// ParseClientHello parses a TLS ClientHello.
+// The data is the contents of the Initial crypto stream, starting at offset 0.
+// If the data only contains the beginning of the ClientHello, ErrIncompleteClientHello is returned.
+func ParseClientHello(data []byte) (*ClientHello, error) {
+	if len(data) < 4 {
+		return nil, ErrIncompleteClientHello
+	}
+	if data[0] != typeClientHello {
+		return nil, fmt.Errorf("unexpected TLS handshake message type: %d", data[0])
+	}
+	length := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+	if len(data) < 4+length {
+		return nil, ErrIncompleteClientHello
+	}
+	raw := data[:4+length]
+	s := cryptobyte.String(raw[4:])
+
+	ch := &ClientHello{Raw: raw}
+	var legacyVersion uint16
+	var sessionID, cipherSuites, compressionMethods, extensions cryptobyte.String
+	if !s.ReadUint16(&legacyVersion) ||
+		!s.ReadBytes(&ch.Random, clientHelloRandomLength) ||
+		!s.ReadUint8LengthPrefixed(&sessionID) ||
+		len(sessionID) > clientHelloMaxLegacySessionIDSize ||
+		!s.ReadUint16LengthPrefixed(&cipherSuites) ||
+		!s.ReadUint8LengthPrefixed(&compressionMethods) {
+		return nil, errors.New("malformed ClientHello")
+	}
+	for !cipherSuites.Empty() {
+		var suite uint16
+		if !cipherSuites.ReadUint16(&suite) {
+			return nil, errors.New("malformed ClientHello: invalid cipher suites")
+		}
+		ch.CipherSuites = append(ch.CipherSuites, suite)
+	}
+	// TLS 1.3 requires the extensions to be present
+	if !s.ReadUint16LengthPrefixed(&extensions) || !s.Empty() {
+		return nil, errors.New("malformed ClientHello: invalid extensions")
+	}
+	for !extensions.Empty() {
+		var extType uint16
+		var extData cryptobyte.String
+		if !extensions.ReadUint16(&extType) || !extensions.ReadUint16LengthPrefixed(&extData) {
+			return nil, errors.New("malformed ClientHello: invalid extension")
+		}
+		if err := ch.parseExtension(extType, extData); err != nil {
+			return nil, err
+		}
+	}
+	return ch, nil
+}
+
+func (ch *ClientHello) parseExtension(extType uint16, data cryptobyte.String) error {
+	switch extType {
+	case extensionServerName:
+		var names cryptobyte.String
+		if !data.ReadUint16LengthPrefixed(&names) || !data.Empty() {
+			return errors.New("malformed ClientHello: invalid server_name extension")
+		}
+		for !names.Empty() {
+			var nameType uint8
+			var name cryptobyte.String
+			if !names.ReadUint8(&nameType) || !names.ReadUint16LengthPrefixed(&name) {
+				return errors.New("malformed ClientHello: invalid server_name extension")
+			}
+			if nameType != serverNameTypeHostName {
+				continue
+			}
+			if ch.ServerName != "" {
+				return errors.New("malformed ClientHello: multiple host names in server_name extension")
+			}
+			ch.ServerName = string(name)
+		}
+	case extensionALPN:
+		var protos cryptobyte.String
+		if !data.ReadUint16LengthPrefixed(&protos) || !data.Empty() {
+			return errors.New("malformed ClientHello: invalid ALPN extension")
+		}
+		for !protos.Empty() {
+			var proto cryptobyte.String
+			if !protos.ReadUint8LengthPrefixed(&proto) || proto.Empty() {
+				return errors.New("malformed ClientHello: invalid ALPN extension")
+			}
+			ch.ALPNProtocols = append(ch.ALPNProtocols, string(proto))
+		}
+	case extensionSupportedVersions:
+		var versions cryptobyte.String
+		if !data.ReadUint8LengthPrefixed(&versions) || !data.Empty() {
+			return errors.New("malformed ClientHello: invalid supported_versions extension")
+		}
+		for !versions.Empty() {
+			var v uint16
+			if !versions.ReadUint16(&v) {
+				return errors.New("malformed ClientHello: invalid supported_versions extension")
+			}
+			ch.SupportedVersions = append(ch.SupportedVersions, v)
+		}
+	case extensionEncryptedClientHello:
+		ch.UsesEncryptedClientHello = true
+	case extensionQUICTransportParameters:
+		tp := &TransportParameters{}
+		if err := tp.Unmarshal(data, protocol.PerspectiveClient); err != nil {
+			return err
+		}
+		ch.TransportParameters = tp
+	}
+	return nil
+}
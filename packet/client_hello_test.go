@@ -0,0 +1,62 @@
+package packet
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientHello(t *testing.T) {
+	data := getClientHello(t, "example.com", []string{"h3", "h3-29"})
+	ch, err := ParseClientHello(data)
+	require.NoError(t, err)
+	require.Equal(t, data, ch.Raw)
+	require.Len(t, ch.Random, 32)
+	require.Contains(t, ch.CipherSuites, tls.TLS_AES_128_GCM_SHA256)
+	require.Equal(t, "example.com", ch.ServerName)
+	require.Equal(t, []string{"h3", "h3-29"}, ch.ALPNProtocols)
+	require.Equal(t, []uint16{tls.VersionTLS13}, ch.SupportedVersions)
+	require.False(t, ch.UsesEncryptedClientHello)
+	require.NotNil(t, ch.TransportParameters)
+	require.Equal(t, protocol.ParseConnectionID([]byte{1, 2, 3, 4}), ch.TransportParameters.InitialSourceConnectionID)
+	require.Equal(t, ByteCount(1452), ch.TransportParameters.MaxUDPPayloadSize)
+}
+
+func TestParseClientHelloWithoutSNI(t *testing.T) {
+	ch, err := ParseClientHello(getClientHello(t, "", []string{"h3"}))
+	require.NoError(t, err)
+	require.Empty(t, ch.ServerName)
+}
+
+func TestParseClientHelloIncomplete(t *testing.T) {
+	data := getClientHello(t, "example.com", []string{"h3"})
+	for _, l := range []int{0, 3, 4, 100, len(data) - 1} {
+		_, err := ParseClientHello(data[:l])
+		require.ErrorIs(t, err, ErrIncompleteClientHello)
+	}
+	// additional data is ignored
+	ch, err := ParseClientHello(append(data, []byte("foobar")...))
+	require.NoError(t, err)
+	require.Equal(t, data, ch.Raw)
+}
+
+func TestParseClientHelloErrors(t *testing.T) {
+	t.Run("wrong message type", func(t *testing.T) {
+		data := getClientHello(t, "example.com", []string{"h3"})
+		data[0] = 2 // ServerHello
+		_, err := ParseClientHello(data)
+		require.EqualError(t, err, "unexpected TLS handshake message type: 2")
+	})
+
+	t.Run("truncated message", func(t *testing.T) {
+		data := getClientHello(t, "example.com", []string{"h3"})
+		// shorten the message length, cutting off the extensions
+		l := len(data) - 4 - 10
+		data[1], data[2], data[3] = byte(l>>16), byte(l>>8), byte(l)
+		_, err := ParseClientHello(data[:4+l])
+		require.ErrorContains(t, err, "malformed ClientHello")
+	})
+}
@@ -0,0 +1,139 @@
+package packet
+
+import (
+	"slices"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// A Frame is a QUIC frame.
+// It can be serialized using its Append method.
+type Frame = wire.Frame
+
+// The AckRange is used within the AckFrame.
+// It is a range of packet numbers that is being acknowledged.
+type AckRange = wire.AckRange
+
+type (
+	// An AckFrame is an ACK frame.
+	AckFrame = wire.AckFrame
+	// A ConnectionCloseFrame is a CONNECTION_CLOSE frame.
+	ConnectionCloseFrame = wire.ConnectionCloseFrame
+	// A CryptoFrame is a CRYPTO frame.
+	CryptoFrame = wire.CryptoFrame
+	// A DataBlockedFrame is a DATA_BLOCKED frame.
+	DataBlockedFrame = wire.DataBlockedFrame
+	// A DatagramFrame is a DATAGRAM frame (RFC 9221).
+	DatagramFrame = wire.DatagramFrame
+	// A HandshakeDoneFrame is a HANDSHAKE_DONE frame.
+	HandshakeDoneFrame = wire.HandshakeDoneFrame
+	// A MaxDataFrame is a MAX_DATA frame.
+	MaxDataFrame = wire.MaxDataFrame
+	// A MaxStreamDataFrame is a MAX_STREAM_DATA frame.
+	MaxStreamDataFrame = wire.MaxStreamDataFrame
+	// A MaxStreamsFrame is a MAX_STREAMS frame.
+	MaxStreamsFrame = wire.MaxStreamsFrame
+	// A NewConnectionIDFrame is a NEW_CONNECTION_ID frame.
+	NewConnectionIDFrame = wire.NewConnectionIDFrame
+	// A NewTokenFrame is a NEW_TOKEN frame.
+	NewTokenFrame = wire.NewTokenFrame
+	// A PathChallengeFrame is a PATH_CHALLENGE frame.
+	PathChallengeFrame = wire.PathChallengeFrame
+	// A PathResponseFrame is a PATH_RESPONSE frame.
+	PathResponseFrame = wire.PathResponseFrame
+	// A PingFrame is a PING frame.
+	PingFrame = wire.PingFrame
+	// A ResetStreamFrame is a RESET_STREAM frame.
+	// If the ReliableSize is non-zero, it is a RESET_STREAM_AT frame.
+	ResetStreamFrame = wire.ResetStreamFrame
+	// A RetireConnectionIDFrame is a RETIRE_CONNECTION_ID frame.
+	RetireConnectionIDFrame = wire.RetireConnectionIDFrame
+	// A StopSendingFrame is a STOP_SENDING frame.
+	StopSendingFrame = wire.StopSendingFrame
+	// A StreamFrame is a STREAM frame.
+	StreamFrame = wire.StreamFrame
+	// A StreamsBlockedFrame is a STREAMS_BLOCKED frame.
+	StreamsBlockedFrame = wire.StreamsBlockedFrame
+	// A StreamDataBlockedFrame is a STREAM_DATA_BLOCKED frame.
+	StreamDataBlockedFrame = wire.StreamDataBlockedFrame
+)
+
+// Frames defined by the multipath extension.
+type (
+	// A PathAckFrame is a PATH_ACK frame.
+	PathAckFrame = wire.PathAckFrame
+	// A PathAbandonFrame is a PATH_ABANDON frame.
+	PathAbandonFrame = wire.PathAbandonFrame
+	// A PathStandbyFrame is a PATH_STANDBY frame.
+	PathStandbyFrame = wire.PathStandbyFrame
+	// A PathAvailableFrame is a PATH_AVAILABLE frame.
+	PathAvailableFrame = wire.PathAvailableFrame
+	// An MPNewConnectionIDFrame is an MP_NEW_CONNECTION_ID frame.
+	MPNewConnectionIDFrame = wire.MPNewConnectionIDFrame
+	// An MPRetireConnectionIDFrame is an MP_RETIRE_CONNECTION_ID frame.
+	MPRetireConnectionIDFrame = wire.MPRetireConnectionIDFrame
+	// A MaxPathIDFrame is a MAX_PATH_ID frame.
+	MaxPathIDFrame = wire.MaxPathIDFrame
+)
+
+// Frames defined by the ACK frequency extension.
+type (
+	// An AckFrequencyFrame is an ACK_FREQUENCY frame.
+	AckFrequencyFrame = wire.AckFrequencyFrame
+	// An ImmediateAckFrame is an IMMEDIATE_ACK frame.
+	ImmediateAckFrame = wire.ImmediateAckFrame
+)
+
+// The FrameParser parses QUIC frames, one by one.
+// It parses the frames of all extensions supported by quic-go.
+type FrameParser struct {
+	parser *wire.FrameParser
+}
+
+// NewFrameParser creates a new frame parser.
+// The ACK delay exponent is the value sent by the peer in its transport parameters.
+// It is only used for ACK frames in 1-RTT packets.
+func NewFrameParser(ackDelayExponent uint8) *FrameParser {
+	p := wire.NewFrameParser(true, true, true)
+	p.SetSupportsMultipath()
+	p.SetAckDelayExponent(ackDelayExponent)
+	return &FrameParser{parser: p}
+}
+
+// ParseNext parses the next frame.
+// It skips PADDING frames, and returns a nil frame if only PADDING frames are left.
+// It returns an error if the frame is not allowed at the encryption level.
+func (p *FrameParser) ParseNext(data []byte, encLevel EncryptionLevel, v Version) (int, Frame, error) {
+	l, f, err := p.parser.ParseNext(data, encLevel, v)
+	if err != nil {
+		return l, nil, err
+	}
+	// The wire.FrameParser reuses the ACK frame.
+	if ack, ok := f.(*wire.AckFrame); ok {
+		af := *ack
+		af.AckRanges = slices.Clone(ack.AckRanges)
+		f = &af
+	}
+	return l, f, nil
+}
+
+// ParseFrames parses all frames contained in the (decrypted) payload of a packet.
+// PADDING frames are skipped.
+// ACK frames in 1-RTT packets are parsed assuming the default ACK delay exponent.
+func ParseFrames(data []byte, encLevel EncryptionLevel, v Version) ([]Frame, error) {
+	p := NewFrameParser(protocol.DefaultAckDelayExponent)
+	var frames []Frame
+	for len(data) > 0 {
+		l, f, err := p.ParseNext(data, encLevel, v)
+		if err != nil {
+			return nil, err
+		}
+		data = data[l:]
+		if f == nil {
+			break
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
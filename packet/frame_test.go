@@ -0,0 +1,49 @@
+package packet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFrames(t *testing.T) {
+	frames := []Frame{
+		&AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 10}}},
+		&PingFrame{},
+		&MaxDataFrame{MaximumData: 1337},
+		&StreamFrame{StreamID: 4, Data: []byte("foobar"), DataLenPresent: true},
+	}
+	var b []byte
+	for _, f := range frames {
+		var err error
+		b, err = f.Append(b, Version1)
+		require.NoError(t, err)
+	}
+	b = append(b, make([]byte, 10)...) // PADDING
+
+	parsed, err := ParseFrames(b, Encryption1RTT, Version1)
+	require.NoError(t, err)
+	require.Equal(t, frames, parsed)
+}
+
+func TestFrameParserCopiesAckFrames(t *testing.T) {
+	p := NewFrameParser(3)
+	b1, err := (&AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 10}}}).Append(nil, Version1)
+	require.NoError(t, err)
+	b2, err := (&AckFrame{AckRanges: []AckRange{{Smallest: 20, Largest: 30}}}).Append(nil, Version1)
+	require.NoError(t, err)
+
+	_, f1, err := p.ParseNext(b1, EncryptionInitial, Version1)
+	require.NoError(t, err)
+	_, f2, err := p.ParseNext(b2, EncryptionInitial, Version1)
+	require.NoError(t, err)
+	require.Equal(t, []AckRange{{Smallest: 1, Largest: 10}}, f1.(*AckFrame).AckRanges)
+	require.Equal(t, []AckRange{{Smallest: 20, Largest: 30}}, f2.(*AckFrame).AckRanges)
+}
+
+func TestParseFramesForbiddenFrame(t *testing.T) {
+	b, err := (&MaxDataFrame{MaximumData: 1337}).Append(nil, Version1)
+	require.NoError(t, err)
+	_, err = ParseFrames(b, EncryptionInitial, Version1)
+	require.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/handshake"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+type (
+	// A LongHeaderSealer seals (encrypts) Long Header packets, and applies header protection.
+	LongHeaderSealer = handshake.LongHeaderSealer
+	// A LongHeaderOpener opens (decrypts) Long Header packets, and removes header protection.
+	LongHeaderOpener = handshake.LongHeaderOpener
+)
+
+// ErrInvalidReservedBits is returned when the reserved bits of a packet are not 0.
+// The packet was still decrypted successfully, but the endpoint would close the connection.
+var ErrInvalidReservedBits = wire.ErrInvalidReservedBits
+
+// ErrDecryptionFailed is returned when a packet fails to decrypt.
+var ErrDecryptionFailed = handshake.ErrDecryptionFailed
+
+// NewInitialAEAD derives the Initial keys (RFC 9001, section 5.2) from the connection ID.
+// The connection ID is the Destination Connection ID of the first Initial packet sent by the client.
+// The Perspective is the perspective of the endpoint using the keys:
+// to decrypt the Initial packets sent by a client, PerspectiveServer needs to be used.
+func NewInitialAEAD(connID ConnectionID, pers Perspective, v Version) (LongHeaderSealer, LongHeaderOpener) {
+	return handshake.NewInitialAEAD(connID, pers, v)
+}
+
+// OpenInitialPacket removes header protection from an Initial packet, and decrypts its payload.
+// The packet is the (coalesced) packet as returned by ParseLongHeader.
+// Decryption happens in place, i.e. the contents of packet are modified.
+// The payload can be parsed using ParseFrames.
+func OpenInitialPacket(opener LongHeaderOpener, hdr *Header, packet []byte) (*ExtendedHeader, []byte, error) {
+	if hdr.Type != protocol.PacketTypeInitial {
+		return nil, nil, fmt.Errorf("not an Initial packet: %s", hdr.Type)
+	}
+	hdrLen := int(hdr.ParsedLen())
+	if len(packet) < hdrLen+4+16 {
+		return nil, nil, fmt.Errorf("packet too small: expected at least 20 bytes after the header, got %d", len(packet)-hdrLen)
+	}
+	// The packet number can be up to 4 bytes long, but we won't know the length until we decrypt it.
+	origPNBytes := make([]byte, 4)
+	copy(origPNBytes, packet[hdrLen:hdrLen+4])
+	opener.DecryptHeader(packet[hdrLen+4:hdrLen+4+16], &packet[0], packet[hdrLen:hdrLen+4])
+	extHdr, parseErr := hdr.ParseExtended(packet)
+	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
+		return nil, nil, parseErr
+	}
+	// if the packet number is shorter than 4 bytes, restore the bytes that belong to the payload
+	if extHdr.PacketNumberLen != protocol.PacketNumberLen4 {
+		copy(packet[extHdr.ParsedLen():hdrLen+4], origPNBytes[int(extHdr.PacketNumberLen):])
+	}
+	extHdrLen := extHdr.ParsedLen()
+	extHdr.PacketNumber = opener.DecodePacketNumber(extHdr.PacketNumber, extHdr.PacketNumberLen)
+	payload, err := opener.Open(packet[extHdrLen:extHdrLen], packet[extHdrLen:], extHdr.PacketNumber, packet[:extHdrLen])
+	if err != nil {
+		return nil, nil, err
+	}
+	return extHdr, payload, parseErr
+}
+
+// ParseClientHelloFromDatagram decrypts the Initial packets sent by a client in a UDP datagram,
+// and parses the ClientHello contained in their CRYPTO frames.
+// This can be used to route QUIC connections based on the SNI.
+// The datagram is not modified.
+// If the ClientHello is split across multiple datagrams, ErrIncompleteClientHello is returned.
+// In that case, the CRYPTO frames of the following datagrams need to be reassembled by the caller,
+// and the ClientHello can be parsed using ParseClientHello.
+func ParseClientHelloFromDatagram(datagram []byte) (*ClientHello, error) {
+	data := make([]byte, len(datagram))
+	copy(data, datagram)
+
+	var frames []*CryptoFrame
+	var connID ConnectionID
+	var opener LongHeaderOpener
+	for len(data) > 0 {
+		if !wire.IsLongHeaderPacket(data[0]) {
+			break
+		}
+		hdr, packet, rest, err := ParseLongHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		data = rest
+		if hdr.Type != protocol.PacketTypeInitial {
+			continue
+		}
+		// All Initial packets in a datagram use the same connection ID, and therefore the same keys.
+		if opener == nil || hdr.DestConnectionID != connID {
+			connID = hdr.DestConnectionID
+			_, opener = NewInitialAEAD(connID, protocol.PerspectiveServer, hdr.Version)
+		}
+		_, payload, err := OpenInitialPacket(opener, hdr, packet)
+		if err != nil {
+			return nil, err
+		}
+		fs, err := ParseFrames(payload, protocol.EncryptionInitial, hdr.Version)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fs {
+			if cf, ok := f.(*CryptoFrame); ok {
+				frames = append(frames, cf)
+			}
+		}
+	}
+	if len(frames) == 0 {
+		return nil, errors.New("no CRYPTO frames found in Initial packets")
+	}
+	return ParseClientHello(assembleCryptoData(frames))
+}
+
+// assembleCryptoData returns the contiguous data starting at offset 0.
+// CRYPTO frames can arrive in any order, and might overlap.
+func assembleCryptoData(frames []*CryptoFrame) []byte {
+	var data []byte
+	for {
+		var added bool
+		for _, f := range frames {
+			start := f.Offset
+			end := f.Offset + protocol.ByteCount(len(f.Data))
+			offset := protocol.ByteCount(len(data))
+			if start > offset || end <= offset {
+				continue
+			}
+			data = append(data, f.Data[offset-start:]...)
+			added = true
+		}
+		if !added {
+			return data
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package packet
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getClientHello(t *testing.T, serverName string, alpn []string) []byte {
+	t.Helper()
+	tp := &TransportParameters{
+		InitialSourceConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+		MaxIdleTimeout:            1337,
+		MaxUDPPayloadSize:         1452,
+		ActiveConnectionIDLimit:   4,
+	}
+	conn := tls.QUICClient(&tls.QUICConfig{
+		TLSConfig: &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: serverName == "",
+			NextProtos:         alpn,
+			MinVersion:         tls.VersionTLS13,
+		},
+	})
+	conn.SetTransportParameters(tp.Marshal(protocol.PerspectiveClient))
+	require.NoError(t, conn.Start(context.Background()))
+	defer conn.Close()
+	for {
+		ev := conn.NextEvent()
+		switch ev.Kind {
+		case tls.QUICNoEvent:
+			t.Fatal("didn't receive the ClientHello")
+		case tls.QUICWriteData:
+			require.Equal(t, tls.QUICEncryptionLevelInitial, ev.Level)
+			return ev.Data
+		}
+	}
+}
+
+func composeInitialPacket(t *testing.T, connID ConnectionID, v Version, pn PacketNumber, frames ...Frame) []byte {
+	t.Helper()
+	sealer, _ := NewInitialAEAD(connID, PerspectiveClient, v)
+	var payload []byte
+	for _, f := range frames {
+		var err error
+		payload, err = f.Append(payload, v)
+		require.NoError(t, err)
+	}
+	if len(payload) < 16 {
+		payload = append(payload, make([]byte, 16-len(payload))...)
+	}
+	hdr := &ExtendedHeader{
+		Header: Header{
+			Type:             PacketTypeInitial,
+			Version:          v,
+			DestConnectionID: connID,
+			SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 6, 7, 8}),
+			Length:           ByteCount(int(PacketNumberLen2) + len(payload) + sealer.Overhead()),
+		},
+		PacketNumber:    pn,
+		PacketNumberLen: PacketNumberLen2,
+	}
+	b, err := hdr.Append(nil, v)
+	require.NoError(t, err)
+	hdrLen := len(b)
+	b = sealer.Seal(b, payload, pn, b)
+	pnOffset := hdrLen - int(PacketNumberLen2)
+	sealer.EncryptHeader(b[pnOffset+4:pnOffset+4+16], &b[0], b[pnOffset:hdrLen])
+	return b
+}
+
+func TestOpenInitialPacket(t *testing.T) {
+	for _, v := range []Version{Version1, Version2} {
+		t.Run(v.String(), func(t *testing.T) {
+			connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe, 0x13, 0x37})
+			b := composeInitialPacket(t, connID, v, 42, &CryptoFrame{Data: []byte("foobar")}, &PingFrame{})
+
+			hdr, packet, rest, err := ParseLongHeader(b)
+			require.NoError(t, err)
+			require.Empty(t, rest)
+			require.Equal(t, PacketTypeInitial, hdr.Type)
+			require.Equal(t, connID, hdr.DestConnectionID)
+
+			_, opener := NewInitialAEAD(connID, PerspectiveServer, v)
+			extHdr, payload, err := OpenInitialPacket(opener, hdr, packet)
+			require.NoError(t, err)
+			require.Equal(t, PacketNumber(42), extHdr.PacketNumber)
+			require.Equal(t, PacketNumberLen2, extHdr.PacketNumberLen)
+			frames, err := ParseFrames(payload, EncryptionInitial, v)
+			require.NoError(t, err)
+			require.Equal(t, []Frame{&CryptoFrame{Data: []byte("foobar")}, &PingFrame{}}, frames)
+		})
+	}
+}
+
+func TestOpenInitialPacketWrongKeys(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	b := composeInitialPacket(t, connID, Version1, 1, &PingFrame{})
+	hdr, packet, _, err := ParseLongHeader(b)
+	require.NoError(t, err)
+
+	// the client's keys can't be used to decrypt the client's packets
+	_, opener := NewInitialAEAD(connID, PerspectiveClient, Version1)
+	_, _, err = OpenInitialPacket(opener, hdr, packet)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestOpenInitialPacketRejectsOtherPacketTypes(t *testing.T) {
+	hdr := &ExtendedHeader{
+		Header: Header{
+			Type:             PacketTypeHandshake,
+			Version:          Version1,
+			DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+			Length:           100,
+		},
+		PacketNumberLen: PacketNumberLen1,
+	}
+	b, err := hdr.Append(nil, Version1)
+	require.NoError(t, err)
+	b = append(b, make([]byte, 99)...)
+	parsed, packet, _, err := ParseLongHeader(b)
+	require.NoError(t, err)
+	_, opener := NewInitialAEAD(hdr.DestConnectionID, PerspectiveServer, Version1)
+	_, _, err = OpenInitialPacket(opener, parsed, packet)
+	require.EqualError(t, err, "not an Initial packet: Handshake")
+}
+
+func TestParseClientHelloFromDatagram(t *testing.T) {
+	ch := getClientHello(t, "quic-go.net", []string{"h3"})
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	// split the ClientHello into multiple CRYPTO frames, sent out of order in two coalesced packets
+	third := len(ch) / 3
+	datagram := composeInitialPacket(t, connID, Version1, 0,
+		&CryptoFrame{Offset: ByteCount(2 * third), Data: ch[2*third:]},
+		&CryptoFrame{Offset: 0, Data: ch[:third+10]},
+	)
+	datagram = append(datagram, composeInitialPacket(t, connID, Version1, 1,
+		&CryptoFrame{Offset: ByteCount(third), Data: ch[third : 2*third]},
+	)...)
+	orig := make([]byte, len(datagram))
+	copy(orig, datagram)
+
+	hello, err := ParseClientHelloFromDatagram(datagram)
+	require.NoError(t, err)
+	require.Equal(t, orig, datagram) // the datagram is not modified
+	require.Equal(t, "quic-go.net", hello.ServerName)
+	require.Equal(t, []string{"h3"}, hello.ALPNProtocols)
+	require.Equal(t, ch, hello.Raw)
+}
+
+func TestParseClientHelloFromDatagramIncomplete(t *testing.T) {
+	ch := getClientHello(t, "quic-go.net", []string{"h3"})
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	datagram := composeInitialPacket(t, connID, Version1, 0, &CryptoFrame{Data: ch[:len(ch)-1]})
+	_, err := ParseClientHelloFromDatagram(datagram)
+	require.ErrorIs(t, err, ErrIncompleteClientHello)
+}
+
+func TestParseClientHelloFromDatagramNoCryptoFrames(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	datagram := composeInitialPacket(t, connID, Version1, 0, &PingFrame{})
+	_, err := ParseClientHelloFromDatagram(datagram)
+	require.EqualError(t, err, "no CRYPTO frames found in Initial packets")
+}
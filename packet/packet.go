@@ -0,0 +1,169 @@
+// Package packet parses and serializes QUIC packets, without the need to run a QUIC connection.
+// It is intended for tools that inspect QUIC traffic, such as load balancers routing packets
+// based on the connection ID or the SNI, or packet capture tools.
+// All functions in this package are stateless.
+//
+// This package should not be considered stable.
+package packet
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+type (
+	// A ByteCount is used to count bytes.
+	ByteCount = protocol.ByteCount
+	// A ConnectionID is a QUIC Connection ID, as defined in RFC 9000.
+	ConnectionID = protocol.ConnectionID
+	// An ArbitraryLenConnectionID is a QUIC Connection ID that can be up to 255 bytes long.
+	// Such Connection IDs are used in Version Negotiation packets.
+	ArbitraryLenConnectionID = protocol.ArbitraryLenConnectionID
+	// The EncryptionLevel is the encryption level of a packet.
+	EncryptionLevel = protocol.EncryptionLevel
+	// The KeyPhaseBit is the value of the key phase bit of the 1-RTT packets.
+	KeyPhaseBit = protocol.KeyPhaseBit
+	// The PacketNumber is the packet number of a packet.
+	PacketNumber = protocol.PacketNumber
+	// The PacketNumberLen is the length of the packet number on the wire.
+	PacketNumberLen = protocol.PacketNumberLen
+	// The PacketType is the type of a Long Header packet.
+	PacketType = protocol.PacketType
+	// The Perspective is the role of a QUIC endpoint (client or server).
+	Perspective = protocol.Perspective
+	// The StreamID is the stream ID.
+	StreamID = protocol.StreamID
+	// The Version is the QUIC version.
+	Version = protocol.Version
+
+	// The Header is the header of a Long Header packet, before removing header protection.
+	Header = wire.Header
+	// The ExtendedHeader is the header of a Long Header packet, after removing header protection.
+	// It can be serialized using its Append method.
+	ExtendedHeader = wire.ExtendedHeader
+	// The TransportParameters are QUIC transport parameters.
+	TransportParameters = wire.TransportParameters
+	// The PreferredAddress is the preferred address sent in the transport parameters.
+	PreferredAddress = wire.PreferredAddress
+	// The VersionInformation is sent in the version_information transport parameter (RFC 9368).
+	VersionInformation = wire.VersionInformation
+)
+
+const (
+	// Version1 is RFC 9000
+	Version1 = protocol.Version1
+	// Version2 is RFC 9369
+	Version2 = protocol.Version2
+)
+
+const (
+	// PacketTypeInitial is the packet type of an Initial packet
+	PacketTypeInitial = protocol.PacketTypeInitial
+	// PacketTypeRetry is the packet type of a Retry packet
+	PacketTypeRetry = protocol.PacketTypeRetry
+	// PacketTypeHandshake is the packet type of a Handshake packet
+	PacketTypeHandshake = protocol.PacketTypeHandshake
+	// PacketType0RTT is the packet type of a 0-RTT packet
+	PacketType0RTT = protocol.PacketType0RTT
+)
+
+const (
+	// PerspectiveServer is used for a QUIC server
+	PerspectiveServer = protocol.PerspectiveServer
+	// PerspectiveClient is used for a QUIC client
+	PerspectiveClient = protocol.PerspectiveClient
+)
+
+const (
+	// EncryptionInitial is the Initial encryption level
+	EncryptionInitial = protocol.EncryptionInitial
+	// EncryptionHandshake is the Handshake encryption level
+	EncryptionHandshake = protocol.EncryptionHandshake
+	// Encryption0RTT is the 0-RTT encryption level
+	Encryption0RTT = protocol.Encryption0RTT
+	// Encryption1RTT is the 1-RTT encryption level
+	Encryption1RTT = protocol.Encryption1RTT
+)
+
+const (
+	// KeyPhaseZero is key phase bit 0
+	KeyPhaseZero = protocol.KeyPhaseZero
+	// KeyPhaseOne is key phase bit 1
+	KeyPhaseOne = protocol.KeyPhaseOne
+)
+
+const (
+	// PacketNumberLen1 is a packet number length of 1 byte
+	PacketNumberLen1 = protocol.PacketNumberLen1
+	// PacketNumberLen2 is a packet number length of 2 bytes
+	PacketNumberLen2 = protocol.PacketNumberLen2
+	// PacketNumberLen3 is a packet number length of 3 bytes
+	PacketNumberLen3 = protocol.PacketNumberLen3
+	// PacketNumberLen4 is a packet number length of 4 bytes
+	PacketNumberLen4 = protocol.PacketNumberLen4
+)
+
+// ErrUnsupportedVersion is returned when parsing a Long Header packet of a QUIC version
+// that is not supported by this package.
+// Only the version-independent part of the header is parsed in that case.
+var ErrUnsupportedVersion = wire.ErrUnsupportedVersion
+
+// IsLongHeaderPacket says if this is a Long Header packet.
+func IsLongHeaderPacket(firstByte byte) bool {
+	return wire.IsLongHeaderPacket(firstByte)
+}
+
+// IsVersionNegotiationPacket says if this is a Version Negotiation packet.
+func IsVersionNegotiationPacket(b []byte) bool {
+	return wire.IsVersionNegotiationPacket(b)
+}
+
+// ParseConnectionID parses the Destination Connection ID of a packet.
+// Since Short Header packets don't encode the length of the connection ID,
+// it needs to be passed as shortHeaderConnIDLen.
+func ParseConnectionID(data []byte, shortHeaderConnIDLen int) (ConnectionID, error) {
+	return wire.ParseConnectionID(data, shortHeaderConnIDLen)
+}
+
+// ParseArbitraryLenConnectionIDs parses the version-independent part of a Long Header packet
+// (RFC 8999), and returns the connection IDs, which can be up to 255 bytes long.
+func ParseArbitraryLenConnectionIDs(data []byte) (bytesParsed int, dest, src ArbitraryLenConnectionID, _ error) {
+	return wire.ParseArbitraryLenConnectionIDs(data)
+}
+
+// ParseVersion parses the QUIC version of a Long Header packet.
+func ParseVersion(data []byte) (Version, error) {
+	return wire.ParseVersion(data)
+}
+
+// ParseLongHeader parses the header of a Long Header packet.
+// Since multiple Long Header packets can be coalesced into a single UDP datagram,
+// it returns the packet, cut according to the length field, and the remaining data.
+// For versions that are not supported, only the version-independent part of the header is parsed,
+// and ErrUnsupportedVersion is returned.
+// Packets with the QUIC bit set to 0 are accepted, since endpoints might grease the QUIC bit (RFC 9287).
+func ParseLongHeader(data []byte) (hdr *Header, packet []byte, rest []byte, _ error) {
+	return wire.ParsePacket(data, true)
+}
+
+// ParseShortHeader parses the header of a Short Header packet.
+// It must be called after header protection was removed.
+func ParseShortHeader(data []byte, connIDLen int) (length int, _ PacketNumber, _ PacketNumberLen, _ KeyPhaseBit, _ error) {
+	return wire.ParseShortHeader(data, connIDLen, true)
+}
+
+// AppendShortHeader appends the (unprotected) header of a Short Header packet.
+func AppendShortHeader(b []byte, connID ConnectionID, pn PacketNumber, pnLen PacketNumberLen, kp KeyPhaseBit) ([]byte, error) {
+	return wire.AppendShortHeader(b, connID, pn, pnLen, kp, false)
+}
+
+// ParseVersionNegotiationPacket parses a Version Negotiation packet.
+func ParseVersionNegotiationPacket(b []byte) (dest, src ArbitraryLenConnectionID, _ []Version, _ error) {
+	return wire.ParseVersionNegotiationPacket(b)
+}
+
+// ComposeVersionNegotiation composes a Version Negotiation packet.
+// A greased version is added to the list of versions.
+func ComposeVersionNegotiation(destConnID, srcConnID ArbitraryLenConnectionID, versions []Version) []byte {
+	return wire.ComposeVersionNegotiation(destConnID, srcConnID, versions)
+}
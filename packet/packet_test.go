@@ -0,0 +1,73 @@
+package packet
+
+import (
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongHeaderRoundTrip(t *testing.T) {
+	hdr := &ExtendedHeader{
+		Header: Header{
+			Type:             PacketTypeHandshake,
+			Version:          Version1,
+			DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+			SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 6, 7, 8, 9}),
+			Length:           10,
+		},
+		PacketNumber:    0x1337,
+		PacketNumberLen: PacketNumberLen2,
+	}
+	b, err := hdr.Append(nil, Version1)
+	require.NoError(t, err)
+	b = append(b, make([]byte, 8)...)     // payload
+	b = append(b, []byte("coalesced")...) // the next packet
+
+	require.True(t, IsLongHeaderPacket(b[0]))
+	v, err := ParseVersion(b)
+	require.NoError(t, err)
+	require.Equal(t, Version1, v)
+	connID, err := ParseConnectionID(b, 0)
+	require.NoError(t, err)
+	require.Equal(t, hdr.DestConnectionID, connID)
+
+	parsed, packet, rest, err := ParseLongHeader(b)
+	require.NoError(t, err)
+	require.Equal(t, PacketTypeHandshake, parsed.Type)
+	require.Equal(t, hdr.SrcConnectionID, parsed.SrcConnectionID)
+	require.Equal(t, []byte("coalesced"), rest)
+	extHdr, err := parsed.ParseExtended(packet)
+	require.NoError(t, err)
+	require.Equal(t, PacketNumber(0x1337), extHdr.PacketNumber)
+}
+
+func TestShortHeaderRoundTrip(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
+	b, err := AppendShortHeader(nil, connID, 1337, PacketNumberLen2, KeyPhaseOne)
+	require.NoError(t, err)
+	require.False(t, IsLongHeaderPacket(b[0]))
+	parsedConnID, err := ParseConnectionID(b, 4)
+	require.NoError(t, err)
+	require.Equal(t, connID, parsedConnID)
+	l, pn, pnLen, kp, err := ParseShortHeader(b, 4)
+	require.NoError(t, err)
+	require.Equal(t, len(b), l)
+	require.Equal(t, PacketNumber(1337), pn)
+	require.Equal(t, PacketNumberLen2, pnLen)
+	require.Equal(t, KeyPhaseOne, kp)
+}
+
+func TestVersionNegotiationRoundTrip(t *testing.T) {
+	dest := ArbitraryLenConnectionID{1, 2, 3}
+	src := ArbitraryLenConnectionID{4, 5, 6, 7}
+	b := ComposeVersionNegotiation(dest, src, []Version{Version1, Version2})
+	require.True(t, IsVersionNegotiationPacket(b))
+	parsedDest, parsedSrc, versions, err := ParseVersionNegotiationPacket(b)
+	require.NoError(t, err)
+	require.Equal(t, dest, parsedDest)
+	require.Equal(t, src, parsedSrc)
+	require.Contains(t, versions, Version1)
+	require.Contains(t, versions, Version2)
+}
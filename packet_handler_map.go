@@ -7,6 +7,7 @@ import (
 	"hash"
 	"io"
 	"net"
+	"net/netip"
 	"sync"
 	"time"
 
@@ -20,6 +21,10 @@ type connCapabilities struct {
 	DF bool
 	// GSO (Generic Segmentation Offload) supported
 	GSO bool
+	// GRO (Generic Receive Offload) enabled
+	GRO bool
+	// TXTime says if the kernel paces packets (using SO_TXTIME).
+	TXTime bool
 	// ECN (Explicit Congestion Notifications) supported
 	ECN bool
 }
@@ -28,9 +33,13 @@ type connCapabilities struct {
 type rawConn interface {
 	ReadPacket() (receivedPacket, error)
 	// WritePacket writes a packet on the wire.
+	// localAddr is the source address, and packetInfoOOB contains the same information encoded as control messages
+	// (see packetInfo.OOB). Implementations use whichever of the two they support.
 	// gsoSize is the size of a single packet, or 0 to disable GSO.
 	// It is invalid to set gsoSize if capabilities.GSO is not set.
-	WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN) (int, error)
+	// txTime is the time when the kernel should send the packet, or the zero value to send it immediately.
+	// It is invalid to set txTime if capabilities.TXTime is not set.
+	WritePacket(b []byte, addr net.Addr, localAddr netip.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN, txTime time.Time) (int, error)
 	LocalAddr() net.Addr
 	SetReadDeadline(time.Time) error
 	io.Closer
@@ -52,6 +61,10 @@ type packetHandlerMap struct {
 	closed    bool
 	closeChan chan struct{}
 
+	// closed once no connection IDs are tracked anymore, see Empty
+	// It is created lazily, and shared by all callers of Empty.
+	emptyChan chan struct{}
+
 	enqueueClosePacket func(closePacket)
 
 	deleteRetiredConnsAfter time.Duration
@@ -146,6 +159,7 @@ func (h *packetHandlerMap) AddWithConnID(clientDestConnID, newConnID protocol.Co
 func (h *packetHandlerMap) Remove(id protocol.ConnectionID) {
 	h.mutex.Lock()
 	delete(h.handlers, id)
+	h.maybeSignalEmpty()
 	h.mutex.Unlock()
 	h.logger.Debugf("Removing connection ID %s.", id)
 }
@@ -155,6 +169,7 @@ func (h *packetHandlerMap) Retire(id protocol.ConnectionID) {
 	time.AfterFunc(h.deleteRetiredConnsAfter, func() {
 		h.mutex.Lock()
 		delete(h.handlers, id)
+		h.maybeSignalEmpty()
 		h.mutex.Unlock()
 		h.logger.Debugf("Removing connection ID %s after it has been retired.", id)
 	})
@@ -189,11 +204,36 @@ func (h *packetHandlerMap) ReplaceWithClosed(ids []protocol.ConnectionID, connCl
 		for _, id := range ids {
 			delete(h.handlers, id)
 		}
+		h.maybeSignalEmpty()
 		h.mutex.Unlock()
 		h.logger.Debugf("Removing connection IDs %s for a closed connection after it has been retired.", ids)
 	})
 }
 
+// Empty returns a channel that is closed once no connection IDs are tracked anymore.
+// This includes the connection IDs of closed connections, which are kept around for a while
+// to handle delayed packets.
+func (h *packetHandlerMap) Empty() <-chan struct{} {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.emptyChan == nil {
+		h.emptyChan = make(chan struct{})
+	}
+	c := h.emptyChan
+	h.maybeSignalEmpty()
+	return c
+}
+
+// must be called with the mutex held
+func (h *packetHandlerMap) maybeSignalEmpty() {
+	if len(h.handlers) > 0 || h.emptyChan == nil {
+		return
+	}
+	close(h.emptyChan)
+	h.emptyChan = nil
+}
+
 func (h *packetHandlerMap) AddResetToken(token protocol.StatelessResetToken, handler packetHandler) {
 	h.mutex.Lock()
 	h.resetTokens[token] = handler
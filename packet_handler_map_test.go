@@ -57,6 +57,37 @@ var _ = Describe("Packet Handler Map", func() {
 		Eventually(func() bool { _, ok := m.Get(connID); return ok }).Should(BeFalse())
 	})
 
+	It("signals when it becomes empty", func() {
+		m := newPacketHandlerMap(nil, nil, utils.DefaultLogger)
+		dur := scaleDuration(50 * time.Millisecond)
+		m.deleteRetiredConnsAfter = dur
+		Expect(m.Empty()).To(BeClosed())
+
+		connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		connID2 := protocol.ParseConnectionID([]byte{4, 3, 2, 1})
+		handler := NewMockPacketHandler(mockCtrl)
+		Expect(m.Add(connID1, handler)).To(BeTrue())
+		Expect(m.Add(connID2, handler)).To(BeTrue())
+		empty := m.Empty()
+		m.Remove(connID1)
+		Consistently(empty, dur).ShouldNot(BeClosed())
+		m.Retire(connID2)
+		Consistently(empty, dur/2).ShouldNot(BeClosed())
+		Eventually(empty).Should(BeClosed())
+	})
+
+	It("returns the same channel to all callers", func() {
+		m := newPacketHandlerMap(nil, nil, utils.DefaultLogger)
+		Expect(m.Add(protocol.ParseConnectionID([]byte{1, 2, 3, 4}), NewMockPacketHandler(mockCtrl))).To(BeTrue())
+		empty := m.Empty()
+		for i := 0; i < 10; i++ {
+			Expect(m.Empty()).To(Equal(empty))
+		}
+		m.Remove(protocol.ParseConnectionID([]byte{1, 2, 3, 4}))
+		Expect(empty).To(BeClosed())
+		Expect(m.Empty()).To(BeClosed())
+	})
+
 	It("adds newly to-be-constructed handlers", func() {
 		m := newPacketHandlerMap(nil, nil, utils.DefaultLogger)
 		connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
@@ -0,0 +1,235 @@
+package quic
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// ECN is the value of the ECN field in the IP header, see RFC 3168.
+type ECN = protocol.ECN
+
+const (
+	// ECNUnsupported means that the ECN field is not read or written.
+	ECNUnsupported = protocol.ECNUnsupported
+	// ECNNon is the Not-ECT codepoint (00).
+	ECNNon = protocol.ECNNon
+	// ECT1 is the ECT(1) codepoint (01).
+	ECT1 = protocol.ECT1
+	// ECT0 is the ECT(0) codepoint (10).
+	ECT0 = protocol.ECT0
+	// ECNCE is the Congestion Experienced codepoint (11).
+	ECNCE = protocol.ECNCE
+)
+
+// packetIOBatchSize is the number of packets read from a PacketIO at once.
+const packetIOBatchSize = 8
+
+// A Packet is a UDP datagram sent or received using a PacketIO.
+type Packet struct {
+	// Data is the UDP payload.
+	// When sending with a SegmentSize, it contains multiple QUIC packets.
+	Data []byte
+	// RemoteAddr is the address of the peer.
+	// It is the source address of received packets, and the destination address of sent packets.
+	RemoteAddr net.Addr
+	// LocalAddr is the local IP address.
+	// For received packets, it is the destination address, if known.
+	// For sent packets, it is the source address. If it is not set, the PacketIO chooses the source address.
+	LocalAddr netip.Addr
+	// ECN is the value of the ECN field in the IP header.
+	// It is ECNUnsupported, unless PacketIOCapabilities.ECN is set.
+	ECN ECN
+	// SegmentSize is only used when sending packets, and only if PacketIOCapabilities.GSO is set.
+	// If non-zero, Data is split into multiple UDP datagrams of SegmentSize bytes each
+	// (the last datagram may be shorter), which are sent to the same peer.
+	SegmentSize uint16
+}
+
+// PacketIOCapabilities are the features supported by a PacketIO.
+type PacketIOCapabilities struct {
+	// DF says if the Don't Fragment bit is set on sent packets.
+	// This is required to run DPLPMTUD (Path MTU Discovery, RFC 8899).
+	DF bool
+	// GSO says if packets with a SegmentSize can be sent.
+	GSO bool
+	// ECN says if the ECN field is read from received packets, and set on sent packets.
+	ECN bool
+}
+
+// A PacketIO sends and receives batches of UDP datagrams.
+// It allows running a Transport on top of networking stacks other than the kernel's UDP sockets,
+// for example AF_XDP, a DPDK-style ring buffer, or an in-memory network.
+// NewPacketIO returns a PacketIO that uses the kernel's UDP stack.
+type PacketIO interface {
+	// ReadBatch reads up to len(packets) datagrams, blocking until at least one datagram was received.
+	// The Data field of every packet is a buffer provided by the caller.
+	// ReadBatch copies the payload into that buffer, reslices Data to the payload length,
+	// and sets the other fields of the packet. It returns the number of packets read.
+	// ReadBatch is never called concurrently.
+	ReadBatch(packets []Packet) (int, error)
+	// WriteBatch sends the packets, and returns the number of packets that were sent.
+	// It must not retain the Data slices after returning.
+	// WriteBatch may be called concurrently.
+	WriteBatch(packets []Packet) (int, error)
+	// LocalAddr returns the local address.
+	LocalAddr() net.Addr
+	// SetReadDeadline sets the deadline for ReadBatch calls, including the currently blocked call.
+	// This is used to unblock reading when closing the Transport.
+	SetReadDeadline(time.Time) error
+	Close() error
+	Capabilities() PacketIOCapabilities
+}
+
+// NewPacketIO returns a PacketIO that sends and receives packets using conn.
+// It uses the same optimizations as a Transport that uses conn directly (see Transport.Conn),
+// except that received packets are copied into the buffers passed to ReadBatch,
+// and that packet pacing is not offloaded to the kernel (see Transport.EnableKernelPacing).
+// This is useful for wrapping the kernel's UDP stack, for example to simulate packet loss.
+func NewPacketIO(conn net.PacketConn) (PacketIO, error) {
+	return wrapConn(conn)
+}
+
+// readBatch implements PacketIO.ReadBatch for a rawConn.
+// It blocks until it has read the first packet.
+// It then fills the batch with the packets that were already read from the socket
+// (e.g. using recvmmsg, or by splitting a datagram coalesced by GRO), without blocking again.
+func readBatch(c rawConn, packets []Packet) (int, error) {
+	var n int
+	for n < len(packets) {
+		if n > 0 {
+			if c, ok := c.(interface{ hasBufferedPackets() bool }); !ok || !c.hasBufferedPackets() {
+				break
+			}
+		}
+		p, err := c.ReadPacket()
+		if err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		// If the packet doesn't fit into the buffer, we only copy a truncated packet,
+		// which will then end up undecryptable.
+		l := copy(packets[n].Data, p.data)
+		packets[n] = Packet{
+			Data:       packets[n].Data[:l],
+			RemoteAddr: p.remoteAddr,
+			LocalAddr:  p.info.addr,
+			ECN:        p.ecn,
+		}
+		p.buffer.Release()
+		n++
+	}
+	return n, nil
+}
+
+// writeBatch implements PacketIO.WriteBatch for a rawConn.
+func writeBatch(c rawConn, packets []Packet) (int, error) {
+	capabilities := c.capabilities()
+	for i, p := range packets {
+		if p.SegmentSize > 0 && !capabilities.GSO {
+			return i, errors.New("quic: GSO not supported")
+		}
+		if p.ECN != ECNUnsupported && !capabilities.ECN {
+			return i, errors.New("quic: ECN not supported")
+		}
+		info := packetInfo{addr: p.LocalAddr}
+		if _, err := c.WritePacket(p.Data, p.RemoteAddr, p.LocalAddr, info.OOB(), p.SegmentSize, p.ECN, time.Time{}); err != nil {
+			return i, err
+		}
+	}
+	return len(packets), nil
+}
+
+func packetIOCapabilities(c rawConn) PacketIOCapabilities {
+	capabilities := c.capabilities()
+	return PacketIOCapabilities{
+		DF:  capabilities.DF,
+		GSO: capabilities.GSO,
+		ECN: capabilities.ECN,
+	}
+}
+
+// packetIOConn is a rawConn that sends and receives packets using a PacketIO.
+type packetIOConn struct {
+	io  PacketIO
+	cap connCapabilities
+
+	// buffers that were passed to the last ReadBatch call, and haven't been returned by ReadPacket yet
+	buffers          [packetIOBatchSize]*packetBuffer
+	packets          [packetIOBatchSize]Packet
+	readPos, numRead int
+}
+
+var _ rawConn = &packetIOConn{}
+
+func newPacketIOConn(io PacketIO) *packetIOConn {
+	capabilities := io.Capabilities()
+	return &packetIOConn{
+		io: io,
+		cap: connCapabilities{
+			DF:  capabilities.DF,
+			GSO: capabilities.GSO,
+			ECN: capabilities.ECN,
+		},
+	}
+}
+
+func (c *packetIOConn) ReadPacket() (receivedPacket, error) {
+	for c.readPos == c.numRead {
+		for i := range c.packets {
+			if c.buffers[i] == nil {
+				c.buffers[i] = getPacketBuffer()
+			}
+			c.packets[i] = Packet{Data: c.buffers[i].Data[:protocol.MaxPacketBufferSize]}
+		}
+		c.readPos = 0
+		n, err := c.io.ReadBatch(c.packets[:])
+		if err != nil {
+			c.numRead = 0
+			return receivedPacket{}, err
+		}
+		c.numRead = n
+	}
+	p := c.packets[c.readPos]
+	buffer := c.buffers[c.readPos]
+	c.packets[c.readPos] = Packet{}
+	c.buffers[c.readPos] = nil
+	c.readPos++
+	return receivedPacket{
+		remoteAddr: p.RemoteAddr,
+		rcvTime:    time.Now(),
+		data:       p.Data,
+		buffer:     buffer,
+		ecn:        p.ECN,
+		info:       packetInfo{addr: p.LocalAddr},
+	}, nil
+}
+
+// WritePacket writes a packet.
+// The PacketIO chooses how to set the source address, so the control messages are ignored.
+func (c *packetIOConn) WritePacket(b []byte, addr net.Addr, localAddr netip.Addr, _ []byte, gsoSize uint16, ecn protocol.ECN, txTime time.Time) (int, error) {
+	if !txTime.IsZero() {
+		panic("cannot set the transmit time on a PacketIO")
+	}
+	packets := [1]Packet{{
+		Data:        b,
+		RemoteAddr:  addr,
+		LocalAddr:   localAddr,
+		ECN:         ecn,
+		SegmentSize: gsoSize,
+	}}
+	if _, err := c.io.WriteBatch(packets[:]); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *packetIOConn) LocalAddr() net.Addr               { return c.io.LocalAddr() }
+func (c *packetIOConn) SetReadDeadline(t time.Time) error { return c.io.SetReadDeadline(t) }
+func (c *packetIOConn) Close() error                      { return c.io.Close() }
+func (c *packetIOConn) capabilities() connCapabilities    { return c.cap }
@@ -0,0 +1,248 @@
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/testdata"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// memPacketIO is an in-memory PacketIO.
+// Packets written to it are delivered to its peer.
+type memPacketIO struct {
+	addr    net.Addr
+	peer    *memPacketIO
+	packets chan Packet
+
+	closeOnce sync.Once
+	unblock   chan struct{}
+
+	mutex   sync.Mutex
+	written []Packet
+}
+
+var _ PacketIO = &memPacketIO{}
+
+func newMemPacketIOPair() (*memPacketIO, *memPacketIO) {
+	a := &memPacketIO{
+		addr:    &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234},
+		packets: make(chan Packet, 100),
+		unblock: make(chan struct{}),
+	}
+	b := &memPacketIO{
+		addr:    &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 4321},
+		packets: make(chan Packet, 100),
+		unblock: make(chan struct{}),
+	}
+	a.peer = b
+	b.peer = a
+	return a, b
+}
+
+func (c *memPacketIO) ReadBatch(packets []Packet) (int, error) {
+	var n int
+	for n < len(packets) {
+		var p Packet
+		if n == 0 {
+			select {
+			case p = <-c.packets:
+			case <-c.unblock:
+				return 0, os.ErrDeadlineExceeded
+			}
+		} else {
+			select {
+			case p = <-c.packets:
+			default:
+				return n, nil
+			}
+		}
+		p.Data = packets[n].Data[:copy(packets[n].Data, p.Data)]
+		packets[n] = p
+		n++
+	}
+	return n, nil
+}
+
+func (c *memPacketIO) WriteBatch(packets []Packet) (int, error) {
+	for _, p := range packets {
+		p.Data = append([]byte(nil), p.Data...)
+		c.mutex.Lock()
+		c.written = append(c.written, p)
+		c.mutex.Unlock()
+		data := p.Data
+		for len(data) > 0 {
+			l := len(data)
+			if p.SegmentSize > 0 {
+				l = min(l, int(p.SegmentSize))
+			}
+			select {
+			case c.peer.packets <- Packet{Data: data[:l], RemoteAddr: c.addr, ECN: p.ECN}:
+			default:
+			}
+			data = data[l:]
+		}
+	}
+	return len(packets), nil
+}
+
+func (c *memPacketIO) SetReadDeadline(t time.Time) error {
+	if !t.IsZero() && !t.After(time.Now()) {
+		c.closeOnce.Do(func() { close(c.unblock) })
+	}
+	return nil
+}
+
+func (c *memPacketIO) LocalAddr() net.Addr { return c.addr }
+func (c *memPacketIO) Close() error        { return c.SetReadDeadline(time.Now()) }
+
+func (c *memPacketIO) Capabilities() PacketIOCapabilities {
+	return PacketIOCapabilities{GSO: true, ECN: true}
+}
+
+func (c *memPacketIO) Written() []Packet {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.written
+}
+
+var _ = Describe("PacketIO", func() {
+	It("reads batches of packets", func() {
+		a, b := newMemPacketIOPair()
+		for _, data := range []string{"foo", "bar", "baz"} {
+			_, err := a.WriteBatch([]Packet{{Data: []byte(data)}})
+			Expect(err).ToNot(HaveOccurred())
+		}
+		conn := newPacketIOConn(b)
+		for _, data := range []string{"foo", "bar", "baz"} {
+			p, err := conn.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(p.data)).To(Equal(data))
+			Expect(p.remoteAddr).To(Equal(a.LocalAddr()))
+			Expect(p.rcvTime).To(BeTemporally("~", time.Now(), scaleDuration(20*time.Millisecond)))
+			p.buffer.Release()
+		}
+		Expect(b.packets).To(BeEmpty())
+		Expect(conn.numRead).To(Equal(3))
+	})
+
+	It("writes packets", func() {
+		a, _ := newMemPacketIOPair()
+		conn := newPacketIOConn(a)
+		Expect(conn.capabilities()).To(Equal(connCapabilities{GSO: true, ECN: true}))
+		remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 42}
+		sconn := newSendConn(conn, remoteAddr, packetInfo{addr: netip.MustParseAddr("10.0.0.42")}, utils.DefaultLogger)
+		Expect(sconn.LocalAddr()).To(Equal(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 42).To4(), Port: 1234}))
+		Expect(sconn.Write([]byte("foobar"), 3, protocol.ECT1, time.Time{})).To(Succeed())
+		Expect(a.Written()).To(Equal([]Packet{{
+			Data:        []byte("foobar"),
+			RemoteAddr:  remoteAddr,
+			LocalAddr:   netip.MustParseAddr("10.0.0.42"),
+			ECN:         ECT1,
+			SegmentSize: 3,
+		}}))
+	})
+
+	It("uses the kernel's UDP stack", func() {
+		server, err := net.ListenUDP("udp4", &net.UDPAddr{})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+		serverAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: server.LocalAddr().(*net.UDPAddr).Port}
+		serverIO, err := NewPacketIO(server)
+		Expect(err).ToNot(HaveOccurred())
+		client, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+		clientIO, err := NewPacketIO(client)
+		Expect(err).ToNot(HaveOccurred())
+
+		n, err := clientIO.WriteBatch([]Packet{
+			{Data: []byte("foo"), RemoteAddr: serverAddr},
+			{Data: []byte("bar"), RemoteAddr: serverAddr},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(2))
+		var received []Packet
+		for len(received) < 2 {
+			packets := make([]Packet, 3)
+			for i := range packets {
+				packets[i].Data = make([]byte, protocol.MaxPacketBufferSize)
+			}
+			n, err := serverIO.ReadBatch(packets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(BeNumerically(">", 0))
+			if runtime.GOOS == "linux" {
+				// both packets are read using a single recvmmsg call
+				Expect(n).To(Equal(2))
+			}
+			received = append(received, packets[:n]...)
+		}
+		Expect(received).To(HaveLen(2))
+		for i, data := range []string{"foo", "bar"} {
+			Expect(string(received[i].Data)).To(Equal(data))
+			Expect(received[i].RemoteAddr).To(Equal(client.LocalAddr()))
+			if runtime.GOOS == "linux" {
+				Expect(received[i].LocalAddr).To(Equal(netip.MustParseAddr("127.0.0.1")))
+			}
+		}
+	})
+
+	It("refuses to use both a Conn and a PacketIO", func() {
+		a, _ := newMemPacketIOPair()
+		udpConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer udpConn.Close()
+		tr := &Transport{Conn: udpConn, PacketIO: a}
+		_, err = tr.Listen(&tls.Config{}, nil)
+		Expect(err).To(MatchError("quic: both Conn and PacketIO set"))
+	})
+
+	It("establishes a connection over an in-memory network", func() {
+		serverIO, clientIO := newMemPacketIOPair()
+		serverTr := &Transport{PacketIO: serverIO}
+		defer serverTr.Close()
+		ln, err := serverTr.Listen(testdata.GetTLSConfig(), &Config{})
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			str.Close()
+		}()
+
+		clientTr := &Transport{PacketIO: clientIO}
+		defer clientTr.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := clientTr.Dial(ctx, serverIO.LocalAddr(), &tls.Config{RootCAs: testdata.GetRootCA(), ServerName: "localhost"}, &Config{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conn.LocalAddr()).To(Equal(clientIO.LocalAddr()))
+		Expect(conn.RemoteAddr()).To(Equal(serverIO.LocalAddr()))
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal([]byte("foobar")))
+		conn.CloseWithError(0, "")
+	})
+})
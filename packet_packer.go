@@ -26,8 +26,10 @@ type packer interface {
 	PackConnectionClose(*qerr.TransportError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackApplicationClose(*qerr.ApplicationError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
 
 	SetToken([]byte)
+	EnableQUICBitGreasing()
 }
 
 type sealer interface {
@@ -105,6 +107,37 @@ type sealingManager interface {
 	Get1RTTSealer() (handshake.ShortHeaderSealer, error)
 }
 
+// The pathSealingManager is used by packers of paths other than path 0 of a multipath connection.
+// Packets sent on these paths are sealed using a nonce derived from the path ID and the packet number.
+type pathSealingManager struct {
+	sealingManager
+	pathID protocol.PathID
+
+	sealer     handshake.ShortHeaderSealer
+	pathSealer *pathSealer
+}
+
+func (m *pathSealingManager) Get1RTTSealer() (handshake.ShortHeaderSealer, error) {
+	s, err := m.sealingManager.Get1RTTSealer()
+	if err != nil {
+		return nil, err
+	}
+	if s != m.sealer {
+		m.sealer = s
+		m.pathSealer = &pathSealer{ShortHeaderSealer: s, pathID: m.pathID}
+	}
+	return m.pathSealer, nil
+}
+
+type pathSealer struct {
+	handshake.ShortHeaderSealer
+	pathID protocol.PathID
+}
+
+func (s *pathSealer) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byte) []byte {
+	return s.SealPath(dst, src, s.pathID, pn, ad)
+}
+
 type frameSource interface {
 	HasData() bool
 	AppendStreamFrames([]ackhandler.StreamFrame, protocol.ByteCount, protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)
@@ -135,6 +168,13 @@ type packetPacker struct {
 	rand                rand.Rand
 
 	numNonAckElicitingAcks int
+
+	// If set, the QUIC bit is randomized on all packets except for Initial packets (RFC 9287).
+	greaseQUICBit bool
+
+	// The path ID, for packers used on a path other than path 0 of a multipath connection.
+	// ACKs are then sent in PATH_ACK frames.
+	pathID protocol.PathID
 }
 
 var _ packer = &packetPacker{}
@@ -170,6 +210,37 @@ func newPacketPacker(
 	}
 }
 
+// newPathPacketPacker creates a packer for a path of a multipath connection.
+// It only packs 1-RTT packets, using the packet number space of that path.
+// Stream data, control frames and DATAGRAM frames are shared with all other paths.
+func newPathPacketPacker(
+	pathID protocol.PathID,
+	getDestConnID func() protocol.ConnectionID,
+	packetNumberManager packetNumberManager,
+	retransmissionQueue *retransmissionQueue,
+	cryptoSetup sealingManager,
+	framer frameSource,
+	acks ackFrameSource,
+	datagramQueue *datagramQueue,
+	perspective protocol.Perspective,
+) *packetPacker {
+	p := newPacketPacker(
+		protocol.ConnectionID{},
+		getDestConnID,
+		nil,
+		nil,
+		packetNumberManager,
+		retransmissionQueue,
+		&pathSealingManager{sealingManager: cryptoSetup, pathID: pathID},
+		framer,
+		acks,
+		datagramQueue,
+		perspective,
+	)
+	p.pathID = pathID
+	return p
+}
+
 // PackConnectionClose packs a packet that closes the connection with a transport error.
 func (p *packetPacker) PackConnectionClose(e *qerr.TransportError, maxPacketSize protocol.ByteCount, v protocol.Version) (*coalescedPacket, error) {
 	var reason string
@@ -517,7 +588,7 @@ func (p *packetPacker) maybeGetCryptoPacket(maxPacketSize protocol.ByteCount, en
 	var pl payload
 	if ack != nil {
 		pl.ack = ack
-		pl.length = ack.Length(v)
+		pl.length = p.ackLength(ack, v)
 		maxPacketSize -= pl.length
 	}
 	hdr := p.getLongHeader(encLevel, v)
@@ -612,7 +683,7 @@ func (p *packetPacker) composeNextPacket(
 ) payload {
 	if onlyAck {
 		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, true); ack != nil {
-			return payload{ack: ack, length: ack.Length(v)}
+			return payload{ack: ack, length: p.ackLength(ack, v)}
 		}
 		return payload{}
 	}
@@ -625,7 +696,7 @@ func (p *packetPacker) composeNextPacket(
 	if ackAllowed {
 		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, !hasRetransmission && !hasData); ack != nil {
 			pl.ack = ack
-			pl.length += ack.Length(v)
+			pl.length += p.ackLength(ack, v)
 			hasAck = true
 		}
 	}
@@ -780,6 +851,33 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	return packet, buffer, err
 }
 
+// PackPathProbePacket packs a packet containing PATH_CHALLENGE and / or PATH_RESPONSE frames.
+// The packet is padded to size bytes. Unless limited by the anti-amplification limit,
+// this is 1200 bytes, as required by section 8.2.1 of RFC 9000.
+func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	pl := payload{frames: frames}
+	for _, f := range frames {
+		pl.length += f.Frame.Length(v)
+	}
+	s, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return shortHeaderPacket{}, nil, err
+	}
+	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
+	padding := size - p.shortHeaderPacketLength(connID, pnLen, pl) - protocol.ByteCount(s.Overhead())
+	if padding < 0 {
+		return shortHeaderPacket{}, nil, errors.New("path probe frames don't fit into the packet")
+	}
+	buffer := getPacketBuffer()
+	kp := s.KeyPhase()
+	packet, err := p.appendShortHeaderPacket(buffer, connID, pn, pnLen, kp, pl, padding, size, s, false, v)
+	if err != nil {
+		buffer.Release()
+		return shortHeaderPacket{}, nil, err
+	}
+	return packet, buffer, nil
+}
+
 func (p *packetPacker) getLongHeader(encLevel protocol.EncryptionLevel, v protocol.Version) *wire.ExtendedHeader {
 	pn, pnLen := p.pnManager.PeekPacketNumber(encLevel)
 	hdr := &wire.ExtendedHeader{
@@ -811,6 +909,9 @@ func (p *packetPacker) appendLongHeaderPacket(buffer *packetBuffer, header *wire
 	}
 	paddingLen += padding
 	header.Length = pnLen + protocol.ByteCount(sealer.Overhead()) + pl.length + paddingLen
+	if p.greaseQUICBit && header.Type != protocol.PacketTypeInitial {
+		header.ClearQUICBit = p.rand.Intn(2) == 0
+	}
 
 	startLen := len(buffer.Data)
 	raw := buffer.Data[startLen:]
@@ -859,7 +960,7 @@ func (p *packetPacker) appendShortHeaderPacket(
 
 	startLen := len(buffer.Data)
 	raw := buffer.Data[startLen:]
-	raw, err := wire.AppendShortHeader(raw, connID, pn, pnLen, kp)
+	raw, err := wire.AppendShortHeader(raw, connID, pn, pnLen, kp, p.greaseQUICBit && p.rand.Intn(2) == 0)
 	if err != nil {
 		return shortHeaderPacket{}, err
 	}
@@ -899,7 +1000,11 @@ func (p *packetPacker) appendPacketPayload(raw []byte, pl payload, paddingLen pr
 	payloadOffset := len(raw)
 	if pl.ack != nil {
 		var err error
-		raw, err = pl.ack.Append(raw, v)
+		if p.pathID != 0 {
+			raw, err = (&wire.PathAckFrame{PathID: p.pathID, AckFrame: *pl.ack}).Append(raw, v)
+		} else {
+			raw, err = pl.ack.Append(raw, v)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -933,6 +1038,15 @@ func (p *packetPacker) appendPacketPayload(raw []byte, pl payload, paddingLen pr
 	return raw, nil
 }
 
+// ackLength returns the length of the frame used to send the ACK.
+// On paths other than path 0, a PATH_ACK frame is used.
+func (p *packetPacker) ackLength(ack *wire.AckFrame, v protocol.Version) protocol.ByteCount {
+	if p.pathID != 0 {
+		return (&wire.PathAckFrame{PathID: p.pathID, AckFrame: *ack}).Length(v)
+	}
+	return ack.Length(v)
+}
+
 func (p *packetPacker) encryptPacket(raw []byte, sealer sealer, pn protocol.PacketNumber, payloadOffset, pnLen protocol.ByteCount) []byte {
 	_ = sealer.Seal(raw[payloadOffset:payloadOffset], raw[payloadOffset:], pn, raw[:payloadOffset])
 	raw = raw[:len(raw)+sealer.Overhead()]
@@ -945,3 +1059,8 @@ func (p *packetPacker) encryptPacket(raw []byte, sealer sealer, pn protocol.Pack
 func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
+
+// EnableQUICBitGreasing is called once the peer advertised support for greasing the QUIC bit.
+func (p *packetPacker) EnableQUICBitGreasing() {
+	p.greaseQUICBit = true
+}
@@ -43,7 +43,7 @@ var _ = Describe("Packet packer", func() {
 			if !wire.IsLongHeaderPacket(data[0]) {
 				break
 			}
-			hdr, _, more, err := wire.ParsePacket(data)
+			hdr, _, more, err := wire.ParsePacket(data, false)
 			Expect(err).ToNot(HaveOccurred())
 			extHdr, err := hdr.ParseExtended(data)
 			Expect(err).ToNot(HaveOccurred())
@@ -56,7 +56,7 @@ var _ = Describe("Packet packer", func() {
 	}
 
 	parseShortHeaderPacket := func(data []byte) {
-		l, _, pnLen, _, err := wire.ParseShortHeader(data, connID.Len())
+		l, _, pnLen, _, err := wire.ParseShortHeader(data, connID.Len(), false)
 		ExpectWithOffset(1, err).ToNot(HaveOccurred())
 		ExpectWithOffset(1, len(data)-l+int(pnLen)).To(BeNumerically(">=", 4))
 	}
@@ -260,6 +260,31 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.Frames).To(BeEmpty())
 				parsePacket(buffer.Data)
 			})
+
+			It("packs PATH_ACK frames on paths other than path 0, sealed for that path", func() {
+				packer = newPathPacketPacker(3, func() protocol.ConnectionID { return connID }, pnManager, retransmissionQueue, sealingManager, framer, ackFramer, datagramQueue, protocol.PerspectiveClient)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealer := mocks.NewMockShortHeaderSealer(mockCtrl)
+				sealer.EXPECT().KeyPhase().Return(protocol.KeyPhaseOne).AnyTimes()
+				sealer.EXPECT().Overhead().Return(7).AnyTimes()
+				sealer.EXPECT().EncryptHeader(gomock.Any(), gomock.Any(), gomock.Any())
+				sealer.EXPECT().SealPath(gomock.Any(), gomock.Any(), protocol.PathID(3), protocol.PacketNumber(0x42), gomock.Any()).DoAndReturn(func(_, src []byte, _ protocol.PathID, _ protocol.PacketNumber, _ []byte) []byte {
+					return append(src, bytes.Repeat([]byte{'s'}, 7)...)
+				})
+				sealingManager.EXPECT().Get1RTTSealer().Return(sealer, nil)
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}}
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(ack)
+				p, buffer, err := packer.PackAckOnlyPacket(maxPacketSize, time.Now(), protocol.Version1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p.Ack).To(Equal(ack))
+				hdrLen := 1 + connID.Len() + int(protocol.PacketNumberLen2)
+				frameParser := wire.NewFrameParser(false, false, false)
+				frameParser.SetSupportsMultipath()
+				_, frame, err := frameParser.ParseNext(buffer.Data[hdrLen:len(buffer.Data)-7], protocol.Encryption1RTT, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame).To(Equal(&wire.PathAckFrame{PathID: 3, AckFrame: *ack}))
+			})
 		})
 
 		Context("packing 0-RTT packets", func() {
@@ -512,6 +537,25 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.Ack).To(Equal(ack))
 			})
 
+			It("randomizes the QUIC bit, if greasing is enabled", func() {
+				packer.EnableQUICBitGreasing()
+				var numCleared int
+				for i := 0; i < 100; i++ {
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+					framer.EXPECT().HasData()
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 42, Smallest: 1}}})
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+					buffer := getPacketBuffer()
+					_, err := packer.AppendPacket(buffer, maxPacketSize, time.Now(), protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					if buffer.Data[0]&0x40 == 0 {
+						numCleared++
+					}
+				}
+				Expect(numCleared).To(And(BeNumerically(">", 20), BeNumerically("<", 80)))
+			})
+
 			It("packs control frames, and sets OnLost / OnAcked handlers", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
@@ -697,7 +741,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(packet.IsOnlyShortHeaderPacket()).To(BeFalse())
 				// cut off the tag that the mock sealer added
 				// packet.buffer.Data = packet.buffer.Data[:packet.buffer.Len()-protocol.ByteCount(sealer.Overhead())]
-				hdr, _, _, err := wire.ParsePacket(packet.buffer.Data)
+				hdr, _, _, err := wire.ParsePacket(packet.buffer.Data, false)
 				Expect(err).ToNot(HaveOccurred())
 				data := packet.buffer.Data
 				extHdr, err := hdr.ParseExtended(data)
@@ -710,7 +754,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(data[1]).To(Equal(byte(0)))
 				data = data[2:]
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false)
+				frameParser := wire.NewFrameParser(false, false, false)
 				l, frame, err := frameParser.ParseNext(data, protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -737,7 +781,7 @@ var _ = Describe("Packet packer", func() {
 				// cut off the tag that the mock sealer added
 				buffer.Data = buffer.Data[:buffer.Len()-protocol.ByteCount(sealer.Overhead())]
 				data := buffer.Data
-				l, _, pnLen, _, err := wire.ParseShortHeader(data, connID.Len())
+				l, _, pnLen, _, err := wire.ParseShortHeader(data, connID.Len(), false)
 				Expect(err).ToNot(HaveOccurred())
 				r := bytes.NewReader(data[l:])
 				Expect(pnLen).To(Equal(protocol.PacketNumberLen1))
@@ -747,7 +791,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(firstPayloadByte).To(Equal(byte(0)))
 				// ... followed by the STREAM frame
-				frameParser := wire.NewFrameParser(true)
+				frameParser := wire.NewFrameParser(true, true, true)
 				l, frame, err := frameParser.ParseNext(buffer.Data[len(data)-r.Len():], protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.StreamFrame{}))
@@ -929,6 +973,23 @@ var _ = Describe("Packet packer", func() {
 				Expect(more).To(BeEmpty())
 			})
 
+			It("doesn't clear the QUIC bit on Initial packets, if greasing is enabled", func() {
+				packer.EnableQUICBitGreasing()
+				for i := 0; i < 20; i++ {
+					pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(0x24), protocol.PacketNumberLen1)
+					pnManager.EXPECT().PopPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(0x24))
+					sealingManager.EXPECT().GetInitialSealer().Return(getSealer(), nil)
+					sealingManager.EXPECT().GetHandshakeSealer().Return(nil, handshake.ErrKeysNotYetAvailable)
+					sealingManager.EXPECT().Get1RTTSealer().Return(nil, handshake.ErrKeysNotYetAvailable)
+					ackFramer.EXPECT().GetAckFrame(protocol.EncryptionInitial, false)
+					initialStream.Write([]byte("initial"))
+					p, err := packer.PackCoalescedPacket(false, maxPacketSize, time.Now(), protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(p.longHdrPackets).To(HaveLen(1))
+					Expect(p.buffer.Data[0] & 0x40).ToNot(BeZero())
+				}
+			})
+
 			It("packs a maximum size Handshake packet", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x42))
@@ -1169,7 +1230,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(packet.shortHdrPacket).To(BeNil())
 				// cut off the tag that the mock sealer added
 				// packet.buffer.Data = packet.buffer.Data[:packet.buffer.Len()-protocol.ByteCount(sealer.Overhead())]
-				hdr, _, _, err := wire.ParsePacket(packet.buffer.Data)
+				hdr, _, _, err := wire.ParsePacket(packet.buffer.Data, false)
 				Expect(err).ToNot(HaveOccurred())
 				data := packet.buffer.Data
 				extHdr, err := hdr.ParseExtended(data)
@@ -1182,7 +1243,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(data[1]).To(Equal(byte(0)))
 				data = data[2:]
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false)
+				frameParser := wire.NewFrameParser(false, false, false)
 				l, frame, err := frameParser.ParseNext(data, protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -1458,6 +1519,46 @@ var _ = Describe("Packet packer", func() {
 				Expect(buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.IsPathMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+				f := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, buffer, err := packer.PackPathProbePacket(connID, []ackhandler.Frame{f}, protocol.MinInitialPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+				Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(p.DestConnID).To(Equal(connID))
+				Expect(p.Frames).To(Equal([]ackhandler.Frame{f}))
+				Expect(buffer.Data).To(HaveLen(protocol.MinInitialPacketSize))
+				Expect(p.IsPathMTUProbePacket).To(BeFalse())
+			})
+
+			It("packs a path probe packet smaller than 1200 bytes", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+				frames := []ackhandler.Frame{
+					{Frame: &wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}},
+					{Frame: &wire.PathChallengeFrame{Data: [8]byte{8, 7, 6, 5, 4, 3, 2, 1}}},
+				}
+				p, buffer, err := packer.PackPathProbePacket(connID, frames, 300, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(300))
+				Expect(p.Frames).To(Equal(frames))
+				Expect(buffer.Data).To(HaveLen(300))
+			})
+
+			It("doesn't pack a path probe packet if the 1-RTT keys are not available", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(nil, handshake.ErrKeysNotYetAvailable)
+				f := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				_, buffer, err := packer.PackPathProbePacket(protocol.ParseConnectionID([]byte{1, 2, 3, 4}), []ackhandler.Frame{f}, protocol.MinInitialPacketSize, protocol.Version1)
+				Expect(err).To(MatchError(handshake.ErrKeysNotYetAvailable))
+				Expect(buffer).To(BeNil())
+			})
 		})
 	})
 })
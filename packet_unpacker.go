@@ -37,14 +37,17 @@ type packetUnpacker struct {
 	cs handshake.CryptoSetup
 
 	shortHdrConnIDLen int
+	// set if we advertised support for greasing the QUIC bit (RFC 9287)
+	acceptZeroQUICBit bool
 }
 
 var _ unpacker = &packetUnpacker{}
 
-func newPacketUnpacker(cs handshake.CryptoSetup, shortHdrConnIDLen int) *packetUnpacker {
+func newPacketUnpacker(cs handshake.CryptoSetup, shortHdrConnIDLen int, acceptZeroQUICBit bool) *packetUnpacker {
 	return &packetUnpacker{
 		cs:                cs,
 		shortHdrConnIDLen: shortHdrConnIDLen,
+		acceptZeroQUICBit: acceptZeroQUICBit,
 	}
 }
 
@@ -60,7 +63,7 @@ func (u *packetUnpacker) UnpackLongHeader(hdr *wire.Header, data []byte) (*unpac
 	switch hdr.Type {
 	case protocol.PacketTypeInitial:
 		encLevel = protocol.EncryptionInitial
-		opener, err := u.cs.GetInitialOpener()
+		opener, err := u.cs.GetInitialOpener(hdr.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -124,6 +127,42 @@ func (u *packetUnpacker) UnpackShortHeader(rcvTime time.Time, data []byte) (prot
 	return pn, pnLen, kp, decrypted, nil
 }
 
+// UnpackPathShortHeader unpacks a short header packet received on a path other than path 0,
+// when using the multipath extension.
+// Every path uses its own packet number space, so the packet number is decoded using the
+// largest packet number received on that path.
+func (u *packetUnpacker) UnpackPathShortHeader(
+	rcvTime time.Time,
+	data []byte,
+	pathID protocol.PathID,
+	largestRcvd protocol.PacketNumber,
+) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
+	opener, err := u.cs.Get1RTTOpener()
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+	l, pn, pnLen, kp, parseErr := u.unpackShortHeader(opener, data)
+	// see unpackShortHeaderPacket for why we continue unpacking if the reserved bits are invalid
+	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
+		return 0, 0, 0, nil, &headerParseError{parseErr}
+	}
+	pn = protocol.DecodePacketNumber(pnLen, largestRcvd, pn)
+	decrypted, err := opener.OpenPath(data[l:l], data[l:], rcvTime, pathID, pn, kp, data[:l])
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+	if parseErr != nil {
+		return 0, 0, 0, nil, parseErr
+	}
+	if len(decrypted) == 0 {
+		return 0, 0, 0, nil, &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "empty packet",
+		}
+	}
+	return pn, pnLen, kp, decrypted, nil
+}
+
 func (u *packetUnpacker) unpackLongHeaderPacket(opener handshake.LongHeaderOpener, hdr *wire.Header, data []byte) (*wire.ExtendedHeader, []byte, error) {
 	extHdr, parseErr := u.unpackLongHeader(opener, hdr, data)
 	// If the reserved bits are set incorrectly, we still need to continue unpacking.
@@ -174,7 +213,7 @@ func (u *packetUnpacker) unpackShortHeader(hd headerDecryptor, data []byte) (int
 		data[hdrLen:hdrLen+4],
 	)
 	// 3. parse the header (and learn the actual length of the packet number)
-	l, pn, pnLen, kp, parseErr := wire.ParseShortHeader(data, u.shortHdrConnIDLen)
+	l, pn, pnLen, kp, parseErr := wire.ParseShortHeader(data, u.shortHdrConnIDLen, u.acceptZeroQUICBit)
 	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
 		return l, pn, pnLen, kp, parseErr
 	}
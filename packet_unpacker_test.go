@@ -31,20 +31,20 @@ var _ = Describe("Packet Unpacker", func() {
 		if extHdr.Length > protocol.ByteCount(extHdr.PacketNumberLen) {
 			b = append(b, make([]byte, int(extHdr.Length)-int(extHdr.PacketNumberLen))...)
 		}
-		hdr, _, _, err := wire.ParsePacket(b)
+		hdr, _, _, err := wire.ParsePacket(b, false)
 		ExpectWithOffset(1, err).ToNot(HaveOccurred())
 		return hdr, b[:hdrLen]
 	}
 
 	getShortHeader := func(connID protocol.ConnectionID, pn protocol.PacketNumber, pnLen protocol.PacketNumberLen, kp protocol.KeyPhaseBit) []byte {
-		b, err := wire.AppendShortHeader(nil, connID, pn, pnLen, kp)
+		b, err := wire.AppendShortHeader(nil, connID, pn, pnLen, kp, false)
 		Expect(err).ToNot(HaveOccurred())
 		return b
 	}
 
 	BeforeEach(func() {
 		cs = mocks.NewMockCryptoSetup(mockCtrl)
-		unpacker = newPacketUnpacker(cs, 4)
+		unpacker = newPacketUnpacker(cs, 4, false)
 	})
 
 	It("errors when the packet is too small to obtain the header decryption sample, for long headers", func() {
@@ -69,7 +69,7 @@ var _ = Describe("Packet Unpacker", func() {
 	})
 
 	It("errors when the packet is too small to obtain the header decryption sample, for short headers", func() {
-		b, err := wire.AppendShortHeader(nil, connID, 1337, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
+		b, err := wire.AppendShortHeader(nil, connID, 1337, protocol.PacketNumberLen2, protocol.KeyPhaseOne, false)
 		Expect(err).ToNot(HaveOccurred())
 		data := append(b, make([]byte, 2 /* fill up packet number */ +15 /* need 16 bytes */)...)
 		opener := mocks.NewMockShortHeaderOpener(mockCtrl)
@@ -93,7 +93,7 @@ var _ = Describe("Packet Unpacker", func() {
 		hdr, hdrRaw := getLongHeader(extHdr)
 		opener := mocks.NewMockLongHeaderOpener(mockCtrl)
 		gomock.InOrder(
-			cs.EXPECT().GetInitialOpener().Return(opener, nil),
+			cs.EXPECT().GetInitialOpener(protocol.Version1).Return(opener, nil),
 			opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any()),
 			opener.EXPECT().DecodePacketNumber(protocol.PacketNumber(2), protocol.PacketNumberLen3).Return(protocol.PacketNumber(1234)),
 			opener.EXPECT().Open(gomock.Any(), payload, protocol.PacketNumber(1234), hdrRaw).Return([]byte("decrypted"), nil),
@@ -147,6 +147,23 @@ var _ = Describe("Packet Unpacker", func() {
 		Expect(data).To(Equal([]byte("decrypted")))
 	})
 
+	It("opens short header packets received on a path, using the path's packet number space", func() {
+		hdrRaw := getShortHeader(connID, 0x38, protocol.PacketNumberLen1, protocol.KeyPhaseZero)
+		opener := mocks.NewMockShortHeaderOpener(mockCtrl)
+		now := time.Now()
+		gomock.InOrder(
+			cs.EXPECT().Get1RTTOpener().Return(opener, nil),
+			opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any()),
+			opener.EXPECT().OpenPath(gomock.Any(), gomock.Any(), now, protocol.PathID(3), protocol.PacketNumber(0x1338), protocol.KeyPhaseZero, hdrRaw).Return([]byte("decrypted"), nil),
+		)
+		pn, pnLen, kp, data, err := unpacker.UnpackPathShortHeader(now, append(hdrRaw, payload...), 3, 0x1337)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pn).To(Equal(protocol.PacketNumber(0x1338)))
+		Expect(pnLen).To(Equal(protocol.PacketNumberLen1))
+		Expect(kp).To(Equal(protocol.KeyPhaseZero))
+		Expect(data).To(Equal([]byte("decrypted")))
+	})
+
 	It("returns the error when getting the opener fails", func() {
 		hdrRaw := getShortHeader(connID, 0x1337, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
 		cs.EXPECT().Get1RTTOpener().Return(nil, handshake.ErrKeysNotYetAvailable)
@@ -0,0 +1,226 @@
+package quic
+
+import (
+	"crypto/rand"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// maxPaths is the maximum number of paths (other than the active path) the server keeps track of.
+// If the client sends from more addresses, the oldest path is dropped.
+const maxPaths = 3
+
+// maxPathChallenges is the maximum number of outstanding PATH_CHALLENGE frames per path.
+const maxPathChallenges = 4
+
+// Before a path is validated, we send at most 3x the number of bytes received on that path,
+// see section 8 of RFC 9000.
+const amplificationFactor = 3
+
+type path struct {
+	// The conn the packets on this path are received on.
+	// It is nil for the conn the connection was created on, and only set for the server's preferred address.
+	rcvConn rawConn
+	addr    net.Addr
+	info    packetInfo
+	allowed bool
+
+	pathChallenges  [][8]byte
+	challengeSentAt time.Time
+	validated       bool
+	// The packet number of the last non-probing packet received on this path.
+	// It is InvalidPacketNumber if no non-probing packet was received yet.
+	largestNonProbing protocol.PacketNumber
+
+	// Used to enforce the anti-amplification limit before the path is validated.
+	bytesReceived protocol.ByteCount
+	bytesSent     protocol.ByteCount
+}
+
+// The pathManager is used by the server to handle packets received on a new path.
+// This happens when the client migrates the connection (either from a new address,
+// or to the server's preferred address), or when a NAT rebinding occurs.
+// New paths are validated using a PATH_CHALLENGE frame, see section 9 of RFC 9000.
+// The connection switches to a new path once it is validated,
+// and the client sent a non-probing packet on that path.
+type pathManager struct {
+	paths []*path // oldest first
+
+	// allowMigration is called for paths that the client deliberately migrated to.
+	// It is not called for NAT rebindings.
+	allowMigration func(net.Addr) bool
+}
+
+func newPathManager(allowMigration func(net.Addr) bool) *pathManager {
+	return &pathManager{allowMigration: allowMigration}
+}
+
+func (pm *pathManager) getPath(rcvConn rawConn, addr net.Addr) *path {
+	for _, p := range pm.paths {
+		if p.rcvConn == rcvConn && addrsEqual(p.addr, addr) {
+			return p
+		}
+	}
+	return nil
+}
+
+// AllowsPath says if packets received from this address should be processed.
+// It is called before the frames of a packet received from a new address are handled.
+// isMigration says if the client deliberately migrated to this path, as opposed to a NAT rebinding.
+// The decision is made for the first packet received on a path.
+func (pm *pathManager) AllowsPath(rcvConn rawConn, addr net.Addr, isMigration bool) bool {
+	if p := pm.getPath(rcvConn, addr); p != nil {
+		return p.allowed
+	}
+	if len(pm.paths) >= maxPaths {
+		pm.paths = pm.paths[1:]
+	}
+	p := &path{
+		rcvConn:           rcvConn,
+		addr:              addr,
+		allowed:           !isMigration || pm.allowMigration == nil || pm.allowMigration(addr),
+		largestNonProbing: protocol.InvalidPacketNumber,
+	}
+	pm.paths = append(pm.paths, p)
+	return p.allowed
+}
+
+// pathProbeOverhead is an upper bound for the size of a packet containing path probing frames,
+// not counting the frames: the short header (using the longest possible connection ID and packet number)
+// and the AEAD tag.
+const pathProbeOverhead = 1 + protocol.MaxConnIDLen + 4 + 16
+
+// pathProbeFrameLen is the length of a PATH_CHALLENGE and a PATH_RESPONSE frame.
+const pathProbeFrameLen = 1 + 8
+
+// HandlePacket handles a packet received on a path that's not the active path.
+// It returns the frames that need to be sent on this path:
+// a PATH_RESPONSE frame, if the packet contained a PATH_CHALLENGE frame,
+// and a PATH_CHALLENGE frame, if the path hasn't been validated yet.
+// The returned size is the size the packet containing these frames is allowed to have.
+// If the anti-amplification limit doesn't allow sending the frames, no frames are returned.
+// Once the packet was packed, SentPathProbe must be called.
+func (pm *pathManager) HandlePacket(
+	rp receivedPacket,
+	pn protocol.PacketNumber,
+	pathChallenge *wire.PathChallengeFrame, // may be nil
+	isNonProbing bool,
+	pto time.Duration,
+) (_ []ackhandler.Frame, maxSize protocol.ByteCount) {
+	p := pm.getPath(rp.rcvConn, rp.remoteAddr)
+	if p == nil || !p.allowed {
+		return nil, 0
+	}
+	now := rp.rcvTime
+	p.info = rp.info
+	p.bytesReceived += rp.Size()
+	if isNonProbing {
+		p.largestNonProbing = max(p.largestNonProbing, pn)
+	}
+
+	// Packets containing PATH_CHALLENGE frames are padded to 1200 bytes, see section 8.2.1 of RFC 9000.
+	// On an unvalidated path, we're limited by the anti-amplification limit,
+	// in which case we send a smaller packet.
+	maxSize = protocol.MinInitialPacketSize
+	if !p.validated {
+		maxSize = min(maxSize, amplificationFactor*p.bytesReceived-p.bytesSent)
+	}
+
+	var frames []ackhandler.Frame
+	if pathChallenge != nil && maxSize >= pathProbeOverhead+pathProbeFrameLen {
+		frames = append(frames, ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: pathChallenge.Data}})
+	}
+	if !p.validated &&
+		(p.challengeSentAt.IsZero() || now.Sub(p.challengeSentAt) >= pto) &&
+		maxSize >= pathProbeOverhead+pathProbeFrameLen*protocol.ByteCount(len(frames)+1) {
+		var b [8]byte
+		_, _ = rand.Read(b[:])
+		frames = append(frames, ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: b}})
+	}
+	if len(frames) == 0 {
+		return nil, 0
+	}
+	return frames, maxSize
+}
+
+// SentPathProbe is called when a packet containing the frames returned by HandlePacket was packed.
+// The size is the size of that packet.
+func (pm *pathManager) SentPathProbe(rcvConn rawConn, addr net.Addr, frames []ackhandler.Frame, size protocol.ByteCount, now time.Time) {
+	p := pm.getPath(rcvConn, addr)
+	if p == nil {
+		return
+	}
+	p.bytesSent += size
+	for _, f := range frames {
+		pc, ok := f.Frame.(*wire.PathChallengeFrame)
+		if !ok {
+			continue
+		}
+		if len(p.pathChallenges) >= maxPathChallenges {
+			p.pathChallenges = p.pathChallenges[1:]
+		}
+		p.pathChallenges = append(p.pathChallenges, pc.Data)
+		p.challengeSentAt = now
+	}
+}
+
+// HandlePathResponseFrame handles a PATH_RESPONSE frame.
+// It returns the path that was validated, if any.
+func (pm *pathManager) HandlePathResponseFrame(f *wire.PathResponseFrame) (_ rawConn, _ net.Addr, validated bool) {
+	for _, p := range pm.paths {
+		if p.validated {
+			continue
+		}
+		for _, c := range p.pathChallenges {
+			if c == f.Data {
+				p.validated = true
+				p.pathChallenges = nil
+				return p.rcvConn, p.addr, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// ShouldSwitchPath returns the path the connection should switch to, if any.
+// This is a path that was validated, and on which the client sent a non-probing packet.
+// That packet needs to be the packet with the largest packet number received so far,
+// such that a reordered packet doesn't cause the connection to switch back to an old path,
+// see section 9.3 of RFC 9000.
+func (pm *pathManager) ShouldSwitchPath(largestRcvd protocol.PacketNumber) (rawConn, net.Addr, packetInfo, bool) {
+	for i := len(pm.paths) - 1; i >= 0; i-- {
+		p := pm.paths[i]
+		if !p.validated || p.largestNonProbing == protocol.InvalidPacketNumber || p.largestNonProbing < largestRcvd {
+			continue
+		}
+		pm.paths = append(pm.paths[:i], pm.paths[i+1:]...)
+		return p.rcvConn, p.addr, p.info, true
+	}
+	return nil, nil, packetInfo{}, false
+}
+
+// isProbingFrame says if a frame is a probing frame, as defined in section 9.1 of RFC 9000.
+func isProbingFrame(f wire.Frame) bool {
+	switch f.(type) {
+	case *wire.PathChallengeFrame, *wire.PathResponseFrame, *wire.NewConnectionIDFrame:
+		return true
+	default:
+		return false
+	}
+}
+
+func addrsEqual(addr1, addr2 net.Addr) bool {
+	if addr1 == nil || addr2 == nil {
+		return false
+	}
+	a1, ok1 := addr1.(*net.UDPAddr)
+	a2, ok2 := addr2.(*net.UDPAddr)
+	if ok1 && ok2 {
+		return a1.IP.Equal(a2.IP) && a1.Port == a2.Port
+	}
+	return addr1.String() == addr2.String()
+}
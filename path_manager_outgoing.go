@@ -0,0 +1,337 @@
+package quic
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+var (
+	// ErrPathClosed is returned when trying to use a path that was closed.
+	ErrPathClosed = errors.New("path closed")
+	// ErrPathNotValidated is returned when trying to switch to a path that hasn't been validated yet.
+	ErrPathNotValidated = errors.New("path not yet validated")
+)
+
+type pathID int64
+
+// A Path is a network path that can be used by a QUIC connection.
+// It is created by calling Connection.AddPath.
+type Path struct {
+	id          pathID
+	pathManager *pathManagerOutgoing
+	tr          *Transport
+	initialRTT  time.Duration
+
+	validated atomic.Bool
+	closeOnce sync.Once
+	abandon   chan struct{}
+}
+
+// Probe validates the path, by sending a PATH_CHALLENGE frame and waiting for the
+// corresponding PATH_RESPONSE frame.
+// PATH_CHALLENGE frames are retransmitted with an exponential backoff,
+// until the path is validated, or the context is canceled.
+func (p *Path) Probe(ctx context.Context) error {
+	path, ok := p.pathManager.getPath(p.id)
+	if !ok {
+		return ErrPathClosed
+	}
+	p.pathManager.enqueueProbe(p.id)
+	nextProbeDur := p.initialRTT
+	timer := time.NewTimer(nextProbeDur)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-p.pathManager.connCtx.Done():
+			return context.Cause(p.pathManager.connCtx)
+		case <-path.validated:
+			p.validated.Store(true)
+			return nil
+		case <-p.abandon:
+			return ErrPathClosed
+		case <-timer.C:
+			nextProbeDur *= 2 // exponential backoff
+			p.pathManager.enqueueProbe(p.id)
+			timer.Reset(nextProbeDur)
+		}
+	}
+}
+
+// Switch switches the QUIC connection to this path.
+// It immediately stops sending on the old path, and sends on this new path.
+// The path must have been validated using Probe before.
+func (p *Path) Switch() error {
+	select {
+	case <-p.abandon:
+		return ErrPathClosed
+	default:
+	}
+	if !p.validated.Load() {
+		return ErrPathNotValidated
+	}
+	return p.pathManager.switchToPath(p.id)
+}
+
+// Close abandons a path.
+// It is not possible to close the path that's currently active.
+// After closing, it is not possible to probe this path again.
+func (p *Path) Close() error {
+	if err := p.pathManager.removePath(p.id); err != nil {
+		return err
+	}
+	p.closeOnce.Do(func() { close(p.abandon) })
+	return nil
+}
+
+type pathOutgoing struct {
+	id pathID
+	tr *Transport
+	// The sendConn is used to send path probe packets.
+	conn sendConn
+
+	pathChallenges [][8]byte // length is implicitly limited by exponential backoff
+	isValidated    bool
+	validated      chan struct{} // closed when the PATH_RESPONSE is received
+
+	// enablePath registers the connection's connection IDs with the Transport.
+	// It is called (on the connection's run loop) before the first probe packet is sent.
+	enablePath func()
+}
+
+// The pathManagerOutgoing manages the paths created by the client using Connection.AddPath.
+// Its exported methods are called from the connection's run loop,
+// while the Path methods might be called from arbitrary go routines.
+type pathManagerOutgoing struct {
+	connCtx         context.Context
+	getConnID       func(pathID) (_ protocol.ConnectionID, ok bool)
+	retirePath      func(pathID)
+	scheduleSending func()
+
+	mx             sync.Mutex
+	activePath     pathID
+	nextPathID     pathID
+	originalPath   *Path
+	paths          map[pathID]*pathOutgoing
+	pathsToProbe   []pathID
+	pathsToRetire  []*pathOutgoing
+	pathToSwitchTo *pathOutgoing
+}
+
+func newPathManagerOutgoing(
+	connCtx context.Context,
+	handshakeConn sendConn,
+	getConnID func(pathID) (_ protocol.ConnectionID, ok bool),
+	retirePath func(pathID),
+	scheduleSending func(),
+) *pathManagerOutgoing {
+	pm := &pathManagerOutgoing{
+		connCtx:         connCtx,
+		activePath:      0, // at initialization time, we're guaranteed to be using the handshake path
+		nextPathID:      1,
+		getConnID:       getConnID,
+		retirePath:      retirePath,
+		scheduleSending: scheduleSending,
+		paths:           make(map[pathID]*pathOutgoing, 4),
+	}
+	// The handshake path was validated during the handshake.
+	// It is tracked like any other path, such that the connection can switch back to it.
+	validated := make(chan struct{})
+	close(validated)
+	pm.paths[0] = &pathOutgoing{
+		id:          0,
+		conn:        handshakeConn,
+		isValidated: true,
+		validated:   validated,
+	}
+	pm.originalPath = &Path{
+		id:          0,
+		pathManager: pm,
+		abandon:     make(chan struct{}),
+	}
+	pm.originalPath.validated.Store(true)
+	return pm
+}
+
+// OriginalPath returns the path that the handshake was performed on.
+func (pm *pathManagerOutgoing) OriginalPath() *Path {
+	return pm.originalPath
+}
+
+func (pm *pathManagerOutgoing) NewPath(t *Transport, conn sendConn, initialRTT time.Duration, enablePath func(pathID)) (*Path, error) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	for _, p := range pm.paths {
+		if t != nil && p.tr == t {
+			return nil, errors.New("path already exists for this Transport")
+		}
+	}
+	id := pm.nextPathID
+	pm.nextPathID++
+	p := &pathOutgoing{
+		id:        id,
+		tr:        t,
+		conn:      conn,
+		validated: make(chan struct{}),
+	}
+	if enablePath != nil {
+		p.enablePath = func() { enablePath(id) }
+	}
+	pm.paths[id] = p
+	return &Path{
+		id:          id,
+		pathManager: pm,
+		tr:          t,
+		initialRTT:  initialRTT,
+		abandon:     make(chan struct{}),
+	}, nil
+}
+
+func (pm *pathManagerOutgoing) getPath(id pathID) (*pathOutgoing, bool) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	p, ok := pm.paths[id]
+	return p, ok
+}
+
+func (pm *pathManagerOutgoing) enqueueProbe(id pathID) {
+	pm.mx.Lock()
+	pm.pathsToProbe = append(pm.pathsToProbe, id)
+	pm.mx.Unlock()
+	pm.scheduleSending()
+}
+
+func (pm *pathManagerOutgoing) switchToPath(id pathID) error {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	p, ok := pm.paths[id]
+	if !ok {
+		return ErrPathClosed
+	}
+	if pm.activePath == id {
+		return nil
+	}
+	pm.pathToSwitchTo = p
+	pm.scheduleSending()
+	return nil
+}
+
+func (pm *pathManagerOutgoing) removePath(id pathID) error {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	p, ok := pm.paths[id]
+	if !ok {
+		return nil
+	}
+	if pm.activePath == id || pm.pathToSwitchTo == p {
+		return errors.New("cannot close active path")
+	}
+	delete(pm.paths, id)
+	pm.pathsToRetire = append(pm.pathsToRetire, p)
+	pm.scheduleSending()
+	return nil
+}
+
+// NextPathToProbe returns the next path that needs to be probed,
+// the connection ID to use on that path, and the PATH_CHALLENGE frame to send.
+func (pm *pathManagerOutgoing) NextPathToProbe() (_ protocol.ConnectionID, _ ackhandler.Frame, _ sendConn, ok bool) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	var p *pathOutgoing
+	for len(pm.pathsToProbe) > 0 {
+		id := pm.pathsToProbe[0]
+		pm.pathsToProbe = pm.pathsToProbe[1:]
+		if path, ok := pm.paths[id]; ok && !path.isValidated {
+			p = path
+			break
+		}
+	}
+	if p == nil {
+		return protocol.ConnectionID{}, ackhandler.Frame{}, nil, false
+	}
+	connID, ok := pm.getConnID(p.id)
+	if !ok {
+		// We don't have an unused connection ID available.
+		// The next probe will be triggered by Path.Probe.
+		return protocol.ConnectionID{}, ackhandler.Frame{}, nil, false
+	}
+	if p.enablePath != nil {
+		p.enablePath()
+		p.enablePath = nil
+	}
+
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	p.pathChallenges = append(p.pathChallenges, b)
+	frame := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: b}}
+	return connID, frame, p.conn, true
+}
+
+// HandlePathResponseFrame handles a PATH_RESPONSE frame.
+// A PATH_RESPONSE frame received on any path validates the path the PATH_CHALLENGE was sent on,
+// see section 8.2.2 of RFC 9000.
+func (pm *pathManagerOutgoing) HandlePathResponseFrame(f *wire.PathResponseFrame) (_ sendConn, validated bool) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	for _, p := range pm.paths {
+		if p.isValidated {
+			continue
+		}
+		for _, c := range p.pathChallenges {
+			if c == f.Data {
+				p.isValidated = true
+				p.pathChallenges = nil
+				close(p.validated)
+				return p.conn, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ShouldSwitchPath returns the path that the connection should switch to, if any.
+func (pm *pathManagerOutgoing) ShouldSwitchPath() (pathID, sendConn, bool) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	if pm.pathToSwitchTo == nil {
+		return 0, nil, false
+	}
+	p := pm.pathToSwitchTo
+	pm.pathToSwitchTo = nil
+	pm.activePath = p.id
+	return p.id, p.conn, true
+}
+
+// RetireAbandonedPaths retires the paths that were closed.
+// It returns the connections used by those paths.
+func (pm *pathManagerOutgoing) RetireAbandonedPaths() []sendConn {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	if len(pm.pathsToRetire) == 0 {
+		return nil
+	}
+	conns := make([]sendConn, 0, len(pm.pathsToRetire))
+	for _, p := range pm.pathsToRetire {
+		pm.retirePath(p.id)
+		conns = append(conns, p.conn)
+	}
+	pm.pathsToRetire = pm.pathsToRetire[:0]
+	return conns
+}
@@ -0,0 +1,220 @@
+package quic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Manager (for outgoing paths)", func() {
+	var (
+		pm            *pathManagerOutgoing
+		connIDs       []protocol.ConnectionID
+		pathConnIDs   map[pathID]protocol.ConnectionID
+		retiredPaths  []pathID
+		enabledPaths  []pathID
+		scheduledSend chan struct{}
+		handshakeConn *MockSendConn
+		connCtx       context.Context
+		connCancel    context.CancelCauseFunc
+	)
+
+	BeforeEach(func() {
+		connIDs = []protocol.ConnectionID{
+			protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+			protocol.ParseConnectionID([]byte{5, 6, 7, 8}),
+		}
+		pathConnIDs = make(map[pathID]protocol.ConnectionID)
+		retiredPaths = nil
+		enabledPaths = nil
+		scheduledSend = make(chan struct{}, 100)
+		connCtx, connCancel = context.WithCancelCause(context.Background())
+		handshakeConn = NewMockSendConn(mockCtrl)
+		pm = newPathManagerOutgoing(
+			connCtx,
+			handshakeConn,
+			func(id pathID) (protocol.ConnectionID, bool) {
+				if c, ok := pathConnIDs[id]; ok {
+					return c, true
+				}
+				if len(connIDs) == 0 {
+					return protocol.ConnectionID{}, false
+				}
+				c := connIDs[0]
+				connIDs = connIDs[1:]
+				pathConnIDs[id] = c
+				return c, true
+			},
+			func(id pathID) { retiredPaths = append(retiredPaths, id) },
+			func() { scheduledSend <- struct{}{} },
+		)
+	})
+
+	AfterEach(func() { connCancel(nil) })
+
+	newPath := func(t *Transport) (*Path, *MockSendConn) {
+		conn := NewMockSendConn(mockCtrl)
+		p, err := pm.NewPath(t, conn, time.Hour, func(id pathID) { enabledPaths = append(enabledPaths, id) })
+		Expect(err).ToNot(HaveOccurred())
+		return p, conn
+	}
+
+	It("probes a path", func() {
+		p, conn := newPath(&Transport{})
+		_, _, _, ok := pm.NextPathToProbe()
+		Expect(ok).To(BeFalse())
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- p.Probe(context.Background()) }()
+		Eventually(scheduledSend).Should(Receive())
+		connID, f, c, ok := pm.NextPathToProbe()
+		Expect(ok).To(BeTrue())
+		Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+		Expect(c).To(Equal(conn))
+		Expect(f.Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+		Expect(enabledPaths).To(Equal([]pathID{p.id}))
+		_, _, _, ok = pm.NextPathToProbe()
+		Expect(ok).To(BeFalse())
+		Consistently(errChan).ShouldNot(Receive())
+
+		// a PATH_RESPONSE with unknown data doesn't validate the path
+		_, validated := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: [8]byte{'f', 'o', 'o', 'b', 'a', 'r'}})
+		Expect(validated).To(BeFalse())
+		c, validated = pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Frame.(*wire.PathChallengeFrame).Data})
+		Expect(validated).To(BeTrue())
+		Expect(c).To(Equal(conn))
+		Eventually(errChan).Should(Receive(BeNil()))
+
+		// duplicate PATH_RESPONSE frames are ignored
+		_, validated = pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Frame.(*wire.PathChallengeFrame).Data})
+		Expect(validated).To(BeFalse())
+	})
+
+	It("retransmits PATH_CHALLENGE frames", func() {
+		conn := NewMockSendConn(mockCtrl)
+		p, err := pm.NewPath(&Transport{}, conn, 10*time.Millisecond, func(pathID) {})
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errChan := make(chan error, 1)
+		go func() { errChan <- p.Probe(ctx) }()
+		var challenges []*wire.PathChallengeFrame
+		for i := 0; i < 3; i++ {
+			Eventually(scheduledSend).Should(Receive())
+			_, f, _, ok := pm.NextPathToProbe()
+			Expect(ok).To(BeTrue())
+			challenges = append(challenges, f.Frame.(*wire.PathChallengeFrame))
+		}
+		Expect(challenges[0].Data).ToNot(Equal(challenges[1].Data))
+		Expect(challenges[1].Data).ToNot(Equal(challenges[2].Data))
+
+		// a response to any of the challenges validates the path
+		_, validated := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: challenges[0].Data})
+		Expect(validated).To(BeTrue())
+		Eventually(errChan).Should(Receive(BeNil()))
+		cancel()
+	})
+
+	It("returns when the context is canceled", func() {
+		p, _ := newPath(&Transport{})
+		ctx, cancel := context.WithCancelCause(context.Background())
+		errChan := make(chan error, 1)
+		go func() { errChan <- p.Probe(ctx) }()
+		Eventually(scheduledSend).Should(Receive())
+		testErr := errors.New("test error")
+		cancel(testErr)
+		Eventually(errChan).Should(Receive(MatchError(testErr)))
+	})
+
+	It("returns when the connection is closed", func() {
+		p, _ := newPath(&Transport{})
+		errChan := make(chan error, 1)
+		go func() { errChan <- p.Probe(context.Background()) }()
+		Eventually(scheduledSend).Should(Receive())
+		testErr := errors.New("connection closed")
+		connCancel(testErr)
+		Eventually(errChan).Should(Receive(MatchError(testErr)))
+	})
+
+	It("doesn't allow multiple paths on the same Transport", func() {
+		t := &Transport{}
+		newPath(t)
+		_, err := pm.NewPath(t, NewMockSendConn(mockCtrl), time.Second, func(pathID) {})
+		Expect(err).To(MatchError("path already exists for this Transport"))
+	})
+
+	It("switches to a validated path", func() {
+		p, conn := newPath(&Transport{})
+		Expect(p.Switch()).To(MatchError(ErrPathNotValidated))
+
+		go func() { p.Probe(context.Background()) }()
+		Eventually(scheduledSend).Should(Receive())
+		_, f, _, ok := pm.NextPathToProbe()
+		Expect(ok).To(BeTrue())
+		_, validated := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Frame.(*wire.PathChallengeFrame).Data})
+		Expect(validated).To(BeTrue())
+		Eventually(p.validated.Load).Should(BeTrue())
+
+		_, _, ok = pm.ShouldSwitchPath()
+		Expect(ok).To(BeFalse())
+		Expect(p.Switch()).To(Succeed())
+		id, c, ok := pm.ShouldSwitchPath()
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(p.id))
+		Expect(c).To(Equal(conn))
+		_, _, ok = pm.ShouldSwitchPath()
+		Expect(ok).To(BeFalse())
+
+		// the active path can't be closed
+		Expect(p.Close()).To(MatchError("cannot close active path"))
+	})
+
+	It("closes paths", func() {
+		p, conn := newPath(&Transport{})
+		Expect(pm.RetireAbandonedPaths()).To(BeEmpty())
+		Expect(p.Close()).To(Succeed())
+		Expect(pm.RetireAbandonedPaths()).To(Equal([]sendConn{conn}))
+		Expect(retiredPaths).To(Equal([]pathID{p.id}))
+		Expect(pm.RetireAbandonedPaths()).To(BeEmpty())
+
+		// closing a path twice is a no-op
+		Expect(p.Close()).To(Succeed())
+		Expect(p.Probe(context.Background())).To(MatchError(ErrPathClosed))
+		Expect(p.Switch()).To(MatchError(ErrPathClosed))
+	})
+
+	It("switches back to the original path", func() {
+		p, _ := newPath(&Transport{})
+		go func() { p.Probe(context.Background()) }()
+		Eventually(scheduledSend).Should(Receive())
+		_, f, _, ok := pm.NextPathToProbe()
+		Expect(ok).To(BeTrue())
+		_, validated := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Frame.(*wire.PathChallengeFrame).Data})
+		Expect(validated).To(BeTrue())
+		Eventually(p.validated.Load).Should(BeTrue())
+		Expect(p.Switch()).To(Succeed())
+		_, _, ok = pm.ShouldSwitchPath()
+		Expect(ok).To(BeTrue())
+
+		// the original path was validated during the handshake
+		original := pm.OriginalPath()
+		Expect(original.Probe(context.Background())).To(Succeed())
+		_, _, _, ok = pm.NextPathToProbe()
+		Expect(ok).To(BeFalse())
+		Expect(original.Switch()).To(Succeed())
+		id, c, ok := pm.ShouldSwitchPath()
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(pathID(0)))
+		Expect(c).To(Equal(handshakeConn))
+
+		// now the other path can be closed
+		Expect(original.Close()).To(MatchError("cannot close active path"))
+		Expect(p.Close()).To(Succeed())
+	})
+})
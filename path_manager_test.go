@@ -0,0 +1,228 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Manager", func() {
+	var (
+		pm      *pathManager
+		allowed map[string]bool
+		calls   []net.Addr
+	)
+
+	addr := func(port int) net.Addr { return &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: port} }
+	packet := func(addr net.Addr, size int, rcvTime time.Time) receivedPacket {
+		return receivedPacket{remoteAddr: addr, rcvTime: rcvTime, data: make([]byte, size)}
+	}
+
+	BeforeEach(func() {
+		allowed = make(map[string]bool)
+		calls = nil
+		pm = newPathManager(func(a net.Addr) bool {
+			calls = append(calls, a)
+			v, ok := allowed[a.String()]
+			return !ok || v
+		})
+	})
+
+	It("asks if a new path is allowed, and caches the result", func() {
+		allowed[addr(1000).String()] = false
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeFalse())
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeFalse())
+		Expect(pm.AllowsPath(nil, addr(2000), true)).To(BeTrue())
+		Expect(pm.AllowsPath(nil, addr(2000), true)).To(BeTrue())
+		Expect(calls).To(Equal([]net.Addr{addr(1000), addr(2000)}))
+	})
+
+	It("allows NAT rebindings", func() {
+		allowed[addr(1000).String()] = false
+		Expect(pm.AllowsPath(nil, addr(1000), false)).To(BeTrue())
+		Expect(calls).To(BeEmpty())
+	})
+
+	It("doesn't send anything on paths that weren't allowed", func() {
+		allowed[addr(1000).String()] = false
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeFalse())
+		frames, size := pm.HandlePacket(packet(addr(1000), 1200, time.Now()), 10, &wire.PathChallengeFrame{Data: [8]byte{1}}, true, time.Second)
+		Expect(frames).To(BeEmpty())
+		Expect(size).To(BeZero())
+	})
+
+	It("drops the oldest path", func() {
+		for i := 0; i < maxPaths+1; i++ {
+			Expect(pm.AllowsPath(nil, addr(1000+i), true)).To(BeTrue())
+		}
+		Expect(pm.paths).To(HaveLen(maxPaths))
+		Expect(pm.getPath(nil, addr(1000))).To(BeNil())
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		Expect(calls).To(HaveLen(maxPaths + 2))
+	})
+
+	It("sends a PATH_CHALLENGE and a PATH_RESPONSE, and validates the path", func() {
+		now := time.Now()
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		frames, size := pm.HandlePacket(packet(addr(1000), 1200, now), 10, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3}}, false, time.Second)
+		Expect(size).To(Equal(protocol.ByteCount(protocol.MinInitialPacketSize)))
+		Expect(frames).To(HaveLen(2))
+		Expect(frames[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3}}))
+		Expect(frames[1].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+		challenge := frames[1].Frame.(*wire.PathChallengeFrame)
+		pm.SentPathProbe(nil, addr(1000), frames, size, now)
+
+		// a PATH_RESPONSE that doesn't match any PATH_CHALLENGE we sent
+		_, _, ok := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: [8]byte{42}})
+		Expect(ok).To(BeFalse())
+		_, _, _, ok = pm.ShouldSwitchPath(10)
+		Expect(ok).To(BeFalse())
+
+		_, a, ok := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: challenge.Data})
+		Expect(ok).To(BeTrue())
+		Expect(a).To(Equal(addr(1000)))
+		// the client hasn't sent a non-probing packet yet
+		_, _, _, ok = pm.ShouldSwitchPath(10)
+		Expect(ok).To(BeFalse())
+
+		// once validated, no further PATH_CHALLENGE frames are sent
+		frames, _ = pm.HandlePacket(packet(addr(1000), 100, now.Add(time.Hour)), 11, nil, true, time.Second)
+		Expect(frames).To(BeEmpty())
+		_, a, _, ok = pm.ShouldSwitchPath(11)
+		Expect(ok).To(BeTrue())
+		Expect(a).To(Equal(addr(1000)))
+		Expect(pm.paths).To(BeEmpty())
+	})
+
+	It("retransmits PATH_CHALLENGE frames after the PTO", func() {
+		now := time.Now()
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		frames, size := pm.HandlePacket(packet(addr(1000), 1200, now), 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		first := frames[0].Frame.(*wire.PathChallengeFrame)
+		pm.SentPathProbe(nil, addr(1000), frames, size, now)
+		frames, _ = pm.HandlePacket(packet(addr(1000), 1200, now.Add(time.Second/2)), 10, nil, true, time.Second)
+		Expect(frames).To(BeEmpty())
+		frames, size = pm.HandlePacket(packet(addr(1000), 1200, now.Add(time.Second)), 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].Frame.(*wire.PathChallengeFrame).Data).ToNot(Equal(first.Data))
+		pm.SentPathProbe(nil, addr(1000), frames, size, now.Add(time.Second))
+		// a response to the first PATH_CHALLENGE still validates the path
+		_, _, ok := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: first.Data})
+		Expect(ok).To(BeTrue())
+	})
+
+	It("respects the anti-amplification limit", func() {
+		now := time.Now()
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		frames, size := pm.HandlePacket(packet(addr(1000), 100, now), 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		Expect(size).To(Equal(protocol.ByteCount(300)))
+		pm.SentPathProbe(nil, addr(1000), frames, size, now)
+		// the amplification budget is used up
+		frames, size = pm.HandlePacket(packet(addr(1000), 0, now), 10, &wire.PathChallengeFrame{Data: [8]byte{1}}, false, time.Second)
+		Expect(frames).To(BeEmpty())
+		Expect(size).To(BeZero())
+		frames, size = pm.HandlePacket(packet(addr(1000), 50, now), 10, &wire.PathChallengeFrame{Data: [8]byte{1}}, false, time.Second)
+		Expect(frames).To(HaveLen(1))
+		Expect(size).To(Equal(protocol.ByteCount(150)))
+	})
+
+	It("only charges the bytes actually sent against the anti-amplification limit", func() {
+		now := time.Now()
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		frames, size := pm.HandlePacket(packet(addr(1000), 100, now), 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		Expect(size).To(Equal(protocol.ByteCount(300)))
+		pm.SentPathProbe(nil, addr(1000), frames, 100, now)
+		frames, size = pm.HandlePacket(packet(addr(1000), 0, now), 10, &wire.PathChallengeFrame{Data: [8]byte{1}}, false, time.Second)
+		Expect(frames).To(HaveLen(1))
+		Expect(size).To(Equal(protocol.ByteCount(200)))
+	})
+
+	It("doesn't send frames that don't fit into the anti-amplification limit", func() {
+		now := time.Now()
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		// 3 * 19 bytes are enough for a single frame, but not for both frames
+		frames, size := pm.HandlePacket(packet(addr(1000), 19, now), 10, &wire.PathChallengeFrame{Data: [8]byte{1}}, false, time.Second)
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1}}))
+		Expect(size).To(Equal(protocol.ByteCount(57)))
+		Expect(pm.AllowsPath(nil, addr(2000), true)).To(BeTrue())
+		frames, size = pm.HandlePacket(packet(addr(2000), 10, now), 10, &wire.PathChallengeFrame{Data: [8]byte{1}}, false, time.Second)
+		Expect(frames).To(BeEmpty())
+		Expect(size).To(BeZero())
+	})
+
+	It("only commits the PATH_CHALLENGE once the probe packet was sent", func() {
+		now := time.Now()
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		frames, _ := pm.HandlePacket(packet(addr(1000), 1200, now), 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		notSent := frames[0].Frame.(*wire.PathChallengeFrame)
+		// packing the probe packet failed, so we try again with the next packet
+		frames, _ = pm.HandlePacket(packet(addr(1000), 1200, now), 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		_, _, ok := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: notSent.Data})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("only switches to a path if the non-probing packet has the largest packet number", func() {
+		now := time.Now()
+		Expect(pm.AllowsPath(nil, addr(1000), false)).To(BeTrue())
+		frames, size := pm.HandlePacket(packet(addr(1000), 1200, now), 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		pm.SentPathProbe(nil, addr(1000), frames, size, now)
+		_, _, ok := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: frames[0].Frame.(*wire.PathChallengeFrame).Data})
+		Expect(ok).To(BeTrue())
+		// a packet with a larger packet number was received on the active path
+		_, _, _, ok = pm.ShouldSwitchPath(12)
+		Expect(ok).To(BeFalse())
+		// a reordered packet doesn't cause a switch
+		pm.HandlePacket(packet(addr(1000), 100, now), 9, nil, true, time.Second)
+		_, _, _, ok = pm.ShouldSwitchPath(12)
+		Expect(ok).To(BeFalse())
+		pm.HandlePacket(packet(addr(1000), 100, now), 13, nil, true, time.Second)
+		_, a, _, ok := pm.ShouldSwitchPath(13)
+		Expect(ok).To(BeTrue())
+		Expect(a).To(Equal(addr(1000)))
+	})
+
+	It("distinguishes paths by the conn packets are received on", func() {
+		rcvConn := NewMockRawConn(mockCtrl)
+		Expect(pm.AllowsPath(nil, addr(1000), true)).To(BeTrue())
+		Expect(pm.AllowsPath(rcvConn, addr(1000), true)).To(BeTrue())
+		Expect(pm.paths).To(HaveLen(2))
+
+		frames, _ := pm.HandlePacket(packet(addr(1000), 1200, time.Now()), 10, nil, false, time.Second)
+		Expect(frames).To(HaveLen(1))
+		p := packet(addr(1000), 1200, time.Now())
+		p.rcvConn = rcvConn
+		frames, size := pm.HandlePacket(p, 10, nil, true, time.Second)
+		Expect(frames).To(HaveLen(1))
+		challenge := frames[0].Frame.(*wire.PathChallengeFrame)
+		pm.SentPathProbe(rcvConn, addr(1000), frames, size, time.Now())
+		c, a, ok := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: challenge.Data})
+		Expect(ok).To(BeTrue())
+		Expect(c).To(Equal(rcvConn))
+		Expect(a).To(Equal(addr(1000)))
+		c, a, _, ok = pm.ShouldSwitchPath(10)
+		Expect(ok).To(BeTrue())
+		Expect(c).To(Equal(rcvConn))
+		Expect(a).To(Equal(addr(1000)))
+		Expect(pm.paths).To(HaveLen(1))
+	})
+
+	It("compares addresses", func() {
+		Expect(addrsEqual(addr(1000), addr(1000))).To(BeTrue())
+		Expect(addrsEqual(addr(1000), addr(1001))).To(BeFalse())
+		Expect(addrsEqual(addr(1000), &net.UDPAddr{IP: net.IPv4(192, 168, 0, 2), Port: 1000})).To(BeFalse())
+		Expect(addrsEqual(addr(1000), nil)).To(BeFalse())
+		Expect(addrsEqual(nil, nil)).To(BeFalse())
+	})
+})
@@ -0,0 +1,73 @@
+package quic
+
+import (
+	"net"
+	"time"
+)
+
+// PathState is the state of a path of a multipath connection, as passed to the PathScheduler.
+type PathState struct {
+	ID         PathID
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	// SmoothedRTT is the smoothed RTT of the path.
+	// It is 0 until the first RTT sample was obtained on this path.
+	SmoothedRTT time.Duration
+	// Standby says if the peer asked us to not use this path,
+	// unless no other path is available.
+	Standby bool
+}
+
+// A PathScheduler decides which path a packet is sent on,
+// for connections that negotiated the multipath extension.
+type PathScheduler interface {
+	// SelectPath is called for every packet sent on a multipath connection.
+	// It is only passed paths that have been validated, and on which the
+	// congestion controller currently allows sending.
+	// It must return the ID of one of these paths.
+	// It is called from the connection's run loop, and must not block.
+	SelectPath([]PathState) PathID
+}
+
+type minRTTPathScheduler struct{}
+
+// NewMinRTTPathScheduler creates a PathScheduler that sends packets on the path with the lowest smoothed RTT.
+// Once the congestion window of this path is filled, packets are sent on the path with the next lowest RTT.
+// Paths without an RTT sample are used first, such that an RTT sample can be obtained.
+func NewMinRTTPathScheduler() PathScheduler { return &minRTTPathScheduler{} }
+
+func (s *minRTTPathScheduler) SelectPath(paths []PathState) PathID {
+	best := paths[0]
+	for _, p := range paths[1:] {
+		if p.SmoothedRTT < best.SmoothedRTT {
+			best = p
+		}
+	}
+	return best.ID
+}
+
+type roundRobinPathScheduler struct {
+	last PathID
+}
+
+// NewRoundRobinPathScheduler creates a PathScheduler that uses all paths in turn.
+func NewRoundRobinPathScheduler() PathScheduler { return &roundRobinPathScheduler{} }
+
+func (s *roundRobinPathScheduler) SelectPath(paths []PathState) PathID {
+	// Use the path with the smallest ID larger than the last path used.
+	// If there's no such path, start over with the smallest path ID.
+	next, first := paths[0].ID, paths[0].ID
+	var found bool
+	for _, p := range paths {
+		first = min(first, p.ID)
+		if p.ID > s.last && (!found || p.ID < next) {
+			next = p.ID
+			found = true
+		}
+	}
+	if !found {
+		next = first
+	}
+	s.last = next
+	return next
+}
@@ -0,0 +1,50 @@
+package quic
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Scheduler", func() {
+	Context("min RTT", func() {
+		It("selects the path with the lowest RTT", func() {
+			s := NewMinRTTPathScheduler()
+			paths := []PathState{
+				{ID: 0, SmoothedRTT: 30 * time.Millisecond},
+				{ID: 1, SmoothedRTT: 10 * time.Millisecond},
+				{ID: 2, SmoothedRTT: 20 * time.Millisecond},
+			}
+			Expect(s.SelectPath(paths)).To(Equal(PathID(1)))
+			Expect(s.SelectPath(paths[:1])).To(Equal(PathID(0)))
+		})
+
+		It("prefers paths without an RTT sample", func() {
+			s := NewMinRTTPathScheduler()
+			Expect(s.SelectPath([]PathState{
+				{ID: 0, SmoothedRTT: 10 * time.Millisecond},
+				{ID: 3},
+			})).To(Equal(PathID(3)))
+		})
+	})
+
+	Context("round robin", func() {
+		It("uses all paths in turn", func() {
+			s := NewRoundRobinPathScheduler()
+			paths := []PathState{{ID: 2}, {ID: 0}, {ID: 5}}
+			var selected []PathID
+			for i := 0; i < 6; i++ {
+				selected = append(selected, s.SelectPath(paths))
+			}
+			Expect(selected).To(Equal([]PathID{2, 5, 0, 2, 5, 0}))
+		})
+
+		It("skips paths that are not passed to it", func() {
+			s := NewRoundRobinPathScheduler()
+			Expect(s.SelectPath([]PathState{{ID: 0}, {ID: 1}, {ID: 2}})).To(Equal(PathID(1)))
+			Expect(s.SelectPath([]PathState{{ID: 0}, {ID: 1}})).To(Equal(PathID(0)))
+			Expect(s.SelectPath([]PathState{{ID: 0}, {ID: 2}})).To(Equal(PathID(2)))
+		})
+	})
+})
@@ -46,6 +46,9 @@ func NewConnectionTracer(w io.WriteCloser, p logging.Perspective, odcid protocol
 		SentTransportParameters:     func(tp *wire.TransportParameters) { t.SentTransportParameters(tp) },
 		ReceivedTransportParameters: func(tp *wire.TransportParameters) { t.ReceivedTransportParameters(tp) },
 		RestoredTransportParameters: func(tp *wire.TransportParameters) { t.RestoredTransportParameters(tp) },
+		Rejected0RTT: func(reason logging.ZeroRTTRejectReason) {
+			t.recordEvent(time.Now(), &eventZeroRTTRejected{reason: reason})
+		},
 		SentLongHeaderPacket: func(hdr *logging.ExtendedHeader, size logging.ByteCount, ecn logging.ECN, ack *logging.AckFrame, frames []logging.Frame) {
 			t.SentLongHeaderPacket(hdr, size, ecn, ack, frames)
 		},
@@ -227,6 +230,10 @@ func (t *connectionTracer) toTransportParameters(tp *wire.TransportParameters) *
 		InitialMaxStreamsUni:            int64(tp.MaxUniStreamNum),
 		PreferredAddress:                pa,
 		MaxDatagramFrameSize:            tp.MaxDatagramFrameSize,
+		InitialMaxPathID:                tp.InitialMaxPathID,
+		EnableResetStreamAt:             tp.EnableResetStreamAt,
+		MinAckDelay:                     tp.MinAckDelay,
+		GreaseQUICBit:                   tp.GreaseQUICBit,
 	}
 }
 
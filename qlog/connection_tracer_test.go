@@ -305,6 +305,55 @@ func TestTransportParametersWithDatagramExtension(t *testing.T) {
 	require.Equal(t, float64(1337), ev["max_datagram_frame_size"])
 }
 
+func TestTransportParametersWithMultipathExtension(t *testing.T) {
+	tracer, buf := newConnectionTracer()
+	maxPathID := protocol.PathID(3)
+	tracer.SentTransportParameters(&logging.TransportParameters{
+		MaxDatagramFrameSize: protocol.InvalidByteCount,
+		InitialMaxPathID:     &maxPathID,
+	})
+	tracer.Close()
+	entry := exportAndParseSingle(t, buf)
+	require.Equal(t, "transport:parameters_set", entry.Name)
+	require.Equal(t, float64(3), entry.Event["initial_max_path_id"])
+}
+
+func TestTransportParametersWithResetStreamAt(t *testing.T) {
+	tracer, buf := newConnectionTracer()
+	tracer.SentTransportParameters(&logging.TransportParameters{
+		MaxDatagramFrameSize: protocol.InvalidByteCount,
+		EnableResetStreamAt:  true,
+	})
+	tracer.Close()
+	entry := exportAndParseSingle(t, buf)
+	require.Equal(t, "transport:parameters_set", entry.Name)
+	require.Equal(t, true, entry.Event["reset_stream_at"])
+}
+
+func TestTransportParametersWithMinAckDelay(t *testing.T) {
+	tracer, buf := newConnectionTracer()
+	tracer.SentTransportParameters(&logging.TransportParameters{
+		MaxDatagramFrameSize: protocol.InvalidByteCount,
+		MinAckDelay:          1500 * time.Microsecond,
+	})
+	tracer.Close()
+	entry := exportAndParseSingle(t, buf)
+	require.Equal(t, "transport:parameters_set", entry.Name)
+	require.Equal(t, 1.5, entry.Event["min_ack_delay"])
+}
+
+func TestTransportParametersWithGreaseQUICBit(t *testing.T) {
+	tracer, buf := newConnectionTracer()
+	tracer.SentTransportParameters(&logging.TransportParameters{
+		MaxDatagramFrameSize: protocol.InvalidByteCount,
+		GreaseQUICBit:        true,
+	})
+	tracer.Close()
+	entry := exportAndParseSingle(t, buf)
+	require.Equal(t, "transport:parameters_set", entry.Name)
+	require.Equal(t, true, entry.Event["grease_quic_bit"])
+}
+
 func TestReceivedTransportParameters(t *testing.T) {
 	tracer, buf := newConnectionTracer()
 	tracer.ReceivedTransportParameters(&logging.TransportParameters{})
@@ -877,6 +926,18 @@ func TestECNStateTransitionWithTrigger(t *testing.T) {
 	require.Equal(t, "ACK doesn't contain ECN marks", ev["trigger"])
 }
 
+func TestZeroRTTRejected(t *testing.T) {
+	tracer, buf := newConnectionTracer()
+	tracer.Rejected0RTT(logging.ZeroRTTRejectedReplay)
+	tracer.Close()
+	entry := exportAndParseSingle(t, buf)
+	require.WithinDuration(t, time.Now(), entry.Time, scaleDuration(10*time.Millisecond))
+	require.Equal(t, "security:zero_rtt_rejected", entry.Name)
+	ev := entry.Event
+	require.Len(t, ev, 1)
+	require.Equal(t, "replay", ev["reason"])
+}
+
 func TestGenericConnectionTracerEvent(t *testing.T) {
 	tracer, buf := newConnectionTracer()
 	tracer.Debug("foo", "bar")
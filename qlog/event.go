@@ -435,6 +435,14 @@ type eventTransportParameters struct {
 	PreferredAddress *preferredAddress
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	InitialMaxPathID *protocol.PathID
+
+	EnableResetStreamAt bool
+
+	MinAckDelay time.Duration
+
+	GreaseQUICBit bool
 }
 
 func (e eventTransportParameters) Category() category { return categoryTransport }
@@ -480,6 +488,12 @@ func (e eventTransportParameters) MarshalJSONObject(enc *gojay.Encoder) {
 	if e.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		enc.Int64Key("max_datagram_frame_size", int64(e.MaxDatagramFrameSize))
 	}
+	if e.InitialMaxPathID != nil {
+		enc.Uint64Key("initial_max_path_id", uint64(*e.InitialMaxPathID))
+	}
+	enc.BoolKeyOmitEmpty("reset_stream_at", e.EnableResetStreamAt)
+	enc.FloatKeyOmitEmpty("min_ack_delay", milliseconds(e.MinAckDelay))
+	enc.BoolKeyOmitEmpty("grease_quic_bit", e.GreaseQUICBit)
 }
 
 type preferredAddress struct {
@@ -581,6 +595,18 @@ func (e eventGeneric) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("details", e.msg)
 }
 
+type eventZeroRTTRejected struct {
+	reason logging.ZeroRTTRejectReason
+}
+
+func (e eventZeroRTTRejected) Category() category { return categorySecurity }
+func (e eventZeroRTTRejected) Name() string       { return "zero_rtt_rejected" }
+func (e eventZeroRTTRejected) IsNil() bool        { return false }
+
+func (e eventZeroRTTRejected) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("reason", zeroRTTRejectReason(e.reason).String())
+}
+
 type eventALPNInformation struct {
 	chosenALPN string
 }
@@ -59,6 +59,24 @@ func (f frame) MarshalJSONObject(enc *gojay.Encoder) {
 		marshalHandshakeDoneFrame(enc, frame)
 	case *logging.DatagramFrame:
 		marshalDatagramFrame(enc, frame)
+	case *logging.PathAckFrame:
+		marshalPathAckFrame(enc, frame)
+	case *logging.PathAbandonFrame:
+		marshalPathAbandonFrame(enc, frame)
+	case *logging.PathStandbyFrame:
+		marshalPathStandbyFrame(enc, frame)
+	case *logging.PathAvailableFrame:
+		marshalPathAvailableFrame(enc, frame)
+	case *logging.MPNewConnectionIDFrame:
+		marshalMPNewConnectionIDFrame(enc, frame)
+	case *logging.MPRetireConnectionIDFrame:
+		marshalMPRetireConnectionIDFrame(enc, frame)
+	case *logging.MaxPathIDFrame:
+		marshalMaxPathIDFrame(enc, frame)
+	case *logging.AckFrequencyFrame:
+		marshalAckFrequencyFrame(enc, frame)
+	case *logging.ImmediateAckFrame:
+		marshalImmediateAckFrame(enc, frame)
 	default:
 		panic("unknown frame type")
 	}
@@ -102,6 +120,10 @@ func (ar ackRange) IsNil() bool { return false }
 
 func marshalAckFrame(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.StringKey("frame_type", "ack")
+	marshalAckFrameFields(enc, f)
+}
+
+func marshalAckFrameFields(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.FloatKeyOmitEmpty("ack_delay", milliseconds(f.DelayTime))
 	enc.ArrayKey("acked_ranges", ackRanges(f.AckRanges))
 	if hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0; hasECN {
@@ -112,10 +134,17 @@ func marshalAckFrame(enc *gojay.Encoder, f *logging.AckFrame) {
 }
 
 func marshalResetStreamFrame(enc *gojay.Encoder, f *logging.ResetStreamFrame) {
-	enc.StringKey("frame_type", "reset_stream")
+	if f.ReliableSize > 0 {
+		enc.StringKey("frame_type", "reset_stream_at")
+	} else {
+		enc.StringKey("frame_type", "reset_stream")
+	}
 	enc.Int64Key("stream_id", int64(f.StreamID))
 	enc.Int64Key("error_code", int64(f.ErrorCode))
 	enc.Int64Key("final_size", int64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		enc.Int64Key("reliable_size", int64(f.ReliableSize))
+	}
 }
 
 func marshalStopSendingFrame(enc *gojay.Encoder, f *logging.StopSendingFrame) {
@@ -225,3 +254,61 @@ func marshalDatagramFrame(enc *gojay.Encoder, f *logging.DatagramFrame) {
 	enc.StringKey("frame_type", "datagram")
 	enc.Int64Key("length", int64(f.Length))
 }
+
+func marshalPathAckFrame(enc *gojay.Encoder, f *logging.PathAckFrame) {
+	enc.StringKey("frame_type", "path_ack")
+	enc.Uint64Key("path_id", uint64(f.PathID))
+	marshalAckFrameFields(enc, &f.AckFrame)
+}
+
+func marshalPathAbandonFrame(enc *gojay.Encoder, f *logging.PathAbandonFrame) {
+	enc.StringKey("frame_type", "path_abandon")
+	enc.Uint64Key("path_id", uint64(f.PathID))
+	enc.Uint64Key("error_code", f.ErrorCode)
+	enc.StringKey("reason", f.ReasonPhrase)
+}
+
+func marshalPathStandbyFrame(enc *gojay.Encoder, f *logging.PathStandbyFrame) {
+	enc.StringKey("frame_type", "path_standby")
+	enc.Uint64Key("path_id", uint64(f.PathID))
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+}
+
+func marshalPathAvailableFrame(enc *gojay.Encoder, f *logging.PathAvailableFrame) {
+	enc.StringKey("frame_type", "path_available")
+	enc.Uint64Key("path_id", uint64(f.PathID))
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+}
+
+func marshalMPNewConnectionIDFrame(enc *gojay.Encoder, f *logging.MPNewConnectionIDFrame) {
+	enc.StringKey("frame_type", "mp_new_connection_id")
+	enc.Uint64Key("path_id", uint64(f.PathID))
+	enc.Int64Key("sequence_number", int64(f.SequenceNumber))
+	enc.Int64Key("retire_prior_to", int64(f.RetirePriorTo))
+	enc.IntKey("length", f.ConnectionID.Len())
+	enc.StringKey("connection_id", f.ConnectionID.String())
+	enc.StringKey("stateless_reset_token", fmt.Sprintf("%x", f.StatelessResetToken))
+}
+
+func marshalMPRetireConnectionIDFrame(enc *gojay.Encoder, f *logging.MPRetireConnectionIDFrame) {
+	enc.StringKey("frame_type", "mp_retire_connection_id")
+	enc.Uint64Key("path_id", uint64(f.PathID))
+	enc.Int64Key("sequence_number", int64(f.SequenceNumber))
+}
+
+func marshalMaxPathIDFrame(enc *gojay.Encoder, f *logging.MaxPathIDFrame) {
+	enc.StringKey("frame_type", "max_path_id")
+	enc.Uint64Key("maximum_path_id", uint64(f.MaxPathID))
+}
+
+func marshalAckFrequencyFrame(enc *gojay.Encoder, f *logging.AckFrequencyFrame) {
+	enc.StringKey("frame_type", "ack_frequency")
+	enc.Uint64Key("sequence_number", f.SequenceNumber)
+	enc.Uint64Key("ack_eliciting_threshold", f.AckElicitingThreshold)
+	enc.FloatKey("request_max_ack_delay", milliseconds(f.RequestMaxAckDelay))
+	enc.Int64Key("reordering_threshold", int64(f.ReorderingThreshold))
+}
+
+func marshalImmediateAckFrame(enc *gojay.Encoder, _ *logging.ImmediateAckFrame) {
+	enc.StringKey("frame_type", "immediate_ack")
+}
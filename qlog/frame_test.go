@@ -119,6 +119,24 @@ func TestResetStreamFrame(t *testing.T) {
 	)
 }
 
+func TestResetStreamAtFrame(t *testing.T) {
+	check(t,
+		&logging.ResetStreamFrame{
+			StreamID:     987,
+			FinalSize:    1234,
+			ErrorCode:    42,
+			ReliableSize: 999,
+		},
+		map[string]interface{}{
+			"frame_type":    "reset_stream_at",
+			"stream_id":     987,
+			"error_code":    42,
+			"final_size":    1234,
+			"reliable_size": 999,
+		},
+	)
+}
+
 func TestStopSendingFrame(t *testing.T) {
 	check(t,
 		&logging.StopSendingFrame{
@@ -315,6 +333,133 @@ func TestRetireConnectionIDFrame(t *testing.T) {
 	)
 }
 
+func TestPathAckFrame(t *testing.T) {
+	check(t,
+		&logging.PathAckFrame{
+			PathID: 2,
+			AckFrame: logging.AckFrame{
+				AckRanges: []logging.AckRange{{Smallest: 1, Largest: 10}},
+				DelayTime: 86 * time.Millisecond,
+			},
+		},
+		map[string]interface{}{
+			"frame_type":   "path_ack",
+			"path_id":      2,
+			"ack_delay":    86,
+			"acked_ranges": [][]float64{{1, 10}},
+		},
+	)
+}
+
+func TestPathAbandonFrame(t *testing.T) {
+	check(t,
+		&logging.PathAbandonFrame{
+			PathID:       3,
+			ErrorCode:    1337,
+			ReasonPhrase: "foobar",
+		},
+		map[string]interface{}{
+			"frame_type": "path_abandon",
+			"path_id":    3,
+			"error_code": 1337,
+			"reason":     "foobar",
+		},
+	)
+}
+
+func TestPathStandbyFrame(t *testing.T) {
+	check(t,
+		&logging.PathStandbyFrame{PathID: 1, SequenceNumber: 42},
+		map[string]interface{}{
+			"frame_type":      "path_standby",
+			"path_id":         1,
+			"sequence_number": 42,
+		},
+	)
+}
+
+func TestPathAvailableFrame(t *testing.T) {
+	check(t,
+		&logging.PathAvailableFrame{PathID: 1, SequenceNumber: 43},
+		map[string]interface{}{
+			"frame_type":      "path_available",
+			"path_id":         1,
+			"sequence_number": 43,
+		},
+	)
+}
+
+func TestMPNewConnectionIDFrame(t *testing.T) {
+	check(t,
+		&logging.MPNewConnectionIDFrame{
+			PathID: 5,
+			NewConnectionIDFrame: logging.NewConnectionIDFrame{
+				SequenceNumber:      42,
+				RetirePriorTo:       24,
+				ConnectionID:        protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
+				StatelessResetToken: protocol.StatelessResetToken{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0xa, 0xb, 0xc, 0xd, 0xe, 0xf},
+			},
+		},
+		map[string]interface{}{
+			"frame_type":            "mp_new_connection_id",
+			"path_id":               5,
+			"sequence_number":       42,
+			"retire_prior_to":       24,
+			"length":                4,
+			"connection_id":         "deadbeef",
+			"stateless_reset_token": "000102030405060708090a0b0c0d0e0f",
+		},
+	)
+}
+
+func TestMPRetireConnectionIDFrame(t *testing.T) {
+	check(t,
+		&logging.MPRetireConnectionIDFrame{PathID: 5, SequenceNumber: 1337},
+		map[string]interface{}{
+			"frame_type":      "mp_retire_connection_id",
+			"path_id":         5,
+			"sequence_number": 1337,
+		},
+	)
+}
+
+func TestMaxPathIDFrame(t *testing.T) {
+	check(t,
+		&logging.MaxPathIDFrame{MaxPathID: 8},
+		map[string]interface{}{
+			"frame_type":      "max_path_id",
+			"maximum_path_id": 8,
+		},
+	)
+}
+
+func TestAckFrequencyFrame(t *testing.T) {
+	check(t,
+		&logging.AckFrequencyFrame{
+			SequenceNumber:        3,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    25 * time.Millisecond,
+			ReorderingThreshold:   1,
+		},
+		map[string]interface{}{
+			"frame_type":              "ack_frequency",
+			"sequence_number":         3,
+			"ack_eliciting_threshold": 10,
+			"request_max_ack_delay":   25,
+			"reordering_threshold":    1,
+		},
+	)
+}
+
+func TestImmediateAckFrame(t *testing.T) {
+	check(t,
+		&logging.ImmediateAckFrame{},
+		map[string]interface{}{
+			"frame_type": "immediate_ack",
+		},
+	)
+}
+
 func TestPathChallengeFrame(t *testing.T) {
 	check(t,
 		&logging.PathChallengeFrame{
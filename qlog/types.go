@@ -209,6 +209,8 @@ func (e transportError) String() string {
 		return "aead_limit_reached"
 	case qerr.NoViablePathError:
 		return "no_viable_path"
+	case qerr.VersionNegotiationErrorCode:
+		return "version_negotiation_error"
 	default:
 		return ""
 	}
@@ -308,6 +310,14 @@ func (s congestionState) String() string {
 		return "recovery"
 	case logging.CongestionStateApplicationLimited:
 		return "application_limited"
+	case logging.CongestionStateStartup:
+		return "startup"
+	case logging.CongestionStateDrain:
+		return "drain"
+	case logging.CongestionStateProbeBW:
+		return "probe_bw"
+	case logging.CongestionStateProbeRTT:
+		return "probe_rtt"
 	default:
 		return "unknown congestion state"
 	}
@@ -370,3 +380,20 @@ func (e ecnStateTrigger) String() string {
 		return "unknown ECN state trigger"
 	}
 }
+
+type zeroRTTRejectReason logging.ZeroRTTRejectReason
+
+func (r zeroRTTRejectReason) String() string {
+	switch logging.ZeroRTTRejectReason(r) {
+	case logging.ZeroRTTRejectedDisabled:
+		return "disabled"
+	case logging.ZeroRTTRejectedTransportParameters:
+		return "transport_parameters_changed"
+	case logging.ZeroRTTRejectedByApplication:
+		return "application"
+	case logging.ZeroRTTRejectedReplay:
+		return "replay"
+	default:
+		return "unknown"
+	}
+}
@@ -187,6 +187,7 @@ func TestTransportErrorStringRepresentation(t *testing.T) {
 		{qerr.ApplicationErrorErrorCode, "application_error"},
 		{qerr.CryptoBufferExceeded, "crypto_buffer_exceeded"},
 		{qerr.NoViablePathError, "no_viable_path"},
+		{qerr.VersionNegotiationErrorCode, "version_negotiation_error"},
 		{1337, ""},
 	}
 
@@ -204,6 +205,10 @@ func TestCongestionStateUpdatesStringRepresentation(t *testing.T) {
 		{logging.CongestionStateCongestionAvoidance, "congestion_avoidance"},
 		{logging.CongestionStateApplicationLimited, "application_limited"},
 		{logging.CongestionStateRecovery, "recovery"},
+		{logging.CongestionStateStartup, "startup"},
+		{logging.CongestionStateDrain, "drain"},
+		{logging.CongestionStateProbeBW, "probe_bw"},
+		{logging.CongestionStateProbeRTT, "probe_rtt"},
 	}
 
 	for _, tc := range testCases {
@@ -264,3 +269,20 @@ func TestECNStateTriggerStringRepresentation(t *testing.T) {
 		require.Equal(t, tc.expected, ecnStateTrigger(tc.trigger).String())
 	}
 }
+
+func TestZeroRTTRejectReasonStringRepresentation(t *testing.T) {
+	testCases := []struct {
+		reason   logging.ZeroRTTRejectReason
+		expected string
+	}{
+		{logging.ZeroRTTRejectedDisabled, "disabled"},
+		{logging.ZeroRTTRejectedTransportParameters, "transport_parameters_changed"},
+		{logging.ZeroRTTRejectedByApplication, "application"},
+		{logging.ZeroRTTRejectedReplay, "replay"},
+		{42, "unknown"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, zeroRTTRejectReason(tc.reason).String())
+	}
+}
@@ -0,0 +1,125 @@
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+var errInvalidLength = errors.New("quiclb: invalid length")
+
+// An lbCipher encrypts and decrypts the server ID and the nonce.
+type lbCipher struct {
+	block        cipher.Block
+	plaintextLen int
+}
+
+// Encrypt encrypts the plaintext (server ID followed by the nonce) in place.
+func (c *lbCipher) Encrypt(b []byte) error {
+	if len(b) != c.plaintextLen {
+		return errInvalidLength
+	}
+	if c.plaintextLen == aes.BlockSize {
+		c.block.Encrypt(b, b)
+		return nil
+	}
+	left, right := c.split(b)
+	right = c.xorRight(right, c.round(left, 1))
+	left = c.xorLeft(left, c.round(right, 2))
+	right = c.xorRight(right, c.round(left, 3))
+	left = c.xorLeft(left, c.round(right, 4))
+	c.join(b, left, right)
+	return nil
+}
+
+// Decrypt decrypts the ciphertext in place.
+func (c *lbCipher) Decrypt(b []byte) error {
+	if len(b) != c.plaintextLen {
+		return errInvalidLength
+	}
+	if c.plaintextLen == aes.BlockSize {
+		c.block.Decrypt(b, b)
+		return nil
+	}
+	left, right := c.split(b)
+	left = c.xorLeft(left, c.round(right, 4))
+	right = c.xorRight(right, c.round(left, 3))
+	left = c.xorLeft(left, c.round(right, 2))
+	right = c.xorRight(right, c.round(left, 1))
+	c.join(b, left, right)
+	return nil
+}
+
+func (c *lbCipher) halfLen() int {
+	return (c.plaintextLen + 1) / 2
+}
+
+func (c *lbCipher) isOdd() bool {
+	return c.plaintextLen%2 == 1
+}
+
+// split splits b into two halves.
+// If the length is odd, the middle octet is split between the halves:
+// the left half gets the upper 4 bits, the right half gets the lower 4 bits.
+func (c *lbCipher) split(b []byte) (left, right []byte) {
+	l := c.halfLen()
+	left = make([]byte, l)
+	right = make([]byte, l)
+	copy(left, b[:l])
+	copy(right, b[len(b)-l:])
+	if c.isOdd() {
+		left[l-1] &= 0xf0
+		right[0] &= 0x0f
+	}
+	return left, right
+}
+
+func (c *lbCipher) join(b, left, right []byte) {
+	l := c.halfLen()
+	copy(b[len(b)-l:], right)
+	if c.isOdd() {
+		copy(b[:l-1], left[:l-1])
+		b[l-1] = left[l-1] | right[0]
+		return
+	}
+	copy(b[:l], left)
+}
+
+// round applies the round function to one half.
+// The result is truncated to the length of a half, and used to modify the other half.
+func (c *lbCipher) round(half []byte, pass byte) []byte {
+	out := c.expandAndEncrypt(half, pass)
+	return out[:c.halfLen()]
+}
+
+// expandAndEncrypt expands a half to a full AES block, and encrypts it.
+// The expanded block consists of the half, padded with zeros, followed by
+// the plaintext length and the pass index.
+func (c *lbCipher) expandAndEncrypt(half []byte, pass byte) []byte {
+	var block [aes.BlockSize]byte
+	copy(block[:], half)
+	block[aes.BlockSize-2] = byte(c.plaintextLen)
+	block[aes.BlockSize-1] = pass
+	c.block.Encrypt(block[:], block[:])
+	return block[:]
+}
+
+func (c *lbCipher) xorLeft(left, mask []byte) []byte {
+	for i := range left {
+		left[i] ^= mask[i]
+	}
+	if c.isOdd() {
+		left[len(left)-1] &= 0xf0
+	}
+	return left
+}
+
+func (c *lbCipher) xorRight(right, mask []byte) []byte {
+	for i := range right {
+		right[i] ^= mask[i]
+	}
+	if c.isOdd() {
+		right[0] &= 0x0f
+	}
+	return right
+}
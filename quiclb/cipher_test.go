@@ -0,0 +1,107 @@
+package quiclb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	key := make([]byte, 16)
+	rand.Read(key)
+	for l := 5; l <= 19; l++ {
+		t.Run(fmt.Sprintf("length %d", l), func(t *testing.T) {
+			c, err := (&Config{ServerIDLen: 1, NonceLen: l - 1, Key: key}).newCipher()
+			require.NoError(t, err)
+			for i := 0; i < 100; i++ {
+				plaintext := make([]byte, l)
+				rand.Read(plaintext)
+				b := bytes.Clone(plaintext)
+				require.NoError(t, c.Encrypt(b))
+				require.NotEqual(t, plaintext, b)
+				require.NoError(t, c.Decrypt(b))
+				require.Equal(t, plaintext, b)
+			}
+		})
+	}
+}
+
+// testVectorKey is the key used for the test vectors in draft-ietf-quic-load-balancers, Appendix B.
+const testVectorKey = "8f95f09245765f80256934e50c66207f"
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestCipherTestVectors(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		serverID, nonce string
+		ciphertext      string
+	}{
+		// These test vectors are taken from draft-ietf-quic-load-balancers, Appendix B.
+		// The connection IDs in the draft start with the first octet, which is not encrypted.
+		{
+			name:       "single-pass",
+			serverID:   "ed793a51d49b8f5f",
+			nonce:      "ee080dbf48c0d1e5",
+			ciphertext: "4dd2d05a7b0de9b2b9907afb5ecf8cc3",
+		},
+		{
+			name:       "four-pass, odd length",
+			serverID:   "ed793a",
+			nonce:      "ee080dbf",
+			ciphertext: "20b1d07b359d3c",
+		},
+		{
+			name:       "four-pass, odd length, long server ID",
+			serverID:   "ed793a51d49b8f5fab65",
+			nonce:      "ee080dbf48",
+			ciphertext: "cc381bc74cb4fbad2823a3d1f8fed2",
+		},
+		{
+			// The draft doesn't contain a test vector for this case.
+			name:       "four-pass, even length",
+			serverID:   "ed793a51",
+			nonce:      "ee080dbf48c0",
+			ciphertext: "0ac36ede5dec665f16de",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			serverID := decodeHex(t, tc.serverID)
+			nonce := decodeHex(t, tc.nonce)
+			c, err := (&Config{
+				ServerIDLen: len(serverID),
+				NonceLen:    len(nonce),
+				Key:         decodeHex(t, testVectorKey),
+			}).newCipher()
+			require.NoError(t, err)
+			plaintext := append(serverID, nonce...)
+			b := bytes.Clone(plaintext)
+			require.NoError(t, c.Encrypt(b))
+			require.Equal(t, decodeHex(t, tc.ciphertext), b)
+			require.NoError(t, c.Decrypt(b))
+			require.Equal(t, plaintext, b)
+		})
+	}
+}
+
+func TestCipherInvalidLength(t *testing.T) {
+	c, err := (&Config{ServerIDLen: 2, NonceLen: 6, Key: make([]byte, 16)}).newCipher()
+	require.NoError(t, err)
+	require.ErrorIs(t, c.Encrypt(make([]byte, 7)), errInvalidLength)
+	require.ErrorIs(t, c.Decrypt(make([]byte, 9)), errInvalidLength)
+}
+
+func TestCipherPlaintext(t *testing.T) {
+	c, err := (&Config{ServerIDLen: 2, NonceLen: 6}).newCipher()
+	require.NoError(t, err)
+	require.Nil(t, c)
+}
@@ -0,0 +1,96 @@
+// Package quiclb implements connection ID encoding for QUIC-LB (draft-ietf-quic-load-balancers).
+//
+// QUIC-LB allows stateless load balancers to route QUIC packets to the server that
+// generated the connection ID, by encoding a server ID into the connection ID.
+// Servers use a Generator as the quic.Transport's ConnectionIDGenerator,
+// load balancers use a Decoder to extract the server ID from the connection ID.
+//
+// This package should not be considered stable.
+package quiclb
+
+import (
+	"crypto/aes"
+	"fmt"
+)
+
+const (
+	// MaxConfigID is the largest config ID that can be used.
+	// The config rotation codepoint 0b111 is reserved for unroutable connection IDs.
+	MaxConfigID = 6
+	// unroutableConfigID is used for connection IDs that don't encode a server ID.
+	unroutableConfigID = 7
+
+	maxServerIDLen         = 15
+	minNonceLen            = 4
+	maxNonceLen            = 18
+	maxServerIDAndNonceLen = 19
+	keyLen                 = 16
+)
+
+// A Config is a QUIC-LB configuration.
+// The same configuration needs to be used by the servers and by the load balancer.
+type Config struct {
+	// ConfigID is encoded into the config rotation bits of the first octet of the connection ID.
+	// It allows the load balancer to use multiple configurations at the same time, e.g. during a key rotation.
+	// It must be between 0 and MaxConfigID.
+	ConfigID uint8
+	// ServerIDLen is the length of the server ID, in bytes.
+	// It must be between 1 and 15.
+	ServerIDLen int
+	// NonceLen is the length of the nonce, in bytes.
+	// It must be between 4 and 18, and ServerIDLen + NonceLen must not exceed 19.
+	NonceLen int
+	// Key is the 16 byte AES-128 key used to encrypt the server ID and the nonce.
+	// If unset, the server ID is encoded in plaintext.
+	// If the sum of ServerIDLen and NonceLen is 16, a single AES block encryption is used,
+	// otherwise the four-pass algorithm is used.
+	Key []byte
+	// EncodeLength makes the first octet encode the length of the connection ID.
+	// This allows intermediaries that are not aware of the configuration to parse the connection ID.
+	// If unset, these bits are random.
+	EncodeLength bool
+}
+
+func (c *Config) validate() error {
+	if c.ConfigID > MaxConfigID {
+		return fmt.Errorf("quiclb: invalid config ID: %d", c.ConfigID)
+	}
+	if c.ServerIDLen < 1 || c.ServerIDLen > maxServerIDLen {
+		return fmt.Errorf("quiclb: invalid server ID length: %d", c.ServerIDLen)
+	}
+	if c.NonceLen < minNonceLen || c.NonceLen > maxNonceLen {
+		return fmt.Errorf("quiclb: invalid nonce length: %d", c.NonceLen)
+	}
+	if c.ServerIDLen+c.NonceLen > maxServerIDAndNonceLen {
+		return fmt.Errorf("quiclb: server ID and nonce too long: %d bytes", c.ServerIDLen+c.NonceLen)
+	}
+	if c.Key != nil && len(c.Key) != keyLen {
+		return fmt.Errorf("quiclb: invalid key length: %d", len(c.Key))
+	}
+	return nil
+}
+
+// connIDLen is the length of the connection IDs generated using this configuration.
+func (c *Config) connIDLen() int {
+	return 1 + c.ServerIDLen + c.NonceLen
+}
+
+func (c *Config) newCipher() (*lbCipher, error) {
+	if c.Key == nil {
+		return nil, nil
+	}
+	block, err := aes.NewCipher(c.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &lbCipher{block: block, plaintextLen: c.ServerIDLen + c.NonceLen}, nil
+}
+
+// firstOctet composes the first octet of the connection ID.
+// The lower 5 bits are either random, or encode the length of the connection ID.
+func (c *Config) firstOctet(random byte) byte {
+	if c.EncodeLength {
+		return c.ConfigID<<5 | byte(c.connIDLen()-1)
+	}
+	return c.ConfigID<<5 | random&0x1f
+}
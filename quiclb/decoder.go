@@ -0,0 +1,99 @@
+package quiclb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnroutable is returned by the Decoder for connection IDs that use the
+// reserved config rotation codepoint for unroutable connection IDs,
+// or use a config ID that the Decoder doesn't know.
+var ErrUnroutable = errors.New("quiclb: unroutable connection ID")
+
+type decoderConfig struct {
+	config Config
+	cipher *lbCipher
+}
+
+// A Decoder extracts the server ID from QUIC-LB connection IDs.
+// It is intended to be used by stateless load balancers.
+// It is safe for concurrent use.
+type Decoder struct {
+	configs [MaxConfigID + 1]*decoderConfig
+}
+
+// NewDecoder creates a new Decoder.
+// Multiple configurations can be used at the same time, as long as they use different config IDs.
+func NewDecoder(configs ...*Config) (*Decoder, error) {
+	d := &Decoder{}
+	for _, conf := range configs {
+		if err := conf.validate(); err != nil {
+			return nil, err
+		}
+		if d.configs[conf.ConfigID] != nil {
+			return nil, fmt.Errorf("quiclb: duplicate config ID: %d", conf.ConfigID)
+		}
+		c, err := conf.newCipher()
+		if err != nil {
+			return nil, err
+		}
+		d.configs[conf.ConfigID] = &decoderConfig{config: *conf, cipher: c}
+	}
+	return d, nil
+}
+
+// ConnectionIDLen returns the length of the connection ID, based on its first octet.
+// For Short Header packets, the length of the connection ID is not encoded in the packet,
+// so this is needed to parse the packet.
+// If the connection ID is unroutable, ErrUnroutable is returned.
+func (d *Decoder) ConnectionIDLen(firstOctet byte) (int, error) {
+	conf, err := d.getConfig(firstOctet)
+	if err != nil {
+		return 0, err
+	}
+	return conf.config.connIDLen(), nil
+}
+
+// ServerID decodes the server ID from a connection ID.
+// The connection ID can be followed by additional data, which is ignored.
+// This allows passing the remainder of a Short Header packet after the first byte.
+// If the connection ID is unroutable, ErrUnroutable is returned.
+func (d *Decoder) ServerID(connID []byte) ([]byte, error) {
+	if len(connID) == 0 {
+		return nil, ErrUnroutable
+	}
+	conf, err := d.getConfig(connID[0])
+	if err != nil {
+		return nil, err
+	}
+	l := conf.config.connIDLen()
+	if len(connID) < l {
+		return nil, fmt.Errorf("quiclb: connection ID too short: %d bytes, expected %d", len(connID), l)
+	}
+	if conf.config.EncodeLength && int(connID[0]&0x1f) != l-1 {
+		return nil, fmt.Errorf("quiclb: connection ID length mismatch: encoded %d, expected %d", connID[0]&0x1f+1, l)
+	}
+	if conf.cipher == nil {
+		serverID := make([]byte, conf.config.ServerIDLen)
+		copy(serverID, connID[1:])
+		return serverID, nil
+	}
+	b := make([]byte, l-1)
+	copy(b, connID[1:l])
+	if err := conf.cipher.Decrypt(b); err != nil {
+		return nil, err
+	}
+	return b[:conf.config.ServerIDLen], nil
+}
+
+func (d *Decoder) getConfig(firstOctet byte) (*decoderConfig, error) {
+	configID := firstOctet >> 5
+	if configID == unroutableConfigID {
+		return nil, ErrUnroutable
+	}
+	conf := d.configs[configID]
+	if conf == nil {
+		return nil, ErrUnroutable
+	}
+	return conf, nil
+}
@@ -0,0 +1,153 @@
+package quiclb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	for _, tc := range []struct {
+		name   string
+		config Config
+	}{
+		{name: "plaintext", config: Config{ConfigID: 0, ServerIDLen: 2, NonceLen: 6}},
+		{name: "single-pass", config: Config{ConfigID: 1, ServerIDLen: 4, NonceLen: 12, Key: key}},
+		{name: "four-pass, even length", config: Config{ConfigID: 2, ServerIDLen: 3, NonceLen: 5, Key: key}},
+		{name: "four-pass, odd length", config: Config{ConfigID: 3, ServerIDLen: 3, NonceLen: 4, Key: key}},
+		{name: "four-pass, maximum length", config: Config{ConfigID: 4, ServerIDLen: 15, NonceLen: 4, Key: key, EncodeLength: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			serverID := make([]byte, tc.config.ServerIDLen)
+			for i := range serverID {
+				serverID[i] = byte(0xf0 + i)
+			}
+			g, err := NewGenerator(&tc.config, serverID)
+			require.NoError(t, err)
+			d, err := NewDecoder(&tc.config)
+			require.NoError(t, err)
+
+			for i := 0; i < 100; i++ {
+				c, err := g.GenerateConnectionID()
+				require.NoError(t, err)
+				if tc.config.Key != nil {
+					require.NotEqual(t, serverID, c.Bytes()[1:1+tc.config.ServerIDLen])
+				}
+				l, err := d.ConnectionIDLen(c.Bytes()[0])
+				require.NoError(t, err)
+				require.Equal(t, c.Len(), l)
+				id, err := d.ServerID(c.Bytes())
+				require.NoError(t, err)
+				require.Equal(t, serverID, id)
+				// additional data is ignored
+				id, err = d.ServerID(append(c.Bytes(), []byte("foobar")...))
+				require.NoError(t, err)
+				require.Equal(t, serverID, id)
+			}
+		})
+	}
+}
+
+func TestDecoderTestVectors(t *testing.T) {
+	// These test vectors are taken from draft-ietf-quic-load-balancers, Appendix B.
+	key := decodeHex(t, testVectorKey)
+	for _, tc := range []struct {
+		name     string
+		config   Config
+		connID   string
+		serverID string
+	}{
+		{
+			name:     "plaintext",
+			config:   Config{ServerIDLen: 3, NonceLen: 4, EncodeLength: true},
+			connID:   "07c4605e4504cc4f",
+			serverID: "c4605e",
+		},
+		{
+			name:     "single-pass",
+			config:   Config{ServerIDLen: 8, NonceLen: 8, Key: key, EncodeLength: true},
+			connID:   "104dd2d05a7b0de9b2b9907afb5ecf8cc3",
+			serverID: "ed793a51d49b8f5f",
+		},
+		{
+			name:     "four-pass",
+			config:   Config{ServerIDLen: 3, NonceLen: 4, Key: key, EncodeLength: true},
+			connID:   "0720b1d07b359d3c",
+			serverID: "ed793a",
+		},
+		{
+			name:     "four-pass, config rotation",
+			config:   Config{ConfigID: 2, ServerIDLen: 10, NonceLen: 5, Key: key, EncodeLength: true},
+			connID:   "4fcc381bc74cb4fbad2823a3d1f8fed2",
+			serverID: "ed793a51d49b8f5fab65",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := NewDecoder(&tc.config)
+			require.NoError(t, err)
+			connID := decodeHex(t, tc.connID)
+			l, err := d.ConnectionIDLen(connID[0])
+			require.NoError(t, err)
+			require.Equal(t, len(connID), l)
+			id, err := d.ServerID(connID)
+			require.NoError(t, err)
+			require.Equal(t, decodeHex(t, tc.serverID), id)
+		})
+	}
+}
+
+func TestDecoderConfigRotation(t *testing.T) {
+	conf1 := &Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 6, Key: []byte("0123456789abcdef")}
+	conf2 := &Config{ConfigID: 2, ServerIDLen: 3, NonceLen: 8, Key: []byte("fedcba9876543210")}
+	g1, err := NewGenerator(conf1, []byte{1, 2})
+	require.NoError(t, err)
+	g2, err := NewGenerator(conf2, []byte{3, 4, 5})
+	require.NoError(t, err)
+
+	d, err := NewDecoder(conf1, conf2)
+	require.NoError(t, err)
+	c1, err := g1.GenerateConnectionID()
+	require.NoError(t, err)
+	c2, err := g2.GenerateConnectionID()
+	require.NoError(t, err)
+	id, err := d.ServerID(c1.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, id)
+	id, err = d.ServerID(c2.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, []byte{3, 4, 5}, id)
+
+	// a decoder that only knows the new config
+	d, err = NewDecoder(conf2)
+	require.NoError(t, err)
+	_, err = d.ServerID(c1.Bytes())
+	require.ErrorIs(t, err, ErrUnroutable)
+}
+
+func TestDecoderDuplicateConfigID(t *testing.T) {
+	_, err := NewDecoder(&Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 6}, &Config{ConfigID: 1, ServerIDLen: 3, NonceLen: 6})
+	require.EqualError(t, err, "quiclb: duplicate config ID: 1")
+}
+
+func TestDecoderUnroutable(t *testing.T) {
+	d, err := NewDecoder(&Config{ServerIDLen: 2, NonceLen: 6})
+	require.NoError(t, err)
+	c, err := GenerateUnroutableConnectionID(8)
+	require.NoError(t, err)
+	_, err = d.ServerID(c.Bytes())
+	require.ErrorIs(t, err, ErrUnroutable)
+	_, err = d.ConnectionIDLen(c.Bytes()[0])
+	require.ErrorIs(t, err, ErrUnroutable)
+	_, err = d.ServerID(nil)
+	require.ErrorIs(t, err, ErrUnroutable)
+}
+
+func TestDecoderInvalidConnectionIDs(t *testing.T) {
+	d, err := NewDecoder(&Config{ServerIDLen: 2, NonceLen: 6, EncodeLength: true})
+	require.NoError(t, err)
+	_, err = d.ServerID([]byte{7, 1, 2, 3})
+	require.EqualError(t, err, "quiclb: connection ID too short: 4 bytes, expected 9")
+	_, err = d.ServerID([]byte{5, 1, 2, 3, 4, 5, 6, 7, 8})
+	require.EqualError(t, err, "quiclb: connection ID length mismatch: encoded 6, expected 9")
+}
@@ -0,0 +1,109 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// A Generator generates QUIC-LB connection IDs encoding the server ID.
+// It can be used as the quic.Transport's ConnectionIDGenerator.
+// It is safe for concurrent use.
+type Generator struct {
+	config   Config
+	serverID []byte
+	cipher   *lbCipher
+
+	// The nonce consists of a random prefix (if the nonce is longer than 8 bytes),
+	// followed by a counter.
+	// Using a counter guarantees that nonces are not reused (until the counter wraps around),
+	// which is important for encrypted connection IDs, since connection IDs would otherwise
+	// be linkable.
+	noncePrefix []byte
+	counter     atomic.Uint64
+}
+
+// NewGenerator creates a new Generator for the server with the given server ID.
+// The length of the server ID must match the ServerIDLen of the configuration.
+func NewGenerator(config *Config, serverID []byte) (*Generator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if len(serverID) != config.ServerIDLen {
+		return nil, fmt.Errorf("quiclb: server ID has the wrong length: %d bytes, expected %d", len(serverID), config.ServerIDLen)
+	}
+	c, err := config.newCipher()
+	if err != nil {
+		return nil, err
+	}
+	g := &Generator{
+		config:   *config,
+		serverID: append([]byte(nil), serverID...),
+		cipher:   c,
+	}
+	if config.NonceLen > 8 {
+		g.noncePrefix = make([]byte, config.NonceLen-8)
+		if _, err := rand.Read(g.noncePrefix); err != nil {
+			return nil, err
+		}
+	}
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	g.counter.Store(binary.BigEndian.Uint64(b[:]))
+	return g, nil
+}
+
+// GenerateConnectionID generates a new connection ID.
+func (g *Generator) GenerateConnectionID() (protocol.ConnectionID, error) {
+	var random [1]byte
+	if !g.config.EncodeLength {
+		if _, err := rand.Read(random[:]); err != nil {
+			return protocol.ConnectionID{}, err
+		}
+	}
+	b := make([]byte, g.config.connIDLen())
+	b[0] = g.config.firstOctet(random[0])
+	copy(b[1:], g.serverID)
+	g.fillNonce(b[1+g.config.ServerIDLen:])
+	if g.cipher != nil {
+		if err := g.cipher.Encrypt(b[1:]); err != nil {
+			return protocol.ConnectionID{}, err
+		}
+	}
+	return protocol.ParseConnectionID(b), nil
+}
+
+func (g *Generator) fillNonce(nonce []byte) {
+	counter := g.counter.Add(1)
+	n := copy(nonce, g.noncePrefix)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], counter)
+	copy(nonce[n:], b[8-(len(nonce)-n):])
+}
+
+// ConnectionIDLen returns the length of the connection IDs.
+func (g *Generator) ConnectionIDLen() int {
+	return g.config.connIDLen()
+}
+
+// GenerateUnroutableConnectionID generates a connection ID that doesn't encode a server ID,
+// using the reserved config rotation codepoint 0b111.
+// Load balancers route packets with such connection IDs using a fallback algorithm,
+// e.g. based on the 4-tuple.
+func GenerateUnroutableConnectionID(l int) (protocol.ConnectionID, error) {
+	if l < 1 || l > 20 {
+		return protocol.ConnectionID{}, protocol.ErrInvalidConnectionIDLen
+	}
+	connID, err := protocol.GenerateConnectionID(l)
+	if err != nil {
+		return protocol.ConnectionID{}, err
+	}
+	b := connID.Bytes()
+	b[0] = unroutableConfigID<<5 | b[0]&0x1f
+	return protocol.ParseConnectionID(b), nil
+}
@@ -0,0 +1,79 @@
+package quiclb
+
+import (
+	"testing"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ quic.ConnectionIDGenerator = &Generator{}
+
+func TestGeneratorInvalidConfigs(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config Config
+		err    string
+	}{
+		{name: "config ID", config: Config{ConfigID: 7, ServerIDLen: 2, NonceLen: 6}, err: "quiclb: invalid config ID: 7"},
+		{name: "server ID too short", config: Config{ServerIDLen: 0, NonceLen: 6}, err: "quiclb: invalid server ID length: 0"},
+		{name: "server ID too long", config: Config{ServerIDLen: 16, NonceLen: 6}, err: "quiclb: invalid server ID length: 16"},
+		{name: "nonce too short", config: Config{ServerIDLen: 2, NonceLen: 3}, err: "quiclb: invalid nonce length: 3"},
+		{name: "nonce too long", config: Config{ServerIDLen: 2, NonceLen: 19}, err: "quiclb: invalid nonce length: 19"},
+		{name: "too long", config: Config{ServerIDLen: 10, NonceLen: 10}, err: "quiclb: server ID and nonce too long: 20 bytes"},
+		{name: "key length", config: Config{ServerIDLen: 2, NonceLen: 6, Key: make([]byte, 32)}, err: "quiclb: invalid key length: 32"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewGenerator(&tc.config, make([]byte, tc.config.ServerIDLen))
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestGeneratorServerIDLength(t *testing.T) {
+	_, err := NewGenerator(&Config{ServerIDLen: 3, NonceLen: 4}, []byte{1, 2})
+	require.EqualError(t, err, "quiclb: server ID has the wrong length: 2 bytes, expected 3")
+}
+
+func TestGeneratorPlaintext(t *testing.T) {
+	g, err := NewGenerator(&Config{ConfigID: 2, ServerIDLen: 3, NonceLen: 4, EncodeLength: true}, []byte{0xa, 0xb, 0xc})
+	require.NoError(t, err)
+	require.Equal(t, 8, g.ConnectionIDLen())
+	c1, err := g.GenerateConnectionID()
+	require.NoError(t, err)
+	c2, err := g.GenerateConnectionID()
+	require.NoError(t, err)
+	for _, c := range []quic.ConnectionID{c1, c2} {
+		require.Equal(t, 8, c.Len())
+		require.Equal(t, byte(2<<5|7), c.Bytes()[0])
+		require.Equal(t, []byte{0xa, 0xb, 0xc}, c.Bytes()[1:4])
+	}
+	require.NotEqual(t, c1, c2)
+}
+
+func TestGeneratorUniqueConnectionIDs(t *testing.T) {
+	for _, nonceLen := range []int{4, 12} {
+		g, err := NewGenerator(&Config{ServerIDLen: 4, NonceLen: nonceLen, Key: make([]byte, 16)}, []byte{1, 2, 3, 4})
+		require.NoError(t, err)
+		seen := make(map[quic.ConnectionID]struct{})
+		for i := 0; i < 1000; i++ {
+			c, err := g.GenerateConnectionID()
+			require.NoError(t, err)
+			require.Equal(t, 1+4+nonceLen, c.Len())
+			require.Zero(t, c.Bytes()[0]>>5)
+			_, ok := seen[c]
+			require.False(t, ok)
+			seen[c] = struct{}{}
+		}
+	}
+}
+
+func TestGenerateUnroutableConnectionID(t *testing.T) {
+	c, err := GenerateUnroutableConnectionID(8)
+	require.NoError(t, err)
+	require.Equal(t, 8, c.Len())
+	require.Equal(t, byte(7), c.Bytes()[0]>>5)
+	_, err = GenerateUnroutableConnectionID(21)
+	require.Error(t, err)
+}
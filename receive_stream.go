@@ -36,6 +36,12 @@ type receiveStream struct {
 	currentFrameDone   func()
 	readPosInFrame     int
 	currentFrameIsLast bool // is the currentFrame the last frame on this stream
+	readOffset         protocol.ByteCount
+
+	// Set when a RESET_STREAM_AT frame was received.
+	// The reset only takes effect once the application has read all data up to the reliableSize.
+	resetErr     *StreamError
+	reliableSize protocol.ByteCount
 
 	queuedStopSending   bool
 	queuedMaxStreamData bool
@@ -198,8 +204,13 @@ func (s *receiveStream) readImpl(p []byte) (bool, int, error) {
 			return queuedNewControlFrame, bytesRead, fmt.Errorf("BUG: readPosInFrame (%d) > frame.DataLen (%d) in stream.Read", s.readPosInFrame, len(s.currentFrame))
 		}
 
-		m := copy(p[bytesRead:], s.currentFrame[s.readPosInFrame:])
+		data := s.currentFrame[s.readPosInFrame:]
+		if s.resetErr != nil {
+			data = data[:min(protocol.ByteCount(len(data)), s.reliableSize-s.readOffset)]
+		}
+		m := copy(p[bytesRead:], data)
 		s.readPosInFrame += m
+		s.readOffset += protocol.ByteCount(m)
 		bytesRead += m
 
 		// when a RESET_STREAM was received, the flow controller was already
@@ -211,6 +222,13 @@ func (s *receiveStream) readImpl(p []byte) (bool, int, error) {
 			}
 		}
 
+		// After a RESET_STREAM_AT, the reset takes effect once all data up to the reliable size was read.
+		if s.resetErr != nil && s.readOffset >= s.reliableSize {
+			s.applyReset()
+			s.errorRead = true
+			return queuedNewControlFrame, bytesRead, s.cancelErr
+		}
+
 		if s.readPosInFrame >= len(s.currentFrame) && s.currentFrameIsLast {
 			s.currentFrame = nil
 			if s.currentFrameDone != nil {
@@ -322,15 +340,29 @@ func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame,
 	if s.cancelledRemotely {
 		return nil
 	}
+	// The reliable size can only be reduced by subsequent RESET_STREAM_AT (or RESET_STREAM) frames.
+	if s.resetErr != nil && frame.ReliableSize >= s.reliableSize {
+		return nil
+	}
+	s.resetErr = &StreamError{StreamID: s.streamID, ErrorCode: frame.ErrorCode, Remote: true}
+	s.reliableSize = frame.ReliableSize
+	// Once the data up to the reliable size has been read, the reset takes effect immediately.
+	// Otherwise, it takes effect when Read reaches the reliable size.
+	if s.readOffset >= s.reliableSize || s.cancelledLocally {
+		s.applyReset()
+	}
+	return nil
+}
+
+func (s *receiveStream) applyReset() {
 	s.flowController.Abandon()
 	// don't save the error if the RESET_STREAM frames was received after CancelRead was called
 	if s.cancelledLocally {
-		return nil
+		return
 	}
 	s.cancelledRemotely = true
-	s.cancelErr = &StreamError{StreamID: s.streamID, ErrorCode: frame.ErrorCode, Remote: true}
+	s.cancelErr = s.resetErr
 	s.signalRead()
-	return nil
 }
 
 func (s *receiveStream) getControlFrame(now time.Time) (_ ackhandler.Frame, ok, hasMore bool) {
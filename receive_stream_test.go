@@ -723,6 +723,105 @@ var _ = Describe("Receive Stream", func() {
 				Expect(streamErr.Remote).To(BeFalse())
 			})
 		})
+
+		Context("receiving RESET_STREAM_AT frames", func() {
+			rst := &wire.ResetStreamFrame{
+				StreamID:     streamID,
+				FinalSize:    42,
+				ErrorCode:    1234,
+				ReliableSize: 4,
+			}
+
+			It("delivers data up to the reliable size before returning the error", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false, gomock.Any())
+				Expect(str.handleStreamFrame(&wire.StreamFrame{
+					StreamID: streamID,
+					Data:     []byte("foobar"),
+				}, time.Now())).To(Succeed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true, gomock.Any())
+				Expect(str.handleResetStreamFrame(rst, time.Now())).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+				b := make([]byte, 2)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(b[:n]).To(Equal([]byte("fo")))
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2)),
+					mockFC.EXPECT().Abandon(),
+				)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b = make([]byte, 6)
+				n, err = strWithTimeout.Read(b)
+				Expect(err).To(Equal(&StreamError{StreamID: streamID, ErrorCode: 1234, Remote: true}))
+				Expect(b[:n]).To(Equal([]byte("ob")))
+			})
+
+			It("waits for data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true, gomock.Any())
+				Expect(str.handleResetStreamFrame(rst, time.Now())).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					b := make([]byte, 10)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+					Expect(b[:n]).To(Equal([]byte("foob")))
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false, gomock.Any())
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4)),
+					mockFC.EXPECT().Abandon(),
+				)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{
+					StreamID: streamID,
+					Data:     []byte("foobar"),
+				}, time.Now())).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("resets immediately if the data up to the reliable size was already read", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false, gomock.Any())
+				Expect(str.handleStreamFrame(&wire.StreamFrame{
+					StreamID: streamID,
+					Data:     []byte("foobar"),
+				}, time.Now())).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				n, err := strWithTimeout.Read(make([]byte, 6))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(6))
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true, gomock.Any())
+				mockFC.EXPECT().Abandon()
+				Expect(str.handleResetStreamFrame(rst, time.Now())).To(Succeed())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				_, err = strWithTimeout.Read([]byte{0})
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+			})
+
+			It("only allows reducing the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true, gomock.Any()).Times(3)
+				Expect(str.handleResetStreamFrame(rst, time.Now())).To(Succeed())
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					FinalSize:    42,
+					ErrorCode:    1234,
+					ReliableSize: 10,
+				}, time.Now())).To(Succeed())
+				Expect(str.reliableSize).To(BeEquivalentTo(4))
+				// a RESET_STREAM frame resets the stream immediately
+				mockFC.EXPECT().Abandon()
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					FinalSize: 42,
+					ErrorCode: 1234,
+				}, time.Now())).To(Succeed())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				_, err := strWithTimeout.Read([]byte{0})
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+			})
+		})
 	})
 
 	It("errors when a STREAM frame causes a flow control violation", func() {
@@ -2,6 +2,8 @@ package quic
 
 import (
 	"net"
+	"net/netip"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
@@ -9,12 +11,17 @@ import (
 
 // A sendConn allows sending using a simple Write() on a non-connected packet conn.
 type sendConn interface {
-	Write(b []byte, gsoSize uint16, ecn protocol.ECN) error
+	// Write sends a packet.
+	// If txTime is set, the kernel sends the packet at this time.
+	Write(b []byte, gsoSize uint16, ecn protocol.ECN, txTime time.Time) error
 	Close() error
 	LocalAddr() net.Addr
 	RemoteAddr() net.Addr
 
 	capabilities() connCapabilities
+	// withRemoteAddr returns a sendConn that sends packets to a different remote address,
+	// using the same underlying connection.
+	withRemoteAddr(remote net.Addr, info packetInfo) sendConn
 }
 
 type sconn struct {
@@ -25,7 +32,9 @@ type sconn struct {
 
 	logger utils.Logger
 
-	packetInfoOOB []byte
+	// the source address, and the same information encoded as control messages
+	packetInfoAddr netip.Addr
+	packetInfoOOB  []byte
 	// If GSO enabled, and we receive a GSO error for this remote address, GSO is disabled.
 	gotGSOError bool
 	// Used to catch the error sometimes returned by the first sendmsg call on Linux,
@@ -40,26 +49,27 @@ func newSendConn(c rawConn, remote net.Addr, info packetInfo, logger utils.Logge
 	if info.addr.IsValid() {
 		if udpAddr, ok := localAddr.(*net.UDPAddr); ok {
 			addrCopy := *udpAddr
-			addrCopy.IP = info.addr.AsSlice()
+			addrCopy.IP = info.addr.Unmap().AsSlice()
 			localAddr = &addrCopy
 		}
 	}
 
 	oob := info.OOB()
-	// increase oob slice capacity, so we can add the UDP_SEGMENT and ECN control messages without allocating
+	// increase oob slice capacity, so we can add the UDP_SEGMENT, ECN and SCM_TXTIME control messages without allocating
 	l := len(oob)
-	oob = append(oob, make([]byte, 64)...)[:l]
+	oob = append(oob, make([]byte, 96)...)[:l]
 	return &sconn{
-		rawConn:       c,
-		localAddr:     localAddr,
-		remoteAddr:    remote,
-		packetInfoOOB: oob,
-		logger:        logger,
+		rawConn:        c,
+		localAddr:      localAddr,
+		remoteAddr:     remote,
+		packetInfoAddr: info.addr,
+		packetInfoOOB:  oob,
+		logger:         logger,
 	}
 }
 
-func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
-	err := c.writePacket(p, c.remoteAddr, c.packetInfoOOB, gsoSize, ecn)
+func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN, txTime time.Time) error {
+	err := c.writePacket(p, c.remoteAddr, gsoSize, ecn, txTime)
 	if err != nil && isGSOError(err) {
 		// disable GSO for future calls
 		c.gotGSOError = true
@@ -72,7 +82,7 @@ func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
 			if l > int(gsoSize) {
 				l = int(gsoSize)
 			}
-			if err := c.writePacket(p[:l], c.remoteAddr, c.packetInfoOOB, 0, ecn); err != nil {
+			if err := c.writePacket(p[:l], c.remoteAddr, 0, ecn, txTime); err != nil {
 				return err
 			}
 			p = p[l:]
@@ -82,10 +92,10 @@ func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
 	return err
 }
 
-func (c *sconn) writePacket(p []byte, addr net.Addr, oob []byte, gsoSize uint16, ecn protocol.ECN) error {
-	_, err := c.WritePacket(p, addr, oob, gsoSize, ecn)
+func (c *sconn) writePacket(p []byte, addr net.Addr, gsoSize uint16, ecn protocol.ECN, txTime time.Time) error {
+	_, err := c.WritePacket(p, addr, c.packetInfoAddr, c.packetInfoOOB, gsoSize, ecn, txTime)
 	if err != nil && !c.wroteFirstPacket && isPermissionError(err) {
-		_, err = c.WritePacket(p, addr, oob, gsoSize, ecn)
+		_, err = c.WritePacket(p, addr, c.packetInfoAddr, c.packetInfoOOB, gsoSize, ecn, txTime)
 	}
 	c.wroteFirstPacket = true
 	return err
@@ -99,5 +109,9 @@ func (c *sconn) capabilities() connCapabilities {
 	return capabilities
 }
 
+func (c *sconn) withRemoteAddr(remote net.Addr, info packetInfo) sendConn {
+	return newSendConn(c.rawConn, remote, info, c.logger)
+}
+
 func (c *sconn) RemoteAddr() net.Addr { return c.remoteAddr }
 func (c *sconn) LocalAddr() net.Addr  { return c.localAddr }
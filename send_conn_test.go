@@ -4,6 +4,7 @@ import (
 	"net"
 	"net/netip"
 	"runtime"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
@@ -46,8 +47,8 @@ var _ = Describe("Connection (for sending packets)", func() {
 			pi := packetInfo{addr: netip.IPv6Loopback()}
 			Expect(pi.OOB()).ToNot(BeEmpty())
 			c := newSendConn(rawConn, remoteAddr, pi, utils.DefaultLogger)
-			rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, pi.OOB(), uint16(0), protocol.ECT1)
-			Expect(c.Write([]byte("foobar"), 0, protocol.ECT1)).To(Succeed())
+			rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, pi.addr, pi.OOB(), uint16(0), protocol.ECT1, time.Time{})
+			Expect(c.Write([]byte("foobar"), 0, protocol.ECT1, time.Time{})).To(Succeed())
 		})
 	}
 
@@ -56,8 +57,8 @@ var _ = Describe("Connection (for sending packets)", func() {
 		rawConn.EXPECT().LocalAddr()
 		rawConn.EXPECT().capabilities().AnyTimes()
 		c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
-		rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(3), protocol.ECNCE)
-		Expect(c.Write([]byte("foobar"), 3, protocol.ECNCE)).To(Succeed())
+		rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), uint16(3), protocol.ECNCE, time.Time{})
+		Expect(c.Write([]byte("foobar"), 3, protocol.ECNCE, time.Time{})).To(Succeed())
 	})
 
 	if platformSupportsGSO {
@@ -68,11 +69,11 @@ var _ = Describe("Connection (for sending packets)", func() {
 			c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
 			Expect(c.capabilities().GSO).To(BeTrue())
 			gomock.InOrder(
-				rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(4), protocol.ECNCE).Return(0, errGSO),
-				rawConn.EXPECT().WritePacket([]byte("foob"), remoteAddr, gomock.Any(), uint16(0), protocol.ECNCE).Return(4, nil),
-				rawConn.EXPECT().WritePacket([]byte("ar"), remoteAddr, gomock.Any(), uint16(0), protocol.ECNCE).Return(2, nil),
+				rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), uint16(4), protocol.ECNCE, time.Time{}).Return(0, errGSO),
+				rawConn.EXPECT().WritePacket([]byte("foob"), remoteAddr, gomock.Any(), gomock.Any(), uint16(0), protocol.ECNCE, time.Time{}).Return(4, nil),
+				rawConn.EXPECT().WritePacket([]byte("ar"), remoteAddr, gomock.Any(), gomock.Any(), uint16(0), protocol.ECNCE, time.Time{}).Return(2, nil),
 			)
-			Expect(c.Write([]byte("foobar"), 4, protocol.ECNCE)).To(Succeed())
+			Expect(c.Write([]byte("foobar"), 4, protocol.ECNCE, time.Time{})).To(Succeed())
 			Expect(c.capabilities().GSO).To(BeFalse())
 		})
 	}
@@ -84,10 +85,10 @@ var _ = Describe("Connection (for sending packets)", func() {
 			rawConn.EXPECT().capabilities().AnyTimes()
 			c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
 			gomock.InOrder(
-				rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), protocol.ECNCE).Return(0, errNotPermitted),
-				rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(0), protocol.ECNCE).Return(6, nil),
+				rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), gomock.Any(), protocol.ECNCE, time.Time{}).Return(0, errNotPermitted),
+				rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), uint16(0), protocol.ECNCE, time.Time{}).Return(6, nil),
 			)
-			Expect(c.Write([]byte("foobar"), 0, protocol.ECNCE)).To(Succeed())
+			Expect(c.Write([]byte("foobar"), 0, protocol.ECNCE, time.Time{})).To(Succeed())
 		})
 
 		It("fails if the sendmsg calls fail multiple times", func() {
@@ -95,8 +96,8 @@ var _ = Describe("Connection (for sending packets)", func() {
 			rawConn.EXPECT().LocalAddr()
 			rawConn.EXPECT().capabilities().AnyTimes()
 			c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
-			rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), protocol.ECNCE).Return(0, errNotPermitted).Times(2)
-			Expect(c.Write([]byte("foobar"), 0, protocol.ECNCE)).To(MatchError(errNotPermitted))
+			rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), gomock.Any(), protocol.ECNCE, time.Time{}).Return(0, errNotPermitted).Times(2)
+			Expect(c.Write([]byte("foobar"), 0, protocol.ECNCE, time.Time{})).To(MatchError(errNotPermitted))
 		})
 	}
 })
@@ -1,9 +1,13 @@
 package quic
 
-import "github.com/quic-go/quic-go/internal/protocol"
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
 
 type sender interface {
-	Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN)
+	Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN, txTime time.Time)
 	Run() error
 	WouldBlock() bool
 	Available() <-chan struct{}
@@ -14,6 +18,7 @@ type queueEntry struct {
 	buf     *packetBuffer
 	gsoSize uint16
 	ecn     protocol.ECN
+	txTime  time.Time
 }
 
 type sendQueue struct {
@@ -41,9 +46,10 @@ func newSendQueue(conn sendConn) sender {
 // Send sends out a packet. It's guaranteed to not block.
 // Callers need to make sure that there's actually space in the send queue by calling WouldBlock.
 // Otherwise Send will panic.
-func (h *sendQueue) Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN) {
+// If txTime is set, the kernel sends the packet at this time (see connCapabilities.TXTime).
+func (h *sendQueue) Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN, txTime time.Time) {
 	select {
-	case h.queue <- queueEntry{buf: p, gsoSize: gsoSize, ecn: ecn}:
+	case h.queue <- queueEntry{buf: p, gsoSize: gsoSize, ecn: ecn, txTime: txTime}:
 		// clear available channel if we've reached capacity
 		if len(h.queue) == sendQueueCapacity {
 			select {
@@ -78,7 +84,7 @@ func (h *sendQueue) Run() error {
 			// make sure that all queued packets are actually sent out
 			shouldClose = true
 		case e := <-h.queue:
-			if err := h.conn.Write(e.buf.Data, e.gsoSize, e.ecn); err != nil {
+			if err := h.conn.Write(e.buf.Data, e.gsoSize, e.ecn, e.txTime); err != nil {
 				// This additional check enables:
 				// 1. Checking for "datagram too large" message from the kernel, as such,
 				// 2. Path MTU discovery,and
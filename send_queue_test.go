@@ -2,6 +2,7 @@ package quic
 
 import (
 	"errors"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 
@@ -28,10 +29,28 @@ var _ = Describe("Send Queue", func() {
 
 	It("sends a packet", func() {
 		p := getPacket([]byte("foobar"))
-		q.Send(p, 10, protocol.ECT1) // make sure the packet size is passed through to the conn
+		q.Send(p, 10, protocol.ECT1, time.Time{}) // make sure the packet size is passed through to the conn
 
 		written := make(chan struct{})
-		c.EXPECT().Write([]byte("foobar"), uint16(10), protocol.ECT1).Do(func([]byte, uint16, protocol.ECN) error { close(written); return nil })
+		c.EXPECT().Write([]byte("foobar"), uint16(10), protocol.ECT1, time.Time{}).Do(func([]byte, uint16, protocol.ECN, time.Time) error { close(written); return nil })
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			q.Run()
+			close(done)
+		}()
+
+		Eventually(written).Should(BeClosed())
+		q.Close()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("passes the transmission time through to the conn", func() {
+		txTime := time.Now().Add(time.Millisecond)
+		q.Send(getPacket([]byte("foobar")), 0, protocol.ECNNon, txTime)
+
+		written := make(chan struct{})
+		c.EXPECT().Write([]byte("foobar"), uint16(0), protocol.ECNNon, txTime).Do(func([]byte, uint16, protocol.ECN, time.Time) error { close(written); return nil })
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -47,19 +66,19 @@ var _ = Describe("Send Queue", func() {
 	It("panics when Send() is called although there's no space in the queue", func() {
 		for i := 0; i < sendQueueCapacity; i++ {
 			Expect(q.WouldBlock()).To(BeFalse())
-			q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon)
+			q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon, time.Time{})
 		}
 		Expect(q.WouldBlock()).To(BeTrue())
-		Expect(func() { q.Send(getPacket([]byte("raboof")), 6, protocol.ECNNon) }).To(Panic())
+		Expect(func() { q.Send(getPacket([]byte("raboof")), 6, protocol.ECNNon, time.Time{}) }).To(Panic())
 	})
 
 	It("signals when sending is possible again", func() {
 		Expect(q.WouldBlock()).To(BeFalse())
-		q.Send(getPacket([]byte("foobar1")), 6, protocol.ECNNon)
+		q.Send(getPacket([]byte("foobar1")), 6, protocol.ECNNon, time.Time{})
 		Consistently(q.Available()).ShouldNot(Receive())
 
 		// now start sending out packets. This should free up queue space.
-		c.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon).MinTimes(1).MaxTimes(2)
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), protocol.ECNNon, time.Time{}).MinTimes(1).MaxTimes(2)
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -69,7 +88,7 @@ var _ = Describe("Send Queue", func() {
 
 		Eventually(q.Available()).Should(Receive())
 		Expect(q.WouldBlock()).To(BeFalse())
-		Expect(func() { q.Send(getPacket([]byte("foobar2")), 7, protocol.ECNNon) }).ToNot(Panic())
+		Expect(func() { q.Send(getPacket([]byte("foobar2")), 7, protocol.ECNNon, time.Time{}) }).ToNot(Panic())
 
 		q.Close()
 		Eventually(done).Should(BeClosed())
@@ -79,7 +98,7 @@ var _ = Describe("Send Queue", func() {
 		write := make(chan struct{}, 1)
 		written := make(chan struct{}, 100)
 		// now start sending out packets. This should free up queue space.
-		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func([]byte, uint16, protocol.ECN) error {
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func([]byte, uint16, protocol.ECN, time.Time) error {
 			written <- struct{}{}
 			<-write
 			return nil
@@ -94,19 +113,19 @@ var _ = Describe("Send Queue", func() {
 			close(done)
 		}()
 
-		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon)
+		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon, time.Time{})
 		<-written
 
 		// now fill up the send queue
 		for i := 0; i < sendQueueCapacity; i++ {
 			Expect(q.WouldBlock()).To(BeFalse())
-			q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon)
+			q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon, time.Time{})
 		}
 		// One more packet is queued when it's picked up by Run and written to the connection.
 		// In this test, it's blocked on write channel in the mocked Write call.
 		<-written
 		Eventually(q.WouldBlock()).Should(BeFalse())
-		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon)
+		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon, time.Time{})
 
 		Expect(q.WouldBlock()).To(BeTrue())
 		Consistently(q.Available()).ShouldNot(Receive())
@@ -132,15 +151,15 @@ var _ = Describe("Send Queue", func() {
 
 		// the run loop exits if there is a write error
 		testErr := errors.New("test error")
-		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Return(testErr)
-		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon)
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(testErr)
+		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon, time.Time{})
 		Eventually(done).Should(BeClosed())
 
 		sent := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
-			q.Send(getPacket([]byte("raboof")), 6, protocol.ECNNon)
-			q.Send(getPacket([]byte("quux")), 4, protocol.ECNNon)
+			q.Send(getPacket([]byte("raboof")), 6, protocol.ECNNon, time.Time{})
+			q.Send(getPacket([]byte("quux")), 4, protocol.ECNNon, time.Time{})
 			close(sent)
 		}()
 
@@ -149,7 +168,7 @@ var _ = Describe("Send Queue", func() {
 
 	It("blocks Close() until the packet has been sent out", func() {
 		written := make(chan []byte)
-		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(p []byte, _ uint16, _ protocol.ECN) error { written <- p; return nil })
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(p []byte, _ uint16, _ protocol.ECN, _ time.Time) error { written <- p; return nil })
 		done := make(chan struct{})
 		go func() {
 			defer GinkgoRecover()
@@ -157,7 +176,7 @@ var _ = Describe("Send Queue", func() {
 			close(done)
 		}()
 
-		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon)
+		q.Send(getPacket([]byte("foobar")), 6, protocol.ECNNon, time.Time{})
 
 		closed := make(chan struct{})
 		go func() {
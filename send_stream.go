@@ -21,6 +21,7 @@ type sendStreamI interface {
 	popStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (frame ackhandler.StreamFrame, ok, hasMore bool)
 	closeForShutdown(error)
 	updateSendWindow(protocol.ByteCount)
+	isCompleted() bool
 }
 
 type sendStream struct {
@@ -43,6 +44,11 @@ type sendStream struct {
 	queuedResetStreamFrame bool
 	queuedBlockedFrame     bool
 
+	// Set when the stream was reset using CancelWriteAt.
+	// Data below the reliableSize is still delivered reliably.
+	reliableReset bool
+	reliableSize  protocol.ByteCount
+
 	finishedWriting bool // set once Close() is called
 	finSent         bool // set when a STREAM_FRAME with FIN bit has been sent
 	// Set when the application knows about the cancellation.
@@ -138,7 +144,7 @@ func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error)
 		// This allows us to return Write() when all data but x bytes have been sent out.
 		// When the user now calls Close(), this is much more likely to happen before we popped that last STREAM frame,
 		// allowing us to set the FIN bit on that frame (instead of sending an empty STREAM frame with FIN).
-		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
+		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 && s.cancelWriteErr == nil {
 			if s.nextFrame == nil {
 				f := wire.GetStreamFrame()
 				f.Offset = s.writeOffset
@@ -238,7 +244,13 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Vers
 }
 
 func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (_ *wire.StreamFrame, hasMoreData, queuedControlFrame bool) {
-	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+	if s.closeForShutdownErr != nil {
+		return nil, false, false
+	}
+	if s.cancelWriteErr != nil {
+		if s.reliableReset {
+			return s.popReliableStreamFrame(maxBytes, v)
+		}
 		return nil, false, false
 	}
 
@@ -288,6 +300,56 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 	return f, hasMoreData, false
 }
 
+// popReliableStreamFrame pops STREAM frames after the stream was reset using RESET_STREAM_AT.
+// Only data below the reliable size is sent (or retransmitted).
+// Once all of this data has been sent, the RESET_STREAM_AT frame is queued.
+func (s *sendStream) popReliableStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (_ *wire.StreamFrame, hasMoreData, queuedControlFrame bool) {
+	if len(s.retransmissionQueue) > 0 {
+		f, hasMoreRetransmissions := s.maybeGetRetransmission(maxBytes, v)
+		if f == nil {
+			return nil, true, false
+		}
+		return f, hasMoreRetransmissions || s.writeOffset < s.reliableSize, false
+	}
+	if s.writeOffset >= s.reliableSize {
+		return nil, false, false
+	}
+
+	sendWindow := s.flowController.SendWindowSize()
+	if sendWindow == 0 {
+		if s.flowController.IsNewlyBlocked() {
+			s.queuedBlockedFrame = true
+			return nil, false, true
+		}
+		return nil, true, false
+	}
+	// All data below the reliable size was already copied to the nextFrame, see cancelWriteImpl.
+	f, _ := s.popNewStreamFrame(maxBytes, min(sendWindow, s.reliableSize-s.writeOffset), v)
+	if f == nil {
+		return nil, true, false
+	}
+	s.writeOffset += f.DataLen()
+	s.flowController.AddBytesSent(f.DataLen())
+	if s.writeOffset < s.reliableSize {
+		return f, true, false
+	}
+	s.queuedResetStreamFrame = true
+	return f, false, true
+}
+
+// trimToReliableSize removes the data beyond the reliable size from a STREAM frame.
+// It returns false if the frame doesn't contain any data below the reliable size.
+func (s *sendStream) trimToReliableSize(f *wire.StreamFrame) bool {
+	if f.Offset >= s.reliableSize {
+		return false
+	}
+	if f.Offset+f.DataLen() > s.reliableSize {
+		f.Data = f.Data[:s.reliableSize-f.Offset]
+	}
+	f.Fin = false
+	return true
+}
+
 func (s *sendStream) popNewStreamFrame(maxBytes, sendWindow protocol.ByteCount, v protocol.Version) (*wire.StreamFrame, bool) {
 	if s.nextFrame != nil {
 		nextFrame := s.nextFrame
@@ -374,6 +436,10 @@ func (s *sendStream) isNewlyCompleted() bool {
 	if s.numOutstandingFrames > 0 || len(s.retransmissionQueue) > 0 || s.queuedResetStreamFrame {
 		return false
 	}
+	// After a RESET_STREAM_AT, the data up to the reliable size still needs to be sent.
+	if s.reliableReset && s.writeOffset < s.reliableSize {
+		return false
+	}
 	// The stream is completed if we sent the FIN.
 	if s.finSent {
 		s.completed = true
@@ -418,10 +484,17 @@ func (s *sendStream) Close() error {
 }
 
 func (s *sendStream) CancelWrite(errorCode StreamErrorCode) {
-	s.cancelWriteImpl(errorCode, false)
+	s.cancelWriteImpl(errorCode, 0, false)
 }
 
-func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool) {
+func (s *sendStream) CancelWriteAt(errorCode StreamErrorCode, reliableSize int64) {
+	if reliableSize < 0 || !s.sender.supportsResetStreamAt() {
+		reliableSize = 0
+	}
+	s.cancelWriteImpl(errorCode, protocol.ByteCount(reliableSize), false)
+}
+
+func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, reliableSize protocol.ByteCount, remote bool) {
 	s.mutex.Lock()
 	if s.closeForShutdownErr != nil {
 		s.mutex.Unlock()
@@ -430,8 +503,16 @@ func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool
 	if !remote {
 		s.cancellationFlagged = true
 		if s.cancelWriteErr != nil {
+			// The reliable size of a RESET_STREAM_AT can be reduced, but never increased.
+			var queuedResetStreamFrame bool
+			if s.reliableReset && reliableSize < s.reliableSize {
+				queuedResetStreamFrame = s.reduceReliableSize(reliableSize)
+			}
 			completed := s.isNewlyCompleted()
 			s.mutex.Unlock()
+			if queuedResetStreamFrame {
+				s.sender.onHasStreamControlFrame(s.streamID, s)
+			}
 			// The user has called CancelWrite. If the previous cancellation was
 			// because of a STOP_SENDING, we don't need to flag the error to the
 			// user anymore.
@@ -447,13 +528,60 @@ func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool
 	}
 	s.cancelWriteErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: remote}
 	s.ctxCancel(s.cancelWriteErr)
-	s.numOutstandingFrames = 0
-	s.retransmissionQueue = nil
-	s.queuedResetStreamFrame = true
+	var hasStreamData bool
+	if reliableSize > 0 {
+		// Data that is still waiting in the nextFrame was already accepted by Write,
+		// while the data in dataForWriting will be returned as not written.
+		committed := s.writeOffset
+		if s.nextFrame != nil {
+			committed += s.nextFrame.DataLen()
+		}
+		s.reliableReset = true
+		s.reliableSize = min(reliableSize, committed)
+		s.trimRetransmissionQueue()
+		s.queuedResetStreamFrame = s.writeOffset >= s.reliableSize
+		hasStreamData = len(s.retransmissionQueue) > 0 || s.writeOffset < s.reliableSize
+	} else {
+		s.numOutstandingFrames = 0
+		s.retransmissionQueue = nil
+		s.queuedResetStreamFrame = true
+	}
+	queuedResetStreamFrame := s.queuedResetStreamFrame
 	s.mutex.Unlock()
 
 	s.signalWrite()
-	s.sender.onHasStreamControlFrame(s.streamID, s)
+	if hasStreamData {
+		s.sender.onHasStreamData(s.streamID, s)
+	}
+	if queuedResetStreamFrame {
+		s.sender.onHasStreamControlFrame(s.streamID, s)
+	}
+}
+
+// reduceReliableSize reduces the reliable size after the stream was already reset using RESET_STREAM_AT.
+// It returns true if a new RESET_STREAM_AT (or RESET_STREAM) frame was queued.
+func (s *sendStream) reduceReliableSize(reliableSize protocol.ByteCount) bool {
+	s.reliableSize = reliableSize
+	s.trimRetransmissionQueue()
+	if s.writeOffset < s.reliableSize {
+		return false
+	}
+	s.queuedResetStreamFrame = true
+	return true
+}
+
+func (s *sendStream) trimRetransmissionQueue() {
+	var j int
+	for _, f := range s.retransmissionQueue {
+		if !s.trimToReliableSize(f) {
+			f.PutBack()
+			continue
+		}
+		s.retransmissionQueue[j] = f
+		j++
+	}
+	clear(s.retransmissionQueue[j:])
+	s.retransmissionQueue = s.retransmissionQueue[:j]
 }
 
 func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
@@ -470,7 +598,7 @@ func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 }
 
 func (s *sendStream) handleStopSendingFrame(frame *wire.StopSendingFrame) {
-	s.cancelWriteImpl(frame.ErrorCode, true)
+	s.cancelWriteImpl(frame.ErrorCode, 0, true)
 }
 
 func (s *sendStream) getControlFrame(time.Time) (_ ackhandler.Frame, ok, hasMore bool) {
@@ -491,9 +619,10 @@ func (s *sendStream) getControlFrame(time.Time) (_ ackhandler.Frame, ok, hasMore
 	s.numOutstandingFrames++
 	return ackhandler.Frame{
 		Frame: &wire.ResetStreamFrame{
-			StreamID:  s.streamID,
-			FinalSize: s.writeOffset,
-			ErrorCode: s.cancelWriteErr.ErrorCode,
+			StreamID:     s.streamID,
+			FinalSize:    s.writeOffset,
+			ErrorCode:    s.cancelWriteErr.ErrorCode,
+			ReliableSize: s.reliableSize,
 		},
 		Handler: (*sendStreamResetStreamHandler)(s),
 	}, true, false
@@ -511,6 +640,18 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetPriority(prio StreamPriority) {
+	s.sender.onStreamPriorityChanged(s.streamID, prio, s) // must be called without holding the mutex
+}
+
+// isCompleted says if the stream has been reported to the streamSender as completed.
+// Once the stream has completed, there's no more data to schedule.
+func (s *sendStream) isCompleted() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.completed
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
@@ -537,7 +678,7 @@ func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	sf.PutBack()
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && !s.reliableReset {
 		s.mutex.Unlock()
 		return
 	}
@@ -556,16 +697,26 @@ func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 func (s *sendStreamAckHandler) OnLost(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && !s.reliableReset {
 		s.mutex.Unlock()
 		return
 	}
-	sf.DataLenPresent = true
-	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	// After a RESET_STREAM_AT, only data below the reliable size is retransmitted.
+	if s.cancelWriteErr != nil && !(*sendStream)(s).trimToReliableSize(sf) {
+		sf.PutBack()
+		completed := (*sendStream)(s).isNewlyCompleted()
+		s.mutex.Unlock()
+		if completed {
+			s.sender.onStreamCompleted(s.streamID)
+		}
+		return
+	}
+	sf.DataLenPresent = true
+	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.mutex.Unlock()
 
 	s.sender.onHasStreamData(s.streamID, (*sendStream)(s))
@@ -74,6 +74,20 @@ var _ = Describe("Send Stream", func() {
 		Expect(str.StreamID()).To(Equal(protocol.StreamID(1337)))
 	})
 
+	It("sets the priority", func() {
+		prio := StreamPriority{Urgency: 1, Incremental: true}
+		mockSender.EXPECT().onStreamPriorityChanged(streamID, prio, str)
+		str.SetPriority(prio)
+	})
+
+	It("says if it completed", func() {
+		Expect(str.isCompleted()).To(BeFalse())
+		str.mutex.Lock()
+		str.completed = true
+		str.mutex.Unlock()
+		Expect(str.isCompleted()).To(BeTrue())
+	})
+
 	Context("writing", func() {
 		It("writes and gets all data at once", func() {
 			done := make(chan struct{})
@@ -991,6 +1005,146 @@ var _ = Describe("Send Stream", func() {
 				Expect(err).To(Equal(closeErr))
 			})
 		})
+
+		Context("canceling writing at a reliable size", func() {
+			It("sends the data up to the reliable size before queueing the RESET_STREAM_AT frame", func() {
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(40))
+				frame, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+40, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame.Frame.Data).To(Equal(getData(40)))
+
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				str.CancelWriteAt(1234, 60)
+				_, ok, _ = str.getControlFrame(time.Now())
+				Expect(ok).To(BeFalse())
+				_, err = strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(20))
+				mockSender.EXPECT().onHasStreamControlFrame(streamID, str)
+				frame, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMore).To(BeFalse())
+				Expect(frame.Frame.Offset).To(Equal(protocol.ByteCount(40)))
+				Expect(frame.Frame.Data).To(Equal(getDataAtOffset(40, 20)))
+				Expect(frame.Frame.Fin).To(BeFalse())
+				cf, ok, _ := str.getControlFrame(time.Now())
+				Expect(ok).To(BeTrue())
+				Expect(cf.Frame).To(Equal(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					FinalSize:    60,
+					ErrorCode:    1234,
+					ReliableSize: 60,
+				}))
+			})
+
+			It("retransmits lost data up to the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(50)).Times(2)
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+50, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				frame2, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamControlFrame(streamID, str)
+				str.CancelWriteAt(1234, 70)
+				cf, ok, _ := str.getControlFrame(time.Now())
+				Expect(ok).To(BeTrue())
+				Expect(cf.Frame).To(Equal(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					FinalSize:    100,
+					ErrorCode:    1234,
+					ReliableSize: 70,
+				}))
+
+				// only the data up to the reliable size is retransmitted
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				frame2.Handler.OnLost(frame2.Frame)
+				retransmission, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMore).To(BeFalse())
+				Expect(retransmission.Frame.Offset).To(Equal(protocol.ByteCount(50)))
+				Expect(retransmission.Frame.Data).To(Equal(getDataAtOffset(50, 20)))
+
+				frame1.Handler.OnAcked(frame1.Frame)
+				cf.Handler.OnAcked(cf.Frame)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				retransmission.Handler.OnAcked(retransmission.Frame)
+			})
+
+			It("discards lost data beyond the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(100))
+				frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamControlFrame(streamID, str)
+				str.CancelWriteAt(1234, 10)
+				cf, ok, _ := str.getControlFrame(time.Now())
+				Expect(ok).To(BeTrue())
+				cf.Handler.OnAcked(cf.Frame)
+				// the lost frame is trimmed to the reliable size
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				frame.Handler.OnLost(frame.Frame)
+				retransmission, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(retransmission.Frame.Data).To(Equal(getData(10)))
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				retransmission.Handler.OnAcked(retransmission.Frame)
+			})
+
+			It("falls back to RESET_STREAM if the peer doesn't support RESET_STREAM_AT", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(false)
+				mockSender.EXPECT().onHasStreamControlFrame(streamID, str)
+				str.writeOffset = 1000
+				str.CancelWriteAt(1234, 500)
+				cf, ok, _ := str.getControlFrame(time.Now())
+				Expect(ok).To(BeTrue())
+				Expect(cf.Frame).To(Equal(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					FinalSize: 1000,
+					ErrorCode: 1234,
+				}))
+			})
+
+			It("reduces the reliable size, but doesn't increase it", func() {
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true).Times(3)
+				mockSender.EXPECT().onHasStreamData(streamID, str)
+				str.CancelWriteAt(1234, 80)
+				str.CancelWriteAt(1234, 90)
+				Expect(str.reliableSize).To(Equal(protocol.ByteCount(80)))
+
+				mockSender.EXPECT().onHasStreamControlFrame(streamID, str)
+				str.CancelWriteAt(1234, 0)
+				cf, ok, _ := str.getControlFrame(time.Now())
+				Expect(ok).To(BeTrue())
+				Expect(cf.Frame).To(Equal(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					FinalSize: 0,
+					ErrorCode: 1234,
+				}))
+				_, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				Expect(hasMore).To(BeFalse())
+			})
+		})
 	})
 
 	Context("retransmissions", func() {
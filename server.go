@@ -36,6 +36,7 @@ type packetHandlerManager interface {
 	Get(protocol.ConnectionID) (packetHandler, bool)
 	GetByResetToken(protocol.StatelessResetToken) (packetHandler, bool)
 	AddWithConnID(destConnID, newConnID protocol.ConnectionID, h packetHandler) bool
+	Empty() <-chan struct{}
 	Close(error)
 	connRunner
 }
@@ -69,8 +70,8 @@ type baseServer struct {
 
 	conn rawConn
 
-	tokenGenerator *handshake.TokenGenerator
-	maxTokenAge    time.Duration
+	tokenProvider TokenProvider
+	maxTokenAge   time.Duration
 
 	connIDGenerator ConnectionIDGenerator
 	connHandler     packetHandlerManager
@@ -98,7 +99,8 @@ type baseServer struct {
 		protocol.StatelessResetToken,
 		*Config,
 		*tls.Config,
-		*handshake.TokenGenerator,
+		TokenProvider,
+		*PreferredAddress,
 		bool, /* client address validated by an address validation token */
 		*logging.ConnectionTracer,
 		utils.Logger,
@@ -117,6 +119,7 @@ type baseServer struct {
 	handshakingCount        sync.WaitGroup
 
 	verifySourceAddress func(net.Addr) bool
+	preferredAddress    *PreferredAddress
 
 	connQueue chan quicConn
 
@@ -129,10 +132,14 @@ type baseServer struct {
 // It returns connections once the handshake has completed.
 type Listener struct {
 	baseServer *baseServer
+	group      *serverGroup // set when listening on multiple sockets
 }
 
 // Accept returns new connections. It should be called in a loop.
 func (l *Listener) Accept(ctx context.Context) (Connection, error) {
+	if l.group != nil {
+		return l.group.accept(ctx)
+	}
 	return l.baseServer.Accept(ctx)
 }
 
@@ -143,6 +150,9 @@ func (l *Listener) Accept(ctx context.Context) (Connection, error) {
 // * if it was created using Transport.Listen, already established connections will be unaffected
 // * if it was created using the Listen convenience method, all established connection will be closed immediately
 func (l *Listener) Close() error {
+	if l.group != nil {
+		return l.group.Close()
+	}
 	return l.baseServer.Close()
 }
 
@@ -159,15 +169,22 @@ func (l *Listener) Addr() net.Addr {
 // client has not yet been confirmed, and the 0-RTT data could have been replayed by an attacker.
 type EarlyListener struct {
 	baseServer *baseServer
+	group      *serverGroup // set when listening on multiple sockets
 }
 
 // Accept returns a new connections. It should be called in a loop.
 func (l *EarlyListener) Accept(ctx context.Context) (EarlyConnection, error) {
+	if l.group != nil {
+		return l.group.accept(ctx)
+	}
 	return l.baseServer.accept(ctx)
 }
 
 // Close the server. All active connections will be closed.
 func (l *EarlyListener) Close() error {
+	if l.group != nil {
+		return l.group.Close()
+	}
 	return l.baseServer.Close()
 }
 
@@ -245,9 +262,10 @@ func newServer(
 	config *Config,
 	tracer *logging.Tracer,
 	onClose func(),
-	tokenGeneratorKey TokenGeneratorKey,
+	tokenProvider TokenProvider,
 	maxTokenAge time.Duration,
 	verifySourceAddress func(net.Addr) bool,
+	preferredAddress *PreferredAddress,
 	disableVersionNegotiation bool,
 	acceptEarly bool,
 ) *baseServer {
@@ -256,9 +274,10 @@ func newServer(
 		connContext:               connContext,
 		tlsConf:                   tlsConf,
 		config:                    config,
-		tokenGenerator:            handshake.NewTokenGenerator(tokenGeneratorKey),
+		tokenProvider:             tokenProvider,
 		maxTokenAge:               maxTokenAge,
 		verifySourceAddress:       verifySourceAddress,
+		preferredAddress:          preferredAddress,
 		connIDGenerator:           connIDGenerator,
 		connHandler:               connHandler,
 		connQueue:                 make(chan quicConn, protocol.MaxAcceptQueueSize),
@@ -430,7 +449,7 @@ func (s *baseServer) handlePacketImpl(p receivedPacket) bool /* is the buffer st
 
 	// If we're creating a new connection, the packet will be passed to the connection.
 	// The header will then be parsed again.
-	hdr, _, _, err := wire.ParsePacket(p.data)
+	hdr, _, _, err := wire.ParsePacket(p.data, s.config.EnableQUICBitGreasing)
 	if err != nil {
 		if s.tracer != nil && s.tracer.DroppedPacket != nil {
 			s.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropHeaderParseError)
@@ -539,7 +558,7 @@ func (s *baseServer) cleanupZeroRTTQueues(now time.Time) {
 //   - address is invalid
 //   - token is expired
 //   - token is null
-func (s *baseServer) validateToken(token *handshake.Token, addr net.Addr) bool {
+func (s *baseServer) validateToken(token *AddressToken, addr net.Addr) bool {
 	if token == nil {
 		return false
 	}
@@ -573,14 +592,14 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	}
 
 	var (
-		token              *handshake.Token
+		token              *AddressToken
 		retrySrcConnID     *protocol.ConnectionID
 		clientAddrVerified bool
 	)
 	origDestConnID := hdr.DestConnectionID
 	if len(hdr.Token) > 0 {
-		tok, err := s.tokenGenerator.DecodeToken(hdr.Token)
-		if err == nil {
+		tok, err := s.tokenProvider.DecodeToken(hdr.Token)
+		if err == nil && tok != nil {
 			if tok.IsRetryToken {
 				origDestConnID = tok.OriginalDestConnectionID
 				retrySrcConnID = &tok.RetrySrcConnectionID
@@ -623,11 +642,15 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		return nil
 	}
 
+	// The sendConn uses the destination address of the Initial as the source address for all packets.
+	sconn := newSendConn(s.conn, p.remoteAddr, p.info, s.logger)
 	config := s.config
 	if s.config.GetConfigForClient != nil {
 		conf, err := s.config.GetConfigForClient(&ClientHelloInfo{
 			RemoteAddr:   p.remoteAddr,
+			LocalAddr:    sconn.LocalAddr(),
 			AddrVerified: clientAddrVerified,
+			Token:        token,
 		})
 		if err != nil {
 			s.logger.Debugf("Rejecting new connection due to GetConfigForClient callback")
@@ -681,7 +704,7 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	conn = s.newConn(
 		ctx,
 		cancel,
-		newSendConn(s.conn, p.remoteAddr, p.info, s.logger),
+		sconn,
 		s.connHandler,
 		origDestConnID,
 		retrySrcConnID,
@@ -692,7 +715,8 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		s.connHandler.GetStatelessResetToken(connID),
 		config,
 		s.tlsConf,
-		s.tokenGenerator,
+		s.tokenProvider,
+		s.preferredAddress,
 		clientAddrVerified,
 		tracer,
 		s.logger,
@@ -773,7 +797,7 @@ func (s *baseServer) sendRetryPacket(p rejectedPacket) error {
 	if err != nil {
 		return err
 	}
-	token, err := s.tokenGenerator.NewRetryToken(p.remoteAddr, hdr.DestConnectionID, srcConnID)
+	token, err := s.tokenProvider.NewRetryToken(p.remoteAddr, hdr.DestConnectionID, srcConnID)
 	if err != nil {
 		return err
 	}
@@ -801,7 +825,7 @@ func (s *baseServer) sendRetryPacket(p rejectedPacket) error {
 	if s.tracer != nil && s.tracer.SentPacket != nil {
 		s.tracer.SentPacket(p.remoteAddr, &replyHdr.Header, protocol.ByteCount(len(buf.Data)), nil)
 	}
-	_, err = s.conn.WritePacket(buf.Data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported)
+	_, err = s.conn.WritePacket(buf.Data, p.remoteAddr, p.info.addr, p.info.OOB(), 0, protocol.ECNUnsupported, time.Time{})
 	return err
 }
 
@@ -886,7 +910,7 @@ func (s *baseServer) sendError(remoteAddr net.Addr, hdr *wire.Header, sealer han
 	if s.tracer != nil && s.tracer.SentPacket != nil {
 		s.tracer.SentPacket(remoteAddr, &replyHdr.Header, protocol.ByteCount(len(b.Data)), []logging.Frame{ccf})
 	}
-	_, err = s.conn.WritePacket(b.Data, remoteAddr, info.OOB(), 0, protocol.ECNUnsupported)
+	_, err = s.conn.WritePacket(b.Data, remoteAddr, info.addr, info.OOB(), 0, protocol.ECNUnsupported, time.Time{})
 	return err
 }
 
@@ -924,7 +948,7 @@ func (s *baseServer) maybeSendVersionNegotiationPacket(p receivedPacket) {
 	if s.tracer != nil && s.tracer.SentVersionNegotiationPacket != nil {
 		s.tracer.SentVersionNegotiationPacket(p.remoteAddr, src, dest, s.config.Versions)
 	}
-	if _, err := s.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported); err != nil {
+	if _, err := s.conn.WritePacket(data, p.remoteAddr, p.info.addr, p.info.OOB(), 0, protocol.ECNUnsupported, time.Time{}); err != nil {
 		s.logger.Debugf("Error sending Version Negotiation: %s", err)
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"net/netip"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,6 +27,11 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// nilTokenProvider is a TokenProvider that decodes every token to nil, without returning an error.
+type nilTokenProvider struct{ TokenProvider }
+
+func (p *nilTokenProvider) DecodeToken([]byte) (*AddressToken, error) { return nil, nil }
+
 var _ = Describe("Server", func() {
 	var (
 		conn    *MockPacketConn
@@ -80,7 +86,7 @@ var _ = Describe("Server", func() {
 	}
 
 	parseHeader := func(data []byte) *wire.Header {
-		hdr, _, _, err := wire.ParsePacket(data)
+		hdr, _, _, err := wire.ParsePacket(data, false)
 		Expect(err).ToNot(HaveOccurred())
 		return hdr
 	}
@@ -95,7 +101,7 @@ var _ = Describe("Server", func() {
 		Expect(err).ToNot(HaveOccurred())
 		data, err := opener.Open(nil, b[extHdr.ParsedLen():], extHdr.PacketNumber, b[:extHdr.ParsedLen()])
 		Expect(err).ToNot(HaveOccurred())
-		_, f, err := wire.NewFrameParser(false).ParseNext(data, protocol.EncryptionInitial, origHdr.Version)
+		_, f, err := wire.NewFrameParser(false, false, false).ParseNext(data, protocol.EncryptionInitial, origHdr.Version)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(f).To(BeAssignableToTypeOf(&wire.ConnectionCloseFrame{}))
 		ccf := f.(*wire.ConnectionCloseFrame)
@@ -264,7 +270,7 @@ var _ = Describe("Server", func() {
 			It("creates a connection when the token is accepted", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return true }
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				retryToken, err := serv.tokenGenerator.NewRetryToken(
+				retryToken, err := serv.tokenProvider.NewRetryToken(
 					raddr,
 					protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde}),
 					protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
@@ -300,7 +306,8 @@ var _ = Describe("Server", func() {
 					tokenP protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -503,7 +510,8 @@ var _ = Describe("Server", func() {
 					tokenP protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -572,7 +580,8 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -628,7 +637,8 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -678,7 +688,8 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -708,7 +719,7 @@ var _ = Describe("Server", func() {
 				conn.EXPECT().WriteTo(gomock.Any(), gomock.Any()).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
 					defer GinkgoRecover()
 					defer close(done)
-					hdr, _, _, err := wire.ParsePacket(b)
+					hdr, _, _, err := wire.ParsePacket(b, false)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(hdr.Type).To(Equal(protocol.PacketTypeRetry))
 					return len(b), nil
@@ -740,7 +751,8 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -756,7 +768,7 @@ var _ = Describe("Server", func() {
 					return c
 				}
 				raddr := &net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 1337}
-				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenProvider.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				packet := getPacket(&wire.Header{
 					Type:    protocol.PacketTypeInitial,
@@ -781,7 +793,7 @@ var _ = Describe("Server", func() {
 
 			It("sends an INVALID_TOKEN error, if an invalid retry token is received", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return true }
-				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenProvider.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -820,7 +832,7 @@ var _ = Describe("Server", func() {
 				serv.config.HandshakeIdleTimeout = time.Millisecond / 2 // the maximum retry token age is equivalent to the handshake timeout
 				Expect(serv.config.maxRetryTokenAge()).To(Equal(time.Millisecond))
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenProvider.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				time.Sleep(2 * time.Millisecond) // make sure the token is expired
 				hdr := &wire.Header{
@@ -855,7 +867,7 @@ var _ = Describe("Server", func() {
 
 			It("doesn't send an INVALID_TOKEN error, if an invalid non-retry token is received", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return true }
-				token, err := serv.tokenGenerator.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
+				token, err := serv.tokenProvider.NewToken(context.Background(), &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -886,7 +898,7 @@ var _ = Describe("Server", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return true }
 				serv.maxTokenAge = time.Millisecond
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				token, err := serv.tokenGenerator.NewToken(raddr)
+				token, err := serv.tokenProvider.NewToken(context.Background(), raddr)
 				Expect(err).ToNot(HaveOccurred())
 				time.Sleep(2 * time.Millisecond) // make sure the token is expired
 				hdr := &wire.Header{
@@ -912,7 +924,7 @@ var _ = Describe("Server", func() {
 			})
 
 			It("doesn't send an INVALID_TOKEN error, if the packet is corrupted", func() {
-				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenProvider.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -975,7 +987,8 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					conf *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -1043,7 +1056,8 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					conf *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -1087,6 +1101,106 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("passes the local address to GetConfigClient", func() {
+				infoChan := make(chan *ClientHelloInfo, 1)
+				serv.config = populateConfig(&Config{GetConfigForClient: func(info *ClientHelloInfo) (*Config, error) {
+					infoChan <- info
+					return nil, errors.New("rejected")
+				}})
+
+				phm.EXPECT().Get(gomock.Any())
+				done := make(chan struct{})
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				conn.EXPECT().WriteTo(gomock.Any(), gomock.Any()).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					close(done)
+					return len(b), nil
+				})
+				remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 42), Port: 4321}
+				serv.handleInitialImpl(
+					receivedPacket{
+						buffer:     getPacketBuffer(),
+						remoteAddr: remoteAddr,
+						info:       packetInfo{addr: netip.MustParseAddr("::ffff:192.0.2.1")},
+					},
+					&wire.Header{DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}), Version: protocol.Version1},
+				)
+				var info *ClientHelloInfo
+				Expect(infoChan).To(Receive(&info))
+				Expect(info.RemoteAddr).To(Equal(remoteAddr))
+				Expect(info.LocalAddr).To(Equal(&net.UDPAddr{IP: net.IPv4(192, 0, 2, 1).To4()}))
+				Expect(info.Token).To(BeNil())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("passes validated tokens to GetConfigClient", func() {
+				tokenProvider, err := NewDefaultTokenProvider(TokenKey{ID: 7, Key: TokenGeneratorKey{1, 2, 3}})
+				Expect(err).ToNot(HaveOccurred())
+				tokenProvider.GetData = func(context.Context) []byte { return []byte("premium") }
+				serv.tokenProvider = tokenProvider
+				serv.maxTokenAge = time.Hour
+				infoChan := make(chan *ClientHelloInfo, 1)
+				serv.config = populateConfig(&Config{GetConfigForClient: func(info *ClientHelloInfo) (*Config, error) {
+					infoChan <- info
+					return nil, errors.New("rejected")
+				}})
+
+				remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 42), Port: 4321}
+				token, err := tokenProvider.NewToken(context.Background(), remoteAddr)
+				Expect(err).ToNot(HaveOccurred())
+				phm.EXPECT().Get(gomock.Any())
+				done := make(chan struct{})
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				conn.EXPECT().WriteTo(gomock.Any(), gomock.Any()).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					close(done)
+					return len(b), nil
+				})
+				serv.handleInitialImpl(
+					receivedPacket{buffer: getPacketBuffer(), remoteAddr: remoteAddr},
+					&wire.Header{
+						DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+						Token:            token,
+						Version:          protocol.Version1,
+					},
+				)
+				var info *ClientHelloInfo
+				Expect(infoChan).To(Receive(&info))
+				Expect(info.AddrVerified).To(BeTrue())
+				Expect(info.Token).ToNot(BeNil())
+				Expect(info.Token.IsRetryToken).To(BeFalse())
+				Expect(info.Token.Data).To(Equal([]byte("premium")))
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("ignores tokens if the TokenProvider returns neither a token nor an error", func() {
+				serv.tokenProvider = &nilTokenProvider{TokenProvider: serv.tokenProvider}
+				infoChan := make(chan *ClientHelloInfo, 1)
+				serv.config = populateConfig(&Config{GetConfigForClient: func(info *ClientHelloInfo) (*Config, error) {
+					infoChan <- info
+					return nil, errors.New("rejected")
+				}})
+
+				phm.EXPECT().Get(gomock.Any())
+				done := make(chan struct{})
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				conn.EXPECT().WriteTo(gomock.Any(), gomock.Any()).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					close(done)
+					return len(b), nil
+				})
+				serv.handleInitialImpl(
+					receivedPacket{buffer: getPacketBuffer(), remoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 42), Port: 4321}},
+					&wire.Header{
+						DestConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+						Token:            []byte("foobar"),
+						Version:          protocol.Version1,
+					},
+				)
+				var info *ClientHelloInfo
+				Expect(infoChan).To(Receive(&info))
+				Expect(info.AddrVerified).To(BeFalse())
+				Expect(info.Token).To(BeNil())
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("accepts new connections when the handshake completes", func() {
 				conn := NewMockQUICConn(mockCtrl)
 
@@ -1114,7 +1228,8 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenProvider,
+					_ *PreferredAddress,
 					_ bool,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
@@ -1185,7 +1300,8 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenProvider,
+				_ *PreferredAddress,
 				_ bool,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
@@ -1227,7 +1343,8 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenProvider,
+				_ *PreferredAddress,
 				_ bool,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
@@ -1287,7 +1404,8 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenProvider,
+				_ *PreferredAddress,
 				_ bool,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
@@ -1410,7 +1528,8 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenProvider,
+				_ *PreferredAddress,
 				_ bool,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
@@ -0,0 +1,275 @@
+package quic
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+const sessionStateRevision = 1
+
+// A SessionState is the serialized form of a tls.ClientSessionState.
+// Besides the TLS session, it contains the state that quic-go saves alongside it:
+// the RTT estimate and, for session tickets that allow 0-RTT, the server's transport parameters.
+type SessionState struct {
+	// Ticket is the session ticket (or the PSK identity) sent to the server.
+	Ticket []byte
+	// State is the TLS session state, as encoded by tls.SessionState.Bytes.
+	State []byte
+}
+
+// NewSessionState serializes a tls.ClientSessionState.
+func NewSessionState(cs *tls.ClientSessionState) (*SessionState, error) {
+	ticket, state, err := cs.ResumptionState()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, errors.New("quic: session can't be resumed")
+	}
+	b, err := state.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &SessionState{Ticket: ticket, State: b}, nil
+}
+
+// ClientSessionState restores the tls.ClientSessionState.
+func (s *SessionState) ClientSessionState() (*tls.ClientSessionState, error) {
+	state, err := tls.ParseSessionState(s.State)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewResumptionState(s.Ticket, state)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s *SessionState) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 8+len(s.Ticket)+len(s.State))
+	b = quicvarint.Append(b, sessionStateRevision)
+	b = quicvarint.Append(b, uint64(len(s.Ticket)))
+	b = append(b, s.Ticket...)
+	return append(b, s.State...), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *SessionState) UnmarshalBinary(b []byte) error {
+	rev, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return errors.New("quic: failed to read session state revision")
+	}
+	if rev != sessionStateRevision {
+		return fmt.Errorf("quic: unknown session state revision: %d", rev)
+	}
+	b = b[l:]
+	ticketLen, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return errors.New("quic: failed to read session ticket length")
+	}
+	b = b[l:]
+	if uint64(len(b)) < ticketLen {
+		return errors.New("quic: failed to read session ticket")
+	}
+	s.Ticket = append([]byte(nil), b[:ticketLen]...)
+	s.State = append([]byte(nil), b[ticketLen:]...)
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (t *ClientToken) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), t.data...), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (t *ClientToken) UnmarshalBinary(b []byte) error {
+	t.data = append([]byte(nil), b...)
+	return nil
+}
+
+// A SessionStore is a key-value store used to persist session resumption state,
+// such that it can be shared between processes, or survive a restart.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Get returns the value stored for the key.
+	// It returns nil if no value is stored.
+	Get(key string) ([]byte, error)
+	// Put stores the value for the key, replacing any previous value.
+	Put(key string, value []byte) error
+	// Delete removes the value stored for the key.
+	// It is not an error if no value is stored.
+	Delete(key string) error
+	// Take atomically removes the value stored for the key, and returns it.
+	// If multiple processes share the store, only one of them obtains the value.
+	// It returns nil if no value is stored.
+	Take(key string) ([]byte, error)
+}
+
+const (
+	sessionStoreTLSPrefix   = "tls:"
+	sessionStoreTokenPrefix = "token:"
+)
+
+type persistentClientSessionCache struct {
+	store SessionStore
+}
+
+var _ tls.ClientSessionCache = &persistentClientSessionCache{}
+
+// NewPersistentClientSessionCache creates a tls.ClientSessionCache that saves sessions to the SessionStore.
+// It is used by setting tls.Config.ClientSessionCache.
+// Errors returned by the SessionStore are treated as cache misses.
+func NewPersistentClientSessionCache(store SessionStore) tls.ClientSessionCache {
+	return &persistentClientSessionCache{store: store}
+}
+
+func (c *persistentClientSessionCache) Get(key string) (*tls.ClientSessionState, bool) {
+	b, err := c.store.Get(sessionStoreTLSPrefix + key)
+	if err != nil || b == nil {
+		return nil, false
+	}
+	var s SessionState
+	if err := s.UnmarshalBinary(b); err != nil {
+		// Remove the session, so we don't run into this error over and over again.
+		c.store.Delete(sessionStoreTLSPrefix + key)
+		return nil, false
+	}
+	cs, err := s.ClientSessionState()
+	if err != nil {
+		c.store.Delete(sessionStoreTLSPrefix + key)
+		return nil, false
+	}
+	return cs, true
+}
+
+func (c *persistentClientSessionCache) Put(key string, cs *tls.ClientSessionState) {
+	if cs == nil {
+		c.store.Delete(sessionStoreTLSPrefix + key)
+		return
+	}
+	s, err := NewSessionState(cs)
+	if err != nil {
+		return
+	}
+	b, err := s.MarshalBinary()
+	if err != nil {
+		return
+	}
+	c.store.Put(sessionStoreTLSPrefix+key, b)
+}
+
+type persistentTokenStore struct {
+	store SessionStore
+}
+
+var _ TokenStore = &persistentTokenStore{}
+
+// NewPersistentTokenStore creates a TokenStore that saves tokens to the SessionStore.
+// Only the most recently received token is kept for every key.
+// Tokens are removed from the SessionStore using SessionStore.Take,
+// so that a token is never used twice, even if the store is shared between multiple processes.
+// Errors returned by the SessionStore are treated as cache misses.
+func NewPersistentTokenStore(store SessionStore) TokenStore {
+	return &persistentTokenStore{store: store}
+}
+
+func (s *persistentTokenStore) Pop(key string) *ClientToken {
+	// Tokens are not supposed to be reused.
+	b, err := s.store.Take(sessionStoreTokenPrefix + key)
+	if err != nil || b == nil {
+		return nil
+	}
+	token := &ClientToken{}
+	if err := token.UnmarshalBinary(b); err != nil {
+		return nil
+	}
+	return token
+}
+
+func (s *persistentTokenStore) Put(key string, token *ClientToken) {
+	b, err := token.MarshalBinary()
+	if err != nil {
+		return
+	}
+	s.store.Put(sessionStoreTokenPrefix+key, b)
+}
+
+type fileSessionStore struct {
+	dir string
+}
+
+var _ SessionStore = &fileSessionStore{}
+
+// NewFileSessionStore creates a SessionStore that saves every value in a separate file in dir.
+// The directory is created if it doesn't exist yet.
+// Values are written atomically, so the directory can be shared between multiple processes.
+func NewFileSessionStore(dir string) (SessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fileSessionStore{dir: dir}, nil
+}
+
+func (s *fileSessionStore) path(key string) string {
+	// keys might contain characters that are not allowed in file names
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(key)))
+}
+
+func (s *fileSessionStore) Get(key string) ([]byte, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return b, err
+}
+
+func (s *fileSessionStore) Put(key string, value []byte) error {
+	f, err := os.CreateTemp(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), s.path(key)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *fileSessionStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileSessionStore) Take(key string) ([]byte, error) {
+	f, err := os.CreateTemp(s.dir, ".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	tmp := f.Name()
+	f.Close()
+	defer os.Remove(tmp)
+	// Renaming is atomic: if multiple processes take the same value, only one of them succeeds.
+	if err := os.Rename(s.path(key), tmp); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return os.ReadFile(tmp)
+}
@@ -0,0 +1,287 @@
+package quic
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/quic-go/quic-go/internal/testdata"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type mapSessionStore struct {
+	mutex sync.Mutex
+	m     map[string][]byte
+}
+
+var _ SessionStore = &mapSessionStore{}
+
+func (s *mapSessionStore) Get(key string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.m[key], nil
+}
+
+func (s *mapSessionStore) Put(key string, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.m == nil {
+		s.m = make(map[string][]byte)
+	}
+	s.m[key] = value
+	return nil
+}
+
+func (s *mapSessionStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.m, key)
+	return nil
+}
+
+func (s *mapSessionStore) Take(key string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	v := s.m[key]
+	delete(s.m, key)
+	return v, nil
+}
+
+type recordingClientSessionCache struct {
+	tls.ClientSessionCache
+	puts chan *tls.ClientSessionState
+}
+
+func (c *recordingClientSessionCache) Put(key string, cs *tls.ClientSessionState) {
+	c.ClientSessionCache.Put(key, cs)
+	c.puts <- cs
+}
+
+var _ = Describe("Session Store", func() {
+	var serverConf *tls.Config
+
+	BeforeEach(func() {
+		serverConf = testdata.GetTLSConfig()
+	})
+
+	// tlsHandshake performs a TLS handshake, and waits until the client received a session ticket
+	tlsHandshake := func(cache tls.ClientSessionCache) (tls.ConnectionState, *tls.ClientSessionState) {
+		c, s := net.Pipe()
+		defer c.Close()
+		defer s.Close()
+		cache = &recordingClientSessionCache{ClientSessionCache: cache, puts: make(chan *tls.ClientSessionState, 1)}
+		client := tls.Client(c, &tls.Config{
+			ServerName:         "localhost",
+			RootCAs:            testdata.GetRootCA(),
+			ClientSessionCache: cache,
+		})
+		server := tls.Server(s, serverConf)
+		errChan := make(chan error, 1)
+		go func() {
+			if err := server.Handshake(); err != nil {
+				errChan <- err
+				return
+			}
+			_, err := server.Write([]byte("foo"))
+			errChan <- err
+		}()
+		Expect(client.Handshake()).To(Succeed())
+		// the session ticket is processed when reading application data
+		_, err := io.ReadFull(client, make([]byte, 3))
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(errChan).Should(Receive(BeNil()))
+		var cs *tls.ClientSessionState
+		Eventually(cache.(*recordingClientSessionCache).puts).Should(Receive(&cs))
+		return client.ConnectionState(), cs
+	}
+
+	Context("session state", func() {
+		It("serializes a TLS session", func() {
+			_, cs := tlsHandshake(tls.NewLRUClientSessionCache(1))
+			// simulate the data saved by quic-go
+			ticket, state, err := cs.ResumptionState()
+			Expect(err).ToNot(HaveOccurred())
+			state.Extra = append(state.Extra, []byte("quic-go data"))
+			cs, err = tls.NewResumptionState(ticket, state)
+			Expect(err).ToNot(HaveOccurred())
+
+			s, err := NewSessionState(cs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Ticket).To(Equal(ticket))
+			b, err := s.MarshalBinary()
+			Expect(err).ToNot(HaveOccurred())
+			var s2 SessionState
+			Expect(s2.UnmarshalBinary(b)).To(Succeed())
+			Expect(s2).To(Equal(*s))
+			restored, err := s2.ClientSessionState()
+			Expect(err).ToNot(HaveOccurred())
+			restoredTicket, restoredState, err := restored.ResumptionState()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restoredTicket).To(Equal(ticket))
+			Expect(restoredState.Extra).To(ContainElement([]byte("quic-go data")))
+		})
+
+		It("rejects invalid session states", func() {
+			var s SessionState
+			Expect(s.UnmarshalBinary(nil)).To(MatchError("quic: failed to read session state revision"))
+			Expect(s.UnmarshalBinary(quicvarint.Append(nil, 1337))).To(MatchError("quic: unknown session state revision: 1337"))
+			b := quicvarint.Append(nil, sessionStateRevision)
+			Expect(s.UnmarshalBinary(b)).To(MatchError("quic: failed to read session ticket length"))
+			b = quicvarint.Append(b, 10)
+			b = append(b, []byte("foobar")...)
+			Expect(s.UnmarshalBinary(b)).To(MatchError("quic: failed to read session ticket"))
+		})
+	})
+
+	Context("client session cache", func() {
+		It("resumes sessions using a new cache", func() {
+			store := &mapSessionStore{}
+			connState, _ := tlsHandshake(NewPersistentClientSessionCache(store))
+			Expect(connState.DidResume).To(BeFalse())
+			Expect(store.m).To(HaveKey("tls:localhost"))
+			// the new cache simulates a restart of the client
+			connState, _ = tlsHandshake(NewPersistentClientSessionCache(store))
+			Expect(connState.DidResume).To(BeTrue())
+		})
+
+		It("deletes sessions", func() {
+			store := &mapSessionStore{}
+			cache := NewPersistentClientSessionCache(store)
+			tlsHandshake(cache)
+			_, ok := cache.Get("localhost")
+			Expect(ok).To(BeTrue())
+			cache.Put("localhost", nil)
+			Expect(store.m).To(BeEmpty())
+			_, ok = cache.Get("localhost")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("deletes invalid sessions", func() {
+			store := &mapSessionStore{}
+			Expect(store.Put("tls:localhost", []byte("foobar"))).To(Succeed())
+			cache := NewPersistentClientSessionCache(store)
+			_, ok := cache.Get("localhost")
+			Expect(ok).To(BeFalse())
+			Expect(store.m).To(BeEmpty())
+		})
+	})
+
+	Context("token store", func() {
+		It("stores tokens", func() {
+			store := &mapSessionStore{}
+			NewPersistentTokenStore(store).Put("localhost", &ClientToken{data: []byte("foo")})
+			NewPersistentTokenStore(store).Put("localhost", &ClientToken{data: []byte("bar")})
+			s := NewPersistentTokenStore(store)
+			Expect(s.Pop("localhost")).To(Equal(&ClientToken{data: []byte("bar")}))
+			// tokens are only used once
+			Expect(s.Pop("localhost")).To(BeNil())
+			Expect(s.Pop("example.com")).To(BeNil())
+		})
+
+		It("doesn't mix up tokens and sessions", func() {
+			store := &mapSessionStore{}
+			NewPersistentTokenStore(store).Put("localhost", &ClientToken{data: []byte("foo")})
+			_, ok := NewPersistentClientSessionCache(store).Get("localhost")
+			Expect(ok).To(BeFalse())
+			Expect(NewPersistentTokenStore(store).Pop("localhost")).To(Equal(&ClientToken{data: []byte("foo")}))
+		})
+	})
+
+	Context("file store", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+
+		It("stores values", func() {
+			s, err := NewFileSessionStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+			const key = "tls:example.com/foo"
+			b, err := s.Get(key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(BeNil())
+			Expect(s.Put(key, []byte("foo"))).To(Succeed())
+			Expect(s.Put(key, []byte("bar"))).To(Succeed())
+			Expect(s.Put("token:example.com", []byte("baz"))).To(Succeed())
+
+			// a new store reads the same values
+			s, err = NewFileSessionStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+			b, err = s.Get(key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("bar")))
+			Expect(s.Delete(key)).To(Succeed())
+			Expect(s.Delete(key)).To(Succeed())
+			b, err = s.Get(key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(BeNil())
+			entries, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+
+		It("takes values", func() {
+			s, err := NewFileSessionStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+			b, err := s.Take("token:example.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(BeNil())
+			Expect(s.Put("token:example.com", []byte("foo"))).To(Succeed())
+
+			// only one of multiple stores using the same directory obtains the value
+			const num = 10
+			var wg sync.WaitGroup
+			values := make(chan []byte, num)
+			for i := 0; i < num; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					s, err := NewFileSessionStore(dir)
+					Expect(err).ToNot(HaveOccurred())
+					b, err := s.Take("token:example.com")
+					Expect(err).ToNot(HaveOccurred())
+					values <- b
+				}()
+			}
+			wg.Wait()
+			close(values)
+			var taken [][]byte
+			for b := range values {
+				if b != nil {
+					taken = append(taken, b)
+				}
+			}
+			Expect(taken).To(Equal([][]byte{[]byte("foo")}))
+			entries, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("creates the directory", func() {
+			s, err := NewFileSessionStore(dir + "/sessions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Put("foo", []byte("bar"))).To(Succeed())
+			info, err := os.Stat(dir + "/sessions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.IsDir()).To(BeTrue())
+		})
+
+		It("resumes sessions", func() {
+			s, err := NewFileSessionStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+			connState, _ := tlsHandshake(NewPersistentClientSessionCache(s))
+			Expect(connState.DidResume).To(BeFalse())
+			s, err = NewFileSessionStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+			connState, _ = tlsHandshake(NewPersistentClientSessionCache(s))
+			Expect(connState.DidResume).To(BeTrue())
+		})
+	})
+})
@@ -26,6 +26,9 @@ var errDeadline net.Error = &deadlineError{}
 type streamSender interface {
 	onHasStreamData(protocol.StreamID, sendStreamI)
 	onHasStreamControlFrame(protocol.StreamID, streamControlFrameGetter)
+	onStreamPriorityChanged(protocol.StreamID, StreamPriority, sendStreamI)
+	// says if the peer supports the RESET_STREAM_AT frame
+	supportsResetStreamAt() bool
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -59,6 +62,7 @@ type streamI interface {
 	handleStopSendingFrame(*wire.StopSendingFrame)
 	popStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (ackhandler.StreamFrame, bool, bool)
 	updateSendWindow(protocol.ByteCount)
+	isCompleted() bool
 }
 
 var (
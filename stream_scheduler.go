@@ -0,0 +1,269 @@
+package quic
+
+import (
+	"container/heap"
+	"slices"
+
+	list "github.com/quic-go/quic-go/internal/utils/linkedlist"
+)
+
+// StreamPriority is the priority of a stream, as set by SendStream.SetPriority.
+// Urgency and Incremental have the semantics of the Extensible Prioritization Scheme for HTTP (RFC 9218),
+// and are used by the strict-priority scheduler.
+// Weight is used by the weighted fair scheduler.
+// Streams that SetPriority was not called on have an urgency of 3, are not incremental, and use the default weight.
+type StreamPriority struct {
+	// Urgency ranges from 0 (highest priority) to 7 (lowest priority).
+	// Larger values are treated as 7.
+	Urgency uint8
+	// Incremental says if the data of this stream can be interleaved with the data
+	// of other incremental streams of the same urgency.
+	Incremental bool
+	// Weight is the relative share of the bandwidth that this stream receives,
+	// compared to all other streams that have data to send.
+	// If 0, the default weight of 16 is used.
+	Weight uint8
+}
+
+const (
+	maxStreamUrgency     = 7
+	defaultStreamWeight  = 16
+	streamWeightVTimeMul = 256
+)
+
+var defaultStreamPriority = StreamPriority{Urgency: 3}
+
+// A StreamScheduler decides which stream the next STREAM frame is sent on.
+// It only keeps track of the streams that currently have data to send.
+// Its methods are called from the connection's run loop, and must not block.
+type StreamScheduler interface {
+	// AddStream is called when a stream has data to send.
+	// It is not called for streams that were already added.
+	AddStream(StreamID, StreamPriority)
+	// RemoveStream is called when a stream doesn't have any more data to send.
+	RemoveStream(StreamID)
+	// SetPriority is called when the priority of a stream that was added changes.
+	SetPriority(StreamID, StreamPriority)
+	// NextStream returns the stream that the next STREAM frame is sent on.
+	// The stream stays scheduled until RemoveStream is called.
+	NextStream() (_ StreamID, ok bool)
+	// StreamDataSent is called after a STREAM frame containing n bytes of stream data
+	// was packed for the stream returned by NextStream.
+	StreamDataSent(_ StreamID, n int)
+}
+
+type roundRobinStreamScheduler struct {
+	queue   list.List[StreamID]
+	streams map[StreamID]*list.Element[StreamID]
+}
+
+// NewRoundRobinStreamScheduler creates a StreamScheduler that sends a STREAM frame on all streams in turn.
+// It ignores stream priorities.
+func NewRoundRobinStreamScheduler() StreamScheduler {
+	return &roundRobinStreamScheduler{streams: make(map[StreamID]*list.Element[StreamID])}
+}
+
+func (s *roundRobinStreamScheduler) AddStream(id StreamID, _ StreamPriority) {
+	s.streams[id] = s.queue.PushBack(id)
+}
+
+func (s *roundRobinStreamScheduler) RemoveStream(id StreamID) {
+	if e, ok := s.streams[id]; ok {
+		s.queue.Remove(e)
+		delete(s.streams, id)
+	}
+}
+
+func (s *roundRobinStreamScheduler) SetPriority(StreamID, StreamPriority) {}
+
+func (s *roundRobinStreamScheduler) NextStream() (StreamID, bool) {
+	e := s.queue.Front()
+	if e == nil {
+		return 0, false
+	}
+	s.queue.MoveToBack(e)
+	return e.Value, true
+}
+
+func (s *roundRobinStreamScheduler) StreamDataSent(StreamID, int) {}
+
+type strictPriorityLevel struct {
+	// non-incremental streams, sorted by stream ID
+	sequential []StreamID
+	// incremental streams, in round-robin order
+	incremental list.List[StreamID]
+}
+
+type strictPriorityStreamScheduler struct {
+	levels      [maxStreamUrgency + 1]strictPriorityLevel
+	priorities  map[StreamID]StreamPriority
+	incremental map[StreamID]*list.Element[StreamID]
+}
+
+// NewStrictPriorityStreamScheduler creates a StreamScheduler that implements the scheduling
+// recommended by RFC 9218, Section 10:
+// Data is only sent on a stream if no stream with a higher urgency has data to send.
+// Among streams of the same urgency, non-incremental streams are sent one after the other,
+// in the order of their stream IDs, before incremental streams are served in turn.
+func NewStrictPriorityStreamScheduler() StreamScheduler {
+	return &strictPriorityStreamScheduler{
+		priorities:  make(map[StreamID]StreamPriority),
+		incremental: make(map[StreamID]*list.Element[StreamID]),
+	}
+}
+
+func (s *strictPriorityStreamScheduler) AddStream(id StreamID, p StreamPriority) {
+	p.Urgency = min(p.Urgency, maxStreamUrgency)
+	s.priorities[id] = p
+	level := &s.levels[p.Urgency]
+	if p.Incremental {
+		s.incremental[id] = level.incremental.PushBack(id)
+		return
+	}
+	i, _ := slices.BinarySearch(level.sequential, id)
+	level.sequential = slices.Insert(level.sequential, i, id)
+}
+
+func (s *strictPriorityStreamScheduler) RemoveStream(id StreamID) {
+	p, ok := s.priorities[id]
+	if !ok {
+		return
+	}
+	delete(s.priorities, id)
+	level := &s.levels[p.Urgency]
+	if p.Incremental {
+		level.incremental.Remove(s.incremental[id])
+		delete(s.incremental, id)
+		return
+	}
+	if i, found := slices.BinarySearch(level.sequential, id); found {
+		level.sequential = slices.Delete(level.sequential, i, i+1)
+	}
+}
+
+func (s *strictPriorityStreamScheduler) SetPriority(id StreamID, p StreamPriority) {
+	if _, ok := s.priorities[id]; !ok {
+		return
+	}
+	s.RemoveStream(id)
+	s.AddStream(id, p)
+}
+
+func (s *strictPriorityStreamScheduler) NextStream() (StreamID, bool) {
+	for i := range s.levels {
+		level := &s.levels[i]
+		if len(level.sequential) > 0 {
+			return level.sequential[0], true
+		}
+		if e := level.incremental.Front(); e != nil {
+			level.incremental.MoveToBack(e)
+			return e.Value, true
+		}
+	}
+	return 0, false
+}
+
+func (s *strictPriorityStreamScheduler) StreamDataSent(StreamID, int) {}
+
+type weightedFairStream struct {
+	id     StreamID
+	weight uint64
+	// the virtual time at which this stream is next served
+	vtime uint64
+	// the index of the stream in the heap
+	index int
+}
+
+// weightedFairStreamHeap is a min-heap of streams, ordered by their virtual time.
+// Ties are broken by stream ID.
+type weightedFairStreamHeap []*weightedFairStream
+
+var _ heap.Interface = &weightedFairStreamHeap{}
+
+func (h weightedFairStreamHeap) Len() int { return len(h) }
+
+func (h weightedFairStreamHeap) Less(i, j int) bool {
+	if h[i].vtime != h[j].vtime {
+		return h[i].vtime < h[j].vtime
+	}
+	return h[i].id < h[j].id
+}
+
+func (h weightedFairStreamHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *weightedFairStreamHeap) Push(x any) {
+	str := x.(*weightedFairStream)
+	str.index = len(*h)
+	*h = append(*h, str)
+}
+
+func (h *weightedFairStreamHeap) Pop() any {
+	old := *h
+	n := len(old)
+	str := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return str
+}
+
+type weightedFairStreamScheduler struct {
+	streams map[StreamID]*weightedFairStream
+	queue   weightedFairStreamHeap
+	// the virtual time of the stream that was served last
+	vtime uint64
+}
+
+// NewWeightedFairStreamScheduler creates a StreamScheduler that divides the available bandwidth
+// between all streams that have data to send, proportional to their weight.
+// It ignores the urgency and the incremental flag.
+func NewWeightedFairStreamScheduler() StreamScheduler {
+	return &weightedFairStreamScheduler{streams: make(map[StreamID]*weightedFairStream)}
+}
+
+func streamWeight(p StreamPriority) uint64 {
+	if p.Weight == 0 {
+		return defaultStreamWeight
+	}
+	return uint64(p.Weight)
+}
+
+func (s *weightedFairStreamScheduler) AddStream(id StreamID, p StreamPriority) {
+	str := &weightedFairStream{id: id, weight: streamWeight(p), vtime: s.vtime}
+	s.streams[id] = str
+	heap.Push(&s.queue, str)
+}
+
+func (s *weightedFairStreamScheduler) RemoveStream(id StreamID) {
+	str, ok := s.streams[id]
+	if !ok {
+		return
+	}
+	heap.Remove(&s.queue, str.index)
+	delete(s.streams, id)
+}
+
+func (s *weightedFairStreamScheduler) SetPriority(id StreamID, p StreamPriority) {
+	if str, ok := s.streams[id]; ok {
+		str.weight = streamWeight(p)
+	}
+}
+
+func (s *weightedFairStreamScheduler) NextStream() (StreamID, bool) {
+	if len(s.queue) == 0 {
+		return 0, false
+	}
+	str := s.queue[0]
+	s.vtime = str.vtime
+	return str.id, true
+}
+
+func (s *weightedFairStreamScheduler) StreamDataSent(id StreamID, n int) {
+	if str, ok := s.streams[id]; ok {
+		str.vtime += uint64(n) * streamWeightVTimeMul / str.weight
+		heap.Fix(&s.queue, str.index)
+	}
+}
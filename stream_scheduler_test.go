@@ -0,0 +1,174 @@
+package quic
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream Scheduler", func() {
+	// nextStreams returns the next n streams, assuming that every stream sends 1000 bytes
+	nextStreams := func(s StreamScheduler, n int) []StreamID {
+		var ids []StreamID
+		for i := 0; i < n; i++ {
+			id, ok := s.NextStream()
+			Expect(ok).To(BeTrue())
+			s.StreamDataSent(id, 1000)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	Context("round robin", func() {
+		It("uses all streams in turn", func() {
+			s := NewRoundRobinStreamScheduler()
+			_, ok := s.NextStream()
+			Expect(ok).To(BeFalse())
+			s.AddStream(8, StreamPriority{Urgency: 0})
+			s.AddStream(4, StreamPriority{Urgency: 7})
+			s.AddStream(0, defaultStreamPriority)
+			Expect(nextStreams(s, 6)).To(Equal([]StreamID{8, 4, 0, 8, 4, 0}))
+		})
+
+		It("removes streams", func() {
+			s := NewRoundRobinStreamScheduler()
+			s.AddStream(0, defaultStreamPriority)
+			s.AddStream(4, defaultStreamPriority)
+			s.AddStream(8, defaultStreamPriority)
+			s.RemoveStream(4)
+			s.RemoveStream(12) // doesn't exist
+			Expect(nextStreams(s, 4)).To(Equal([]StreamID{0, 8, 0, 8}))
+			s.RemoveStream(0)
+			s.RemoveStream(8)
+			_, ok := s.NextStream()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("strict priority", func() {
+		It("sends streams with a higher urgency first", func() {
+			s := NewStrictPriorityStreamScheduler()
+			s.AddStream(0, StreamPriority{Urgency: 5})
+			s.AddStream(4, StreamPriority{Urgency: 1})
+			s.AddStream(8, StreamPriority{Urgency: 42}) // treated as 7
+			Expect(nextStreams(s, 2)).To(Equal([]StreamID{4, 4}))
+			s.RemoveStream(4)
+			Expect(nextStreams(s, 2)).To(Equal([]StreamID{0, 0}))
+			s.RemoveStream(0)
+			Expect(nextStreams(s, 1)).To(Equal([]StreamID{8}))
+		})
+
+		It("sends non-incremental streams in the order of their stream ID", func() {
+			s := NewStrictPriorityStreamScheduler()
+			s.AddStream(8, StreamPriority{Urgency: 3})
+			s.AddStream(0, StreamPriority{Urgency: 3})
+			s.AddStream(4, StreamPriority{Urgency: 3, Incremental: true})
+			Expect(nextStreams(s, 2)).To(Equal([]StreamID{0, 0}))
+			s.RemoveStream(0)
+			Expect(nextStreams(s, 2)).To(Equal([]StreamID{8, 8}))
+			s.RemoveStream(8)
+			Expect(nextStreams(s, 2)).To(Equal([]StreamID{4, 4}))
+		})
+
+		It("interleaves incremental streams", func() {
+			s := NewStrictPriorityStreamScheduler()
+			s.AddStream(8, StreamPriority{Urgency: 2, Incremental: true})
+			s.AddStream(0, StreamPriority{Urgency: 2, Incremental: true})
+			s.AddStream(4, StreamPriority{Urgency: 6, Incremental: true})
+			Expect(nextStreams(s, 4)).To(Equal([]StreamID{8, 0, 8, 0}))
+		})
+
+		It("changes the priority", func() {
+			s := NewStrictPriorityStreamScheduler()
+			s.AddStream(0, StreamPriority{Urgency: 3})
+			s.AddStream(4, StreamPriority{Urgency: 4, Incremental: true})
+			Expect(nextStreams(s, 1)).To(Equal([]StreamID{0}))
+			s.SetPriority(4, StreamPriority{Urgency: 1})
+			s.SetPriority(12, StreamPriority{Urgency: 0}) // doesn't exist
+			Expect(nextStreams(s, 2)).To(Equal([]StreamID{4, 4}))
+			s.RemoveStream(4)
+			s.SetPriority(0, StreamPriority{Urgency: 0, Incremental: true})
+			Expect(nextStreams(s, 1)).To(Equal([]StreamID{0}))
+			s.RemoveStream(0)
+			_, ok := s.NextStream()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("weighted fair", func() {
+		It("divides the bandwidth proportional to the weights", func() {
+			s := NewWeightedFairStreamScheduler()
+			s.AddStream(0, StreamPriority{Weight: 100})
+			s.AddStream(4, StreamPriority{Weight: 50})
+			s.AddStream(8, StreamPriority{Weight: 50})
+			counts := make(map[StreamID]int)
+			for _, id := range nextStreams(s, 400) {
+				counts[id]++
+			}
+			Expect(counts).To(Equal(map[StreamID]int{0: 200, 4: 100, 8: 100}))
+		})
+
+		It("uses the default weight", func() {
+			s := NewWeightedFairStreamScheduler()
+			s.AddStream(0, defaultStreamPriority)
+			s.AddStream(4, StreamPriority{Weight: 2 * defaultStreamWeight})
+			counts := make(map[StreamID]int)
+			for _, id := range nextStreams(s, 300) {
+				counts[id]++
+			}
+			Expect(counts).To(Equal(map[StreamID]int{0: 100, 4: 200}))
+		})
+
+		It("doesn't give credit to streams that are added later", func() {
+			s := NewWeightedFairStreamScheduler()
+			s.AddStream(0, defaultStreamPriority)
+			nextStreams(s, 100)
+			s.AddStream(4, defaultStreamPriority)
+			counts := make(map[StreamID]int)
+			for _, id := range nextStreams(s, 100) {
+				counts[id]++
+			}
+			Expect(counts[0]).To(BeNumerically("~", 50, 1))
+			Expect(counts[4]).To(BeNumerically("~", 50, 1))
+		})
+
+		It("breaks ties by stream ID", func() {
+			s := NewWeightedFairStreamScheduler()
+			for _, id := range []StreamID{12, 4, 16, 0, 8} {
+				s.AddStream(id, defaultStreamPriority)
+			}
+			Expect(nextStreams(s, 10)).To(Equal([]StreamID{0, 4, 8, 12, 16, 0, 4, 8, 12, 16}))
+		})
+
+		It("removes streams", func() {
+			s := NewWeightedFairStreamScheduler()
+			for id := StreamID(0); id < 400; id += 4 {
+				s.AddStream(id, defaultStreamPriority)
+			}
+			nextStreams(s, 150)
+			for id := StreamID(0); id < 400; id += 4 {
+				if id != 200 && id != 8 {
+					s.RemoveStream(id)
+				}
+			}
+			s.RemoveStream(1000) // doesn't exist
+			Expect(nextStreams(s, 4)).To(Equal([]StreamID{200, 8, 200, 8}))
+		})
+
+		It("changes the weight", func() {
+			s := NewWeightedFairStreamScheduler()
+			s.AddStream(0, StreamPriority{Weight: 10})
+			s.AddStream(4, StreamPriority{Weight: 10})
+			s.SetPriority(4, StreamPriority{Weight: 30})
+			counts := make(map[StreamID]int)
+			for _, id := range nextStreams(s, 400) {
+				counts[id]++
+			}
+			Expect(counts[0]).To(BeNumerically("~", 100, 1))
+			Expect(counts[4]).To(BeNumerically("~", 300, 1))
+			s.RemoveStream(0)
+			s.RemoveStream(4)
+			_, ok := s.NextStream()
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -3,6 +3,7 @@ package quic
 import (
 	"log"
 	"net"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
@@ -26,7 +27,14 @@ type OOBCapablePacketConn interface {
 
 var _ OOBCapablePacketConn = &net.UDPConn{}
 
-func wrapConn(pc net.PacketConn) (rawConn, error) {
+// A sysConn is a connection that uses the kernel's UDP stack.
+// It is used by a Transport as a rawConn (see Transport.Conn), and returned as a PacketIO by NewPacketIO.
+type sysConn interface {
+	rawConn
+	PacketIO
+}
+
+func wrapConn(pc net.PacketConn) (sysConn, error) {
 	if err := setReceiveBuffer(pc); err != nil {
 		if !strings.Contains(err.Error(), "use of closed network connection") {
 			setBufferWarningOnce.Do(func() {
@@ -85,7 +93,7 @@ type basicConn struct {
 	supportsDF bool
 }
 
-var _ rawConn = &basicConn{}
+var _ sysConn = &basicConn{}
 
 func (c *basicConn) ReadPacket() (receivedPacket, error) {
 	buffer := getPacketBuffer()
@@ -104,14 +112,21 @@ func (c *basicConn) ReadPacket() (receivedPacket, error) {
 	}, nil
 }
 
-func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ []byte, gsoSize uint16, ecn protocol.ECN) (n int, err error) {
+func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ netip.Addr, _ []byte, gsoSize uint16, ecn protocol.ECN, txTime time.Time) (n int, err error) {
 	if gsoSize != 0 {
 		panic("cannot use GSO with a basicConn")
 	}
 	if ecn != protocol.ECNUnsupported {
 		panic("cannot use ECN with a basicConn")
 	}
+	if !txTime.IsZero() {
+		panic("cannot use SO_TXTIME with a basicConn")
+	}
 	return c.PacketConn.WriteTo(b, addr)
 }
 
 func (c *basicConn) capabilities() connCapabilities { return connCapabilities{DF: c.supportsDF} }
+
+func (c *basicConn) ReadBatch(packets []Packet) (int, error)  { return readBatch(c, packets) }
+func (c *basicConn) WriteBatch(packets []Packet) (int, error) { return writeBatch(c, packets) }
+func (c *basicConn) Capabilities() PacketIOCapabilities       { return packetIOCapabilities(c) }
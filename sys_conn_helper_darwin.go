@@ -35,4 +35,8 @@ func parseIPv4PktInfo(body []byte) (ip netip.Addr, ifIndex uint32, ok bool) {
 
 func isGSOEnabled(syscall.RawConn) bool { return false }
 
+func enableTXTime(syscall.RawConn) bool        { return false }
+func enableGRO(syscall.RawConn) bool           { return false }
+func parseUDPGROMsg(int32, []byte) (int, bool) { return 0, false }
+
 func isECNEnabled() bool { return !isECNDisabledUsingEnv() }
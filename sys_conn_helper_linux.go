@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/unix"
@@ -80,6 +81,34 @@ func isGSOEnabled(conn syscall.RawConn) bool {
 	return serr == nil
 }
 
+// enableGRO enables UDP Generic Receive Offload (GRO) on the socket.
+// With GRO, the kernel coalesces multiple datagrams received from the same sender,
+// and reports the size of the individual datagrams in the UDP_GRO control message.
+func enableGRO(conn syscall.RawConn) bool {
+	if kernelVersionMajor < 5 {
+		return false
+	}
+	disabled, err := strconv.ParseBool(os.Getenv("QUIC_GO_DISABLE_GRO"))
+	if err == nil && disabled {
+		return false
+	}
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_UDP, unix.UDP_GRO, 1)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// parseUDPGROMsg parses the size of the individual datagrams from the UDP_GRO control message.
+func parseUDPGROMsg(msgType int32, body []byte) (int, bool) {
+	if msgType != unix.UDP_GRO || len(body) < 4 {
+		return 0, false
+	}
+	return int(binary.NativeEndian.Uint32(body)), true
+}
+
 func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
 	startLen := len(b)
 	const dataLen = 2 // payload is a uint16
@@ -95,6 +124,51 @@ func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
 	return b
 }
 
+// enableTXTime enables SO_TXTIME on the socket.
+// The kernel then sends every packet at the time given in its SCM_TXTIME control message.
+// Note that this only works if the outgoing interface uses a qdisc that supports
+// earliest departure time scheduling, like fq.
+func enableTXTime(conn syscall.RawConn) bool {
+	if kernelVersionMajor < 5 {
+		return false
+	}
+	// struct sock_txtime {
+	// 	__kernel_clockid_t clockid; /* reference clockid */
+	// 	__u32              flags;   /* as defined by enum txtime_flags */
+	// };
+	cfg := make([]byte, 8)
+	binary.NativeEndian.PutUint32(cfg, unix.CLOCK_MONOTONIC)
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_TXTIME, string(cfg))
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// appendTXTimeMsg appends a SCM_TXTIME control message.
+// The kernel expects the time in nanoseconds of the CLOCK_MONOTONIC clock.
+func appendTXTimeMsg(b []byte, t time.Time) []byte {
+	var now unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &now); err != nil {
+		return b
+	}
+	txTime := now.Nano() + int64(time.Until(t))
+
+	startLen := len(b)
+	const dataLen = 8 // payload is a uint64
+	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = unix.SOL_SOCKET
+	h.Type = unix.SCM_TXTIME
+	h.SetLen(unix.CmsgLen(dataLen))
+
+	offset := startLen + unix.CmsgSpace(0)
+	binary.NativeEndian.PutUint64(b[offset:], uint64(txTime))
+	return b
+}
+
 func isGSOError(err error) bool {
 	var serr *os.SyscallError
 	if errors.As(err, &serr) {
@@ -3,14 +3,22 @@
 package quic
 
 import (
+	"encoding/binary"
 	"errors"
 	"net"
+	"net/netip"
 	"os"
+	"time"
+	"unsafe"
 
+	"golang.org/x/net/ipv4"
 	"golang.org/x/sys/unix"
 
+	"github.com/quic-go/quic-go/internal/protocol"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
 )
 
 var (
@@ -79,3 +87,140 @@ var _ = Describe("forcing a change of send and receive buffer sizes", func() {
 		Expect(isGSOError(errors.New("test"))).To(BeFalse())
 	})
 })
+
+var _ = Describe("GRO", func() {
+	appendUDPGROMsg := func(b []byte, segmentSize int) []byte {
+		startLen := len(b)
+		b = append(b, make([]byte, unix.CmsgSpace(4))...)
+		h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+		h.Level = unix.IPPROTO_UDP
+		h.Type = unix.UDP_GRO
+		h.SetLen(unix.CmsgLen(4))
+		binary.NativeEndian.PutUint32(b[startLen+unix.CmsgSpace(0):], uint32(segmentSize))
+		return b
+	}
+
+	newGROConn := func() *oobConn {
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(udpConn.Close)
+		c, err := newConn(udpConn, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.capabilities().GRO).To(BeTrue())
+		return c
+	}
+
+	It("parses the UDP_GRO control message", func() {
+		size, ok := parseUDPGROMsg(unix.UDP_GRO, appendUDPGROMsg(nil, 1337)[unix.CmsgSpace(0):])
+		Expect(ok).To(BeTrue())
+		Expect(size).To(Equal(1337))
+		_, ok = parseUDPGROMsg(unix.UDP_SEGMENT, []byte{1, 2, 3, 4})
+		Expect(ok).To(BeFalse())
+		_, ok = parseUDPGROMsg(unix.UDP_GRO, []byte{1, 2, 3})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("splits coalesced datagrams", func() {
+		c := newGROConn()
+		batchConn := NewMockBatchConn(mockCtrl)
+		c.batchConn = batchConn
+		batchConn.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ms []ipv4.Message, _ int) (int, error) {
+			Expect(ms[0].Buffers[0]).To(HaveLen(protocol.MaxGROBufferSize))
+			ms[0].N = copy(ms[0].Buffers[0], "foobarfoobarfoo")
+			ms[0].NN = len(appendUDPGROMsg(ms[0].OOB[:0], 6))
+			ms[1].N = copy(ms[1].Buffers[0], "raboof")
+			ms[1].NN = 0
+			return 2, nil
+		})
+
+		var packets []receivedPacket
+		for i := 0; i < 4; i++ {
+			p, err := c.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			packets = append(packets, p)
+		}
+		Expect(string(packets[0].data)).To(Equal("foobar"))
+		Expect(string(packets[1].data)).To(Equal("foobar"))
+		Expect(string(packets[2].data)).To(Equal("foo"))
+		Expect(string(packets[3].data)).To(Equal("raboof"))
+		shared := packets[0].buffer.parent
+		Expect(shared).ToNot(BeNil())
+		Expect(packets[1].buffer.parent).To(Equal(shared))
+		// the last segment, as well as datagrams that weren't coalesced, are copied
+		Expect(packets[2].buffer.parent).To(BeNil())
+		Expect(packets[2].buffer.Cap()).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
+		Expect(packets[3].buffer.parent).To(BeNil())
+		Expect(packets[3].buffer.Cap()).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
+		Expect(shared.refCount.Load()).To(BeEquivalentTo(2))
+		for _, p := range packets {
+			p.buffer.Release()
+		}
+		Expect(shared.refCount.Load()).To(BeZero())
+	})
+
+	It("receives datagrams sent with GSO", func() {
+		server := newGROConn()
+		client, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+		clientConn, err := newConn(client, true)
+		Expect(err).ToNot(HaveOccurred())
+		if !clientConn.capabilities().GSO {
+			Skip("GSO not supported")
+		}
+
+		data := make([]byte, 3*1000+500)
+		for i := range data {
+			data[i] = byte(i / 1000)
+		}
+		_, err = clientConn.WritePacket(data, server.LocalAddr(), netip.Addr{}, nil, 1000, protocol.ECNUnsupported, time.Time{})
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 4; i++ {
+			p, err := server.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.remoteAddr).To(Equal(client.LocalAddr()))
+			Expect(p.data).To(Equal(data[i*1000 : min((i+1)*1000, len(data))]))
+			p.buffer.Release()
+		}
+	})
+})
+
+var _ = Describe("TXTime", func() {
+	It("appends the SCM_TXTIME control message", func() {
+		b := appendTXTimeMsg(nil, time.Now().Add(time.Millisecond))
+		Expect(b).To(HaveLen(unix.CmsgSpace(8)))
+		h := (*unix.Cmsghdr)(unsafe.Pointer(&b[0]))
+		Expect(h.Level).To(BeEquivalentTo(unix.SOL_SOCKET))
+		Expect(h.Type).To(BeEquivalentTo(unix.SCM_TXTIME))
+		Expect(h.Len).To(BeEquivalentTo(unix.CmsgLen(8)))
+		var ts unix.Timespec
+		Expect(unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts)).To(Succeed())
+		txTime := binary.NativeEndian.Uint64(b[unix.CmsgSpace(0):])
+		Expect(txTime).To(BeNumerically(">", uint64(ts.Nano())))
+		Expect(txTime).To(BeNumerically("<=", uint64(ts.Nano())+uint64(time.Millisecond)))
+	})
+
+	It("sends packets with a transmission time", func() {
+		server, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+		client, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+		c, err := newConn(client, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.capabilities().TXTime).To(BeFalse())
+		c.enableKernelPacing()
+		if !c.capabilities().TXTime {
+			Skip("SO_TXTIME not supported")
+		}
+
+		_, err = c.WritePacket([]byte("foobar"), server.LocalAddr(), netip.Addr{}, nil, 0, protocol.ECNUnsupported, time.Now().Add(time.Millisecond))
+		Expect(err).ToNot(HaveOccurred())
+		server.SetReadDeadline(time.Now().Add(time.Second))
+		b := make([]byte, 100)
+		n, _, err := server.ReadFromUDP(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b[:n])).To(Equal("foobar"))
+	})
+})
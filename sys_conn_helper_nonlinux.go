@@ -2,9 +2,12 @@
 
 package quic
 
+import "time"
+
 func forceSetReceiveBuffer(c any, bytes int) error { return nil }
 func forceSetSendBuffer(c any, bytes int) error    { return nil }
 
 func appendUDPSegmentSizeMsg([]byte, uint16) []byte { return nil }
+func appendTXTimeMsg(b []byte, _ time.Time) []byte  { return b }
 func isGSOError(error) bool                         { return false }
 func isPermissionError(err error) bool              { return false }
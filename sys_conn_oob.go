@@ -72,11 +72,17 @@ type oobConn struct {
 	// Packets received from the kernel, but not yet returned by ReadPacket().
 	messages []ipv4.Message
 	buffers  [batchSize]*packetBuffer
+	// When GRO is enabled, datagrams are read into shared buffers,
+	// since a single message might contain multiple datagrams.
+	groBuffers [batchSize]*sharedBuffer
+	// Datagrams that were coalesced by GRO, but not yet returned by ReadPacket().
+	segments   []receivedPacket
+	segmentPos int
 
 	cap connCapabilities
 }
 
-var _ rawConn = &oobConn{}
+var _ sysConn = &oobConn{}
 
 func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 	rawConn, err := c.SyscallConn()
@@ -148,6 +154,7 @@ func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 		cap: connCapabilities{
 			DF:  supportsDF,
 			GSO: isGSOEnabled(rawConn),
+			GRO: enableGRO(rawConn),
 			ECN: isECNEnabled(),
 		},
 	}
@@ -160,10 +167,23 @@ func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 var invalidCmsgOnceV4, invalidCmsgOnceV6 sync.Once
 
 func (c *oobConn) ReadPacket() (receivedPacket, error) {
+	if c.segmentPos < len(c.segments) {
+		p := c.segments[c.segmentPos]
+		c.segments[c.segmentPos] = receivedPacket{}
+		c.segmentPos++
+		return p, nil
+	}
 	if len(c.messages) == int(c.readPos) { // all messages read. Read the next batch of messages.
 		c.messages = c.messages[:batchSize]
 		// replace buffers data buffers up to the packet that has been consumed during the last ReadBatch call
 		for i := uint8(0); i < c.readPos; i++ {
+			if c.cap.GRO {
+				buffer := getSharedBuffer()
+				buffer.Data = buffer.Data[:protocol.MaxGROBufferSize]
+				c.groBuffers[i] = buffer
+				c.messages[i].Buffers[0] = c.groBuffers[i].Data
+				continue
+			}
 			buffer := getPacketBuffer()
 			buffer.Data = buffer.Data[:protocol.MaxPacketBufferSize]
 			c.buffers[i] = buffer
@@ -180,6 +200,7 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 
 	msg := c.messages[c.readPos]
 	buffer := c.buffers[c.readPos]
+	groBuffer := c.groBuffers[c.readPos]
 	c.readPos++
 
 	data := msg.OOB[:msg.NN]
@@ -187,8 +208,8 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 		remoteAddr: msg.Addr,
 		rcvTime:    time.Now(),
 		data:       msg.Buffers[0][:msg.N],
-		buffer:     buffer,
 	}
+	var segmentSize int
 	for len(data) > 0 {
 		hdr, body, remainder, err := unix.ParseOneSocketControlMessage(data)
 		if err != nil {
@@ -211,6 +232,11 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 				}
 			}
 		}
+		if hdr.Level == unix.IPPROTO_UDP {
+			if size, ok := parseUDPGROMsg(hdr.Type, body); ok {
+				segmentSize = size
+			}
+		}
 		if hdr.Level == unix.IPPROTO_IPV6 {
 			switch hdr.Type {
 			case unix.IPV6_TCLASS:
@@ -233,11 +259,68 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 		}
 		data = remainder
 	}
-	return p, nil
+	if !c.cap.GRO {
+		p.buffer = buffer
+		return p, nil
+	}
+	return c.splitSegments(p, groBuffer, segmentSize), nil
+}
+
+// hasBufferedPackets says if ReadPacket returns a packet without reading from the socket.
+func (c *oobConn) hasBufferedPackets() bool {
+	return c.segmentPos < len(c.segments) || int(c.readPos) < len(c.messages)
+}
+
+// splitSegments splits a datagram coalesced by GRO into its segments.
+// It returns the first segment, and queues the other segments in c.segments.
+// Full-sized segments reference the shared buffer, so their data is not copied.
+// Datagrams that weren't coalesced, as well as a shorter last segment, are copied
+// into a regular packet buffer, such that a single small packet doesn't keep the
+// (large) shared buffer from being reused.
+func (c *oobConn) splitSegments(p receivedPacket, buffer *sharedBuffer, segmentSize int) receivedPacket {
+	data := p.data
+	if segmentSize <= 0 || segmentSize >= len(data) {
+		p.buffer = copySegment(buffer, 0, len(data))
+		p.data = p.buffer.Data
+		buffer.Release()
+		return p
+	}
+	c.segments = c.segments[:0]
+	for offset := 0; offset < len(data); offset += segmentSize {
+		segment := p
+		if l := len(data) - offset; l < segmentSize {
+			segment.buffer = copySegment(buffer, offset, offset+l)
+		} else {
+			segment.buffer = buffer.Slice(offset, offset+segmentSize)
+		}
+		segment.data = segment.buffer.Data
+		c.segments = append(c.segments, segment)
+	}
+	buffer.Release()
+	first := c.segments[0]
+	c.segments[0] = receivedPacket{}
+	c.segmentPos = 1
+	return first
+}
+
+// copySegment copies buffer.Data[start:end] into a regular packet buffer.
+// If the segment doesn't fit into a large packet buffer, it references the shared buffer instead.
+func copySegment(buffer *sharedBuffer, start, end int) *packetBuffer {
+	var buf *packetBuffer
+	switch l := end - start; {
+	case l <= protocol.MaxPacketBufferSize:
+		buf = getPacketBuffer()
+	case l <= protocol.MaxLargePacketBufferSize:
+		buf = getLargePacketBuffer()
+	default:
+		return buffer.Slice(start, end)
+	}
+	buf.Data = append(buf.Data, buffer.Data[start:end]...)
+	return buf
 }
 
 // WritePacket writes a new packet.
-func (c *oobConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN) (int, error) {
+func (c *oobConn) WritePacket(b []byte, addr net.Addr, _ netip.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN, txTime time.Time) (int, error) {
 	oob := packetInfoOOB
 	if gsoSize > 0 {
 		if !c.capabilities().GSO {
@@ -257,6 +340,12 @@ func (c *oobConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gso
 			}
 		}
 	}
+	if !txTime.IsZero() {
+		if !c.capabilities().TXTime {
+			panic("tried to set the transmit time although SO_TXTIME is disabled")
+		}
+		oob = appendTXTimeMsg(oob, txTime)
+	}
 	n, _, err := c.OOBCapablePacketConn.WriteMsgUDP(b, oob, addr.(*net.UDPAddr))
 	return n, err
 }
@@ -265,6 +354,19 @@ func (c *oobConn) capabilities() connCapabilities {
 	return c.cap
 }
 
+func (c *oobConn) ReadBatch(packets []Packet) (int, error)  { return readBatch(c, packets) }
+func (c *oobConn) WriteBatch(packets []Packet) (int, error) { return writeBatch(c, packets) }
+func (c *oobConn) Capabilities() PacketIOCapabilities       { return packetIOCapabilities(c) }
+
+// enableKernelPacing enables SO_TXTIME, if supported by the kernel.
+func (c *oobConn) enableKernelPacing() {
+	rawConn, err := c.OOBCapablePacketConn.SyscallConn()
+	if err != nil {
+		return
+	}
+	c.cap.TXTime = enableTXTime(rawConn)
+}
+
 type packetInfo struct {
 	addr    netip.Addr
 	ifIndex uint32
@@ -274,8 +376,9 @@ func (info *packetInfo) OOB() []byte {
 	if info == nil {
 		return nil
 	}
-	if info.addr.Is4() {
-		ip := info.addr.As4()
+	// IPv4-mapped IPv6 addresses are set using IP_PKTINFO, which is honored on both IPv4 and dual-stack sockets.
+	if addr := info.addr.Unmap(); addr.Is4() {
+		ip := addr.As4()
 		// struct in_pktinfo {
 		// 	unsigned int   ipi_ifindex;  /* Interface index */
 		// 	struct in_addr ipi_spec_dst; /* Local address */
@@ -5,12 +5,15 @@ package quic
 import (
 	"fmt"
 	"net"
+	"net/netip"
+	"runtime"
 	"time"
 
 	"golang.org/x/net/ipv4"
 	"golang.org/x/sys/unix"
 
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -249,6 +252,50 @@ var _ = Describe("OOB Conn Test", func() {
 			Expect(p.info).To(Not(BeNil()))
 			Expect(net.IP(p.info.addr.AsSlice())).To(Equal(ip6))
 		})
+
+		It("uses IPv4 packet info for IPv4-mapped IPv6 addresses", func() {
+			mapped := packetInfo{addr: netip.MustParseAddr("::ffff:127.0.0.1"), ifIndex: 1}
+			ipv4 := packetInfo{addr: netip.MustParseAddr("127.0.0.1"), ifIndex: 1}
+			Expect(mapped.OOB()).To(Equal(ipv4.OOB()))
+		})
+
+		It("replies from the destination address of the received packet", func() {
+			if runtime.GOOS != "linux" {
+				Skip("only Linux routes the whole 127.0.0.0/8 block to the loopback interface")
+			}
+			udpConn, err := net.ListenUDP("udp", &net.UDPAddr{})
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			oobConn, err := newConn(udpConn, true)
+			Expect(err).ToNot(HaveOccurred())
+			port := udpConn.LocalAddr().(*net.UDPAddr).Port
+
+			for _, ip := range []net.IP{net.IPv4(127, 0, 0, 2), net.IPv4(127, 0, 0, 3), net.IPv6loopback} {
+				network := "udp4"
+				if ip.To4() == nil {
+					network = "udp6"
+				}
+				conn, err := net.DialUDP(network, nil, &net.UDPAddr{IP: ip, Port: port})
+				Expect(err).ToNot(HaveOccurred())
+				defer conn.Close()
+				_, err = conn.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+
+				p, err := oobConn.ReadPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(net.IP(p.info.addr.AsSlice()).Equal(ip)).To(BeTrue())
+				sconn := newSendConn(oobConn, p.remoteAddr, p.info, utils.DefaultLogger)
+				Expect(sconn.LocalAddr()).To(Equal(&net.UDPAddr{IP: p.info.addr.AsSlice(), Port: port}))
+				Expect(sconn.Write([]byte("raboof"), 0, protocol.ECNUnsupported, time.Time{})).To(Succeed())
+
+				// The client's socket is connected, so it only receives the reply if the source address matches.
+				conn.SetReadDeadline(time.Now().Add(time.Second))
+				b := make([]byte, 100)
+				n, err := conn.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(b[:n])).To(Equal("raboof"))
+			}
+		})
 	})
 
 	Context("Batch Reading", func() {
@@ -281,6 +328,7 @@ var _ = Describe("OOB Conn Test", func() {
 			oobConn, err := newConn(udpConn, true)
 			Expect(err).ToNot(HaveOccurred())
 			oobConn.batchConn = batchConn
+			oobConn.cap.GRO = false // GRO uses larger buffers, see the GRO tests below
 
 			for i := 0; i < batchSize+1; i++ {
 				p, err := oobConn.ReadPacket()
@@ -301,7 +349,7 @@ var _ = Describe("OOB Conn Test", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			oob := make([]byte, 0, 123)
-			oobConn.WritePacket([]byte("foobar"), addr, oob, 0, protocol.ECNCE)
+			oobConn.WritePacket([]byte("foobar"), addr, netip.Addr{}, oob, 0, protocol.ECNCE, time.Time{})
 			Expect(c.oobs).To(HaveLen(1))
 			oobMsg := c.oobs[0]
 			Expect(oobMsg).ToNot(BeEmpty())
@@ -324,7 +372,7 @@ var _ = Describe("OOB Conn Test", func() {
 				Expect(oobConn.capabilities().GSO).To(BeTrue())
 
 				oob := make([]byte, 0, 123)
-				oobConn.WritePacket([]byte("foobar"), addr, oob, 3, protocol.ECNCE)
+				oobConn.WritePacket([]byte("foobar"), addr, netip.Addr{}, oob, 3, protocol.ECNCE, time.Time{})
 				Expect(c.oobs).To(HaveLen(1))
 				oobMsg := c.oobs[0]
 				Expect(oobMsg).ToNot(BeEmpty())
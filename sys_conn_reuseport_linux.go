@@ -0,0 +1,95 @@
+//go:build linux
+
+package quic
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// listenReusePort creates num UDP sockets bound to the same address using SO_REUSEPORT,
+// and attaches a BPF program steering packets to the sockets based on the connection ID.
+func listenReusePort(addr *net.UDPAddr, num, connIDLen int) ([]*net.UDPConn, error) {
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var serr error
+			if err := c.Control(func(fd uintptr) {
+				serr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			}); err != nil {
+				return err
+			}
+			return serr
+		},
+	}
+	conns := make([]*net.UDPConn, 0, num)
+	closeAll := func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}
+	for i := 0; i < num; i++ {
+		c, err := lc.ListenPacket(context.Background(), "udp", addr.String())
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		conns = append(conns, c.(*net.UDPConn))
+		// If no port was specified, all subsequent sockets need to use the port chosen by the kernel.
+		if i == 0 {
+			addr = c.LocalAddr().(*net.UDPAddr)
+		}
+	}
+	if err := attachSteeringProgram(conns[0], num, connIDLen); err != nil {
+		closeAll()
+		return nil, err
+	}
+	return conns, nil
+}
+
+// attachSteeringProgram attaches a classic BPF program to the SO_REUSEPORT group of conn.
+// The program selects the socket based on the last byte of the Destination Connection ID.
+// The kernel runs the program on the UDP payload, and uses the return value as the index of
+// the socket in the group. Sockets are indexed in the order they were bound.
+func attachSteeringProgram(conn *net.UDPConn, num, connIDLen int) error {
+	// For Short Header packets, the connection ID starts right after the first byte.
+	// For Long Header packets, it starts after the first byte, the version and the connection ID length.
+	// Client-chosen connection IDs of Initial packets might have a different length, in which case the
+	// packet is steered to a random (but consistent for all Initial packets of the connection attempt) socket.
+	prog, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 0, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x80, SkipTrue: 3},
+		// Short Header
+		bpf.LoadAbsolute{Off: uint32(connIDLen), Size: 1},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: uint32(num)},
+		bpf.RetA{},
+		// Long Header
+		bpf.LoadAbsolute{Off: uint32(1 + 4 + 1 + connIDLen - 1), Size: 1},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: uint32(num)},
+		bpf.RetA{},
+	})
+	if err != nil {
+		return err
+	}
+	filter := make([]unix.SockFilter, 0, len(prog))
+	for _, ins := range prog {
+		filter = append(filter, unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K})
+	}
+	fprog := unix.SockFprog{Len: uint16(len(filter)), Filter: unsafe.SliceData(filter)}
+
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var serr error
+	if err := rawConn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptSockFprog(int(fd), unix.SOL_SOCKET, unix.SO_ATTACH_REUSEPORT_CBPF, &fprog)
+	}); err != nil {
+		return err
+	}
+	return serr
+}
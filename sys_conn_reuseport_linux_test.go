@@ -0,0 +1,46 @@
+//go:build linux
+
+package quic
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SO_REUSEPORT steering", func() {
+	It("steers packets based on the connection ID", func() {
+		const numSockets = 4
+		const connIDLen = 5
+		conns, err := listenReusePort(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, numSockets, connIDLen)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conns).To(HaveLen(numSockets))
+		for _, c := range conns {
+			defer c.Close()
+			Expect(c.LocalAddr()).To(Equal(conns[0].LocalAddr()))
+		}
+
+		client, err := net.DialUDP("udp", nil, conns[0].LocalAddr().(*net.UDPAddr))
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		expectReceivedOn := func(index int, packet []byte) {
+			_, err := client.Write(packet)
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, 100)
+			Expect(conns[index].SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+			n, _, err := conns[index].ReadFromUDP(b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b[:n]).To(Equal(packet))
+		}
+
+		for i := 0; i < 2*numSockets; i++ {
+			// short header packet
+			expectReceivedOn(i%numSockets, []byte{0x40, 1, 2, 3, 4, byte(i), 0xff, 0xff})
+			// long header packet
+			expectReceivedOn(i%numSockets, []byte{0xc0, 0, 0, 0, 1, connIDLen, 1, 2, 3, 4, byte(i), 0xff})
+		}
+	})
+})
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package quic
+
+import (
+	"errors"
+	"net"
+)
+
+func listenReusePort(*net.UDPAddr, int, int) ([]*net.UDPConn, error) {
+	return nil, errors.New("quic: listening on multiple sockets using SO_REUSEPORT is only supported on Linux")
+}
@@ -0,0 +1,132 @@
+package quic
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/quic-go/quic-go/internal/handshake"
+)
+
+// An AddressToken is a token used for address validation, see section 8.1 of RFC 9000.
+// It is either sent in a Retry packet, or in a NEW_TOKEN frame after completion of the handshake.
+type AddressToken = handshake.Token
+
+// A TokenKey is a key used to encrypt address validation tokens.
+// The ID is sent in the clear, and is used to select the key when decrypting a token.
+type TokenKey = handshake.TokenKey
+
+// A TokenProvider issues and decodes address validation tokens.
+// It allows the application to control the format and the encryption of the tokens.
+// If multiple servers are authoritative for the same domain, they should be able to decode
+// each other's tokens, see section 8.1.3 of RFC 9000.
+type TokenProvider interface {
+	// NewRetryToken issues a token that is sent in a Retry packet.
+	NewRetryToken(remoteAddr net.Addr, origDestConnID, retrySrcConnID ConnectionID) ([]byte, error)
+	// NewToken issues a token that is sent in a NEW_TOKEN frame once the handshake completes.
+	// The context is the context of the connection, see Connection.Context.
+	NewToken(ctx context.Context, remoteAddr net.Addr) ([]byte, error)
+	// DecodeToken decodes a token sent by the client.
+	// It returns an error if the token can't be decoded, in which case the token is ignored.
+	// The token is also ignored if DecodeToken returns neither a token nor an error.
+	// Validation of the remote address and the token age is performed by the server.
+	DecodeToken(token []byte) (*AddressToken, error)
+}
+
+// DefaultTokenProvider is the TokenProvider used if Transport.TokenProvider is not set.
+// Tokens are encrypted using AES-GCM, and may be encrypted using one of multiple keys,
+// which allows rotating keys without rejecting tokens issued using the previous key.
+// The zero value is ready to use, and encrypts tokens using a randomly generated key.
+type DefaultTokenProvider struct {
+	// GetData returns application-defined data that is included in tokens sent in NEW_TOKEN frames.
+	// The context is the context of the connection, see Connection.Context.
+	// When the token is used on a later connection, the data is available in AddressToken.Data.
+	GetData func(ctx context.Context) []byte
+
+	initOnce  sync.Once
+	initErr   error
+	generator *handshake.TokenGenerator
+}
+
+var _ TokenProvider = &DefaultTokenProvider{}
+
+// NewDefaultTokenProvider creates a new DefaultTokenProvider.
+// New tokens are encrypted using the current key.
+// Tokens encrypted using the current or any of the previous keys are accepted.
+func NewDefaultTokenProvider(current TokenKey, previous ...TokenKey) (*DefaultTokenProvider, error) {
+	if err := checkTokenKeys(current, previous); err != nil {
+		return nil, err
+	}
+	return &DefaultTokenProvider{generator: handshake.NewTokenGenerator(current, previous...)}, nil
+}
+
+// SetKeys replaces the keys used to encrypt and decrypt tokens.
+// It is safe to call SetKeys while the provider is being used.
+func (p *DefaultTokenProvider) SetKeys(current TokenKey, previous ...TokenKey) error {
+	if err := checkTokenKeys(current, previous); err != nil {
+		return err
+	}
+	if err := p.init(); err != nil {
+		return err
+	}
+	p.generator.SetKeys(current, previous...)
+	return nil
+}
+
+// init initializes the zero value, using a randomly generated key.
+func (p *DefaultTokenProvider) init() error {
+	p.initOnce.Do(func() {
+		if p.generator != nil {
+			return
+		}
+		var key TokenKey
+		if _, err := rand.Read(key.Key[:]); err != nil {
+			p.initErr = err
+			return
+		}
+		p.generator = handshake.NewTokenGenerator(key)
+	})
+	return p.initErr
+}
+
+func checkTokenKeys(current TokenKey, previous []TokenKey) error {
+	ids := make(map[uint8]struct{}, len(previous)+1)
+	ids[current.ID] = struct{}{}
+	for _, k := range previous {
+		if _, ok := ids[k.ID]; ok {
+			return fmt.Errorf("quic: duplicate token key ID: %d", k.ID)
+		}
+		ids[k.ID] = struct{}{}
+	}
+	return nil
+}
+
+// NewRetryToken issues a token that is sent in a Retry packet.
+func (p *DefaultTokenProvider) NewRetryToken(remoteAddr net.Addr, origDestConnID, retrySrcConnID ConnectionID) ([]byte, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return p.generator.NewRetryToken(remoteAddr, origDestConnID, retrySrcConnID)
+}
+
+// NewToken issues a token that is sent in a NEW_TOKEN frame.
+func (p *DefaultTokenProvider) NewToken(ctx context.Context, remoteAddr net.Addr) ([]byte, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	var data []byte
+	if p.GetData != nil {
+		data = p.GetData(ctx)
+	}
+	return p.generator.NewToken(remoteAddr, data)
+}
+
+// DecodeToken decodes a token.
+func (p *DefaultTokenProvider) DecodeToken(token []byte) (*AddressToken, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return p.generator.DecodeToken(token)
+}
@@ -0,0 +1,84 @@
+package quic
+
+import (
+	"context"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type tokenDataKey struct{}
+
+var _ = Describe("DefaultTokenProvider", func() {
+	addr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1337}
+
+	It("includes application data in NEW_TOKEN tokens", func() {
+		p, err := NewDefaultTokenProvider(TokenKey{Key: TokenGeneratorKey{1}})
+		Expect(err).ToNot(HaveOccurred())
+		p.GetData = func(ctx context.Context) []byte { return ctx.Value(tokenDataKey{}).([]byte) }
+		token, err := p.NewToken(context.WithValue(context.Background(), tokenDataKey{}, []byte("foobar")), addr)
+		Expect(err).ToNot(HaveOccurred())
+		decoded, err := p.DecodeToken(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.IsRetryToken).To(BeFalse())
+		Expect(decoded.Data).To(Equal([]byte("foobar")))
+		Expect(decoded.ValidateRemoteAddr(addr)).To(BeTrue())
+	})
+
+	It("rotates keys", func() {
+		key1 := TokenKey{ID: 1, Key: TokenGeneratorKey{1}}
+		key2 := TokenKey{ID: 2, Key: TokenGeneratorKey{2}}
+		p, err := NewDefaultTokenProvider(key1)
+		Expect(err).ToNot(HaveOccurred())
+		token1, err := p.NewToken(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(p.SetKeys(key2, key1)).To(Succeed())
+		token2, err := p.NewRetryToken(addr, ConnectionIDFromBytes([]byte{1, 2, 3, 4}), ConnectionIDFromBytes([]byte{5, 6, 7, 8}))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p.DecodeToken(token1)
+		Expect(err).ToNot(HaveOccurred())
+		decoded, err := p.DecodeToken(token2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.IsRetryToken).To(BeTrue())
+
+		// tokens issued using a retired key are rejected
+		Expect(p.SetKeys(key2)).To(Succeed())
+		_, err = p.DecodeToken(token1)
+		Expect(err).To(MatchError("unknown token key ID: 1"))
+		_, err = p.DecodeToken(token2)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("uses a random key for the zero value", func() {
+		p := &DefaultTokenProvider{GetData: func(context.Context) []byte { return []byte("foobar") }}
+		token, err := p.NewToken(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+		decoded, err := p.DecodeToken(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.Data).To(Equal([]byte("foobar")))
+		_, err = (&DefaultTokenProvider{}).DecodeToken(token)
+		Expect(err).To(HaveOccurred())
+
+		// the keys of the zero value can be replaced
+		key := TokenKey{ID: 1, Key: TokenGeneratorKey{1}}
+		p = &DefaultTokenProvider{}
+		Expect(p.SetKeys(key)).To(Succeed())
+		token, err = p.NewRetryToken(addr, ConnectionIDFromBytes([]byte{1, 2, 3, 4}), ConnectionIDFromBytes([]byte{5, 6, 7, 8}))
+		Expect(err).ToNot(HaveOccurred())
+		p2, err := NewDefaultTokenProvider(key)
+		Expect(err).ToNot(HaveOccurred())
+		decoded, err = p2.DecodeToken(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.IsRetryToken).To(BeTrue())
+	})
+
+	It("rejects duplicate key IDs", func() {
+		_, err := NewDefaultTokenProvider(TokenKey{ID: 1, Key: TokenGeneratorKey{1}}, TokenKey{ID: 1, Key: TokenGeneratorKey{2}})
+		Expect(err).To(MatchError("quic: duplicate token key ID: 1"))
+		p, err := NewDefaultTokenProvider(TokenKey{ID: 1, Key: TokenGeneratorKey{1}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.SetKeys(TokenKey{ID: 2}, TokenKey{ID: 3}, TokenKey{ID: 2})).To(MatchError("quic: duplicate token key ID: 2"))
+	})
+})
@@ -5,11 +5,14 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
+	"net/netip"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -36,10 +39,17 @@ type Transport struct {
 	//    This allows the remote node to speed up its loss detection and recovery.
 	// 3. It uses batched syscalls (recvmmsg) to more efficiently receive packets from the socket.
 	// 4. It uses Generic Segmentation Offload (GSO) to efficiently send batches of packets (on Linux).
+	// 5. It uses Generic Receive Offload (GRO) to efficiently receive batches of packets (on Linux).
 	//
 	// After passing the connection to the Transport, it's invalid to call ReadFrom or WriteTo on the connection.
 	Conn net.PacketConn
 
+	// PacketIO sends and receives packets, as an alternative to using a net.PacketConn.
+	// This allows using networking stacks other than the kernel's UDP sockets, see PacketIO for details.
+	// Exactly one of Conn and PacketIO must be set.
+	// The PacketIO is not closed when the Transport is closed.
+	PacketIO PacketIO
+
 	// The length of the connection ID in bytes.
 	// It can be any value between 1 and 20.
 	// Due to the increased risk of collisions, it is not recommended to use connection IDs shorter than 4 bytes.
@@ -66,6 +76,13 @@ type Transport struct {
 	// see section 8.1.3 of RFC 9000 for details.
 	TokenGeneratorKey *TokenGeneratorKey
 
+	// TokenProvider issues and decodes the tokens used for address validation,
+	// i.e. the tokens sent in Retry packets and in NEW_TOKEN frames.
+	// If not set, a DefaultTokenProvider using the TokenGeneratorKey (with key ID 0) is used.
+	// Use a DefaultTokenProvider with multiple keys to rotate keys without rejecting tokens
+	// issued using the previous key.
+	TokenProvider TokenProvider
+
 	// MaxTokenAge is the maximum age of the resumption token presented during the handshake.
 	// These tokens allow skipping address resumption when resuming a QUIC connection,
 	// and are especially useful when using 0-RTT.
@@ -78,6 +95,16 @@ type Transport struct {
 	// It has no effect for clients.
 	DisableVersionNegotiationPackets bool
 
+	// EnableKernelPacing offloads packet pacing to the kernel, using SO_TXTIME (only on Linux).
+	// Packets are then scheduled ahead of time, and the kernel sends them at their departure time.
+	// This requires the outgoing interface to use a qdisc that supports earliest departure time
+	// scheduling, like fq. quic-go keeps pacing packets itself, and only hands them to the kernel
+	// shortly before their departure time (at most 2ms).
+	// If the qdisc doesn't support earliest departure time scheduling, packets are therefore still paced,
+	// although with a lower precision.
+	// If the kernel rejects SO_TXTIME, or if a PacketIO is used, kernel pacing is not used.
+	EnableKernelPacing bool
+
 	// VerifySourceAddress decides if a connection attempt originating from unvalidated source
 	// addresses first needs to go through source address validation using QUIC's Retry mechanism,
 	// as described in RFC 9000 section 8.1.2.
@@ -100,6 +127,19 @@ type Transport struct {
 	// It is not used for dialed connections.
 	ConnContext func(context.Context) context.Context
 
+	// PreferredAddress is the address the server would like clients to use,
+	// see section 9.6 of RFC 9000.
+	// It is sent in the preferred_address transport parameter, and clients migrate the connection
+	// to this address once the handshake is confirmed.
+	// It has no effect for clients.
+	PreferredAddress *PreferredAddress
+
+	// The Forwarder forwards packets that don't belong to any connection handled by this Transport
+	// to a sibling Transport, usually running in a different process.
+	// This allows restarting a server without interrupting existing connections.
+	// See PacketForwarder and Handoff for details.
+	Forwarder PacketForwarder
+
 	// A Tracer traces events that don't belong to a single QUIC connection.
 	// Tracer.Close is called when the transport is closed.
 	Tracer *logging.Tracer
@@ -116,6 +156,9 @@ type Transport struct {
 	// Set in init.
 	// If no ConnectionIDGenerator is set, this is set to a default.
 	connIDGenerator ConnectionIDGenerator
+	// Set in init.
+	// If no TokenProvider is set, this is a DefaultTokenProvider using the TokenGeneratorKey.
+	tokenProvider TokenProvider
 
 	server *baseServer
 
@@ -128,16 +171,52 @@ type Transport struct {
 	closed      bool
 	createdConn bool
 	isSingleUse bool // was created for a single server or client, i.e. by calling quic.Listen or quic.Dial
+	reusePort   bool // shares the local address with other Transports using SO_REUSEPORT
 
 	readingNonQUICPackets atomic.Bool
 	nonQUICPackets        chan receivedPacket
 
+	// set once a connection advertised support for greasing the QUIC bit (RFC 9287)
+	acceptZeroQUICBit atomic.Bool
+
+	// set once Handoff is called
+	handingOff atomic.Bool
+
 	logger utils.Logger
 }
 
+// ErrTransportClosed is returned by the Transport's ServeForwardedPackets method after the Transport was closed.
+var ErrTransportClosed = errTransportClosed{}
+
+type errTransportClosed struct{}
+
+func (errTransportClosed) Error() string { return "quic: transport closed" }
+func (errTransportClosed) Unwrap() error { return net.ErrClosed }
+
+// A PreferredAddress is a server address that clients migrate to after the handshake.
+// Packets sent to this address are received by a different Transport than the one
+// the server is listening on.
+type PreferredAddress struct {
+	// The IPv4 and IPv6 address advertised to the client.
+	// At least one of them needs to be set.
+	// Clients only migrate to an address of the same address family they used for the handshake.
+	IPv4, IPv6 netip.AddrPort
+	// The Transports that receive packets sent to IPv4 and IPv6.
+	// They may be the same Transport, e.g. when using a dual-stack socket.
+	// They need to use the same connection ID length as the Transport the server is listening on,
+	// and should use the same StatelessResetKey.
+	IPv4Transport, IPv6Transport *Transport
+}
+
 // Listen starts listening for incoming QUIC connections.
 // There can only be a single listener on any net.PacketConn.
 // Listen may only be called again after the current Listener was closed.
+//
+// When listening on a wildcard address, a single Transport can serve multiple local addresses.
+// Every connection then uses the destination address of the client's first packet as its local address.
+// This address is reported by ClientHelloInfo.LocalAddr, and by the net.Conn set on the tls.ClientHelloInfo
+// passed to the GetConfigForClient and GetCertificate callbacks of the tls.Config.
+// This allows using different configurations and certificates for different local addresses.
 func (t *Transport) Listen(tlsConf *tls.Config, conf *Config) (*Listener, error) {
 	s, err := t.createServer(tlsConf, conf, false)
 	if err != nil {
@@ -175,6 +254,14 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	if err := t.init(false); err != nil {
 		return nil, err
 	}
+	if conf.EnableQUICBitGreasing {
+		t.acceptZeroQUICBit.Store(true)
+	}
+	if t.PreferredAddress != nil {
+		if err := t.initPreferredAddress(); err != nil {
+			return nil, err
+		}
+	}
 	s := newServer(
 		t.conn,
 		t.handlerMap,
@@ -184,9 +271,10 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 		conf,
 		t.Tracer,
 		t.closeServer,
-		*t.TokenGeneratorKey,
+		t.tokenProvider,
 		t.MaxTokenAge,
 		t.VerifySourceAddress,
+		t.PreferredAddress,
 		t.DisableVersionNegotiationPackets,
 		allow0RTT,
 	)
@@ -194,6 +282,40 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	return s, nil
 }
 
+func (t *Transport) initPreferredAddress() error {
+	pa := t.PreferredAddress
+	if !pa.IPv4.IsValid() && !pa.IPv6.IsValid() {
+		return errors.New("quic: preferred address needs an IPv4 or an IPv6 address")
+	}
+	if pa.IPv4.IsValid() && !pa.IPv4.Addr().Is4() {
+		return errors.New("quic: invalid IPv4 preferred address")
+	}
+	if pa.IPv6.IsValid() && !pa.IPv6.Addr().Is6() {
+		return errors.New("quic: invalid IPv6 preferred address")
+	}
+	for _, tr := range []*Transport{pa.IPv4Transport, pa.IPv6Transport} {
+		if tr == nil {
+			continue
+		}
+		if tr == t {
+			return errors.New("quic: preferred address must use a different Transport")
+		}
+		if err := tr.init(false); err != nil {
+			return err
+		}
+		if tr.connIDLen != t.connIDLen {
+			return fmt.Errorf("quic: preferred address Transport uses connection IDs of length %d, expected %d", tr.connIDLen, t.connIDLen)
+		}
+	}
+	if pa.IPv4.IsValid() && pa.IPv4Transport == nil {
+		return errors.New("quic: missing Transport for the IPv4 preferred address")
+	}
+	if pa.IPv6.IsValid() && pa.IPv6Transport == nil {
+		return errors.New("quic: missing Transport for the IPv6 preferred address")
+	}
+	return nil
+}
+
 // Dial dials a new connection to a remote host (not using 0-RTT).
 func (t *Transport) Dial(ctx context.Context, addr net.Addr, tlsConf *tls.Config, conf *Config) (Connection, error) {
 	return t.dial(ctx, addr, "", tlsConf, conf, false)
@@ -212,6 +334,9 @@ func (t *Transport) dial(ctx context.Context, addr net.Addr, host string, tlsCon
 	if err := t.init(t.isSingleUse); err != nil {
 		return nil, err
 	}
+	if conf.EnableQUICBitGreasing {
+		t.acceptZeroQUICBit.Store(true)
+	}
 	var onClose func()
 	if t.isSingleUse {
 		onClose = func() { t.Close() }
@@ -224,7 +349,13 @@ func (t *Transport) dial(ctx context.Context, addr net.Addr, host string, tlsCon
 func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 	t.initOnce.Do(func() {
 		var conn rawConn
-		if c, ok := t.Conn.(rawConn); ok {
+		if t.PacketIO != nil {
+			if t.Conn != nil {
+				t.initErr = errors.New("quic: both Conn and PacketIO set")
+				return
+			}
+			conn = newPacketIOConn(t.PacketIO)
+		} else if c, ok := t.Conn.(rawConn); ok {
 			conn = c
 		} else {
 			var err error
@@ -235,6 +366,12 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			}
 		}
 
+		if t.EnableKernelPacing {
+			if c, ok := conn.(interface{ enableKernelPacing() }); ok {
+				c.enableKernelPacing()
+			}
+		}
+
 		t.logger = utils.DefaultLogger // TODO: make this configurable
 		t.conn = conn
 		t.handlerMap = newPacketHandlerMap(t.StatelessResetKey, t.enqueueClosePacket, t.logger)
@@ -250,6 +387,12 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			}
 			t.TokenGeneratorKey = &key
 		}
+		t.tokenProvider = t.TokenProvider
+		if t.tokenProvider == nil {
+			t.tokenProvider = &DefaultTokenProvider{
+				generator: handshake.NewTokenGenerator(TokenKey{Key: *t.TokenGeneratorKey}),
+			}
+		}
 
 		if t.ConnectionIDGenerator != nil {
 			t.connIDGenerator = t.ConnectionIDGenerator
@@ -263,7 +406,9 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			t.connIDGenerator = &protocol.DefaultConnectionIDGenerator{ConnLen: t.connIDLen}
 		}
 
-		getMultiplexer().AddConn(t.Conn)
+		if t.usesMultiplexer() {
+			getMultiplexer().AddConn(t.Conn)
+		}
 		go t.listen(conn)
 		go t.runSendQueue()
 	})
@@ -275,7 +420,7 @@ func (t *Transport) WriteTo(b []byte, addr net.Addr) (int, error) {
 	if err := t.init(false); err != nil {
 		return 0, err
 	}
-	return t.conn.WritePacket(b, addr, nil, 0, protocol.ECNUnsupported)
+	return t.conn.WritePacket(b, addr, netip.Addr{}, nil, 0, protocol.ECNUnsupported, time.Time{})
 }
 
 func (t *Transport) enqueueClosePacket(p closePacket) {
@@ -293,7 +438,7 @@ func (t *Transport) runSendQueue() {
 		case <-t.listening:
 			return
 		case p := <-t.closeQueue:
-			t.conn.WritePacket(p.payload, p.addr, p.info.OOB(), 0, protocol.ECNUnsupported)
+			t.conn.WritePacket(p.payload, p.addr, p.info.addr, p.info.OOB(), 0, protocol.ECNUnsupported, time.Time{})
 		case p := <-t.statelessResetQueue:
 			t.sendStatelessReset(p)
 		}
@@ -355,12 +500,20 @@ func (t *Transport) close(e error) {
 	t.closed = true
 }
 
+// usesMultiplexer says if the Transport registers its net.PacketConn with the multiplexer,
+// which makes sure that a local address is not used by multiple Transports.
+func (t *Transport) usesMultiplexer() bool {
+	return t.Conn != nil && !t.reusePort
+}
+
 // only print warnings about the UDP receive buffer size once
 var setBufferWarningOnce sync.Once
 
 func (t *Transport) listen(conn rawConn) {
 	defer close(t.listening)
-	defer getMultiplexer().RemoveConn(t.Conn)
+	if t.usesMultiplexer() {
+		defer getMultiplexer().RemoveConn(t.Conn)
+	}
 
 	for {
 		p, err := conn.ReadPacket()
@@ -391,15 +544,28 @@ func (t *Transport) listen(conn rawConn) {
 }
 
 func (t *Transport) handlePacket(p receivedPacket) {
+	t.handlePacketImpl(p, false)
+}
+
+// handlePacketImpl handles a packet.
+// Packets that were forwarded by a sibling Transport are never forwarded again.
+func (t *Transport) handlePacketImpl(p receivedPacket, forwarded bool) {
 	if len(p.data) == 0 {
 		return
 	}
-	if !wire.IsPotentialQUICPacket(p.data[0]) && !wire.IsLongHeaderPacket(p.data[0]) {
+	isQUICPacket := wire.IsPotentialQUICPacket(p.data[0]) || wire.IsLongHeaderPacket(p.data[0])
+	// When greasing the QUIC bit (RFC 9287), a short header packet might have the QUIC bit set to 0.
+	// Such packets are only treated as QUIC packets if they belong to a known connection.
+	if !isQUICPacket && !t.acceptZeroQUICBit.Load() {
 		t.handleNonQUICPacket(p)
 		return
 	}
 	connID, err := wire.ParseConnectionID(p.data, t.connIDLen)
 	if err != nil {
+		if !isQUICPacket {
+			t.handleNonQUICPacket(p)
+			return
+		}
 		t.logger.Debugf("error parsing connection ID on packet from %s: %s", p.remoteAddr, err)
 		if t.Tracer != nil && t.Tracer.DroppedPacket != nil {
 			t.Tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropHeaderParseError)
@@ -413,6 +579,10 @@ func (t *Transport) handlePacket(p receivedPacket) {
 		handler.handlePacket(p)
 		return
 	}
+	if !isQUICPacket {
+		t.handleNonQUICPacket(p)
+		return
+	}
 	// RFC 9000 section 10.3.1 requires that the stateless reset detection logic is run for both
 	// packets that cannot be associated with any connections, and for packets that can't be decrypted.
 	// We deviate from the RFC and ignore the latter: If a packet's connection ID is associated with an
@@ -423,6 +593,9 @@ func (t *Transport) handlePacket(p receivedPacket) {
 	if isStatelessReset := t.maybeHandleStatelessReset(p.data); isStatelessReset {
 		return
 	}
+	if !forwarded && t.maybeForward(p, connID) {
+		return
+	}
 	if !wire.IsLongHeaderPacket(p.data[0]) {
 		t.maybeSendStatelessReset(p)
 		return
@@ -472,7 +645,7 @@ func (t *Transport) sendStatelessReset(p receivedPacket) {
 	rand.Read(data)
 	data[0] = (data[0] & 0x7f) | 0x40
 	data = append(data, token[:]...)
-	if _, err := t.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported); err != nil {
+	if _, err := t.conn.WritePacket(data, p.remoteAddr, p.info.addr, p.info.OOB(), 0, protocol.ECNUnsupported, time.Time{}); err != nil {
 		t.logger.Debugf("Error sending Stateless Reset to %s: %s", p.remoteAddr, err)
 	}
 }
@@ -0,0 +1,200 @@
+package quic
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// A PacketForwarder forwards packets to a sibling Transport, usually running in a different process.
+//
+// This allows restarting a server without interrupting existing connections:
+// The old and the new process both receive packets on the same UDP socket, either by passing the
+// socket's file descriptor to the new process, or by opening a second socket using SO_REUSEPORT.
+// Each process handles packets for its own connection IDs, and forwards all other packets to its sibling.
+// Once the new process is ready to accept connections, the old process calls Transport.Handoff.
+//
+// A Transport only knows about its own connection IDs, so it can't tell which sibling a packet belongs to.
+// If packets need to be routed to one of multiple processes, or if the kernel steers packets
+// to the right socket (e.g. using a BPF program), the owner needs to be derivable from the connection ID itself.
+// This is done by configuring every Transport with a ConnectionIDGenerator that encodes an identifier
+// of the process into all connection IDs it generates. All processes need to use the same connection ID length.
+// The destination connection ID of a short header packet starts at the second byte of the packet.
+// The Initial packets of new connections use a connection ID chosen by the client,
+// and are handled by the process that didn't hand off.
+//
+// Forwarded packets are never forwarded again, so forwarding loops are not possible.
+type PacketForwarder interface {
+	// ForwardPacket forwards a packet received from remoteAddr.
+	// It must not retain b after returning.
+	ForwardPacket(b []byte, remoteAddr net.Addr) error
+}
+
+// NewConnForwarder creates a PacketForwarder that forwards packets on conn.
+// Every packet is sent as a single datagram, so conn must be a datagram-oriented connection,
+// for example a connected Unix datagram socket.
+// The sibling Transport reads the packets using ServeForwardedPackets.
+func NewConnForwarder(conn net.Conn) PacketForwarder {
+	return &connForwarder{conn: conn}
+}
+
+type connForwarder struct {
+	conn net.Conn
+}
+
+func (f *connForwarder) ForwardPacket(b []byte, remoteAddr net.Addr) error {
+	// The packet might be up to protocol.MaxPacketBufferSize bytes large,
+	// so we need a large buffer to fit the remote address in addition.
+	buffer := getLargePacketBuffer()
+	defer buffer.Release()
+	data, err := appendForwardedPacket(buffer.Data, b, remoteAddr)
+	if err != nil {
+		return err
+	}
+	_, err = f.conn.Write(data)
+	return err
+}
+
+// A forwarded packet is encoded as:
+// * the length of the remote address (1 byte)
+// * the remote address, encoded using netip.AddrPort.MarshalBinary
+// * the packet
+func appendForwardedPacket(b, packet []byte, remoteAddr net.Addr) ([]byte, error) {
+	addr, err := toAddrPort(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	a, err := addr.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, uint8(len(a)))
+	b = append(b, a...)
+	return append(b, packet...), nil
+}
+
+func parseForwardedPacket(b []byte) (packet []byte, remoteAddr *net.UDPAddr, _ error) {
+	if len(b) < 1 || len(b) < 1+int(b[0]) {
+		return nil, nil, errors.New("forwarded packet too short")
+	}
+	var addr netip.AddrPort
+	if err := addr.UnmarshalBinary(b[1 : 1+int(b[0])]); err != nil {
+		return nil, nil, err
+	}
+	return b[1+int(b[0]):], net.UDPAddrFromAddrPort(addr), nil
+}
+
+func toAddrPort(addr net.Addr) (netip.AddrPort, error) {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.AddrPort(), nil
+	case interface{ AddrPort() netip.AddrPort }:
+		return a.AddrPort(), nil
+	default:
+		return netip.ParseAddrPort(addr.String())
+	}
+}
+
+// ServeForwardedPackets reads packets forwarded by a sibling Transport from conn,
+// and handles them as if they had been received on this Transport's Conn.
+// It blocks until reading from conn fails, or the Transport is closed, in which case it returns ErrTransportClosed.
+// To interrupt the pending Read when the Transport is closed, it sets a read deadline on conn,
+// and resets it before returning. conn is not closed.
+func (t *Transport) ServeForwardedPackets(conn net.Conn) error {
+	if err := t.init(false); err != nil {
+		return err
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		for {
+			buffer := getLargePacketBuffer()
+			buffer.Data = buffer.Data[:cap(buffer.Data)]
+			n, err := conn.Read(buffer.Data)
+			if err != nil {
+				buffer.Release()
+				errChan <- err
+				return
+			}
+			data, remoteAddr, err := parseForwardedPacket(buffer.Data[:n])
+			if err != nil {
+				t.logger.Debugf("error parsing forwarded packet: %s", err)
+				buffer.Release()
+				continue
+			}
+			t.handlePacketImpl(receivedPacket{
+				remoteAddr: remoteAddr,
+				rcvTime:    time.Now(),
+				data:       data,
+				buffer:     buffer,
+			}, true)
+		}
+	}()
+	select {
+	case err := <-errChan:
+		return err
+	case <-t.listening:
+		conn.SetReadDeadline(time.Now())
+		<-errChan
+		conn.SetReadDeadline(time.Time{})
+		return ErrTransportClosed
+	}
+}
+
+// Handoff hands off new connections to the sibling Transport.
+// After calling Handoff, this Transport stops accepting new connections,
+// and forwards all packets that don't belong to one of its connections using the Forwarder.
+// If no Forwarder is set, these packets are dropped. This is useful when the kernel steers packets
+// to the right socket based on the connection ID, e.g. using a BPF program attached to the socket,
+// see PacketForwarder for how connection IDs identify the owning process.
+//
+// Existing connections are not affected. Handoff blocks until all connections have been closed,
+// or until the context is canceled. The Transport is not closed.
+func (t *Transport) Handoff(ctx context.Context) error {
+	if err := t.init(false); err != nil {
+		return err
+	}
+	t.handingOff.Store(true)
+	select {
+	case <-t.handlerMap.Empty():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// maybeForward forwards packets that can't be associated with any of this Transport's connections.
+// It returns true if the packet was consumed.
+func (t *Transport) maybeForward(p receivedPacket, connID protocol.ConnectionID) bool {
+	handingOff := t.handingOff.Load()
+	if !handingOff {
+		// As long as we're not handing off, this Transport is responsible for new connections.
+		// Short header and Handshake packets can only belong to an existing connection.
+		if t.Forwarder == nil || (wire.IsLongHeaderPacket(p.data[0]) && !wire.IsHandshakePacket(p.data)) {
+			return false
+		}
+	}
+	if t.Forwarder == nil {
+		// The kernel is expected to steer packets to the right socket.
+		// Short header packets are handled as usual, i.e. a stateless reset might be sent.
+		if !wire.IsLongHeaderPacket(p.data[0]) {
+			return false
+		}
+		t.logger.Debugf("Dropping a packet with an unknown connection ID %s, since the Transport is handing off.", connID)
+		if t.Tracer != nil && t.Tracer.DroppedPacket != nil {
+			t.Tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropUnknownConnectionID)
+		}
+		p.buffer.Release()
+		return true
+	}
+	if err := t.Forwarder.ForwardPacket(p.data, p.remoteAddr); err != nil {
+		t.logger.Debugf("Error forwarding packet with connection ID %s: %s", connID, err)
+	}
+	p.buffer.Release()
+	return true
+}
@@ -0,0 +1,205 @@
+package quic
+
+import (
+	"context"
+	"net"
+	"time"
+
+	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+type packetForwarderFunc func([]byte, net.Addr) error
+
+func (f packetForwarderFunc) ForwardPacket(b []byte, addr net.Addr) error { return f(b, addr) }
+
+var _ = Describe("Transport handoff", func() {
+	type forwardedPacket struct {
+		data []byte
+		addr net.Addr
+	}
+
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 4242}
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+
+	newForwarder := func() (PacketForwarder, <-chan forwardedPacket) {
+		forwarded := make(chan forwardedPacket, 10)
+		return packetForwarderFunc(func(b []byte, addr net.Addr) error {
+			forwarded <- forwardedPacket{data: append([]byte{}, b...), addr: addr}
+			return nil
+		}), forwarded
+	}
+
+	getShortHeaderPacket := func() []byte {
+		b, err := wire.AppendShortHeader(nil, connID, 1337, protocol.PacketNumberLen2, protocol.KeyPhaseOne, false)
+		Expect(err).ToNot(HaveOccurred())
+		return append(b, make([]byte, 50)...)
+	}
+
+	getLongHeaderPacket := func(t protocol.PacketType) []byte {
+		b, err := (&wire.ExtendedHeader{
+			Header: wire.Header{
+				Type:             t,
+				DestConnectionID: connID,
+				Length:           52,
+				Version:          protocol.Version1,
+			},
+			PacketNumberLen: protocol.PacketNumberLen2,
+		}).Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		return append(b, make([]byte, 50)...)
+	}
+
+	// newTransport creates a new Transport with a mocked packet handler map.
+	// The returned function needs to be called to close the Transport.
+	newTransport := func(forwarder PacketForwarder) (*Transport, *MockPacketHandlerManager, func()) {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr := &Transport{Conn: conn, ConnectionIDLength: connID.Len(), Forwarder: forwarder}
+		Expect(tr.init(false)).To(Succeed())
+		phm := NewMockPacketHandlerManager(mockCtrl)
+		tr.handlerMap = phm
+		return tr, phm, func() {
+			phm.EXPECT().Close(gomock.Any())
+			tr.Close()
+			conn.Close()
+		}
+	}
+
+	It("forwards short header and Handshake packets with unknown connection IDs", func() {
+		forwarder, forwarded := newForwarder()
+		tr, phm, closeTransport := newTransport(forwarder)
+		defer closeTransport()
+		phm.EXPECT().Get(connID).Times(2)
+		phm.EXPECT().GetByResetToken(gomock.Any())
+
+		short := getShortHeaderPacket()
+		tr.handlePacket(receivedPacket{data: short, remoteAddr: remoteAddr, buffer: getPacketBuffer()})
+		var p forwardedPacket
+		Expect(forwarded).To(Receive(&p))
+		Expect(p.data).To(Equal(short))
+		Expect(p.addr).To(Equal(remoteAddr))
+
+		handshake := getLongHeaderPacket(protocol.PacketTypeHandshake)
+		tr.handlePacket(receivedPacket{data: handshake, remoteAddr: remoteAddr, buffer: getPacketBuffer()})
+		Expect(forwarded).To(Receive(&p))
+		Expect(p.data).To(Equal(handshake))
+	})
+
+	It("doesn't forward packets that belong to a connection", func() {
+		forwarder, forwarded := newForwarder()
+		tr, phm, closeTransport := newTransport(forwarder)
+		defer closeTransport()
+		handler := NewMockPacketHandler(mockCtrl)
+		phm.EXPECT().Get(connID).Return(handler, true)
+		handler.EXPECT().handlePacket(gomock.Any())
+		tr.handlePacket(receivedPacket{data: getShortHeaderPacket(), remoteAddr: remoteAddr, buffer: getPacketBuffer()})
+		Expect(forwarded).ToNot(Receive())
+	})
+
+	It("doesn't forward Initial packets, unless handing off", func() {
+		forwarder, forwarded := newForwarder()
+		tr, phm, closeTransport := newTransport(forwarder)
+		defer closeTransport()
+		phm.EXPECT().Get(connID).Times(2)
+		phm.EXPECT().Empty().Return(make(chan struct{}))
+		initial := getLongHeaderPacket(protocol.PacketTypeInitial)
+		tr.handlePacket(receivedPacket{data: initial, remoteAddr: remoteAddr, buffer: getPacketBuffer()})
+		Expect(forwarded).ToNot(Receive())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errChan := make(chan error, 1)
+		go func() { errChan <- tr.Handoff(ctx) }()
+		Eventually(tr.handingOff.Load).Should(BeTrue())
+		tr.handlePacket(receivedPacket{data: initial, remoteAddr: remoteAddr, buffer: getPacketBuffer()})
+		var p forwardedPacket
+		Expect(forwarded).To(Receive(&p))
+		Expect(p.data).To(Equal(initial))
+
+		Consistently(errChan).ShouldNot(Receive())
+		cancel()
+		Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+	})
+
+	It("doesn't forward packets that were forwarded by the sibling", func() {
+		forwarder, forwarded := newForwarder()
+		tr, phm, closeTransport := newTransport(forwarder)
+		defer closeTransport()
+		phm.EXPECT().Get(connID)
+		phm.EXPECT().GetByResetToken(gomock.Any())
+		tr.handlePacketImpl(receivedPacket{data: getShortHeaderPacket(), remoteAddr: remoteAddr, buffer: getPacketBuffer()}, true)
+		Expect(forwarded).ToNot(Receive())
+	})
+
+	It("drops packets for new connections when handing off without a forwarder", func() {
+		tr, phm, closeTransport := newTransport(nil)
+		defer closeTransport()
+		t, tracer := mocklogging.NewMockTracer(mockCtrl)
+		tr.Tracer = t
+		done := make(chan struct{})
+		close(done)
+		phm.EXPECT().Empty().Return(done)
+		Expect(tr.Handoff(context.Background())).To(Succeed())
+
+		initial := getLongHeaderPacket(protocol.PacketTypeInitial)
+		phm.EXPECT().Get(connID)
+		tracer.EXPECT().DroppedPacket(remoteAddr, logging.PacketTypeNotDetermined, protocol.ByteCount(len(initial)), logging.PacketDropUnknownConnectionID)
+		tr.handlePacket(receivedPacket{data: initial, remoteAddr: remoteAddr, buffer: getPacketBuffer()})
+		tracer.EXPECT().Close()
+	})
+
+	It("forwards packets over a connection", func() {
+		// net.Pipe preserves message boundaries, as long as the reader's buffer is large enough
+		c1, c2 := net.Pipe()
+		defer c1.Close()
+		defer c2.Close()
+
+		tr, phm, closeTransport := newTransport(nil)
+		defer closeTransport()
+		handler := NewMockPacketHandler(mockCtrl)
+		received := make(chan receivedPacket, 1)
+		phm.EXPECT().Get(connID).Return(handler, true)
+		handler.EXPECT().handlePacket(gomock.Any()).Do(func(p receivedPacket) { received <- p })
+		errChan := make(chan error, 1)
+		go func() { errChan <- tr.ServeForwardedPackets(c2) }()
+
+		packet := getShortHeaderPacket()
+		Expect(NewConnForwarder(c1).ForwardPacket(packet, remoteAddr)).To(Succeed())
+		var p receivedPacket
+		Eventually(received).Should(Receive(&p))
+		Expect(p.data).To(Equal(packet))
+		Expect(p.remoteAddr).To(Equal(remoteAddr))
+		Expect(p.rcvTime).To(BeTemporally("~", time.Now(), time.Second))
+
+		c2.Close()
+		Eventually(errChan).Should(Receive(HaveOccurred()))
+	})
+	It("stops serving forwarded packets when the Transport is closed", func() {
+		c1, c2 := net.Pipe()
+		defer c1.Close()
+		defer c2.Close()
+
+		tr, _, closeTransport := newTransport(nil)
+		errChan := make(chan error, 1)
+		go func() { errChan <- tr.ServeForwardedPackets(c2) }()
+		Consistently(errChan, scaleDuration(20*time.Millisecond)).ShouldNot(Receive())
+		closeTransport()
+		var err error
+		Eventually(errChan).Should(Receive(&err))
+		Expect(err).To(MatchError(ErrTransportClosed))
+		Expect(err).To(MatchError(net.ErrClosed))
+
+		// the read deadline was reset
+		go c1.Write([]byte("foobar"))
+		b := make([]byte, 10)
+		n, err := c2.Read(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b[:n]).To(Equal([]byte("foobar")))
+	})
+})
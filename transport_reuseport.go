@@ -0,0 +1,289 @@
+package quic
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// maxSteeringAttempts is the maximum number of connection IDs generated by the underlying
+// ConnectionIDGenerator until a connection ID that is steered to the right socket is found.
+const maxSteeringAttempts = 4096
+
+// A ReusePortTransport receives packets on multiple UDP sockets bound to the same address using SO_REUSEPORT.
+// Every socket is handled by its own Transport, and therefore by its own goroutine,
+// allowing receive processing to be spread across multiple CPU cores.
+//
+// The kernel steers packets to the socket handling the connection based on the last byte of the
+// connection ID chosen by the server: the connection ID generator only returns connection IDs
+// whose last byte, modulo the number of sockets, equals the index of the socket.
+// Clients that haven't yet received a connection ID from the server are steered
+// based on the connection ID chosen by the client.
+//
+// It is only supported on Linux.
+type ReusePortTransport struct {
+	transports []*Transport
+	tracer     *logging.Tracer
+
+	mutex  sync.Mutex
+	closed bool
+}
+
+// NewReusePortTransport opens numSockets UDP sockets bound to addr.
+// If no port is specified, all sockets are bound to the same port chosen by the kernel.
+// The configuration of the template is used for all sockets. Its Conn must not be set.
+// PreferredAddress and Forwarder are not supported.
+//
+// Only the server side of the Transport is supported: Connections are accepted using Listen and ListenEarly.
+func NewReusePortTransport(addr *net.UDPAddr, numSockets int, template *Transport) (*ReusePortTransport, error) {
+	if numSockets < 1 || numSockets > 256 {
+		return nil, fmt.Errorf("quic: invalid number of sockets: %d", numSockets)
+	}
+	if template == nil {
+		template = &Transport{}
+	}
+	if template.Conn != nil || template.PacketIO != nil {
+		return nil, errors.New("quic: Conn and PacketIO must not be set on the template Transport")
+	}
+	if template.PreferredAddress != nil {
+		return nil, errors.New("quic: preferred address not supported when using multiple sockets")
+	}
+	if template.Forwarder != nil {
+		return nil, errors.New("quic: packet forwarding not supported when using multiple sockets")
+	}
+	var connIDGenerator ConnectionIDGenerator
+	if template.ConnectionIDGenerator != nil {
+		connIDGenerator = template.ConnectionIDGenerator
+	} else {
+		connIDLen := template.ConnectionIDLength
+		if connIDLen == 0 {
+			connIDLen = protocol.DefaultConnectionIDLength
+		}
+		connIDGenerator = &protocol.DefaultConnectionIDGenerator{ConnLen: connIDLen}
+	}
+	// All sockets need to be able to validate tokens issued on any other socket.
+	tokenGeneratorKey := template.TokenGeneratorKey
+	if tokenGeneratorKey == nil {
+		tokenGeneratorKey = &TokenGeneratorKey{}
+		if _, err := rand.Read(tokenGeneratorKey[:]); err != nil {
+			return nil, err
+		}
+	}
+	// Tracer.Close is called once, when the ReusePortTransport is closed.
+	var tracer *logging.Tracer
+	if template.Tracer != nil {
+		t := *template.Tracer
+		t.Close = nil
+		tracer = &t
+	}
+
+	conns, err := listenReusePort(addr, numSockets, connIDGenerator.ConnectionIDLen())
+	if err != nil {
+		return nil, err
+	}
+	transports := make([]*Transport, 0, numSockets)
+	for i, conn := range conns {
+		transports = append(transports, &Transport{
+			Conn: conn,
+			ConnectionIDGenerator: &steeringConnIDGenerator{
+				ConnectionIDGenerator: connIDGenerator,
+				index:                 i,
+				numSockets:            numSockets,
+			},
+			StatelessResetKey:                template.StatelessResetKey,
+			TokenGeneratorKey:                tokenGeneratorKey,
+			TokenProvider:                    template.TokenProvider,
+			MaxTokenAge:                      template.MaxTokenAge,
+			DisableVersionNegotiationPackets: template.DisableVersionNegotiationPackets,
+			EnableKernelPacing:               template.EnableKernelPacing,
+			VerifySourceAddress:              template.VerifySourceAddress,
+			ConnContext:                      template.ConnContext,
+			Tracer:                           tracer,
+			createdConn:                      true,
+			reusePort:                        true,
+		})
+	}
+	return &ReusePortTransport{transports: transports, tracer: template.Tracer}, nil
+}
+
+// Listen starts listening for incoming QUIC connections on all sockets.
+// There can only be a single listener on any ReusePortTransport.
+func (t *ReusePortTransport) Listen(tlsConf *tls.Config, conf *Config) (*Listener, error) {
+	g, err := t.createServerGroup(tlsConf, conf, false)
+	if err != nil {
+		return nil, err
+	}
+	return &Listener{baseServer: g.servers[0], group: g}, nil
+}
+
+// ListenEarly starts listening for incoming QUIC connections on all sockets.
+// There can only be a single listener on any ReusePortTransport.
+func (t *ReusePortTransport) ListenEarly(tlsConf *tls.Config, conf *Config) (*EarlyListener, error) {
+	g, err := t.createServerGroup(tlsConf, conf, true)
+	if err != nil {
+		return nil, err
+	}
+	return &EarlyListener{baseServer: g.servers[0], group: g}, nil
+}
+
+func (t *ReusePortTransport) createServerGroup(tlsConf *tls.Config, conf *Config, allow0RTT bool) (*serverGroup, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.closed {
+		return nil, errors.New("quic: transport closed")
+	}
+	servers := make([]*baseServer, 0, len(t.transports))
+	for _, tr := range t.transports {
+		s, err := tr.createServer(tlsConf, conf, allow0RTT)
+		if err != nil {
+			for _, s := range servers {
+				s.Close()
+			}
+			return nil, err
+		}
+		servers = append(servers, s)
+	}
+	return newServerGroup(servers), nil
+}
+
+// Addr returns the local address that all sockets are bound to.
+func (t *ReusePortTransport) Addr() net.Addr {
+	return t.transports[0].Conn.LocalAddr()
+}
+
+// Close closes all sockets and all connections.
+func (t *ReusePortTransport) Close() error {
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mutex.Unlock()
+
+	var firstErr error
+	for _, tr := range t.transports {
+		if err := tr.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if t.tracer != nil && t.tracer.Close != nil {
+		t.tracer.Close()
+	}
+	return firstErr
+}
+
+// The steeringConnIDGenerator generates connection IDs that are steered to the socket at index
+// by the BPF program attached to the SO_REUSEPORT group.
+type steeringConnIDGenerator struct {
+	ConnectionIDGenerator
+
+	index      int
+	numSockets int
+}
+
+func (g *steeringConnIDGenerator) GenerateConnectionID() (ConnectionID, error) {
+	for i := 0; i < maxSteeringAttempts; i++ {
+		connID, err := g.ConnectionIDGenerator.GenerateConnectionID()
+		if err != nil {
+			return ConnectionID{}, err
+		}
+		if connID.Len() == 0 {
+			return ConnectionID{}, errors.New("quic: zero-length connection IDs can't be steered")
+		}
+		if int(connID.Bytes()[connID.Len()-1])%g.numSockets == g.index {
+			return connID, nil
+		}
+	}
+	return ConnectionID{}, fmt.Errorf("quic: failed to generate a connection ID for socket %d after %d attempts", g.index, maxSteeringAttempts)
+}
+
+// A serverGroup delivers the connections accepted by multiple servers through a single Listener.
+type serverGroup struct {
+	servers []*baseServer
+
+	connQueue chan quicConn
+	closeOnce sync.Once
+	closed    chan struct{}
+	wg        sync.WaitGroup
+
+	errOnce   sync.Once
+	errorChan chan struct{} // closed when the first server fails
+	closeErr  error         // the error of the first server that failed
+}
+
+func newServerGroup(servers []*baseServer) *serverGroup {
+	g := &serverGroup{
+		servers:   servers,
+		connQueue: make(chan quicConn, protocol.MaxAcceptQueueSize),
+		closed:    make(chan struct{}),
+		errorChan: make(chan struct{}),
+	}
+	g.wg.Add(len(servers))
+	for _, s := range servers {
+		go func(s *baseServer) {
+			defer g.wg.Done()
+			g.run(s)
+		}(s)
+	}
+	return g
+}
+
+func (g *serverGroup) run(s *baseServer) {
+	for {
+		conn, err := s.accept(context.Background())
+		if err != nil {
+			g.errOnce.Do(func() {
+				g.closeErr = err
+				close(g.errorChan)
+			})
+			return
+		}
+		select {
+		case g.connQueue <- conn:
+		default:
+			conn.closeWithTransportError(ConnectionRefused)
+		}
+	}
+}
+
+func (g *serverGroup) accept(ctx context.Context) (quicConn, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case conn := <-g.connQueue:
+		return conn, nil
+	case <-g.closed:
+		return nil, ErrServerClosed
+	case <-g.errorChan:
+		return nil, g.closeErr
+	}
+}
+
+func (g *serverGroup) Close() error {
+	g.closeOnce.Do(func() {
+		close(g.closed)
+		for _, s := range g.servers {
+			s.Close()
+		}
+	})
+	g.wg.Wait()
+	// No more connections are queued once all servers have returned.
+	// Reject the connections that were never accepted.
+	for {
+		select {
+		case conn := <-g.connQueue:
+			conn.closeWithTransportError(ConnectionRefused)
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/testdata"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReusePortTransport", func() {
+	It("rejects invalid configurations", func() {
+		_, err := NewReusePortTransport(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, 0, nil)
+		Expect(err).To(MatchError("quic: invalid number of sockets: 0"))
+		_, err = NewReusePortTransport(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, 2, &Transport{Forwarder: NewConnForwarder(nil)})
+		Expect(err).To(MatchError("quic: packet forwarding not supported when using multiple sockets"))
+	})
+
+	It("accepts connections on all sockets using a single listener", func() {
+		tr, err := NewReusePortTransport(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, 4, &Transport{ConnectionIDLength: 6})
+		Expect(err).ToNot(HaveOccurred())
+		defer tr.Close()
+		ln, err := tr.Listen(testdata.GetTLSConfig(), &Config{})
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		Expect(ln.Addr()).To(Equal(tr.Addr()))
+
+		const numConns = 16
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < numConns; i++ {
+				conn, err := ln.Accept(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					str, err := conn.AcceptStream(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					_, err = io.Copy(str, str)
+					Expect(err).ToNot(HaveOccurred())
+					str.Close()
+				}()
+			}
+		}()
+
+		tlsConf := &tls.Config{RootCAs: testdata.GetRootCA(), ServerName: "localhost"}
+		for i := 0; i < numConns; i++ {
+			udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			Expect(err).ToNot(HaveOccurred())
+			clientTr := &Transport{Conn: udpConn}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			conn, err := clientTr.Dial(ctx, tr.Addr(), tlsConf, &Config{})
+			cancel()
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foobar")))
+			conn.CloseWithError(0, "")
+			Expect(clientTr.Close()).To(Succeed())
+			Expect(udpConn.Close()).To(Succeed())
+		}
+	})
+})
@@ -0,0 +1,116 @@
+package quic
+
+import (
+	"context"
+	"errors"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type constantConnIDGenerator struct {
+	connID protocol.ConnectionID
+	err    error
+}
+
+func (g *constantConnIDGenerator) GenerateConnectionID() (protocol.ConnectionID, error) {
+	return g.connID, g.err
+}
+
+func (g *constantConnIDGenerator) ConnectionIDLen() int { return g.connID.Len() }
+
+var _ = Describe("Steering Connection ID Generator", func() {
+	It("generates connection IDs for the socket", func() {
+		for i := 0; i < 5; i++ {
+			g := &steeringConnIDGenerator{
+				ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 6},
+				index:                 i,
+				numSockets:            5,
+			}
+			Expect(g.ConnectionIDLen()).To(Equal(6))
+			for j := 0; j < 100; j++ {
+				connID, err := g.GenerateConnectionID()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(connID.Len()).To(Equal(6))
+				Expect(int(connID.Bytes()[5]) % 5).To(Equal(i))
+			}
+		}
+	})
+
+	It("gives up if the generator doesn't generate a suitable connection ID", func() {
+		g := &steeringConnIDGenerator{
+			ConnectionIDGenerator: &constantConnIDGenerator{connID: protocol.ParseConnectionID([]byte{1, 2, 3, 4})},
+			index:                 1,
+			numSockets:            4,
+		}
+		_, err := g.GenerateConnectionID()
+		Expect(err).To(MatchError("quic: failed to generate a connection ID for socket 1 after 4096 attempts"))
+		g.index = 0
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+	})
+
+	It("returns errors from the generator", func() {
+		g := &steeringConnIDGenerator{
+			ConnectionIDGenerator: &constantConnIDGenerator{err: errors.New("test error")},
+			numSockets:            2,
+		}
+		_, err := g.GenerateConnectionID()
+		Expect(err).To(MatchError("test error"))
+	})
+})
+
+var _ = Describe("Server Group", func() {
+	// newServer creates a baseServer that only delivers the connections queued on its connQueue.
+	newServer := func() *baseServer {
+		running := make(chan struct{})
+		close(running)
+		return &baseServer{
+			connQueue: make(chan quicConn, 4),
+			errorChan: make(chan struct{}),
+			running:   running,
+			onClose:   func() {},
+		}
+	}
+
+	It("accepts connections from all servers", func() {
+		s1, s2 := newServer(), newServer()
+		g := newServerGroup([]*baseServer{s1, s2})
+		defer g.Close()
+		c1, c2 := NewMockQUICConn(mockCtrl), NewMockQUICConn(mockCtrl)
+		s1.connQueue <- c1
+		s2.connQueue <- c2
+		var accepted []quicConn
+		for i := 0; i < 2; i++ {
+			conn, err := g.accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			accepted = append(accepted, conn)
+		}
+		Expect(accepted).To(ConsistOf(c1, c2))
+	})
+
+	It("returns the error of the first server that failed", func() {
+		s1, s2 := newServer(), newServer()
+		g := newServerGroup([]*baseServer{s1, s2})
+		defer g.Close()
+		testErr := errors.New("test error")
+		s2.close(testErr, false)
+		_, err := g.accept(context.Background())
+		Expect(err).To(MatchError(testErr))
+	})
+
+	It("rejects queued connections when closed", func() {
+		s := newServer()
+		g := newServerGroup([]*baseServer{s})
+		conn := NewMockQUICConn(mockCtrl)
+		s.connQueue <- conn
+		Eventually(func() int { return len(g.connQueue) }).Should(Equal(1))
+		conn.EXPECT().closeWithTransportError(ConnectionRefused)
+		Expect(g.Close()).To(Succeed())
+		_, err := g.accept(context.Background())
+		Expect(err).To(MatchError(ErrServerClosed))
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"net/netip"
 	"syscall"
 	"time"
 
@@ -122,6 +123,61 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("validates the preferred address", func() {
+		var port int
+		newTransport := func(connIDLen int) (*Transport, func()) {
+			port++
+			conn := NewMockPacketConn(mockCtrl)
+			conn.EXPECT().LocalAddr().Return(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port}).AnyTimes()
+			conn.EXPECT().ReadFrom(gomock.Any()).Return(0, nil, errors.New("closed")).AnyTimes()
+			conn.EXPECT().SetReadDeadline(gomock.Any()).AnyTimes()
+			tr := &Transport{Conn: conn, ConnectionIDLength: connIDLen}
+			return tr, func() { tr.Close() }
+		}
+		preferredTr, closeFn := newTransport(4)
+		defer closeFn()
+		addr4 := netip.MustParseAddrPort("192.0.2.1:443")
+		addr6 := netip.MustParseAddrPort("[2001:db8::1]:443")
+
+		for _, tc := range []struct {
+			pa  *PreferredAddress
+			err string
+		}{
+			{pa: &PreferredAddress{}, err: "quic: preferred address needs an IPv4 or an IPv6 address"},
+			{pa: &PreferredAddress{IPv4: addr6, IPv4Transport: preferredTr}, err: "quic: invalid IPv4 preferred address"},
+			{pa: &PreferredAddress{IPv6: addr4, IPv6Transport: preferredTr}, err: "quic: invalid IPv6 preferred address"},
+			{pa: &PreferredAddress{IPv4: addr4}, err: "quic: missing Transport for the IPv4 preferred address"},
+			{pa: &PreferredAddress{IPv4: addr4, IPv4Transport: preferredTr, IPv6: addr6}, err: "quic: missing Transport for the IPv6 preferred address"},
+		} {
+			tr, closeFn := newTransport(4)
+			tr.PreferredAddress = tc.pa
+			_, err := tr.Listen(&tls.Config{}, nil)
+			Expect(err).To(MatchError(tc.err))
+			closeFn()
+		}
+
+		// the preferred address needs to use a different Transport
+		tr, closeFn := newTransport(4)
+		tr.PreferredAddress = &PreferredAddress{IPv4: addr4, IPv4Transport: tr}
+		_, err := tr.Listen(&tls.Config{}, nil)
+		Expect(err).To(MatchError("quic: preferred address must use a different Transport"))
+		closeFn()
+
+		// the connection ID lengths need to match
+		tr, closeFn = newTransport(8)
+		tr.PreferredAddress = &PreferredAddress{IPv4: addr4, IPv4Transport: preferredTr}
+		_, err = tr.Listen(&tls.Config{}, nil)
+		Expect(err).To(MatchError("quic: preferred address Transport uses connection IDs of length 4, expected 8"))
+		closeFn()
+
+		tr, closeFn = newTransport(4)
+		defer closeFn()
+		tr.PreferredAddress = &PreferredAddress{IPv4: addr4, IPv4Transport: preferredTr, IPv6: addr6, IPv6Transport: preferredTr}
+		ln, err := tr.Listen(&tls.Config{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ln.Close()).To(Succeed())
+	})
+
 	It("closes transport concurrently with listener", func() {
 		// try 10 times to trigger race conditions
 		for i := 0; i < 10; i++ {
@@ -221,7 +277,7 @@ var _ = Describe("Transport", func() {
 		rand.Read(token[:])
 
 		var b []byte
-		b, err := wire.AppendShortHeader(b, connID, 1337, 2, protocol.KeyPhaseOne)
+		b, err := wire.AppendShortHeader(b, connID, 1337, 2, protocol.KeyPhaseOne, false)
 		Expect(err).ToNot(HaveOccurred())
 		b = append(b, token[:]...)
 		conn := NewMockPacketHandler(mockCtrl)
@@ -240,6 +296,59 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("passes packets with the QUIC bit set to 0 to connections, if greasing the QUIC bit is enabled", func() {
+		remoteAddr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
+		connID := protocol.ParseConnectionID([]byte{2, 3, 4, 5})
+		packetChan := make(chan packetToRead)
+		tr := Transport{
+			Conn:               newMockPacketConn(packetChan),
+			ConnectionIDLength: connID.Len(),
+		}
+		tr.init(true)
+		defer tr.Close()
+		tr.acceptZeroQUICBit.Store(true)
+		phm := NewMockPacketHandlerManager(mockCtrl)
+		tr.handlerMap = phm
+
+		receivedNonQUICPacket := make(chan []byte)
+		go func() {
+			defer GinkgoRecover()
+			b := make([]byte, 100)
+			n, _, err := tr.ReadNonQUICPacket(context.Background(), b)
+			Expect(err).ToNot(HaveOccurred())
+			receivedNonQUICPacket <- b[:n]
+		}()
+		// Receiving of non-QUIC packets is enabled when ReadNonQUICPacket is called.
+		// Give the Go routine some time to spin up.
+		time.Sleep(scaleDuration(50 * time.Millisecond))
+
+		b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne, true)
+		Expect(err).ToNot(HaveOccurred())
+		b = append(b, make([]byte, 20)...)
+		conn := NewMockPacketHandler(mockCtrl)
+		handled := make(chan struct{})
+		gomock.InOrder(
+			phm.EXPECT().Get(connID).Return(conn, true),
+			conn.EXPECT().handlePacket(gomock.Any()).Do(func(p receivedPacket) {
+				Expect(p.data).To(Equal(b))
+				close(handled)
+			}),
+		)
+		packetChan <- packetToRead{addr: remoteAddr, data: b}
+		Eventually(handled).Should(BeClosed())
+
+		// packets with the QUIC bit set to 0 that don't belong to a connection are non-QUIC packets
+		nonQUICPacket := []byte{0 /* don't set the QUIC bit */, 1, 2, 3, 4, 5}
+		phm.EXPECT().Get(protocol.ParseConnectionID([]byte{1, 2, 3, 4})).Return(nil, false)
+		packetChan <- packetToRead{addr: remoteAddr, data: nonQUICPacket}
+		Eventually(receivedNonQUICPacket).Should(Receive(Equal(nonQUICPacket)))
+
+		// shutdown
+		phm.EXPECT().Close(gomock.Any())
+		close(packetChan)
+		tr.Close()
+	})
+
 	It("handles stateless resets", func() {
 		connID := protocol.ParseConnectionID([]byte{2, 3, 4, 5})
 		packetChan := make(chan packetToRead)
@@ -256,7 +365,7 @@ var _ = Describe("Transport", func() {
 		rand.Read(token[:])
 
 		var b []byte
-		b, err := wire.AppendShortHeader(b, connID, 1337, 2, protocol.KeyPhaseOne)
+		b, err := wire.AppendShortHeader(b, connID, 1337, 2, protocol.KeyPhaseOne, false)
 		Expect(err).ToNot(HaveOccurred())
 		b = append(b, token[:]...)
 		conn := NewMockPacketHandler(mockCtrl)
@@ -293,7 +402,7 @@ var _ = Describe("Transport", func() {
 		tr.handlerMap = phm
 
 		var b []byte
-		b, err := wire.AppendShortHeader(b, connID, 1337, 2, protocol.KeyPhaseOne)
+		b, err := wire.AppendShortHeader(b, connID, 1337, 2, protocol.KeyPhaseOne, false)
 		Expect(err).ToNot(HaveOccurred())
 		b = append(b, make([]byte, protocol.MinStatelessResetSize-len(b)+1)...)
 
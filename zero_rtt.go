@@ -0,0 +1,97 @@
+package quic
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/handshake"
+)
+
+// ZeroRTTInfo contains information about a 0-RTT connection attempt.
+type ZeroRTTInfo struct {
+	// RemoteAddr is the remote address of the connection.
+	// Since the handshake hasn't completed yet, the address hasn't been validated.
+	RemoteAddr net.Addr
+	// TicketAge is the time since the session ticket used for this connection attempt was issued.
+	TicketAge time.Duration
+	// NegotiatedProtocol is the application protocol negotiated using ALPN.
+	NegotiatedProtocol string
+	// SessionTicketData is the application data stored with the session ticket,
+	// see Config.GetSessionTicketData.
+	SessionTicketData []byte
+}
+
+// An AntiReplayStore records which session tickets were used for 0-RTT.
+// 0-RTT data is not protected against replay attacks (see section 8 of RFC 8446).
+// By only accepting 0-RTT once for every session ticket, replays of the ClientHello are limited
+// to the servers sharing the AntiReplayStore.
+// Implementations must be safe for concurrent use.
+type AntiReplayStore interface {
+	// Use marks the session ticket as used.
+	// It returns false if the session ticket was used before, in which case 0-RTT is rejected.
+	// The ticket ID needs to be remembered until the expiry time, after which the session ticket
+	// is not accepted anymore.
+	Use(ticketID []byte, expiry time.Time) bool
+}
+
+// antiReplayStoreCleanupInterval is the interval at which expired tickets are removed from the
+// in-memory AntiReplayStore.
+const antiReplayStoreCleanupInterval = time.Minute
+
+type memoryAntiReplayStore struct {
+	mutex       sync.Mutex
+	used        map[string]time.Time // ticket ID -> expiry
+	nextCleanup time.Time
+}
+
+var _ AntiReplayStore = &memoryAntiReplayStore{}
+
+// NewAntiReplayStore creates an in-memory AntiReplayStore.
+// It is only sufficient if session tickets are not shared with other servers.
+func NewAntiReplayStore() AntiReplayStore {
+	return &memoryAntiReplayStore{used: make(map[string]time.Time)}
+}
+
+func (s *memoryAntiReplayStore) Use(ticketID []byte, expiry time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if now.After(s.nextCleanup) {
+		for id, exp := range s.used {
+			if now.After(exp) {
+				delete(s.used, id)
+			}
+		}
+		s.nextCleanup = now.Add(antiReplayStoreCleanupInterval)
+	}
+	if _, ok := s.used[string(ticketID)]; ok {
+		return false
+	}
+	s.used[string(ticketID)] = expiry
+	return true
+}
+
+// newZeroRTTPolicy returns the policy used by the server to decide if 0-RTT is accepted.
+// It returns nil if neither Config.Accept0RTT nor Config.ZeroRTTAntiReplay is set.
+func newZeroRTTPolicy(conf *Config, remoteAddr net.Addr) *handshake.ZeroRTTPolicy {
+	if conf.Accept0RTT == nil && conf.ZeroRTTAntiReplay == nil {
+		return nil
+	}
+	policy := &handshake.ZeroRTTPolicy{}
+	if conf.Accept0RTT != nil {
+		policy.Accept = func(ticketAge time.Duration, alpn string, appData []byte) bool {
+			return conf.Accept0RTT(&ZeroRTTInfo{
+				RemoteAddr:         remoteAddr,
+				TicketAge:          ticketAge,
+				NegotiatedProtocol: alpn,
+				SessionTicketData:  appData,
+			})
+		}
+	}
+	if conf.ZeroRTTAntiReplay != nil {
+		policy.UseTicket = conf.ZeroRTTAntiReplay.Use
+	}
+	return policy
+}
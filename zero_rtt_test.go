@@ -0,0 +1,65 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("0-RTT", func() {
+	Context("in-memory anti-replay store", func() {
+		It("accepts every session ticket once", func() {
+			s := NewAntiReplayStore()
+			expiry := time.Now().Add(time.Hour)
+			Expect(s.Use([]byte("foo"), expiry)).To(BeTrue())
+			Expect(s.Use([]byte("bar"), expiry)).To(BeTrue())
+			Expect(s.Use([]byte("foo"), expiry)).To(BeFalse())
+			Expect(s.Use([]byte("bar"), expiry)).To(BeFalse())
+		})
+
+		It("removes expired session tickets", func() {
+			s := NewAntiReplayStore().(*memoryAntiReplayStore)
+			Expect(s.Use([]byte("foo"), time.Now().Add(-time.Second))).To(BeTrue())
+			Expect(s.Use([]byte("bar"), time.Now().Add(time.Hour))).To(BeTrue())
+			Expect(s.used).To(HaveLen(2))
+			s.nextCleanup = time.Now().Add(-time.Second)
+			Expect(s.Use([]byte("baz"), time.Now().Add(time.Hour))).To(BeTrue())
+			Expect(s.used).To(HaveLen(2))
+			Expect(s.used).ToNot(HaveKey("foo"))
+		})
+	})
+
+	Context("policy", func() {
+		It("doesn't create a policy if neither Accept0RTT nor ZeroRTTAntiReplay is set", func() {
+			Expect(newZeroRTTPolicy(&Config{Allow0RTT: true}, &net.UDPAddr{})).To(BeNil())
+		})
+
+		It("passes information about the 0-RTT attempt to Accept0RTT", func() {
+			var info *ZeroRTTInfo
+			remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1337}
+			policy := newZeroRTTPolicy(&Config{
+				Accept0RTT: func(i *ZeroRTTInfo) bool {
+					info = i
+					return false
+				},
+			}, remoteAddr)
+			Expect(policy.UseTicket).To(BeNil())
+			Expect(policy.Accept(time.Minute, "h3", []byte("foobar"))).To(BeFalse())
+			Expect(info).To(Equal(&ZeroRTTInfo{
+				RemoteAddr:         remoteAddr,
+				TicketAge:          time.Minute,
+				NegotiatedProtocol: "h3",
+				SessionTicketData:  []byte("foobar"),
+			}))
+		})
+
+		It("uses the anti-replay store", func() {
+			policy := newZeroRTTPolicy(&Config{ZeroRTTAntiReplay: NewAntiReplayStore()}, &net.UDPAddr{})
+			Expect(policy.Accept).To(BeNil())
+			Expect(policy.UseTicket([]byte("foo"), time.Now().Add(time.Hour))).To(BeTrue())
+			Expect(policy.UseTicket([]byte("foo"), time.Now().Add(time.Hour))).To(BeFalse())
+		})
+	})
+})